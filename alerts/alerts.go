@@ -0,0 +1,148 @@
+// Package alerts fires webhook alerts when security events cross
+// configured thresholds, such as a burst of failed logins.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Events that rules can count
+const (
+	EventLoginFailed    = "login_failed"
+	EventAccountLocked  = "account_locked"
+	EventUserRegistered = "user_registered"
+	// EventSigningSlow and EventSigningFailed are recorded for HSM
+	// signatures slower than PKCS11_SLOW_SIGNING, or failing
+	EventSigningSlow   = "token_signing_slow"
+	EventSigningFailed = "token_signing_failed"
+)
+
+var knownEvents = map[string]bool{
+	EventLoginFailed:    true,
+	EventAccountLocked:  true,
+	EventUserRegistered: true,
+	EventSigningSlow:    true,
+	EventSigningFailed:  true,
+}
+
+// Alert severities, as understood by PagerDuty
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+// Alert is a threshold crossing sent to notifiers
+type Alert struct {
+	// Rule names the rule that fired. Notifiers use it as the dedupe key
+	// so repeated firings of one rule group into one incident.
+	Rule     string
+	Event    string
+	Count    int
+	Window   time.Duration
+	Severity string
+	FiredAt  time.Time
+}
+
+// Summary describes the alert in one line
+func (a Alert) Summary() string {
+	return fmt.Sprintf("%s: %d %s events in the last %s", a.Rule, a.Count, a.Event, a.Window)
+}
+
+// Notifier delivers alerts to an external system
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// ruleState counts one rule's events over its window
+type ruleState struct {
+	rule      Rule
+	events    []time.Time
+	lastFired time.Time
+}
+
+// Manager counts events against the rules and notifies when a threshold
+// is crossed. After firing, a rule stays quiet for its cooldown. A nil
+// Manager ignores events.
+type Manager struct {
+	mu        sync.Mutex
+	rules     map[string][]*ruleState
+	notifiers []Notifier
+	now       func() time.Time
+}
+
+func NewManager(rules []Rule, notifiers []Notifier) *Manager {
+	m := &Manager{
+		rules:     map[string][]*ruleState{},
+		notifiers: notifiers,
+		now:       time.Now,
+	}
+	for _, rule := range rules {
+		m.rules[rule.Event] = append(m.rules[rule.Event], &ruleState{rule: rule})
+	}
+	return m
+}
+
+// Record counts one event and fires every rule it pushes over threshold
+func (m *Manager) Record(event string) {
+	if m == nil {
+		return
+	}
+
+	now := m.now()
+	var fired []Alert
+
+	m.mu.Lock()
+	for _, state := range m.rules[event] {
+		// Drop events that have left the window
+		cutoff := now.Add(-time.Duration(state.rule.Window))
+		kept := state.events[:0]
+		for _, t := range state.events {
+			if t.After(cutoff) {
+				kept = append(kept, t)
+			}
+		}
+		state.events = append(kept, now)
+
+		if len(state.events) < state.rule.Threshold {
+			continue
+		}
+		if !state.lastFired.IsZero() && now.Sub(state.lastFired) < time.Duration(state.rule.Cooldown) {
+			continue
+		}
+
+		state.lastFired = now
+		fired = append(fired, Alert{
+			Rule:     state.rule.Name,
+			Event:    event,
+			Count:    len(state.events),
+			Window:   time.Duration(state.rule.Window),
+			Severity: state.rule.Severity,
+			FiredAt:  now,
+		})
+	}
+	m.mu.Unlock()
+
+	for _, alert := range fired {
+		go m.notify(alert)
+	}
+}
+
+// notify sends the alert to every notifier. Delivery failures are logged;
+// the rule still cools down so a broken webhook cannot cause a storm.
+func (m *Manager) notify(alert Alert) {
+	log.Printf("Alert %s", alert.Summary())
+
+	for _, notifier := range m.notifiers {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := notifier.Notify(ctx, alert); err != nil {
+			log.Printf("Failed to deliver alert %s: %v", alert.Rule, err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,165 @@
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"user-management/config"
+)
+
+// Rule fires when Threshold events happen within Window
+type Rule struct {
+	Name      string          `json:"name"`
+	Event     string          `json:"event"`
+	Threshold int             `json:"threshold"`
+	Window    config.Duration `json:"window"`
+	// Cooldown keeps the rule quiet after it fires
+	Cooldown config.Duration `json:"cooldown"`
+	Severity string          `json:"severity"`
+}
+
+// Webhook is a destination for alerts
+type Webhook struct {
+	// Type is "slack" or "pagerduty"
+	Type string `json:"type"`
+	// URL is the Slack webhook, or overrides the PagerDuty endpoint
+	URL        string `json:"url,omitempty"`
+	RoutingKey string `json:"routing_key,omitempty"`
+}
+
+// Config is the alerting config file
+type Config struct {
+	Rules    []Rule    `json:"rules"`
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// DefaultConfig alerts on bursts of failed logins, lockouts and
+// registrations and on degraded HSM signing, and only logs alerts until
+// webhooks are configured
+func DefaultConfig() Config {
+	return Config{
+		Rules: []Rule{
+			{
+				Name:      "failed_login_burst",
+				Event:     EventLoginFailed,
+				Threshold: 100,
+				Window:    config.Duration(time.Minute),
+				Cooldown:  config.Duration(15 * time.Minute),
+				Severity:  SeverityWarning,
+			},
+			{
+				Name:      "lockout_burst",
+				Event:     EventAccountLocked,
+				Threshold: 20,
+				Window:    config.Duration(time.Hour),
+				Cooldown:  config.Duration(time.Hour),
+				Severity:  SeverityError,
+			},
+			{
+				Name:      "registration_spike",
+				Event:     EventUserRegistered,
+				Threshold: 500,
+				Window:    config.Duration(10 * time.Minute),
+				Cooldown:  config.Duration(time.Hour),
+				Severity:  SeverityWarning,
+			},
+			{
+				Name:      "slow_token_signing",
+				Event:     EventSigningSlow,
+				Threshold: 20,
+				Window:    config.Duration(5 * time.Minute),
+				Cooldown:  config.Duration(30 * time.Minute),
+				Severity:  SeverityWarning,
+			},
+			{
+				Name:      "token_signing_failures",
+				Event:     EventSigningFailed,
+				Threshold: 3,
+				Window:    config.Duration(5 * time.Minute),
+				Cooldown:  config.Duration(15 * time.Minute),
+				Severity:  SeverityCritical,
+			},
+		},
+	}
+}
+
+// LoadConfig reads the alerting config file at path. A missing file gives
+// DefaultConfig.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultConfig(), nil
+	} else if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks the rules and webhooks
+func (c Config) Validate() error {
+	names := map[string]bool{}
+	for _, rule := range c.Rules {
+		switch {
+		case rule.Name == "":
+			return fmt.Errorf("every rule needs a name")
+		case names[rule.Name]:
+			return fmt.Errorf("rule %s is defined twice", rule.Name)
+		case !knownEvents[rule.Event]:
+			return fmt.Errorf("rule %s: unknown event %q", rule.Name, rule.Event)
+		case rule.Threshold <= 0:
+			return fmt.Errorf("rule %s: threshold must be greater than zero", rule.Name)
+		case rule.Window <= 0:
+			return fmt.Errorf("rule %s: window must be greater than zero", rule.Name)
+		case rule.Cooldown < 0:
+			return fmt.Errorf("rule %s: cooldown must not be negative", rule.Name)
+		}
+		switch rule.Severity {
+		case SeverityInfo, SeverityWarning, SeverityError, SeverityCritical:
+		default:
+			return fmt.Errorf("rule %s: unknown severity %q", rule.Name, rule.Severity)
+		}
+		names[rule.Name] = true
+	}
+
+	for _, webhook := range c.Webhooks {
+		switch webhook.Type {
+		case "slack":
+			if webhook.URL == "" {
+				return fmt.Errorf("slack webhooks need a url")
+			}
+		case "pagerduty":
+			if webhook.RoutingKey == "" {
+				return fmt.Errorf("pagerduty webhooks need a routing_key")
+			}
+		default:
+			return fmt.Errorf("unknown webhook type %q", webhook.Type)
+		}
+	}
+	return nil
+}
+
+// Notifiers builds the notifiers for the configured webhooks. source
+// names this service in PagerDuty incidents.
+func (c Config) Notifiers(source string) []Notifier {
+	var notifiers []Notifier
+	for _, webhook := range c.Webhooks {
+		switch webhook.Type {
+		case "slack":
+			notifiers = append(notifiers, &SlackNotifier{WebhookURL: webhook.URL})
+		case "pagerduty":
+			notifiers = append(notifiers, &PagerDutyNotifier{RoutingKey: webhook.RoutingKey, URL: webhook.URL, Source: source})
+		}
+	}
+	return notifiers
+}
@@ -0,0 +1,84 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 endpoint
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// SlackNotifier posts alerts to a Slack incoming webhook
+type SlackNotifier struct {
+	WebhookURL string
+}
+
+func (n *SlackNotifier) Notify(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, n.WebhookURL, map[string]string{
+		"text": fmt.Sprintf(":rotating_light: [%s] %s", alert.Severity, alert.Summary()),
+	})
+}
+
+// PagerDutyNotifier triggers PagerDuty incidents through the Events API v2.
+// The rule name is the dedup key, so repeated firings update one incident.
+type PagerDutyNotifier struct {
+	RoutingKey string
+	// URL defaults to DefaultPagerDutyURL
+	URL string
+	// Source names this service in incidents
+	Source string
+}
+
+func (n *PagerDutyNotifier) Notify(ctx context.Context, alert Alert) error {
+	url := n.URL
+	if url == "" {
+		url = DefaultPagerDutyURL
+	}
+
+	return postJSON(ctx, url, map[string]interface{}{
+		"routing_key":  n.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    alert.Rule,
+		"payload": map[string]interface{}{
+			"summary":   alert.Summary(),
+			"source":    n.Source,
+			"severity":  alert.Severity,
+			"timestamp": alert.FiredAt.Format(time.RFC3339),
+			"custom_details": map[string]interface{}{
+				"event":  alert.Event,
+				"count":  alert.Count,
+				"window": alert.Window.String(),
+			},
+		},
+	})
+}
+
+func postJSON(ctx context.Context, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package apierrors builds gRPC errors that carry machine-readable details,
+// so clients can tell failures apart without matching on messages. Every
+// error has a google.rpc.ErrorInfo with one of the reasons below.
+package apierrors
+
+import (
+	"log"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is the ErrorInfo domain of errors from this service
+const Domain = "user-management"
+
+// Reasons reported in ErrorInfo
+const (
+	ReasonEmailTaken    = "EMAIL_TAKEN"
+	ReasonRateLimited   = "RATE_LIMITED"
+	ReasonWeakPassword  = "WEAK_PASSWORD"
+	ReasonAccountLocked = "ACCOUNT_LOCKED"
+
+	ReasonReauthenticationRequired = "REAUTHENTICATION_REQUIRED"
+	ReasonRefreshTokenReused       = "REFRESH_TOKEN_REUSED"
+
+	ReasonTwoFactorEnrollmentRequired = "TWO_FACTOR_ENROLLMENT_REQUIRED"
+)
+
+// MetadataLockedUntil is the ErrorInfo metadata key of when a temporary
+// account lock ends, in RFC 3339
+const MetadataLockedUntil = "locked_until"
+
+// New returns a status error with an ErrorInfo for reason and any extra
+// details
+func New(code codes.Code, reason, message string, details ...protoadapt.MessageV1) error {
+	return newWithMetadata(code, reason, message, nil, details...)
+}
+
+func newWithMetadata(code codes.Code, reason, message string, metadata map[string]string, details ...protoadapt.MessageV1) error {
+	st := status.New(code, message)
+	details = append([]protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reason, Domain: Domain, Metadata: metadata}}, details...)
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		// Only fails for an OK code, which is a programming error
+		log.Printf("Failed to attach error details: %v", err)
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// EmailTaken reports an email address that belongs to another account
+func EmailTaken(message string) error {
+	return New(codes.AlreadyExists, ReasonEmailTaken, message)
+}
+
+// RateLimited reports a request refused for being too frequent, with how
+// long to wait before retrying
+func RateLimited(message string, retryAfter time.Duration) error {
+	return New(codes.ResourceExhausted, ReasonRateLimited, message, &errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryAfter),
+	})
+}
+
+// AccountLocked reports a login refused because the account is locked. A
+// temporary lock carries its end, as metadata and as a RetryInfo delay.
+// Locks without an end are PERMISSION_DENIED.
+func AccountLocked(message string, lockedUntil *time.Time) error {
+	if lockedUntil == nil {
+		return New(codes.PermissionDenied, ReasonAccountLocked, message)
+	}
+	return newWithMetadata(codes.ResourceExhausted, ReasonAccountLocked, message, map[string]string{
+		MetadataLockedUntil: lockedUntil.UTC().Format(time.RFC3339),
+	}, &errdetails.RetryInfo{
+		RetryDelay: durationpb.New(time.Until(*lockedUntil)),
+	})
+}
+
+// WeakPassword reports a password that fails the strength rules, one field
+// violation per unmet requirement
+func WeakPassword(field, message string, requirements []string) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(requirements))
+	for _, requirement := range requirements {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: requirement,
+		})
+	}
+	return New(codes.InvalidArgument, ReasonWeakPassword, message, &errdetails.BadRequest{FieldViolations: violations})
+}
+
+// TwoFactorEnrollmentRequired reports a call refused until the caller sets
+// up two-factor authentication
+func TwoFactorEnrollmentRequired(message string) error {
+	return New(codes.PermissionDenied, ReasonTwoFactorEnrollmentRequired, message)
+}
+
+// ReauthenticationRequired reports a call refused because the caller signed
+// in too long ago, and must sign in again first
+func ReauthenticationRequired(message string) error {
+	return New(codes.PermissionDenied, ReasonReauthenticationRequired, message)
+}
+
+// RefreshTokenReused reports a refresh token presented after it was already
+// exchanged. Its session has been revoked, and the user must sign in again.
+func RefreshTokenReused(message string) error {
+	return New(codes.PermissionDenied, ReasonRefreshTokenReused, message)
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"user-management/database"
+	"user-management/models"
+)
+
+// Audit actions
+const (
+	ActionRecoveryVerified       = "account.recovery_verified"
+	ActionPasswordRotated        = "account.password_rotated"
+	ActionSessionsRevoked        = "account.sessions_revoked"
+	ActionDevicesRemoved         = "account.devices_removed"
+	ActionTwoFactorReset         = "account.two_factor_reset"
+	ActionRecoveryCodeUsed       = "account.recovery_code_used"
+	ActionRecoveryCodesGenerated = "account.recovery_codes_generated"
+	ActionProfileChangeApproved  = "profile.change_approved"
+	ActionProfileChangeRejected  = "profile.change_rejected"
+	ActionLoginSucceeded         = "account.login_succeeded"
+	ActionPasswordReset          = "account.password_reset"
+	ActionPasswordChanged        = "account.password_changed"
+	ActionTwoFactorResetStarted  = "account.two_factor_reset_started"
+	ActionTwoFactorResetCanceled = "account.two_factor_reset_canceled"
+	ActionTwoFactorEnabled       = "account.two_factor_enabled"
+	ActionTwoFactorDisabled      = "account.two_factor_disabled"
+	ActionPasskeyRegistered      = "account.passkey_registered"
+	ActionCredentialRenamed      = "account.credential_renamed"
+	ActionCredentialDeleted      = "account.credential_deleted"
+	ActionEmailVerified          = "account.email_verified"
+	ActionTermsAccepted          = "account.terms_accepted"
+	ActionIdentityLinked         = "account.identity_linked"
+	ActionIdentityUnlinked       = "account.identity_unlinked"
+	ActionSessionRevoked         = "account.session_revoked"
+	ActionPasswordSet            = "account.password_set"
+	ActionUserProvisioned        = "account.provisioned"
+	ActionMappedRolesChanged     = "account.mapped_roles_changed"
+	ActionUserDeprovisioned      = "account.deprovisioned"
+	ActionUserDeactivated        = "account.deactivated"
+	ActionUserReactivated        = "account.reactivated"
+	ActionUserHardDeleted        = "account.hard_deleted"
+	ActionPasswordResetByAdmin   = "account.password_reset_by_admin"
+	ActionRegistered             = "account.registered"
+	ActionLoggedOut              = "account.logged_out"
+	ActionRefreshTokenReused     = "account.refresh_token_reused"
+	ActionProfileUpdated         = "profile.updated"
+	ActionUserDeleted            = "account.deleted"
+	ActionUserRestored           = "account.restored"
+	ActionUserPurged             = "account.purged"
+	ActionUserErased             = "account.erased"
+	ActionOrgRoleChanged         = "account.org_role_changed"
+	ActionAPIKeyCreated          = "account.api_key_created"
+	ActionAPIKeyRevoked          = "account.api_key_revoked"
+	// ActionTenantIsolationViolated is a query of an organization's RPC
+	// that was not limited to the organization
+	ActionTenantIsolationViolated = "tenant.isolation_violated"
+	// ActionTenantKeyDestroyed is an organization's encryption key being
+	// destroyed, deleting its members' encrypted data
+	ActionTenantKeyDestroyed = "tenant.key_destroyed"
+)
+
+// Record writes an audit entry and applies it to the projections. Call it
+// with the context passed to database.WithTransaction so the entry is only
+// kept if the change it describes commits.
+func Record(ctx context.Context, db *database.Database, entry models.AuditLog) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	if _, err := db.AuditLogs.InsertOne(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record %s audit entry: %v", entry.Action, err)
+	}
+
+	for _, p := range projections {
+		if err := p.Apply(ctx, db, entry); err != nil {
+			return fmt.Errorf("failed to apply %s audit entry to %s: %v", entry.Action, p.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"user-management/database"
+	"user-management/models"
+)
+
+// maxLoginHistory is the number of logins kept per user
+const maxLoginHistory = 20
+
+// Projection is a read model derived from the audit log. Record applies
+// every projection as entries are written, and Replay rebuilds them from
+// the whole log.
+type Projection interface {
+	// Name identifies the projection in replay requests
+	Name() string
+	// Reset deletes everything the projection has derived
+	Reset(ctx context.Context, db *database.Database) error
+	// Apply updates the projection with one entry. Entries are applied in
+	// the order they were recorded.
+	Apply(ctx context.Context, db *database.Database, entry models.AuditLog) error
+}
+
+var projections = []Projection{
+	loginHistory{},
+	auditStats{},
+}
+
+// Projections returns every projection
+func Projections() []Projection {
+	return projections
+}
+
+// LookupProjections returns the named projections, or every projection
+// when no names are given
+func LookupProjections(names []string) ([]Projection, error) {
+	if len(names) == 0 {
+		return projections, nil
+	}
+
+	selected := make([]Projection, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, p := range projections {
+			if p.Name() == name {
+				selected = append(selected, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown projection %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// loginHistory keeps each user's recent logins in login_history
+type loginHistory struct{}
+
+func (loginHistory) Name() string { return "login_history" }
+
+func (loginHistory) Reset(ctx context.Context, db *database.Database) error {
+	_, err := db.LoginHistory.DeleteMany(ctx, bson.M{})
+	return err
+}
+
+func (loginHistory) Apply(ctx context.Context, db *database.Database, entry models.AuditLog) error {
+	if entry.Action != ActionLoginSucceeded {
+		return nil
+	}
+
+	_, err := db.LoginHistory.UpdateOne(ctx, bson.M{"_id": entry.TargetID}, bson.M{
+		"$push": bson.M{
+			"logins": bson.M{
+				"$each": []models.LoginRecord{{
+					IPAddress: entry.IPAddress,
+					UserAgent: entry.UserAgent,
+					At:        entry.CreatedAt,
+				}},
+				"$slice": -maxLoginHistory,
+			},
+		},
+		"$inc": bson.M{"login_count": 1},
+		"$max": bson.M{"last_login_at": entry.CreatedAt},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+// auditStats counts entries per action and day in audit_stats
+type auditStats struct{}
+
+func (auditStats) Name() string { return "audit_stats" }
+
+func (auditStats) Reset(ctx context.Context, db *database.Database) error {
+	_, err := db.AuditStats.DeleteMany(ctx, bson.M{})
+	return err
+}
+
+func (auditStats) Apply(ctx context.Context, db *database.Database, entry models.AuditLog) error {
+	key := models.AuditStatKey{
+		Day:    entry.CreatedAt.UTC().Format("2006-01-02"),
+		Action: entry.Action,
+	}
+
+	_, err := db.AuditStats.UpdateOne(ctx, bson.M{"_id": key}, bson.M{
+		"$inc": bson.M{"count": 1},
+	}, options.Update().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"user-management/database"
+	"user-management/models"
+)
+
+// ReplayProgress reports how far a replay has got
+type ReplayProgress struct {
+	// Processed is the number of entries applied by this batch
+	Processed int
+	// LastID is the last entry applied, where the next batch starts
+	LastID primitive.ObjectID
+	// UntilID is the last entry the replay covers
+	UntilID primitive.ObjectID
+	// Remaining is the number of entries still to apply
+	Remaining int64
+	Done      bool
+}
+
+// StartReplay resets the projections and returns the last entry the replay
+// must apply. Entries recorded after this point are applied by Record, so
+// the replay stops here to avoid applying them twice.
+func StartReplay(ctx context.Context, db *database.Database, projections []Projection) (primitive.ObjectID, error) {
+	var last models.AuditLog
+	err := db.AuditLogs.FindOne(ctx, bson.M{}, options.FindOne().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetProjection(bson.M{"_id": 1})).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return primitive.NilObjectID, fmt.Errorf("failed to find the last audit entry: %v", err)
+	}
+
+	for _, p := range projections {
+		if err := p.Reset(ctx, db); err != nil {
+			return primitive.NilObjectID, fmt.Errorf("failed to reset %s: %v", p.Name(), err)
+		}
+	}
+	return last.ID, nil
+}
+
+// ReplayBatch applies up to batchSize entries after afterID, up to and
+// including untilID, to the projections
+func ReplayBatch(ctx context.Context, db *database.Database, projections []Projection, afterID, untilID primitive.ObjectID, batchSize int) (ReplayProgress, error) {
+	progress := ReplayProgress{LastID: afterID, UntilID: untilID}
+	if untilID.IsZero() {
+		progress.Done = true
+		return progress, nil
+	}
+
+	rangeFilter := bson.M{"$lte": untilID}
+	if !afterID.IsZero() {
+		rangeFilter["$gt"] = afterID
+	}
+
+	cursor, err := db.AuditLogs.Find(ctx, bson.M{"_id": rangeFilter}, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(batchSize)))
+	if err != nil {
+		return progress, fmt.Errorf("failed to find audit entries: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var entry models.AuditLog
+		if err := cursor.Decode(&entry); err != nil {
+			return progress, fmt.Errorf("failed to decode audit entry: %v", err)
+		}
+
+		for _, p := range projections {
+			if err := p.Apply(ctx, db, entry); err != nil {
+				return progress, fmt.Errorf("failed to apply audit entry %s to %s: %v", entry.ID.Hex(), p.Name(), err)
+			}
+		}
+		progress.Processed++
+		progress.LastID = entry.ID
+	}
+	if err := cursor.Err(); err != nil {
+		return progress, fmt.Errorf("failed to read audit entries: %v", err)
+	}
+
+	progress.Remaining, err = db.AuditLogs.CountDocuments(ctx, bson.M{
+		"_id": bson.M{"$gt": progress.LastID, "$lte": untilID},
+	})
+	if err != nil {
+		return progress, fmt.Errorf("failed to count remaining audit entries: %v", err)
+	}
+	progress.Done = progress.Remaining == 0
+	return progress, nil
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Action token purposes
+const (
+	PurposeDownloadExport  = "download_export"
+	PurposeConfirmDeletion = "confirm_deletion"
+)
+
+const (
+	DefaultActionTokenTTL = 5 * time.Minute
+	MaxActionTokenTTL     = 15 * time.Minute
+)
+
+var (
+	ErrUnknownPurpose  = errors.New("unknown token purpose")
+	ErrPurposeMismatch = errors.New("token was issued for a different purpose")
+)
+
+var actionPurposes = map[string]bool{
+	PurposeDownloadExport:  true,
+	PurposeConfirmDeletion: true,
+}
+
+// IsActionPurpose reports whether purpose is a known action token purpose
+func IsActionPurpose(purpose string) bool {
+	return actionPurposes[purpose]
+}
+
+// GenerateActionToken issues a short-lived token that authorizes a single
+// kind of action, optionally bound to one resource (e.g. an export ID). The
+// TTL is capped at MaxActionTokenTTL.
+func (j *JWTService) GenerateActionToken(userID, email, purpose, resource string, ttl time.Duration) (string, time.Time, error) {
+	if !IsActionPurpose(purpose) {
+		return "", time.Time{}, ErrUnknownPurpose
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultActionTokenTTL
+	}
+	if ttl > MaxActionTokenTTL {
+		ttl = MaxActionTokenTTL
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := JWTClaims{
+		Type:     TokenTypeAction,
+		UserID:   userID,
+		Email:    email,
+		Purpose:  purpose,
+		Resource: resource,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   userID,
+			// Unique, so two links issued in the same second differ.
+			// Tokens aren't looked up by it: a used link stays valid until
+			// it expires, unless its token is blacklisted.
+			ID: primitive.NewObjectID().Hex(),
+		},
+	}
+
+	signed, err := j.signClaims(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ValidateActionToken validates a token minted by GenerateActionToken and
+// checks that it was issued for the expected purpose
+func (j *JWTService) ValidateActionToken(tokenString, purpose string) (_ *JWTClaims, err error) {
+	defer func() { observeValidation(TokenTypeAction, err) }()
+
+	claims, err := j.parseToken(context.Background(), tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Type != TokenTypeAction {
+		return nil, ErrInvalidToken
+	}
+	if claims.Purpose != purpose {
+		return nil, ErrPurposeMismatch
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"user-management/database"
+	"user-management/models"
+)
+
+// newTestService returns a JWTService that keeps its data in repos
+func newTestService(t *testing.T, repos database.Repositories) *JWTService {
+	t.Helper()
+	j, err := NewJWTService("test-secret", nil, time.Hour, 0, TokenVersions{
+		Issue:       CurrentTokenVersion,
+		MinAccepted: TokenVersion1,
+	})
+	if err != nil {
+		t.Fatalf("NewJWTService() = %v", err)
+	}
+	j.SetRepositories(repos)
+	return j
+}
+
+// newTestAPIKey stores a key of userID created at createdAt
+func newTestAPIKey(t *testing.T, repos database.Repositories, userID models.ID, createdAt time.Time) string {
+	t.Helper()
+	key, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey() = %v", err)
+	}
+	err = repos.APIKeys.Insert(context.Background(), models.APIKey{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Name:      "test",
+		KeyHash:   hash,
+		CreatedAt: createdAt,
+	})
+	if err != nil {
+		t.Fatalf("Insert() = %v", err)
+	}
+	return key
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-time.Hour)
+	tests := []struct {
+		name    string
+		user    models.User
+		expired bool
+		wantErr error
+	}{
+		{
+			name: "active account",
+			user: models.User{IsActive: true},
+		},
+		{
+			name:    "deactivated account",
+			user:    models.User{IsActive: false},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name:    "deleted account",
+			user:    models.User{IsActive: true, IsDeleted: true},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name:    "expired key",
+			user:    models.User{IsActive: true},
+			expired: true,
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name:    "tokens revoked after the key was created",
+			user:    models.User{IsActive: true, TokensValidAfter: &now},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "tokens revoked before the key was created",
+			user: models.User{IsActive: true, TokensValidAfter: &before},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := database.NewMemoryRepositories()
+			j := newTestService(t, repos)
+
+			user := tt.user
+			user.ID = models.ID(primitive.NewObjectID().Hex())
+			user.Email = "jane@example.com"
+			if err := repos.Users.Insert(ctx, user); err != nil {
+				t.Fatalf("Insert() = %v", err)
+			}
+			key, hash, err := GenerateAPIKey()
+			if err != nil {
+				t.Fatalf("GenerateAPIKey() = %v", err)
+			}
+			apiKey := models.APIKey{
+				ID:        primitive.NewObjectID(),
+				UserID:    user.ID,
+				KeyHash:   hash,
+				CreatedAt: now.Add(-time.Minute),
+			}
+			if tt.expired {
+				expiresAt := now.Add(-time.Second)
+				apiKey.ExpiresAt = &expiresAt
+			}
+			if err := repos.APIKeys.Insert(ctx, apiKey); err != nil {
+				t.Fatalf("Insert() = %v", err)
+			}
+
+			got, err := j.AuthenticateAPIKey(ctx, key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AuthenticateAPIKey() = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.ID != user.ID {
+				t.Errorf("AuthenticateAPIKey() = user %s, want %s", got.ID, user.ID)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKeyUnknown(t *testing.T) {
+	j := newTestService(t, database.NewMemoryRepositories())
+	for _, key := range []string{"not-a-key", APIKeyPrefix + "unknown"} {
+		if _, err := j.AuthenticateAPIKey(context.Background(), key); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("AuthenticateAPIKey(%q) = %v, want %v", key, err, ErrInvalidAPIKey)
+		}
+	}
+}
+
+// Revoking every token of a user, as signing out everywhere and password
+// resets do, revokes the API keys they had. Keys created afterwards work.
+func TestAPIKeyRevokedWithTokens(t *testing.T) {
+	ctx := context.Background()
+	repos := database.NewMemoryRepositories()
+	j := newTestService(t, repos)
+
+	userID := models.ID(primitive.NewObjectID().Hex())
+	err := repos.Users.Insert(ctx, models.User{ID: userID, Email: "jane@example.com", IsActive: true})
+	if err != nil {
+		t.Fatalf("Insert() = %v", err)
+	}
+	key := newTestAPIKey(t, repos, userID, time.Now().Add(-time.Minute))
+	if _, err := j.AuthenticateAPIKey(ctx, key); err != nil {
+		t.Fatalf("AuthenticateAPIKey() before revoking = %v, want nil", err)
+	}
+
+	if err := j.InvalidateAllTokens(ctx, userID.String()); err != nil {
+		t.Fatalf("InvalidateAllTokens() = %v", err)
+	}
+	if _, err := j.AuthenticateAPIKey(ctx, key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("AuthenticateAPIKey() after revoking = %v, want %v", err, ErrInvalidAPIKey)
+	}
+
+	newKey := newTestAPIKey(t, repos, userID, time.Now().Add(time.Millisecond))
+	if _, err := j.AuthenticateAPIKey(ctx, newKey); err != nil {
+		t.Errorf("AuthenticateAPIKey() with a key created after revoking = %v, want nil", err)
+	}
+}
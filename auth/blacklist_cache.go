@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"user-management/metrics"
+	"user-management/models"
+	"user-management/utils"
+)
+
+// Redis keys of the blacklist cache. Tokens are stored by their hash.
+const (
+	blacklistKeyPrefix = "auth:blacklist:"
+	// blacklistReadyKey is set once the cache holds the whole blacklist.
+	// Redis losing its data loses the key too, which sends lookups back to
+	// MongoDB until the cache is filled again.
+	blacklistReadyKey = "auth:blacklist-ready"
+)
+
+// blacklistCacheTimeout bounds each Redis call, well below a MongoDB
+// lookup, so a slow cache falls back instead of adding latency
+const blacklistCacheTimeout = 50 * time.Millisecond
+
+// BlacklistCache holds the token blacklist in Redis so validation doesn't
+// query MongoDB, which stays the source of truth. Each entry expires with
+// its token. Lookups fall back to MongoDB while the cache is being filled
+// or Redis is unreachable.
+type BlacklistCache struct {
+	client redis.UniversalClient
+	// stale is set when an invalidated token could not be added, so the
+	// cache is missing it until it is filled again
+	stale atomic.Bool
+}
+
+func NewBlacklistCache(client redis.UniversalClient) *BlacklistCache {
+	return &BlacklistCache{client: client}
+}
+
+// SetBlacklistCache makes token validation check cache before MongoDB.
+// Start RunBlacklistCache to fill it.
+func (j *JWTService) SetBlacklistCache(cache *BlacklistCache) {
+	j.blacklistCache = cache
+}
+
+// lookup reports whether token is blacklisted. ok is false when the cache
+// can't answer and MongoDB must be asked.
+func (c *BlacklistCache) lookup(ctx context.Context, token string) (blacklisted, ok bool) {
+	if c.stale.Load() {
+		metrics.TokenBlacklistCacheLookups.WithLabelValues("cold").Inc()
+		return false, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, blacklistCacheTimeout)
+	defer cancel()
+
+	values, err := c.client.MGet(ctx, blacklistKeyPrefix+utils.HashToken(token), blacklistReadyKey).Result()
+	if err != nil {
+		metrics.TokenBlacklistCacheLookups.WithLabelValues("error").Inc()
+		return false, false
+	}
+	if values[1] == nil {
+		metrics.TokenBlacklistCacheLookups.WithLabelValues("cold").Inc()
+		return false, false
+	}
+
+	if values[0] != nil {
+		metrics.TokenBlacklistCacheLookups.WithLabelValues("hit").Inc()
+		return true, true
+	}
+	metrics.TokenBlacklistCacheLookups.WithLabelValues("miss").Inc()
+	return false, true
+}
+
+// add caches a token invalidated until expiresAt. A failure marks the
+// cache stale, so lookups skip it until it is filled again.
+func (c *BlacklistCache) add(ctx context.Context, token string, expiresAt time.Time) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, blacklistCacheTimeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, blacklistKeyPrefix+utils.HashToken(token), 1, ttl).Err(); err != nil {
+		log.Printf("Failed to cache invalidated token, falling back to MongoDB until the cache is refilled: %v", err)
+		c.stale.Store(true)
+	}
+}
+
+// RunBlacklistCache fills the blacklist cache from MongoDB, then checks it
+// every interval until ctx is cancelled, filling it again whenever Redis
+// lost its data or an invalidated token couldn't be added
+func (j *JWTService) RunBlacklistCache(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.refreshBlacklistCache(ctx); err != nil {
+			log.Printf("Failed to fill the token blacklist cache: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshBlacklistCache fills the cache when it isn't ready or is stale
+func (j *JWTService) refreshBlacklistCache(ctx context.Context) error {
+	c := j.blacklistCache
+	stale := c.stale.Load()
+	if !stale {
+		n, err := c.client.Exists(ctx, blacklistReadyKey).Result()
+		if err != nil {
+			return err
+		}
+		if n == 1 {
+			return nil
+		}
+	}
+
+	// Other instances stop trusting the cache while it is refilled, since
+	// it may be missing the tokens this one failed to add
+	if stale {
+		if err := c.client.Del(ctx, blacklistReadyKey).Err(); err != nil {
+			return err
+		}
+	}
+
+	pipe := c.client.Pipeline()
+	count := 0
+	err := j.tokens.EachUnexpired(ctx, func(token models.InvalidatedToken) error {
+		if ttl := time.Until(token.ExpiresAt); ttl > 0 {
+			pipe.Set(ctx, blacklistKeyPrefix+utils.HashToken(token.Token), 1, ttl)
+			count++
+		}
+		if pipe.Len() >= 1000 {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	pipe.Set(ctx, blacklistReadyKey, 1, 0)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to write the cache: %v", err)
+	}
+
+	c.stale.Store(false)
+	log.Printf("Filled the token blacklist cache with %d tokens", count)
+	return nil
+}
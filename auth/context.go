@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+var ErrMissingToken = errors.New("missing bearer token")
+
+// BearerTokenFromContext extracts the token from the "authorization" metadata
+// of an incoming request, accepting the "Bearer <token>" form
+func BearerTokenFromContext(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ErrMissingToken
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", ErrMissingToken
+	}
+
+	return BearerToken(values[0])
+}
+
+// BearerToken extracts the token from an Authorization header value,
+// accepting the "Bearer <token>" form
+func BearerToken(authorization string) (string, error) {
+	token := strings.TrimSpace(authorization)
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	token = strings.Trim(token, `"`)
+	if token == "" {
+		return "", ErrMissingToken
+	}
+
+	return token, nil
+}
+
+// AuthenticateContext validates the bearer token of an incoming request
+func (j *JWTService) AuthenticateContext(ctx context.Context) (*JWTClaims, error) {
+	token, err := BearerTokenFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return j.validateToken(ctx, token)
+}
+
+// AuthenticateHeader validates the bearer token of an Authorization header
+// value, for requests that don't arrive as gRPC calls
+func (j *JWTService) AuthenticateHeader(ctx context.Context, authorization string) (*JWTClaims, error) {
+	token, err := BearerToken(authorization)
+	if err != nil {
+		return nil, err
+	}
+	return j.validateToken(ctx, token)
+}
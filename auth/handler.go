@@ -2,50 +2,125 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"user-management/database"
+	"user-management/metrics"
 	"user-management/models"
+	"user-management/session"
 )
 
 var (
 	ErrInvalidToken     = errors.New("invalid token")
 	ErrTokenExpired     = errors.New("token expired")
 	ErrTokenBlacklisted = errors.New("token has been invalidated")
+	ErrTokenRevoked     = errors.New("token was revoked for all devices")
 )
 
 type JWTClaims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	// Version is the token format version, see TokenVersions
+	Version int    `json:"ver,omitempty"`
+	Type    string `json:"typ,omitempty"`
+	UserID  string `json:"user_id"`
+	Email   string `json:"email"`
+	// Purpose and Resource are only set on action tokens
+	Purpose  string `json:"purpose,omitempty"`
+	Resource string `json:"resource,omitempty"`
+	// Scope limits a session token to the RPCs of one flow, see
+	// ScopeTwoFactorEnrollment
+	Scope string `json:"scope,omitempty"`
+	// AuthMethod is how the user signed in to the session, and AuthTime
+	// when. Refreshed tokens keep both.
+	AuthMethod string           `json:"auth_method,omitempty"`
+	AuthTime   *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type JWTService struct {
 	secretKey []byte
-	db        *database.Database
-	tokenTTL  time.Duration
+	// previousSecrets verify tokens signed before the secret was rotated
+	previousSecrets [][]byte
+	// The repositories are those of the database, or others set with
+	// SetRepositories
+	users         database.UserRepository
+	tokens        database.TokenRepository
+	apiKeys       database.APIKeyRepository
+	sessions      database.SessionRepository
+	refreshTokens database.RefreshTokenRepository
+	tokenTTL      time.Duration
+	// idleTimeout ends sessions that go unused for this long before they
+	// expire. Zero disables it.
+	idleTimeout time.Duration
+	versions    TokenVersions
+	// blacklistCache answers blacklist lookups before MongoDB when set
+	blacklistCache *BlacklistCache
+	// refreshTTL is how long refresh tokens last. Zero issues none.
+	refreshTTL time.Duration
+
+	// signingKey signs tokens in a KMS instead of secretKey when set. The
+	// tokens it verified are kept in verifiedTokens, by hash, until they
+	// expire.
+	signingKey     MACKey
+	verifiedMu     sync.Mutex
+	verifiedTokens map[[sha256.Size]byte]time.Time
+	// signer signs tokens in an HSM or with a key file instead of
+	// secretKey when set, with the key ID signerKeyID. Tokens are verified
+	// with the public key of their key ID in publicKeys.
+	signer      Signer
+	signerKeyID string
+	publicKeys  map[string]publicKey
 }
 
-func NewJWTService(secretKey string, db *database.Database, tokenTTL time.Duration) *JWTService {
-	return &JWTService{
-		secretKey: []byte(secretKey),
-		db:        db,
-		tokenTTL:  tokenTTL,
+func NewJWTService(secretKey string, db *database.Database, tokenTTL, idleTimeout time.Duration, versions TokenVersions) (*JWTService, error) {
+	if err := versions.validate(); err != nil {
+		return nil, err
+	}
+
+	j := &JWTService{
+		secretKey:   []byte(secretKey),
+		tokenTTL:    tokenTTL,
+		idleTimeout: idleTimeout,
+		versions:    versions,
 	}
+	if db != nil {
+		j.SetRepositories(db.Repositories())
+	}
+	return j, nil
+}
+
+// SetRepositories replaces the repositories the blacklist, revocations,
+// API keys, sessions and refresh tokens are kept in, such as with
+// database.NewMemoryRepositories in tests
+func (j *JWTService) SetRepositories(repos database.Repositories) {
+	j.users = repos.Users
+	j.tokens = repos.Tokens
+	j.apiKeys = repos.APIKeys
+	j.sessions = repos.Sessions
+	j.refreshTokens = repos.RefreshTokens
 }
 
-func (j *JWTService) GenerateToken(userID string, email string) (string, error) {
+// GenerateToken issues a session token and records its session. With
+// refresh tokens enabled, it also issues the session's first refresh token,
+// and the session lasts as long as it is refreshed.
+func (j *JWTService) GenerateToken(ctx context.Context, userID string, email string, info session.Info) (token, refreshToken string, err error) {
 	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
+		Type:       TokenTypeSession,
+		UserID:     userID,
+		Email:      email,
+		AuthMethod: info.Method,
+		AuthTime:   jwt.NewNumericDate(time.Now()),
 		RegisteredClaims: jwt.RegisteredClaims{
+			// The jti is the session ID. Revoking the session rejects the
+			// token; single tokens are blacklisted by their whole string.
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.tokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -53,81 +128,212 @@ func (j *JWTService) GenerateToken(userID string, email string) (string, error)
 		},
 	}
 
+	token, err = j.signClaims(claims)
+	if err != nil {
+		return "", "", err
+	}
+	expiresAt := claims.ExpiresAt.Time
+	if j.refreshTTL > 0 {
+		expiresAt = claims.IssuedAt.Add(j.refreshTTL)
+	}
+	if err := j.createSession(ctx, &claims, info, expiresAt); err != nil {
+		return "", "", err
+	}
+
+	if j.refreshTTL > 0 {
+		refreshToken, err = j.issueRefreshToken(ctx, claims.ID, userID, claims.IssuedAt.Time)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return token, refreshToken, nil
+}
+
+// signClaims signs claims in the configured issue version
+func (j *JWTService) signClaims(claims JWTClaims) (string, error) {
+	tokenType := claims.Type
+	j.downgradeClaims(&claims)
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secretKey)
+	signed, err := j.sign(token)
+	if err != nil {
+		return "", err
+	}
+
+	metrics.TokensIssued.WithLabelValues(tokenTypeLabel(tokenType)).Inc()
+	return signed, nil
 }
 
+// ValidateToken validates a session token. Action tokens are rejected so a
+// link sent by email can never be used as a login, and so are scoped
+// tokens.
 func (j *JWTService) ValidateToken(tokenString string) (*JWTClaims, error) {
+	return j.validateToken(context.Background(), tokenString)
+}
+
+func (j *JWTService) validateToken(ctx context.Context, tokenString string) (*JWTClaims, error) {
+	return j.validateSessionToken(ctx, tokenString, "")
+}
+
+// validateSessionToken validates a session token that is either unscoped
+// or limited to scope
+func (j *JWTService) validateSessionToken(ctx context.Context, tokenString, scope string) (_ *JWTClaims, err error) {
+	defer func() { observeValidation(TokenTypeSession, err) }()
+
+	claims, err := j.parseToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Type != TokenTypeSession {
+		return nil, ErrInvalidToken
+	}
+	if claims.Scope != "" && claims.Scope != scope {
+		return nil, ErrTokenScopeRestricted
+	}
+
+	if err := j.checkSession(ctx, claims, true); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// parseToken checks the blacklist and verifies the signature and expiry of
+// any token issued by this service. Claims from older token versions are
+// upgraded to the current format.
+func (j *JWTService) parseToken(ctx context.Context, tokenString string) (*JWTClaims, error) {
 	// First check if token is blacklisted
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	var invalidatedToken models.InvalidatedToken
-	err := j.db.Tokens.FindOne(ctx, bson.M{"token": tokenString}).Decode(&invalidatedToken)
-	if err == nil {
+	blacklisted, err := j.isBlacklisted(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if blacklisted {
+		metrics.TokenBlacklistLookups.WithLabelValues("hit").Inc()
 		return nil, ErrTokenBlacklisted
-	} else if err != mongo.ErrNoDocuments {
-		return nil, fmt.Errorf("error checking token blacklist: %v", err)
 	}
+	metrics.TokenBlacklistLookups.WithLabelValues("miss").Inc()
 
 	// Parse and validate token
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return j.secretKey, nil
-	})
-
-	if err != nil {
+	claims := &JWTClaims{}
+	if err := j.parseClaims(ctx, tokenString, claims); err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrTokenExpired
 		}
 		return nil, ErrInvalidToken
 	}
 
-	claims, ok := token.Claims.(*JWTClaims)
-	if !ok || !token.Valid {
-		return nil, ErrInvalidToken
+	if err := j.upgradeClaims(claims); err != nil {
+		return nil, err
+	}
+
+	if err := j.checkRevokedForUser(ctx, claims); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
 }
 
+// isBlacklisted asks the blacklist cache, if any, and MongoDB when the
+// cache can't answer
+func (j *JWTService) isBlacklisted(ctx context.Context, tokenString string) (bool, error) {
+	if j.blacklistCache != nil {
+		if blacklisted, ok := j.blacklistCache.lookup(ctx, tokenString); ok {
+			return blacklisted, nil
+		}
+	}
+
+	invalidated, err := j.tokens.IsInvalidated(ctx, tokenString)
+	if err != nil {
+		return false, fmt.Errorf("error checking token blacklist: %v", err)
+	}
+	return invalidated, nil
+}
+
+// checkRevokedForUser rejects tokens issued at or before the user's
+// tokens_valid_after. Token issue times only have second precision, so a
+// token issued in the same second as the revocation is also rejected.
+func (j *JWTService) checkRevokedForUser(ctx context.Context, claims *JWTClaims) error {
+	userID, err := models.ParseID(claims.UserID)
+	if err != nil || claims.IssuedAt == nil {
+		return ErrInvalidToken
+	}
+
+	revoked, err := j.users.TokensRevoked(ctx, userID, claims.IssuedAt.Time)
+	if err != nil {
+		return fmt.Errorf("error checking token revocation: %v", err)
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
 func (j *JWTService) InvalidateToken(tokenString string, userID string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	// Parse token to get expiry time
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return j.secretKey, nil
-	})
+	var claims JWTClaims
+	err := j.parseClaims(ctx, tokenString, &claims)
 
 	var expiryTime time.Time
+	var sessionID string
 	if err == nil {
-		if claims, ok := token.Claims.(*JWTClaims); ok {
-			expiryTime = claims.ExpiresAt.Time
-		}
+		expiryTime = claims.ExpiresAt.Time
+		sessionID = claims.ID
 	} else {
 		// If we can't parse, set expiry to current time + token TTL
 		expiryTime = time.Now().Add(j.tokenTTL)
 	}
 
-	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	parsedUserID, err := models.ParseID(userID)
 	if err != nil {
 		return fmt.Errorf("invalid user ID: %v", err)
 	}
 
 	invalidatedToken := models.InvalidatedToken{
 		Token:     tokenString,
-		UserID:    userObjectID,
+		UserID:    parsedUserID,
 		ExpiresAt: expiryTime,
 		CreatedAt: time.Now(),
 	}
 
-	_, err = j.db.Tokens.InsertOne(ctx, invalidatedToken)
+	err = j.tokens.Insert(ctx, invalidatedToken)
 	if err != nil {
 		return fmt.Errorf("failed to invalidate token: %v", err)
 	}
+	if j.blacklistCache != nil {
+		j.blacklistCache.add(ctx, tokenString, expiryTime)
+	}
+
+	// The session ends with its token
+	if sessionID != "" {
+		err = j.RevokeSession(ctx, parsedUserID, sessionID)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return fmt.Errorf("failed to revoke session: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// InvalidateAllTokens revokes every token issued to the user so far by
+// moving their tokens_valid_after to now, without storing the tokens
+func (j *JWTService) InvalidateAllTokens(ctx context.Context, userID string) error {
+	parsedUserID, err := models.ParseID(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %v", err)
+	}
+
+	// Deleted accounts had their tokens revoked when they were deleted
+	err = j.users.RevokeTokens(ctx, parsedUserID, time.Now())
+	if err != nil && err != database.ErrNotFound {
+		return fmt.Errorf("failed to revoke tokens: %v", err)
+	}
 
 	return nil
 }
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+)
+
+// jwksMaxAge is how long clients may cache the key set. Verifiers that see
+// an unknown key ID fetch it again sooner.
+const jwksMaxAge = 300
+
+// jsonWebKey is the JWK of an RSA or P-256 public key
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func newJSONWebKey(kid, algorithm string, key crypto.PublicKey) (jsonWebKey, error) {
+	jwk := jsonWebKey{Kid: kid, Use: "sig", Alg: algorithm}
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeBigInt(key.N)
+		jwk.E = encodeBigInt(big.NewInt(int64(key.E)))
+	case *ecdsa.PublicKey:
+		jwk.Kty = "EC"
+		jwk.Crv = key.Curve.Params().Name
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk.X = base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size)))
+	default:
+		return jsonWebKey{}, fmt.Errorf("unsupported public key type %T", key)
+	}
+	return jwk, nil
+}
+
+// thumbprint returns the RFC 7638 thumbprint of the key, its key ID
+func (k jsonWebKey) thumbprint() string {
+	// The required members in lexicographic order
+	var members string
+	if k.Kty == "RSA" {
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	} else {
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, k.Crv, k.X, k.Y)
+	}
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+// JWKS returns the public keys tokens are verified with as a JSON Web Key
+// Set, so other services can verify tokens without calling this one.
+// Tokens signed with the secret or by the KMS can't be verified this way.
+func (j *JWTService) JWKS() ([]byte, error) {
+	set := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{Keys: []jsonWebKey{}}
+	for kid, key := range j.publicKeys {
+		jwk, err := newJSONWebKey(kid, key.algorithm, key.key)
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	// The signing key first
+	sort.Slice(set.Keys, func(a, b int) bool {
+		if (set.Keys[a].Kid == j.signerKeyID) != (set.Keys[b].Kid == j.signerKeyID) {
+			return set.Keys[a].Kid == j.signerKeyID
+		}
+		return set.Keys[a].Kid < set.Keys[b].Kid
+	})
+	return json.Marshal(set)
+}
+
+// JWKSHandler serves JWKS
+func (j *JWTService) JWKSHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := j.JWKS()
+		if err != nil {
+			http.Error(w, "failed to encode keys", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/jwk-set+json")
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", jwksMaxAge))
+		w.Write(body)
+	})
+}
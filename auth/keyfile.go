@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted for RS256
+const minRSAKeyBits = 2048
+
+// KeyFile is a private key read from a PEM file. An RSA key signs RS256
+// tokens and a P-256 key ES256 tokens.
+type KeyFile struct {
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+// LoadKeyFile reads a PKCS #8, PKCS #1 or SEC 1 private key from the PEM
+// file at path
+func LoadKeyFile(path string) (*KeyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s holds no PEM block", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s holds a %s, not a private key", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key in %s: %v", path, err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key in %s must have at least %d bits", path, minRSAKeyBits)
+		}
+		return &KeyFile{method: jwt.SigningMethodRS256, key: key}, nil
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ECDSA key in %s must be on the P-256 curve", path)
+		}
+		return &KeyFile{method: jwt.SigningMethodES256, key: key}, nil
+	}
+	return nil, fmt.Errorf("%s holds neither an RSA nor an ECDSA key", path)
+}
+
+// Algorithm returns the JWS algorithm, RS256 or ES256
+func (k *KeyFile) Algorithm() string {
+	return k.method.Alg()
+}
+
+func (k *KeyFile) Public() crypto.PublicKey {
+	return k.key.Public()
+}
+
+func (k *KeyFile) Sign(ctx context.Context, message []byte) ([]byte, error) {
+	return k.method.Sign(string(message), k.key)
+}
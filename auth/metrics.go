@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"user-management/metrics"
+)
+
+// Rejection reasons used as metric labels. Every rejection maps to one of
+// these so label cardinality stays fixed.
+const (
+	reasonExpired            = "expired"
+	reasonIdle               = "idle"
+	reasonBlacklisted        = "blacklisted"
+	reasonRevoked            = "revoked"
+	reasonReused             = "reused"
+	reasonUnsupportedVersion = "unsupported_version"
+	reasonPurposeMismatch    = "purpose_mismatch"
+	reasonScopeRestricted    = "scope_restricted"
+	reasonInvalid            = "invalid"
+	reasonError              = "error"
+)
+
+// rejectionReason maps a validation error to a metric label
+func rejectionReason(err error) string {
+	switch {
+	case errors.Is(err, ErrTokenExpired):
+		return reasonExpired
+	case errors.Is(err, ErrSessionIdle):
+		return reasonIdle
+	case errors.Is(err, ErrTokenBlacklisted):
+		return reasonBlacklisted
+	case errors.Is(err, ErrTokenRevoked):
+		return reasonRevoked
+	case errors.Is(err, ErrRefreshTokenReused):
+		return reasonReused
+	case errors.Is(err, ErrUnsupportedTokenVersion):
+		return reasonUnsupportedVersion
+	case errors.Is(err, ErrPurposeMismatch), errors.Is(err, ErrUnknownPurpose):
+		return reasonPurposeMismatch
+	case errors.Is(err, ErrTokenScopeRestricted):
+		return reasonScopeRestricted
+	case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrInvalidAPIKey):
+		return reasonInvalid
+	default:
+		// Database failures while checking the blacklist or revocation
+		return reasonError
+	}
+}
+
+// apiKeyLabel is the type label of API keys, which aren't tokens but are
+// counted with them
+const apiKeyLabel = "api_key"
+
+// tokenTypeLabel bounds the type label to the known token types
+func tokenTypeLabel(tokenType string) string {
+	switch tokenType {
+	case TokenTypeSession, TokenTypeAction, TokenTypeRefresh, apiKeyLabel:
+		return tokenType
+	default:
+		return "other"
+	}
+}
+
+// observeValidation counts a rejected token of the expected type
+func observeValidation(tokenType string, err error) {
+	if err != nil {
+		metrics.TokensRejected.WithLabelValues(tokenTypeLabel(tokenType), rejectionReason(err)).Inc()
+	}
+}
+
+// RunBlacklistMetrics reports the size of the token blacklist every
+// interval until ctx is cancelled. Expired entries are removed by a TTL
+// index, so steady growth means tokens are being invalidated faster than
+// they expire.
+func (j *JWTService) RunBlacklistMetrics(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := j.tokens.Count(ctx)
+		if err != nil {
+			log.Printf("Failed to count blacklisted tokens: %v", err)
+		} else {
+			metrics.TokenBlacklistSize.Set(float64(count))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
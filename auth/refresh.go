@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"user-management/metrics"
+	"user-management/models"
+	"user-management/session"
+)
+
+// ErrRefreshTokenReused is returned when a refresh token that was already
+// exchanged is presented again, which means it was copied. The session it
+// belongs to is revoked.
+var ErrRefreshTokenReused = session.ErrRefreshTokenReused
+
+// SetRefreshTokenTTL makes GenerateToken issue refresh tokens lasting ttl
+// with session tokens. Zero, the default, issues none.
+func (j *JWTService) SetRefreshTokenTTL(ttl time.Duration) {
+	j.refreshTTL = ttl
+}
+
+// issueRefreshToken stores a new refresh token of a session
+func (j *JWTService) issueRefreshToken(ctx context.Context, sessionID, userID string, now time.Time) (string, error) {
+	parsedUserID, err := models.ParseID(userID)
+	if err != nil {
+		return "", fmt.Errorf("invalid user ID: %v", err)
+	}
+	refreshToken, err := j.sessionStore().IssueRefreshToken(ctx, parsedUserID, sessionID, now)
+	if err != nil {
+		return "", err
+	}
+	metrics.TokensIssued.WithLabelValues(TokenTypeRefresh).Inc()
+	return refreshToken, nil
+}
+
+// Refresh exchanges a refresh token for a new session token of its session
+// and the session's next refresh token. Each refresh token is exchanged
+// once. Presenting one again returns ErrRefreshTokenReused, with the user
+// and session it belonged to set: the session and all its refresh tokens
+// are revoked, and the account is flagged.
+func (j *JWTService) Refresh(ctx context.Context, refreshToken string) (_ session.Refreshed, err error) {
+	defer func() { observeValidation(TokenTypeRefresh, err) }()
+
+	if j.refreshTTL <= 0 {
+		return session.Refreshed{}, ErrInvalidToken
+	}
+
+	refreshed, err := j.sessionStore().Exchange(ctx, refreshToken)
+	if err != nil {
+		return refreshed, sessionError(err)
+	}
+	metrics.TokensIssued.WithLabelValues(TokenTypeRefresh).Inc()
+
+	user, err := j.users.FindByID(ctx, refreshed.UserID)
+	if err == mongo.ErrNoDocuments {
+		return session.Refreshed{}, ErrInvalidToken
+	}
+	if err != nil {
+		return session.Refreshed{}, fmt.Errorf("error finding user: %v", err)
+	}
+
+	// The new session token keeps the session's "jti", so revoking the
+	// session rejects every token it was issued
+	now := time.Now()
+	claims := JWTClaims{
+		Type:       TokenTypeSession,
+		UserID:     refreshed.UserID.String(),
+		Email:      user.Email,
+		AuthMethod: refreshed.Session.AuthMethod,
+		AuthTime:   jwt.NewNumericDate(refreshed.Session.IssuedAt),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        refreshed.SessionID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.tokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   refreshed.UserID.String(),
+		},
+	}
+	refreshed.Token, err = j.signClaims(claims)
+	if err != nil {
+		return session.Refreshed{}, err
+	}
+	refreshed.ExpiresAt = claims.ExpiresAt.Time
+	return refreshed, nil
+}
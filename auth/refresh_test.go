@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"user-management/database"
+	"user-management/models"
+	"user-management/session"
+)
+
+// newRefreshTestService returns a JWTService issuing refresh tokens, and
+// stores the user of testUserID
+func newRefreshTestService(t *testing.T, repos database.Repositories) *JWTService {
+	t.Helper()
+	j := newTestService(t, repos)
+	j.SetRefreshTokenTTL(24 * time.Hour)
+	err := repos.Users.Insert(context.Background(), models.User{ID: testUserID, Email: "jane@example.com", IsActive: true})
+	if err != nil {
+		t.Fatalf("Insert() = %v", err)
+	}
+	return j
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	j := newRefreshTestService(t, database.NewMemoryRepositories())
+
+	token, refreshToken, err := j.GenerateToken(ctx, testUserID, "jane@example.com", session.Info{Method: "password"})
+	if err != nil {
+		t.Fatalf("GenerateToken() = %v", err)
+	}
+	if refreshToken == "" {
+		t.Fatal("GenerateToken() issued no refresh token")
+	}
+	claims, err := j.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() = %v", err)
+	}
+
+	refreshed, err := j.Refresh(ctx, refreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() = %v", err)
+	}
+	if refreshed.SessionID != claims.ID || refreshed.RefreshToken == "" || refreshed.RefreshToken == refreshToken {
+		t.Errorf("Refresh() = session %q, refresh token %q, want session %q and a new refresh token", refreshed.SessionID, refreshed.RefreshToken, claims.ID)
+	}
+	newClaims, err := j.ValidateToken(refreshed.Token)
+	if err != nil {
+		t.Fatalf("ValidateToken() of the refreshed token = %v", err)
+	}
+	if newClaims.ID != claims.ID || newClaims.AuthMethod != "password" {
+		t.Errorf("refreshed token = session %q, method %q, want %q, %q", newClaims.ID, newClaims.AuthMethod, claims.ID, "password")
+	}
+}
+
+// Presenting an exchanged refresh token again revokes its session and
+// flags the account
+func TestRefreshTokenReuse(t *testing.T) {
+	ctx := context.Background()
+	repos := database.NewMemoryRepositories()
+	j := newRefreshTestService(t, repos)
+
+	_, refreshToken, err := j.GenerateToken(ctx, testUserID, "jane@example.com", session.Info{})
+	if err != nil {
+		t.Fatalf("GenerateToken() = %v", err)
+	}
+	refreshed, err := j.Refresh(ctx, refreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() = %v", err)
+	}
+
+	reused, err := j.Refresh(ctx, refreshToken)
+	if !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Refresh() of a used token = %v, want %v", err, ErrRefreshTokenReused)
+	}
+	if reused.UserID != testUserID || reused.SessionID != refreshed.SessionID {
+		t.Errorf("Refresh() of a used token = user %q, session %q, want %q, %q", reused.UserID, reused.SessionID, testUserID, refreshed.SessionID)
+	}
+
+	if _, err := j.ValidateToken(refreshed.Token); !errors.Is(err, ErrTokenBlacklisted) {
+		t.Errorf("ValidateToken() after reuse = %v, want %v", err, ErrTokenBlacklisted)
+	}
+	if _, err := j.Refresh(ctx, refreshed.RefreshToken); !errors.Is(err, ErrTokenBlacklisted) {
+		t.Errorf("Refresh() of the next token after reuse = %v, want %v", err, ErrTokenBlacklisted)
+	}
+	user, err := repos.Users.FindByID(ctx, testUserID)
+	if err != nil {
+		t.Fatalf("FindByID() = %v", err)
+	}
+	if user.RefreshTokenReusedAt == nil {
+		t.Error("account not flagged after refresh token reuse")
+	}
+}
+
+func TestRefreshRevokedSession(t *testing.T) {
+	ctx := context.Background()
+	j := newRefreshTestService(t, database.NewMemoryRepositories())
+
+	token, refreshToken, err := j.GenerateToken(ctx, testUserID, "jane@example.com", session.Info{})
+	if err != nil {
+		t.Fatalf("GenerateToken() = %v", err)
+	}
+	claims, err := j.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() = %v", err)
+	}
+	if err := j.RevokeSession(ctx, testUserID, claims.ID); err != nil {
+		t.Fatalf("RevokeSession() = %v", err)
+	}
+
+	if _, err := j.Refresh(ctx, refreshToken); !errors.Is(err, ErrTokenBlacklisted) {
+		t.Errorf("Refresh() = %v, want %v", err, ErrTokenBlacklisted)
+	}
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"user-management/models"
+	"user-management/session"
+)
+
+// MinIdleTimeout is the shortest idle timeout, well above the resolution
+// session activity is recorded at
+const MinIdleTimeout = 5 * time.Minute
+
+var ErrSessionIdle = session.ErrIdle
+
+// sessionStore returns the store j keeps sessions and refresh tokens in
+func (j *JWTService) sessionStore() session.Store {
+	return session.Store{
+		Sessions:      j.sessions,
+		RefreshTokens: j.refreshTokens,
+		Accounts:      j.users,
+		IdleTimeout:   j.idleTimeout,
+		RefreshTTL:    j.refreshTTL,
+	}
+}
+
+// sessionError returns the token error for an error of the session store
+func sessionError(err error) error {
+	switch err {
+	case session.ErrUnknown:
+		return ErrInvalidToken
+	case session.ErrRevoked:
+		return ErrTokenBlacklisted
+	case session.ErrExpired:
+		return ErrTokenExpired
+	case session.ErrTokensRevoked:
+		return ErrTokenRevoked
+	}
+	return err
+}
+
+// createSession records a newly issued session token, which ends at
+// expiresAt unless it is revoked or goes idle
+func (j *JWTService) createSession(ctx context.Context, claims *JWTClaims, info session.Info, expiresAt time.Time) error {
+	userID, err := models.ParseID(claims.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %v", err)
+	}
+
+	return j.sessionStore().Start(ctx, models.Session{
+		ID:             claims.ID,
+		UserID:         userID,
+		UserAgent:      info.UserAgent,
+		IPAddress:      info.IPAddress,
+		AuthMethod:     info.Method,
+		IssuedAt:       claims.IssuedAt.Time,
+		LastActivityAt: claims.IssuedAt.Time,
+		ExpiresAt:      expiresAt,
+	})
+}
+
+// checkSession rejects session tokens whose session was revoked or, with an
+// idle timeout, has not been used within it, and records the activity of
+// the others when recordActivity is set. Tokens without a "jti" claim
+// predate session tracking and only expire at their absolute expiry.
+func (j *JWTService) checkSession(ctx context.Context, claims *JWTClaims, recordActivity bool) error {
+	if claims.ID == "" {
+		return nil
+	}
+
+	userID, err := models.ParseID(claims.UserID)
+	if err != nil || claims.IssuedAt == nil || claims.ExpiresAt == nil {
+		return ErrInvalidToken
+	}
+
+	err = j.sessionStore().Check(ctx, models.Session{
+		ID:        claims.ID,
+		UserID:    userID,
+		IssuedAt:  claims.IssuedAt.Time,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, recordActivity)
+	return sessionError(err)
+}
+
+// SessionEnded returns why the session of a session token has ended, such
+// as ErrTokenBlacklisted once it is revoked or ErrTokenExpired, or nil
+// while it lasts. Unlike validating the token, the check doesn't count as
+// activity, so a client watching its session still goes idle.
+func (j *JWTService) SessionEnded(ctx context.Context, tokenString string) error {
+	claims, err := j.parseToken(ctx, tokenString)
+	if err != nil {
+		return err
+	}
+	if claims.Type != TokenTypeSession {
+		return ErrInvalidToken
+	}
+	return j.checkSession(ctx, claims, false)
+}
+
+// ActiveSessions returns the sessions of userID whose tokens are still
+// accepted, most recently used first. Sessions issued at or before
+// validAfter were revoked along with every other token of the user.
+func (j *JWTService) ActiveSessions(ctx context.Context, userID models.ID, validAfter *time.Time) ([]models.Session, error) {
+	return j.sessionStore().Active(ctx, userID, validAfter)
+}
+
+// RevokeSession revokes one of userID's sessions, rejecting its tokens and
+// refresh tokens from then on. It returns mongo.ErrNoDocuments when the
+// user has no such session or it is already revoked.
+func (j *JWTService) RevokeSession(ctx context.Context, userID models.ID, sessionID string) error {
+	return j.sessionStore().Revoke(ctx, userID, sessionID)
+}
+
+// RevokeAllSessions revokes every unexpired session of userID, and their
+// refresh tokens, and returns how many there were. Callers also move the
+// user's tokens_valid_after, as tokens issued before sessions were tracked
+// have none.
+func (j *JWTService) RevokeAllSessions(ctx context.Context, userID models.ID) (int, error) {
+	return j.sessionStore().RevokeAll(ctx, userID)
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"user-management/database"
+	"user-management/models"
+	"user-management/session"
+)
+
+const testUserID = "507f1f77bcf86cd799439011"
+
+func TestRevokeSession(t *testing.T) {
+	ctx := context.Background()
+	j := newTestService(t, database.NewMemoryRepositories())
+
+	token, _, err := j.GenerateToken(ctx, testUserID, "jane@example.com", session.Info{Method: "password"})
+	if err != nil {
+		t.Fatalf("GenerateToken() = %v", err)
+	}
+	claims, err := j.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() before revoking = %v, want nil", err)
+	}
+
+	sessions, err := j.ActiveSessions(ctx, testUserID, nil)
+	if err != nil {
+		t.Fatalf("ActiveSessions() = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != claims.ID {
+		t.Fatalf("ActiveSessions() = %v, want the session %s", sessions, claims.ID)
+	}
+
+	if err := j.RevokeSession(ctx, testUserID, claims.ID); err != nil {
+		t.Fatalf("RevokeSession() = %v", err)
+	}
+	if _, err := j.ValidateToken(token); !errors.Is(err, ErrTokenBlacklisted) {
+		t.Errorf("ValidateToken() after revoking = %v, want %v", err, ErrTokenBlacklisted)
+	}
+	if err := j.SessionEnded(ctx, token); !errors.Is(err, ErrTokenBlacklisted) {
+		t.Errorf("SessionEnded() = %v, want %v", err, ErrTokenBlacklisted)
+	}
+	if err := j.RevokeSession(ctx, testUserID, claims.ID); err != database.ErrNotFound {
+		t.Errorf("RevokeSession() of a revoked session = %v, want %v", err, database.ErrNotFound)
+	}
+	sessions, err = j.ActiveSessions(ctx, testUserID, nil)
+	if err != nil {
+		t.Fatalf("ActiveSessions() = %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("ActiveSessions() after revoking = %v, want none", sessions)
+	}
+}
+
+// Only the owner of a session can revoke it
+func TestRevokeSessionOfAnotherUser(t *testing.T) {
+	ctx := context.Background()
+	j := newTestService(t, database.NewMemoryRepositories())
+
+	token, _, err := j.GenerateToken(ctx, testUserID, "jane@example.com", session.Info{})
+	if err != nil {
+		t.Fatalf("GenerateToken() = %v", err)
+	}
+	claims, err := j.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() = %v", err)
+	}
+
+	other := models.ID("507f1f77bcf86cd799439012")
+	if err := j.RevokeSession(ctx, other, claims.ID); err != database.ErrNotFound {
+		t.Errorf("RevokeSession() = %v, want %v", err, database.ErrNotFound)
+	}
+	if _, err := j.ValidateToken(token); err != nil {
+		t.Errorf("ValidateToken() = %v, want nil", err)
+	}
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	ctx := context.Background()
+	j := newTestService(t, database.NewMemoryRepositories())
+
+	var tokens []string
+	for i := 0; i < 2; i++ {
+		token, _, err := j.GenerateToken(ctx, testUserID, "jane@example.com", session.Info{})
+		if err != nil {
+			t.Fatalf("GenerateToken() = %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	revoked, err := j.RevokeAllSessions(ctx, testUserID)
+	if err != nil {
+		t.Fatalf("RevokeAllSessions() = %v", err)
+	}
+	if revoked != 2 {
+		t.Errorf("RevokeAllSessions() = %d, want 2", revoked)
+	}
+	for _, token := range tokens {
+		if _, err := j.ValidateToken(token); !errors.Is(err, ErrTokenBlacklisted) {
+			t.Errorf("ValidateToken() = %v, want %v", err, ErrTokenBlacklisted)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// kmsKeyID and hsmKeyID are the kid headers of tokens signed by the KMS
+// and the HSM, telling them apart from tokens signed with the secret.
+// Tokens signed with a key file have the key's thumbprint.
+const (
+	kmsKeyID = "kms"
+	hsmKeyID = "hsm"
+)
+
+const (
+	// signingTimeout bounds each call to the KMS or the HSM
+	signingTimeout = 5 * time.Second
+	// maxVerifiedTokens bounds the tokens whose KMS signature is
+	// remembered
+	maxVerifiedTokens = 100000
+)
+
+// MACKey computes HMAC-SHA256 signatures in a key management service, see
+// kms.MACKey
+type MACKey interface {
+	Sign(ctx context.Context, message []byte) ([]byte, error)
+	Verify(ctx context.Context, message, mac []byte) (bool, error)
+}
+
+// SetSigningKey signs new tokens with key, so the signing key never leaves
+// the KMS. Tokens signed with the secret stay valid if one is configured.
+// Each token's signature is verified by the KMS once, and remembered until
+// the token expires.
+func (j *JWTService) SetSigningKey(key MACKey) {
+	j.signingKey = key
+	j.verifiedTokens = make(map[[sha256.Size]byte]time.Time)
+}
+
+// Signer computes RS256 or ES256 signatures, with a key that never leaves
+// a hardware security module, see hsm.Key, or with a KeyFile
+type Signer interface {
+	// Algorithm returns the JWS algorithm, RS256 or ES256
+	Algorithm() string
+	Public() crypto.PublicKey
+	Sign(ctx context.Context, message []byte) ([]byte, error)
+}
+
+// publicKey verifies tokens signed by a Signer
+type publicKey struct {
+	algorithm string
+	key       crypto.PublicKey
+}
+
+// SetSigner signs new tokens with signer, so the signing key never leaves
+// the HSM. Signatures are verified locally with its public key. Tokens
+// signed with the secret stay valid if one is configured.
+func (j *JWTService) SetSigner(signer Signer) error {
+	if err := checkSigner(signer); err != nil {
+		return err
+	}
+	j.signer = signer
+	j.signerKeyID = hsmKeyID
+	j.publicKeys = map[string]publicKey{
+		hsmKeyID: {algorithm: signer.Algorithm(), key: signer.Public()},
+	}
+	return nil
+}
+
+// SetKeyFiles signs new tokens with the first of keys and verifies tokens
+// signed with any of them, so keys can be rotated: add the new key after
+// the current one, move it first once the JWKS has been picked up, and
+// remove the old one once its tokens have expired. Each key's ID is its
+// RFC 7638 thumbprint. Tokens signed with the secret stay valid if one is
+// configured.
+func (j *JWTService) SetKeyFiles(keys []Signer) error {
+	if len(keys) == 0 {
+		return errors.New("no signing keys")
+	}
+	publicKeys := make(map[string]publicKey, len(keys))
+	var signerKeyID string
+	for i, key := range keys {
+		if err := checkSigner(key); err != nil {
+			return err
+		}
+		jwk, err := newJSONWebKey("", key.Algorithm(), key.Public())
+		if err != nil {
+			return err
+		}
+		kid := jwk.thumbprint()
+		if _, ok := publicKeys[kid]; ok {
+			return fmt.Errorf("signing key %s is listed twice", kid)
+		}
+		publicKeys[kid] = publicKey{algorithm: key.Algorithm(), key: key.Public()}
+		if i == 0 {
+			signerKeyID = kid
+		}
+	}
+	j.signer = keys[0]
+	j.signerKeyID = signerKeyID
+	j.publicKeys = publicKeys
+	return nil
+}
+
+// SigningKeyID returns the key ID of new tokens signed by an HSM or a key
+// file, empty when they are signed with the secret or by the KMS
+func (j *JWTService) SigningKeyID() string {
+	return j.signerKeyID
+}
+
+func checkSigner(signer Signer) error {
+	method := jwt.GetSigningMethod(signer.Algorithm())
+	if method != jwt.SigningMethodRS256 && method != jwt.SigningMethodES256 {
+		return fmt.Errorf("unsupported signing algorithm %s", signer.Algorithm())
+	}
+	return nil
+}
+
+// sign returns the signed token
+func (j *JWTService) sign(token *jwt.Token) (string, error) {
+	if j.signingKey == nil && j.signer == nil {
+		return token.SignedString(j.secretKey)
+	}
+
+	sign := func(ctx context.Context, message []byte) ([]byte, error) {
+		return j.signingKey.Sign(ctx, message)
+	}
+	token.Header["kid"] = kmsKeyID
+	if j.signer != nil {
+		sign = j.signer.Sign
+		token.Method = jwt.GetSigningMethod(j.signer.Algorithm())
+		token.Header["alg"] = j.signer.Algorithm()
+		token.Header["kid"] = j.signerKeyID
+	}
+	signingString, err := token.SigningString()
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), signingTimeout)
+	defer cancel()
+	signature, err := sign(ctx, []byte(signingString))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %v", err)
+	}
+	return signingString + "." + token.EncodeSegment(signature), nil
+}
+
+// parseClaims parses tokenString into claims, verifying its signature and
+// expiry
+func (j *JWTService) parseClaims(ctx context.Context, tokenString string, claims jwt.Claims) error {
+	if j.signingKey == nil && len(j.publicKeys) == 0 {
+		_, err := jwt.ParseWithClaims(tokenString, claims, j.keyFunc)
+		return err
+	}
+
+	parser := jwt.NewParser()
+	token, parts, err := parser.ParseUnverified(tokenString, claims)
+	if err != nil {
+		return err
+	}
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := j.publicKeys[kid]; ok {
+		_, err := jwt.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+			return key.key, nil
+		}, jwt.WithValidMethods([]string{key.algorithm}))
+		return err
+	}
+	if kid != kmsKeyID || j.signingKey == nil {
+		_, err := jwt.ParseWithClaims(tokenString, claims, j.keyFunc)
+		return err
+	}
+	if token.Method != jwt.SigningMethodHS256 {
+		return jwt.ErrTokenSignatureInvalid
+	}
+
+	hash := sha256.Sum256([]byte(tokenString))
+	if !j.isVerified(hash) {
+		signature, err := parser.DecodeSegment(parts[2])
+		if err != nil {
+			return jwt.ErrTokenMalformed
+		}
+		ctx, cancel := context.WithTimeout(ctx, signingTimeout)
+		defer cancel()
+		valid, err := j.signingKey.Verify(ctx, []byte(strings.Join(parts[:2], ".")), signature)
+		if err != nil {
+			return fmt.Errorf("failed to verify token: %v", err)
+		}
+		if !valid {
+			return jwt.ErrTokenSignatureInvalid
+		}
+	}
+
+	if err := jwt.NewValidator().Validate(claims); err != nil {
+		return err
+	}
+	if expiresAt, err := claims.GetExpirationTime(); err == nil && expiresAt != nil {
+		j.addVerified(hash, expiresAt.Time)
+	}
+	return nil
+}
+
+func (j *JWTService) isVerified(hash [sha256.Size]byte) bool {
+	j.verifiedMu.Lock()
+	defer j.verifiedMu.Unlock()
+	expiresAt, ok := j.verifiedTokens[hash]
+	return ok && time.Now().Before(expiresAt)
+}
+
+func (j *JWTService) addVerified(hash [sha256.Size]byte, expiresAt time.Time) {
+	j.verifiedMu.Lock()
+	defer j.verifiedMu.Unlock()
+	if len(j.verifiedTokens) >= maxVerifiedTokens {
+		now := time.Now()
+		for h, e := range j.verifiedTokens {
+			if now.After(e) {
+				delete(j.verifiedTokens, h)
+			}
+		}
+		// Still full of live tokens: they are verified again
+		if len(j.verifiedTokens) >= maxVerifiedTokens {
+			clear(j.verifiedTokens)
+		}
+	}
+	j.verifiedTokens[hash] = expiresAt
+}
+
+// SetPreviousSecrets keeps tokens signed with secrets the current one
+// replaced valid, so the secret can be rotated without signing everyone
+// out: move the old secret to the previous ones when setting the new one,
+// and remove it once its tokens have expired. New tokens are only signed
+// with the current secret.
+func (j *JWTService) SetPreviousSecrets(secrets []string) {
+	j.previousSecrets = make([][]byte, len(secrets))
+	for i, secret := range secrets {
+		j.previousSecrets[i] = []byte(secret)
+	}
+}
+
+// errNoSecret rejects tokens signed with a secret when none is configured
+var errNoSecret = errors.New("tokens are only signed by the KMS or the HSM")
+
+// keyFunc returns the keys that verify a token's signature: the secret,
+// then the previous ones
+func (j *JWTService) keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	// An empty key would verify tokens anyone can sign
+	if len(j.secretKey) == 0 {
+		return nil, errNoSecret
+	}
+	if len(j.previousSecrets) == 0 {
+		return j.secretKey, nil
+	}
+	keys := jwt.VerificationKeySet{Keys: []jwt.VerificationKey{j.secretKey}}
+	for _, secret := range j.previousSecrets {
+		keys.Keys = append(keys.Keys, secret)
+	}
+	return keys, nil
+}
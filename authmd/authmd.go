@@ -0,0 +1,215 @@
+// Package authmd is the metadata contract between the user management
+// service and the services behind it. The auth interceptor adds the
+// authenticated principal to outgoing gRPC metadata; downstream Go services
+// read it back with FromIncomingContext or FromHTTPHeader.
+//
+// Only trust these headers on requests that came through the auth service
+// or a gateway that strips them from external traffic.
+package authmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys. gRPC metadata keys are lowercase; HTTP header lookups are
+// case-insensitive.
+const (
+	HeaderUserID = "x-user-id"
+	HeaderOrgID  = "x-org-id"
+	// HeaderRoles is a comma-separated list of roles
+	HeaderRoles = "x-roles"
+	// HeaderScopes is a comma-separated list of the scopes the caller's
+	// credential is limited to
+	HeaderScopes     = "x-scopes"
+	HeaderSessionID  = "x-session-id"
+	HeaderAuthMethod = "x-auth-method"
+	// HeaderAuthTime is when the caller signed in, in Unix seconds
+	HeaderAuthTime = "x-auth-time"
+)
+
+// headers are all the keys a principal is encoded in
+var headers = []string{HeaderUserID, HeaderOrgID, HeaderRoles, HeaderScopes, HeaderSessionID, HeaderAuthMethod, HeaderAuthTime}
+
+// Headers returns all the keys a principal is encoded in. Proxies remove
+// the ones a principal doesn't set from client requests, so clients can't
+// forge them.
+func Headers() []string {
+	return append([]string(nil), headers...)
+}
+
+// OrgRolePrefix prefixes the caller's role within their organization in the
+// roles list, e.g. "org:admin"
+const OrgRolePrefix = "org:"
+
+var ErrNoPrincipal = errors.New("no authenticated principal in request")
+
+// Principal identifies the authenticated caller
+type Principal struct {
+	UserID string
+	// OrgID is empty for users outside any organization
+	OrgID string
+	Roles []string
+	// Scopes limit what the caller may do. They are empty for a full
+	// session, and name the flow a scoped token was issued for, or the
+	// RPCs a service account may call.
+	Scopes []string
+	// SessionID is the session the caller's token belongs to, when it
+	// has one
+	SessionID string
+	// AuthMethod is how the caller signed in, such as "password" or
+	// "sso", and AuthTime when. Both are empty for tokens issued before
+	// they were recorded.
+	AuthMethod string
+	AuthTime   time.Time
+}
+
+// HasRole reports whether the principal holds role
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the principal's credential is limited to scope
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Metadata encodes the principal as gRPC metadata
+func (p Principal) Metadata() metadata.MD {
+	md := metadata.MD{}
+	p.each(func(key, value string) {
+		md.Set(key, value)
+	})
+	return md
+}
+
+// each calls set with every header of the principal that has a value
+func (p Principal) each(set func(key, value string)) {
+	set(HeaderUserID, p.UserID)
+	if p.OrgID != "" {
+		set(HeaderOrgID, p.OrgID)
+	}
+	if len(p.Roles) > 0 {
+		set(HeaderRoles, strings.Join(p.Roles, ","))
+	}
+	if len(p.Scopes) > 0 {
+		set(HeaderScopes, strings.Join(p.Scopes, ","))
+	}
+	if p.SessionID != "" {
+		set(HeaderSessionID, p.SessionID)
+	}
+	if p.AuthMethod != "" {
+		set(HeaderAuthMethod, p.AuthMethod)
+	}
+	if !p.AuthTime.IsZero() {
+		set(HeaderAuthTime, strconv.FormatInt(p.AuthTime.Unix(), 10))
+	}
+}
+
+// AppendToOutgoingContext adds the principal to the metadata of gRPC calls
+// made with the returned context, replacing any principal already there
+func AppendToOutgoingContext(ctx context.Context, p Principal) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	for _, key := range headers {
+		md.Delete(key)
+	}
+	return metadata.NewOutgoingContext(ctx, metadata.Join(md, p.Metadata()))
+}
+
+// FromIncomingContext reads the principal from the metadata of an incoming
+// gRPC request
+func FromIncomingContext(ctx context.Context) (Principal, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return Principal{}, ErrNoPrincipal
+	}
+
+	return parse(func(key string) string { return first(md.Get(key)) })
+}
+
+// FromHTTPHeader reads the principal from HTTP request headers
+func FromHTTPHeader(h http.Header) (Principal, error) {
+	return parse(h.Get)
+}
+
+// SetHTTPHeader writes the principal to HTTP headers, replacing any
+// principal already there
+func SetHTTPHeader(h http.Header, p Principal) {
+	for _, key := range headers {
+		h.Del(key)
+	}
+	p.each(h.Set)
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the principal for in-process use
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal stored by NewContext
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
+
+// parse reads a principal with get, which returns the value of a header
+func parse(get func(key string) string) (Principal, error) {
+	userID := strings.TrimSpace(get(HeaderUserID))
+	if userID == "" {
+		return Principal{}, ErrNoPrincipal
+	}
+
+	p := Principal{
+		UserID:     userID,
+		OrgID:      strings.TrimSpace(get(HeaderOrgID)),
+		Roles:      splitList(get(HeaderRoles)),
+		Scopes:     splitList(get(HeaderScopes)),
+		SessionID:  strings.TrimSpace(get(HeaderSessionID)),
+		AuthMethod: strings.TrimSpace(get(HeaderAuthMethod)),
+	}
+	if authTime := strings.TrimSpace(get(HeaderAuthTime)); authTime != "" {
+		seconds, err := strconv.ParseInt(authTime, 10, 64)
+		if err != nil {
+			return Principal{}, errors.New("invalid " + HeaderAuthTime + " header")
+		}
+		p.AuthTime = time.Unix(seconds, 0)
+	}
+	return p, nil
+}
+
+// splitList splits a comma-separated header, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
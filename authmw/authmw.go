@@ -0,0 +1,51 @@
+// Package authmw lets downstream services authenticate requests with
+// tokens issued by the user management service. A Verifier checks tokens
+// either locally, against the keys the service publishes as a JWKS, or
+// remotely, through its ValidateToken RPC. UnaryServerInterceptor and
+// Middleware use a Verifier to protect gRPC and net/http servers and store
+// the caller as an authmd.Principal in the request context.
+//
+// This package only depends on authmd and the generated API, so services
+// can import it without the server's database dependencies.
+package authmw
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"user-management/authmd"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Identity is the caller a token was issued to
+type Identity struct {
+	authmd.Principal
+	Email     string
+	ExpiresAt time.Time
+}
+
+// Verifier checks a bearer token. It returns ErrInvalidToken for tokens
+// that are not valid, and other errors when the check itself failed.
+type Verifier interface {
+	Verify(ctx context.Context, token string) (Identity, error)
+}
+
+// bearerToken extracts the token from an authorization header value,
+// accepting the "Bearer <token>" form
+func bearerToken(value string) (string, error) {
+	token := strings.TrimSpace(value)
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	token = strings.Trim(token, `"`)
+	if token == "" {
+		return "", ErrMissingToken
+	}
+	return token, nil
+}
@@ -0,0 +1,69 @@
+package authmw
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// cacheEntry is a cached verification result. A nil identity records a
+// rejected token.
+type cacheEntry struct {
+	identity  *Identity
+	expiresAt time.Time
+}
+
+// tokenCache remembers verification results by token hash, so repeated
+// requests with the same token skip the remote check
+type tokenCache struct {
+	mu         sync.Mutex
+	entries    map[[sha256.Size]byte]cacheEntry
+	maxEntries int
+}
+
+func newTokenCache(maxEntries int) *tokenCache {
+	return &tokenCache{
+		entries:    make(map[[sha256.Size]byte]cacheEntry),
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *tokenCache) get(token string, now time.Time) (cacheEntry, bool) {
+	key := sha256.Sum256([]byte(token))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if !now.Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *tokenCache) put(token string, entry cacheEntry, now time.Time) {
+	key := sha256.Sum256([]byte(token))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= c.maxEntries {
+		// Drop expired entries first, then arbitrary ones, to stay bounded
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.maxEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry
+}
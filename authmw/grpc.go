@@ -0,0 +1,59 @@
+package authmw
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"user-management/authmd"
+)
+
+// UnaryServerInterceptor rejects calls without a valid bearer token with
+// UNAUTHENTICATED. The caller's principal is stored in the context, where
+// handlers read it with authmd.FromContext. Methods listed in
+// publicMethods, by full name such as "/pkg.Service/Method", are let
+// through without a token.
+func UnaryServerInterceptor(v Verifier, publicMethods ...string) grpc.UnaryServerInterceptor {
+	public := make(map[string]bool, len(publicMethods))
+	for _, method := range publicMethods {
+		public[method] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if public[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		var value string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				value = values[0]
+			}
+		}
+
+		identity, err := verify(ctx, v, value)
+		if err != nil {
+			if errors.Is(err, ErrMissingToken) || errors.Is(err, ErrInvalidToken) {
+				return nil, status.Errorf(codes.Unauthenticated, "%s", err.Error())
+			}
+			log.Printf("Failed to verify token for %s: %v", info.FullMethod, err)
+			return nil, status.Errorf(codes.Unavailable, "failed to verify token")
+		}
+
+		return handler(authmd.NewContext(ctx, identity.Principal), req)
+	}
+}
+
+// verify checks the token in an authorization header value
+func verify(ctx context.Context, v Verifier, authorization string) (Identity, error) {
+	token, err := bearerToken(authorization)
+	if err != nil {
+		return Identity{}, err
+	}
+	return v.Verify(ctx, token)
+}
@@ -0,0 +1,35 @@
+package authmw
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"user-management/authmd"
+)
+
+// Middleware rejects requests without a valid bearer token with 401. The
+// caller's principal is stored in the request context, where handlers read
+// it with authmd.FromContext.
+func Middleware(v Verifier, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := verify(r.Context(), v, r.Header.Get("Authorization"))
+		if err != nil {
+			if errors.Is(err, ErrMissingToken) {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			if errors.Is(err, ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			log.Printf("Failed to verify token for %s: %v", r.URL.Path, err)
+			http.Error(w, "failed to verify token", http.StatusServiceUnavailable)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(authmd.NewContext(r.Context(), identity.Principal)))
+	})
+}
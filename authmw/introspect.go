@@ -0,0 +1,84 @@
+package authmw
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"user-management/authmd"
+	pb "user-management/proto"
+)
+
+// IntrospectionConfig configures an IntrospectionVerifier
+type IntrospectionConfig struct {
+	// CacheTTL is how long a result is reused. A token revoked on the auth
+	// service keeps working here for up to this long. Defaults to 30
+	// seconds.
+	CacheTTL time.Duration
+	// CacheSize caps the number of cached results. Defaults to 10000.
+	CacheSize int
+}
+
+// IntrospectionVerifier checks tokens with the auth service's ValidateToken
+// RPC. It sees revocations and role changes, at the cost of a call per
+// token every CacheTTL.
+type IntrospectionVerifier struct {
+	client pb.AuthServiceClient
+	config IntrospectionConfig
+	cache  *tokenCache
+}
+
+func NewIntrospectionVerifier(client pb.AuthServiceClient, config IntrospectionConfig) *IntrospectionVerifier {
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = 30 * time.Second
+	}
+	if config.CacheSize <= 0 {
+		config.CacheSize = 10000
+	}
+
+	return &IntrospectionVerifier{
+		client: client,
+		config: config,
+		cache:  newTokenCache(config.CacheSize),
+	}
+}
+
+func (v *IntrospectionVerifier) Verify(ctx context.Context, token string) (Identity, error) {
+	now := time.Now()
+	if entry, ok := v.cache.get(token, now); ok {
+		if entry.identity == nil {
+			return Identity{}, ErrInvalidToken
+		}
+		return *entry.identity, nil
+	}
+
+	resp, err := v.client.ValidateToken(ctx, &pb.ValidateTokenRequest{Token: token})
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to validate token: %w", err)
+	}
+
+	// Rejections are cached too, so a client retrying a bad token does not
+	// turn into a call per request
+	entry := cacheEntry{expiresAt: now.Add(v.config.CacheTTL)}
+	if resp.Valid {
+		identity := Identity{
+			Principal: authmd.Principal{
+				UserID: resp.UserId,
+				OrgID:  resp.OrgId,
+				Roles:  resp.Roles,
+			},
+			Email:     resp.Email,
+			ExpiresAt: resp.ExpiresAt.AsTime(),
+		}
+		entry.identity = &identity
+		if identity.ExpiresAt.Before(entry.expiresAt) {
+			entry.expiresAt = identity.ExpiresAt
+		}
+	}
+	v.cache.put(token, entry, now)
+
+	if entry.identity == nil {
+		return Identity{}, ErrInvalidToken
+	}
+	return *entry.identity, nil
+}
@@ -0,0 +1,249 @@
+package authmw
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"user-management/authmd"
+)
+
+// JWKSConfig configures a JWKSVerifier
+type JWKSConfig struct {
+	// URL of the auth service's JSON Web Key Set
+	URL string
+	// RefreshInterval is how often keys are fetched again. Defaults to an
+	// hour.
+	RefreshInterval time.Duration
+	// MinRefreshInterval limits refetches triggered by tokens signed with
+	// an unknown key ID, so forged key IDs cannot flood the auth service.
+	// Defaults to a minute.
+	MinRefreshInterval time.Duration
+	// HTTPClient defaults to a client with a 10 second timeout
+	HTTPClient *http.Client
+}
+
+// JWKSVerifier checks token signatures and expiry locally with the public
+// keys the auth service publishes. It needs no call per request, but it
+// cannot see tokens revoked before they expire or the caller's roles; use
+// an IntrospectionVerifier when those matter.
+type JWKSVerifier struct {
+	config JWKSConfig
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func NewJWKSVerifier(config JWKSConfig) (*JWKSVerifier, error) {
+	if config.URL == "" {
+		return nil, errors.New("JWKS URL is required")
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = time.Hour
+	}
+	if config.MinRefreshInterval <= 0 {
+		config.MinRefreshInterval = time.Minute
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &JWKSVerifier{config: config}, nil
+}
+
+// tokenClaims are the claims of a session token
+type tokenClaims struct {
+	Type    string `json:"typ,omitempty"`
+	UserID  string `json:"user_id"`
+	Email   string `json:"email"`
+	Purpose string `json:"purpose,omitempty"`
+	// Scoped tokens only work for one flow of the auth service
+	Scope      string           `json:"scope,omitempty"`
+	AuthMethod string           `json:"auth_method,omitempty"`
+	AuthTime   *jwt.NumericDate `json:"auth_time,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func (v *JWKSVerifier) Verify(ctx context.Context, token string) (Identity, error) {
+	var lookupErr error
+	parsed, err := jwt.ParseWithClaims(token, &tokenClaims{}, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := v.key(ctx, kid)
+		if err != nil {
+			lookupErr = err
+		}
+		return key, err
+	}, jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}), jwt.WithExpirationRequired())
+	if lookupErr != nil && !errors.Is(lookupErr, ErrInvalidToken) {
+		return Identity{}, lookupErr
+	}
+	if err != nil || !parsed.Valid {
+		return Identity{}, ErrInvalidToken
+	}
+
+	claims := parsed.Claims.(*tokenClaims)
+	// Action tokens authorize one operation and are never a login, and
+	// scoped tokens only work for the auth service's own flows
+	if claims.UserID == "" || claims.Purpose != "" || (claims.Type != "" && claims.Type != "session") || claims.Scope != "" {
+		return Identity{}, ErrInvalidToken
+	}
+
+	identity := Identity{
+		Principal: authmd.Principal{
+			UserID:     claims.UserID,
+			SessionID:  claims.ID,
+			AuthMethod: claims.AuthMethod,
+		},
+		Email:     claims.Email,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if claims.AuthTime != nil {
+		identity.Principal.AuthTime = claims.AuthTime.Time
+	}
+	return identity, nil
+}
+
+// key returns the public key with ID kid, fetching the key set when it is
+// stale or does not have the key yet
+func (v *JWKSVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	sinceFetch := time.Since(v.fetchedAt)
+	key, ok := v.keys[kid]
+	if ok && sinceFetch < v.config.RefreshInterval {
+		return key, nil
+	}
+
+	if v.keys == nil || sinceFetch >= v.config.MinRefreshInterval {
+		keys, err := v.fetch(ctx)
+		if err != nil {
+			if ok {
+				// Keep using a known key while the auth service is unreachable
+				return key, nil
+			}
+			return nil, err
+		}
+		v.keys = keys
+		v.fetchedAt = time.Now()
+		key, ok = v.keys[kid]
+	}
+
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return key, nil
+}
+
+// jsonWebKey holds the JWK fields of RSA and EC public keys
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (v *JWKSVerifier) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.config.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := v.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in JWKS: %w", jwk.Kid, err)
+		}
+		if key != nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+// publicKey decodes the key. Key types other than RSA and EC are skipped.
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
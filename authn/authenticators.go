@@ -0,0 +1,112 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"user-management/ldapauth"
+	"user-management/models"
+	"user-management/sso"
+	"user-management/utils"
+)
+
+// Rehash replaces the password hash of user, which it checked password
+// against, with one of the current algorithm
+type Rehash func(ctx context.Context, user *models.User, password string)
+
+// NewPassword returns an authenticator checking the password against the
+// account's hash. Hashes imported from other providers are passed to
+// rehash once the password is known.
+func NewPassword(rehash Rehash) Authenticator {
+	return passwordAuthenticator{rehash: rehash}
+}
+
+type passwordAuthenticator struct {
+	rehash Rehash
+}
+
+func (a passwordAuthenticator) Authenticate(ctx context.Context, user *models.User, credentials Credentials) (bool, error) {
+	// Accounts created through SSO or a social login have no password
+	if user.Password == "" {
+		return false, ErrNotApplicable
+	}
+	if !utils.CheckPasswordHash(credentials.Password, user.Password) {
+		return false, ErrInvalidCredentials
+	}
+
+	if utils.NeedsRehash(user.Password) {
+		a.rehash(ctx, user, credentials.Password)
+	}
+	return false, nil
+}
+
+// Directory checks passwords against an LDAP directory. ldapauth.Client
+// implements it.
+type Directory interface {
+	// Authenticate returns ldapauth.ErrUserNotFound for users not in the
+	// directory and ldapauth.ErrInvalidCredentials for wrong passwords
+	Authenticate(ctx context.Context, email, password string) error
+}
+
+// NewLDAP returns an authenticator checking the password against
+// directory. Users not in the directory fall through to the next method.
+func NewLDAP(directory Directory) Authenticator {
+	return ldapAuthenticator{directory: directory}
+}
+
+type ldapAuthenticator struct {
+	directory Directory
+}
+
+func (a ldapAuthenticator) Authenticate(ctx context.Context, user *models.User, credentials Credentials) (bool, error) {
+	err := a.directory.Authenticate(ctx, user.Email, credentials.Password)
+	switch {
+	case err == nil:
+		return false, nil
+	case errors.Is(err, ldapauth.ErrUserNotFound):
+		return false, ErrNotApplicable
+	case errors.Is(err, ldapauth.ErrInvalidCredentials):
+		return false, ErrInvalidCredentials
+	}
+	return false, fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
+
+// PasswordProvider signs users in at an OpenID Connect provider with their
+// password. sso.Client implements it.
+type PasswordProvider interface {
+	// PasswordLogin returns sso.ErrLoginFailed when the provider rejects
+	// the password
+	PasswordLogin(ctx context.Context, conn sso.Connection, username, password string) (*sso.Identity, error)
+}
+
+// NewOIDC returns an authenticator sending the password to the provider of
+// connection. Providers don't tell unknown users from wrong passwords, so
+// it never falls through for a rejected password.
+func NewOIDC(provider PasswordProvider, connection sso.Connection) Authenticator {
+	return oidcAuthenticator{provider: provider, connection: connection}
+}
+
+type oidcAuthenticator struct {
+	provider   PasswordProvider
+	connection sso.Connection
+}
+
+func (a oidcAuthenticator) Authenticate(ctx context.Context, user *models.User, credentials Credentials) (bool, error) {
+	identity, err := a.provider.PasswordLogin(ctx, a.connection, user.Email, credentials.Password)
+	if errors.Is(err, sso.ErrLoginFailed) {
+		return false, ErrInvalidCredentials
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	// The provider may resolve the username to another of its users
+	if !strings.EqualFold(identity.Email, user.Email) {
+		log.Printf("Provider %s signed in %q for user %s, refusing the login", identity.Issuer, identity.Email, user.ID.String())
+		return false, ErrInvalidCredentials
+	}
+	return false, nil
+}
@@ -0,0 +1,140 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"user-management/ldapauth"
+	"user-management/models"
+	"user-management/sso"
+	"user-management/utils"
+)
+
+func TestPassword(t *testing.T) {
+	hash, err := utils.HashPassword("secret-password-1")
+	if err != nil {
+		t.Fatalf("HashPassword() = %v", err)
+	}
+	weakHash, err := bcrypt.GenerateFromPassword([]byte("secret-password-1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		hash       string
+		password   string
+		wantErr    error
+		wantRehash bool
+	}{
+		{
+			name:     "right password",
+			hash:     hash,
+			password: "secret-password-1",
+		},
+		{
+			name:     "wrong password",
+			hash:     hash,
+			password: "wrong-password-1",
+			wantErr:  ErrInvalidCredentials,
+		},
+		{
+			name:     "account without a password",
+			password: "secret-password-1",
+			wantErr:  ErrNotApplicable,
+		},
+		{
+			name:       "weaker hash",
+			hash:       string(weakHash),
+			password:   "secret-password-1",
+			wantRehash: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rehashed := false
+			authenticator := NewPassword(func(ctx context.Context, user *models.User, password string) {
+				rehashed = password == tt.password
+			})
+
+			_, err := authenticator.Authenticate(context.Background(), &models.User{Password: tt.hash}, Credentials{Password: tt.password})
+			if err != tt.wantErr {
+				t.Fatalf("Authenticate() = %v, want %v", err, tt.wantErr)
+			}
+			if rehashed != tt.wantRehash {
+				t.Errorf("rehashed = %v, want %v", rehashed, tt.wantRehash)
+			}
+		})
+	}
+}
+
+// fakeDirectory returns err for every password
+type fakeDirectory struct {
+	err error
+}
+
+func (f fakeDirectory) Authenticate(ctx context.Context, email, password string) error {
+	return f.err
+}
+
+func TestLDAP(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "accepted"},
+		{name: "user not in the directory", err: ldapauth.ErrUserNotFound, wantErr: ErrNotApplicable},
+		{name: "wrong password", err: ldapauth.ErrInvalidCredentials, wantErr: ErrInvalidCredentials},
+		{name: "directory unreachable", err: errors.New("connection refused"), wantErr: ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := NewLDAP(fakeDirectory{err: tt.err})
+			_, err := authenticator.Authenticate(context.Background(), &models.User{Email: "jane@example.com"}, Credentials{Password: "secret"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authenticate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeProvider signs in email, or returns err
+type fakeProvider struct {
+	email string
+	err   error
+}
+
+func (f fakeProvider) PasswordLogin(ctx context.Context, conn sso.Connection, username, password string) (*sso.Identity, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sso.Identity{Issuer: conn.Issuer, Email: f.email}, nil
+}
+
+func TestOIDC(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider fakeProvider
+		wantErr  error
+	}{
+		{name: "accepted", provider: fakeProvider{email: "Jane@example.com"}},
+		{name: "another user signed in", provider: fakeProvider{email: "john@example.com"}, wantErr: ErrInvalidCredentials},
+		{name: "password rejected", provider: fakeProvider{err: fmt.Errorf("%w: invalid_grant", sso.ErrLoginFailed)}, wantErr: ErrInvalidCredentials},
+		{name: "provider unreachable", provider: fakeProvider{err: errors.New("connection refused")}, wantErr: ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := NewOIDC(tt.provider, sso.Connection{Issuer: "https://idp.example.com"})
+			_, err := authenticator.Authenticate(context.Background(), &models.User{Email: "jane@example.com"}, Credentials{Password: "secret"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authenticate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Package authn checks the first factor of a login with a chain of
+// authenticators, each for one login method. Deployments and organizations
+// list the methods to try in order: a method whose credentials the login
+// doesn't present, or that doesn't know the user or can't be reached, falls
+// through to the next, and the first to accept or reject the credentials
+// decides the login. The password, LDAP and OIDC authenticators are here;
+// services adds those of emailed codes and passkeys, which it stores.
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"user-management/metrics"
+	"user-management/models"
+)
+
+// Login methods
+const (
+	// MethodPassword checks the password against the account's hash
+	MethodPassword = "password"
+	// MethodLDAP binds to an LDAP directory as the user with the password
+	MethodLDAP = "ldap"
+	// MethodOIDC sends the password to an OpenID Connect provider with
+	// the resource owner password grant
+	MethodOIDC = "oidc"
+	// MethodPasskey verifies a WebAuthn assertion of one of the account's
+	// passkeys
+	MethodPasskey = "passkey"
+	// MethodOTP checks a one-time code emailed to the account
+	MethodOTP = "otp"
+)
+
+// Methods are all the login methods
+var Methods = []string{MethodPassword, MethodLDAP, MethodOIDC, MethodPasskey, MethodOTP}
+
+// Results of an authenticator, as counted in metrics
+const (
+	ResultSuccess       = "success"
+	ResultInvalid       = "invalid"
+	ResultNotApplicable = "not_applicable"
+	ResultUnavailable   = "unavailable"
+	ResultError         = "error"
+)
+
+var (
+	// ErrInvalidCredentials rejects the credentials and ends the chain
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrNotApplicable is returned by authenticators that can't check
+	// the user's credentials, such as LDAP for users not in the directory.
+	// The chain tries the next method.
+	ErrNotApplicable = errors.New("login method does not apply to the user")
+	// ErrUnavailable is returned, wrapped, by authenticators whose
+	// backend can't be reached. The chain tries the next method.
+	ErrUnavailable = errors.New("login method is unavailable")
+	// ErrNoMethod is returned when none of the methods takes the
+	// credentials the login presents
+	ErrNoMethod = errors.New("no login method takes these credentials")
+)
+
+// ValidateMethods checks a chain of login methods
+func ValidateMethods(methods []string) error {
+	seen := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		if !slices.Contains(Methods, method) {
+			return fmt.Errorf("unknown login method %q", method)
+		}
+		if seen[method] {
+			return fmt.Errorf("login method %s is listed twice", method)
+		}
+		seen[method] = true
+	}
+	return nil
+}
+
+// Credentials are what a login presents
+type Credentials struct {
+	Email    string
+	Password string
+	// OneTimeCode is a code emailed to the account
+	OneTimeCode string
+	// PasskeyAssertion is the JSON of the credential
+	// navigator.credentials.get() returned
+	PasskeyAssertion string
+}
+
+// presents reports whether the credentials include what method checks.
+// The password methods all take the password.
+func (c Credentials) presents(method string) bool {
+	switch method {
+	case MethodPassword, MethodLDAP, MethodOIDC:
+		return c.Password != ""
+	case MethodPasskey:
+		return c.PasskeyAssertion != ""
+	case MethodOTP:
+		return c.OneTimeCode != ""
+	}
+	return false
+}
+
+// Authenticator checks the credentials of one login method
+type Authenticator interface {
+	// Authenticate checks credentials against user's account. It returns
+	// whether they also proved a second factor, as a passkey with user
+	// verification does.
+	Authenticate(ctx context.Context, user *models.User, credentials Credentials) (multiFactor bool, err error)
+}
+
+// Result is a login the chain accepted
+type Result struct {
+	// Method is the login method that accepted the credentials
+	Method string
+	// MultiFactor is set when no second factor needs to be asked for
+	MultiFactor bool
+}
+
+// Chain holds the authenticators of the deployment's configured methods
+type Chain struct {
+	authenticators map[string]Authenticator
+}
+
+// NewChain returns a chain of authenticators by login method
+func NewChain(authenticators map[string]Authenticator) *Chain {
+	return &Chain{authenticators: authenticators}
+}
+
+// Configured reports whether the deployment has an authenticator for method
+func (c *Chain) Configured(method string) bool {
+	_, ok := c.authenticators[method]
+	return ok
+}
+
+// Run checks credentials with methods in order. Methods the deployment
+// hasn't configured are skipped. It returns ErrInvalidCredentials when a
+// method rejects the credentials or every method taking them falls
+// through, unless one of those was unavailable, when it returns
+// ErrUnavailable.
+func (c *Chain) Run(ctx context.Context, methods []string, user *models.User, credentials Credentials) (Result, error) {
+	tried, unavailable := false, false
+	for _, method := range methods {
+		authenticator, ok := c.authenticators[method]
+		if !ok || !credentials.presents(method) {
+			continue
+		}
+		tried = true
+
+		start := time.Now()
+		multiFactor, err := authenticator.Authenticate(ctx, user, credentials)
+		metrics.LoginMethodDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+
+		switch {
+		case err == nil:
+			metrics.LoginMethodResults.WithLabelValues(method, ResultSuccess).Inc()
+			return Result{Method: method, MultiFactor: multiFactor}, nil
+		case errors.Is(err, ErrInvalidCredentials):
+			metrics.LoginMethodResults.WithLabelValues(method, ResultInvalid).Inc()
+			return Result{}, err
+		case errors.Is(err, ErrNotApplicable):
+			metrics.LoginMethodResults.WithLabelValues(method, ResultNotApplicable).Inc()
+		case errors.Is(err, ErrUnavailable):
+			metrics.LoginMethodResults.WithLabelValues(method, ResultUnavailable).Inc()
+			log.Printf("Login method %s failed for user %s, trying the next: %v", method, user.ID.String(), err)
+			unavailable = true
+		default:
+			metrics.LoginMethodResults.WithLabelValues(method, ResultError).Inc()
+			return Result{}, fmt.Errorf("login method %s failed: %w", method, err)
+		}
+	}
+
+	switch {
+	case unavailable:
+		return Result{}, ErrUnavailable
+	case !tried:
+		return Result{}, ErrNoMethod
+	}
+	return Result{}, ErrInvalidCredentials
+}
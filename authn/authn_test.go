@@ -0,0 +1,135 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"user-management/models"
+)
+
+// fakeAuthenticator returns err, and counts its calls
+type fakeAuthenticator struct {
+	multiFactor bool
+	err         error
+	calls       int
+}
+
+func (f *fakeAuthenticator) Authenticate(ctx context.Context, user *models.User, credentials Credentials) (bool, error) {
+	f.calls++
+	return f.multiFactor, f.err
+}
+
+func TestChainRun(t *testing.T) {
+	unavailable := fmt.Errorf("%w: connection refused", ErrUnavailable)
+	tests := []struct {
+		name        string
+		ldap        error
+		password    error
+		credentials Credentials
+		wantMethod  string
+		wantErr     error
+	}{
+		{
+			name:        "first method accepts",
+			credentials: Credentials{Password: "secret"},
+			wantMethod:  MethodLDAP,
+		},
+		{
+			name:        "first method rejects",
+			ldap:        ErrInvalidCredentials,
+			credentials: Credentials{Password: "secret"},
+			wantErr:     ErrInvalidCredentials,
+		},
+		{
+			name:        "user not in the directory",
+			ldap:        ErrNotApplicable,
+			credentials: Credentials{Password: "secret"},
+			wantMethod:  MethodPassword,
+		},
+		{
+			name:        "directory unavailable",
+			ldap:        unavailable,
+			credentials: Credentials{Password: "secret"},
+			wantMethod:  MethodPassword,
+		},
+		{
+			name:        "directory unavailable and password rejected",
+			ldap:        unavailable,
+			password:    ErrNotApplicable,
+			credentials: Credentials{Password: "secret"},
+			wantErr:     ErrUnavailable,
+		},
+		{
+			name:        "every method falls through",
+			ldap:        ErrNotApplicable,
+			password:    ErrNotApplicable,
+			credentials: Credentials{Password: "secret"},
+			wantErr:     ErrInvalidCredentials,
+		},
+		{
+			name:        "no method takes the credentials",
+			credentials: Credentials{OneTimeCode: "123456"},
+			wantErr:     ErrNoMethod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := NewChain(map[string]Authenticator{
+				MethodLDAP:     &fakeAuthenticator{err: tt.ldap},
+				MethodPassword: &fakeAuthenticator{err: tt.password},
+			})
+			user := &models.User{ID: "507f1f77bcf86cd799439011", Email: "jane@example.com"}
+
+			result, err := chain.Run(context.Background(), []string{MethodLDAP, MethodPassword}, user, tt.credentials)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Run() = %v, want %v", err, tt.wantErr)
+			}
+			if result.Method != tt.wantMethod {
+				t.Errorf("Run() method = %q, want %q", result.Method, tt.wantMethod)
+			}
+		})
+	}
+}
+
+// Methods the deployment hasn't configured are skipped, and a passkey
+// proves the second factor
+func TestChainRunSkipsUnconfigured(t *testing.T) {
+	passkey := &fakeAuthenticator{multiFactor: true}
+	chain := NewChain(map[string]Authenticator{MethodPasskey: passkey})
+	if chain.Configured(MethodLDAP) {
+		t.Errorf("Configured(%q) = true, want false", MethodLDAP)
+	}
+
+	result, err := chain.Run(context.Background(), []string{MethodLDAP, MethodPasskey}, &models.User{}, Credentials{
+		Password:         "secret",
+		PasskeyAssertion: "{}",
+	})
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if result != (Result{Method: MethodPasskey, MultiFactor: true}) {
+		t.Errorf("Run() = %+v, want a multi-factor passkey login", result)
+	}
+	if passkey.calls != 1 {
+		t.Errorf("passkey authenticator called %d times, want 1", passkey.calls)
+	}
+}
+
+func TestValidateMethods(t *testing.T) {
+	tests := []struct {
+		methods []string
+		wantErr bool
+	}{
+		{methods: []string{MethodLDAP, MethodPassword}},
+		{methods: []string{MethodPassword, "kerberos"}, wantErr: true},
+		{methods: []string{MethodPassword, MethodPassword}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := ValidateMethods(tt.methods); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateMethods(%v) = %v, want error %v", tt.methods, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Package authz is the authorization domain: who the caller of a request is
+// and what they may do. Authorizer is what services and the ext_authz
+// endpoints depend on; New makes one of auth.JWTService, which checks
+// tokens, and an identity.Resolver, which resolves principals, and main
+// wires it in. Handlers check what the caller the interceptors resolved
+// may do with Caller, RequireSession and ProfileOwner.
+package authz
+
+import (
+	"context"
+	"time"
+
+	"user-management/auth"
+	"user-management/authmd"
+)
+
+// Authorizer authenticates callers by their tokens and resolves what they
+// may do
+type Authorizer interface {
+	Tokens
+	// PrincipalFor loads the organization and roles of a token's user,
+	// with the session, scope and sign-in the token carries
+	PrincipalFor(ctx context.Context, claims *auth.JWTClaims) (authmd.Principal, error)
+}
+
+// Tokens checks and issues tokens. auth.JWTService implements it.
+type Tokens interface {
+	// AuthenticateContext validates the bearer token of an incoming gRPC
+	// request, and AuthenticateHeader that of an Authorization header value
+	AuthenticateContext(ctx context.Context) (*auth.JWTClaims, error)
+	AuthenticateHeader(ctx context.Context, authorization string) (*auth.JWTClaims, error)
+	// AuthenticateScopedContext accepts a session token or a token scoped
+	// to scope
+	AuthenticateScopedContext(ctx context.Context, scope string) (*auth.JWTClaims, error)
+	ValidateToken(tokenString string) (*auth.JWTClaims, error)
+	// ValidateScopedToken accepts a session token or a token scoped to
+	// scope
+	ValidateScopedToken(tokenString, scope string) (*auth.JWTClaims, error)
+	ExtractUserIDFromToken(tokenString string) (string, error)
+
+	// GenerateActionToken issues a token that authorizes one kind of action,
+	// such as following an emailed link, and ValidateActionToken checks one
+	GenerateActionToken(userID, email, purpose, resource string, ttl time.Duration) (string, time.Time, error)
+	ValidateActionToken(tokenString, purpose string) (*auth.JWTClaims, error)
+}
+
+// Identities resolves the principals of tokens. identity.Resolver
+// implements it.
+type Identities interface {
+	PrincipalFor(ctx context.Context, claims *auth.JWTClaims) (authmd.Principal, error)
+}
+
+// New returns an Authorizer that checks tokens with tokens and resolves
+// principals with identities
+func New(tokens Tokens, identities Identities) Authorizer {
+	return authorizer{Tokens: tokens, identities: identities}
+}
+
+type authorizer struct {
+	Tokens
+	identities Identities
+}
+
+func (a authorizer) PrincipalFor(ctx context.Context, claims *auth.JWTClaims) (authmd.Principal, error) {
+	return a.identities.PrincipalFor(ctx, claims)
+}
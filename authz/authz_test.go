@@ -0,0 +1,50 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"user-management/auth"
+	"user-management/authmd"
+	"user-management/models"
+)
+
+// fakeTokens accepts every token as the session of userID. The methods
+// the tests don't call are left to the nil Tokens.
+type fakeTokens struct {
+	Tokens
+	userID string
+}
+
+func (f fakeTokens) ValidateToken(tokenString string) (*auth.JWTClaims, error) {
+	if tokenString == "" {
+		return nil, auth.ErrInvalidToken
+	}
+	return &auth.JWTClaims{Type: auth.TokenTypeSession, UserID: f.userID}, nil
+}
+
+// fakeIdentities resolves every user to an admin
+type fakeIdentities struct{}
+
+func (fakeIdentities) PrincipalFor(ctx context.Context, claims *auth.JWTClaims) (authmd.Principal, error) {
+	return authmd.Principal{UserID: claims.UserID, Roles: []string{models.RoleAdmin}}, nil
+}
+
+func TestAuthorizer(t *testing.T) {
+	authorizer := New(fakeTokens{userID: testUserID}, fakeIdentities{})
+
+	if _, err := authorizer.ValidateToken(""); err != auth.ErrInvalidToken {
+		t.Errorf("ValidateToken() of no token = %v, want %v", err, auth.ErrInvalidToken)
+	}
+	claims, err := authorizer.ValidateToken("token")
+	if err != nil {
+		t.Fatalf("ValidateToken() = %v", err)
+	}
+	principal, err := authorizer.PrincipalFor(context.Background(), claims)
+	if err != nil {
+		t.Fatalf("PrincipalFor() = %v", err)
+	}
+	if principal.UserID != testUserID || !principal.HasRole(models.RoleAdmin) {
+		t.Errorf("PrincipalFor() = %+v, want an admin %s", principal, testUserID)
+	}
+}
@@ -0,0 +1,99 @@
+package authz
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/auth"
+	"user-management/authmd"
+	"user-management/models"
+)
+
+// Caller returns the caller the auth interceptor identified from the
+// request's session token. Handlers act on it rather than on user IDs in
+// requests, which callers choose.
+func Caller(ctx context.Context) (authmd.Principal, error) {
+	principal, ok := authmd.FromContext(ctx)
+	if !ok {
+		return authmd.Principal{}, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+	return principal, nil
+}
+
+// RequireSession is Caller for RPCs that approve logins, create
+// credentials, change the email or password, or delete the account. They
+// need a signed-in session, so a leaked API key can't take over the
+// account.
+func RequireSession(ctx context.Context) (authmd.Principal, error) {
+	principal, err := Caller(ctx)
+	if err != nil {
+		return authmd.Principal{}, err
+	}
+	if principal.AuthMethod == auth.AuthMethodAPIKey {
+		return authmd.Principal{}, status.Errorf(codes.PermissionDenied, "API keys can't be used for this, sign in instead")
+	}
+	return principal, nil
+}
+
+// CallerID returns the user ID of the authenticated caller
+func CallerID(ctx context.Context) (models.ID, error) {
+	principal, err := Caller(ctx)
+	if err != nil {
+		return "", err
+	}
+	id, err := models.ParseID(principal.UserID)
+	if err != nil {
+		return "", status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+	return id, nil
+}
+
+// RequireSelf checks that userID, taken from a request, is the
+// authenticated caller, and returns the caller's ID when it is empty. The
+// auth interceptor has already rejected calls to these RPCs without a
+// valid token.
+func RequireSelf(ctx context.Context, userID string) (models.ID, error) {
+	owner, caller, err := ProfileOwner(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if owner != caller {
+		return "", status.Errorf(codes.PermissionDenied, "cannot act on another user's profile")
+	}
+	return owner, nil
+}
+
+// ProfileOwner returns the user a profile RPC acts on, and the caller. It
+// is the caller unless userID, taken from the request, names another user,
+// which only admins may.
+func ProfileOwner(ctx context.Context, userID string) (owner, caller models.ID, err error) {
+	principal, err := Caller(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	caller, err = models.ParseID(principal.UserID)
+	if err != nil {
+		return "", "", status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+	if userID == "" {
+		return caller, caller, nil
+	}
+
+	owner, err = models.ParseID(userID)
+	if err != nil {
+		return "", "", status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+	if owner != caller && !principal.HasRole(models.RoleAdmin) {
+		return "", "", status.Errorf(codes.PermissionDenied, "cannot act on another user's profile")
+	}
+	return owner, caller, nil
+}
+
+// RecentLogin reports whether the caller signed in within maxAge.
+// Refreshing a session doesn't count as signing in again.
+func RecentLogin(principal authmd.Principal, maxAge time.Duration) bool {
+	return !principal.AuthTime.IsZero() && time.Since(principal.AuthTime) <= maxAge
+}
@@ -0,0 +1,128 @@
+package authz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/auth"
+	"user-management/authmd"
+	"user-management/models"
+)
+
+const (
+	testUserID  = "507f1f77bcf86cd799439011"
+	otherUserID = "507f1f77bcf86cd799439012"
+)
+
+func TestCaller(t *testing.T) {
+	if _, err := Caller(context.Background()); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Caller() without a principal = %v, want %v", err, codes.Unauthenticated)
+	}
+
+	ctx := authmd.NewContext(context.Background(), authmd.Principal{UserID: testUserID})
+	id, err := CallerID(ctx)
+	if err != nil {
+		t.Fatalf("CallerID() = %v", err)
+	}
+	if id != testUserID {
+		t.Errorf("CallerID() = %s, want %s", id, testUserID)
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	tests := []struct {
+		method   string
+		wantCode codes.Code
+	}{
+		{method: "password", wantCode: codes.OK},
+		{method: "sso", wantCode: codes.OK},
+		{method: auth.AuthMethodAPIKey, wantCode: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		ctx := authmd.NewContext(context.Background(), authmd.Principal{UserID: testUserID, AuthMethod: tt.method})
+		if _, err := RequireSession(ctx); status.Code(err) != tt.wantCode {
+			t.Errorf("RequireSession() signed in with %s = %v, want %v", tt.method, err, tt.wantCode)
+		}
+	}
+}
+
+func TestProfileOwner(t *testing.T) {
+	tests := []struct {
+		name      string
+		roles     []string
+		userID    string
+		wantOwner models.ID
+		wantCode  codes.Code
+	}{
+		{
+			name:      "own profile by default",
+			wantOwner: testUserID,
+		},
+		{
+			name:      "own profile by ID",
+			userID:    testUserID,
+			wantOwner: testUserID,
+		},
+		{
+			name:     "another user's profile",
+			userID:   otherUserID,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:      "another user's profile as an admin",
+			roles:     []string{models.RoleAdmin},
+			userID:    otherUserID,
+			wantOwner: otherUserID,
+		},
+		{
+			name:     "invalid user ID",
+			userID:   "not-an-id",
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := authmd.NewContext(context.Background(), authmd.Principal{UserID: testUserID, Roles: tt.roles})
+			owner, caller, err := ProfileOwner(ctx, tt.userID)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("ProfileOwner() = %v, want %v", err, tt.wantCode)
+			}
+			if err == nil && (owner != tt.wantOwner || caller != testUserID) {
+				t.Errorf("ProfileOwner() = %s, %s, want %s, %s", owner, caller, tt.wantOwner, testUserID)
+			}
+		})
+	}
+}
+
+// Admins may act on other users' profiles, but not as them
+func TestRequireSelf(t *testing.T) {
+	ctx := authmd.NewContext(context.Background(), authmd.Principal{UserID: testUserID, Roles: []string{models.RoleAdmin}})
+	if _, err := RequireSelf(ctx, otherUserID); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("RequireSelf() of another user = %v, want %v", err, codes.PermissionDenied)
+	}
+	if id, err := RequireSelf(ctx, ""); err != nil || id != testUserID {
+		t.Errorf("RequireSelf() = %s, %v, want %s", id, err, testUserID)
+	}
+}
+
+func TestRecentLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		authTime time.Time
+		want     bool
+	}{
+		{name: "recent login", authTime: time.Now().Add(-time.Minute), want: true},
+		{name: "old login", authTime: time.Now().Add(-time.Hour)},
+		{name: "unknown login time"},
+	}
+	for _, tt := range tests {
+		if got := RecentLogin(authmd.Principal{AuthTime: tt.authTime}, 10*time.Minute); got != tt.want {
+			t.Errorf("RecentLogin() with a %s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
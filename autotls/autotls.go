@@ -0,0 +1,99 @@
+// Package autotls obtains and renews TLS certificates from an ACME
+// certificate authority such as Let's Encrypt, so a server can serve TLS
+// without certificate files or a proxy in front of it. Certificates are
+// requested on the first TLS connection for a domain and renewed before
+// they expire. Domains are validated with the TLS-ALPN-01 challenge on
+// the TLS listeners, or HTTP-01 on a plain HTTP listener, so the CA must
+// reach one of them on port 443 or 80.
+package autotls
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"user-management/fips"
+	"user-management/servertls"
+)
+
+// LetsEncryptURL is the directory of Let's Encrypt's production CA
+const LetsEncryptURL = acme.LetsEncryptURL
+
+// Config names the domains to get certificates for and the CA
+type Config struct {
+	// Domains are the host names certificates are requested for.
+	// Connections for other names are refused.
+	Domains []string
+	// Email is the contact of the ACME account, told about expiring
+	// certificates. Optional.
+	Email string
+	// DirectoryURL is the CA's ACME directory. Empty uses Let's Encrypt.
+	DirectoryURL string
+	// Cache keeps the account key and certificates across restarts, and
+	// shares them between servers. Without one, every restart requests
+	// new certificates and soon hits the CA's rate limits.
+	Cache autocert.Cache
+	// FIPS allows only approved cipher suites and curves
+	FIPS bool
+}
+
+// Manager serves certificates it obtains from the CA. Configuring it
+// accepts the CA's terms of service.
+type Manager struct {
+	manager *autocert.Manager
+	fips    bool
+}
+
+// New returns a manager for config. Nothing is requested until the first
+// TLS connection.
+func New(config Config) *Manager {
+	directoryURL := config.DirectoryURL
+	if directoryURL == "" {
+		directoryURL = LetsEncryptURL
+	}
+	return &Manager{
+		manager: &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Cache:      config.Cache,
+			HostPolicy: autocert.HostWhitelist(config.Domains...),
+			Email:      config.Email,
+			Client:     &acme.Client{DirectoryURL: directoryURL},
+		},
+		fips: config.FIPS,
+	}
+}
+
+// TLSConfig returns a config for the gRPC listener
+func (m *Manager) TLSConfig() *tls.Config {
+	return m.config("h2")
+}
+
+// HTTPSConfig returns a config for an HTTPS listener, such as the REST
+// gateway's, with policy applied
+func (m *Manager) HTTPSConfig(policy servertls.Policy) *tls.Config {
+	config := m.config("h2", "http/1.1")
+	policy.Apply(config)
+	return config
+}
+
+func (m *Manager) config(protocols ...string) *tls.Config {
+	config := &tls.Config{
+		GetCertificate: m.manager.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+		// The CA connects with this protocol for TLS-ALPN-01 challenges
+		NextProtos: append(protocols, acme.ALPNProto),
+	}
+	if m.fips {
+		config.CipherSuites = fips.TLSCipherSuites
+		config.CurvePreferences = fips.TLSCurves
+	}
+	return config
+}
+
+// HTTPHandler answers HTTP-01 challenges and passes other requests to
+// fallback
+func (m *Manager) HTTPHandler(fallback http.Handler) http.Handler {
+	return m.manager.HTTPHandler(fallback)
+}
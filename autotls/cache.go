@@ -0,0 +1,74 @@
+package autotls
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/acme/autocert"
+
+	"user-management/database"
+	"user-management/utils"
+)
+
+// cachedItem is an item of the cache in acme_certificates. Items hold
+// private keys, so they are stored encrypted.
+type cachedItem struct {
+	Key       string    `bson:"_id"`
+	Data      string    `bson:"data"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+// MongoCache keeps the ACME cache in MongoDB, shared by every server of
+// the deployment, so any of them can answer a challenge and certificates
+// are only requested once
+type MongoCache struct {
+	items  *database.Collection
+	cipher *utils.SecretCipher
+}
+
+// NewMongoCache stores items in db, encrypted with cipher
+func NewMongoCache(db *database.Database, cipher *utils.SecretCipher) *MongoCache {
+	return &MongoCache{items: db.Certificates, cipher: cipher}
+}
+
+// DirCache keeps the ACME cache in a directory, such as a mounted volume,
+// created if it doesn't exist
+func DirCache(dir string) autocert.Cache {
+	return autocert.DirCache(dir)
+}
+
+func (c *MongoCache) Get(ctx context.Context, key string) ([]byte, error) {
+	var item cachedItem
+	err := c.items.FindOne(ctx, bson.M{"_id": key}).Decode(&item)
+	if err == database.ErrNotFound {
+		return nil, autocert.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.cipher.Decrypt(item.Data, key)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+func (c *MongoCache) Put(ctx context.Context, key string, data []byte) error {
+	encrypted, err := c.cipher.Encrypt(string(data), key)
+	if err != nil {
+		return err
+	}
+	_, err = c.items.ReplaceOne(ctx, bson.M{"_id": key}, cachedItem{
+		Key:       key,
+		Data:      encrypted,
+		UpdatedAt: time.Now(),
+	}, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (c *MongoCache) Delete(ctx context.Context, key string) error {
+	_, err := c.items.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,242 @@
+// Package backup takes and restores MongoDB backups with mongodump and
+// mongorestore. Each backup is a gzipped archive with a JSON manifest next
+// to it recording what it holds and its checksum.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"user-management/database"
+)
+
+// manifestSuffix ends the file name of every manifest
+const manifestSuffix = ".manifest.json"
+
+// ErrChecksumMismatch is returned when an archive no longer matches its
+// manifest
+var ErrChecksumMismatch = errors.New("archive does not match its checksum")
+
+type Config struct {
+	URI      string
+	Database string
+	// Dir holds the archives and manifests
+	Dir string
+	// Mongodump and Mongorestore are the tool binaries, found on PATH
+	// when empty
+	Mongodump    string
+	Mongorestore string
+}
+
+// Manifest describes a backup archive
+type Manifest struct {
+	ID       string `json:"id"`
+	Database string `json:"database"`
+	// Archive is the archive file name, relative to the manifest
+	Archive string `json:"archive"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+	// Oplog is set for consistent snapshots of the whole deployment, taken
+	// with mongodump --oplog
+	Oplog bool `json:"oplog"`
+	// StartedAt is the restore point: the backup holds every write
+	// committed before it
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Create dumps the database to a new archive. With oplog set it dumps the
+// whole deployment with the oplog, giving a snapshot consistent as of the
+// end of the dump; mongodump cannot do that for a single database.
+func Create(ctx context.Context, cfg Config, oplog bool) (*Manifest, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	started := time.Now().UTC()
+	manifest := &Manifest{
+		ID:        started.Format("20060102T150405Z"),
+		Database:  cfg.Database,
+		Oplog:     oplog,
+		StartedAt: started,
+	}
+	manifest.Archive = manifest.ID + ".archive.gz"
+	archivePath := filepath.Join(cfg.Dir, manifest.Archive)
+
+	args := []string{"--uri=" + cfg.URI, "--archive=" + archivePath, "--gzip"}
+	if oplog {
+		args = append(args, "--oplog")
+	} else {
+		args = append(args, "--db="+cfg.Database)
+	}
+	if err := run(ctx, tool(cfg.Mongodump, "mongodump"), args); err != nil {
+		os.Remove(archivePath)
+		return nil, err
+	}
+
+	size, sum, err := checksum(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Size = size
+	manifest.SHA256 = sum
+	manifest.FinishedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	manifestPath := filepath.Join(cfg.Dir, manifest.ID+manifestSuffix)
+	if err := os.WriteFile(manifestPath, append(data, '\n'), 0o600); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// List returns the manifests in dir, newest first
+func List(dir string) ([]*Manifest, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+manifestSuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make([]*Manifest, 0, len(paths))
+	for _, path := range paths {
+		manifest, err := ReadManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].StartedAt.After(manifests[j].StartedAt)
+	})
+	return manifests, nil
+}
+
+// ReadManifest reads a manifest file
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return &manifest, nil
+}
+
+// Find returns the manifest of the backup with the given ID in dir
+func Find(dir, id string) (*Manifest, error) {
+	return ReadManifest(filepath.Join(dir, id+manifestSuffix))
+}
+
+// Verify checks the archive against its checksum, then has mongorestore
+// read it all without writing anything
+func Verify(ctx context.Context, cfg Config, manifest *Manifest) error {
+	archivePath := filepath.Join(cfg.Dir, manifest.Archive)
+	size, sum, err := checksum(archivePath)
+	if err != nil {
+		return err
+	}
+	if size != manifest.Size || sum != manifest.SHA256 {
+		return ErrChecksumMismatch
+	}
+
+	return run(ctx, tool(cfg.Mongorestore, "mongorestore"), []string{
+		"--uri=" + cfg.URI,
+		"--archive=" + archivePath,
+		"--gzip",
+		"--dryRun",
+	})
+}
+
+// Restore replaces the database with the backup. Collections in the backup
+// are dropped before they are restored. Call InvalidateTokens afterwards.
+func Restore(ctx context.Context, cfg Config, manifest *Manifest) error {
+	archivePath := filepath.Join(cfg.Dir, manifest.Archive)
+	size, sum, err := checksum(archivePath)
+	if err != nil {
+		return err
+	}
+	if size != manifest.Size || sum != manifest.SHA256 {
+		return ErrChecksumMismatch
+	}
+
+	args := []string{
+		"--uri=" + cfg.URI,
+		"--archive=" + archivePath,
+		"--gzip",
+		"--drop",
+		"--nsInclude=" + manifest.Database + ".*",
+	}
+	if manifest.Oplog {
+		args = append(args, "--oplogReplay")
+	}
+	return run(ctx, tool(cfg.Mongorestore, "mongorestore"), args)
+}
+
+// InvalidateTokens revokes every token issued up to now. After a restore,
+// tokens issued after the restore point may belong to accounts or sessions
+// the database no longer knows about, and tokens revoked after it are no
+// longer in the blacklist, so none of them can be trusted.
+func InvalidateTokens(ctx context.Context, db *database.Database, now time.Time) (int64, error) {
+	result, err := db.Users.UpdateMany(ctx, bson.M{}, bson.M{
+		"$set": bson.M{"tokens_valid_after": now},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate tokens: %v", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func tool(path, name string) string {
+	if path != "" {
+		return path
+	}
+	return name
+}
+
+// run runs a MongoDB tool, including its output in the error on failure
+func run(ctx context.Context, name string, args []string) error {
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %v: %s", name, err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// checksum returns the size and SHA-256 of a file
+func checksum(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
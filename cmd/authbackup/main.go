@@ -0,0 +1,210 @@
+// Command authbackup takes, verifies and restores backups of the user
+// management database. It talks to MongoDB directly and needs mongodump
+// and mongorestore on PATH.
+//
+// Usage:
+//
+//	authbackup [-uri uri] [-db name] [-dir dir] create [-oplog]
+//	authbackup [-uri uri] [-db name] [-dir dir] list
+//	authbackup [-uri uri] [-db name] [-dir dir] verify [id]
+//	authbackup [-uri uri] [-db name] [-dir dir] restore -yes id
+//	authbackup [-uri uri] [-db name] invalidate-tokens -yes
+//
+// The URI defaults to the MONGO_URI environment variable.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"user-management/backup"
+	"user-management/database"
+)
+
+func main() {
+	uri := flag.String("uri", envOr("MONGO_URI", "mongodb://localhost:27017"), "MongoDB connection string")
+	dbName := flag.String("db", "user_management", "database name")
+	dir := flag.String("dir", "backups", "directory holding the backups")
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg := backup.Config{
+		URI:      *uri,
+		Database: *dbName,
+		Dir:      *dir,
+	}
+	ctx := context.Background()
+
+	var err error
+	switch args[0] {
+	case "create":
+		err = create(ctx, cfg, args[1:])
+	case "list":
+		err = list(cfg)
+	case "verify":
+		err = verify(ctx, cfg, args[1:])
+	case "restore":
+		err = restore(ctx, cfg, args[1:])
+	case "invalidate-tokens":
+		err = invalidateTokens(ctx, cfg, args[1:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func create(ctx context.Context, cfg backup.Config, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	oplog := fs.Bool("oplog", false, "dump the whole deployment with the oplog for a consistent snapshot")
+	fs.Parse(args)
+
+	manifest, err := backup.Create(ctx, cfg, *oplog)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created backup %s (%d bytes) in %s\n", manifest.ID, manifest.Size, manifest.FinishedAt.Sub(manifest.StartedAt).Round(time.Second))
+	return nil
+}
+
+func list(cfg backup.Config) error {
+	manifests, err := backup.List(cfg.Dir)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		fmt.Println("No backups")
+		return nil
+	}
+	for _, m := range manifests {
+		kind := "database"
+		if m.Oplog {
+			kind = "deployment+oplog"
+		}
+		fmt.Printf("%s  %-16s  %-20s  %d bytes\n", m.ID, kind, m.Database, m.Size)
+	}
+	return nil
+}
+
+func verify(ctx context.Context, cfg backup.Config, args []string) error {
+	manifest, err := findBackup(cfg, args)
+	if err != nil {
+		return err
+	}
+
+	if err := backup.Verify(ctx, cfg, manifest); err != nil {
+		return fmt.Errorf("backup %s is not restorable: %v", manifest.ID, err)
+	}
+	fmt.Printf("Backup %s is intact and restorable\n", manifest.ID)
+	return nil
+}
+
+// restore replaces the database with a backup, then revokes every token so
+// no session from after the restore point survives
+func restore(ctx context.Context, cfg backup.Config, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "confirm that the database is replaced and every session is signed out")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("restore needs a backup ID, see list")
+	}
+	if !*yes {
+		return fmt.Errorf("restore replaces %s and signs out every user, pass -yes to continue", cfg.Database)
+	}
+
+	manifest, err := backup.Find(cfg.Dir, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if err := backup.Restore(ctx, cfg, manifest); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s to %s\n", cfg.Database, manifest.StartedAt.Format(time.RFC3339))
+
+	return revokeAll(ctx, cfg)
+}
+
+func invalidateTokens(ctx context.Context, cfg backup.Config, args []string) error {
+	fs := flag.NewFlagSet("invalidate-tokens", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "confirm that every session is signed out")
+	fs.Parse(args)
+
+	if !*yes {
+		return fmt.Errorf("this signs out every user, pass -yes to continue")
+	}
+	return revokeAll(ctx, cfg)
+}
+
+func revokeAll(ctx context.Context, cfg backup.Config) error {
+	db, err := database.NewDatabase(database.Config{
+		URI:          cfg.URI,
+		Database:     cfg.Database,
+		Timeout:      30 * time.Second,
+		QueryTimeout: 5 * time.Minute,
+	})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	users, err := backup.InvalidateTokens(ctx, db, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Invalidated the tokens of %d users\n", users)
+	return nil
+}
+
+// findBackup returns the backup named in args, or the latest one
+func findBackup(cfg backup.Config, args []string) (*backup.Manifest, error) {
+	if len(args) > 0 {
+		return backup.Find(cfg.Dir, args[0])
+	}
+
+	manifests, err := backup.List(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no backups in %s", cfg.Dir)
+	}
+	return manifests[0], nil
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  authbackup [flags] create [-oplog]
+  authbackup [flags] list
+  authbackup [flags] verify [id]
+  authbackup [flags] restore -yes id
+  authbackup [flags] invalidate-tokens -yes
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "authbackup: "+format+"\n", args...)
+	os.Exit(1)
+}
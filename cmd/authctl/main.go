@@ -0,0 +1,398 @@
+// Command authctl is an admin client for the user management service.
+//
+// Usage:
+//
+//	authctl [-addr host:port] [-token jwt] config export [-o file]
+//	authctl [-addr host:port] [-token jwt] config import [-dry-run] file
+//	authctl [-addr host:port] [-token jwt] users import [-format f] [-dry-run] [-report file] file
+//	authctl [-addr host:port] [-token jwt] users export [-o file] [-email prefix] [-active bool] [-resume-after id]
+//	authctl [-addr host:port] [-token jwt] audit replay [-projections list] [-batch n]
+//
+// Pass -tls, -ca-file or -cert-file to connect to a server that serves
+// TLS.
+//
+// The token defaults to the AUTHCTL_TOKEN environment variable and must
+// belong to an account with the admin role.
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"user-management/importers"
+	pb "user-management/proto"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:50051", "gRPC server address")
+	token := flag.String("token", os.Getenv("AUTHCTL_TOKEN"), "admin Bearer token")
+	timeout := flag.Duration("timeout", 30*time.Second, "request timeout")
+	useTLS := flag.Bool("tls", false, "connect over TLS, verifying the server against the system roots unless -ca-file is set")
+	caFile := flag.String("ca-file", "", "CA bundle to verify the server against, implies -tls")
+	certFile := flag.String("cert-file", "", "client certificate for mutual TLS, implies -tls")
+	keyFile := flag.String("key-file", "", "client certificate key for mutual TLS")
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	if *token == "" {
+		fatalf("a token is required, pass -token or set AUTHCTL_TOKEN")
+	}
+
+	transport := insecure.NewCredentials()
+	if *useTLS || *caFile != "" || *certFile != "" {
+		var err error
+		if transport, err = transportCredentials(*caFile, *certFile, *keyFile); err != nil {
+			fatalf("%v", err)
+		}
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		fatalf("failed to connect to %s: %v", *addr, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+*token)
+
+	client := pb.NewAdminServiceClient(conn)
+
+	switch args[0] + " " + args[1] {
+	case "config export":
+		err = exportConfig(ctx, client, args[2:])
+	case "config import":
+		err = importConfig(ctx, client, args[2:])
+	case "users import":
+		err = importUsers(ctx, client, args[2:])
+	case "users export":
+		err = exportUsers(ctx, client, args[2:])
+	case "audit replay":
+		err = replayAuditLog(ctx, client, args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func exportConfig(ctx context.Context, client pb.AdminServiceClient, args []string) error {
+	fs := flag.NewFlagSet("config export", flag.ExitOnError)
+	output := fs.String("o", "", "write the snapshot to this file instead of stdout")
+	fs.Parse(args)
+
+	resp, err := client.ExportConfig(ctx, &pb.ExportConfigRequest{})
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		fmt.Println(resp.Snapshot)
+		return nil
+	}
+	return os.WriteFile(*output, []byte(resp.Snapshot+"\n"), 0o600)
+}
+
+func importConfig(ctx context.Context, client pb.AdminServiceClient, args []string) error {
+	fs := flag.NewFlagSet("config import", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "validate the snapshot and show changes without saving")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("config import needs a snapshot file, or - for stdin")
+	}
+
+	snapshot, err := readInput(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %v", err)
+	}
+
+	resp, err := client.ImportConfig(ctx, &pb.ImportConfigRequest{
+		Snapshot: string(snapshot),
+		DryRun:   *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s (from %s)\n", resp.Message, resp.SourceEnvironment)
+	if len(resp.ChangedSettings) == 0 {
+		fmt.Println("No settings changed")
+	} else {
+		fmt.Printf("Changed settings:\n  %s\n", strings.Join(resp.ChangedSettings, "\n  "))
+	}
+	return nil
+}
+
+// importUsers converts a user export and sends it in batches the server
+// accepts, then reports every record that was not imported
+func importUsers(ctx context.Context, client pb.AdminServiceClient, args []string) error {
+	fs := flag.NewFlagSet("users import", flag.ExitOnError)
+	format := fs.String("format", "native", "export format: native, auth0 or firebase")
+	dryRun := fs.Bool("dry-run", false, "validate the users without importing them")
+	batchSize := fs.Int("batch", 500, "users per request, at most 1000")
+	reportFile := fs.String("report", "", "write the skipped records to this JSON file")
+	var firebaseHash importers.FirebaseHashConfig
+	fs.StringVar(&firebaseHash.SignerKey, "firebase-signer-key", "", "base64 signer key of the Firebase project")
+	fs.StringVar(&firebaseHash.SaltSeparator, "firebase-salt-separator", "", "base64 salt separator of the Firebase project")
+	fs.IntVar(&firebaseHash.Rounds, "firebase-rounds", 8, "scrypt rounds of the Firebase project")
+	fs.IntVar(&firebaseHash.MemCost, "firebase-mem-cost", 14, "scrypt memory cost of the Firebase project")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("users import needs a users file, or - for stdin")
+	}
+	if *batchSize < 1 || *batchSize > 1000 {
+		return fmt.Errorf("batch must be between 1 and 1000")
+	}
+
+	data, err := readInput(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to read users: %v", err)
+	}
+
+	var result *importers.Result
+	switch *format {
+	case "native":
+		result, err = importers.Native(bytes.NewReader(data))
+	case "auth0":
+		result, err = importers.Auth0(bytes.NewReader(data))
+	case "firebase":
+		result, err = importers.Firebase(bytes.NewReader(data), firebaseHash)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Records the converter skipped come first in the report
+	skipped := result.Skipped
+	var imported int32
+	for start := 0; start < len(result.Users); start += *batchSize {
+		end := min(start+*batchSize, len(result.Users))
+		resp, err := client.ImportUsers(ctx, &pb.ImportUsersRequest{
+			Users:  result.Users[start:end],
+			DryRun: *dryRun,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import users %d-%d: %v", start, end-1, err)
+		}
+
+		imported += resp.Imported
+		for _, s := range resp.Skipped {
+			skipped = append(skipped, importers.Skipped{
+				SourceID: result.SourceID(start + int(s.Index)),
+				Email:    s.Email,
+				Reason:   s.Reason,
+			})
+		}
+	}
+
+	for _, s := range skipped {
+		fmt.Printf("skipped %s %s: %s\n", s.SourceID, s.Email, s.Reason)
+	}
+	if *dryRun {
+		fmt.Printf("Dry run: %d users can be imported, %d would be skipped\n", imported, len(skipped))
+	} else {
+		fmt.Printf("Imported %d users, skipped %d\n", imported, len(skipped))
+	}
+
+	if *reportFile != "" {
+		report, err := json.MarshalIndent(map[string]interface{}{
+			"format":   *format,
+			"dry_run":  *dryRun,
+			"imported": imported,
+			"skipped":  skipped,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(*reportFile, append(report, '\n'), 0o600)
+	}
+	return nil
+}
+
+// exportUsers writes the users the server streams as JSON lines. An
+// interrupted export prints the last user written, so it can be resumed.
+func exportUsers(ctx context.Context, client pb.AdminServiceClient, args []string) error {
+	fs := flag.NewFlagSet("users export", flag.ExitOnError)
+	output := fs.String("o", "", "write the users to this file instead of stdout, appending with -resume-after")
+	resumeAfter := fs.String("resume-after", "", "ID of the last user of an interrupted export")
+	emailFilter := fs.String("email", "", "only users whose email starts with this")
+	active := fs.String("active", "", "only active (true) or inactive (false) users")
+	fs.Parse(args)
+
+	req := &pb.ExportUsersRequest{
+		EmailFilter: *emailFilter,
+		ResumeAfter: *resumeAfter,
+	}
+	switch *active {
+	case "":
+	case "true", "false":
+		isActive := *active == "true"
+		req.IsActive = &isActive
+	default:
+		return fmt.Errorf("active must be true or false")
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if *resumeAfter != "" {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		file, err := os.OpenFile(*output, flags, 0o600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	stream, err := client.ExportUsers(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	var exported int
+	lastID := *resumeAfter
+	for {
+		user, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if lastID == "" {
+				return fmt.Errorf("export failed: %v", err)
+			}
+			return fmt.Errorf("export stopped after %d users, continue with -resume-after %s: %v", exported, lastID, err)
+		}
+
+		line, err := protojson.Marshal(user)
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		exported++
+		lastID = user.Id
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d users\n", exported)
+	return nil
+}
+
+// replayAuditLog rebuilds the audit projections batch by batch, printing
+// progress after each batch
+func replayAuditLog(ctx context.Context, client pb.AdminServiceClient, args []string) error {
+	fs := flag.NewFlagSet("audit replay", flag.ExitOnError)
+	projections := fs.String("projections", "", "comma-separated projections to rebuild, all when empty")
+	batchSize := fs.Int("batch", 500, "entries per request, at most 5000")
+	fs.Parse(args)
+
+	if *batchSize < 1 || *batchSize > 5000 {
+		return fmt.Errorf("batch must be between 1 and 5000")
+	}
+
+	req := &pb.ReplayAuditLogRequest{BatchSize: int32(*batchSize)}
+	if *projections != "" {
+		req.Projections = strings.Split(*projections, ",")
+	}
+
+	var replayed int64
+	for {
+		resp, err := client.ReplayAuditLog(ctx, req)
+		if err != nil {
+			return fmt.Errorf("replay stopped after %d entries: %v", replayed, err)
+		}
+
+		replayed += int64(resp.Processed)
+		if resp.Done {
+			fmt.Printf("Rebuilt %s from %d audit entries\n", strings.Join(resp.Projections, ", "), replayed)
+			return nil
+		}
+		fmt.Printf("Replayed %d of %d audit entries\n", replayed, replayed+resp.Remaining)
+
+		req.AfterId = resp.AfterId
+		req.UntilId = resp.UntilId
+	}
+}
+
+// readInput reads a file, or stdin for "-"
+func readInput(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(name)
+}
+
+// transportCredentials returns TLS credentials verifying the server against
+// caFile, or the system roots when empty, and presenting the client
+// certificate when one is given
+func transportCredentials(caFile, certFile, keyFile string) (credentials.TransportCredentials, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("CA bundle %s has no PEM certificates", caFile)
+		}
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(config), nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  authctl [flags] config export [-o file]
+  authctl [flags] config import [-dry-run] file
+  authctl [flags] users import [-format native|auth0|firebase] [-dry-run] [-batch n] [-report file] file
+  authctl [flags] users export [-o file] [-email prefix] [-active true|false] [-resume-after id]
+  authctl [flags] audit replay [-projections login_history,audit_stats] [-batch n]
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "authctl: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,139 @@
+// Command contractgen generates contract.Cases from the (contract) method
+// options in the compiled API descriptors. Run it through go generate in
+// the contract package after changing proto/user.proto:
+//
+//	go generate ./contract
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	pb "user-management/proto"
+)
+
+// generatedCase is one case in the generated file
+type generatedCase struct {
+	method  string
+	name    string
+	caller  pb.Caller
+	request string
+	code    codes.Code
+}
+
+func main() {
+	output := flag.String("o", "cases.gen.go", "output file")
+	flag.Parse()
+
+	cases, err := collect(pb.File_proto_user_proto)
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	source, err := render(cases)
+	if err != nil {
+		fatalf("failed to format generated code: %v", err)
+	}
+	if err := os.WriteFile(*output, source, 0o644); err != nil {
+		fatalf("failed to write %s: %v", *output, err)
+	}
+}
+
+// collect turns the contracts of every method in file into cases, in
+// declaration order
+func collect(file protoreflect.FileDescriptor) ([]generatedCase, error) {
+	var cases []generatedCase
+	services := file.Services()
+	for i := 0; i < services.Len(); i++ {
+		methods := services.Get(i).Methods()
+		for j := 0; j < methods.Len(); j++ {
+			method := methods.Get(j)
+			options, ok := method.Options().(*descriptorpb.MethodOptions)
+			if !ok || !proto.HasExtension(options, pb.E_Contract) {
+				continue
+			}
+			contract := proto.GetExtension(options, pb.E_Contract).(*pb.Contract)
+			fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+
+			authRequest := contract.AuthRequest
+			if authRequest == "" {
+				authRequest = "{}"
+			}
+			if contract.RequiresAuth || contract.RequiresAdmin {
+				cases = append(cases, generatedCase{
+					method:  fullMethod,
+					name:    "rejects a missing token",
+					caller:  pb.Caller_CALLER_ANONYMOUS,
+					request: authRequest,
+					code:    codes.Unauthenticated,
+				})
+			}
+			if contract.RequiresAdmin {
+				cases = append(cases, generatedCase{
+					method:  fullMethod,
+					name:    "rejects a caller without the admin role",
+					caller:  pb.Caller_CALLER_USER,
+					request: authRequest,
+					code:    codes.PermissionDenied,
+				})
+			}
+
+			for _, errorCase := range contract.Errors {
+				var code codes.Code
+				if err := code.UnmarshalJSON([]byte(strconv.Quote(errorCase.Code))); err != nil {
+					return nil, fmt.Errorf("%s: case %q has unknown code %q", fullMethod, errorCase.Name, errorCase.Code)
+				}
+				request := errorCase.Request
+				if request == "" {
+					request = "{}"
+				}
+				cases = append(cases, generatedCase{
+					method:  fullMethod,
+					name:    errorCase.Name,
+					caller:  errorCase.Caller,
+					request: request,
+					code:    code,
+				})
+			}
+		}
+	}
+	return cases, nil
+}
+
+func render(cases []generatedCase) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by contractgen. DO NOT EDIT.\n\n")
+	b.WriteString("package contract\n\n")
+	b.WriteString("import (\n\t\"google.golang.org/grpc/codes\"\n\n\tpb \"user-management/proto\"\n)\n\n")
+	b.WriteString("// Cases holds a case for every requirement annotated in proto/user.proto\n")
+	b.WriteString("var Cases = []Case{\n")
+	for _, c := range cases {
+		fmt.Fprintf(&b, "\t{\n\t\tMethod: %q,\n\t\tName: %q,\n\t\tCaller: pb.Caller_%s,\n\t\tRequest: %s,\n\t\tCode: codes.%s,\n\t},\n",
+			c.method, c.name, pb.Caller_name[int32(c.caller)], quote(c.request), c.code)
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// quote returns a Go string literal, raw when that reads better for JSON
+func quote(s string) string {
+	if !strings.ContainsAny(s, "`\n") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "contractgen: "+format+"\n", args...)
+	os.Exit(1)
+}
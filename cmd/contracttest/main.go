@@ -0,0 +1,86 @@
+// Command contracttest runs the API conformance cases in package contract
+// against a server and exits non-zero if any fails.
+//
+// Usage:
+//
+//	contracttest [-addr host:port] [-user-token jwt] [-admin-token jwt] [-run substring] [-v]
+//
+// Cases that need a signed-in user or admin are skipped without the
+// matching token, and cases for methods the server returns UNIMPLEMENTED
+// for are skipped too.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"user-management/contract"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:50051", "gRPC server address")
+	userToken := flag.String("user-token", os.Getenv("CONTRACT_USER_TOKEN"), "token of a user without the admin role")
+	adminToken := flag.String("admin-token", os.Getenv("CONTRACT_ADMIN_TOKEN"), "token of an admin")
+	run := flag.String("run", "", "only run cases whose method contains this")
+	verbose := flag.Bool("v", false, "also print passing and skipped cases")
+	timeout := flag.Duration("timeout", time.Minute, "timeout for the whole run")
+	flag.Parse()
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fatalf("failed to connect to %s: %v", *addr, err)
+	}
+	defer conn.Close()
+
+	var cases []contract.Case
+	for _, c := range contract.Cases {
+		if strings.Contains(c.Method, *run) {
+			cases = append(cases, c)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	results := contract.Run(ctx, conn, cases, contract.Config{
+		UserToken:  *userToken,
+		AdminToken: *adminToken,
+	})
+
+	var passed, failed, skipped int
+	for _, result := range results {
+		label := fmt.Sprintf("%s: %s", result.Case.Method, result.Case.Name)
+		switch {
+		case result.Skipped:
+			skipped++
+			if *verbose {
+				fmt.Printf("SKIP %s (%s)\n", label, result.SkipReason)
+			}
+		case result.Err != nil:
+			failed++
+			fmt.Printf("FAIL %s: %v\n", label, result.Err)
+		default:
+			passed++
+			if *verbose {
+				fmt.Printf("PASS %s\n", label)
+			}
+		}
+	}
+
+	fmt.Printf("%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "contracttest: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,72 @@
+// Command kmssecret encrypts a secret of the server's configuration with a
+// KMS key, so it can be stored in the config file or the environment
+// without being readable there. The server decrypts it at startup with
+// KMS_KEY.
+//
+// Usage:
+//
+//	kmssecret [-key uri] NAME < secret
+//
+// NAME is the secret's environment variable, such as JWT_SECRET: the value
+// can only be used for that secret. The key defaults to the KMS_KEY
+// environment variable. One trailing newline is removed from the secret.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"user-management/kms"
+)
+
+func main() {
+	keyURI := flag.String("key", os.Getenv("KMS_KEY"), "URI of the KMS key")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() != 1 || *keyURI == "" {
+		usage()
+		os.Exit(2)
+	}
+	name := flag.Arg(0)
+
+	secret, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fatalf("failed to read secret: %v", err)
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(string(secret), "\n"), "\r")
+	if value == "" {
+		fatalf("the secret is empty")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	key, err := kms.Open(ctx, *keyURI)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	encrypted, err := kms.EncryptSecret(ctx, key, name, value)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Println(encrypted)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  kmssecret [-key uri] NAME < secret
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "kmssecret: "+format+"\n", args...)
+	os.Exit(1)
+}
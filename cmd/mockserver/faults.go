@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/apierrors"
+)
+
+// faults injects latency and errors into RPCs
+type faults struct {
+	latency   time.Duration
+	errorRate float64
+	// methods maps a method name, such as "Login", to the code it always
+	// fails with
+	methods map[string]codes.Code
+
+	mu     sync.Mutex
+	random *rand.Rand
+}
+
+// parseMethodFaults parses "Login=UNAVAILABLE,GetProfile=NOT_FOUND"
+func parseMethodFaults(spec string) (map[string]codes.Code, error) {
+	methods := make(map[string]codes.Code)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		method, name, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("fault %q must look like Method=CODE", entry)
+		}
+
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(strings.TrimSpace(name))))); err != nil {
+			return nil, fmt.Errorf("fault %q: unknown code %q", entry, name)
+		}
+		methods[strings.TrimSpace(method)] = code
+	}
+	return methods, nil
+}
+
+// UnaryServerInterceptor delays every call by the configured latency, then
+// fails it if its method is listed or, at the configured rate, with
+// UNAVAILABLE
+func (f *faults) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if f.latency > 0 {
+			select {
+			case <-time.After(f.latency):
+			case <-ctx.Done():
+				return nil, status.FromContextError(ctx.Err()).Err()
+			}
+		}
+
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		if code, ok := f.methods[method]; ok {
+			return nil, injectedError(code)
+		}
+		if f.errorRate > 0 && f.roll() < f.errorRate {
+			return nil, injectedError(codes.Unavailable)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// roll draws from the seeded source, so a seed gives the same failures in
+// the same order on every run
+func (f *faults) roll() float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.random.Float64()
+}
+
+// injectedError builds the error for code. Rate limits carry a retry delay
+// like the real service's.
+func injectedError(code codes.Code) error {
+	if code == codes.ResourceExhausted {
+		return apierrors.RateLimited("injected fault: too many requests", 30*time.Second)
+	}
+	return status.Errorf(code, "injected fault")
+}
@@ -0,0 +1,86 @@
+// Command mockserver serves an in-memory mock of AuthService and
+// UserService for client development. It needs no MongoDB and starts with
+// the same fixtures on every run:
+//
+//	alice@example.com, bob@example.com, admin@example.com (password "Password1!")
+//	deleted@example.com, a deleted account that cannot log in
+//
+// Fixture accounts also have fixed tokens: mock-token-alice, mock-token-bob
+// and mock-token-admin. Other RPCs return UNIMPLEMENTED.
+//
+// Usage:
+//
+//	mockserver [-addr :50052] [-users n] [-latency d] [-error-rate f] [-seed n] [-fail Method=CODE,...]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	pb "user-management/proto"
+)
+
+func main() {
+	addr := flag.String("addr", ":50052", "gRPC listen address")
+	users := flag.Int("users", 25, "extra generated users, for paging through ListUsers")
+	f := &faults{}
+	flag.DurationVar(&f.latency, "latency", 0, "delay added to every RPC")
+	flag.Float64Var(&f.errorRate, "error-rate", 0, "fraction of RPCs that fail with UNAVAILABLE, from 0 to 1")
+	seed := flag.Int64("seed", 1, "seed for -error-rate, so failures repeat across runs")
+	fail := flag.String("fail", "", "methods that always fail, e.g. Login=UNAVAILABLE,Register=RESOURCE_EXHAUSTED")
+	flag.Parse()
+
+	if f.errorRate < 0 || f.errorRate > 1 {
+		fatalf("-error-rate must be between 0 and 1")
+	}
+	methods, err := parseMethodFaults(*fail)
+	if err != nil {
+		fatalf("invalid -fail: %v", err)
+	}
+	f.methods = methods
+	f.random = rand.New(rand.NewSource(*seed))
+
+	store := newStore(*users)
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(f.UnaryServerInterceptor()))
+	pb.RegisterAuthServiceServer(server, &authService{store: store})
+	pb.RegisterUserServiceServer(server, &userService{store: store})
+	reflection.Register(server)
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fatalf("failed to listen on %s: %v", *addr, err)
+	}
+
+	log.Printf("Mock server listening on %s", *addr)
+	if err := server.Serve(lis); err != nil {
+		fatalf("failed to serve: %v", err)
+	}
+}
+
+// bearerToken extracts the token from an authorization value, accepting the
+// "Bearer <token>" form
+func bearerToken(value string) (string, error) {
+	token := strings.TrimSpace(value)
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	token = strings.Trim(token, `"`)
+	if token == "" {
+		return "", fmt.Errorf("missing bearer token")
+	}
+	return token, nil
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "mockserver: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/apierrors"
+	"user-management/database"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// authService mocks the login, registration, token, password strength and
+// auth options RPCs. Other AuthService RPCs return UNIMPLEMENTED.
+type authService struct {
+	pb.UnimplementedAuthServiceServer
+	store *store
+}
+
+func (s *authService) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	if req.Password == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "password is required")
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	user := s.store.byEmail(req.Email)
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "invalid email or password")
+	}
+	if user.password != req.Password {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid email or password")
+	}
+	if !user.isActive || user.isDeleted {
+		return nil, status.Errorf(codes.PermissionDenied, "account is deactivated/deleted")
+	}
+
+	return &pb.LoginResponse{
+		Token:   s.store.issueToken(user.id),
+		User:    user.proto(),
+		Message: "Login success",
+	}, nil
+}
+
+func (s *authService) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if _, ok := s.store.tokens[req.Token]; !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+	delete(s.store.tokens, req.Token)
+
+	return &pb.LogoutResponse{
+		Message: "Logout successful",
+	}, nil
+}
+
+func (s *authService) LogoutAllDevices(ctx context.Context, req *pb.LogoutAllDevicesRequest) (*pb.LogoutAllDevicesResponse, error) {
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	userID, ok := s.store.tokens[req.Token]
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+	for token, tokenUserID := range s.store.tokens {
+		if tokenUserID == userID {
+			delete(s.store.tokens, token)
+		}
+	}
+
+	return &pb.LogoutAllDevicesResponse{
+		Message: "Logged out of all devices",
+	}, nil
+}
+
+func (s *authService) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	req.Email = utils.SanitizeString(req.Email)
+	req.Name = utils.SanitizeString(req.Name)
+
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		var validationErr utils.ValidationError
+		if errors.As(err, &validationErr) && len(validationErr.Requirements) > 0 {
+			return nil, apierrors.WeakPassword(validationErr.Field, validationErr.Error(), validationErr.Requirements)
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	if err := utils.ValidateName(req.Name, "name"); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if s.store.byEmail(req.Email) != nil {
+		return nil, apierrors.EmailTaken("email already exists")
+	}
+	user := s.store.addUser(req.Email, req.Name, req.Password)
+
+	return &pb.RegisterResponse{
+		User:    user.proto(),
+		Message: "Registration successful",
+	}, nil
+}
+
+func (s *authService) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	// The mock issues no action tokens
+	user := s.store.byID(s.store.tokens[req.Token])
+	if user == nil || req.Purpose != "" {
+		return &pb.ValidateTokenResponse{Valid: false}, nil
+	}
+
+	response := &pb.ValidateTokenResponse{
+		Valid:     true,
+		UserId:    user.id,
+		Email:     user.email,
+		ExpiresAt: timestamppb.New(time.Now().Add(s.store.tokenTTL)),
+		Active:    user.isActive && !user.isDeleted,
+	}
+	if user.email == "admin@example.com" {
+		response.Roles = []string{"admin"}
+	}
+	return response, nil
+}
+
+// EvaluatePassword scores passwords against the default password policy
+func (s *authService) EvaluatePassword(ctx context.Context, req *pb.EvaluatePasswordRequest) (*pb.EvaluatePasswordResponse, error) {
+	if len(req.Password) > utils.MaxPasswordLength {
+		return nil, status.Errorf(codes.InvalidArgument, "password must be less than %d characters", utils.MaxPasswordLength)
+	}
+
+	policy := utils.DefaultPasswordPolicy
+	strength := utils.EstimatePasswordStrength(req.Password, req.Email, req.Name)
+	unmet := policy.UnmetWithStrength(req.Password, strength)
+
+	return &pb.EvaluatePasswordResponse{
+		Score:             int32(strength.Score),
+		CrackTimeSeconds:  strength.CrackTimeSeconds,
+		CrackTimeDisplay:  utils.FormatCrackTime(strength.CrackTimeSeconds),
+		UnmetRequirements: unmet,
+		Requirements:      policy.Requirements(),
+		Acceptable:        req.Password != "" && len(unmet) == 0,
+		Warning:           strength.Warning,
+	}, nil
+}
+
+// GetAuthOptions offers password logins, the only method the mock has
+func (s *authService) GetAuthOptions(ctx context.Context, req *pb.GetAuthOptionsRequest) (*pb.GetAuthOptionsResponse, error) {
+	if req.Email != "" {
+		if err := utils.ValidateEmail(utils.SanitizeString(req.Email)); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+		}
+	}
+	// The mock has no organizations
+	if req.OrganizationId != "" {
+		if _, err := primitive.ObjectIDFromHex(req.OrganizationId); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID format")
+		}
+		return nil, status.Errorf(codes.NotFound, "organization not found")
+	}
+
+	return &pb.GetAuthOptionsResponse{
+		Methods: []*pb.AuthMethod{{Type: "password", Required: true}},
+	}, nil
+}
+
+// userService mocks the profile RPCs. Other UserService RPCs return
+// UNIMPLEMENTED.
+type userService struct {
+	pb.UnimplementedUserServiceServer
+	store *store
+}
+
+// requireSelf returns the caller's account when it is the user the request
+// is about, or the request names no user. Like the real service, it checks
+// the token before the request.
+// The caller holds the store lock.
+func (s *userService) requireSelf(ctx context.Context, userID string) (*mockUser, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = values[0]
+		}
+	}
+	token, err := bearerToken(token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "missing bearer token")
+	}
+
+	caller := s.store.byID(s.store.tokens[token])
+	if caller == nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+
+	if userID == "" {
+		return caller, nil
+	}
+	id, err := models.ParseID(userID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+	if caller.id != id.String() {
+		return nil, status.Errorf(codes.PermissionDenied, "cannot act on another user's profile")
+	}
+	return caller, nil
+}
+
+// requireAdmin returns the caller's account when it is the admin account.
+// The caller holds the store lock.
+func (s *userService) requireAdmin(ctx context.Context) (*mockUser, error) {
+	caller, err := s.requireSelf(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if caller.email != "admin@example.com" {
+		return nil, status.Errorf(codes.PermissionDenied, "admin role required")
+	}
+	return caller, nil
+}
+
+func (s *userService) GetProfile(ctx context.Context, req *pb.GetProfileRequest) (*pb.GetProfileResponse, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	user, err := s.requireSelf(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetProfileResponse{
+		User: user.proto(),
+	}, nil
+}
+
+func (s *userService) UpdateProfile(ctx context.Context, req *pb.UpdateProfileRequest) (*pb.UpdateProfileResponse, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	user, err := s.requireSelf(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Name = utils.SanitizeString(req.Name)
+	req.Email = utils.SanitizeString(req.Email)
+
+	if req.Name != "" {
+		if err := utils.ValidateName(req.Name, "name"); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+		}
+	}
+	if req.Email != "" {
+		if err := utils.ValidateEmail(req.Email); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+		}
+		if existing := s.store.byEmail(req.Email); existing != nil && existing != user {
+			return nil, apierrors.EmailTaken("email is already taken")
+		}
+	}
+
+	if req.Name != "" {
+		user.name = req.Name
+	}
+	if req.Email != "" {
+		user.email = req.Email
+	}
+	user.updatedAt = s.store.now()
+
+	return &pb.UpdateProfileResponse{
+		User:    user.proto(),
+		Message: "Profile updated successfully",
+	}, nil
+}
+
+func (s *userService) DeleteProfile(ctx context.Context, req *pb.DeleteProfileRequest) (*pb.DeleteProfileResponse, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	user, err := s.requireSelf(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	user.isDeleted = true
+	user.isActive = false
+	user.updatedAt = s.store.now()
+
+	return &pb.DeleteProfileResponse{
+		Message: "Profile deleted successfully",
+	}, nil
+}
+
+func (s *userService) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	// Filters are validated like the real service's, though the mock
+	// matches them itself
+	search := database.UserSearch{
+		NameFilter:  utils.SanitizeString(req.NameFilter),
+		EmailFilter: utils.SanitizeString(req.EmailFilter),
+		IsActive:    req.IsActive,
+		SortBy:      req.SortBy,
+		SortOrder:   req.SortOrder,
+	}
+	if req.CreatedAfter != nil {
+		search.CreatedAfter = req.CreatedAfter.AsTime()
+	}
+	if req.CreatedBefore != nil {
+		search.CreatedBefore = req.CreatedBefore.AsTime()
+	}
+	if err := utils.ValidateUserSearch(search); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	users := s.store.list(search)
+	start := int((page - 1) * pageSize)
+	if start > len(users) {
+		start = len(users)
+	}
+	end := start + int(pageSize)
+	if end > len(users) {
+		end = len(users)
+	}
+
+	var pbUsers []*pb.User
+	for _, user := range users[start:end] {
+		// Like the real service, listings leave out external IDs
+		pbUser := user.proto()
+		pbUser.ExternalIds = nil
+		pbUsers = append(pbUsers, pbUser)
+	}
+
+	return &pb.ListUsersResponse{
+		Users:      pbUsers,
+		TotalCount: int32(len(users)),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/database"
+	pb "user-management/proto"
+)
+
+// fixturePassword is the password of every fixture account
+const fixturePassword = "Password1!"
+
+// epoch is the mock clock's start. The clock advances one second per write,
+// so timestamps are the same on every run.
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type mockUser struct {
+	id          string
+	email       string
+	name        string
+	password    string
+	createdAt   time.Time
+	updatedAt   time.Time
+	isActive    bool
+	isDeleted   bool
+	externalIDs map[string]string
+}
+
+func (u *mockUser) proto() *pb.User {
+	return &pb.User{
+		Id:          u.id,
+		Email:       u.email,
+		Name:        u.name,
+		CreatedAt:   timestamppb.New(u.createdAt),
+		UpdatedAt:   timestamppb.New(u.updatedAt),
+		IsActive:    u.isActive,
+		IsDeleted:   u.isDeleted,
+		ExternalIds: u.externalIDs,
+	}
+}
+
+// store holds the mock's users and tokens in memory
+type store struct {
+	mu     sync.Mutex
+	users  []*mockUser
+	tokens map[string]string
+	ticks  int
+	nextID int
+	// tokenTTL is reported as the expiry of issued tokens
+	tokenTTL time.Duration
+}
+
+// newStore returns a store with the fixture accounts and extra generated
+// users for paging through ListUsers
+func newStore(extraUsers int) *store {
+	s := &store{
+		tokens:   make(map[string]string),
+		tokenTTL: 24 * time.Hour,
+	}
+
+	fixtures := []struct {
+		email, name, token string
+		deleted            bool
+	}{
+		{"alice@example.com", "Alice Example", "mock-token-alice", false},
+		{"bob@example.com", "Bob Example", "mock-token-bob", false},
+		{"admin@example.com", "Admin User", "mock-token-admin", false},
+		{"deleted@example.com", "Deleted User", "", true},
+	}
+	for _, f := range fixtures {
+		user := s.addUser(f.email, f.name, fixturePassword)
+		if f.deleted {
+			user.isActive = false
+			user.isDeleted = true
+		}
+		if f.token != "" {
+			s.tokens[f.token] = user.id
+		}
+	}
+	s.users[0].externalIDs = map[string]string{"legacy_crm": "CRM-1001"}
+
+	for i := 1; i <= extraUsers; i++ {
+		s.addUser(fmt.Sprintf("user%03d@example.com", i), fmt.Sprintf("Test User %03d", i), fixturePassword)
+	}
+	return s
+}
+
+// now advances the mock clock
+func (s *store) now() time.Time {
+	s.ticks++
+	return epoch.Add(time.Duration(s.ticks) * time.Second)
+}
+
+// addUser creates a user. The caller holds the lock or owns the store.
+func (s *store) addUser(email, name, password string) *mockUser {
+	s.nextID++
+	now := s.now()
+	user := &mockUser{
+		id:        fmt.Sprintf("%024x", s.nextID),
+		email:     email,
+		name:      name,
+		password:  password,
+		createdAt: now,
+		updatedAt: now,
+		isActive:  true,
+	}
+	s.users = append(s.users, user)
+	return user
+}
+
+// byEmail finds a user by email, including deleted users
+func (s *store) byEmail(email string) *mockUser {
+	for _, user := range s.users {
+		if strings.EqualFold(user.email, email) {
+			return user
+		}
+	}
+	return nil
+}
+
+// byID finds a user that has not been deleted
+func (s *store) byID(id string) *mockUser {
+	for _, user := range s.users {
+		if user.id == id && !user.isDeleted {
+			return user
+		}
+	}
+	return nil
+}
+
+// issueToken returns a new opaque token for the user
+func (s *store) issueToken(userID string) string {
+	token := fmt.Sprintf("mock-token-%d", len(s.tokens)+1)
+	for s.tokens[token] != "" {
+		token += "x"
+	}
+	s.tokens[token] = userID
+	return token
+}
+
+// list returns the users matching the filters, newest first
+func (s *store) list(search database.UserSearch) []*mockUser {
+	var users []*mockUser
+	for _, user := range s.users {
+		if user.isDeleted {
+			continue
+		}
+		if search.NameFilter != "" && !strings.Contains(strings.ToLower(user.name), strings.ToLower(search.NameFilter)) {
+			continue
+		}
+		if search.EmailFilter != "" && !strings.HasPrefix(strings.ToLower(user.email), strings.ToLower(search.EmailFilter)) {
+			continue
+		}
+		if search.IsActive != nil && user.isActive != *search.IsActive {
+			continue
+		}
+		if !search.CreatedAfter.IsZero() && !user.createdAt.After(search.CreatedAfter) {
+			continue
+		}
+		if !search.CreatedBefore.IsZero() && !user.createdAt.Before(search.CreatedBefore) {
+			continue
+		}
+		users = append(users, user)
+	}
+	sort.SliceStable(users, func(i, j int) bool {
+		c := compareUsers(users[i], users[j], search.SortBy)
+		if search.SortOrder == database.SortOrderAsc {
+			return c < 0
+		}
+		return c > 0
+	})
+	return users
+}
+
+// compareUsers orders two users by a sort field of ListUsers, then by ID
+func compareUsers(a, b *mockUser, field string) int {
+	var c int
+	switch field {
+	case "updated_at":
+		c = a.updatedAt.Compare(b.updatedAt)
+	case "name":
+		c = strings.Compare(a.name, b.name)
+	case "email":
+		c = strings.Compare(a.email, b.email)
+	default:
+		c = a.createdAt.Compare(b.createdAt)
+	}
+	if c == 0 {
+		c = strings.Compare(a.id, b.id)
+	}
+	return c
+}
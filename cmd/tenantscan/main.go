@@ -0,0 +1,372 @@
+// Command tenantscan checks statically that the queries of organization
+// admins' RPCs are limited to the organization. In the methods of the
+// given receivers, every query on a collection holding organizations' data
+// must have a filter pinning the organization, such as org_id. Queries
+// before the method calls tenancy.SetTenant, and queries whose context
+// comes from tenancy.Unscoped, are not checked. It exits with status 1
+// when it finds a query that isn't.
+//
+// Usage:
+//
+//	tenantscan [-receivers OrganizationService] [dir ...]
+//
+// The directories default to services. The server checks the queries at
+// runtime too, including those made by other packages, when
+// TENANT_ISOLATION_CHECKS is set.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"user-management/tenancy"
+)
+
+// queryMethods are the Collection methods taking a filter, or a pipeline
+// for Aggregate, after the context
+var queryMethods = map[string]bool{
+	"Find":                   true,
+	"FindOne":                true,
+	"FindOneAndUpdate":       true,
+	"FindOneAndReplace":      true,
+	"FindOneAndDelete":       true,
+	"UpdateOne":              true,
+	"UpdateMany":             true,
+	"ReplaceOne":             true,
+	"DeleteOne":              true,
+	"DeleteMany":             true,
+	"CountDocuments":         true,
+	"EstimatedDocumentCount": true,
+	"Aggregate":              true,
+}
+
+// finding is a query that isn't limited to the organization
+type finding struct {
+	pos     token.Position
+	message string
+}
+
+func main() {
+	receivers := flag.String("receivers", "OrganizationService", "comma-separated receiver types whose methods act for an organization")
+	flag.Parse()
+
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"services"}
+	}
+
+	keys := make(map[string]string, len(tenancy.ScopedCollections))
+	for _, collection := range tenancy.ScopedCollections {
+		keys[collection.Field] = collection.Key
+	}
+	scanner := &scanner{
+		fset:      token.NewFileSet(),
+		receivers: map[string]bool{},
+		keys:      keys,
+	}
+	for _, receiver := range strings.Split(*receivers, ",") {
+		scanner.receivers[strings.TrimSpace(receiver)] = true
+	}
+
+	for _, dir := range dirs {
+		if err := scanner.scanDir(dir); err != nil {
+			fatalf("%v", err)
+		}
+	}
+
+	sort.Slice(scanner.findings, func(i, j int) bool {
+		a, b := scanner.findings[i].pos, scanner.findings[j].pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Line < b.Line
+	})
+	for _, f := range scanner.findings {
+		fmt.Printf("%s: %s\n", f.pos, f.message)
+	}
+	fmt.Printf("tenantscan: %d queries checked, %d findings\n", scanner.checked, len(scanner.findings))
+	if len(scanner.findings) > 0 {
+		os.Exit(1)
+	}
+}
+
+type scanner struct {
+	fset      *token.FileSet
+	receivers map[string]bool
+	// keys maps database.Database fields to the key pinning the
+	// organization
+	keys map[string]string
+
+	checked  int
+	findings []finding
+}
+
+func (s *scanner) scanDir(dir string) error {
+	packages, err := parser.ParseDir(s.fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if ok && fn.Body != nil && s.receivers[receiverType(fn)] {
+					s.scanFunc(fn)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// scanFunc checks the queries of one method
+func (s *scanner) scanFunc(fn *ast.FuncDecl) {
+	// Queries before the tenant is set authenticate the caller
+	tenantSet := token.NoPos
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		if call, ok := node.(*ast.CallExpr); ok && tenantSet == token.NoPos && isCall(call, "tenancy", "SetTenant") {
+			tenantSet = call.End()
+		}
+		return true
+	})
+
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		method, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !queryMethods[method.Sel.Name] {
+			return true
+		}
+		collection, ok := method.X.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		key, scoped := s.keys[collection.Sel.Name]
+		if !scoped || call.Pos() < tenantSet {
+			return true
+		}
+
+		if len(call.Args) > 0 {
+			if ctxCall, ok := call.Args[0].(*ast.CallExpr); ok && isCall(ctxCall, "tenancy", "Unscoped") {
+				return true
+			}
+		}
+
+		s.checked++
+		query := collection.Sel.Name + "." + method.Sel.Name
+		if len(call.Args) < 2 {
+			s.report(call, "%s reads every organization", query)
+			return true
+		}
+
+		filter := s.resolve(call.Args[1])
+		if method.Sel.Name == "Aggregate" && filter != nil {
+			filter = firstMatch(filter)
+		}
+		switch {
+		case filter == nil:
+			s.report(call, "%s filter can't be checked, use a literal filter or tenancy.Unscoped", query)
+		case !hasKey(filter, key) && !s.assignsKey(fn, call.Args[1], key, call.Pos()):
+			s.report(call, "%s filter doesn't pin %s", query, key)
+		}
+		return true
+	})
+}
+
+func (s *scanner) report(node ast.Node, format string, args ...interface{}) {
+	s.findings = append(s.findings, finding{
+		pos:     s.fset.Position(node.Pos()),
+		message: fmt.Sprintf(format, args...),
+	})
+}
+
+// resolve returns the composite literal a filter argument is, or the one
+// its variable was declared with
+func (s *scanner) resolve(expr ast.Expr) *ast.CompositeLit {
+	switch expr := expr.(type) {
+	case *ast.CompositeLit:
+		return expr
+	case *ast.Ident:
+		if expr.Obj == nil {
+			return nil
+		}
+		switch decl := expr.Obj.Decl.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range decl.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name == expr.Name && i < len(decl.Rhs) {
+					lit, _ := decl.Rhs[i].(*ast.CompositeLit)
+					return lit
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range decl.Names {
+				if name.Name == expr.Name && i < len(decl.Values) {
+					lit, _ := decl.Values[i].(*ast.CompositeLit)
+					return lit
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// assignsKey reports whether the method sets key in the filter variable,
+// as in filter["org_id"] = id, before the query
+func (s *scanner) assignsKey(fn *ast.FuncDecl, expr ast.Expr, key string, before token.Pos) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok || ident.Obj == nil {
+		return false
+	}
+
+	found := false
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		assign, ok := node.(*ast.AssignStmt)
+		if !ok || assign.Pos() > before {
+			return !found
+		}
+		for _, lhs := range assign.Lhs {
+			index, ok := lhs.(*ast.IndexExpr)
+			if !ok {
+				continue
+			}
+			target, ok := index.X.(*ast.Ident)
+			if ok && target.Obj == ident.Obj && stringLit(index.Index) == key {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// hasKey reports whether a filter literal has key, directly or in an $and
+func hasKey(filter *ast.CompositeLit, key string) bool {
+	for _, elt := range filter.Elts {
+		name, value := element(elt)
+		if name == key {
+			return true
+		}
+		if name == "$and" {
+			if clauses, ok := value.(*ast.CompositeLit); ok {
+				for _, clause := range clauses.Elts {
+					if lit, ok := clause.(*ast.CompositeLit); ok && hasKey(lit, key) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// element returns the key and value of a bson.M entry or bson.D element
+func element(elt ast.Expr) (string, ast.Expr) {
+	switch elt := elt.(type) {
+	case *ast.KeyValueExpr:
+		// bson.M{"key": value}
+		return stringLit(elt.Key), elt.Value
+	case *ast.CompositeLit:
+		// bson.D{{Key: "key", Value: value}} or bson.D{{"key", value}}
+		var name string
+		var value ast.Expr
+		for i, field := range elt.Elts {
+			if kv, ok := field.(*ast.KeyValueExpr); ok {
+				switch fieldName(kv.Key) {
+				case "Key":
+					name = stringLit(kv.Value)
+				case "Value":
+					value = kv.Value
+				}
+			} else if i == 0 {
+				name = stringLit(field)
+			} else if i == 1 {
+				value = field
+			}
+		}
+		return name, value
+	}
+	return "", nil
+}
+
+// firstMatch returns the filter of a pipeline literal's leading $match
+// stage
+func firstMatch(pipeline *ast.CompositeLit) *ast.CompositeLit {
+	if len(pipeline.Elts) == 0 {
+		return nil
+	}
+	stage, ok := pipeline.Elts[0].(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	for _, elt := range stage.Elts {
+		if name, value := element(elt); name == "$match" {
+			lit, _ := value.(*ast.CompositeLit)
+			return lit
+		}
+	}
+	// A bson.D stage is a single element
+	if name, value := element(stage); name == "$match" {
+		lit, _ := value.(*ast.CompositeLit)
+		return lit
+	}
+	return nil
+}
+
+func receiverType(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func isCall(call *ast.CallExpr, pkg, name string) bool {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != name {
+		return false
+	}
+	ident, ok := selector.X.(*ast.Ident)
+	return ok && ident.Name == pkg
+}
+
+func fieldName(expr ast.Expr) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func stringLit(expr ast.Expr) string {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "tenantscan: "+format+"\n", args...)
+	os.Exit(1)
+}
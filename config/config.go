@@ -0,0 +1,483 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"user-management/auth"
+	"user-management/authn"
+	"user-management/passkeys"
+)
+
+// Duration is a time.Duration that reads and writes JSON as a string such
+// as "15m0s" so snapshots stay human-readable
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"15m\": %v", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Settings are the runtime policies, flags and limits that can be exported
+// and promoted between environments. Secrets and connection details are
+// deliberately not part of Settings.
+type Settings struct {
+	Tokens        TokenSettings         `json:"tokens" bson:"tokens"`
+	LoginApproval LoginApprovalSettings `json:"login_approval" bson:"login_approval"`
+	DeviceLogin   DeviceLoginSettings   `json:"device_login" bson:"device_login"`
+	RateLimit     RateLimitSettings     `json:"rate_limit" bson:"rate_limit"`
+	Lockout       LockoutSettings       `json:"lockout" bson:"lockout"`
+	Outbox        OutboxSettings        `json:"outbox" bson:"outbox"`
+	PasswordReset PasswordResetSettings `json:"password_reset" bson:"password_reset"`
+
+	PasswordPolicy PasswordPolicySettings `json:"password_policy" bson:"password_policy"`
+	TwoFactorReset TwoFactorResetSettings `json:"two_factor_reset" bson:"two_factor_reset"`
+
+	TwoFactor TwoFactorSettings `json:"two_factor" bson:"two_factor"`
+
+	WebAuthn WebAuthnSettings `json:"webauthn" bson:"webauthn"`
+
+	Terms TermsSettings `json:"terms" bson:"terms"`
+
+	SSO SSOSettings `json:"sso" bson:"sso"`
+
+	Login LoginSettings `json:"login" bson:"login"`
+
+	Deletion DeletionSettings `json:"deletion" bson:"deletion"`
+
+	Admin AdminSettings `json:"admin" bson:"admin"`
+}
+
+type TokenSettings struct {
+	Expiry Duration `json:"expiry" bson:"expiry"`
+	// IdleTimeout ends a session that goes unused for this long, however
+	// far off its expiry is. Zero disables it.
+	IdleTimeout Duration `json:"idle_timeout" bson:"idle_timeout"`
+	// RefreshExpiry is how long refresh tokens last, and so how long a
+	// session can be kept going by refreshing it. Zero issues no refresh
+	// tokens.
+	RefreshExpiry Duration `json:"refresh_expiry" bson:"refresh_expiry"`
+	// IssueVersion is the format version of newly issued tokens and
+	// MinAcceptedVersion the oldest version still accepted
+	IssueVersion       int `json:"issue_version" bson:"issue_version"`
+	MinAcceptedVersion int `json:"min_accepted_version" bson:"min_accepted_version"`
+}
+
+type LoginApprovalSettings struct {
+	Required bool     `json:"required" bson:"required"`
+	TTL      Duration `json:"ttl" bson:"ttl"`
+	URL      string   `json:"url" bson:"url"`
+}
+
+type DeviceLoginSettings struct {
+	TTL       Duration `json:"ttl" bson:"ttl"`
+	Interval  Duration `json:"interval" bson:"interval"`
+	URL       string   `json:"url" bson:"url"`
+	ClientIDs []string `json:"client_ids" bson:"client_ids"`
+}
+
+type RateLimitSettings struct {
+	// MaxFailedLogins is the number of failed logins allowed per email and
+	// IP address within Window
+	MaxFailedLogins int      `json:"max_failed_logins" bson:"max_failed_logins"`
+	Window          Duration `json:"window" bson:"window"`
+}
+
+type LockoutSettings struct {
+	// MaxFailedLogins failed passwords in a row lock the account for
+	// Duration. Zero disables the lockout.
+	MaxFailedLogins int      `json:"max_failed_logins" bson:"max_failed_logins"`
+	Duration        Duration `json:"duration" bson:"duration"`
+}
+
+type PasswordResetSettings struct {
+	TTL Duration `json:"ttl" bson:"ttl"`
+	// URL is the page that reset links in emails point to
+	URL string `json:"url" bson:"url"`
+	// MaxPerEmail and MaxPerIP cap reset requests per email address and
+	// per IP address within an hour
+	MaxPerEmail int `json:"max_per_email" bson:"max_per_email"`
+	MaxPerIP    int `json:"max_per_ip" bson:"max_per_ip"`
+}
+
+type PasswordPolicySettings struct {
+	MinLength        int  `json:"min_length" bson:"min_length"`
+	RequireUppercase bool `json:"require_uppercase" bson:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase" bson:"require_lowercase"`
+	RequireNumber    bool `json:"require_number" bson:"require_number"`
+	RequireSpecial   bool `json:"require_special" bson:"require_special"`
+	// MinScore is the lowest strength score, from 0 to 4, a new password
+	// may have. Zero accepts any password that meets the other rules.
+	MinScore int `json:"min_score" bson:"min_score"`
+	// MaxAge is how long a password lasts before login asks for a new
+	// one. Zero disables expiry.
+	MaxAge Duration `json:"max_age" bson:"max_age"`
+	// ExpiryWarning is how long before expiry login starts warning
+	ExpiryWarning Duration `json:"expiry_warning" bson:"expiry_warning"`
+}
+
+type TermsSettings struct {
+	// Version of the current terms of service. Users who haven't accepted
+	// it are asked to at login. Empty when there are no terms.
+	Version string `json:"version" bson:"version"`
+	URL     string `json:"url" bson:"url"`
+}
+
+type SSOSettings struct {
+	// RedirectURL is the page identity providers send users back to, which
+	// passes the state and code to CompleteSSOLogin. It must be registered
+	// with each organization's provider.
+	RedirectURL string `json:"redirect_url" bson:"redirect_url"`
+	// LoginTTL is how long a user has to sign in at the provider
+	LoginTTL Duration `json:"login_ttl" bson:"login_ttl"`
+}
+
+type LoginSettings struct {
+	// Methods is the login chain, the login methods to try in order.
+	// Organizations can replace it for their members. Empty tries the
+	// password alone.
+	Methods []string `json:"methods" bson:"methods"`
+}
+
+type DeletionSettings struct {
+	// GracePeriod is how long a deleted account can be restored before it
+	// is purged. Zero keeps deleted accounts, unrestorable, until an
+	// admin hard-deletes them.
+	GracePeriod Duration `json:"grace_period" bson:"grace_period"`
+}
+
+type AdminSettings struct {
+	// UndoWindow holds hard deletes and deprovisioning jobs this long
+	// before they run, so an admin can cancel one made by mistake. Zero
+	// runs them straight away.
+	UndoWindow Duration `json:"undo_window" bson:"undo_window"`
+	// OperationRetention is how long long-running operations are kept
+	// with their results once done
+	OperationRetention Duration `json:"operation_retention" bson:"operation_retention"`
+	// ScheduledActionNotice is how long before a scheduled action runs the
+	// user is emailed about it. Zero sends no notice.
+	ScheduledActionNotice Duration `json:"scheduled_action_notice" bson:"scheduled_action_notice"`
+}
+
+type TwoFactorSettings struct {
+	// Required limits every user without 2FA to enrollment once
+	// GracePeriod has passed since their first login under the policy.
+	// Organizations can require it of their members alone.
+	Required    bool     `json:"required" bson:"required"`
+	GracePeriod Duration `json:"grace_period" bson:"grace_period"`
+	// Issuer names the service in authenticator apps
+	Issuer string `json:"issuer" bson:"issuer"`
+}
+
+type WebAuthnSettings struct {
+	// RPID is the domain passkeys are bound to. Changing it makes existing
+	// passkeys unusable.
+	RPID          string   `json:"rp_id" bson:"rp_id"`
+	RPDisplayName string   `json:"rp_display_name" bson:"rp_display_name"`
+	Origins       []string `json:"origins" bson:"origins"`
+	// Attestation is "none", "indirect" or "direct"
+	Attestation string `json:"attestation" bson:"attestation"`
+	// AllowedAAGUIDs limits passkeys to these authenticator models
+	AllowedAAGUIDs []string `json:"allowed_aaguids" bson:"allowed_aaguids"`
+	// ResidentKey is "discouraged", "preferred" or "required"
+	ResidentKey string `json:"resident_key" bson:"resident_key"`
+}
+
+type TwoFactorResetSettings struct {
+	// Delay is how long a self-serve reset waits before the second factor
+	// is removed, giving the owner time to cancel it
+	Delay Duration `json:"delay" bson:"delay"`
+	// CancelURL is the page that cancel links in emails point to
+	CancelURL string `json:"cancel_url" bson:"cancel_url"`
+}
+
+type OutboxSettings struct {
+	PollInterval Duration `json:"poll_interval" bson:"poll_interval"`
+	BatchSize    int      `json:"batch_size" bson:"batch_size"`
+	MaxAttempts  int      `json:"max_attempts" bson:"max_attempts"`
+	RetryBackoff Duration `json:"retry_backoff" bson:"retry_backoff"`
+}
+
+// DefaultSettings returns the settings used when nothing has been imported
+func DefaultSettings() Settings {
+	return Settings{
+		Tokens: TokenSettings{
+			Expiry:             Duration(24 * time.Hour),
+			IssueVersion:       2,
+			MinAcceptedVersion: 1,
+		},
+		LoginApproval: LoginApprovalSettings{
+			Required: false,
+			TTL:      Duration(15 * time.Minute),
+			URL:      "http://localhost:3000/approve-login",
+		},
+		DeviceLogin: DeviceLoginSettings{
+			TTL:       Duration(10 * time.Minute),
+			Interval:  Duration(5 * time.Second),
+			URL:       "http://localhost:3000/device",
+			ClientIDs: []string{"authctl"},
+		},
+		RateLimit: RateLimitSettings{
+			MaxFailedLogins: 5,
+			Window:          Duration(time.Minute),
+		},
+		Lockout: LockoutSettings{
+			MaxFailedLogins: 5,
+			Duration:        Duration(15 * time.Minute),
+		},
+		Outbox: OutboxSettings{
+			PollInterval: Duration(time.Second),
+			BatchSize:    100,
+			MaxAttempts:  10,
+			RetryBackoff: Duration(5 * time.Second),
+		},
+		PasswordReset: PasswordResetSettings{
+			TTL:         Duration(30 * time.Minute),
+			URL:         "http://localhost:3000/reset-password",
+			MaxPerEmail: 3,
+			MaxPerIP:    10,
+		},
+		PasswordPolicy: PasswordPolicySettings{
+			MinLength:        8,
+			RequireUppercase: true,
+			RequireLowercase: true,
+			RequireNumber:    true,
+			RequireSpecial:   true,
+			MaxAge:           0,
+			ExpiryWarning:    Duration(14 * 24 * time.Hour),
+		},
+		TwoFactorReset: TwoFactorResetSettings{
+			Delay:     Duration(72 * time.Hour),
+			CancelURL: "http://localhost:3000/cancel-two-factor-reset",
+		},
+		TwoFactor: TwoFactorSettings{
+			Required:    false,
+			GracePeriod: Duration(7 * 24 * time.Hour),
+			Issuer:      "user-management",
+		},
+		WebAuthn: WebAuthnSettings{
+			RPID:           "localhost",
+			RPDisplayName:  "user-management",
+			Origins:        []string{"http://localhost:3000"},
+			Attestation:    passkeys.AttestationNone,
+			AllowedAAGUIDs: []string{},
+			ResidentKey:    passkeys.ResidentKeyPreferred,
+		},
+		SSO: SSOSettings{
+			RedirectURL: "http://localhost:3000/sso/callback",
+			LoginTTL:    Duration(10 * time.Minute),
+		},
+		Login: LoginSettings{
+			Methods: []string{authn.MethodPassword},
+		},
+		Deletion: DeletionSettings{
+			GracePeriod: Duration(30 * 24 * time.Hour),
+		},
+		Admin: AdminSettings{
+			OperationRetention:    Duration(7 * 24 * time.Hour),
+			ScheduledActionNotice: Duration(3 * 24 * time.Hour),
+		},
+	}
+}
+
+// Validate rejects settings the server cannot run with
+func (s Settings) Validate() error {
+	durations := []struct {
+		name  string
+		value Duration
+	}{
+		{"tokens.expiry", s.Tokens.Expiry},
+		{"login_approval.ttl", s.LoginApproval.TTL},
+		{"device_login.ttl", s.DeviceLogin.TTL},
+		{"device_login.interval", s.DeviceLogin.Interval},
+		{"rate_limit.window", s.RateLimit.Window},
+		{"lockout.duration", s.Lockout.Duration},
+		{"outbox.poll_interval", s.Outbox.PollInterval},
+		{"outbox.retry_backoff", s.Outbox.RetryBackoff},
+		{"password_reset.ttl", s.PasswordReset.TTL},
+		{"sso.login_ttl", s.SSO.LoginTTL},
+		{"admin.operation_retention", s.Admin.OperationRetention},
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			return fmt.Errorf("%s must be greater than zero", d.name)
+		}
+	}
+
+	if s.Tokens.IdleTimeout < 0 {
+		return fmt.Errorf("tokens.idle_timeout must not be negative")
+	}
+	if s.Tokens.IdleTimeout > 0 && (time.Duration(s.Tokens.IdleTimeout) < auth.MinIdleTimeout || s.Tokens.IdleTimeout >= s.Tokens.Expiry) {
+		return fmt.Errorf("tokens.idle_timeout must be at least %s and shorter than tokens.expiry", auth.MinIdleTimeout)
+	}
+
+	if s.Tokens.RefreshExpiry < 0 {
+		return fmt.Errorf("tokens.refresh_expiry must not be negative")
+	}
+	if s.Tokens.RefreshExpiry > 0 && s.Tokens.RefreshExpiry <= s.Tokens.Expiry {
+		return fmt.Errorf("tokens.refresh_expiry must be longer than tokens.expiry")
+	}
+
+	if s.Tokens.MinAcceptedVersion <= 0 || s.Tokens.MinAcceptedVersion > s.Tokens.IssueVersion {
+		return fmt.Errorf("tokens.min_accepted_version must be between 1 and tokens.issue_version")
+	}
+
+	if s.Deletion.GracePeriod < 0 {
+		return fmt.Errorf("deletion.grace_period must not be negative")
+	}
+	if s.Admin.UndoWindow < 0 {
+		return fmt.Errorf("admin.undo_window must not be negative")
+	}
+	if s.Admin.ScheduledActionNotice < 0 {
+		return fmt.Errorf("admin.scheduled_action_notice must not be negative")
+	}
+
+	if s.Lockout.MaxFailedLogins < 0 {
+		return fmt.Errorf("lockout.max_failed_logins must not be negative")
+	}
+
+	if s.LoginApproval.URL == "" {
+		return fmt.Errorf("login_approval.url is required")
+	}
+	if s.DeviceLogin.URL == "" {
+		return fmt.Errorf("device_login.url is required")
+	}
+	// Login attempts are only kept for an hour
+	if time.Duration(s.RateLimit.Window) > time.Hour {
+		return fmt.Errorf("rate_limit.window must be at most 1h")
+	}
+	if s.RateLimit.MaxFailedLogins <= 0 {
+		return fmt.Errorf("rate_limit.max_failed_logins must be greater than zero")
+	}
+	if s.Outbox.BatchSize <= 0 {
+		return fmt.Errorf("outbox.batch_size must be greater than zero")
+	}
+	if s.Outbox.MaxAttempts <= 0 {
+		return fmt.Errorf("outbox.max_attempts must be greater than zero")
+	}
+	if s.PasswordReset.URL == "" {
+		return fmt.Errorf("password_reset.url is required")
+	}
+	// Reset requests are only kept for two hours
+	if time.Duration(s.PasswordReset.TTL) > time.Hour {
+		return fmt.Errorf("password_reset.ttl must be at most 1h")
+	}
+	if s.PasswordReset.MaxPerEmail <= 0 || s.PasswordReset.MaxPerIP <= 0 {
+		return fmt.Errorf("password_reset.max_per_email and password_reset.max_per_ip must be greater than zero")
+	}
+	// Passwords are at most 128 characters
+	if s.PasswordPolicy.MinLength < 8 || s.PasswordPolicy.MinLength > 128 {
+		return fmt.Errorf("password_policy.min_length must be between 8 and 128")
+	}
+	if s.PasswordPolicy.MinScore < 0 || s.PasswordPolicy.MinScore > 4 {
+		return fmt.Errorf("password_policy.min_score must be between 0 and 4")
+	}
+	if s.PasswordPolicy.MaxAge < 0 || s.PasswordPolicy.ExpiryWarning < 0 {
+		return fmt.Errorf("password_policy.max_age and password_policy.expiry_warning must not be negative")
+	}
+	if s.PasswordPolicy.MaxAge > 0 && s.PasswordPolicy.ExpiryWarning >= s.PasswordPolicy.MaxAge {
+		return fmt.Errorf("password_policy.expiry_warning must be shorter than password_policy.max_age")
+	}
+	// A shorter delay leaves the owner too little time to notice a reset
+	if time.Duration(s.TwoFactorReset.Delay) < time.Hour {
+		return fmt.Errorf("two_factor_reset.delay must be at least 1h")
+	}
+	if s.TwoFactorReset.CancelURL == "" {
+		return fmt.Errorf("two_factor_reset.cancel_url is required")
+	}
+	if s.TwoFactor.GracePeriod < 0 {
+		return fmt.Errorf("two_factor.grace_period must not be negative")
+	}
+	if s.TwoFactor.Issuer == "" {
+		return fmt.Errorf("two_factor.issuer is required")
+	}
+	if s.WebAuthn.RPID == "" || s.WebAuthn.RPDisplayName == "" {
+		return fmt.Errorf("webauthn.rp_id and webauthn.rp_display_name are required")
+	}
+	if len(s.WebAuthn.Origins) == 0 {
+		return fmt.Errorf("webauthn.origins must list at least one origin")
+	}
+	policy := passkeys.Policy{
+		Attestation:    s.WebAuthn.Attestation,
+		AllowedAAGUIDs: s.WebAuthn.AllowedAAGUIDs,
+		ResidentKey:    s.WebAuthn.ResidentKey,
+	}
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("webauthn.%v", err)
+	}
+	if s.Terms.Version != "" && s.Terms.URL == "" {
+		return fmt.Errorf("terms.url is required with terms.version")
+	}
+	if s.SSO.RedirectURL == "" {
+		return fmt.Errorf("sso.redirect_url is required")
+	}
+	if err := authn.ValidateMethods(s.Login.Methods); err != nil {
+		return fmt.Errorf("login.methods: %v", err)
+	}
+
+	return nil
+}
+
+// Diff lists the settings, by JSON path, whose values differ between a and b
+func Diff(a, b Settings) ([]string, error) {
+	flatA, err := flatten(a)
+	if err != nil {
+		return nil, err
+	}
+	flatB, err := flatten(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for key, valueA := range flatA {
+		if !reflect.DeepEqual(valueA, flatB[key]) {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed, nil
+}
+
+func flatten(settings Settings) (map[string]interface{}, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode settings: %v", err)
+	}
+
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to decode settings: %v", err)
+	}
+
+	flat := make(map[string]interface{})
+	var walk func(prefix string, node map[string]interface{})
+	walk = func(prefix string, node map[string]interface{}) {
+		for key, value := range node {
+			if child, ok := value.(map[string]interface{}); ok {
+				walk(prefix+key+".", child)
+				continue
+			}
+			flat[prefix+key] = value
+		}
+	}
+	walk("", tree)
+
+	return flat, nil
+}
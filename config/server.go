@@ -0,0 +1,945 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"gopkg.in/yaml.v3"
+
+	"user-management/autotls"
+	"user-management/fips"
+	"user-management/kms"
+	"user-management/ldapauth"
+	"user-management/models"
+	"user-management/notifications"
+	"user-management/servertls"
+	"user-management/tenancy"
+	"user-management/utils"
+)
+
+// ACMECacheMongoDB keeps ACME certificates in MongoDB rather than a
+// directory
+const ACMECacheMongoDB = "mongodb"
+
+// minSecretLength is the shortest accepted signing key, the 256 bits of an
+// HS256 key
+const minSecretLength = 32
+
+// kmsTimeout bounds each call to the KMS while loading the configuration
+const kmsTimeout = 30 * time.Second
+
+// Server is the configuration of a server process: ports, connection
+// details and secrets. Unlike Settings it differs between every
+// deployment and is never exported.
+type Server struct {
+	Port        string
+	MetricsPort string
+	// GatewayPort serves the REST/JSON gateway. Empty disables it.
+	GatewayPort string
+	MongoURI    string
+	MongoDB     string
+	// QueryTimeout bounds each database operation
+	QueryTimeout time.Duration
+	JWTSecret    string
+	// JWTPreviousSecrets are comma-separated secrets JWTSecret replaced.
+	// Tokens signed with them stay valid, but none are signed with them.
+	JWTPreviousSecrets string
+	// JWTSigningKey is the URI of a KMS HMAC key signing tokens instead of
+	// JWTSecret. Tokens signed with JWTSecret stay valid while it is set.
+	JWTSigningKey string
+	// JWTKeyFiles are comma-separated PEM files of RSA or P-256 private
+	// keys. The first signs tokens instead of JWTSecret, and tokens signed
+	// with any of them are accepted.
+	JWTKeyFiles string
+	// JWTExpiry replaces the default tokens.expiry setting when set.
+	// Imported settings still take precedence.
+	JWTExpiry time.Duration
+	// BcryptCost is the cost of new bcrypt password hashes
+	BcryptCost int
+	// PasswordHash is the format of new password hashes: bcrypt, argon2
+	// or pbkdf2. Existing hashes are replaced at their next login.
+	PasswordHash string
+	// FIPSMode restricts the server to FIPS 140-3 approved algorithms. It
+	// defaults to on when Go's cryptographic module runs in FIPS mode.
+	FIPSMode bool
+	// Region is the region this server runs in, matching the "region" tag
+	// of the local replica set members. Empty when not multi-region.
+	Region string
+	// MajorityWrites acknowledges writes once a majority of replica set
+	// members have them, so failing over to another region loses none
+	MajorityWrites bool
+	// EventSourcedUsers records every user change as an event so past
+	// states of an account can be rebuilt
+	EventSourcedUsers bool
+	// TTLIndexes leaves removing expired records to MongoDB's TTL
+	// monitor. Turn it off where the monitor is disabled, and a background
+	// job removes them instead.
+	TTLIndexes bool
+	// UserIDFormat is the format of new user IDs, "objectid" or "uuidv7".
+	// Existing accounts keep their IDs when it changes.
+	UserIDFormat string
+
+	// Environment names this deployment in exported config snapshots
+	Environment      string
+	ConfigSigningKey string
+
+	// TwoFactorEncryptionKey encrypts the TOTP secrets of accounts
+	TwoFactorEncryptionKey string
+
+	// ScrubEmails redacts email addresses from logs and errors. Tokens,
+	// passwords and hashes are always redacted.
+	ScrubEmails bool
+
+	// AlertsFile holds the alert rules and webhooks. The default rules
+	// are used, logging alerts only, when the file does not exist.
+	AlertsFile string
+
+	// ShutdownTimeout is how long in-flight RPCs get to finish after
+	// SIGINT or SIGTERM before they are cancelled
+	ShutdownTimeout time.Duration
+
+	// TLSCertFile and TLSKeyFile serve gRPC over TLS when set. Both files
+	// are read again on SIGHUP.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile enables mutual TLS, verifying client certificates
+	// against this PEM bundle
+	TLSClientCAFile string
+	// TLSClientAuth is "require" to reject clients without a certificate,
+	// or "optional" to verify only the certificates clients send
+	TLSClientAuth string
+
+	// ACMEDomains gets certificates for these comma-separated domains from
+	// an ACME CA instead of TLSCertFile and TLSKeyFile
+	ACMEDomains      string
+	ACMEEmail        string
+	ACMEDirectoryURL string
+	// ACMECache is "mongodb", or a directory to keep the ACME account key
+	// and certificates in
+	ACMECache string
+	// ACMEEncryptionKey encrypts the ACME cache in MongoDB
+	ACMEEncryptionKey string
+
+	// GatewayTLSMinVersion and GatewayTLSCiphers narrow the TLS the
+	// gateway accepts. The ciphers are comma-separated IANA names; empty
+	// keeps the defaults.
+	GatewayTLSMinVersion string
+	GatewayTLSCiphers    string
+	// GatewayHSTSMaxAge is the max-age of the gateway's
+	// Strict-Transport-Security header over TLS. Zero leaves it out.
+	GatewayHSTSMaxAge            time.Duration
+	GatewayHSTSIncludeSubdomains bool
+	// GatewayRedirectPort serves plain HTTP redirects to the gateway over
+	// TLS. Empty disables it.
+	GatewayRedirectPort string
+
+	// InternalPort serves gRPC to services in a service mesh, which
+	// authenticate with an X.509 SVID instead of a bearer token. Empty
+	// disables it.
+	InternalPort string
+	// SPIFFETrustDomain is the mesh's trust domain, and SPIFFEBundleFile
+	// its CA certificates, read again on SIGHUP
+	SPIFFETrustDomain string
+	SPIFFEBundleFile  string
+	// SPIFFEAccountsFile maps SPIFFE IDs to service accounts and their
+	// scopes
+	SPIFFEAccountsFile string
+
+	// RedisURL enables the token blacklist cache in this Redis
+	RedisURL string
+
+	// GoogleClientID enables Google sign-in for ID tokens issued to this
+	// client
+	GoogleClientID string
+	// GitHubClientID and GitHubClientSecret enable GitHub sign-in for
+	// access tokens of this OAuth app
+	GitHubClientID     string
+	GitHubClientSecret string
+
+	// LDAPURL is the directory the ldap login method checks passwords
+	// against. Users are searched for under LDAPBaseDN with
+	// LDAPUserFilter, binding as LDAPBindDN.
+	LDAPURL          string
+	LDAPBindDN       string
+	LDAPBindPassword string
+	LDAPBaseDN       string
+	LDAPUserFilter   string
+	// OIDCLoginIssuer is the provider the oidc login method sends
+	// passwords to with the resource owner password grant, as the client
+	// OIDCLoginClientID
+	OIDCLoginIssuer       string
+	OIDCLoginClientID     string
+	OIDCLoginClientSecret string
+
+	// EmailSender delivers notification emails: "log" writes them to the
+	// server log, "smtp" sends them through SMTPAddr and "sendgrid"
+	// through SendGrid's API. EmailFrom is the address they come from.
+	EmailSender    string
+	EmailFrom      string
+	SMTPAddr       string
+	SMTPUsername   string
+	SMTPPassword   string
+	SendGridAPIKey string
+
+	// TenantIsolationChecks checks that organization admins' RPCs only
+	// query their organization: "off", "log" or "enforce"
+	TenantIsolationChecks string
+
+	// TenantMasterKey wraps the keys organizations' members' personal
+	// data is encrypted with, as a secret or the URI of a KMS key.
+	// Members' data is stored as it is when unset.
+	TenantMasterKey string
+	// TenantPreviousMasterKey unwraps the keys wrapped before
+	// TenantMasterKey was changed, which are then wrapped again
+	TenantPreviousMasterKey string
+
+	// KMSKey is the URI of the KMS key secrets prefixed with kms: are
+	// encrypted with
+	KMSKey string
+
+	// PKCS11Module is the path of an HSM's PKCS#11 library. When set,
+	// tokens are signed in the HSM with the key pair labelled
+	// PKCS11KeyLabel, instead of with JWTSecret.
+	PKCS11Module     string
+	PKCS11TokenLabel string
+	PKCS11PIN        string
+	PKCS11KeyLabel   string
+	// PKCS11SlowSigning is the signing latency above which an HSM
+	// signature counts towards the slow signing alert
+	PKCS11SlowSigning time.Duration
+}
+
+// DefaultServer returns the configuration used for anything not set.
+// MongoURI and the secrets have no defaults.
+func DefaultServer() Server {
+	return Server{
+		Port:         "50051",
+		MetricsPort:  "9090",
+		GatewayPort:  "8080",
+		MongoDB:      "user_management",
+		QueryTimeout: 5 * time.Second,
+		BcryptCost:   utils.MinBcryptCost,
+		PasswordHash: defaultPasswordHash(),
+		FIPSMode:     fips.ModuleEnabled(),
+
+		MajorityWrites: true,
+		TTLIndexes:     true,
+
+		UserIDFormat: models.IDFormatObjectID,
+
+		Environment: "development",
+
+		ScrubEmails: true,
+
+		AlertsFile: "alerts.json",
+
+		ShutdownTimeout: 30 * time.Second,
+
+		TLSClientAuth: servertls.ClientAuthRequire,
+
+		ACMEDirectoryURL: autotls.LetsEncryptURL,
+		ACMECache:        ACMECacheMongoDB,
+
+		GatewayTLSMinVersion: "1.2",
+		GatewayHSTSMaxAge:    365 * 24 * time.Hour,
+
+		LDAPUserFilter: ldapauth.DefaultUserFilter,
+
+		EmailSender: notifications.SenderLog,
+
+		TenantIsolationChecks: tenancy.ModeOff,
+
+		PKCS11SlowSigning: 250 * time.Millisecond,
+	}
+}
+
+// defaultPasswordHash is pbkdf2, the approved format, when the
+// cryptographic module runs in FIPS mode, and bcrypt otherwise
+func defaultPasswordHash() string {
+	if fips.ModuleEnabled() {
+		return utils.HashFormatPBKDF2
+	}
+	return utils.HashFormatBcrypt
+}
+
+// serverVar is one configuration value. It is read from the config file
+// key, the environment variable of the key in upper case and, unless it is
+// a secret, the flag of the key with dashes.
+type serverVar struct {
+	key    string
+	usage  string
+	secret bool
+	set    func(s *Server, value string) error
+}
+
+func (v serverVar) env() string {
+	return strings.ToUpper(v.key)
+}
+
+func (v serverVar) flag() string {
+	return strings.ReplaceAll(v.key, "_", "-")
+}
+
+// Secrets have no flags, since command lines are visible to every user of
+// the host
+var serverVars = []serverVar{
+	{"port", "gRPC port", false, stringVar(func(s *Server) *string { return &s.Port })},
+	{"metrics_port", "Prometheus metrics port", false, stringVar(func(s *Server) *string { return &s.MetricsPort })},
+	{"gateway_port", "REST gateway port, empty to disable it", false, stringVar(func(s *Server) *string { return &s.GatewayPort })},
+	{"mongo_uri", "MongoDB connection string", true, stringVar(func(s *Server) *string { return &s.MongoURI })},
+	{"mongo_db", "MongoDB database", false, stringVar(func(s *Server) *string { return &s.MongoDB })},
+	{"query_timeout", "timeout of each database operation", false, durationVar(func(s *Server) *time.Duration { return &s.QueryTimeout })},
+	{"jwt_secret", "token signing key", true, stringVar(func(s *Server) *string { return &s.JWTSecret })},
+	{"jwt_previous_secrets", "comma-separated token signing keys replaced by jwt_secret, still accepted", true, stringVar(func(s *Server) *string { return &s.JWTPreviousSecrets })},
+	{"jwt_signing_key", "URI of the KMS HMAC key signing tokens", false, stringVar(func(s *Server) *string { return &s.JWTSigningKey })},
+	{"jwt_key_files", "comma-separated PEM files of the RSA or P-256 keys signing tokens, the first signing new ones", false, stringVar(func(s *Server) *string { return &s.JWTKeyFiles })},
+	{"jwt_expiry", "session token lifetime, replacing the tokens.expiry setting", false, durationVar(func(s *Server) *time.Duration { return &s.JWTExpiry })},
+	{"bcrypt_cost", "bcrypt cost of new password hashes", false, intVar(func(s *Server) *int { return &s.BcryptCost })},
+	{"password_hash", "format of new password hashes, bcrypt, argon2 or pbkdf2", false, stringVar(func(s *Server) *string { return &s.PasswordHash })},
+	{"fips_mode", "allow only FIPS 140-3 approved algorithms", false, boolVar(func(s *Server) *bool { return &s.FIPSMode })},
+	{"region", "region of this server in a multi-region deployment", false, stringVar(func(s *Server) *string { return &s.Region })},
+	{"majority_writes", "acknowledge writes once a majority of members have them", false, boolVar(func(s *Server) *bool { return &s.MajorityWrites })},
+	{"event_sourced_users", "record every user change as an event", false, boolVar(func(s *Server) *bool { return &s.EventSourcedUsers })},
+	{"ttl_indexes", "let MongoDB's TTL monitor remove expired records", false, boolVar(func(s *Server) *bool { return &s.TTLIndexes })},
+	{"user_id_format", "format of new user IDs, objectid or uuidv7", false, stringVar(func(s *Server) *string { return &s.UserIDFormat })},
+	{"environment", "name of this deployment in config snapshots", false, stringVar(func(s *Server) *string { return &s.Environment })},
+	{"config_signing_key", "config snapshot signing key", true, stringVar(func(s *Server) *string { return &s.ConfigSigningKey })},
+	{"two_factor_encryption_key", "TOTP secret encryption key", true, stringVar(func(s *Server) *string { return &s.TwoFactorEncryptionKey })},
+	{"scrub_emails", "redact email addresses from logs and errors", false, boolVar(func(s *Server) *bool { return &s.ScrubEmails })},
+	{"alerts_file", "alert rules and webhooks", false, stringVar(func(s *Server) *string { return &s.AlertsFile })},
+	{"shutdown_timeout", "time in-flight RPCs get to finish on shutdown", false, durationVar(func(s *Server) *time.Duration { return &s.ShutdownTimeout })},
+	{"tls_cert_file", "TLS certificate chain of the gRPC listener", false, stringVar(func(s *Server) *string { return &s.TLSCertFile })},
+	{"tls_key_file", "TLS private key of the gRPC listener", false, stringVar(func(s *Server) *string { return &s.TLSKeyFile })},
+	{"tls_client_ca_file", "CA bundle client certificates are verified against", false, stringVar(func(s *Server) *string { return &s.TLSClientCAFile })},
+	{"tls_client_auth", "require or optional client certificates", false, stringVar(func(s *Server) *string { return &s.TLSClientAuth })},
+	{"acme_domains", "comma-separated domains to get ACME certificates for", false, stringVar(func(s *Server) *string { return &s.ACMEDomains })},
+	{"acme_email", "contact of the ACME account", false, stringVar(func(s *Server) *string { return &s.ACMEEmail })},
+	{"acme_directory_url", "directory of the ACME CA", false, stringVar(func(s *Server) *string { return &s.ACMEDirectoryURL })},
+	{"acme_cache", "mongodb or a directory to keep ACME certificates in", false, stringVar(func(s *Server) *string { return &s.ACMECache })},
+	{"acme_encryption_key", "key ACME certificates in MongoDB are encrypted with", true, stringVar(func(s *Server) *string { return &s.ACMEEncryptionKey })},
+	{"gateway_tls_min_version", "oldest TLS version of the REST gateway, 1.2 or 1.3", false, stringVar(func(s *Server) *string { return &s.GatewayTLSMinVersion })},
+	{"gateway_tls_ciphers", "comma-separated TLS 1.2 cipher suites of the REST gateway", false, stringVar(func(s *Server) *string { return &s.GatewayTLSCiphers })},
+	{"gateway_hsts_max_age", "max-age of the REST gateway's HSTS header, 0 to leave it out", false, durationVar(func(s *Server) *time.Duration { return &s.GatewayHSTSMaxAge })},
+	{"gateway_hsts_include_subdomains", "extend the REST gateway's HSTS header to subdomains", false, boolVar(func(s *Server) *bool { return &s.GatewayHSTSIncludeSubdomains })},
+	{"gateway_redirect_port", "port redirecting plain HTTP to the REST gateway over TLS", false, stringVar(func(s *Server) *string { return &s.GatewayRedirectPort })},
+	{"internal_port", "gRPC port for services authenticated by SPIFFE ID, empty to disable it", false, stringVar(func(s *Server) *string { return &s.InternalPort })},
+	{"spiffe_trust_domain", "SPIFFE trust domain of the internal port's clients", false, stringVar(func(s *Server) *string { return &s.SPIFFETrustDomain })},
+	{"spiffe_bundle_file", "CA certificates of the SPIFFE trust domain", false, stringVar(func(s *Server) *string { return &s.SPIFFEBundleFile })},
+	{"spiffe_accounts_file", "service accounts of SPIFFE IDs", false, stringVar(func(s *Server) *string { return &s.SPIFFEAccountsFile })},
+	{"redis_url", "Redis connection string of the token blacklist cache", true, stringVar(func(s *Server) *string { return &s.RedisURL })},
+	{"google_client_id", "OAuth client of Google sign-in", false, stringVar(func(s *Server) *string { return &s.GoogleClientID })},
+	{"github_client_id", "OAuth app of GitHub sign-in", false, stringVar(func(s *Server) *string { return &s.GitHubClientID })},
+	{"github_client_secret", "OAuth app secret of GitHub sign-in", true, stringVar(func(s *Server) *string { return &s.GitHubClientSecret })},
+	{"ldap_url", "LDAP directory of the ldap login method, ldaps:// or ldap://", false, stringVar(func(s *Server) *string { return &s.LDAPURL })},
+	{"ldap_bind_dn", "LDAP service account users are searched for with", false, stringVar(func(s *Server) *string { return &s.LDAPBindDN })},
+	{"ldap_bind_password", "password of the LDAP service account", true, stringVar(func(s *Server) *string { return &s.LDAPBindPassword })},
+	{"ldap_base_dn", "LDAP subtree users are searched for in", false, stringVar(func(s *Server) *string { return &s.LDAPBaseDN })},
+	{"ldap_user_filter", "LDAP filter finding a user, with %s for the email", false, stringVar(func(s *Server) *string { return &s.LDAPUserFilter })},
+	{"oidc_login_issuer", "OpenID Connect provider of the oidc login method", false, stringVar(func(s *Server) *string { return &s.OIDCLoginIssuer })},
+	{"oidc_login_client_id", "OAuth client of the oidc login method", false, stringVar(func(s *Server) *string { return &s.OIDCLoginClientID })},
+	{"oidc_login_client_secret", "OAuth client secret of the oidc login method", true, stringVar(func(s *Server) *string { return &s.OIDCLoginClientSecret })},
+	{"email_sender", "delivery of notification emails: log, smtp or sendgrid", false, stringVar(func(s *Server) *string { return &s.EmailSender })},
+	{"email_from", "address notification emails are sent from", false, stringVar(func(s *Server) *string { return &s.EmailFrom })},
+	{"smtp_addr", "host:port of the SMTP server of the smtp email sender", false, stringVar(func(s *Server) *string { return &s.SMTPAddr })},
+	{"smtp_username", "SMTP user of the smtp email sender", false, stringVar(func(s *Server) *string { return &s.SMTPUsername })},
+	{"smtp_password", "SMTP password of the smtp email sender", true, stringVar(func(s *Server) *string { return &s.SMTPPassword })},
+	{"sendgrid_api_key", "SendGrid API key of the sendgrid email sender", true, stringVar(func(s *Server) *string { return &s.SendGridAPIKey })},
+	{"tenant_isolation_checks", "check organization queries: off, log or enforce", false, stringVar(func(s *Server) *string { return &s.TenantIsolationChecks })},
+	{"tenant_master_key", "master key of organizations' encryption keys", true, stringVar(func(s *Server) *string { return &s.TenantMasterKey })},
+	{"tenant_previous_master_key", "master key being replaced by tenant_master_key", true, stringVar(func(s *Server) *string { return &s.TenantPreviousMasterKey })},
+	{"kms_key", "URI of the KMS key encrypting kms: secrets", false, stringVar(func(s *Server) *string { return &s.KMSKey })},
+	{"pkcs11_module", "PKCS#11 library of the HSM signing tokens", false, stringVar(func(s *Server) *string { return &s.PKCS11Module })},
+	{"pkcs11_token_label", "label of the HSM token", false, stringVar(func(s *Server) *string { return &s.PKCS11TokenLabel })},
+	{"pkcs11_pin", "user PIN of the HSM token", true, stringVar(func(s *Server) *string { return &s.PKCS11PIN })},
+	{"pkcs11_key_label", "label of the HSM key pair signing tokens", false, stringVar(func(s *Server) *string { return &s.PKCS11KeyLabel })},
+	{"pkcs11_slow_signing", "HSM signing latency counted as slow by alerts", false, durationVar(func(s *Server) *time.Duration { return &s.PKCS11SlowSigning })},
+}
+
+func stringVar(field func(s *Server) *string) func(s *Server, value string) error {
+	return func(s *Server, value string) error {
+		*field(s) = value
+		return nil
+	}
+}
+
+func durationVar(field func(s *Server) *time.Duration) func(s *Server, value string) error {
+	return func(s *Server, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as \"5s\" or \"24h\"")
+		}
+		*field(s) = d
+		return nil
+	}
+}
+
+func intVar(field func(s *Server) *int) func(s *Server, value string) error {
+	return func(s *Server, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+		*field(s) = n
+		return nil
+	}
+}
+
+func boolVar(field func(s *Server) *bool) func(s *Server, value string) error {
+	return func(s *Server, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		*field(s) = b
+		return nil
+	}
+}
+
+// LoadServer reads the server configuration from, in increasing order of
+// precedence, the defaults, the YAML or TOML file named by -config or
+// CONFIG_FILE, environment variables and flags. Every problem found is
+// reported in the error. It returns flag.ErrHelp when -h is passed.
+func LoadServer(args []string) (Server, error) {
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file ($CONFIG_FILE)")
+
+	flagValues := make(map[string]string)
+	for _, v := range serverVars {
+		if v.secret {
+			continue
+		}
+		key := v.key
+		flags.Func(v.flag(), fmt.Sprintf("%s ($%s)", v.usage, v.env()), func(value string) error {
+			flagValues[key] = value
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return Server{}, err
+	}
+	if flags.NArg() > 0 {
+		return Server{}, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	s := DefaultServer()
+	var errs []error
+	// secrets holds the value each secret was last set to
+	secrets := make(map[string]string)
+
+	if *file != "" {
+		values, err := readServerFile(*file)
+		if err != nil {
+			return Server{}, err
+		}
+		for _, v := range serverVars {
+			if value, ok := values[v.key]; ok {
+				if err := v.set(&s, value); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %s %v", *file, v.key, err))
+				} else if v.secret {
+					secrets[v.key] = value
+				}
+			}
+		}
+	}
+
+	for _, v := range serverVars {
+		if value, ok := os.LookupEnv(v.env()); ok {
+			if err := v.set(&s, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s %v", v.env(), err))
+			} else if v.secret {
+				secrets[v.key] = value
+			}
+		}
+	}
+
+	for _, v := range serverVars {
+		if value, ok := flagValues[v.key]; ok {
+			if err := v.set(&s, value); err != nil {
+				errs = append(errs, fmt.Errorf("-%s %v", v.flag(), err))
+			}
+		}
+	}
+
+	// Values that failed to parse would only be reported twice
+	if len(errs) == 0 {
+		errs = append(errs, s.decryptSecrets(secrets)...)
+	}
+	if len(errs) == 0 {
+		errs = append(errs, s.validate()...)
+	}
+	if len(errs) > 0 {
+		return Server{}, errors.Join(errs...)
+	}
+
+	return s, nil
+}
+
+// decryptSecrets replaces the secrets encrypted with KMSKey by their
+// plaintext, so they are validated and used like the others
+func (s *Server) decryptSecrets(values map[string]string) []error {
+	var errs []error
+	var key kms.Key
+	for _, v := range serverVars {
+		value := values[v.key]
+		if !kms.IsEncryptedSecret(value) {
+			continue
+		}
+		if s.KMSKey == "" {
+			errs = append(errs, fmt.Errorf("%s is encrypted, but KMS_KEY is not set", v.env()))
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), kmsTimeout)
+		var err error
+		if key == nil {
+			if key, err = kms.Open(ctx, s.KMSKey); err != nil {
+				cancel()
+				return append(errs, fmt.Errorf("KMS_KEY could not be opened: %v", err))
+			}
+		}
+		plaintext, err := kms.DecryptSecret(ctx, key, v.env(), value)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s could not be decrypted with KMS_KEY: %v", v.env(), err))
+			continue
+		}
+		v.set(s, plaintext)
+	}
+	return errs
+}
+
+// readServerFile reads the values of a config file, choosing the format by
+// its extension. Unknown keys are rejected so typos don't go unnoticed.
+func readServerFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	var raw map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	known := make(map[string]bool, len(serverVars))
+	for _, v := range serverVars {
+		known[v.key] = true
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if !known[key] {
+			return nil, fmt.Errorf("config file %s: unknown key %s", path, key)
+		}
+		switch value.(type) {
+		case string, bool, int, int64, float64:
+			values[key] = fmt.Sprint(value)
+		default:
+			return nil, fmt.Errorf("config file %s: %s must be a single value", path, key)
+		}
+	}
+
+	return values, nil
+}
+
+// validate lists every problem with the configuration, naming values by
+// their environment variable
+func (s Server) validate() []error {
+	var errs []error
+
+	type namedPort struct {
+		name  string
+		value string
+	}
+	ports := []namedPort{
+		{"PORT", s.Port},
+		{"METRICS_PORT", s.MetricsPort},
+	}
+	// Empty disables the gateway
+	if s.GatewayPort != "" {
+		ports = append(ports, namedPort{"GATEWAY_PORT", s.GatewayPort})
+	}
+	if s.GatewayRedirectPort != "" {
+		ports = append(ports, namedPort{"GATEWAY_REDIRECT_PORT", s.GatewayRedirectPort})
+	}
+	if s.InternalPort != "" {
+		ports = append(ports, namedPort{"INTERNAL_PORT", s.InternalPort})
+	}
+	for _, p := range ports {
+		if n, err := strconv.Atoi(p.value); err != nil || n < 1 || n > 65535 {
+			errs = append(errs, fmt.Errorf("%s must be a port number between 1 and 65535", p.name))
+		}
+	}
+
+	if s.MongoURI == "" {
+		errs = append(errs, fmt.Errorf("MONGO_URI is required"))
+	} else if !strings.HasPrefix(s.MongoURI, "mongodb://") && !strings.HasPrefix(s.MongoURI, "mongodb+srv://") {
+		errs = append(errs, fmt.Errorf("MONGO_URI must start with mongodb:// or mongodb+srv://"))
+	}
+	if s.MongoDB == "" {
+		errs = append(errs, fmt.Errorf("MONGO_DB is required"))
+	}
+	if s.QueryTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("QUERY_TIMEOUT must be greater than zero"))
+	}
+
+	type namedSecret struct {
+		name  string
+		value string
+	}
+	var secrets []namedSecret
+	// Not needed when tokens are signed by the KMS, an HSM or key files
+	if (s.JWTSigningKey == "" && s.PKCS11Module == "" && s.JWTKeyFiles == "") || s.JWTSecret != "" {
+		secrets = append(secrets, namedSecret{"JWT_SECRET", s.JWTSecret})
+	}
+	for _, secret := range s.JWTPreviousSecretList() {
+		secrets = append(secrets, namedSecret{"JWT_PREVIOUS_SECRETS", secret})
+	}
+	secrets = append(secrets,
+		namedSecret{"CONFIG_SIGNING_KEY", s.ConfigSigningKey},
+		namedSecret{"TWO_FACTOR_ENCRYPTION_KEY", s.TwoFactorEncryptionKey},
+	)
+	// Optional, but held to the same rules when set. Master keys named by
+	// URI are in a KMS.
+	if s.TenantMasterKey != "" && !kms.IsURI(s.TenantMasterKey) {
+		secrets = append(secrets, namedSecret{"TENANT_MASTER_KEY", s.TenantMasterKey})
+	}
+	if s.TenantPreviousMasterKey != "" && !kms.IsURI(s.TenantPreviousMasterKey) {
+		secrets = append(secrets, namedSecret{"TENANT_PREVIOUS_MASTER_KEY", s.TenantPreviousMasterKey})
+	}
+	if s.ACMEDomains != "" && s.ACMECache == ACMECacheMongoDB {
+		secrets = append(secrets, namedSecret{"ACME_ENCRYPTION_KEY", s.ACMEEncryptionKey})
+	}
+	for _, secret := range secrets {
+		switch {
+		case secret.value == "":
+			errs = append(errs, fmt.Errorf("%s is required", secret.name))
+		case len(secret.value) < minSecretLength:
+			errs = append(errs, fmt.Errorf("%s must be at least %d characters", secret.name, minSecretLength))
+		}
+	}
+	for i, a := range secrets {
+		for _, b := range secrets[i+1:] {
+			if a.value != "" && a.value == b.value {
+				errs = append(errs, fmt.Errorf("%s and %s must be different", a.name, b.name))
+			}
+		}
+	}
+
+	if s.JWTExpiry < 0 {
+		errs = append(errs, fmt.Errorf("JWT_EXPIRY must not be negative"))
+	}
+	if s.BcryptCost < utils.MinBcryptCost || s.BcryptCost > utils.MaxBcryptCost {
+		errs = append(errs, fmt.Errorf("BCRYPT_COST must be between %d and %d", utils.MinBcryptCost, utils.MaxBcryptCost))
+	}
+	switch s.PasswordHash {
+	case utils.HashFormatBcrypt, utils.HashFormatArgon2, utils.HashFormatPBKDF2:
+	default:
+		errs = append(errs, fmt.Errorf("PASSWORD_HASH must be %s, %s or %s", utils.HashFormatBcrypt, utils.HashFormatArgon2, utils.HashFormatPBKDF2))
+	}
+	if _, err := models.NewIDGenerator(s.UserIDFormat); err != nil {
+		errs = append(errs, fmt.Errorf("USER_ID_FORMAT must be %s or %s", models.IDFormatObjectID, models.IDFormatUUIDv7))
+	}
+	if s.Environment == "" {
+		errs = append(errs, fmt.Errorf("ENVIRONMENT is required"))
+	}
+	if s.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT must be greater than zero"))
+	}
+
+	if (s.TLSCertFile == "") != (s.TLSKeyFile == "") {
+		errs = append(errs, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	if s.TLSClientCAFile != "" && s.TLSCertFile == "" {
+		errs = append(errs, fmt.Errorf("TLS_CLIENT_CA_FILE needs TLS_CERT_FILE and TLS_KEY_FILE"))
+	}
+	errs = append(errs, s.validateACME()...)
+	if s.TLSClientAuth != servertls.ClientAuthRequire && s.TLSClientAuth != servertls.ClientAuthOptional {
+		errs = append(errs, fmt.Errorf("TLS_CLIENT_AUTH must be %s or %s", servertls.ClientAuthRequire, servertls.ClientAuthOptional))
+	}
+	errs = append(errs, s.validateGateway()...)
+	errs = append(errs, s.validateSPIFFE()...)
+	if s.RedisURL != "" && !strings.HasPrefix(s.RedisURL, "redis://") && !strings.HasPrefix(s.RedisURL, "rediss://") {
+		errs = append(errs, fmt.Errorf("REDIS_URL must start with redis:// or rediss://"))
+	}
+	if (s.GitHubClientID == "") != (s.GitHubClientSecret == "") {
+		errs = append(errs, fmt.Errorf("GITHUB_CLIENT_ID and GITHUB_CLIENT_SECRET must be set together"))
+	}
+	errs = append(errs, s.validateLoginMethods()...)
+	errs = append(errs, s.validateEmail()...)
+	switch s.TenantIsolationChecks {
+	case tenancy.ModeOff, tenancy.ModeLog, tenancy.ModeEnforce:
+	default:
+		errs = append(errs, fmt.Errorf("TENANT_ISOLATION_CHECKS must be %s, %s or %s", tenancy.ModeOff, tenancy.ModeLog, tenancy.ModeEnforce))
+	}
+
+	keyURIs := []namedSecret{
+		{"JWT_SIGNING_KEY", s.JWTSigningKey},
+		{"KMS_KEY", s.KMSKey},
+	}
+	if kms.IsURI(s.TenantMasterKey) {
+		keyURIs = append(keyURIs, namedSecret{"TENANT_MASTER_KEY", s.TenantMasterKey})
+	}
+	if kms.IsURI(s.TenantPreviousMasterKey) {
+		keyURIs = append(keyURIs, namedSecret{"TENANT_PREVIOUS_MASTER_KEY", s.TenantPreviousMasterKey})
+	}
+	for _, uri := range keyURIs {
+		if uri.value == "" {
+			continue
+		}
+		if err := kms.ValidateURI(uri.value); err != nil {
+			errs = append(errs, fmt.Errorf("%s is invalid: %v", uri.name, err))
+		}
+	}
+	if s.TenantPreviousMasterKey != "" && s.TenantMasterKey == "" {
+		errs = append(errs, fmt.Errorf("TENANT_PREVIOUS_MASTER_KEY needs TENANT_MASTER_KEY"))
+	}
+	if s.JWTPreviousSecrets != "" && s.JWTSecret == "" {
+		errs = append(errs, fmt.Errorf("JWT_PREVIOUS_SECRETS needs JWT_SECRET"))
+	}
+
+	if s.PKCS11Module != "" {
+		if s.JWTSigningKey != "" {
+			errs = append(errs, fmt.Errorf("JWT_SIGNING_KEY and PKCS11_MODULE can't both be set"))
+		}
+		for _, v := range []namedSecret{
+			{"PKCS11_TOKEN_LABEL", s.PKCS11TokenLabel},
+			{"PKCS11_PIN", s.PKCS11PIN},
+			{"PKCS11_KEY_LABEL", s.PKCS11KeyLabel},
+		} {
+			if v.value == "" {
+				errs = append(errs, fmt.Errorf("%s is required with PKCS11_MODULE", v.name))
+			}
+		}
+		if s.PKCS11SlowSigning <= 0 {
+			errs = append(errs, fmt.Errorf("PKCS11_SLOW_SIGNING must be greater than zero"))
+		}
+	}
+	if s.JWTKeyFiles != "" && (s.JWTSigningKey != "" || s.PKCS11Module != "") {
+		errs = append(errs, fmt.Errorf("JWT_KEY_FILES can't be set with JWT_SIGNING_KEY or PKCS11_MODULE"))
+	}
+
+	if s.FIPSMode {
+		errs = append(errs, s.validateFIPS()...)
+	}
+
+	return errs
+}
+
+// validateACME lists the problems with automatic certificates
+func (s Server) validateACME() []error {
+	if s.ACMEDomains == "" {
+		return nil
+	}
+	var errs []error
+	if s.TLSCertFile != "" {
+		errs = append(errs, fmt.Errorf("ACME_DOMAINS and TLS_CERT_FILE can't both be set"))
+	}
+	if len(s.ACMEDomainNames()) == 0 {
+		errs = append(errs, fmt.Errorf("ACME_DOMAINS must name at least one domain"))
+	}
+	for _, domain := range s.ACMEDomainNames() {
+		if strings.Contains(domain, "*") {
+			errs = append(errs, fmt.Errorf("ACME_DOMAINS can't include the wildcard %s: ACME challenges over HTTP and TLS can't validate wildcards", domain))
+		} else if strings.ContainsAny(domain, "/: ") {
+			errs = append(errs, fmt.Errorf("ACME_DOMAINS must be host names, not %s", domain))
+		}
+	}
+	if !strings.HasPrefix(s.ACMEDirectoryURL, "https://") {
+		errs = append(errs, fmt.Errorf("ACME_DIRECTORY_URL must start with https://"))
+	}
+	if s.ACMECache == "" {
+		errs = append(errs, fmt.Errorf("ACME_CACHE must be %s or a directory", ACMECacheMongoDB))
+	}
+	return errs
+}
+
+// ACMEDomainNames returns the domains of ACME_DOMAINS
+func (s Server) ACMEDomainNames() []string {
+	return splitList(s.ACMEDomains)
+}
+
+// JWTPreviousSecretList returns the secrets of JWT_PREVIOUS_SECRETS
+func (s Server) JWTPreviousSecretList() []string {
+	return splitList(s.JWTPreviousSecrets)
+}
+
+// JWTKeyFilePaths returns the files of JWT_KEY_FILES, the signing key
+// first
+func (s Server) JWTKeyFilePaths() []string {
+	return splitList(s.JWTKeyFiles)
+}
+
+// validateSPIFFE lists the problems with the internal port
+func (s Server) validateSPIFFE() []error {
+	if s.InternalPort == "" {
+		return nil
+	}
+	var errs []error
+	if s.TLSCertFile == "" && s.ACMEDomains == "" {
+		errs = append(errs, fmt.Errorf("INTERNAL_PORT needs TLS_CERT_FILE or ACME_DOMAINS"))
+	}
+	if s.InternalPort == s.Port || s.InternalPort == s.GatewayPort {
+		errs = append(errs, fmt.Errorf("INTERNAL_PORT must differ from PORT and GATEWAY_PORT"))
+	}
+	if s.SPIFFEBundleFile == "" {
+		errs = append(errs, fmt.Errorf("SPIFFE_BUNDLE_FILE is required with INTERNAL_PORT"))
+	}
+	if s.SPIFFEAccountsFile == "" {
+		errs = append(errs, fmt.Errorf("SPIFFE_ACCOUNTS_FILE is required with INTERNAL_PORT"))
+	}
+	if _, err := spiffeid.TrustDomainFromString(s.SPIFFETrustDomain); err != nil {
+		errs = append(errs, fmt.Errorf("SPIFFE_TRUST_DOMAIN must be a trust domain such as example.org"))
+	}
+	return errs
+}
+
+// validateLoginMethods lists the problems with the directory and provider
+// of the ldap and oidc login methods
+func (s Server) validateLoginMethods() []error {
+	var errs []error
+	if s.LDAPURL != "" {
+		if !strings.HasPrefix(s.LDAPURL, "ldaps://") && !strings.HasPrefix(s.LDAPURL, "ldap://") {
+			errs = append(errs, fmt.Errorf("LDAP_URL must start with ldaps:// or ldap://"))
+		}
+		if s.LDAPBindDN == "" || s.LDAPBindPassword == "" || s.LDAPBaseDN == "" {
+			errs = append(errs, fmt.Errorf("LDAP_BIND_DN, LDAP_BIND_PASSWORD and LDAP_BASE_DN are required with LDAP_URL"))
+		}
+		if err := ldapauth.ValidateUserFilter(s.LDAPUserFilter); err != nil {
+			errs = append(errs, fmt.Errorf("LDAP_USER_FILTER is invalid: %v", err))
+		}
+	}
+	if s.OIDCLoginIssuer != "" {
+		if !strings.HasPrefix(s.OIDCLoginIssuer, "https://") {
+			errs = append(errs, fmt.Errorf("OIDC_LOGIN_ISSUER must start with https://"))
+		}
+		if s.OIDCLoginClientID == "" {
+			errs = append(errs, fmt.Errorf("OIDC_LOGIN_CLIENT_ID is required with OIDC_LOGIN_ISSUER"))
+		}
+	}
+	return errs
+}
+
+// validateEmail lists the problems with the delivery of notification
+// emails
+func (s Server) validateEmail() []error {
+	var errs []error
+	switch s.EmailSender {
+	case notifications.SenderLog:
+		return nil
+	case notifications.SenderSMTP:
+		if _, _, err := net.SplitHostPort(s.SMTPAddr); err != nil {
+			errs = append(errs, fmt.Errorf("SMTP_ADDR must be a host:port with EMAIL_SENDER=%s", notifications.SenderSMTP))
+		}
+		if s.SMTPPassword != "" && s.SMTPUsername == "" {
+			errs = append(errs, fmt.Errorf("SMTP_PASSWORD needs SMTP_USERNAME"))
+		}
+	case notifications.SenderSendGrid:
+		if s.SendGridAPIKey == "" {
+			errs = append(errs, fmt.Errorf("SENDGRID_API_KEY is required with EMAIL_SENDER=%s", notifications.SenderSendGrid))
+		}
+	default:
+		return []error{fmt.Errorf("EMAIL_SENDER must be %s, %s or %s", notifications.SenderLog, notifications.SenderSMTP, notifications.SenderSendGrid)}
+	}
+	if _, err := mail.ParseAddress(s.EmailFrom); err != nil {
+		errs = append(errs, fmt.Errorf("EMAIL_FROM must be an email address with EMAIL_SENDER=%s", s.EmailSender))
+	}
+	return errs
+}
+
+// validateGateway lists the problems with the REST gateway's TLS policy
+// and redirects
+func (s Server) validateGateway() []error {
+	var errs []error
+	if _, err := servertls.ParseVersion(s.GatewayTLSMinVersion); err != nil {
+		errs = append(errs, fmt.Errorf("GATEWAY_TLS_MIN_VERSION must be 1.2 or 1.3"))
+	}
+	if ciphers, err := servertls.ParseCipherSuites(s.gatewayTLSCipherNames()); err != nil {
+		errs = append(errs, fmt.Errorf("GATEWAY_TLS_CIPHERS is invalid: %v", err))
+	} else if s.FIPSMode {
+		for i, cipher := range ciphers {
+			if !slices.Contains(fips.TLSCipherSuites, cipher) {
+				errs = append(errs, fmt.Errorf("GATEWAY_TLS_CIPHERS can't include %s in FIPS mode", s.gatewayTLSCipherNames()[i]))
+			}
+		}
+	}
+	if s.GatewayHSTSMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("GATEWAY_HSTS_MAX_AGE must not be negative"))
+	}
+	if s.GatewayRedirectPort != "" {
+		switch {
+		case s.GatewayPort == "":
+			errs = append(errs, fmt.Errorf("GATEWAY_REDIRECT_PORT needs GATEWAY_PORT"))
+		case s.TLSCertFile == "" && s.ACMEDomains == "":
+			errs = append(errs, fmt.Errorf("GATEWAY_REDIRECT_PORT needs TLS_CERT_FILE and TLS_KEY_FILE, or ACME_DOMAINS"))
+		case s.GatewayRedirectPort == s.GatewayPort:
+			errs = append(errs, fmt.Errorf("GATEWAY_REDIRECT_PORT and GATEWAY_PORT must be different"))
+		}
+	}
+	return errs
+}
+
+func (s Server) gatewayTLSCipherNames() []string {
+	return splitList(s.GatewayTLSCiphers)
+}
+
+// splitList returns the items of a comma-separated list, without blanks
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// GatewayTLSPolicy returns the TLS versions and cipher suites of the REST
+// gateway. The configuration has been validated, so it can't fail.
+func (s Server) GatewayTLSPolicy() servertls.Policy {
+	minVersion, _ := servertls.ParseVersion(s.GatewayTLSMinVersion)
+	ciphers, _ := servertls.ParseCipherSuites(s.gatewayTLSCipherNames())
+	return servertls.Policy{MinVersion: minVersion, CipherSuites: ciphers}
+}
+
+// validateFIPS lists the settings that would use algorithms FIPS 140-3
+// doesn't approve. TLS certificates are checked when they are loaded.
+func (s Server) validateFIPS() []error {
+	var errs []error
+	if !fips.ModuleEnabled() {
+		errs = append(errs, fmt.Errorf("FIPS_MODE needs Go's cryptographic module in FIPS mode: build with GOFIPS140=v1.0.0 or set GODEBUG=fips140=on"))
+	}
+	if s.PasswordHash != utils.HashFormatPBKDF2 {
+		errs = append(errs, fmt.Errorf("PASSWORD_HASH must be %s in FIPS mode", utils.HashFormatPBKDF2))
+	}
+	return errs
+}
+
+// DefaultSettings returns the built-in settings with JWTExpiry applied
+func (s Server) DefaultSettings() Settings {
+	settings := DefaultSettings()
+	if s.JWTExpiry > 0 {
+		settings.Tokens.Expiry = Duration(s.JWTExpiry)
+	}
+	return settings
+}
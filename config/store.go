@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"user-management/database"
+)
+
+// storedSettingsID is the _id of the single settings document
+const storedSettingsID = "runtime"
+
+type storedSettings struct {
+	ID                string    `bson:"_id"`
+	Settings          Settings  `bson:"settings"`
+	SourceEnvironment string    `bson:"source_environment"`
+	ImportedBy        string    `bson:"imported_by"`
+	ImportedAt        time.Time `bson:"imported_at"`
+}
+
+// LoadStored returns the settings saved by the last import, or false when
+// nothing has been imported
+func LoadStored(ctx context.Context, db *database.Database) (Settings, bool, error) {
+	// Settings added since the import keep their defaults
+	stored := storedSettings{Settings: DefaultSettings()}
+	err := db.Settings.FindOne(ctx, bson.M{"_id": storedSettingsID}).Decode(&stored)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return Settings{}, false, nil
+		}
+		return Settings{}, false, fmt.Errorf("failed to load stored settings: %v", err)
+	}
+
+	if err := stored.Settings.Validate(); err != nil {
+		return Settings{}, false, fmt.Errorf("stored settings are invalid: %v", err)
+	}
+
+	return stored.Settings, true, nil
+}
+
+// SaveImported stores the settings from an imported snapshot. They take
+// effect the next time the server starts.
+func SaveImported(ctx context.Context, db *database.Database, snapshot *Snapshot, importedBy string) error {
+	_, err := db.Settings.ReplaceOne(ctx, bson.M{"_id": storedSettingsID}, storedSettings{
+		ID:                storedSettingsID,
+		Settings:          snapshot.Settings,
+		SourceEnvironment: snapshot.Environment,
+		ImportedBy:        importedBy,
+		ImportedAt:        time.Now(),
+	}, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save settings: %v", err)
+	}
+	return nil
+}
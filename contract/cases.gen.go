@@ -0,0 +1,1518 @@
+// Code generated by contractgen. DO NOT EDIT.
+
+package contract
+
+import (
+	"google.golang.org/grpc/codes"
+
+	pb "user-management/proto"
+)
+
+// Cases holds a case for every requirement annotated in proto/user.proto
+var Cases = []Case{
+	{
+		Method:  "/user.AuthService/Login",
+		Name:    "rejects an invalid email",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "not-an-email", "password": "x"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/Login",
+		Name:    "rejects a missing password",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "user@example.com"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/RestoreProfile",
+		Name:    "rejects an invalid email",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "not-an-email", "password": "Password1!"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/RestoreProfile",
+		Name:    "rejects a missing password",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "user@example.com"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/SendLoginCode",
+		Name:    "rejects an invalid email",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "not-an-email"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/BeginPasskeyLogin",
+		Name:    "rejects an invalid email",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "not-an-email"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/Logout",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/Logout",
+		Name:    "rejects an invalid token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"token": "not-a-token"}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AuthService/RefreshToken",
+		Name:    "rejects a missing refresh token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/RefreshToken",
+		Name:    "rejects an unknown refresh token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"refresh_token": "not-a-refresh-token"}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AuthService/LogoutAllDevices",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/LogoutAllDevices",
+		Name:    "rejects an invalid token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"token": "not-a-token"}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AuthService/Register",
+		Name:    "rejects an invalid email",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "not-an-email", "password": "Password1!", "name": "Test User"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/Register",
+		Name:    "rejects a weak password",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "user@example.com", "password": "password", "name": "Test User"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/ListPendingLogins",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AuthService/ApproveDeviceLogin",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"user_code": "ABCD-EFGH"}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AuthService/CreateActionToken",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AuthService/ValidateToken",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/StartTwoFactorReset",
+		Name:    "rejects an invalid email",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "not-an-email"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/ResetTwoFactor",
+		Name:    "rejects a missing password",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "user@example.com", "email_code": "123456"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/ResetTwoFactor",
+		Name:    "rejects a missing second proof",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "user@example.com", "password": "Password1!"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/CancelTwoFactorReset",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/CancelTwoFactorReset",
+		Name:    "rejects an unknown token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"token": "not-a-token"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/RequestPasswordReset",
+		Name:    "rejects an invalid email",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "not-an-email"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/ResetPassword",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"new_password": "Password1!"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/ResetPassword",
+		Name:    "rejects a weak password",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"token": "not-a-token", "new_password": "password"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/ResetPassword",
+		Name:    "rejects an unknown token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"token": "not-a-token", "new_password": "Password1!"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/GetAuthOptions",
+		Name:    "rejects an invalid email",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"email": "not-an-email"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/GetAuthOptions",
+		Name:    "rejects an invalid organization ID",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"organization_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/CompleteSSOLogin",
+		Name:    "rejects a missing state",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"code": "x"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/CompleteSSOLogin",
+		Name:    "rejects an unknown state",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"state": "not-a-state", "code": "x"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/LoginWithProvider",
+		Name:    "rejects an unknown provider",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"provider": "myspace", "token": "x"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/LoginWithProvider",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"provider": "google"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/StartEmailVerification",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AuthService/VerifyEmail",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AuthService/VerifyEmail",
+		Name:    "rejects a missing code",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/VerifyEmail",
+		Name:    "rejects a code that was never sent",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"code": "000000"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AuthService/StartSetPassword",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AuthService/SetPassword",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AuthService/SetPassword",
+		Name:    "rejects a missing code",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"new_password": "Correct-Horse-42"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/GetProfile",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/GetProfile",
+		Name:    "rejects an invalid user ID",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"user_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/GetProfile",
+		Name:    "rejects another user's profile",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"user_id": "ffffffffffffffffffffffff"}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.UserService/UpdateProfile",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/UpdateProfile",
+		Name:    "rejects another user's profile",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"user_id": "ffffffffffffffffffffffff", "name": "Other"}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.UserService/DeleteProfile",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/DeleteProfile",
+		Name:    "rejects another user's profile",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"user_id": "ffffffffffffffffffffffff"}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.UserService/ListUsers",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/ListUsers",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.UserService/ListUsers",
+		Name:    "rejects an overlong name filter",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"name_filter": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/ListUsers",
+		Name:    "rejects created_after later than created_before",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"created_after": "2025-02-01T00:00:00Z", "created_before": "2025-01-01T00:00:00Z"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/ListUsers",
+		Name:    "rejects an unknown sort field",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"sort_by": "password"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/ListUsers",
+		Name:    "rejects an unknown sort order",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"sort_order": "random"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/ChangePassword",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/ChangePassword",
+		Name:    "rejects another user's password",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"user_id": "ffffffffffffffffffffffff", "current_password": "Password1!", "new_password": "Password2!"}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.UserService/EnableTwoFactor",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/VerifyTwoFactor",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{"code": "123456"}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/DisableTwoFactor",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/DisableTwoFactor",
+		Name:    "rejects a missing password",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"code": "123456"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/GenerateRecoveryCodes",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/BeginCredentialRegistration",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/RegisterCredential",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/RegisterCredential",
+		Name:    "rejects a missing credential",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/ListCredentials",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/RenameCredential",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/RenameCredential",
+		Name:    "rejects an unknown credential type",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"id": "ffffffffffffffffffffffff", "type": "password", "name": "Work laptop"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/RenameCredential",
+		Name:    "rejects a missing name",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"id": "ffffffffffffffffffffffff", "type": "passkey"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/RenameCredential",
+		Name:    "rejects another user's credential",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"id": "ffffffffffffffffffffffff", "type": "trusted_device", "name": "Work laptop"}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.UserService/DeleteCredential",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/DeleteCredential",
+		Name:    "rejects an invalid credential ID",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"id": "not-an-id", "type": "passkey"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/ListKnownDevices",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/ListLinkedIdentities",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/UnlinkIdentity",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/UnlinkIdentity",
+		Name:    "rejects a missing subject",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"issuer": "https://accounts.example.com"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/UnlinkIdentity",
+		Name:    "rejects an identity that isn't linked",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"issuer": "https://accounts.example.com", "subject": "unknown"}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.UserService/ListSessions",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/WatchSession",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/RevokeSession",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/RevokeSession",
+		Name:    "rejects a missing session ID",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/RevokeSession",
+		Name:    "rejects an unknown session",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"session_id": "unknown"}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.UserService/CreateApiKey",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/CreateApiKey",
+		Name:    "rejects a missing name",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/CreateApiKey",
+		Name:    "rejects an expiry in the past",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"name": "Billing sync", "expires_at": "2020-01-01T00:00:00Z"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/ListApiKeys",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/RevokeApiKey",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/RevokeApiKey",
+		Name:    "rejects an invalid API key ID",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.UserService/RevokeApiKey",
+		Name:    "rejects another user's API key",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{"id": "ffffffffffffffffffffffff"}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.UserService/AcceptTerms",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.UserService/AcceptTerms",
+		Name:    "rejects a missing version",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/ListNotificationTemplates",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ListNotificationTemplates",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/PreviewNotificationTemplate",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/PreviewNotificationTemplate",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/SendTestNotification",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/SendTestNotification",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/ListDeadLetterEvents",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ListDeadLetterEvents",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/ReplayDeadLetterEvents",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ReplayDeadLetterEvents",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/ExportConfig",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ExportConfig",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/ImportConfig",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ImportConfig",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/LockUser",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/LockUser",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/UnlockUser",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/UnlockUser",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/DeactivateUser",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/DeactivateUser",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/DeactivateUser",
+		Name:    "rejects an invalid user ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/ReactivateUser",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ReactivateUser",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/ReactivateUser",
+		Name:    "rejects an invalid user ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/HardDeleteUser",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/HardDeleteUser",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/HardDeleteUser",
+		Name:    "rejects an invalid user ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "not-an-id", "confirm": true}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/HardDeleteUser",
+		Name:    "requires confirmation",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "000000000000000000000000"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/HardDeleteUser",
+		Name:    "dry run of a missing user",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "ffffffffffffffffffffffff", "dry_run": true}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.AdminService/ResetUserPassword",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ResetUserPassword",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/ResetUserPassword",
+		Name:    "rejects an invalid user ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/GetLoginHistory",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/GetLoginHistory",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/GetLoginHistory",
+		Name:    "rejects an invalid user ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/ForceLogout",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ForceLogout",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/ForceLogout",
+		Name:    "rejects an invalid user ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/ForceLogout",
+		Name:    "dry run of a missing user",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "ffffffffffffffffffffffff", "dry_run": true}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.AdminService/CreateOrganization",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/CreateOrganization",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/UpdateOrganizationPolicy",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/UpdateOrganizationPolicy",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/SetOrganizationSSO",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/SetOrganizationSSO",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/SetOrganizationProvisioning",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/SetOrganizationProvisioning",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/SetOrganizationProvisioning",
+		Name:    "rejects an invalid organization ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"org_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/SetOrganizationProvisioning",
+		Name:    "rejects an unknown role",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"org_id": "000000000000000000000000", "provisioning": {"default_role": "owner"}}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/DeprovisionOrganizationMembers",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/DeprovisionOrganizationMembers",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/DeprovisionOrganizationMembers",
+		Name:    "rejects an invalid organization ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"org_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/GetDeprovisioningJob",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/GetDeprovisioningJob",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/GetDeprovisioningJob",
+		Name:    "rejects an invalid job ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"job_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/SetOrganizationMember",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/SetOrganizationMember",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/CancelOperation",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/CancelOperation",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/CancelOperation",
+		Name:    "rejects an invalid operation ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"operation_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/CancelOperation",
+		Name:    "missing operation",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"operation_id": "000000000000000000000000"}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.AdminService/ScheduleAction",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ScheduleAction",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/ScheduleAction",
+		Name:    "rejects an invalid user ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "not-an-id", "action": "deactivate"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/ScheduleAction",
+		Name:    "rejects an unknown action",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "000000000000000000000000", "action": "promote"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/CancelScheduledAction",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/CancelScheduledAction",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/CancelScheduledAction",
+		Name:    "missing scheduled action",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"action_id": "000000000000000000000000"}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.AdminService/ListScheduledActions",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ListScheduledActions",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/EraseUser",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/EraseUser",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/EraseUser",
+		Name:    "rejects an invalid user ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "not-an-id", "confirm": true}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/EraseUser",
+		Name:    "requires confirmation",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_id": "000000000000000000000000"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/GetWorkflow",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/GetWorkflow",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/GetWorkflow",
+		Name:    "missing workflow",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"workflow_id": "000000000000000000000000"}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.AdminService/ListWorkflows",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ListWorkflows",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/RetryWorkflow",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/RetryWorkflow",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/RetryWorkflow",
+		Name:    "missing workflow",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"workflow_id": "000000000000000000000000"}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.AdminService/DestroyOrganizationKey",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/DestroyOrganizationKey",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/DestroyOrganizationKey",
+		Name:    "rejects an invalid organization ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"org_id": "not-an-id", "confirm": true}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/DestroyOrganizationKey",
+		Name:    "requires confirmation",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"org_id": "000000000000000000000000"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/SetExternalId",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/SetExternalId",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/GetUserByExternalId",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/GetUserByExternalId",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/GetUsersByIds",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/GetUsersByIds",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/GetUsersByIds",
+		Name:    "invalid user ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"user_ids": ["not-an-id"]}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/ExportUsers",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ExportUsers",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/ExportUsers",
+		Name:    "invalid resume cursor",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"resume_after": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/ImportUsers",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ImportUsers",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/GetOperation",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/GetOperation",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/GetOperation",
+		Name:    "rejects an invalid operation ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"operation_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/GetOperation",
+		Name:    "missing operation",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"operation_id": "000000000000000000000000"}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.AdminService/ListOperations",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ListOperations",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/WatchOperation",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/WatchOperation",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/WatchOperation",
+		Name:    "missing operation",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"operation_id": "000000000000000000000000"}`,
+		Code:    codes.NotFound,
+	},
+	{
+		Method:  "/user.AdminService/RebuildIndexes",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/RebuildIndexes",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/RunAccountPurge",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/RunAccountPurge",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/ReplayAuditLog",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ReplayAuditLog",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/GetAuditLogs",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/GetAuditLogs",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/GetAuditLogs",
+		Name:    "rejects an invalid target ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"target_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/GetAuditLogs",
+		Name:    "rejects an invalid actor ID",
+		Caller:  pb.Caller_CALLER_ADMIN,
+		Request: `{"actor_id": "not-an-id"}`,
+		Code:    codes.InvalidArgument,
+	},
+	{
+		Method:  "/user.AdminService/GetUserAt",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/GetUserAt",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/ListUserEvents",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/ListUserEvents",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.AdminService/GetReplicationStatus",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.AdminService/GetReplicationStatus",
+		Name:    "rejects a caller without the admin role",
+		Caller:  pb.Caller_CALLER_USER,
+		Request: `{}`,
+		Code:    codes.PermissionDenied,
+	},
+	{
+		Method:  "/user.OrganizationService/ListProfileChangeRequests",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.OrganizationService/ApproveProfileChange",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+	{
+		Method:  "/user.OrganizationService/RejectProfileChange",
+		Name:    "rejects a missing token",
+		Caller:  pb.Caller_CALLER_ANONYMOUS,
+		Request: `{}`,
+		Code:    codes.Unauthenticated,
+	},
+}
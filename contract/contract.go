@@ -0,0 +1,135 @@
+// Package contract checks that a server keeps the behaviour annotated on
+// the API with the (contract) method option in proto/contract.proto. Cases
+// is generated from those annotations; regenerate it after changing them.
+// Run works against any implementation, such as the real server or
+// cmd/mockserver.
+package contract
+
+//go:generate go run ../cmd/contractgen -o cases.gen.go
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	pb "user-management/proto"
+)
+
+// Case is a call that must fail with Code
+type Case struct {
+	// Method is the full method name, e.g. "/user.AuthService/Login"
+	Method string
+	Name   string
+	Caller pb.Caller
+	// Request is the request in protojson
+	Request string
+	Code    codes.Code
+}
+
+// Config holds the tokens cases are run with. Cases for a caller without a
+// token are skipped.
+type Config struct {
+	// UserToken belongs to an account without the admin role
+	UserToken  string
+	AdminToken string
+}
+
+// Result is the outcome of one case
+type Result struct {
+	Case Case
+	// Skipped is set when Config has no token for the case's caller or
+	// the server does not implement the method
+	Skipped    bool
+	SkipReason string
+	// Got is the code the server returned
+	Got codes.Code
+	// Err is set when the case failed
+	Err error
+}
+
+// Run runs every case against the server behind conn
+func Run(ctx context.Context, conn grpc.ClientConnInterface, cases []Case, config Config) []Result {
+	results := make([]Result, 0, len(cases))
+	for _, c := range cases {
+		results = append(results, runCase(ctx, conn, c, config))
+	}
+	return results
+}
+
+func runCase(ctx context.Context, conn grpc.ClientConnInterface, c Case, config Config) Result {
+	result := Result{Case: c}
+
+	var token string
+	switch c.Caller {
+	case pb.Caller_CALLER_USER:
+		token = config.UserToken
+	case pb.Caller_CALLER_ADMIN:
+		token = config.AdminToken
+	}
+	if c.Caller != pb.Caller_CALLER_ANONYMOUS && token == "" {
+		result.Skipped = true
+		result.SkipReason = fmt.Sprintf("no token for %s", c.Caller)
+		return result
+	}
+	if token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+
+	method, err := lookupMethod(c.Method)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	req := dynamicpb.NewMessage(method.Input())
+	if err := protojson.Unmarshal([]byte(c.Request), req); err != nil {
+		result.Err = fmt.Errorf("invalid request: %v", err)
+		return result
+	}
+
+	err = conn.Invoke(ctx, c.Method, req, dynamicpb.NewMessage(method.Output()))
+	result.Got = status.Code(err)
+	if result.Got == codes.Unimplemented && c.Code != codes.Unimplemented {
+		// Partial implementations, such as mocks, are checked on what they
+		// implement
+		result.Skipped = true
+		result.SkipReason = "not implemented"
+		return result
+	}
+	if result.Got != c.Code {
+		result.Err = fmt.Errorf("got %s, want %s: %v", result.Got, c.Code, err)
+	}
+	return result
+}
+
+// lookupMethod finds the descriptor of a full method name
+func lookupMethod(fullMethod string) (protoreflect.MethodDescriptor, error) {
+	service, name, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid method name %q", fullMethod)
+	}
+
+	descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("unknown service %q", service)
+	}
+	serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a service", service)
+	}
+
+	method := serviceDescriptor.Methods().ByName(protoreflect.Name(name))
+	if method == nil {
+		return nil, fmt.Errorf("unknown method %q", fullMethod)
+	}
+	return method, nil
+}
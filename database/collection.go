@@ -0,0 +1,297 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"user-management/authmd"
+	"user-management/requestctx"
+)
+
+// Collection wraps a mongo.Collection and tags every operation with a
+// $comment naming the request ID, RPC and acting user, so slow query logs
+// and the profiler can be traced back to the request. A comment set by the
+// caller takes precedence.
+//
+// Every operation is also bounded by the query timeout. The driver sends
+// the remaining time as maxTimeMS, so the server abandons the query when
+// the timeout passes or the RPC's context is cancelled. Reads that fail
+// transiently, such as during a primary election, are retried within the
+// same timeout.
+type Collection struct {
+	*mongo.Collection
+	timeout time.Duration
+	budget  *retryBudget
+}
+
+func newCollection(collection *mongo.Collection, timeout time.Duration, budget *retryBudget) *Collection {
+	return &Collection{Collection: collection, timeout: timeout, budget: budget}
+}
+
+// withTimeout bounds ctx by the query timeout. A shorter deadline already
+// on ctx, such as the RPC's, is kept.
+func (c *Collection) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// queryComment describes the request that ctx belongs to
+type queryComment struct {
+	RequestID string `json:"request_id,omitempty"`
+	RPC       string `json:"rpc,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+}
+
+// commentFor returns the $comment for operations made with ctx, or false
+// when ctx carries nothing to identify the request
+func commentFor(ctx context.Context) (string, bool) {
+	var comment queryComment
+	if info, ok := requestctx.FromContext(ctx); ok {
+		comment.RequestID = info.RequestID
+		comment.RPC = info.Method
+	}
+	if principal, ok := authmd.FromContext(ctx); ok {
+		comment.UserID = principal.UserID
+	}
+
+	if comment == (queryComment{}) {
+		return "", false
+	}
+
+	data, err := json.Marshal(comment)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func (c *Collection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "find", filter); err != nil {
+		return nil, err
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.FindOptions{options.Find().SetComment(comment)}, opts...)
+	}
+	var cursor *mongo.Cursor
+	err := c.retryRead(ctx, func() error {
+		var err error
+		cursor, err = c.Collection.Find(ctx, filter, opts...)
+		return err
+	})
+	return cursor, err
+}
+
+func (c *Collection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "findOne", filter); err != nil {
+		return failedResult(err)
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.FindOneOptions{options.FindOne().SetComment(comment)}, opts...)
+	}
+	var result *mongo.SingleResult
+	c.retryRead(ctx, func() error {
+		result = c.Collection.FindOne(ctx, filter, opts...)
+		return result.Err()
+	})
+	return result
+}
+
+func (c *Collection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "findOneAndUpdate", filter); err != nil {
+		return failedResult(err)
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.FindOneAndUpdateOptions{options.FindOneAndUpdate().SetComment(comment)}, opts...)
+	}
+	return c.Collection.FindOneAndUpdate(ctx, filter, update, opts...)
+}
+
+func (c *Collection) FindOneAndReplace(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.FindOneAndReplaceOptions) *mongo.SingleResult {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "findOneAndReplace", filter); err != nil {
+		return failedResult(err)
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.FindOneAndReplaceOptions{options.FindOneAndReplace().SetComment(comment)}, opts...)
+	}
+	return c.Collection.FindOneAndReplace(ctx, filter, replacement, opts...)
+}
+
+func (c *Collection) FindOneAndDelete(ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) *mongo.SingleResult {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "findOneAndDelete", filter); err != nil {
+		return failedResult(err)
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.FindOneAndDeleteOptions{options.FindOneAndDelete().SetComment(comment)}, opts...)
+	}
+	return c.Collection.FindOneAndDelete(ctx, filter, opts...)
+}
+
+func (c *Collection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.InsertOneOptions{options.InsertOne().SetComment(comment)}, opts...)
+	}
+	return c.Collection.InsertOne(ctx, document, opts...)
+}
+
+func (c *Collection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.InsertManyOptions{options.InsertMany().SetComment(comment)}, opts...)
+	}
+	return c.Collection.InsertMany(ctx, documents, opts...)
+}
+
+func (c *Collection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "updateOne", filter); err != nil {
+		return nil, err
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.UpdateOptions{options.Update().SetComment(comment)}, opts...)
+	}
+	return c.Collection.UpdateOne(ctx, filter, update, opts...)
+}
+
+func (c *Collection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "updateMany", filter); err != nil {
+		return nil, err
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.UpdateOptions{options.Update().SetComment(comment)}, opts...)
+	}
+	return c.Collection.UpdateMany(ctx, filter, update, opts...)
+}
+
+func (c *Collection) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "replaceOne", filter); err != nil {
+		return nil, err
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.ReplaceOptions{options.Replace().SetComment(comment)}, opts...)
+	}
+	return c.Collection.ReplaceOne(ctx, filter, replacement, opts...)
+}
+
+func (c *Collection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "deleteOne", filter); err != nil {
+		return nil, err
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.DeleteOptions{options.Delete().SetComment(comment)}, opts...)
+	}
+	return c.Collection.DeleteOne(ctx, filter, opts...)
+}
+
+func (c *Collection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "deleteMany", filter); err != nil {
+		return nil, err
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.DeleteOptions{options.Delete().SetComment(comment)}, opts...)
+	}
+	return c.Collection.DeleteMany(ctx, filter, opts...)
+}
+
+func (c *Collection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "countDocuments", filter); err != nil {
+		return 0, err
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.CountOptions{options.Count().SetComment(comment)}, opts...)
+	}
+	var count int64
+	err := c.retryRead(ctx, func() error {
+		var err error
+		count, err = c.Collection.CountDocuments(ctx, filter, opts...)
+		return err
+	})
+	return count, err
+}
+
+func (c *Collection) EstimatedDocumentCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "estimatedDocumentCount", nil); err != nil {
+		return 0, err
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.EstimatedDocumentCountOptions{options.EstimatedDocumentCount().SetComment(comment)}, opts...)
+	}
+	var count int64
+	err := c.retryRead(ctx, func() error {
+		var err error
+		count, err = c.Collection.EstimatedDocumentCount(ctx, opts...)
+		return err
+	})
+	return count, err
+}
+
+func (c *Collection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.checkQuery(ctx, "aggregate", pipeline); err != nil {
+		return nil, err
+	}
+
+	if comment, ok := commentFor(ctx); ok {
+		opts = append([]*options.AggregateOptions{options.Aggregate().SetComment(comment)}, opts...)
+	}
+	return c.Collection.Aggregate(ctx, pipeline, opts...)
+}
@@ -4,25 +4,97 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"go.mongodb.org/mongo-driver/tag"
 )
 
 type Database struct {
-	Client   *mongo.Client
-	DB       *mongo.Database
-	Users    *mongo.Collection
-	Tokens   *mongo.Collection
-	Attempts *mongo.Collection
+	Client       *mongo.Client
+	DB           *mongo.Database
+	Users        *Collection
+	Tokens       *Collection
+	Attempts     *Collection
+	Devices      *Collection
+	Pending      *Collection
+	DeviceLogins *Collection
+	Outbox       *Collection
+	DeadLetters  *Collection
+	Settings     *Collection
+	Challenges   *Collection
+	AuditLogs    *Collection
+	Orgs         *Collection
+	Changes      *Collection
+	LoginHistory *Collection
+	AuditStats   *Collection
+	// UserEvents and UserSnapshots hold the event-sourced user history
+	UserEvents    *Collection
+	UserSnapshots *Collection
+	// PasswordResets holds reset tokens and the requests counted by the
+	// reset rate limits
+	PasswordResets *Collection
+	Passkeys       *Collection
+	Sessions       *Collection
+	RefreshTokens  *Collection
+	// SSOLogins holds logins waiting for an identity provider
+	SSOLogins *Collection
+	// Deprovisioning holds deprovisioning jobs and their reports
+	Deprovisioning *Collection
+	// PendingOperations holds destructive admin actions waiting out the
+	// undo window
+	PendingOperations *Collection
+	// Operations holds long-running admin tasks and their results
+	Operations *Collection
+	// ScheduledActions holds admin actions on accounts set to run later
+	ScheduledActions *Collection
+	// Workflows holds multi-step flows, such as account erasures, and the
+	// step each has reached
+	Workflows *Collection
+	// TenantKeys holds organizations' wrapped data keys
+	TenantKeys *Collection
+	// Certificates caches the ACME account key and the certificates it
+	// obtained, encrypted
+	Certificates *Collection
+	// APIKeys holds the hashed API keys of machine clients
+	APIKeys *Collection
+
+	supportsTransactions bool
+	eventSourcedUsers    bool
+	topology             *topologyTracker
+
+	// indexes are the names of the indexes createIndexes made, by
+	// collection, which CheckIndexes looks for
+	indexesMu sync.Mutex
+	indexes   map[string][]string
+	// expiring are the TTL indexes ensureIndexes made, by collection,
+	// which DeleteExpired applies when MongoDB's TTL monitor doesn't run
+	expiring map[string][]expiringIndex
 }
 
 type Config struct {
 	URI      string
 	Database string
-	Timeout  time.Duration
+	// Timeout bounds connecting and creating indexes at startup
+	Timeout time.Duration
+	// QueryTimeout bounds each operation made through a Collection
+	QueryTimeout time.Duration
+	// EventSourcedUsers records every user change in the user_events
+	// collection
+	EventSourcedUsers bool
+
+	// Region is the region this server runs in. When set, reads go to the
+	// primary, or to a member tagged with this region while there is no
+	// primary.
+	Region string
+	// MajorityWrites makes writes wait for a majority of members, so a
+	// regional failover cannot lose acknowledged writes
+	MajorityWrites bool
 }
 
 func NewDatabase(config Config) (*Database, error) {
@@ -32,6 +104,28 @@ func NewDatabase(config Config) (*Database, error) {
 	// Set client options
 	clientOptions := options.Client().ApplyURI(config.URI)
 
+	// Derive maxTimeMS from each operation's deadline so the server stops
+	// work the client has given up on
+	if config.QueryTimeout > 0 {
+		clientOptions.SetTimeout(config.QueryTimeout)
+	}
+	topology := newTopologyTracker(config.Region, config.MajorityWrites)
+	clientOptions.SetServerMonitor(topology.monitor())
+
+	// Keep serving reads from the local region during an election
+	if config.Region != "" {
+		clientOptions.SetReadPreference(readpref.PrimaryPreferred(
+			readpref.WithTagSets(
+				tag.Set{{Name: RegionTag, Value: config.Region}},
+				tag.Set{},
+			),
+			readpref.WithMaxStaleness(90*time.Second),
+		))
+	}
+	if config.MajorityWrites {
+		clientOptions.SetWriteConcern(writeconcern.Majority())
+	}
+
 	// Connect to MongoDB
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
@@ -46,12 +140,50 @@ func NewDatabase(config Config) (*Database, error) {
 
 	db := client.Database(config.Database)
 
+	// Shared by all collections: at most one read retry per ten reads,
+	// with a burst of ten
+	budget := newRetryBudget(10, 0.1)
+
 	database := &Database{
-		Client:   client,
-		DB:       db,
-		Users:    db.Collection("users"),
-		Tokens:   db.Collection("invalidated_tokens"),
-		Attempts: db.Collection("login_attempts"),
+		Client:       client,
+		DB:           db,
+		Users:        newCollection(db.Collection("users"), config.QueryTimeout, budget),
+		Tokens:       newCollection(db.Collection("invalidated_tokens"), config.QueryTimeout, budget),
+		Attempts:     newCollection(db.Collection("login_attempts"), config.QueryTimeout, budget),
+		Devices:      newCollection(db.Collection("devices"), config.QueryTimeout, budget),
+		Pending:      newCollection(db.Collection("pending_logins"), config.QueryTimeout, budget),
+		DeviceLogins: newCollection(db.Collection("device_logins"), config.QueryTimeout, budget),
+		Outbox:       newCollection(db.Collection("outbox_events"), config.QueryTimeout, budget),
+		DeadLetters:  newCollection(db.Collection("dead_letter_events"), config.QueryTimeout, budget),
+		Settings:     newCollection(db.Collection("settings"), config.QueryTimeout, budget),
+		Challenges:   newCollection(db.Collection("email_challenges"), config.QueryTimeout, budget),
+		AuditLogs:    newCollection(db.Collection("audit_logs"), config.QueryTimeout, budget),
+		Orgs:         newCollection(db.Collection("organizations"), config.QueryTimeout, budget),
+		Changes:      newCollection(db.Collection("profile_change_requests"), config.QueryTimeout, budget),
+		LoginHistory: newCollection(db.Collection("login_history"), config.QueryTimeout, budget),
+		AuditStats:   newCollection(db.Collection("audit_stats"), config.QueryTimeout, budget),
+
+		UserEvents:    newCollection(db.Collection("user_events"), config.QueryTimeout, budget),
+		UserSnapshots: newCollection(db.Collection("user_snapshots"), config.QueryTimeout, budget),
+
+		PasswordResets: newCollection(db.Collection("password_resets"), config.QueryTimeout, budget),
+		Passkeys:       newCollection(db.Collection("passkeys"), config.QueryTimeout, budget),
+		Sessions:       newCollection(db.Collection("sessions"), config.QueryTimeout, budget),
+		RefreshTokens:  newCollection(db.Collection("refresh_tokens"), config.QueryTimeout, budget),
+		SSOLogins:      newCollection(db.Collection("sso_logins"), config.QueryTimeout, budget),
+		Deprovisioning: newCollection(db.Collection("deprovisioning_jobs"), config.QueryTimeout, budget),
+		TenantKeys:     newCollection(db.Collection("tenant_keys"), config.QueryTimeout, budget),
+		Certificates:   newCollection(db.Collection("acme_certificates"), config.QueryTimeout, budget),
+		APIKeys:        newCollection(db.Collection("api_keys"), config.QueryTimeout, budget),
+
+		PendingOperations: newCollection(db.Collection("pending_operations"), config.QueryTimeout, budget),
+		Operations:        newCollection(db.Collection("operations"), config.QueryTimeout, budget),
+		ScheduledActions:  newCollection(db.Collection("scheduled_actions"), config.QueryTimeout, budget),
+		Workflows:         newCollection(db.Collection("workflows"), config.QueryTimeout, budget),
+
+		supportsTransactions: detectTransactionSupport(ctx, client),
+		eventSourcedUsers:    config.EventSourcedUsers,
+		topology:             topology,
 	}
 
 	// Create indexes
@@ -59,6 +191,10 @@ func NewDatabase(config Config) (*Database, error) {
 		return nil, fmt.Errorf("failed to create indexes: %v", err)
 	}
 
+	if !database.supportsTransactions {
+		log.Printf("MongoDB is not a replica set, multi-document writes will run without transactions")
+	}
+
 	log.Printf("Connected to MongoDB database: %s", config.Database)
 	return database, nil
 }
@@ -73,12 +209,58 @@ func (d *Database) createIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "is_deleted", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "org_id", Value: 1}},
+		},
+		{
+			// An external ID belongs to one user per system
+			Keys: bson.D{{Key: "external_ids.system", Value: 1}, {Key: "external_ids.id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"external_ids.system": bson.M{"$exists": true},
+			}),
+		},
+		{
+			// An identity at a provider belongs to one user
+			Keys: bson.D{{Key: "identities.issuer", Value: 1}, {Key: "identities.subject", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"identities.issuer": bson.M{"$exists": true},
+			}),
+		},
 		{
 			Keys: bson.D{{Key: "created_at", Value: -1}},
 		},
+		{
+			// Deleted accounts the purge job is due to remove
+			Keys: bson.D{{Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{
+				"deleted_at": bson.M{"$exists": true},
+			}),
+		},
+		// One per sort field of ListUsers (UserSortFields). Listings
+		// in either order walk the index forwards or backwards.
+		{
+			Keys: bson.D{{Key: "is_deleted", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "is_deleted", Value: 1}, {Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "is_deleted", Value: 1}, {Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "is_deleted", Value: 1}, {Key: "email", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "two_factor_reset.effective_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "two_factor_reset.cancel_token_hash", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
 	}
 
-	_, err := d.Users.Indexes().CreateMany(ctx, userIndexes)
+	err := d.ensureIndexes(ctx, d.Users, userIndexes)
 	if err != nil {
 		return fmt.Errorf("failed to create user indexes: %v", err)
 	}
@@ -95,7 +277,7 @@ func (d *Database) createIndexes(ctx context.Context) error {
 		},
 	}
 
-	_, err = d.Tokens.Indexes().CreateMany(ctx, tokenIndexes)
+	err = d.ensureIndexes(ctx, d.Tokens, tokenIndexes)
 	if err != nil {
 		return fmt.Errorf("failed to create token indexes: %v", err)
 	}
@@ -111,14 +293,435 @@ func (d *Database) createIndexes(ctx context.Context) error {
 		},
 	}
 
-	_, err = d.Attempts.Indexes().CreateMany(ctx, attemptIndexes)
+	err = d.ensureIndexes(ctx, d.Attempts, attemptIndexes)
 	if err != nil {
 		return fmt.Errorf("failed to create login attempt indexes: %v", err)
 	}
 
+	// Device indexes
+	deviceIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "fingerprint", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.Devices, deviceIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create device indexes: %v", err)
+	}
+
+	// Pending login indexes (with TTL for automatic cleanup)
+	pendingIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "approval_token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.Pending, pendingIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create pending login indexes: %v", err)
+	}
+
+	// Device login indexes (with TTL for automatic cleanup)
+	deviceLoginIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "device_code_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_code", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.DeviceLogins, deviceLoginIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create device login indexes: %v", err)
+	}
+
+	// Outbox indexes
+	outboxIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "locked_until", Value: 1}},
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.Outbox, outboxIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox indexes: %v", err)
+	}
+
+	// Dead letter indexes
+	deadLetterIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "dead_lettered_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "type", Value: 1}},
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.DeadLetters, deadLetterIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create dead letter indexes: %v", err)
+	}
+
+	// Email challenge indexes (with TTL for automatic cleanup)
+	challengeIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.Challenges, challengeIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create email challenge indexes: %v", err)
+	}
+
+	// Password reset indexes. Requests are kept for two hours, past both
+	// the longest token lifetime and the rate limit window.
+	passwordResetIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"token_hash": bson.M{"$exists": true},
+			}),
+		},
+		{
+			Keys: bson.D{{Key: "email", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "ip_address", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(2 * 3600), // 2 hour TTL
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.PasswordResets, passwordResetIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset indexes: %v", err)
+	}
+
+	// Passkey indexes. Credential IDs are unique across users, as the
+	// relying party must not register one credential twice.
+	passkeyIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "credential_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.Passkeys, passkeyIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create passkey indexes: %v", err)
+	}
+
+	// Session indexes. Sessions are removed once their token expires.
+	sessionIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.Sessions, sessionIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create session indexes: %v", err)
+	}
+
+	// Refresh token indexes. Tokens are removed once they expire.
+	refreshTokenIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "session_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.RefreshTokens, refreshTokenIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token indexes: %v", err)
+	}
+
+	// API key indexes. Keys with an expiry are removed once they expire.
+	apiKeyIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.APIKeys, apiKeyIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create API key indexes: %v", err)
+	}
+
+	// SSO login indexes. Logins are removed once they expire.
+	ssoLoginIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "state_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.SSOLogins, ssoLoginIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create SSO login indexes: %v", err)
+	}
+
+	// Deprovisioning job indexes, for claiming the oldest pending job
+	err = d.ensureIndexes(ctx, d.Deprovisioning, []mongo.IndexModel{{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to create deprovisioning job indexes: %v", err)
+	}
+
+	// Pending operation indexes, for claiming operations whose undo window
+	// has passed
+	err = d.ensureIndexes(ctx, d.PendingOperations, []mongo.IndexModel{{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "locked_until", Value: 1}},
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to create pending operation indexes: %v", err)
+	}
+
+	// Scheduled action indexes, for claiming due actions and notices and
+	// listing a user's actions. A user has one scheduled action of each
+	// kind at a time.
+	err = d.ensureIndexes(ctx, d.ScheduledActions, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "locked_until", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "notify_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "action", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"status": "scheduled",
+			}),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create scheduled action indexes: %v", err)
+	}
+
+	// Workflow indexes, for claiming flows to run and listing the newest.
+	// A user has one running erasure at a time.
+	err = d.ensureIndexes(ctx, d.Workflows, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "locked_until", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"kind":   "erasure",
+				"status": "running",
+			}),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create workflow indexes: %v", err)
+	}
+
+	// Operation indexes, for listing the newest operations, finding those
+	// whose server stopped, and removing them after the retention period
+	err = d.ensureIndexes(ctx, d.Operations, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "done", Value: 1}, {Key: "updated_at", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create operation indexes: %v", err)
+	}
+
+	// Organization indexes. An email domain belongs to one organization,
+	// so logins are sent to one identity provider.
+	err = d.ensureIndexes(ctx, d.Orgs, []mongo.IndexModel{{
+		Keys: bson.D{{Key: "sso.domains", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"sso.domains": bson.M{"$exists": true},
+		}),
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to create organization indexes: %v", err)
+	}
+
+	// Audit log indexes
+	auditIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "action", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}},
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.AuditLogs, auditIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log indexes: %v", err)
+	}
+
+	// User history indexes. Versions are unique per user so concurrent
+	// writers cannot both append the next event.
+	err = d.ensureIndexes(ctx, d.UserEvents, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to create user event indexes: %v", err)
+	}
+
+	err = d.ensureIndexes(ctx, d.UserSnapshots, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to create user snapshot indexes: %v", err)
+	}
+
+	// Profile change request indexes
+	changeIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "org_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			// One pending request per user
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"status": "pending",
+			}),
+		},
+	}
+
+	err = d.ensureIndexes(ctx, d.Changes, changeIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create profile change request indexes: %v", err)
+	}
+
 	return nil
 }
 
+// detectTransactionSupport reports whether the deployment is a replica set
+// or sharded cluster; standalone servers reject multi-document transactions
+func detectTransactionSupport(ctx context.Context, client *mongo.Client) bool {
+	var hello bson.M
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false
+	}
+
+	if _, ok := hello["setName"]; ok {
+		return true
+	}
+	return hello["msg"] == "isdbgrid"
+}
+
+// ReplicationStatus describes the replica set members, their regions and
+// replication lag, and the last failover
+func (d *Database) ReplicationStatus() ReplicationStatus {
+	return d.topology.status()
+}
+
+// EventSourcedUsers reports whether user changes are recorded as events
+func (d *Database) EventSourcedUsers() bool {
+	return d.eventSourcedUsers
+}
+
+// SupportsChangeStreams reports whether collections can be watched for
+// changes, which needs a replica set or sharded cluster
+func (d *Database) SupportsChangeStreams() bool {
+	return d.supportsTransactions
+}
+
+// WithTransaction runs fn in a multi-document transaction when the deployment
+// supports them, and directly otherwise. fn must use the context it is given
+// for its operations to take part in the transaction.
+func (d *Database) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !d.supportsTransactions {
+		return fn(ctx)
+	}
+
+	session, err := d.Client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %v", err)
+	}
+	defer session.EndSession(ctx)
+
+	// Transactions must read from the primary whatever the client's read
+	// preference
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	}, options.Transaction().SetReadPreference(readpref.Primary()))
+	return err
+}
+
 func (d *Database) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
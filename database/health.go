@@ -0,0 +1,194 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/metrics"
+)
+
+// HealthState is the database's state as seen by the health checks
+type HealthState int32
+
+const (
+	// HealthServing means the database is reachable and its indexes are
+	// ready
+	HealthServing HealthState = iota
+	// HealthDegraded means the database is reachable but indexes are
+	// missing or still building. RPCs are still served, but slowly and
+	// without the guarantees of unique indexes.
+	HealthDegraded
+	// HealthDown means the database is unreachable and RPCs are refused
+	HealthDown
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case HealthServing:
+		return "serving"
+	case HealthDegraded:
+		return "degraded"
+	default:
+		return "down"
+	}
+}
+
+// HealthConfig controls how often the database is checked and when it is
+// reported as down
+type HealthConfig struct {
+	Interval time.Duration
+	Timeout  time.Duration
+	// FailureThreshold consecutive failed checks mark the database down.
+	// One successful check marks it up again.
+	FailureThreshold int
+	// IndexInterval is how often indexes are checked. Missing indexes are
+	// created again. Zero disables the check.
+	IndexInterval time.Duration
+}
+
+// HealthMonitor pings the primary in the background and tracks whether
+// the database is reachable and its indexes are ready
+type HealthMonitor struct {
+	db       *Database
+	config   HealthConfig
+	onChange func(state HealthState)
+	state    atomic.Int32
+}
+
+// NewHealthMonitor returns a monitor that starts out serving, since
+// NewDatabase has just built the indexes. onChange is called whenever the
+// state changes.
+func NewHealthMonitor(db *Database, config HealthConfig, onChange func(state HealthState)) *HealthMonitor {
+	m := &HealthMonitor{db: db, config: config, onChange: onChange}
+	metrics.DatabaseUp.Set(1)
+	metrics.DatabaseIndexesReady.Set(1)
+	return m
+}
+
+// State returns the state found by the recent checks
+func (m *HealthMonitor) State() HealthState {
+	return HealthState(m.state.Load())
+}
+
+// Healthy reports whether the database is reachable
+func (m *HealthMonitor) Healthy() bool {
+	return m.State() != HealthDown
+}
+
+// Run checks the database until ctx is cancelled
+func (m *HealthMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	failures := 0
+	reachable, indexesReady := true, true
+	lastIndexCheck := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+		err := m.db.Client.Ping(pingCtx, readpref.Primary())
+		cancel()
+
+		if err == nil {
+			failures = 0
+			reachable = true
+			metrics.DatabaseUp.Set(1)
+		} else {
+			failures++
+			log.Printf("MongoDB health check failed (%d in a row): %v", failures, err)
+			if failures >= m.config.FailureThreshold {
+				reachable = false
+				metrics.DatabaseUp.Set(0)
+			}
+		}
+
+		if reachable && m.config.IndexInterval > 0 && time.Since(lastIndexCheck) >= m.config.IndexInterval {
+			lastIndexCheck = time.Now()
+			if ready, ok := m.checkIndexes(ctx); ok {
+				indexesReady = ready
+			}
+		}
+
+		state := HealthServing
+		switch {
+		case !reachable:
+			state = HealthDown
+		case !indexesReady:
+			state = HealthDegraded
+		}
+		m.setState(state)
+	}
+}
+
+// checkIndexes reports whether the indexes are ready, rebuilding missing
+// ones first. ok is false when they could not be checked.
+func (m *HealthMonitor) checkIndexes(ctx context.Context) (ready, ok bool) {
+	checkCtx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	err := m.db.CheckIndexes(checkCtx)
+	cancel()
+
+	var indexErr IndexError
+	if err != nil && !errors.As(err, &indexErr) {
+		log.Printf("MongoDB index check failed: %v", err)
+		return false, false
+	}
+
+	if err != nil {
+		metrics.DatabaseIndexesReady.Set(0)
+		log.Printf("MongoDB %v, creating them again", err)
+		// Index builds take as long as they take; only shutdown stops them
+		if err := m.db.RebuildIndexes(ctx); err != nil {
+			log.Printf("Failed to create indexes: %v", err)
+			return false, true
+		}
+		log.Printf("MongoDB indexes created")
+	}
+
+	metrics.DatabaseIndexesReady.Set(1)
+	return true, true
+}
+
+func (m *HealthMonitor) setState(state HealthState) {
+	previous := HealthState(m.state.Swap(int32(state)))
+	if previous == state {
+		return
+	}
+
+	switch state {
+	case HealthServing:
+		log.Printf("MongoDB is healthy again")
+	case HealthDegraded:
+		log.Printf("MongoDB indexes are not ready, reporting NOT_SERVING until they are")
+	case HealthDown:
+		log.Printf("MongoDB is unavailable, refusing requests until it recovers")
+	}
+	if m.onChange != nil {
+		m.onChange(state)
+	}
+}
+
+// UnaryServerInterceptor fails RPCs fast with Unavailable while the
+// database is down, so clients retry elsewhere instead of waiting for
+// driver timeouts. Health checks are always served.
+func (m *HealthMonitor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !m.Healthy() && !strings.HasPrefix(info.FullMethod, "/grpc.health.v1.Health/") {
+			return nil, status.Errorf(codes.Unavailable, "service temporarily unavailable")
+		}
+		return handler(ctx, req)
+	}
+}
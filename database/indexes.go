@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ensureIndexes creates indexes on collection unless they exist, and
+// records their names for CheckIndexes and their expiry for DeleteExpired
+func (d *Database) ensureIndexes(ctx context.Context, collection *Collection, indexes []mongo.IndexModel) error {
+	names, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return err
+	}
+
+	d.indexesMu.Lock()
+	defer d.indexesMu.Unlock()
+	if d.indexes == nil {
+		d.indexes = make(map[string][]string)
+	}
+	d.indexes[collection.Name()] = names
+	if d.expiring == nil {
+		d.expiring = make(map[string][]expiringIndex)
+	}
+	var expiring []expiringIndex
+	for _, index := range indexes {
+		if index.Options == nil || index.Options.ExpireAfterSeconds == nil {
+			continue
+		}
+		keys, ok := index.Keys.(bson.D)
+		if !ok || len(keys) != 1 {
+			continue
+		}
+		expiring = append(expiring, expiringIndex{
+			collection: collection,
+			field:      keys[0].Key,
+			after:      time.Duration(*index.Options.ExpireAfterSeconds) * time.Second,
+		})
+	}
+	d.expiring[collection.Name()] = expiring
+	return nil
+}
+
+// expiringIndex is a TTL index, which removes documents once after has
+// passed since the time in field
+type expiringIndex struct {
+	collection *Collection
+	field      string
+	after      time.Duration
+}
+
+// DeleteExpired removes the documents the TTL indexes would have removed
+// by now, such as blacklisted tokens past their expiry, and returns how
+// many. MongoDB's TTL monitor does this on its own; this is for
+// deployments where it is disabled.
+func (d *Database) DeleteExpired(ctx context.Context) (int, error) {
+	d.indexesMu.Lock()
+	var expiring []expiringIndex
+	for _, indexes := range d.expiring {
+		expiring = append(expiring, indexes...)
+	}
+	d.indexesMu.Unlock()
+
+	now := time.Now()
+	deleted := 0
+	for _, index := range expiring {
+		result, err := index.collection.DeleteMany(ctx, bson.M{
+			index.field: bson.M{"$lte": now.Add(-index.after)},
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete expired %s: %v", index.collection.Name(), err)
+		}
+		deleted += int(result.DeletedCount)
+	}
+	return deleted, nil
+}
+
+// IndexError lists indexes that are missing or still being built
+type IndexError struct {
+	// Missing are the indexes as "collection.index"
+	Missing []string
+}
+
+func (e IndexError) Error() string {
+	return fmt.Sprintf("indexes not ready: %s", strings.Join(e.Missing, ", "))
+}
+
+// CheckIndexes returns an IndexError when any index created at startup is
+// missing, such as after a restore from a backup taken before it existed,
+// or is still being built. MongoDB only lists indexes once their build has
+// finished.
+func (d *Database) CheckIndexes(ctx context.Context) error {
+	d.indexesMu.Lock()
+	expected := make(map[string][]string, len(d.indexes))
+	for collection, names := range d.indexes {
+		expected[collection] = names
+	}
+	d.indexesMu.Unlock()
+
+	var missing []string
+	for collection, names := range expected {
+		specs, err := d.DB.Collection(collection).Indexes().ListSpecifications(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list indexes of %s: %v", collection, err)
+		}
+
+		ready := make(map[string]bool, len(specs))
+		for _, spec := range specs {
+			ready[spec.Name] = true
+		}
+		for _, name := range names {
+			if !ready[name] {
+				missing = append(missing, collection+"."+name)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return IndexError{Missing: missing}
+	}
+	return nil
+}
+
+// RebuildIndexes creates any missing indexes again. It blocks until they
+// are built.
+func (d *Database) RebuildIndexes(ctx context.Context) error {
+	return d.createIndexes(ctx)
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// QueryCheck inspects an operation before it runs. filter is the
+// operation's filter, or the pipeline of an aggregation. An error fails the
+// operation without sending it.
+type QueryCheck func(ctx context.Context, collection, operation string, filter interface{}) error
+
+type queryCheckKey struct{}
+
+// WithQueryCheck makes every operation on a Collection with ctx, or a
+// context derived from it, pass check first
+func WithQueryCheck(ctx context.Context, check QueryCheck) context.Context {
+	return context.WithValue(ctx, queryCheckKey{}, check)
+}
+
+// checkQuery runs the check of ctx, if any
+func (c *Collection) checkQuery(ctx context.Context, operation string, filter interface{}) error {
+	check, ok := ctx.Value(queryCheckKey{}).(QueryCheck)
+	if !ok || check == nil {
+		return nil
+	}
+	return check(ctx, c.Name(), operation, filter)
+}
+
+// failedResult is the result of a single-document operation that was not
+// sent
+func failedResult(err error) *mongo.SingleResult {
+	return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+}
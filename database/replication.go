@@ -0,0 +1,192 @@
+package database
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/description"
+
+	"user-management/metrics"
+)
+
+// RegionTag is the replica set member tag naming the member's region
+const RegionTag = "region"
+
+// MemberStatus is the driver's view of one replica set member
+type MemberStatus struct {
+	Host   string
+	Region string
+	// State is primary, secondary, arbiter or unknown
+	State string
+	// Lag estimates how far the member's data is behind the primary
+	Lag time.Duration
+	// RoundTrip is the average round trip time from this server
+	RoundTrip time.Duration
+}
+
+// ReplicationStatus describes the replica set as this server sees it
+type ReplicationStatus struct {
+	LocalRegion   string
+	Primary       string
+	PrimaryRegion string
+	Members       []MemberStatus
+	// MajorityWrites is set when writes wait for a majority of members,
+	// so a failover does not lose acknowledged writes
+	MajorityWrites bool
+	// LastFailoverAt and LastFailoverDuration describe the last time the
+	// replica set was without a primary, zero if it has not happened
+	// since the server started
+	LastFailoverAt       time.Time
+	LastFailoverDuration time.Duration
+	// NoPrimarySince is set while there is no primary
+	NoPrimarySince time.Time
+}
+
+// MaxLag is the largest lag of a member in another region than the primary,
+// the data at risk if the primary's region is lost and writes are not
+// acknowledged by a majority
+func (s ReplicationStatus) MaxLag() time.Duration {
+	var lag time.Duration
+	for _, member := range s.Members {
+		if member.State == "secondary" && member.Region != s.PrimaryRegion && member.Lag > lag {
+			lag = member.Lag
+		}
+	}
+	return lag
+}
+
+// topologyTracker follows the replica set through driver monitoring events
+// to log failovers and report replication status
+type topologyTracker struct {
+	localRegion    string
+	majorityWrites bool
+
+	mu                   sync.Mutex
+	servers              []description.Server
+	primary              string
+	noPrimarySince       time.Time
+	lastFailoverAt       time.Time
+	lastFailoverDuration time.Duration
+}
+
+func newTopologyTracker(localRegion string, majorityWrites bool) *topologyTracker {
+	return &topologyTracker{
+		localRegion:    localRegion,
+		majorityWrites: majorityWrites,
+	}
+}
+
+// monitor logs and counts changes of replica set primary, and times how
+// long the replica set goes without one
+func (t *topologyTracker) monitor() *event.ServerMonitor {
+	return &event.ServerMonitor{
+		TopologyDescriptionChanged: func(e *event.TopologyDescriptionChangedEvent) {
+			current := ""
+			for _, server := range e.NewDescription.Servers {
+				if server.Kind == description.RSPrimary {
+					current = server.Addr.String()
+				}
+			}
+			now := time.Now()
+
+			t.mu.Lock()
+			previous := t.primary
+			t.primary = current
+			t.servers = e.NewDescription.Servers
+			if current == "" && previous != "" {
+				t.noPrimarySince = now
+			}
+			var outage time.Duration
+			if current != "" && !t.noPrimarySince.IsZero() {
+				outage = now.Sub(t.noPrimarySince)
+				t.lastFailoverAt = t.noPrimarySince
+				t.lastFailoverDuration = outage
+				t.noPrimarySince = time.Time{}
+			}
+			t.mu.Unlock()
+
+			switch {
+			case current == previous:
+			case current == "":
+				log.Printf("MongoDB primary %s is unavailable", previous)
+			case previous == "" && outage > 0:
+				log.Printf("MongoDB primary is %s after %s without one", current, outage.Round(time.Millisecond))
+				metrics.DatabasePrimaryChanges.Inc()
+			case previous == "":
+				log.Printf("MongoDB primary is %s", current)
+			default:
+				log.Printf("MongoDB primary changed from %s to %s", previous, current)
+				metrics.DatabasePrimaryChanges.Inc()
+			}
+		},
+	}
+}
+
+// status returns the replication status from the latest topology. Lag
+// follows the staleness estimate of the server selection spec.
+func (t *topologyTracker) status() ReplicationStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := ReplicationStatus{
+		LocalRegion:          t.localRegion,
+		Primary:              t.primary,
+		MajorityWrites:       t.majorityWrites,
+		LastFailoverAt:       t.lastFailoverAt,
+		LastFailoverDuration: t.lastFailoverDuration,
+		NoPrimarySince:       t.noPrimarySince,
+	}
+
+	var primary *description.Server
+	for i := range t.servers {
+		if t.servers[i].Kind == description.RSPrimary {
+			primary = &t.servers[i]
+		}
+	}
+
+	for _, server := range t.servers {
+		member := MemberStatus{
+			Host:      server.Addr.String(),
+			Region:    regionOf(server),
+			State:     memberState(server.Kind),
+			RoundTrip: server.AverageRTT,
+		}
+		if server.Kind == description.RSSecondary && primary != nil && !server.LastWriteTime.IsZero() {
+			lag := server.LastUpdateTime.Sub(server.LastWriteTime) - primary.LastUpdateTime.Sub(primary.LastWriteTime)
+			member.Lag = max(lag, 0)
+		}
+		if server.Kind == description.RSPrimary {
+			status.PrimaryRegion = member.Region
+		}
+		status.Members = append(status.Members, member)
+	}
+
+	sort.Slice(status.Members, func(i, j int) bool {
+		return status.Members[i].Host < status.Members[j].Host
+	})
+	return status
+}
+
+func regionOf(server description.Server) string {
+	for _, tag := range server.Tags {
+		if tag.Name == RegionTag {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+func memberState(kind description.ServerKind) string {
+	switch kind {
+	case description.RSPrimary:
+		return "primary"
+	case description.RSSecondary:
+		return "secondary"
+	case description.RSArbiter:
+		return "arbiter"
+	}
+	return "unknown"
+}
@@ -0,0 +1,377 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"user-management/models"
+)
+
+// ErrNotFound is returned by repositories when nothing matches. It is the
+// driver's error, so callers comparing with mongo.ErrNoDocuments keep
+// working.
+var ErrNotFound = mongo.ErrNoDocuments
+
+// ErrDuplicate is returned by in-memory repositories for writes that would
+// break a unique index. Check with IsDuplicate, which also recognizes
+// MongoDB's errors.
+var ErrDuplicate = errors.New("duplicate key")
+
+// IsDuplicate reports whether err is a write rejected by a unique index
+func IsDuplicate(err error) bool {
+	return errors.Is(err, ErrDuplicate) || mongo.IsDuplicateKeyError(err)
+}
+
+// ErrLastLoginMethod is returned by UserRepository.UnlinkIdentity for the
+// only way left to sign in to an account
+var ErrLastLoginMethod = errors.New("last login method")
+
+// UserRepository reads and writes user accounts. Reads skip deleted
+// accounts and writes apply to them too, unless a method says otherwise.
+// Writes to an account that doesn't exist, or no longer is as the write
+// expects, return ErrNotFound.
+type UserRepository interface {
+	FindByID(ctx context.Context, id models.ID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// FindPrincipal loads what a user's principal is made from, their
+	// organization, roles and status, whether they are deleted or not,
+	// and when their tokens were last revoked
+	FindPrincipal(ctx context.Context, id models.ID) (*models.User, error)
+	// FindIncludingDeleted finds a user whether they are deleted or not
+	FindIncludingDeleted(ctx context.Context, id models.ID) (*models.User, error)
+	// FindMember finds a user of the organization, deleted or not
+	FindMember(ctx context.Context, id models.ID, orgID primitive.ObjectID) (*models.User, error)
+	// FindDeletedByEmail finds the account with email deleted after
+	// deletedAfter, which can still be restored
+	FindDeletedByEmail(ctx context.Context, email string, deletedAfter time.Time) (*models.User, error)
+	// FindByIDs returns the users among ids, in no particular order
+	FindByIDs(ctx context.Context, ids []models.ID) ([]models.User, error)
+	FindByExternalID(ctx context.Context, system, externalID string) (*models.User, error)
+	// FindByTwoFactorReset finds the user whose pending two-factor reset
+	// has the cancel token hash
+	FindByTwoFactorReset(ctx context.Context, cancelTokenHash string) (*models.User, error)
+	// ListDueTwoFactorResets returns up to limit users, deleted or not,
+	// whose two-factor reset takes effect at or before at
+	ListDueTwoFactorResets(ctx context.Context, at time.Time, limit int) ([]models.User, error)
+	// ListPurgeable returns the IDs of up to limit accounts deleted at or
+	// before deletedBefore
+	ListPurgeable(ctx context.Context, deletedBefore time.Time, limit int) ([]models.ID, error)
+	// ListOrgAdmins returns the active admins of an organization
+	ListOrgAdmins(ctx context.Context, orgID primitive.ObjectID) ([]models.User, error)
+	// EachToDeprovision calls fn with every active member of the job's
+	// organization one of whose identities the job matches, stopping at
+	// the first error
+	EachToDeprovision(ctx context.Context, job *models.DeprovisioningJob, fn func(user models.User) error) error
+	// Search returns a page of the users matching search and how many
+	// match in all, as of cluster time at. A zero at reads the latest
+	// data, as servers without snapshot reads must.
+	Search(ctx context.Context, search UserSearch, at primitive.Timestamp, skip, limit int64) ([]models.User, int64, error)
+	// EachMatching calls fn with every user matching search in ID order,
+	// starting after the ID after unless it is zero, and stopping at the
+	// first error
+	EachMatching(ctx context.Context, search UserSearch, after models.ID, fn func(user models.User) error) error
+	// EmailTaken reports whether an account other than exceptID, deleted or
+	// not, has email. A zero exceptID excludes no account.
+	EmailTaken(ctx context.Context, email string, exceptID models.ID) (bool, error)
+	// Conflicts reports whether an account, deleted or not, has email or
+	// one of the external IDs
+	Conflicts(ctx context.Context, email string, externalIDs []models.ExternalID) (bool, error)
+
+	// Insert fails with a duplicate error when the email, an external ID
+	// or an identity is taken
+	Insert(ctx context.Context, user models.User) error
+	// Delete removes the user's document
+	Delete(ctx context.Context, id models.ID) error
+	// Purge removes the account if it is still deleted at or before
+	// deletedBefore
+	Purge(ctx context.Context, id models.ID, deletedBefore time.Time) error
+	// SoftDelete deletes the account unless it is deleted already, and
+	// revokes its tokens. It can be restored until it is purged.
+	SoftDelete(ctx context.Context, id models.ID, at time.Time) error
+	// Restore restores the account deleted at deletedAt
+	Restore(ctx context.Context, id models.ID, deletedAt, at time.Time) error
+	// Deactivate stops the user from signing in and revokes their tokens,
+	// unless they are deleted
+	Deactivate(ctx context.Context, id models.ID, at time.Time) error
+	// DeactivateActive is Deactivate for users still active, and returns
+	// ErrNotFound for the others
+	DeactivateActive(ctx context.Context, id models.ID, at time.Time) error
+	// Reactivate lets a user who isn't deleted sign in again
+	Reactivate(ctx context.Context, id models.ID, at time.Time) error
+
+	// TokensRevoked reports whether the user's tokens issued at or before
+	// issuedAt have been revoked
+	TokensRevoked(ctx context.Context, id models.ID, issuedAt time.Time) (bool, error)
+	// RevokeTokens revokes the tokens issued at or before at of a user
+	// who isn't deleted
+	RevokeTokens(ctx context.Context, id models.ID, at time.Time) error
+	// RevokedSince returns when the users among ids whose tokens were
+	// revoked at or after since, deleted or not, revoked them
+	RevokedSince(ctx context.Context, ids []models.ID, since time.Time) (map[models.ID]time.Time, error)
+	// FlagRefreshTokenReuse records that a consumed refresh token of the
+	// user was presented again
+	FlagRefreshTokenReuse(ctx context.Context, id models.ID, at time.Time) error
+
+	// UpdateProfile applies change to the profile of a user who isn't
+	// deleted
+	UpdateProfile(ctx context.Context, id models.ID, change ProfileChange) error
+	// UpdateMemberProfile is UpdateProfile for a member of the organization
+	UpdateMemberProfile(ctx context.Context, id models.ID, orgID primitive.ObjectID, change ProfileChange) error
+	// SetOrgMembership gives the user role in the organization, with their
+	// name stored for it, unless they are deleted or belong to another
+	// one. An empty role removes them from the organization.
+	SetOrgMembership(ctx context.Context, id models.ID, orgID primitive.ObjectID, role, name string, at time.Time) error
+	// SetEmailVerified marks the user's email verified if it is still email
+	SetEmailVerified(ctx context.Context, id models.ID, email string, at time.Time) error
+	// SetRoles replaces the user's roles and which of them are mapped from
+	// their organization's groups
+	SetRoles(ctx context.Context, id models.ID, roles, mappedRoles []string, at time.Time) error
+	// AcceptTerms records the version of the terms the user accepted
+	AcceptTerms(ctx context.Context, id models.ID, version string, at time.Time) error
+	// SetExternalID sets the ID in system of a user who isn't deleted and
+	// returns the updated user. An empty externalID removes it.
+	SetExternalID(ctx context.Context, id models.ID, system, externalID string, at time.Time) (*models.User, error)
+
+	// Lock places lock on the account unless it is deleted, replacing any
+	// other
+	Lock(ctx context.Context, id models.ID, lock models.AccountLock, at time.Time) error
+	// Unlock lifts any lock on the account unless it is deleted, and
+	// clears its failed logins
+	Unlock(ctx context.Context, id models.ID, at time.Time) error
+	// UnlockProtective is Unlock for accounts with a protective lock,
+	// deleted or not, and returns ErrNotFound for the others
+	UnlockProtective(ctx context.Context, id models.ID, at time.Time) error
+	// RecordFailedLogin counts a failed login of the user and returns the count since the last success or lock
+	RecordFailedLogin(ctx context.Context, id models.ID) (int, error)
+	// LockProtectively places a protective lock and clears the failed
+	// logins, unless an administrator locked the account
+	LockProtectively(ctx context.Context, id models.ID, lock models.AccountLock) error
+	// ClearFailedLogins clears the failed logins and lock, unless an
+	// administrator locked the account
+	ClearFailedLogins(ctx context.Context, id models.ID) error
+
+	// ResetPassword replaces the password, revokes the user's tokens and
+	// clears their failed logins
+	ResetPassword(ctx context.Context, id models.ID, hash string, at time.Time) error
+	// ChangePassword is ResetPassword for users whose password hash is
+	// still oldHash, keeping their failed logins
+	ChangePassword(ctx context.Context, id models.ID, oldHash, hash string, at time.Time) error
+	// RehashPassword replaces the password hash with one of the same
+	// password if it is still oldHash
+	RehashPassword(ctx context.Context, id models.ID, oldHash, hash string) error
+	// SetFirstPassword sets a password for users who have none
+	SetFirstPassword(ctx context.Context, id models.ID, hash string, at time.Time) error
+	// ClearPassword removes the password of a user who isn't deleted,
+	// revokes their tokens and returns the updated user
+	ClearPassword(ctx context.Context, id models.ID, at time.Time) (*models.User, error)
+	// RecoverAccount consumes the recovery code, and replaces the password
+	// while removing the second factor, lock and reuse flag of the user.
+	// Their tokens are revoked.
+	RecoverAccount(ctx context.Context, id models.ID, recoveryCodeHash, hash string, at time.Time) error
+
+	// SetRecoveryCodes replaces the user's unused recovery codes
+	SetRecoveryCodes(ctx context.Context, id models.ID, hashes []string, at time.Time) error
+	// UseRecoveryCode consumes one of the user's recovery codes
+	UseRecoveryCode(ctx context.Context, id models.ID, hash string, at time.Time) error
+	// StartTwoFactor replaces the factor being set up
+	StartTwoFactor(ctx context.Context, id models.ID, pending models.TwoFactor, at time.Time) error
+	// EnableTwoFactor enables factor if the factor being set up still has
+	// its secret, and clears the enrollment deadline
+	EnableTwoFactor(ctx context.Context, id models.ID, factor models.TwoFactor, at time.Time) error
+	// DisableTwoFactor removes the second factor if it still has secret
+	DisableTwoFactor(ctx context.Context, id models.ID, secret string, at time.Time) error
+	// ReplaceTwoFactorSecret replaces the secret of the second factor if
+	// it is still oldSecret
+	ReplaceTwoFactorSecret(ctx context.Context, id models.ID, oldSecret, secret string) error
+	// SetTwoFactorDeadline sets when the user must have set up 2FA by,
+	// unless it is set already, and returns the deadline they have
+	SetTwoFactorDeadline(ctx context.Context, id models.ID, deadline time.Time) (*time.Time, error)
+	// StartTwoFactorReset starts reset for users with a second factor and
+	// no reset pending. A non-empty recoveryCodeHash is consumed with it.
+	StartTwoFactorReset(ctx context.Context, id models.ID, reset models.TwoFactorReset, recoveryCodeHash string, at time.Time) error
+	// CancelTwoFactorReset cancels the pending reset with the cancel token
+	// hash and revokes the user's tokens
+	CancelTwoFactorReset(ctx context.Context, id models.ID, cancelTokenHash string, at time.Time) error
+	// CompleteTwoFactorReset removes the user's second factor if the reset
+	// with the cancel token hash is pending
+	CompleteTwoFactorReset(ctx context.Context, id models.ID, cancelTokenHash string, at time.Time) error
+
+	// StartPasskeyRegistration replaces the passkey registration in
+	// progress
+	StartPasskeyRegistration(ctx context.Context, id models.ID, registration models.PasskeyRegistration, at time.Time) error
+	// ConsumePasskeyRegistration ends the passkey registration with the
+	// WebAuthn session, so it is used once
+	ConsumePasskeyRegistration(ctx context.Context, id models.ID, session []byte) error
+	// StartPasskeyLogin replaces the passkey login in progress
+	StartPasskeyLogin(ctx context.Context, id models.ID, login models.PasskeyLogin) error
+	// ConsumePasskeyLogin ends the passkey login with the WebAuthn
+	// session, so it is used once
+	ConsumePasskeyLogin(ctx context.Context, id models.ID, session []byte) error
+
+	// RecordIdentityLogin finds the user with the identity, unless they are
+	// deleted, and records that it signed in at
+	RecordIdentityLogin(ctx context.Context, issuer, subject string, at time.Time) (*models.User, error)
+	// LinkIdentity adds identity to the user's account. It fails with a
+	// duplicate error when the identity belongs to another account.
+	LinkIdentity(ctx context.Context, id models.ID, identity models.LinkedIdentity, at time.Time) error
+	// LinkMemberIdentity is LinkIdentity for the member of the
+	// organization with email who isn't deleted, and returns the updated
+	// user
+	LinkMemberIdentity(ctx context.Context, email string, orgID primitive.ObjectID, identity models.LinkedIdentity, at time.Time) (*models.User, error)
+	// UnlinkIdentity removes an identity from the user's account. It
+	// returns ErrLastLoginMethod when the user would be left without a
+	// password or identity to sign in with.
+	UnlinkIdentity(ctx context.Context, id models.ID, issuer, subject string, at time.Time) error
+}
+
+// ProfileChange is a change to a user's profile. Empty fields are kept.
+type ProfileChange struct {
+	// Name is stored as it is, encrypted for members of organizations
+	Name string
+	// Email is unverified once changed
+	Email     string
+	UpdatedAt time.Time
+}
+
+// UserSearch narrows a user listing to accounts that aren't deleted. Zero
+// values match every user.
+type UserSearch struct {
+	// NameFilter matches anywhere in the user's name and EmailFilter as a
+	// prefix of the address, both literally and case-insensitively
+	NameFilter  string
+	EmailFilter string
+	// IsActive matches only active, or only inactive, users when set
+	IsActive *bool
+	// CreatedAfter and CreatedBefore bound the users' creation time,
+	// excluding both ends
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// SortBy is one of UserSortFields, created_at by default. SortOrder is
+	// asc or desc, desc by default. _id breaks ties, so users with the
+	// same value keep their order between pages.
+	SortBy    string
+	SortOrder string
+}
+
+// Sort orders of user listings
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// UserSortFields are the fields user listings can be sorted by. Each has
+// an index with is_deleted and _id, so listings are never sorted in
+// memory.
+var UserSortFields = []string{"created_at", "updated_at", "name", "email"}
+
+// TokenRepository stores the blacklist of tokens invalidated before they
+// expire
+type TokenRepository interface {
+	Insert(ctx context.Context, token models.InvalidatedToken) error
+	IsInvalidated(ctx context.Context, token string) (bool, error)
+	// EachUnexpired calls fn with every token that has not expired yet,
+	// stopping at the first error
+	EachUnexpired(ctx context.Context, fn func(token models.InvalidatedToken) error) error
+	// Count estimates the number of tokens, expired ones included until
+	// they are removed
+	Count(ctx context.Context) (int64, error)
+}
+
+// AttemptRepository records login attempts for rate limiting
+type AttemptRepository interface {
+	Insert(ctx context.Context, attempt models.LoginAttempt) error
+	// CountFailures counts the failed attempts for email from ipAddress
+	// since the given time
+	CountFailures(ctx context.Context, email, ipAddress string, since time.Time) (int64, error)
+	// NthLatestFailure returns the time of the nth most recent failed
+	// attempt since the given time, counting from 1, or the zero time when
+	// there are fewer
+	NthLatestFailure(ctx context.Context, email, ipAddress string, since time.Time, n int) (time.Time, error)
+	// DeleteUnused deletes the attempts rate limiting never reads again,
+	// the successful ones and the failures before the given time, and
+	// returns how many there were
+	DeleteUnused(ctx context.Context, before time.Time) (int64, error)
+}
+
+// APIKeyRepository stores the hashed API keys of machine clients
+type APIKeyRepository interface {
+	// Insert fails with a duplicate error when the hash is taken
+	Insert(ctx context.Context, key models.APIKey) error
+	FindByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	// ListByUser returns the user's keys, oldest first
+	ListByUser(ctx context.Context, userID models.ID) ([]models.APIKey, error)
+	// RecordUse moves the key's last use to at, unless it is later already
+	RecordUse(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	// Delete deletes one of the user's keys, or returns ErrNotFound when
+	// the user has no key id
+	Delete(ctx context.Context, userID models.ID, id primitive.ObjectID) error
+	// DeleteByUser deletes all of the user's keys
+	DeleteByUser(ctx context.Context, userID models.ID) error
+}
+
+// SessionRepository stores the sessions of session tokens. Revoked
+// sessions are kept until they expire, so their tokens stay rejected.
+type SessionRepository interface {
+	Insert(ctx context.Context, session models.Session) error
+	FindByID(ctx context.Context, id string) (*models.Session, error)
+	// RecordActivity moves the session's last activity to at, unless it is
+	// later already. A session that isn't stored, of a token issued before
+	// sessions were tracked, is stored as session.
+	RecordActivity(ctx context.Context, session models.Session, at time.Time) error
+	// Extend moves the session's last activity to at and its expiry to
+	// expiresAt, unless they are later already
+	Extend(ctx context.Context, id string, at, expiresAt time.Time) error
+	// ListActive returns the user's sessions that aren't revoked and
+	// expire after now, most recently used first. Unless they are zero,
+	// the sessions must also be issued after issuedAfter and used at or
+	// after activeSince.
+	ListActive(ctx context.Context, userID models.ID, now, issuedAfter, activeSince time.Time) ([]models.Session, error)
+	// Revoke revokes one of the user's sessions, or returns ErrNotFound
+	// when the user has no such session or it is revoked already
+	Revoke(ctx context.Context, userID models.ID, id string, at time.Time) error
+	// RevokeAll revokes the user's unexpired sessions and returns how many
+	// there were
+	RevokeAll(ctx context.Context, userID models.ID, at time.Time) (int, error)
+	// Revoked returns the IDs among ids of revoked sessions
+	Revoked(ctx context.Context, ids []string) ([]string, error)
+}
+
+// RefreshTokenRepository stores the refresh tokens of sessions by hash
+type RefreshTokenRepository interface {
+	Insert(ctx context.Context, token models.RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	// Consume records that the token was exchanged at, or returns
+	// ErrNotFound when it was exchanged or revoked already
+	Consume(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	// RevokeSession revokes the unexpired refresh tokens of one of the
+	// user's sessions
+	RevokeSession(ctx context.Context, userID models.ID, sessionID string, at time.Time) error
+	// RevokeUser revokes all the user's unexpired refresh tokens
+	RevokeUser(ctx context.Context, userID models.ID, at time.Time) error
+}
+
+// Repositories are the repositories of one database
+type Repositories struct {
+	Users         UserRepository
+	Tokens        TokenRepository
+	Attempts      AttemptRepository
+	APIKeys       APIKeyRepository
+	Sessions      SessionRepository
+	RefreshTokens RefreshTokenRepository
+}
+
+// Repositories returns repositories backed by the database's collections
+func (d *Database) Repositories() Repositories {
+	return Repositories{
+		Users:         mongoUsers{d.Users},
+		Tokens:        mongoTokens{d.Tokens},
+		Attempts:      mongoAttempts{d.Attempts},
+		APIKeys:       mongoAPIKeys{d.APIKeys},
+		Sessions:      mongoSessions{d.Sessions},
+		RefreshTokens: mongoRefreshTokens{d.RefreshTokens},
+	}
+}
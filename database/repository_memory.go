@@ -0,0 +1,1294 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"user-management/models"
+)
+
+// NewMemoryRepositories returns empty repositories held in memory, for
+// tests of code that would otherwise need a MongoDB server. They keep the
+// behavior of the unique indexes, but not of transactions or TTL indexes:
+// expired tokens and attempts stay until the repositories are dropped.
+func NewMemoryRepositories() Repositories {
+	return Repositories{
+		Users:    &memoryUsers{users: map[models.ID]models.User{}},
+		Tokens:   &memoryTokens{tokens: map[string]models.InvalidatedToken{}},
+		Attempts: &memoryAttempts{},
+		APIKeys:  &memoryAPIKeys{keys: map[primitive.ObjectID]models.APIKey{}},
+		Sessions: &memorySessions{sessions: map[string]models.Session{}},
+		RefreshTokens: &memoryRefreshTokens{
+			tokens: map[primitive.ObjectID]models.RefreshToken{},
+		},
+	}
+}
+
+type memoryUsers struct {
+	mu    sync.Mutex
+	users map[models.ID]models.User
+}
+
+// Matchers of the users writes apply to
+func anyUser(user *models.User) bool    { return true }
+func notDeleted(user *models.User) bool { return !user.IsDeleted }
+
+// find returns a copy of the user with id if match accepts them
+func (r *memoryUsers) find(id models.ID, match func(user *models.User) bool) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || !match(&user) {
+		return nil, ErrNotFound
+	}
+	user = cloneUser(user)
+	return &user, nil
+}
+
+// findFirst returns a copy of the first user match accepts, in ID order
+func (r *memoryUsers) findFirst(match func(user *models.User) bool) (*models.User, error) {
+	users := r.filter(match)
+	if len(users) == 0 {
+		return nil, ErrNotFound
+	}
+	return &users[0], nil
+}
+
+// filter returns copies of the users match accepts, in ID order
+func (r *memoryUsers) filter(match func(user *models.User) bool) []models.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []models.User
+	for _, user := range r.users {
+		if match(&user) {
+			users = append(users, cloneUser(user))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return idLess(users[i].ID, users[j].ID) })
+	return users
+}
+
+// update applies change to the user with id if match accepts them, and
+// returns a copy of the updated user. The change is dropped with
+// ErrDuplicate when it would break a unique index.
+func (r *memoryUsers) update(id models.ID, match func(user *models.User) bool, change func(user *models.User)) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || !match(&user) {
+		return nil, ErrNotFound
+	}
+	user = cloneUser(user)
+	change(&user)
+	if r.conflicts(user) {
+		return nil, ErrDuplicate
+	}
+	r.users[id] = user
+	user = cloneUser(user)
+	return &user, nil
+}
+
+// conflicts reports whether another user has the email, an external ID or
+// an identity of user
+func (r *memoryUsers) conflicts(user models.User) bool {
+	for id, other := range r.users {
+		if id == user.ID {
+			continue
+		}
+		if other.Email == user.Email {
+			return true
+		}
+		for _, externalID := range user.ExternalIDs {
+			if hasExternalID(&other, externalID.System, externalID.ID) {
+				return true
+			}
+		}
+		for _, identity := range user.Identities {
+			if hasIdentity(&other, identity.Issuer, identity.Subject) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cloneUser copies user, so the slices of stored users aren't shared with
+// callers
+func cloneUser(user models.User) models.User {
+	user.Roles = append([]string(nil), user.Roles...)
+	user.MappedRoles = append([]string(nil), user.MappedRoles...)
+	user.RecoveryCodeHashes = append([]string(nil), user.RecoveryCodeHashes...)
+	user.Identities = append([]models.LinkedIdentity(nil), user.Identities...)
+	user.ExternalIDs = append([]models.ExternalID(nil), user.ExternalIDs...)
+	return user
+}
+
+// idLess orders IDs as MongoDB does, UUIDs stored as strings before
+// ObjectIDs
+func idLess(a, b models.ID) bool {
+	_, aErr := primitive.ObjectIDFromHex(a.String())
+	_, bErr := primitive.ObjectIDFromHex(b.String())
+	if (aErr == nil) != (bErr == nil) {
+		return aErr != nil
+	}
+	return a < b
+}
+
+func hasExternalID(user *models.User, system, externalID string) bool {
+	for _, stored := range user.ExternalIDs {
+		if stored.System == system && stored.ID == externalID {
+			return true
+		}
+	}
+	return false
+}
+
+func hasIdentity(user *models.User, issuer, subject string) bool {
+	for _, identity := range user.Identities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRecoveryCode(user *models.User, hash string) bool {
+	for _, stored := range user.RecoveryCodeHashes {
+		if stored == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// removeRecoveryCode removes a recovery code from the user's unused ones
+func removeRecoveryCode(user *models.User, hash string) {
+	kept := user.RecoveryCodeHashes[:0]
+	for _, stored := range user.RecoveryCodeHashes {
+		if stored != hash {
+			kept = append(kept, stored)
+		}
+	}
+	user.RecoveryCodeHashes = kept
+}
+
+func inOrg(user *models.User, orgID primitive.ObjectID) bool {
+	return user.OrgID != nil && *user.OrgID == orgID
+}
+
+func (r *memoryUsers) FindByID(ctx context.Context, id models.ID) (*models.User, error) {
+	return r.find(id, notDeleted)
+}
+
+func (r *memoryUsers) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.findFirst(func(user *models.User) bool {
+		return user.Email == email && !user.IsDeleted
+	})
+}
+
+func (r *memoryUsers) FindPrincipal(ctx context.Context, id models.ID) (*models.User, error) {
+	user, err := r.find(id, anyUser)
+	if err != nil {
+		return nil, err
+	}
+	return &models.User{
+		ID:               user.ID,
+		OrgID:            user.OrgID,
+		OrgRole:          user.OrgRole,
+		Roles:            user.Roles,
+		IsActive:         user.IsActive,
+		IsDeleted:        user.IsDeleted,
+		TokensValidAfter: user.TokensValidAfter,
+	}, nil
+}
+
+func (r *memoryUsers) FindIncludingDeleted(ctx context.Context, id models.ID) (*models.User, error) {
+	return r.find(id, anyUser)
+}
+
+func (r *memoryUsers) FindMember(ctx context.Context, id models.ID, orgID primitive.ObjectID) (*models.User, error) {
+	return r.find(id, func(user *models.User) bool { return inOrg(user, orgID) })
+}
+
+func (r *memoryUsers) FindDeletedByEmail(ctx context.Context, email string, deletedAfter time.Time) (*models.User, error) {
+	return r.findFirst(func(user *models.User) bool {
+		return user.Email == email && user.IsDeleted &&
+			user.DeletedAt != nil && user.DeletedAt.After(deletedAfter)
+	})
+}
+
+func (r *memoryUsers) FindByIDs(ctx context.Context, ids []models.ID) ([]models.User, error) {
+	wanted := make(map[models.ID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return r.filter(func(user *models.User) bool {
+		return wanted[user.ID] && !user.IsDeleted
+	}), nil
+}
+
+func (r *memoryUsers) FindByExternalID(ctx context.Context, system, externalID string) (*models.User, error) {
+	return r.findFirst(func(user *models.User) bool {
+		return hasExternalID(user, system, externalID) && !user.IsDeleted
+	})
+}
+
+func (r *memoryUsers) FindByTwoFactorReset(ctx context.Context, cancelTokenHash string) (*models.User, error) {
+	return r.findFirst(func(user *models.User) bool {
+		return user.TwoFactorReset != nil && user.TwoFactorReset.CancelTokenHash == cancelTokenHash &&
+			!user.IsDeleted
+	})
+}
+
+func (r *memoryUsers) ListDueTwoFactorResets(ctx context.Context, at time.Time, limit int) ([]models.User, error) {
+	users := r.filter(func(user *models.User) bool {
+		return user.TwoFactorReset != nil && !user.TwoFactorReset.EffectiveAt.After(at)
+	})
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r *memoryUsers) ListPurgeable(ctx context.Context, deletedBefore time.Time, limit int) ([]models.ID, error) {
+	users := r.filter(func(user *models.User) bool { return purgeable(user, deletedBefore) })
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	ids := make([]models.ID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids, nil
+}
+
+// purgeable is purgeableFilter for one user
+func purgeable(user *models.User, deletedBefore time.Time) bool {
+	return user.IsDeleted && user.DeletedAt != nil && !user.DeletedAt.After(deletedBefore)
+}
+
+func (r *memoryUsers) ListOrgAdmins(ctx context.Context, orgID primitive.ObjectID) ([]models.User, error) {
+	return r.filter(func(user *models.User) bool {
+		return inOrg(user, orgID) && user.OrgRole == models.OrgRoleAdmin &&
+			user.IsActive && !user.IsDeleted
+	}), nil
+}
+
+func (r *memoryUsers) EachToDeprovision(ctx context.Context, job *models.DeprovisioningJob, fn func(user models.User) error) error {
+	users := r.filter(func(user *models.User) bool {
+		if !inOrg(user, job.OrgID) || !user.IsActive || user.IsDeleted {
+			return false
+		}
+		for _, identity := range user.Identities {
+			if deprovisions(job, identity) {
+				return true
+			}
+		}
+		return false
+	})
+	for _, user := range users {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deprovisions is deprovisioningFilter for one identity
+func deprovisions(job *models.DeprovisioningJob, identity models.LinkedIdentity) bool {
+	if identity.Provider != models.IdentityProviderSSO || identity.OrgID != job.OrgID || identity.Issuer != job.Issuer {
+		return false
+	}
+	switch job.Reason {
+	case models.DeprovisionReasonDeleted:
+		for _, subject := range job.Subjects {
+			if identity.Subject == subject {
+				return true
+			}
+		}
+		return false
+	case models.DeprovisionReasonInactive:
+		if identity.LastLoginAt != nil {
+			return identity.LastLoginAt.Before(*job.InactiveSince)
+		}
+		return identity.LinkedAt.Before(*job.InactiveSince)
+	}
+	return true
+}
+
+// Search ignores at, the repositories have no history to read from
+func (r *memoryUsers) Search(ctx context.Context, search UserSearch, at primitive.Timestamp, skip, limit int64) ([]models.User, int64, error) {
+	users := r.filter(func(user *models.User) bool { return matchesSearch(user, search) })
+	sort.SliceStable(users, func(i, j int) bool {
+		if search.SortOrder == SortOrderAsc {
+			return searchLess(search.SortBy, &users[i], &users[j])
+		}
+		return searchLess(search.SortBy, &users[j], &users[i])
+	})
+
+	total := int64(len(users))
+	if skip > total {
+		skip = total
+	}
+	users = users[skip:]
+	if limit > 0 && int64(len(users)) > limit {
+		users = users[:limit]
+	}
+	return users, total, nil
+}
+
+func (r *memoryUsers) EachMatching(ctx context.Context, search UserSearch, after models.ID, fn func(user models.User) error) error {
+	users := r.filter(func(user *models.User) bool {
+		return matchesSearch(user, search) && (after.IsZero() || idLess(after, user.ID))
+	})
+	for _, user := range users {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchesSearch is searchFilter for one user
+func matchesSearch(user *models.User, search UserSearch) bool {
+	if user.IsDeleted {
+		return false
+	}
+	if search.NameFilter != "" && !strings.Contains(strings.ToLower(user.Name), strings.ToLower(search.NameFilter)) {
+		return false
+	}
+	if search.EmailFilter != "" && !strings.HasPrefix(strings.ToLower(user.Email), strings.ToLower(search.EmailFilter)) {
+		return false
+	}
+	if search.IsActive != nil && user.IsActive != *search.IsActive {
+		return false
+	}
+	if !search.CreatedAfter.IsZero() && !user.CreatedAt.After(search.CreatedAfter) {
+		return false
+	}
+	if !search.CreatedBefore.IsZero() && !user.CreatedAt.Before(search.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// searchLess is searchSort ascending, for two users
+func searchLess(field string, a, b *models.User) bool {
+	switch field {
+	case "updated_at":
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		}
+	case "name":
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+	case "email":
+		if a.Email != b.Email {
+			return a.Email < b.Email
+		}
+	default:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+	}
+	return idLess(a.ID, b.ID)
+}
+
+func (r *memoryUsers) EmailTaken(ctx context.Context, email string, exceptID models.ID) (bool, error) {
+	users := r.filter(func(user *models.User) bool {
+		return user.Email == email && user.ID != exceptID
+	})
+	return len(users) > 0, nil
+}
+
+func (r *memoryUsers) Conflicts(ctx context.Context, email string, externalIDs []models.ExternalID) (bool, error) {
+	users := r.filter(func(user *models.User) bool {
+		if user.Email == email {
+			return true
+		}
+		for _, externalID := range externalIDs {
+			if hasExternalID(user, externalID.System, externalID.ID) {
+				return true
+			}
+		}
+		return false
+	})
+	return len(users) > 0, nil
+}
+
+func (r *memoryUsers) Insert(ctx context.Context, user models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[user.ID]; ok || r.conflicts(user) {
+		return ErrDuplicate
+	}
+	r.users[user.ID] = cloneUser(user)
+	return nil
+}
+
+func (r *memoryUsers) Delete(ctx context.Context, id models.ID) error {
+	return r.delete(id, anyUser)
+}
+
+func (r *memoryUsers) Purge(ctx context.Context, id models.ID, deletedBefore time.Time) error {
+	return r.delete(id, func(user *models.User) bool { return purgeable(user, deletedBefore) })
+}
+
+// delete removes the user with id if match accepts them
+func (r *memoryUsers) delete(id models.ID, match func(user *models.User) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || !match(&user) {
+		return ErrNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+// write is update for writes that don't return the user
+func (r *memoryUsers) write(id models.ID, match func(user *models.User) bool, change func(user *models.User)) error {
+	_, err := r.update(id, match, change)
+	return err
+}
+
+func (r *memoryUsers) SoftDelete(ctx context.Context, id models.ID, at time.Time) error {
+	return r.write(id, notDeleted, func(user *models.User) {
+		user.IsDeleted = true
+		user.IsActive = false
+		user.DeletedAt = &at
+		user.TokensValidAfter = &at
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) Restore(ctx context.Context, id models.ID, deletedAt, at time.Time) error {
+	return r.write(id, func(user *models.User) bool {
+		return user.IsDeleted && user.DeletedAt != nil && user.DeletedAt.Equal(deletedAt)
+	}, func(user *models.User) {
+		user.IsDeleted = false
+		user.IsActive = true
+		user.DeletedAt = nil
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) Deactivate(ctx context.Context, id models.ID, at time.Time) error {
+	return r.write(id, notDeleted, deactivate(at))
+}
+
+func (r *memoryUsers) DeactivateActive(ctx context.Context, id models.ID, at time.Time) error {
+	return r.write(id, func(user *models.User) bool { return user.IsActive }, deactivate(at))
+}
+
+// deactivate is deactivation for one user
+func deactivate(at time.Time) func(user *models.User) {
+	return func(user *models.User) {
+		user.IsActive = false
+		user.TokensValidAfter = &at
+		user.UpdatedAt = at
+	}
+}
+
+func (r *memoryUsers) Reactivate(ctx context.Context, id models.ID, at time.Time) error {
+	return r.write(id, notDeleted, func(user *models.User) {
+		user.IsActive = true
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) TokensRevoked(ctx context.Context, id models.ID, issuedAt time.Time) (bool, error) {
+	user, err := r.find(id, anyUser)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return user.TokensValidAfter != nil && !user.TokensValidAfter.Before(issuedAt), nil
+}
+
+func (r *memoryUsers) RevokeTokens(ctx context.Context, id models.ID, at time.Time) error {
+	return r.write(id, notDeleted, func(user *models.User) {
+		user.TokensValidAfter = &at
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) RevokedSince(ctx context.Context, ids []models.ID, since time.Time) (map[models.ID]time.Time, error) {
+	wanted := make(map[models.ID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	users := r.filter(func(user *models.User) bool {
+		return wanted[user.ID] && user.TokensValidAfter != nil && !user.TokensValidAfter.Before(since)
+	})
+	revoked := make(map[models.ID]time.Time, len(users))
+	for _, user := range users {
+		revoked[user.ID] = *user.TokensValidAfter
+	}
+	return revoked, nil
+}
+
+func (r *memoryUsers) FlagRefreshTokenReuse(ctx context.Context, id models.ID, at time.Time) error {
+	return r.write(id, anyUser, func(user *models.User) {
+		user.RefreshTokenReusedAt = &at
+	})
+}
+
+func (r *memoryUsers) UpdateProfile(ctx context.Context, id models.ID, change ProfileChange) error {
+	return r.write(id, notDeleted, changeProfile(change))
+}
+
+func (r *memoryUsers) UpdateMemberProfile(ctx context.Context, id models.ID, orgID primitive.ObjectID, change ProfileChange) error {
+	return r.write(id, func(user *models.User) bool {
+		return inOrg(user, orgID) && !user.IsDeleted
+	}, changeProfile(change))
+}
+
+// changeProfile is profileFields for one user
+func changeProfile(change ProfileChange) func(user *models.User) {
+	return func(user *models.User) {
+		user.UpdatedAt = change.UpdatedAt
+		if change.Name != "" {
+			user.Name = change.Name
+		}
+		if change.Email != "" {
+			user.Email = change.Email
+			user.EmailVerified = false
+		}
+	}
+}
+
+func (r *memoryUsers) SetOrgMembership(ctx context.Context, id models.ID, orgID primitive.ObjectID, role, name string, at time.Time) error {
+	return r.write(id, func(user *models.User) bool {
+		return !user.IsDeleted && (user.OrgID == nil || *user.OrgID == orgID)
+	}, func(user *models.User) {
+		if role == "" {
+			user.OrgID = nil
+		} else {
+			user.OrgID = &orgID
+		}
+		user.OrgRole = role
+		user.Name = name
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) SetEmailVerified(ctx context.Context, id models.ID, email string, at time.Time) error {
+	return r.write(id, func(user *models.User) bool { return user.Email == email }, func(user *models.User) {
+		user.EmailVerified = true
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) SetRoles(ctx context.Context, id models.ID, roles, mappedRoles []string, at time.Time) error {
+	return r.write(id, anyUser, func(user *models.User) {
+		user.Roles = append([]string(nil), roles...)
+		user.MappedRoles = append([]string(nil), mappedRoles...)
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) AcceptTerms(ctx context.Context, id models.ID, version string, at time.Time) error {
+	return r.write(id, anyUser, func(user *models.User) {
+		user.AcceptedTermsVersion = version
+		user.TermsAcceptedAt = &at
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) SetExternalID(ctx context.Context, id models.ID, system, externalID string, at time.Time) (*models.User, error) {
+	return r.update(id, notDeleted, func(user *models.User) {
+		user.UpdatedAt = at
+		for i, stored := range user.ExternalIDs {
+			if stored.System != system {
+				continue
+			}
+			if externalID == "" {
+				user.ExternalIDs = append(user.ExternalIDs[:i], user.ExternalIDs[i+1:]...)
+			} else {
+				user.ExternalIDs[i].ID = externalID
+			}
+			return
+		}
+		if externalID != "" {
+			user.ExternalIDs = append(user.ExternalIDs, models.ExternalID{System: system, ID: externalID})
+		}
+	})
+}
+
+func (r *memoryUsers) Lock(ctx context.Context, id models.ID, lock models.AccountLock, at time.Time) error {
+	return r.write(id, notDeleted, func(user *models.User) {
+		user.Lock = &lock
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) Unlock(ctx context.Context, id models.ID, at time.Time) error {
+	return r.write(id, notDeleted, unlock(at))
+}
+
+func (r *memoryUsers) UnlockProtective(ctx context.Context, id models.ID, at time.Time) error {
+	return r.write(id, func(user *models.User) bool {
+		return user.Lock != nil && user.Lock.Kind == models.LockKindProtective
+	}, unlock(at))
+}
+
+// unlock is unlocking for one user
+func unlock(at time.Time) func(user *models.User) {
+	return func(user *models.User) {
+		user.Lock = nil
+		user.FailedLoginCount = 0
+		user.UpdatedAt = at
+	}
+}
+
+func (r *memoryUsers) RecordFailedLogin(ctx context.Context, id models.ID) (int, error) {
+	user, err := r.update(id, anyUser, func(user *models.User) {
+		user.FailedLoginCount++
+	})
+	if err != nil {
+		return 0, err
+	}
+	return user.FailedLoginCount, nil
+}
+
+// notAdminLocked matches the users an administrator hasn't locked
+func notAdminLocked(user *models.User) bool {
+	return user.Lock == nil || user.Lock.Kind != models.LockKindAdmin
+}
+
+func (r *memoryUsers) LockProtectively(ctx context.Context, id models.ID, lock models.AccountLock) error {
+	return r.write(id, notAdminLocked, func(user *models.User) {
+		user.Lock = &lock
+		user.FailedLoginCount = 0
+	})
+}
+
+func (r *memoryUsers) ClearFailedLogins(ctx context.Context, id models.ID) error {
+	return r.write(id, notAdminLocked, func(user *models.User) {
+		user.Lock = nil
+		user.FailedLoginCount = 0
+	})
+}
+
+func (r *memoryUsers) ResetPassword(ctx context.Context, id models.ID, hash string, at time.Time) error {
+	return r.write(id, anyUser, func(user *models.User) {
+		setPassword(user, hash, at)
+		user.FailedLoginCount = 0
+	})
+}
+
+func (r *memoryUsers) ChangePassword(ctx context.Context, id models.ID, oldHash, hash string, at time.Time) error {
+	return r.write(id, func(user *models.User) bool { return user.Password == oldHash }, func(user *models.User) {
+		setPassword(user, hash, at)
+	})
+}
+
+// setPassword is passwordFields for one user
+func setPassword(user *models.User, hash string, at time.Time) {
+	user.Password = hash
+	user.PasswordChangedAt = &at
+	user.TokensValidAfter = &at
+	user.UpdatedAt = at
+}
+
+func (r *memoryUsers) RehashPassword(ctx context.Context, id models.ID, oldHash, hash string) error {
+	return r.write(id, func(user *models.User) bool { return user.Password == oldHash }, func(user *models.User) {
+		user.Password = hash
+	})
+}
+
+func (r *memoryUsers) SetFirstPassword(ctx context.Context, id models.ID, hash string, at time.Time) error {
+	return r.write(id, func(user *models.User) bool { return user.Password == "" }, func(user *models.User) {
+		user.Password = hash
+		user.PasswordChangedAt = &at
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) ClearPassword(ctx context.Context, id models.ID, at time.Time) (*models.User, error) {
+	return r.update(id, notDeleted, func(user *models.User) {
+		user.Password = ""
+		user.TokensValidAfter = &at
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) RecoverAccount(ctx context.Context, id models.ID, recoveryCodeHash, hash string, at time.Time) error {
+	return r.write(id, func(user *models.User) bool {
+		return hasRecoveryCode(user, recoveryCodeHash)
+	}, func(user *models.User) {
+		setPassword(user, hash, at)
+		user.TwoFactor = nil
+		user.Lock = nil
+		user.FailedLoginCount = 0
+		user.RefreshTokenReusedAt = nil
+		removeRecoveryCode(user, recoveryCodeHash)
+	})
+}
+
+func (r *memoryUsers) SetRecoveryCodes(ctx context.Context, id models.ID, hashes []string, at time.Time) error {
+	return r.write(id, anyUser, func(user *models.User) {
+		user.RecoveryCodeHashes = append([]string(nil), hashes...)
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) UseRecoveryCode(ctx context.Context, id models.ID, hash string, at time.Time) error {
+	return r.write(id, func(user *models.User) bool { return hasRecoveryCode(user, hash) }, func(user *models.User) {
+		removeRecoveryCode(user, hash)
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) StartTwoFactor(ctx context.Context, id models.ID, pending models.TwoFactor, at time.Time) error {
+	return r.write(id, anyUser, func(user *models.User) {
+		user.PendingTwoFactor = &pending
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) EnableTwoFactor(ctx context.Context, id models.ID, factor models.TwoFactor, at time.Time) error {
+	return r.write(id, func(user *models.User) bool {
+		return user.PendingTwoFactor != nil && user.PendingTwoFactor.Secret == factor.Secret
+	}, func(user *models.User) {
+		user.TwoFactor = &factor
+		user.PendingTwoFactor = nil
+		user.TwoFactorDeadline = nil
+		user.UpdatedAt = at
+	})
+}
+
+// twoFactorSecret matches the users whose second factor has secret
+func twoFactorSecret(secret string) func(user *models.User) bool {
+	return func(user *models.User) bool {
+		return user.TwoFactor != nil && user.TwoFactor.Secret == secret
+	}
+}
+
+func (r *memoryUsers) DisableTwoFactor(ctx context.Context, id models.ID, secret string, at time.Time) error {
+	return r.write(id, twoFactorSecret(secret), func(user *models.User) {
+		user.TwoFactor = nil
+		user.PendingTwoFactor = nil
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) ReplaceTwoFactorSecret(ctx context.Context, id models.ID, oldSecret, secret string) error {
+	return r.write(id, twoFactorSecret(oldSecret), func(user *models.User) {
+		factor := *user.TwoFactor
+		factor.Secret = secret
+		user.TwoFactor = &factor
+	})
+}
+
+func (r *memoryUsers) SetTwoFactorDeadline(ctx context.Context, id models.ID, deadline time.Time) (*time.Time, error) {
+	user, err := r.update(id, anyUser, func(user *models.User) {
+		if user.TwoFactorDeadline == nil {
+			user.TwoFactorDeadline = &deadline
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user.TwoFactorDeadline, nil
+}
+
+func (r *memoryUsers) StartTwoFactorReset(ctx context.Context, id models.ID, reset models.TwoFactorReset, recoveryCodeHash string, at time.Time) error {
+	return r.write(id, func(user *models.User) bool {
+		return user.TwoFactor != nil && user.TwoFactorReset == nil &&
+			(recoveryCodeHash == "" || hasRecoveryCode(user, recoveryCodeHash))
+	}, func(user *models.User) {
+		user.TwoFactorReset = &reset
+		user.UpdatedAt = at
+		if recoveryCodeHash != "" {
+			removeRecoveryCode(user, recoveryCodeHash)
+		}
+	})
+}
+
+// pendingTwoFactorReset matches the users whose pending two-factor reset
+// has the cancel token hash
+func pendingTwoFactorReset(cancelTokenHash string) func(user *models.User) bool {
+	return func(user *models.User) bool {
+		return user.TwoFactorReset != nil && user.TwoFactorReset.CancelTokenHash == cancelTokenHash
+	}
+}
+
+func (r *memoryUsers) CancelTwoFactorReset(ctx context.Context, id models.ID, cancelTokenHash string, at time.Time) error {
+	return r.write(id, pendingTwoFactorReset(cancelTokenHash), func(user *models.User) {
+		user.TwoFactorReset = nil
+		user.TokensValidAfter = &at
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) CompleteTwoFactorReset(ctx context.Context, id models.ID, cancelTokenHash string, at time.Time) error {
+	return r.write(id, pendingTwoFactorReset(cancelTokenHash), func(user *models.User) {
+		user.TwoFactor = nil
+		user.TwoFactorReset = nil
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) StartPasskeyRegistration(ctx context.Context, id models.ID, registration models.PasskeyRegistration, at time.Time) error {
+	return r.write(id, anyUser, func(user *models.User) {
+		user.PendingPasskey = &registration
+		user.UpdatedAt = at
+	})
+}
+
+func (r *memoryUsers) ConsumePasskeyRegistration(ctx context.Context, id models.ID, session []byte) error {
+	return r.write(id, func(user *models.User) bool {
+		return user.PendingPasskey != nil && bytes.Equal(user.PendingPasskey.Session, session)
+	}, func(user *models.User) {
+		user.PendingPasskey = nil
+	})
+}
+
+func (r *memoryUsers) StartPasskeyLogin(ctx context.Context, id models.ID, login models.PasskeyLogin) error {
+	return r.write(id, anyUser, func(user *models.User) {
+		user.PasskeyLogin = &login
+	})
+}
+
+func (r *memoryUsers) ConsumePasskeyLogin(ctx context.Context, id models.ID, session []byte) error {
+	return r.write(id, func(user *models.User) bool {
+		return user.PasskeyLogin != nil && bytes.Equal(user.PasskeyLogin.Session, session)
+	}, func(user *models.User) {
+		user.PasskeyLogin = nil
+	})
+}
+
+func (r *memoryUsers) RecordIdentityLogin(ctx context.Context, issuer, subject string, at time.Time) (*models.User, error) {
+	match := func(user *models.User) bool {
+		return hasIdentity(user, issuer, subject) && !user.IsDeleted
+	}
+	user, err := r.findFirst(match)
+	if err != nil {
+		return nil, err
+	}
+	return r.update(user.ID, match, func(user *models.User) {
+		for i, identity := range user.Identities {
+			if identity.Issuer == issuer && identity.Subject == subject {
+				user.Identities[i].LastLoginAt = &at
+			}
+		}
+	})
+}
+
+func (r *memoryUsers) LinkIdentity(ctx context.Context, id models.ID, identity models.LinkedIdentity, at time.Time) error {
+	return r.write(id, anyUser, linkIdentity(identity, at))
+}
+
+func (r *memoryUsers) LinkMemberIdentity(ctx context.Context, email string, orgID primitive.ObjectID, identity models.LinkedIdentity, at time.Time) (*models.User, error) {
+	match := func(user *models.User) bool {
+		return user.Email == email && inOrg(user, orgID) && !user.IsDeleted
+	}
+	user, err := r.findFirst(match)
+	if err != nil {
+		return nil, err
+	}
+	return r.update(user.ID, match, linkIdentity(identity, at))
+}
+
+func linkIdentity(identity models.LinkedIdentity, at time.Time) func(user *models.User) {
+	return func(user *models.User) {
+		user.Identities = append(user.Identities, identity)
+		user.UpdatedAt = at
+	}
+}
+
+func (r *memoryUsers) UnlinkIdentity(ctx context.Context, id models.ID, issuer, subject string, at time.Time) error {
+	_, err := r.update(id, func(user *models.User) bool {
+		return user.Password != "" || len(user.Identities) > 1
+	}, func(user *models.User) {
+		kept := user.Identities[:0]
+		for _, identity := range user.Identities {
+			if identity.Issuer != issuer || identity.Subject != subject {
+				kept = append(kept, identity)
+			}
+		}
+		user.Identities = kept
+		user.UpdatedAt = at
+	})
+	if err == ErrNotFound {
+		return ErrLastLoginMethod
+	}
+	return err
+}
+
+type memoryTokens struct {
+	mu     sync.Mutex
+	tokens map[string]models.InvalidatedToken
+}
+
+func (r *memoryTokens) Insert(ctx context.Context, token models.InvalidatedToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tokens[token.Token]; ok {
+		return ErrDuplicate
+	}
+	r.tokens[token.Token] = token
+	return nil
+}
+
+func (r *memoryTokens) IsInvalidated(ctx context.Context, token string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.tokens[token]
+	return ok, nil
+}
+
+func (r *memoryTokens) EachUnexpired(ctx context.Context, fn func(token models.InvalidatedToken) error) error {
+	r.mu.Lock()
+	var unexpired []models.InvalidatedToken
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.ExpiresAt.After(now) {
+			unexpired = append(unexpired, token)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, token := range unexpired {
+		if err := fn(token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryTokens) Count(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.tokens)), nil
+}
+
+type memoryAttempts struct {
+	mu       sync.Mutex
+	attempts []models.LoginAttempt
+}
+
+func (r *memoryAttempts) Insert(ctx context.Context, attempt models.LoginAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+// failures returns the matching failed attempts, newest first
+func (r *memoryAttempts) failures(email, ipAddress string, since time.Time) []time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var times []time.Time
+	for _, attempt := range r.attempts {
+		if attempt.Email == email && attempt.IPAddress == ipAddress && !attempt.Success && !attempt.Timestamp.Before(since) {
+			times = append(times, attempt.Timestamp)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].After(times[j]) })
+	return times
+}
+
+func (r *memoryAttempts) CountFailures(ctx context.Context, email, ipAddress string, since time.Time) (int64, error) {
+	return int64(len(r.failures(email, ipAddress, since))), nil
+}
+
+func (r *memoryAttempts) NthLatestFailure(ctx context.Context, email, ipAddress string, since time.Time, n int) (time.Time, error) {
+	times := r.failures(email, ipAddress, since)
+	if n < 1 || n > len(times) {
+		return time.Time{}, nil
+	}
+	return times[n-1], nil
+}
+
+func (r *memoryAttempts) DeleteUnused(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.attempts[:0]
+	for _, attempt := range r.attempts {
+		if !attempt.Success && !attempt.Timestamp.Before(before) {
+			kept = append(kept, attempt)
+		}
+	}
+	deleted := int64(len(r.attempts) - len(kept))
+	r.attempts = kept
+	return deleted, nil
+}
+
+type memoryAPIKeys struct {
+	mu   sync.Mutex
+	keys map[primitive.ObjectID]models.APIKey
+}
+
+func (r *memoryAPIKeys) Insert(ctx context.Context, key models.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stored := range r.keys {
+		if stored.KeyHash == key.KeyHash {
+			return ErrDuplicate
+		}
+	}
+	r.keys[key.ID] = key
+	return nil
+}
+
+func (r *memoryAPIKeys) FindByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.keys {
+		if key.KeyHash == hash {
+			return &key, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryAPIKeys) ListByUser(ctx context.Context, userID models.ID) ([]models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var keys []models.APIKey
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID.Hex() < keys[j].ID.Hex()
+	})
+	return keys, nil
+}
+
+func (r *memoryAPIKeys) RecordUse(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, ok := r.keys[id]
+	if !ok || (key.LastUsedAt != nil && !at.After(*key.LastUsedAt)) {
+		return nil
+	}
+	key.LastUsedAt = &at
+	r.keys[id] = key
+	return nil
+}
+
+func (r *memoryAPIKeys) Delete(ctx context.Context, userID models.ID, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, ok := r.keys[id]
+	if !ok || key.UserID != userID {
+		return ErrNotFound
+	}
+	delete(r.keys, id)
+	return nil
+}
+
+func (r *memoryAPIKeys) DeleteByUser(ctx context.Context, userID models.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, key := range r.keys {
+		if key.UserID == userID {
+			delete(r.keys, id)
+		}
+	}
+	return nil
+}
+
+type memorySessions struct {
+	mu       sync.Mutex
+	sessions map[string]models.Session
+}
+
+func (r *memorySessions) Insert(ctx context.Context, session models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sessions[session.ID]; ok {
+		return ErrDuplicate
+	}
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *memorySessions) FindByID(ctx context.Context, id string) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+func (r *memorySessions) RecordActivity(ctx context.Context, session models.Session, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.sessions[session.ID]
+	if !ok {
+		stored = models.Session{
+			ID:        session.ID,
+			UserID:    session.UserID,
+			IssuedAt:  session.IssuedAt,
+			ExpiresAt: session.ExpiresAt,
+		}
+	}
+	if at.After(stored.LastActivityAt) {
+		stored.LastActivityAt = at
+	}
+	r.sessions[session.ID] = stored
+	return nil
+}
+
+func (r *memorySessions) Extend(ctx context.Context, id string, at, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil
+	}
+	if at.After(session.LastActivityAt) {
+		session.LastActivityAt = at
+	}
+	if expiresAt.After(session.ExpiresAt) {
+		session.ExpiresAt = expiresAt
+	}
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *memorySessions) ListActive(ctx context.Context, userID models.ID, now, issuedAfter, activeSince time.Time) ([]models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := []models.Session{}
+	for _, session := range r.sessions {
+		if session.UserID != userID || session.RevokedAt != nil || !session.ExpiresAt.After(now) {
+			continue
+		}
+		if !issuedAfter.IsZero() && !session.IssuedAt.After(issuedAfter) {
+			continue
+		}
+		if !activeSince.IsZero() && session.LastActivityAt.Before(activeSince) {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastActivityAt.After(sessions[j].LastActivityAt)
+	})
+	return sessions, nil
+}
+
+func (r *memorySessions) Revoke(ctx context.Context, userID models.ID, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return ErrNotFound
+	}
+	session.RevokedAt = &at
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *memorySessions) RevokeAll(ctx context.Context, userID models.ID, at time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	revoked := 0
+	for id, session := range r.sessions {
+		if session.UserID != userID || !session.ExpiresAt.After(at) || session.RevokedAt != nil {
+			continue
+		}
+		session.RevokedAt = &at
+		r.sessions[id] = session
+		revoked++
+	}
+	return revoked, nil
+}
+
+func (r *memorySessions) Revoked(ctx context.Context, ids []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var revoked []string
+	for _, id := range ids {
+		if session, ok := r.sessions[id]; ok && session.RevokedAt != nil {
+			revoked = append(revoked, id)
+		}
+	}
+	return revoked, nil
+}
+
+type memoryRefreshTokens struct {
+	mu     sync.Mutex
+	tokens map[primitive.ObjectID]models.RefreshToken
+}
+
+func (r *memoryRefreshTokens) Insert(ctx context.Context, token models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	for _, stored := range r.tokens {
+		if stored.TokenHash == token.TokenHash {
+			return ErrDuplicate
+		}
+	}
+	r.tokens[token.ID] = token
+	return nil
+}
+
+func (r *memoryRefreshTokens) FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryRefreshTokens) Consume(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[id]
+	if !ok || token.ConsumedAt != nil || token.RevokedAt != nil {
+		return ErrNotFound
+	}
+	token.ConsumedAt = &at
+	r.tokens[id] = token
+	return nil
+}
+
+func (r *memoryRefreshTokens) RevokeSession(ctx context.Context, userID models.ID, sessionID string, at time.Time) error {
+	r.revoke(func(token models.RefreshToken) bool {
+		return token.UserID == userID && token.SessionID == sessionID
+	}, at)
+	return nil
+}
+
+func (r *memoryRefreshTokens) RevokeUser(ctx context.Context, userID models.ID, at time.Time) error {
+	r.revoke(func(token models.RefreshToken) bool { return token.UserID == userID }, at)
+	return nil
+}
+
+// revoke revokes the unexpired refresh tokens match accepts
+func (r *memoryRefreshTokens) revoke(match func(token models.RefreshToken) bool, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, token := range r.tokens {
+		if !match(token) || !token.ExpiresAt.After(at) || token.RevokedAt != nil {
+			continue
+		}
+		token.RevokedAt = &at
+		r.tokens[id] = token
+	}
+}
@@ -0,0 +1,1289 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"user-management/models"
+)
+
+type mongoUsers struct {
+	users *Collection
+}
+
+func (r mongoUsers) FindByID(ctx context.Context, id models.ID) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "is_deleted": false})
+}
+
+func (r mongoUsers) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"email": email, "is_deleted": false})
+}
+
+func (r mongoUsers) FindPrincipal(ctx context.Context, id models.ID) (*models.User, error) {
+	var user models.User
+	err := r.users.FindOne(ctx, bson.M{"_id": id}, options.FindOne().SetProjection(bson.M{
+		"org_id":             1,
+		"org_role":           1,
+		"roles":              1,
+		"is_active":          1,
+		"is_deleted":         1,
+		"tokens_valid_after": 1,
+	})).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r mongoUsers) findOne(ctx context.Context, filter bson.M) (*models.User, error) {
+	var user models.User
+	if err := r.users.FindOne(ctx, filter).Decode(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r mongoUsers) EmailTaken(ctx context.Context, email string, exceptID models.ID) (bool, error) {
+	filter := bson.M{"email": email}
+	if !exceptID.IsZero() {
+		filter["_id"] = bson.M{"$ne": exceptID}
+	}
+	err := r.users.FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r mongoUsers) FindIncludingDeleted(ctx context.Context, id models.ID) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r mongoUsers) FindMember(ctx context.Context, id models.ID, orgID primitive.ObjectID) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "org_id": orgID})
+}
+
+func (r mongoUsers) FindDeletedByEmail(ctx context.Context, email string, deletedAfter time.Time) (*models.User, error) {
+	return r.findOne(ctx, bson.M{
+		"email":      email,
+		"is_deleted": true,
+		"deleted_at": bson.M{"$gt": deletedAfter},
+	})
+}
+
+func (r mongoUsers) FindByIDs(ctx context.Context, ids []models.ID) ([]models.User, error) {
+	return r.find(ctx, bson.M{
+		"_id":        bson.M{"$in": ids},
+		"is_deleted": false,
+	})
+}
+
+func (r mongoUsers) FindByExternalID(ctx context.Context, system, externalID string) (*models.User, error) {
+	return r.findOne(ctx, bson.M{
+		"external_ids": bson.M{"$elemMatch": bson.M{
+			"system": system,
+			"id":     externalID,
+		}},
+		"is_deleted": false,
+	})
+}
+
+func (r mongoUsers) FindByTwoFactorReset(ctx context.Context, cancelTokenHash string) (*models.User, error) {
+	return r.findOne(ctx, bson.M{
+		"two_factor_reset.cancel_token_hash": cancelTokenHash,
+		"is_deleted":                         false,
+	})
+}
+
+func (r mongoUsers) ListDueTwoFactorResets(ctx context.Context, at time.Time, limit int) ([]models.User, error) {
+	return r.find(ctx, bson.M{
+		"two_factor_reset.effective_at": bson.M{"$lte": at},
+	}, options.Find().SetLimit(int64(limit)))
+}
+
+func (r mongoUsers) ListPurgeable(ctx context.Context, deletedBefore time.Time, limit int) ([]models.ID, error) {
+	users, err := r.find(ctx, purgeableFilter(deletedBefore), options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]models.ID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids, nil
+}
+
+// purgeableFilter matches the accounts whose deletion grace period ended
+// by deletedBefore
+func purgeableFilter(deletedBefore time.Time) bson.M {
+	return bson.M{
+		"is_deleted": true,
+		"deleted_at": bson.M{"$lte": deletedBefore},
+	}
+}
+
+func (r mongoUsers) ListOrgAdmins(ctx context.Context, orgID primitive.ObjectID) ([]models.User, error) {
+	return r.find(ctx, bson.M{
+		"org_id":     orgID,
+		"org_role":   models.OrgRoleAdmin,
+		"is_active":  true,
+		"is_deleted": false,
+	})
+}
+
+func (r mongoUsers) EachToDeprovision(ctx context.Context, job *models.DeprovisioningJob, fn func(user models.User) error) error {
+	return r.each(ctx, deprovisioningFilter(job), nil, fn)
+}
+
+// deprovisioningFilter matches the active accounts of the job's
+// organization with an identity the job deprovisions
+func deprovisioningFilter(job *models.DeprovisioningJob) bson.M {
+	identity := bson.M{
+		"provider": models.IdentityProviderSSO,
+		"org_id":   job.OrgID,
+		"issuer":   job.Issuer,
+	}
+	switch job.Reason {
+	case models.DeprovisionReasonDeleted:
+		identity["subject"] = bson.M{"$in": job.Subjects}
+	case models.DeprovisionReasonInactive:
+		// Identities that never signed in since being linked count from
+		// the link
+		identity["$or"] = bson.A{
+			bson.M{"last_login_at": bson.M{"$lt": *job.InactiveSince}},
+			bson.M{
+				"last_login_at": bson.M{"$exists": false},
+				"linked_at":     bson.M{"$lt": *job.InactiveSince},
+			},
+		}
+	}
+
+	return bson.M{
+		"org_id":     job.OrgID,
+		"is_active":  true,
+		"is_deleted": false,
+		"identities": bson.M{"$elemMatch": identity},
+	}
+}
+
+func (r mongoUsers) Search(ctx context.Context, search UserSearch, at primitive.Timestamp, skip, limit int64) ([]models.User, int64, error) {
+	filter := searchFilter(search)
+	sort := searchSort(search)
+
+	var users []models.User
+	if !at.IsZero() {
+		total, err := r.users.CountAt(ctx, at, filter)
+		if err == nil {
+			err = r.users.FindAt(ctx, at, filter, sort, skip, limit, &users)
+		}
+		return users, total, err
+	}
+
+	total, err := r.users.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	users, err = r.find(ctx, filter, options.Find().
+		SetSkip(skip).
+		SetLimit(limit).
+		SetSort(sort))
+	return users, total, err
+}
+
+// exportBatchSize is how many users EachMatching reads from the cursor at
+// a time
+const exportBatchSize = 500
+
+func (r mongoUsers) EachMatching(ctx context.Context, search UserSearch, after models.ID, fn func(user models.User) error) error {
+	filter := searchFilter(search)
+	if !after.IsZero() {
+		// UUIDs are stored as strings, which sort before every ObjectID, and
+		// $gt only compares IDs of the same type
+		if _, err := primitive.ObjectIDFromHex(after.String()); err == nil {
+			filter["_id"] = bson.M{"$gt": after}
+		} else {
+			filter["$or"] = bson.A{
+				bson.M{"_id": bson.M{"$gt": after}},
+				bson.M{"_id": bson.M{"$type": "objectId"}},
+			}
+		}
+	}
+
+	return r.each(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetBatchSize(exportBatchSize), fn)
+}
+
+// searchFilter builds the filter of a user search. The text filters are
+// escaped, so they match literally.
+func searchFilter(search UserSearch) bson.M {
+	filter := bson.M{"is_deleted": false}
+	if search.NameFilter != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(search.NameFilter), "$options": "i"}
+	}
+	if search.EmailFilter != "" {
+		filter["email"] = bson.M{"$regex": "^" + regexp.QuoteMeta(search.EmailFilter), "$options": "i"}
+	}
+	if search.IsActive != nil {
+		filter["is_active"] = *search.IsActive
+	}
+
+	created := bson.M{}
+	if !search.CreatedAfter.IsZero() {
+		created["$gt"] = search.CreatedAfter
+	}
+	if !search.CreatedBefore.IsZero() {
+		created["$lt"] = search.CreatedBefore
+	}
+	if len(created) > 0 {
+		filter["created_at"] = created
+	}
+	return filter
+}
+
+// searchSort builds the sort order of a user search
+func searchSort(search UserSearch) bson.D {
+	field := search.SortBy
+	if field == "" {
+		field = "created_at"
+	}
+	direction := -1
+	if search.SortOrder == SortOrderAsc {
+		direction = 1
+	}
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}
+}
+
+func (r mongoUsers) Conflicts(ctx context.Context, email string, externalIDs []models.ExternalID) (bool, error) {
+	conflicts := bson.A{bson.M{"email": email}}
+	for _, externalID := range externalIDs {
+		conflicts = append(conflicts, bson.M{"external_ids": bson.M{"$elemMatch": bson.M{
+			"system": externalID.System,
+			"id":     externalID.ID,
+		}}})
+	}
+
+	count, err := r.users.CountDocuments(ctx, bson.M{"$or": conflicts})
+	return count > 0, err
+}
+
+func (r mongoUsers) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]models.User, error) {
+	cursor, err := r.users.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// each calls fn with every user filter matches, reading them with one
+// cursor
+func (r mongoUsers) each(ctx context.Context, filter bson.M, opts *options.FindOptions, fn func(user models.User) error) error {
+	cursor, err := r.users.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (r mongoUsers) Insert(ctx context.Context, user models.User) error {
+	_, err := r.users.InsertOne(ctx, user)
+	return err
+}
+
+func (r mongoUsers) Delete(ctx context.Context, id models.ID) error {
+	return r.deleteOne(ctx, bson.M{"_id": id})
+}
+
+func (r mongoUsers) Purge(ctx context.Context, id models.ID, deletedBefore time.Time) error {
+	filter := purgeableFilter(deletedBefore)
+	filter["_id"] = id
+	return r.deleteOne(ctx, filter)
+}
+
+func (r mongoUsers) deleteOne(ctx context.Context, filter bson.M) error {
+	result, err := r.users.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r mongoUsers) SoftDelete(ctx context.Context, id models.ID, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":        id,
+		"is_deleted": false,
+	}, bson.M{
+		"$set": bson.M{
+			"is_deleted":         true,
+			"is_active":          false,
+			"deleted_at":         at,
+			"tokens_valid_after": at,
+			"updated_at":         at,
+		},
+	})
+}
+
+func (r mongoUsers) Restore(ctx context.Context, id models.ID, deletedAt, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":        id,
+		"is_deleted": true,
+		"deleted_at": deletedAt,
+	}, bson.M{
+		"$set": bson.M{
+			"is_deleted": false,
+			"is_active":  true,
+			"updated_at": at,
+		},
+		"$unset": bson.M{"deleted_at": ""},
+	})
+}
+
+func (r mongoUsers) Deactivate(ctx context.Context, id models.ID, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":        id,
+		"is_deleted": false,
+	}, deactivation(at))
+}
+
+func (r mongoUsers) DeactivateActive(ctx context.Context, id models.ID, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":       id,
+		"is_active": true,
+	}, deactivation(at))
+}
+
+// deactivation stops a user from signing in and revokes their tokens
+func deactivation(at time.Time) bson.M {
+	return bson.M{
+		"$set": bson.M{
+			"is_active":          false,
+			"tokens_valid_after": at,
+			"updated_at":         at,
+		},
+	}
+}
+
+func (r mongoUsers) Reactivate(ctx context.Context, id models.ID, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":        id,
+		"is_deleted": false,
+	}, bson.M{
+		"$set": bson.M{
+			"is_active":  true,
+			"updated_at": at,
+		},
+	})
+}
+
+func (r mongoUsers) TokensRevoked(ctx context.Context, id models.ID, issuedAt time.Time) (bool, error) {
+	err := r.users.FindOne(ctx, bson.M{
+		"_id":                id,
+		"tokens_valid_after": bson.M{"$gte": issuedAt},
+	}, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r mongoUsers) RevokeTokens(ctx context.Context, id models.ID, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":        id,
+		"is_deleted": false,
+	}, bson.M{
+		"$set": bson.M{
+			"tokens_valid_after": at,
+			"updated_at":         at,
+		},
+	})
+}
+
+func (r mongoUsers) RevokedSince(ctx context.Context, ids []models.ID, since time.Time) (map[models.ID]time.Time, error) {
+	users, err := r.find(ctx, bson.M{
+		"_id":                bson.M{"$in": ids},
+		"tokens_valid_after": bson.M{"$gte": since},
+	}, options.Find().SetProjection(bson.M{"tokens_valid_after": 1}))
+	if err != nil {
+		return nil, err
+	}
+	revoked := make(map[models.ID]time.Time, len(users))
+	for _, user := range users {
+		revoked[user.ID] = *user.TokensValidAfter
+	}
+	return revoked, nil
+}
+
+func (r mongoUsers) FlagRefreshTokenReuse(ctx context.Context, id models.ID, at time.Time) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"refresh_token_reused_at": at},
+	})
+}
+
+func (r mongoUsers) UpdateProfile(ctx context.Context, id models.ID, change ProfileChange) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":        id,
+		"is_deleted": false,
+	}, bson.M{"$set": profileFields(change)})
+}
+
+func (r mongoUsers) UpdateMemberProfile(ctx context.Context, id models.ID, orgID primitive.ObjectID, change ProfileChange) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":        id,
+		"org_id":     orgID,
+		"is_deleted": false,
+	}, bson.M{"$set": profileFields(change)})
+}
+
+// profileFields are the fields a profile change sets
+func profileFields(change ProfileChange) bson.M {
+	fields := bson.M{"updated_at": change.UpdatedAt}
+	if change.Name != "" {
+		fields["name"] = change.Name
+	}
+	if change.Email != "" {
+		fields["email"] = change.Email
+		fields["email_verified"] = false
+	}
+	return fields
+}
+
+func (r mongoUsers) SetOrgMembership(ctx context.Context, id models.ID, orgID primitive.ObjectID, role, name string, at time.Time) error {
+	update := bson.M{
+		"$set": bson.M{
+			"org_id":     orgID,
+			"org_role":   role,
+			"name":       name,
+			"updated_at": at,
+		},
+	}
+	if role == "" {
+		update = bson.M{
+			"$unset": bson.M{"org_id": "", "org_role": ""},
+			"$set": bson.M{
+				"name":       name,
+				"updated_at": at,
+			},
+		}
+	}
+
+	return r.updateOne(ctx, bson.M{
+		"_id":        id,
+		"is_deleted": false,
+		"$or": []bson.M{
+			{"org_id": bson.M{"$exists": false}},
+			{"org_id": orgID},
+		},
+	}, update)
+}
+
+func (r mongoUsers) SetEmailVerified(ctx context.Context, id models.ID, email string, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":   id,
+		"email": email,
+	}, bson.M{
+		"$set": bson.M{
+			"email_verified": true,
+			"updated_at":     at,
+		},
+	})
+}
+
+func (r mongoUsers) SetRoles(ctx context.Context, id models.ID, roles, mappedRoles []string, at time.Time) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"roles":        roles,
+			"mapped_roles": mappedRoles,
+			"updated_at":   at,
+		},
+	})
+}
+
+func (r mongoUsers) AcceptTerms(ctx context.Context, id models.ID, version string, at time.Time) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"accepted_terms_version": version,
+			"terms_accepted_at":      at,
+			"updated_at":             at,
+		},
+	})
+}
+
+func (r mongoUsers) SetExternalID(ctx context.Context, id models.ID, system, externalID string, at time.Time) (*models.User, error) {
+	if externalID == "" {
+		return r.findOneAndUpdate(ctx, bson.M{
+			"_id":        id,
+			"is_deleted": false,
+		}, bson.M{
+			"$pull": bson.M{"external_ids": bson.M{"system": system}},
+			"$set":  bson.M{"updated_at": at},
+		})
+	}
+
+	// Replace an existing ID in the system
+	user, err := r.findOneAndUpdate(ctx, bson.M{
+		"_id":                 id,
+		"is_deleted":          false,
+		"external_ids.system": system,
+	}, bson.M{
+		"$set": bson.M{
+			"external_ids.$.id": externalID,
+			"updated_at":        at,
+		},
+	})
+	if err != ErrNotFound {
+		return user, err
+	}
+
+	// Otherwise add one
+	return r.findOneAndUpdate(ctx, bson.M{
+		"_id":                 id,
+		"is_deleted":          false,
+		"external_ids.system": bson.M{"$ne": system},
+	}, bson.M{
+		"$push": bson.M{"external_ids": models.ExternalID{System: system, ID: externalID}},
+		"$set":  bson.M{"updated_at": at},
+	})
+}
+
+func (r mongoUsers) Lock(ctx context.Context, id models.ID, lock models.AccountLock, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":        id,
+		"is_deleted": false,
+	}, bson.M{
+		"$set": bson.M{
+			"lock":       lock,
+			"updated_at": at,
+		},
+	})
+}
+
+func (r mongoUsers) Unlock(ctx context.Context, id models.ID, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":        id,
+		"is_deleted": false,
+	}, unlocking(at))
+}
+
+func (r mongoUsers) UnlockProtective(ctx context.Context, id models.ID, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":       id,
+		"lock.kind": models.LockKindProtective,
+	}, unlocking(at))
+}
+
+// unlocking lifts a lock and resets the failure count
+func unlocking(at time.Time) bson.M {
+	return bson.M{
+		"$unset": bson.M{
+			"lock":               "",
+			"failed_login_count": "",
+		},
+		"$set": bson.M{"updated_at": at},
+	}
+}
+
+func (r mongoUsers) RecordFailedLogin(ctx context.Context, id models.ID) (int, error) {
+	user, err := r.findOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{
+		"$inc": bson.M{"failed_login_count": 1},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return user.FailedLoginCount, nil
+}
+
+func (r mongoUsers) LockProtectively(ctx context.Context, id models.ID, lock models.AccountLock) error {
+	// Never replace an administrator's lock with one that expires
+	return r.updateOne(ctx, bson.M{
+		"_id":       id,
+		"lock.kind": bson.M{"$ne": models.LockKindAdmin},
+	}, bson.M{
+		"$set": bson.M{
+			"lock":               lock,
+			"failed_login_count": 0,
+		},
+	})
+}
+
+func (r mongoUsers) ClearFailedLogins(ctx context.Context, id models.ID) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":       id,
+		"lock.kind": bson.M{"$ne": models.LockKindAdmin},
+	}, bson.M{
+		"$unset": bson.M{
+			"lock":               "",
+			"failed_login_count": "",
+		},
+	})
+}
+
+func (r mongoUsers) ResetPassword(ctx context.Context, id models.ID, hash string, at time.Time) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": passwordFields(hash, at),
+		"$unset": bson.M{
+			"failed_login_count": "",
+		},
+	})
+}
+
+func (r mongoUsers) ChangePassword(ctx context.Context, id models.ID, oldHash, hash string, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":      id,
+		"password": oldHash,
+	}, bson.M{
+		"$set": passwordFields(hash, at),
+	})
+}
+
+// passwordFields are the fields replacing a password sets, signing the
+// user out everywhere
+func passwordFields(hash string, at time.Time) bson.M {
+	return bson.M{
+		"password":            hash,
+		"password_changed_at": at,
+		"tokens_valid_after":  at,
+		"updated_at":          at,
+	}
+}
+
+func (r mongoUsers) RehashPassword(ctx context.Context, id models.ID, oldHash, hash string) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":      id,
+		"password": oldHash,
+	}, bson.M{
+		"$set": bson.M{"password": hash},
+	})
+}
+
+func (r mongoUsers) SetFirstPassword(ctx context.Context, id models.ID, hash string, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":      id,
+		"password": bson.M{"$in": []any{"", nil}},
+	}, bson.M{
+		"$set": bson.M{
+			"password":            hash,
+			"password_changed_at": at,
+			"updated_at":          at,
+		},
+	})
+}
+
+func (r mongoUsers) ClearPassword(ctx context.Context, id models.ID, at time.Time) (*models.User, error) {
+	return r.findOneAndUpdate(ctx, bson.M{
+		"_id":        id,
+		"is_deleted": false,
+	}, bson.M{
+		"$set": bson.M{
+			"password":           "",
+			"tokens_valid_after": at,
+			"updated_at":         at,
+		},
+	})
+}
+
+func (r mongoUsers) RecoverAccount(ctx context.Context, id models.ID, recoveryCodeHash, hash string, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":                  id,
+		"recovery_code_hashes": recoveryCodeHash,
+	}, bson.M{
+		"$set": passwordFields(hash, at),
+		"$unset": bson.M{
+			"two_factor":              "",
+			"lock":                    "",
+			"failed_login_count":      "",
+			"refresh_token_reused_at": "",
+		},
+		"$pull": bson.M{
+			"recovery_code_hashes": recoveryCodeHash,
+		},
+	})
+}
+
+func (r mongoUsers) SetRecoveryCodes(ctx context.Context, id models.ID, hashes []string, at time.Time) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"recovery_code_hashes": hashes,
+			"updated_at":           at,
+		},
+	})
+}
+
+func (r mongoUsers) UseRecoveryCode(ctx context.Context, id models.ID, hash string, at time.Time) error {
+	// Pulling the code in the same update makes it work once
+	return r.updateOne(ctx, bson.M{
+		"_id":                  id,
+		"recovery_code_hashes": hash,
+	}, bson.M{
+		"$pull": bson.M{"recovery_code_hashes": hash},
+		"$set":  bson.M{"updated_at": at},
+	})
+}
+
+func (r mongoUsers) StartTwoFactor(ctx context.Context, id models.ID, pending models.TwoFactor, at time.Time) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"pending_two_factor": pending,
+			"updated_at":         at,
+		},
+	})
+}
+
+func (r mongoUsers) EnableTwoFactor(ctx context.Context, id models.ID, factor models.TwoFactor, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":                       id,
+		"pending_two_factor.secret": factor.Secret,
+	}, bson.M{
+		"$set": bson.M{
+			"two_factor": factor,
+			"updated_at": at,
+		},
+		"$unset": bson.M{
+			"pending_two_factor":  "",
+			"two_factor_deadline": "",
+		},
+	})
+}
+
+func (r mongoUsers) DisableTwoFactor(ctx context.Context, id models.ID, secret string, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":               id,
+		"two_factor.secret": secret,
+	}, bson.M{
+		"$set": bson.M{"updated_at": at},
+		"$unset": bson.M{
+			"two_factor":         "",
+			"pending_two_factor": "",
+		},
+	})
+}
+
+func (r mongoUsers) ReplaceTwoFactorSecret(ctx context.Context, id models.ID, oldSecret, secret string) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":               id,
+		"two_factor.secret": oldSecret,
+	}, bson.M{
+		"$set": bson.M{"two_factor.secret": secret},
+	})
+}
+
+func (r mongoUsers) SetTwoFactorDeadline(ctx context.Context, id models.ID, deadline time.Time) (*time.Time, error) {
+	// A concurrent login may set the deadline first, in which case its
+	// value wins
+	user, err := r.findOneAndUpdate(ctx, bson.M{
+		"_id":                 id,
+		"two_factor_deadline": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{"two_factor_deadline": deadline},
+	})
+	if err == ErrNotFound {
+		user, err = r.findOne(ctx, bson.M{"_id": id})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user.TwoFactorDeadline, nil
+}
+
+func (r mongoUsers) StartTwoFactorReset(ctx context.Context, id models.ID, reset models.TwoFactorReset, recoveryCodeHash string, at time.Time) error {
+	filter := bson.M{
+		"_id":              id,
+		"two_factor":       bson.M{"$exists": true},
+		"two_factor_reset": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"two_factor_reset": reset,
+			"updated_at":       at,
+		},
+	}
+
+	// Consuming the recovery code in the same update makes a second
+	// request with the same code fail
+	if recoveryCodeHash != "" {
+		filter["recovery_code_hashes"] = recoveryCodeHash
+		update["$pull"] = bson.M{"recovery_code_hashes": recoveryCodeHash}
+	}
+	return r.updateOne(ctx, filter, update)
+}
+
+func (r mongoUsers) CancelTwoFactorReset(ctx context.Context, id models.ID, cancelTokenHash string, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":                                id,
+		"two_factor_reset.cancel_token_hash": cancelTokenHash,
+	}, bson.M{
+		"$set": bson.M{
+			"tokens_valid_after": at,
+			"updated_at":         at,
+		},
+		"$unset": bson.M{
+			"two_factor_reset": "",
+		},
+	})
+}
+
+func (r mongoUsers) CompleteTwoFactorReset(ctx context.Context, id models.ID, cancelTokenHash string, at time.Time) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":                                id,
+		"two_factor_reset.cancel_token_hash": cancelTokenHash,
+	}, bson.M{
+		"$set": bson.M{
+			"updated_at": at,
+		},
+		"$unset": bson.M{
+			"two_factor":       "",
+			"two_factor_reset": "",
+		},
+	})
+}
+
+func (r mongoUsers) StartPasskeyRegistration(ctx context.Context, id models.ID, registration models.PasskeyRegistration, at time.Time) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"pending_passkey": registration,
+			"updated_at":      at,
+		},
+	})
+}
+
+func (r mongoUsers) ConsumePasskeyRegistration(ctx context.Context, id models.ID, session []byte) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":                     id,
+		"pending_passkey.session": session,
+	}, bson.M{
+		"$unset": bson.M{"pending_passkey": ""},
+	})
+}
+
+func (r mongoUsers) StartPasskeyLogin(ctx context.Context, id models.ID, login models.PasskeyLogin) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"passkey_login": login},
+	})
+}
+
+func (r mongoUsers) ConsumePasskeyLogin(ctx context.Context, id models.ID, session []byte) error {
+	return r.updateOne(ctx, bson.M{
+		"_id":                   id,
+		"passkey_login.session": session,
+	}, bson.M{
+		"$unset": bson.M{"passkey_login": ""},
+	})
+}
+
+func (r mongoUsers) RecordIdentityLogin(ctx context.Context, issuer, subject string, at time.Time) (*models.User, error) {
+	return r.findOneAndUpdate(ctx, bson.M{
+		"is_deleted": false,
+		"identities": bson.M{"$elemMatch": bson.M{
+			"issuer":  issuer,
+			"subject": subject,
+		}},
+	}, bson.M{
+		"$set": bson.M{"identities.$.last_login_at": at},
+	})
+}
+
+func (r mongoUsers) LinkIdentity(ctx context.Context, id models.ID, identity models.LinkedIdentity, at time.Time) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$push": bson.M{"identities": identity},
+		"$set":  bson.M{"updated_at": at},
+	})
+}
+
+func (r mongoUsers) LinkMemberIdentity(ctx context.Context, email string, orgID primitive.ObjectID, identity models.LinkedIdentity, at time.Time) (*models.User, error) {
+	return r.findOneAndUpdate(ctx, bson.M{
+		"email":      email,
+		"org_id":     orgID,
+		"is_deleted": false,
+	}, bson.M{
+		"$push": bson.M{"identities": identity},
+		"$set":  bson.M{"updated_at": at},
+	})
+}
+
+func (r mongoUsers) UnlinkIdentity(ctx context.Context, id models.ID, issuer, subject string, at time.Time) error {
+	// Checked in the filter so a concurrent unlink or password removal
+	// can't leave the account without a login method
+	result, err := r.users.UpdateOne(ctx, bson.M{
+		"_id": id,
+		"$or": []bson.M{
+			{"password": bson.M{"$nin": []any{"", nil}}},
+			{"identities.1": bson.M{"$exists": true}},
+		},
+	}, bson.M{
+		"$pull": bson.M{"identities": bson.M{
+			"issuer":  issuer,
+			"subject": subject,
+		}},
+		"$set": bson.M{"updated_at": at},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrLastLoginMethod
+	}
+	if result.ModifiedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// updateOne updates the user filter matches, or returns ErrNotFound when
+// there is none
+func (r mongoUsers) updateOne(ctx context.Context, filter, update bson.M) error {
+	result, err := r.users.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// findOneAndUpdate updates the user filter matches and returns it updated
+func (r mongoUsers) findOneAndUpdate(ctx context.Context, filter, update bson.M) (*models.User, error) {
+	var user models.User
+	err := r.users.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+type mongoTokens struct {
+	tokens *Collection
+}
+
+func (r mongoTokens) Insert(ctx context.Context, token models.InvalidatedToken) error {
+	_, err := r.tokens.InsertOne(ctx, token)
+	return err
+}
+
+func (r mongoTokens) IsInvalidated(ctx context.Context, token string) (bool, error) {
+	err := r.tokens.FindOne(ctx, bson.M{"token": token}, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r mongoTokens) EachUnexpired(ctx context.Context, fn func(token models.InvalidatedToken) error) error {
+	cursor, err := r.tokens.Find(ctx, bson.M{"expires_at": bson.M{"$gt": time.Now()}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var token models.InvalidatedToken
+		if err := cursor.Decode(&token); err != nil {
+			return err
+		}
+		if err := fn(token); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (r mongoTokens) Count(ctx context.Context) (int64, error) {
+	return r.tokens.EstimatedDocumentCount(ctx)
+}
+
+type mongoAttempts struct {
+	attempts *Collection
+}
+
+func (r mongoAttempts) Insert(ctx context.Context, attempt models.LoginAttempt) error {
+	_, err := r.attempts.InsertOne(ctx, attempt)
+	return err
+}
+
+func failuresFilter(email, ipAddress string, since time.Time) bson.M {
+	return bson.M{
+		"email":      email,
+		"ip_address": ipAddress,
+		"success":    false,
+		"timestamp":  bson.M{"$gte": since},
+	}
+}
+
+func (r mongoAttempts) CountFailures(ctx context.Context, email, ipAddress string, since time.Time) (int64, error) {
+	return r.attempts.CountDocuments(ctx, failuresFilter(email, ipAddress, since))
+}
+
+func (r mongoAttempts) NthLatestFailure(ctx context.Context, email, ipAddress string, since time.Time, n int) (time.Time, error) {
+	findOptions := options.FindOne().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}).
+		SetSkip(int64(n - 1))
+
+	var attempt models.LoginAttempt
+	err := r.attempts.FindOne(ctx, failuresFilter(email, ipAddress, since), findOptions).Decode(&attempt)
+	if err == ErrNotFound {
+		return time.Time{}, nil
+	}
+	return attempt.Timestamp, err
+}
+
+func (r mongoAttempts) DeleteUnused(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.attempts.DeleteMany(ctx, bson.M{"$or": []bson.M{
+		{"success": true},
+		{"timestamp": bson.M{"$lt": before}},
+	}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+type mongoAPIKeys struct {
+	apiKeys *Collection
+}
+
+func (r mongoAPIKeys) Insert(ctx context.Context, key models.APIKey) error {
+	_, err := r.apiKeys.InsertOne(ctx, key)
+	return err
+}
+
+func (r mongoAPIKeys) FindByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.apiKeys.FindOne(ctx, bson.M{"key_hash": hash}).Decode(&key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r mongoAPIKeys) ListByUser(ctx context.Context, userID models.ID) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	cursor, err := r.apiKeys.Find(ctx, bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r mongoAPIKeys) RecordUse(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	// $max keeps the latest use when concurrent requests race
+	_, err := r.apiKeys.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$max": bson.M{"last_used_at": at},
+	})
+	return err
+}
+
+func (r mongoAPIKeys) Delete(ctx context.Context, userID models.ID, id primitive.ObjectID) error {
+	result, err := r.apiKeys.DeleteOne(ctx, bson.M{
+		"_id":     id,
+		"user_id": userID,
+	})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r mongoAPIKeys) DeleteByUser(ctx context.Context, userID models.ID) error {
+	_, err := r.apiKeys.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
+
+type mongoSessions struct {
+	sessions *Collection
+}
+
+func (r mongoSessions) Insert(ctx context.Context, session models.Session) error {
+	_, err := r.sessions.InsertOne(ctx, session)
+	return err
+}
+
+func (r mongoSessions) FindByID(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	if err := r.sessions.FindOne(ctx, bson.M{"_id": id}).Decode(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r mongoSessions) RecordActivity(ctx context.Context, session models.Session, at time.Time) error {
+	// $max keeps the latest activity when concurrent requests race
+	_, err := r.sessions.UpdateOne(ctx, bson.M{"_id": session.ID}, bson.M{
+		"$max": bson.M{"last_activity_at": at},
+		"$setOnInsert": bson.M{
+			"user_id":    session.UserID,
+			"issued_at":  session.IssuedAt,
+			"expires_at": session.ExpiresAt,
+		},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r mongoSessions) Extend(ctx context.Context, id string, at, expiresAt time.Time) error {
+	_, err := r.sessions.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$max": bson.M{
+			"last_activity_at": at,
+			"expires_at":       expiresAt,
+		},
+	})
+	return err
+}
+
+func (r mongoSessions) ListActive(ctx context.Context, userID models.ID, now, issuedAfter, activeSince time.Time) ([]models.Session, error) {
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+	if !issuedAfter.IsZero() {
+		filter["issued_at"] = bson.M{"$gt": issuedAfter}
+	}
+	if !activeSince.IsZero() {
+		filter["last_activity_at"] = bson.M{"$gte": activeSince}
+	}
+
+	cursor, err := r.sessions.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "last_activity_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	sessions := []models.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r mongoSessions) Revoke(ctx context.Context, userID models.ID, id string, at time.Time) error {
+	result, err := r.sessions.UpdateOne(ctx, bson.M{
+		"_id":        id,
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{"revoked_at": at},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r mongoSessions) RevokeAll(ctx context.Context, userID models.ID, at time.Time) (int, error) {
+	result, err := r.sessions.UpdateMany(ctx, bson.M{
+		"user_id":    userID,
+		"expires_at": bson.M{"$gt": at},
+		"revoked_at": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{"revoked_at": at},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(result.ModifiedCount), nil
+}
+
+func (r mongoSessions) Revoked(ctx context.Context, ids []string) ([]string, error) {
+	var revoked []struct {
+		ID string `bson:"_id"`
+	}
+	cursor, err := r.sessions.Find(ctx, bson.M{
+		"_id":        bson.M{"$in": ids},
+		"revoked_at": bson.M{"$exists": true},
+	}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &revoked); err != nil {
+		return nil, err
+	}
+	revokedIDs := make([]string, len(revoked))
+	for i, session := range revoked {
+		revokedIDs[i] = session.ID
+	}
+	return revokedIDs, nil
+}
+
+type mongoRefreshTokens struct {
+	refreshTokens *Collection
+}
+
+func (r mongoRefreshTokens) Insert(ctx context.Context, token models.RefreshToken) error {
+	_, err := r.refreshTokens.InsertOne(ctx, token)
+	return err
+}
+
+func (r mongoRefreshTokens) FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.refreshTokens.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r mongoRefreshTokens) Consume(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	result, err := r.refreshTokens.UpdateOne(ctx, bson.M{
+		"_id":         id,
+		"consumed_at": bson.M{"$exists": false},
+		"revoked_at":  bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{"consumed_at": at},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r mongoRefreshTokens) RevokeSession(ctx context.Context, userID models.ID, sessionID string, at time.Time) error {
+	return r.revoke(ctx, bson.M{"user_id": userID, "session_id": sessionID}, at)
+}
+
+func (r mongoRefreshTokens) RevokeUser(ctx context.Context, userID models.ID, at time.Time) error {
+	return r.revoke(ctx, bson.M{"user_id": userID}, at)
+}
+
+// revoke revokes the unexpired refresh tokens filter matches
+func (r mongoRefreshTokens) revoke(ctx context.Context, filter bson.M, at time.Time) error {
+	filter["expires_at"] = bson.M{"$gt": at}
+	filter["revoked_at"] = bson.M{"$exists": false}
+	_, err := r.refreshTokens.UpdateMany(ctx, filter, bson.M{
+		"$set": bson.M{"revoked_at": at},
+	})
+	return err
+}
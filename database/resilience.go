@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+
+	"user-management/metrics"
+)
+
+// Read retries on top of the driver's own single retry
+const (
+	maxReadAttempts  = 3
+	readRetryBackoff = 50 * time.Millisecond
+)
+
+// Server error codes returned while a replica set elects a new primary
+var failoverErrorCodes = []int{
+	91,    // ShutdownInProgress
+	189,   // PrimarySteppedDown
+	10107, // NotWritablePrimary
+	11600, // InterruptedAtShutdown
+	11602, // InterruptedDueToReplStateChange
+	13435, // NotPrimaryNoSecondaryOk
+	13436, // NotPrimaryOrSecondary
+}
+
+// retryBudget caps read retries at a fraction of reads, so retries cannot
+// multiply the load on a database that is already struggling. Each read
+// earns ratio tokens, each retry spends one.
+type retryBudget struct {
+	mu     sync.Mutex
+	tokens float64
+	max    float64
+	ratio  float64
+}
+
+func newRetryBudget(max, ratio float64) *retryBudget {
+	return &retryBudget{tokens: max, max: max, ratio: ratio}
+}
+
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.max, b.tokens+b.ratio)
+}
+
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// isTransient reports whether a read failed for a reason that may clear up
+// on its own, such as a dropped connection or a primary election
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+
+	var selectionErr topology.ServerSelectionError
+	if errors.As(err, &selectionErr) {
+		return true
+	}
+
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		if serverErr.HasErrorLabel("RetryableReadError") {
+			return true
+		}
+		for _, code := range failoverErrorCodes {
+			if serverErr.HasErrorCode(code) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// retryRead runs an idempotent read, retrying transient failures with
+// backoff while the retry budget allows
+func (c *Collection) retryRead(ctx context.Context, read func() error) error {
+	c.budget.deposit()
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = read()
+		if !isTransient(err) || attempt >= maxReadAttempts || ctx.Err() != nil {
+			return err
+		}
+		if !c.budget.withdraw() {
+			metrics.DatabaseReadRetries.WithLabelValues("budget_exhausted").Inc()
+			return err
+		}
+		metrics.DatabaseReadRetries.WithLabelValues("retried").Inc()
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(readRetryBackoff << (attempt - 1)):
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// snapshotTooOldCode is returned for reads at a cluster time older than the
+// server keeps history for, minSnapshotHistoryWindowInSeconds (5 minutes
+// by default)
+const snapshotTooOldCode = 239
+
+// ErrSnapshotTooOld is returned by reads at a cluster time the server no
+// longer keeps history for
+var ErrSnapshotTooOld = errors.New("snapshot is too old")
+
+// SupportsSnapshotReads reports whether reads at a fixed cluster time are
+// possible, which needs a replica set or sharded cluster
+func (d *Database) SupportsSnapshotReads() bool {
+	return d.supportsTransactions
+}
+
+// ClusterTime returns the deployment's current cluster time, a point that
+// later snapshot reads can all read at
+func (d *Database) ClusterTime(ctx context.Context) (primitive.Timestamp, error) {
+	session, err := d.Client.StartSession()
+	if err != nil {
+		return primitive.Timestamp{}, fmt.Errorf("failed to start session: %v", err)
+	}
+	defer session.EndSession(ctx)
+
+	err = mongo.WithSession(ctx, session, func(sc mongo.SessionContext) error {
+		return d.DB.RunCommand(sc, bson.D{{Key: "ping", Value: 1}}).Err()
+	})
+	if err != nil {
+		return primitive.Timestamp{}, err
+	}
+
+	operationTime := session.OperationTime()
+	if operationTime == nil {
+		return primitive.Timestamp{}, errors.New("deployment did not report a cluster time")
+	}
+	return *operationTime, nil
+}
+
+// FindAt runs a find that sees the collection as it was at cluster time at
+// and decodes the matching documents into results, a pointer to a slice.
+// At most limit documents are returned, in a single batch.
+func (c *Collection) FindAt(ctx context.Context, at primitive.Timestamp, filter interface{}, sort bson.D, skip, limit int64, results interface{}) error {
+	if err := c.checkQuery(ctx, "find", filter); err != nil {
+		return err
+	}
+
+	command := bson.D{
+		{Key: "find", Value: c.Name()},
+		{Key: "filter", Value: filter},
+		{Key: "sort", Value: sort},
+		{Key: "skip", Value: skip},
+		{Key: "limit", Value: limit},
+		{Key: "singleBatch", Value: true},
+	}
+
+	batch, err := c.snapshotCommand(ctx, at, command)
+	if err != nil {
+		return err
+	}
+	return batch.Unmarshal(results)
+}
+
+// CountAt counts the documents matching filter at cluster time at
+func (c *Collection) CountAt(ctx context.Context, at primitive.Timestamp, filter interface{}) (int64, error) {
+	if err := c.checkQuery(ctx, "countDocuments", filter); err != nil {
+		return 0, err
+	}
+
+	command := bson.D{
+		{Key: "aggregate", Value: c.Name()},
+		{Key: "pipeline", Value: bson.A{
+			bson.D{{Key: "$match", Value: filter}},
+			bson.D{{Key: "$count", Value: "count"}},
+		}},
+		{Key: "cursor", Value: bson.D{}},
+	}
+
+	batch, err := c.snapshotCommand(ctx, at, command)
+	if err != nil {
+		return 0, err
+	}
+
+	var counts []struct {
+		Count int64 `bson:"count"`
+	}
+	if err := batch.Unmarshal(&counts); err != nil {
+		return 0, err
+	}
+	if len(counts) == 0 {
+		return 0, nil
+	}
+	return counts[0].Count, nil
+}
+
+// snapshotCommand runs a cursor command with snapshot read concern at
+// cluster time at, and returns the first batch
+func (c *Collection) snapshotCommand(ctx context.Context, at primitive.Timestamp, command bson.D) (bson.RawValue, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	command = append(command, bson.E{Key: "readConcern", Value: bson.D{
+		{Key: "level", Value: "snapshot"},
+		{Key: "atClusterTime", Value: at},
+	}})
+	if comment, ok := commentFor(ctx); ok {
+		command = append(command, bson.E{Key: "comment", Value: comment})
+	}
+
+	var response struct {
+		Cursor struct {
+			FirstBatch bson.RawValue `bson:"firstBatch"`
+		} `bson:"cursor"`
+	}
+	err := c.retryRead(ctx, func() error {
+		return c.Database().RunCommand(ctx, command).Decode(&response)
+	})
+	if err != nil {
+		var serverErr mongo.ServerError
+		if errors.As(err, &serverErr) && serverErr.HasErrorCode(snapshotTooOldCode) {
+			return bson.RawValue{}, ErrSnapshotTooOld
+		}
+		return bson.RawValue{}, err
+	}
+	return response.Cursor.FirstBatch, nil
+}
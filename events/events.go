@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"user-management/database"
+	"user-management/models"
+)
+
+// Event types
+const (
+	TypeUserRegistered = "user.registered"
+	TypeUserUpdated    = "user.updated"
+	TypeUserDeleted    = "user.deleted"
+	TypeUserRestored   = "user.restored"
+	TypeUserErased     = "user.erased"
+)
+
+// Publisher delivers events to downstream consumers
+type Publisher interface {
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
+
+// LogPublisher writes events to the server log instead of a message broker
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	log.Printf("Event %s %s: %v", event.Type, event.ID.Hex(), event.Payload)
+	return nil
+}
+
+// Enqueue writes an event to the outbox. Call it with the context passed to
+// database.WithTransaction so the event is only recorded if the change it
+// describes commits.
+func Enqueue(ctx context.Context, db *database.Database, eventType string, payload bson.M) error {
+	now := time.Now()
+	event := models.OutboxEvent{
+		Type:          eventType,
+		Payload:       payload,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+
+	if _, err := db.Outbox.InsertOne(ctx, event); err != nil {
+		return fmt.Errorf("failed to enqueue %s event: %v", eventType, err)
+	}
+	return nil
+}
+
+// ReplayDeadLetters moves dead-lettered events back into the outbox with a
+// fresh retry budget. An empty filter replays every dead letter.
+func ReplayDeadLetters(ctx context.Context, db *database.Database, filter bson.M) (int, error) {
+	cursor, err := db.DeadLetters.Find(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find dead letters: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	replayed := 0
+	for cursor.Next(ctx) {
+		var deadLetter models.DeadLetterEvent
+		if err := cursor.Decode(&deadLetter); err != nil {
+			return replayed, fmt.Errorf("failed to decode dead letter: %v", err)
+		}
+
+		err := db.WithTransaction(ctx, func(ctx context.Context) error {
+			now := time.Now()
+			_, err := db.Outbox.InsertOne(ctx, models.OutboxEvent{
+				ID:            deadLetter.ID,
+				Type:          deadLetter.Type,
+				Payload:       deadLetter.Payload,
+				NextAttemptAt: now,
+				CreatedAt:     deadLetter.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = db.DeadLetters.DeleteOne(ctx, bson.M{"_id": deadLetter.ID})
+			return err
+		})
+		if err != nil {
+			return replayed, fmt.Errorf("failed to replay event %s: %v", deadLetter.ID.Hex(), err)
+		}
+		replayed++
+	}
+
+	if err := cursor.Err(); err != nil {
+		return replayed, fmt.Errorf("failed to iterate dead letters: %v", err)
+	}
+	return replayed, nil
+}
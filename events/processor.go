@@ -0,0 +1,175 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"user-management/database"
+	"user-management/metrics"
+	"user-management/models"
+)
+
+// maxRetryBackoff caps the exponential delay between delivery attempts
+const maxRetryBackoff = time.Hour
+
+type ProcessorConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxAttempts is the number of deliveries tried before an event is moved
+	// to the dead letter queue
+	MaxAttempts int
+	// RetryBackoff is the delay after the first failure, doubled for each
+	// further failure
+	RetryBackoff time.Duration
+	// LeaseDuration is how long a claimed event is hidden from other
+	// processors while it is being published
+	LeaseDuration time.Duration
+}
+
+// Processor publishes outbox events, retrying failures with exponential
+// backoff and dead-lettering events that exhaust their attempts. Several
+// processors can run against the same database; events are claimed with a
+// lease so each is handled by one processor at a time.
+type Processor struct {
+	db        *database.Database
+	publisher Publisher
+	config    ProcessorConfig
+}
+
+func NewProcessor(db *database.Database, publisher Publisher, config ProcessorConfig) *Processor {
+	return &Processor{
+		db:        db,
+		publisher: publisher,
+		config:    config,
+	}
+}
+
+// Run processes the outbox until ctx is cancelled
+func (p *Processor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		p.processBatch(ctx)
+		p.updateBacklogMetrics(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Processor) processBatch(ctx context.Context) {
+	for i := 0; i < p.config.BatchSize; i++ {
+		event, err := p.claimNext(ctx)
+		if err != nil {
+			if err != mongo.ErrNoDocuments && ctx.Err() == nil {
+				log.Printf("Failed to claim outbox event: %v", err)
+			}
+			return
+		}
+
+		if err := p.publisher.Publish(ctx, *event); err != nil {
+			p.handleFailure(ctx, event, err)
+			continue
+		}
+
+		metrics.EventsPublished.WithLabelValues(event.Type).Inc()
+		if _, err := p.db.Outbox.DeleteOne(ctx, bson.M{"_id": event.ID}); err != nil {
+			// The lease expires and the event is delivered again, so
+			// consumers must tolerate duplicates
+			log.Printf("Failed to remove published event %s: %v", event.ID.Hex(), err)
+		}
+	}
+}
+
+// claimNext leases the oldest event that is due for delivery
+func (p *Processor) claimNext(ctx context.Context) (*models.OutboxEvent, error) {
+	now := time.Now()
+
+	var event models.OutboxEvent
+	err := p.db.Outbox.FindOneAndUpdate(ctx, bson.M{
+		"next_attempt_at": bson.M{"$lte": now},
+		"locked_until":    bson.M{"$lte": now},
+	}, bson.M{
+		"$set": bson.M{"locked_until": now.Add(p.config.LeaseDuration)},
+	}, options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After),
+	).Decode(&event)
+
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (p *Processor) handleFailure(ctx context.Context, event *models.OutboxEvent, publishErr error) {
+	metrics.EventPublishFailures.WithLabelValues(event.Type).Inc()
+	attempts := event.Attempts + 1
+	now := time.Now()
+
+	if attempts >= p.config.MaxAttempts {
+		err := p.db.WithTransaction(ctx, func(ctx context.Context) error {
+			_, err := p.db.DeadLetters.InsertOne(ctx, models.DeadLetterEvent{
+				ID:             event.ID,
+				Type:           event.Type,
+				Payload:        event.Payload,
+				Attempts:       attempts,
+				LastError:      publishErr.Error(),
+				CreatedAt:      event.CreatedAt,
+				DeadLetteredAt: now,
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = p.db.Outbox.DeleteOne(ctx, bson.M{"_id": event.ID})
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to dead-letter event %s: %v", event.ID.Hex(), err)
+			return
+		}
+
+		metrics.EventsDeadLettered.WithLabelValues(event.Type).Inc()
+		log.Printf("Event %s %s moved to dead letter queue after %d attempts: %v", event.Type, event.ID.Hex(), attempts, publishErr)
+		return
+	}
+
+	backoff := p.config.RetryBackoff << (attempts - 1)
+	if backoff <= 0 || backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+
+	_, err := p.db.Outbox.UpdateOne(ctx, bson.M{"_id": event.ID}, bson.M{
+		"$set": bson.M{
+			"attempts":        attempts,
+			"last_error":      publishErr.Error(),
+			"next_attempt_at": now.Add(backoff),
+			"locked_until":    time.Time{},
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to reschedule event %s: %v", event.ID.Hex(), err)
+	}
+}
+
+func (p *Processor) updateBacklogMetrics(ctx context.Context) {
+	backlog, err := p.db.Outbox.EstimatedDocumentCount(ctx)
+	if err == nil {
+		metrics.OutboxBacklog.Set(float64(backlog))
+	}
+
+	deadLetters, err := p.db.DeadLetters.EstimatedDocumentCount(ctx)
+	if err == nil {
+		metrics.DeadLetterBacklog.Set(float64(deadLetters))
+	}
+}
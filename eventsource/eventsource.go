@@ -0,0 +1,266 @@
+// Package eventsource keeps the event-sourced history of user documents.
+// When it is enabled, every change to a user appends an event holding the
+// changed fields to user_events, in the same transaction as the change, and
+// every few events a snapshot of the whole document goes to user_snapshots.
+// The users collection is the current state projected from those events.
+package eventsource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"user-management/database"
+	"user-management/models"
+)
+
+// Event types
+const (
+	TypeRegistered             = "registered"
+	TypeImported               = "imported"
+	TypeProfileUpdated         = "profile_updated"
+	TypeProfileChangeApproved  = "profile_change_approved"
+	TypeDeleted                = "deleted"
+	TypeRestored               = "restored"
+	TypePasswordRehashed       = "password_rehashed"
+	TypeRecovered              = "recovered"
+	TypeRecoveryCodesGenerated = "recovery_codes_generated"
+	TypeLocked                 = "locked"
+	TypeUnlocked               = "unlocked"
+	TypeOrgMembershipChanged   = "org_membership_changed"
+	TypeExternalIDChanged      = "external_id_changed"
+	TypePasswordReset          = "password_reset"
+	TypePasswordChanged        = "password_changed"
+	TypePasswordSet            = "password_set"
+	TypeProvisioned            = "provisioned"
+	TypeTwoFactorResetStarted  = "two_factor_reset_started"
+	TypeTwoFactorResetCanceled = "two_factor_reset_canceled"
+	TypeTwoFactorReset         = "two_factor_reset"
+	TypeTwoFactorEnabled       = "two_factor_enabled"
+	TypeTwoFactorDisabled      = "two_factor_disabled"
+	TypeDeprovisioned          = "deprovisioned"
+	TypeDeactivated            = "deactivated"
+	TypeReactivated            = "reactivated"
+	TypePasswordResetByAdmin   = "password_reset_by_admin"
+)
+
+// snapshotInterval is the number of events between snapshots
+const snapshotInterval = 20
+
+// untrackedFields change too often to be worth keeping in the history
+var untrackedFields = map[string]bool{
+	"failed_login_count": true,
+}
+
+// ErrNoHistory is returned for users with no events at the requested time
+var ErrNoHistory = errors.New("no history for user")
+
+// Record appends an event for the latest change to the user. Call it after
+// the change with the context passed to database.WithTransaction, so the
+// event and the change commit together. It does nothing unless event
+// sourcing is enabled, or when no tracked field changed.
+func Record(ctx context.Context, db *database.Database, userID models.ID, eventType string) error {
+	if !db.EventSourcedUsers() {
+		return nil
+	}
+
+	var current bson.Raw
+	if err := db.Users.FindOne(ctx, bson.M{"_id": userID}).Decode(&current); err != nil {
+		return fmt.Errorf("failed to read user %s: %v", userID.String(), err)
+	}
+
+	state, version, err := load(ctx, db, userID, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	set, unset, err := diff(state, current)
+	if err != nil {
+		return err
+	}
+	if len(set) == 0 && len(unset) == 0 {
+		return nil
+	}
+
+	setDoc, err := bson.Marshal(set)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	event := models.UserEvent{
+		UserID:    userID,
+		Version:   version + 1,
+		Type:      eventType,
+		Set:       setDoc,
+		Unset:     unset,
+		CreatedAt: now,
+	}
+
+	// The unique index on user_id and version rejects a concurrent writer
+	// that read the same version
+	if _, err := db.UserEvents.InsertOne(ctx, event); err != nil {
+		return fmt.Errorf("failed to record %s event for user %s: %v", eventType, userID.String(), err)
+	}
+
+	if event.Version%snapshotInterval == 0 {
+		_, err := db.UserSnapshots.InsertOne(ctx, models.UserSnapshot{
+			UserID:    userID,
+			Version:   event.Version,
+			State:     current,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to snapshot user %s: %v", userID.String(), err)
+		}
+	}
+	return nil
+}
+
+// StateAt rebuilds the user as it was at the given time. It also returns
+// the version of the last event applied.
+func StateAt(ctx context.Context, db *database.Database, userID models.ID, at time.Time) (*models.User, int64, error) {
+	state, version, err := load(ctx, db, userID, at)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version == 0 {
+		return nil, 0, ErrNoHistory
+	}
+
+	doc, err := bson.Marshal(state)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var user models.User
+	if err := bson.Unmarshal(doc, &user); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode user %s at version %d: %v", userID.String(), version, err)
+	}
+	return &user, version, nil
+}
+
+// load folds the user's events up to at, starting from the latest snapshot
+// before it. A zero at loads the latest state.
+func load(ctx context.Context, db *database.Database, userID models.ID, at time.Time) (bson.D, int64, error) {
+	snapshotFilter := bson.M{"user_id": userID}
+	eventFilter := bson.M{"user_id": userID}
+	if !at.IsZero() {
+		snapshotFilter["created_at"] = bson.M{"$lte": at}
+		eventFilter["created_at"] = bson.M{"$lte": at}
+	}
+
+	state := bson.D{}
+	var version int64
+
+	var snapshot models.UserSnapshot
+	err := db.UserSnapshots.FindOne(ctx, snapshotFilter, options.FindOne().
+		SetSort(bson.D{{Key: "version", Value: -1}})).Decode(&snapshot)
+	if err == nil {
+		if err := bson.Unmarshal(snapshot.State, &state); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode snapshot of user %s: %v", userID.String(), err)
+		}
+		version = snapshot.Version
+		eventFilter["version"] = bson.M{"$gt": version}
+	} else if err != mongo.ErrNoDocuments {
+		return nil, 0, fmt.Errorf("failed to find snapshot of user %s: %v", userID.String(), err)
+	}
+
+	cursor, err := db.UserEvents.Find(ctx, eventFilter, options.Find().
+		SetSort(bson.D{{Key: "version", Value: 1}}))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find events of user %s: %v", userID.String(), err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var event models.UserEvent
+		if err := cursor.Decode(&event); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode event of user %s: %v", userID.String(), err)
+		}
+		state, err = apply(state, event)
+		if err != nil {
+			return nil, 0, err
+		}
+		version = event.Version
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read events of user %s: %v", userID.String(), err)
+	}
+	return state, version, nil
+}
+
+// apply returns state with the event's changes
+func apply(state bson.D, event models.UserEvent) (bson.D, error) {
+	removed := map[string]bool{}
+	for _, key := range event.Unset {
+		removed[key] = true
+	}
+
+	var set bson.D
+	if len(event.Set) > 0 {
+		if err := bson.Unmarshal(event.Set, &set); err != nil {
+			return nil, fmt.Errorf("failed to decode event %d of user %s: %v", event.Version, event.UserID.String(), err)
+		}
+	}
+	for _, e := range set {
+		removed[e.Key] = true
+	}
+
+	next := make(bson.D, 0, len(state)+len(set))
+	for _, e := range state {
+		if !removed[e.Key] {
+			next = append(next, e)
+		}
+	}
+	return append(next, set...), nil
+}
+
+// diff returns the tracked fields of current that differ from state, and
+// the tracked fields of state that current no longer has
+func diff(state bson.D, current bson.Raw) (bson.D, []string, error) {
+	previous := map[string][]byte{}
+	for _, e := range state {
+		t, data, err := bson.MarshalValue(e.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		previous[e.Key] = append([]byte{byte(t)}, data...)
+	}
+
+	elements, err := current.Elements()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var set bson.D
+	seen := map[string]bool{}
+	for _, element := range elements {
+		key := element.Key()
+		if untrackedFields[key] {
+			continue
+		}
+		seen[key] = true
+
+		value := element.Value()
+		encoded := append([]byte{byte(value.Type)}, value.Value...)
+		if old, ok := previous[key]; ok && bytes.Equal(old, encoded) {
+			continue
+		}
+		set = append(set, bson.E{Key: key, Value: value})
+	}
+
+	var unset []string
+	for _, e := range state {
+		if !untrackedFields[e.Key] && !seen[e.Key] {
+			unset = append(unset, e.Key)
+		}
+	}
+	return set, unset, nil
+}
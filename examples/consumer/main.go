@@ -0,0 +1,70 @@
+// Command consumer is an example downstream service that authenticates its
+// callers with tokens from the user management service.
+//
+// Usage:
+//
+//	consumer [-addr :8081] [-auth host:port]   # check tokens with ValidateToken
+//	consumer [-addr :8081] -jwks url           # check tokens locally
+//
+// Then call it with a token from Login:
+//
+//	curl -H "Authorization: Bearer $TOKEN" localhost:8081/whoami
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"user-management/authmd"
+	"user-management/authmw"
+	pb "user-management/proto"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "HTTP listen address")
+	authAddr := flag.String("auth", "localhost:50051", "user management gRPC address, for remote checks")
+	jwksURL := flag.String("jwks", "", "JWKS URL of the user management service; checks tokens locally when set")
+	flag.Parse()
+
+	var verifier authmw.Verifier
+	if *jwksURL != "" {
+		jwks, err := authmw.NewJWKSVerifier(authmw.JWKSConfig{URL: *jwksURL})
+		if err != nil {
+			log.Fatalf("Failed to create JWKS verifier: %v", err)
+		}
+		verifier = jwks
+	} else {
+		conn, err := grpc.NewClient(*authAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			log.Fatalf("Failed to connect to %s: %v", *authAddr, err)
+		}
+		defer conn.Close()
+		verifier = authmw.NewIntrospectionVerifier(pb.NewAuthServiceClient(conn), authmw.IntrospectionConfig{})
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/whoami", authmw.Middleware(verifier, http.HandlerFunc(whoami)))
+
+	log.Printf("Consumer listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		log.Fatalf("Failed to serve: %v", err)
+	}
+}
+
+// whoami returns the authenticated caller
+func whoami(w http.ResponseWriter, r *http.Request) {
+	principal, _ := authmd.FromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": principal.UserID,
+		"org_id":  principal.OrgID,
+		"roles":   principal.Roles,
+		"admin":   principal.HasRole("admin"),
+	})
+}
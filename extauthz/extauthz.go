@@ -0,0 +1,156 @@
+// Package extauthz lets Envoy and nginx authenticate requests for backends
+// that can't use authmw, by asking this service before forwarding them.
+// Envoy's ext_authz filter calls Check over gRPC, or the HTTP handler as
+// an HTTP authorization service; nginx makes auth_request subrequests to
+// the HTTP handler.
+//
+// Requests with a valid session token are allowed and the caller's
+// principal is returned as the authmd headers, which the proxy passes to
+// the backend in place of any the client sent. Other requests are denied
+// with 401, or 403 for tokens scoped to other calls.
+package extauthz
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+
+	"user-management/auth"
+	"user-management/authmd"
+	"user-management/authz"
+)
+
+// Checker answers authorization checks from proxies. It serves the gRPC
+// envoy.service.auth.v3.Authorization service and, as an http.Handler,
+// HTTP checks on any path.
+type Checker struct {
+	authv3.UnimplementedAuthorizationServer
+	authorizer authz.Authorizer
+}
+
+// New returns a Checker authorizing requests with authorizer
+func New(authorizer authz.Authorizer) *Checker {
+	return &Checker{authorizer: authorizer}
+}
+
+// denial is why a request is not allowed
+type denial struct {
+	// status is the HTTP status the proxy answers the client with
+	status int
+	reason string
+	// challenge is the WWW-Authenticate header of the response
+	challenge string
+}
+
+// check authenticates the Authorization header value of a proxied request
+func (c *Checker) check(ctx context.Context, authorization string) (authmd.Principal, *denial) {
+	claims, err := c.authorizer.AuthenticateHeader(ctx, authorization)
+	if err != nil {
+		return authmd.Principal{}, deny(err)
+	}
+	principal, err := c.authorizer.PrincipalFor(ctx, claims)
+	if err != nil {
+		return authmd.Principal{}, deny(err)
+	}
+	return principal, nil
+}
+
+// deny converts an authentication failure to a denial, like the auth
+// interceptor converts it to a gRPC error
+func deny(err error) *denial {
+	switch {
+	case errors.Is(err, auth.ErrMissingToken):
+		return &denial{http.StatusUnauthorized, "missing bearer token", "Bearer"}
+	case errors.Is(err, auth.ErrTokenExpired):
+		return &denial{http.StatusUnauthorized, "token expired", `Bearer error="invalid_token"`}
+	case errors.Is(err, auth.ErrSessionIdle):
+		return &denial{http.StatusUnauthorized, "session expired after inactivity", `Bearer error="invalid_token"`}
+	case errors.Is(err, auth.ErrInvalidToken),
+		errors.Is(err, auth.ErrTokenBlacklisted),
+		errors.Is(err, auth.ErrTokenRevoked),
+		errors.Is(err, auth.ErrUnsupportedTokenVersion):
+		return &denial{http.StatusUnauthorized, "invalid token", `Bearer error="invalid_token"`}
+	case errors.Is(err, auth.ErrTokenScopeRestricted):
+		return &denial{http.StatusForbidden, "token does not allow this call", `Bearer error="insufficient_scope"`}
+	}
+	log.Printf("Failed to authenticate proxied request: %v", err)
+	return &denial{status: http.StatusServiceUnavailable, reason: "failed to authenticate request"}
+}
+
+// Check implements the gRPC ext_authz service. Envoy passes request
+// headers with lowercase names.
+func (c *Checker) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	headers := req.GetAttributes().GetRequest().GetHttp().GetHeaders()
+	principal, denied := c.check(ctx, headers["authorization"])
+	if denied != nil {
+		response := &authv3.DeniedHttpResponse{
+			Status: &typev3.HttpStatus{Code: typev3.StatusCode(denied.status)},
+			Body:   denied.reason,
+		}
+		if denied.challenge != "" {
+			response.Headers = []*corev3.HeaderValueOption{header("www-authenticate", denied.challenge)}
+		}
+		return &authv3.CheckResponse{
+			Status:       &rpcstatus.Status{Code: int32(grpcCode(denied.status)), Message: denied.reason},
+			HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: response},
+		}, nil
+	}
+
+	// Replace the principal headers of the client's request
+	response := &authv3.OkHttpResponse{}
+	md := principal.Metadata()
+	for key, values := range md {
+		response.Headers = append(response.Headers, header(key, values[0]))
+	}
+	for _, key := range authmd.Headers() {
+		if _, ok := md[key]; !ok {
+			response.HeadersToRemove = append(response.HeadersToRemove, key)
+		}
+	}
+	return &authv3.CheckResponse{
+		Status:       &rpcstatus.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: response},
+	}, nil
+}
+
+func header(key, value string) *corev3.HeaderValueOption {
+	return &corev3.HeaderValueOption{
+		Header:       &corev3.HeaderValue{Key: key, Value: value},
+		AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+	}
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	default:
+		return codes.Unavailable
+	}
+}
+
+// ServeHTTP answers an HTTP check with 200 and the principal headers, or
+// with the denial. The request method, path and body are ignored, so
+// proxies can pass the original request's.
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, denied := c.check(r.Context(), r.Header.Get("Authorization"))
+	if denied != nil {
+		if denied.challenge != "" {
+			w.Header().Set("WWW-Authenticate", denied.challenge)
+		}
+		http.Error(w, denied.reason, denied.status)
+		return
+	}
+
+	authmd.SetHTTPHeader(w.Header(), principal)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,51 @@
+// Package fips is the policy of FIPS mode, which restricts the server to
+// FIPS 140-3 approved algorithms. The algorithms themselves come from Go's
+// cryptographic module, which must run in FIPS 140-3 mode: built with
+// GOFIPS140=v1.0.0 or run with GODEBUG=fips140=on.
+package fips
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/fips140"
+	"crypto/rsa"
+	"crypto/tls"
+	"fmt"
+)
+
+// ModuleEnabled reports whether Go's cryptographic module runs in FIPS
+// 140-3 mode. It then also limits TLS 1.3 to approved cipher suites.
+func ModuleEnabled() bool {
+	return fips140.Enabled()
+}
+
+// TLSCipherSuites are the TLS 1.2 cipher suites allowed in FIPS mode
+var TLSCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// TLSCurves are the key exchange curves allowed in FIPS mode
+var TLSCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384}
+
+// CheckPublicKey checks that key is of an approved type and size: RSA of
+// at least 2048 bits, or ECDSA on P-256 or P-384
+func CheckPublicKey(key crypto.PublicKey) error {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return fmt.Errorf("%d-bit RSA keys are not FIPS approved, at least 2048 bits are needed", key.N.BitLen())
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() && key.Curve != elliptic.P384() {
+			return fmt.Errorf("ECDSA keys on %s are not FIPS approved, use P-256 or P-384", key.Curve.Params().Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%T keys are not allowed in FIPS mode", key)
+	}
+}
@@ -0,0 +1,96 @@
+// Package gateway serves the gRPC services as a REST/JSON API for clients
+// that can't speak gRPC. The handlers generated from proto/gateway.yaml
+// translate each HTTP request into a call to a gRPC server in the same
+// process, so REST calls run the same service implementations and
+// interceptors, authentication included, as gRPC calls.
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/textproto"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "user-management/proto"
+	"user-management/requestctx"
+)
+
+// bufferSize is the buffer of the in-memory connection to the gRPC server
+const bufferSize = 1 << 20
+
+// forwardedHeaders are passed to the gRPC server as metadata of the same
+// name, like gRPC clients and proxies send them. Authorization is always
+// passed, and the user agent as grpcgateway-user-agent.
+var forwardedHeaders = map[string]bool{
+	textproto.CanonicalMIMEHeaderKey(requestctx.HeaderRequestID):   true,
+	textproto.CanonicalMIMEHeaderKey(requestctx.HeaderTraceParent): true,
+	"X-Real-Ip": true,
+	"X-Api-Key": true,
+}
+
+// Connect serves server on an in-memory listener and returns a connection
+// to it for the gateway. Stopping server closes the listener.
+func Connect(server *grpc.Server) (*grpc.ClientConn, error) {
+	listener := bufconn.Listen(bufferSize)
+	go server.Serve(listener)
+
+	return grpc.NewClient("passthrough:///gateway",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+}
+
+// New returns the REST handler of the services, calling them through conn
+func New(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeader),
+		// Fields are named as in the proto files, like the rest of the docs
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+	)
+
+	registrations := []func(context.Context, *runtime.ServeMux, *grpc.ClientConn) error{
+		pb.RegisterAuthServiceHandler,
+		pb.RegisterUserServiceHandler,
+		pb.RegisterAdminServiceHandler,
+		pb.RegisterOrganizationServiceHandler,
+	}
+	for _, register := range registrations {
+		if err := register(ctx, mux, conn); err != nil {
+			return nil, err
+		}
+	}
+	return mux, nil
+}
+
+func incomingHeader(key string) (string, bool) {
+	if forwardedHeaders[key] {
+		return key, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// outgoingHeader returns the request ID the server sets in its own header
+// rather than as Grpc-Metadata-X-Request-Id
+func outgoingHeader(key string) (string, bool) {
+	if key == requestctx.HeaderRequestID {
+		return key, true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
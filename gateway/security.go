@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SecurityConfig controls the Strict-Transport-Security header of HTTPS
+// responses
+type SecurityConfig struct {
+	// HSTSMaxAge is how long browsers only connect over HTTPS after a
+	// response. Zero leaves the header out.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+}
+
+// securityHeaders are set on every response. The gateway only returns
+// JSON, so nothing it returns should be rendered, framed, cached or sniffed
+// as another type by a browser.
+var securityHeaders = map[string]string{
+	"Content-Security-Policy": "default-src 'none'; frame-ancestors 'none'",
+	"X-Content-Type-Options":  "nosniff",
+	"X-Frame-Options":         "DENY",
+	"Referrer-Policy":         "no-referrer",
+	"Cache-Control":           "no-store",
+}
+
+// Secure sets the standard security headers on the responses of handler,
+// and Strict-Transport-Security on those sent over HTTPS
+func Secure(handler http.Handler, config SecurityConfig) http.Handler {
+	hsts := ""
+	if config.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int64(config.HSTSMaxAge.Seconds()))
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		for name, value := range securityHeaders {
+			header.Set(name, value)
+		}
+		// Browsers ignore the header over plain HTTP
+		if hsts != "" && r.TLS != nil {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// RedirectToHTTPS redirects every request to the same URL over HTTPS on
+// httpsPort. Methods and bodies are kept, so clients that follow the
+// redirect don't turn a POST into a GET.
+func RedirectToHTTPS(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hostname := (&url.URL{Host: r.Host}).Hostname()
+		if hostname == "" {
+			http.Error(w, "missing Host header", http.StatusBadRequest)
+			return
+		}
+		host := net.JoinHostPort(hostname, httpsPort)
+		if httpsPort == "443" {
+			host = strings.TrimSuffix(host, ":443")
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,97 @@
+package hsm
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"user-management/metrics"
+)
+
+// probeMessage is signed by health checks. Its signatures are discarded.
+var probeMessage = []byte("user-management HSM health check")
+
+// HealthConfig controls how often the HSM is checked and when it is
+// reported as down
+type HealthConfig struct {
+	Interval time.Duration
+	Timeout  time.Duration
+	// FailureThreshold consecutive failed checks mark the HSM down. One
+	// successful check marks it up again.
+	FailureThreshold int
+}
+
+// HealthMonitor signs a probe message in the background and tracks
+// whether the HSM session can sign. The probes count towards the signing
+// latency alerts, so a degrading HSM is noticed without traffic.
+type HealthMonitor struct {
+	key      *Key
+	config   HealthConfig
+	onChange func(healthy bool)
+	healthy  atomic.Bool
+}
+
+// NewHealthMonitor returns a monitor that starts out healthy, since Open
+// has just found the key. onChange is called whenever that changes.
+func NewHealthMonitor(key *Key, config HealthConfig, onChange func(healthy bool)) *HealthMonitor {
+	m := &HealthMonitor{key: key, config: config, onChange: onChange}
+	m.healthy.Store(true)
+	metrics.HSMUp.Set(1)
+	return m
+}
+
+// Healthy reports whether the HSM signed the recent probes
+func (m *HealthMonitor) Healthy() bool {
+	return m.healthy.Load()
+}
+
+// Run checks the HSM until ctx is cancelled
+func (m *HealthMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		probeCtx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+		_, err := m.key.Sign(probeCtx, probeMessage)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			failures = 0
+			m.setHealthy(true)
+			continue
+		}
+		failures++
+		log.Printf("HSM health check failed (%d in a row): %v", failures, err)
+		if failures >= m.config.FailureThreshold {
+			m.setHealthy(false)
+		}
+	}
+}
+
+func (m *HealthMonitor) setHealthy(healthy bool) {
+	if m.healthy.Swap(healthy) == healthy {
+		return
+	}
+
+	if healthy {
+		metrics.HSMUp.Set(1)
+		log.Printf("HSM is signing again")
+	} else {
+		metrics.HSMUp.Set(0)
+		log.Printf("HSM can't sign, token issuance is failing")
+	}
+	if m.onChange != nil {
+		m.onChange(healthy)
+	}
+}
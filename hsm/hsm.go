@@ -0,0 +1,174 @@
+// Package hsm signs tokens with an RSA or ECDSA key held by a hardware
+// security module, reached through its PKCS#11 library, so the private key
+// never leaves the HSM. RSA keys sign RS256 and P-256 keys ES256
+// signatures. Verifying them only needs the public key, which is read once
+// when the key is opened.
+//
+// PKCS#11 libraries are loaded through cgo. Servers built without it
+// refuse to start when an HSM is configured.
+package hsm
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"user-management/alerts"
+	"user-management/metrics"
+)
+
+// JWS algorithms of the signatures
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+// Config names the PKCS#11 library, the token and the key pair in it
+type Config struct {
+	// Module is the path of the vendor's PKCS#11 library
+	Module     string
+	TokenLabel string
+	PIN        string
+	// KeyLabel is the CKA_LABEL of the key pair
+	KeyLabel string
+	// SlowSigning is the signing latency above which a signature counts
+	// as slow for alerting
+	SlowSigning time.Duration
+}
+
+// Key signs with a key pair in the HSM. It is safe for concurrent use:
+// signatures are spread over a pool of PKCS#11 sessions.
+type Key struct {
+	signer       crypto.Signer
+	closeSession func() error
+	algorithm    string
+	slowSigning  time.Duration
+	alerts       *alerts.Manager
+}
+
+// Open logs in to the token and finds the key pair. Slow and failed
+// signatures are recorded with alertManager.
+func Open(config Config, alertManager *alerts.Manager) (*Key, error) {
+	signer, closeSession, err := openPKCS11(config)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, err := algorithmOf(signer.Public())
+	if err != nil {
+		closeSession()
+		return nil, err
+	}
+
+	return &Key{
+		signer:       signer,
+		closeSession: closeSession,
+		algorithm:    algorithm,
+		slowSigning:  config.SlowSigning,
+		alerts:       alertManager,
+	}, nil
+}
+
+func algorithmOf(public crypto.PublicKey) (string, error) {
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < 2048 {
+			return "", fmt.Errorf("RSA signing key has %d bits, at least 2048 are needed", public.N.BitLen())
+		}
+		return AlgorithmRS256, nil
+	case *ecdsa.PublicKey:
+		if public.Curve != elliptic.P256() {
+			return "", fmt.Errorf("ECDSA signing key must be on the P-256 curve")
+		}
+		return AlgorithmES256, nil
+	default:
+		return "", fmt.Errorf("signing key must be an RSA or ECDSA key, not %T", public)
+	}
+}
+
+// Algorithm returns the JWS algorithm of the signatures
+func (k *Key) Algorithm() string {
+	return k.algorithm
+}
+
+// Public returns the public key verifying the signatures
+func (k *Key) Public() crypto.PublicKey {
+	return k.signer.Public()
+}
+
+// Sign returns the JWS signature of message. PKCS#11 calls can't be
+// cancelled: when ctx ends first, Sign returns and the call finishes in
+// the background.
+func (k *Key) Sign(ctx context.Context, message []byte) ([]byte, error) {
+	type result struct {
+		signature []byte
+		err       error
+	}
+	done := make(chan result, 1)
+	start := time.Now()
+	go func() {
+		signature, err := k.sign(message)
+		done <- result{signature, err}
+	}()
+
+	var r result
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		r.err = ctx.Err()
+	}
+
+	elapsed := time.Since(start)
+	metrics.TokenSigningDuration.Observe(elapsed.Seconds())
+	if r.err != nil {
+		metrics.TokenSigningFailures.Inc()
+		k.alerts.Record(alerts.EventSigningFailed)
+		return nil, r.err
+	}
+	if k.slowSigning > 0 && elapsed > k.slowSigning {
+		k.alerts.Record(alerts.EventSigningSlow)
+	}
+	return r.signature, nil
+}
+
+func (k *Key) sign(message []byte) ([]byte, error) {
+	digest := sha256.Sum256(message)
+	signature, err := k.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	if k.algorithm == AlgorithmES256 {
+		return rawECDSASignature(signature)
+	}
+	return signature, nil
+}
+
+// rawECDSASignature converts an ASN.1 ECDSA signature to the fixed-size
+// r || s form JWS uses
+func rawECDSASignature(der []byte) ([]byte, error) {
+	var signature struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(der, &signature)
+	if err != nil || len(rest) > 0 || signature.R.BitLen() > 256 || signature.S.BitLen() > 256 {
+		return nil, errors.New("HSM returned a malformed ECDSA signature")
+	}
+	raw := make([]byte, 64)
+	signature.R.FillBytes(raw[:32])
+	signature.S.FillBytes(raw[32:])
+	return raw, nil
+}
+
+// Close logs out of the token
+func (k *Key) Close() error {
+	return k.closeSession()
+}
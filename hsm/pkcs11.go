@@ -0,0 +1,33 @@
+//go:build cgo
+
+package hsm
+
+import (
+	"crypto"
+	"fmt"
+
+	"github.com/ThalesIgnite/crypto11"
+)
+
+// openPKCS11 logs in to the token and returns the key pair, with the
+// function logging out again
+func openPKCS11(config Config) (crypto.Signer, func() error, error) {
+	session, err := crypto11.Configure(&crypto11.Config{
+		Path:       config.Module,
+		TokenLabel: config.TokenLabel,
+		Pin:        config.PIN,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open PKCS#11 token %s: %v", config.TokenLabel, err)
+	}
+
+	signer, err := session.FindKeyPair(nil, []byte(config.KeyLabel))
+	if err == nil && signer == nil {
+		err = fmt.Errorf("no key pair labelled %s", config.KeyLabel)
+	}
+	if err != nil {
+		session.Close()
+		return nil, nil, fmt.Errorf("failed to find signing key: %v", err)
+	}
+	return signer, session.Close, nil
+}
@@ -0,0 +1,12 @@
+//go:build !cgo
+
+package hsm
+
+import (
+	"crypto"
+	"errors"
+)
+
+func openPKCS11(config Config) (crypto.Signer, func() error, error) {
+	return nil, nil, errors.New("PKCS#11 needs a server built with cgo")
+}
@@ -0,0 +1,221 @@
+// Package identity resolves who the caller of a request is. It turns the
+// bearer token or API key a request carries into an authmd.Principal with
+// the caller's organization and roles, and its interceptors put that
+// principal in the context of every call.
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/auth"
+	"user-management/authmd"
+	"user-management/database"
+	"user-management/models"
+)
+
+// Credentials checks the credentials callers present. auth.JWTService
+// implements it.
+type Credentials interface {
+	// AuthenticateContext validates the bearer token of an incoming
+	// request
+	AuthenticateContext(ctx context.Context) (*auth.JWTClaims, error)
+	// AuthenticateAPIKey returns the active user an API key belongs to
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.User, error)
+}
+
+// Resolver resolves the principals of callers
+type Resolver struct {
+	credentials Credentials
+	users       database.UserRepository
+}
+
+// NewResolver returns a Resolver that checks credentials with credentials
+// and loads the accounts they belong to from users
+func NewResolver(credentials Credentials, users database.UserRepository) *Resolver {
+	return &Resolver{credentials: credentials, users: users}
+}
+
+// PrincipalFor loads the organization and roles of the token's user, and
+// adds the session, scope and sign-in the token carries
+func (r *Resolver) PrincipalFor(ctx context.Context, claims *auth.JWTClaims) (authmd.Principal, error) {
+	userID, err := models.ParseID(claims.UserID)
+	if err != nil {
+		return authmd.Principal{}, auth.ErrInvalidToken
+	}
+
+	user, err := r.users.FindPrincipal(ctx, userID)
+	if err == database.ErrNotFound {
+		// The account was removed after the token was issued
+		return authmd.Principal{}, auth.ErrInvalidToken
+	} else if err != nil {
+		return authmd.Principal{}, fmt.Errorf("failed to load user: %v", err)
+	}
+
+	principal := userPrincipal(user)
+	principal.SessionID = claims.ID
+	principal.AuthMethod = claims.AuthMethod
+	if claims.Scope != "" {
+		principal.Scopes = []string{claims.Scope}
+	}
+	if claims.AuthTime != nil {
+		principal.AuthTime = claims.AuthTime.Time
+	}
+
+	return principal, nil
+}
+
+// APIKeyPrincipal returns the principal of the user an API key belongs to
+func (r *Resolver) APIKeyPrincipal(ctx context.Context, key string) (authmd.Principal, error) {
+	user, err := r.credentials.AuthenticateAPIKey(ctx, key)
+	if err != nil {
+		return authmd.Principal{}, err
+	}
+
+	// API keys have no session, and never count as a recent sign-in
+	principal := userPrincipal(user)
+	principal.AuthMethod = auth.AuthMethodAPIKey
+	return principal, nil
+}
+
+// userPrincipal returns the principal of user with their organization and
+// roles
+func userPrincipal(user *models.User) authmd.Principal {
+	principal := authmd.Principal{
+		UserID: user.ID.String(),
+		Roles:  append([]string{}, user.Roles...),
+	}
+	if user.OrgID != nil {
+		principal.OrgID = user.OrgID.Hex()
+		if user.OrgRole != "" {
+			principal.Roles = append(principal.Roles, authmd.OrgRolePrefix+user.OrgRole)
+		}
+	}
+	return principal
+}
+
+// UnaryServerInterceptor identifies callers that present a valid bearer
+// token or API key. Their principal is stored in the context and added to
+// its outgoing metadata, so calls handlers make to downstream services
+// carry it.
+// Calls to protectedMethods, by full name such as
+// "/user.UserService/GetProfile", are rejected with UNAUTHENTICATED unless
+// the credential is valid. Other requests without a valid credential pass
+// through unchanged and handlers decide whether they need one.
+func (r *Resolver) UnaryServerInterceptor(protectedMethods ...string) grpc.UnaryServerInterceptor {
+	protected := methodSet(protectedMethods)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := r.identify(ctx, info.FullMethod, protected[info.FullMethod])
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor identifies the callers of streaming RPCs like
+// UnaryServerInterceptor
+func (r *Resolver) StreamServerInterceptor(protectedMethods ...string) grpc.StreamServerInterceptor {
+	protected := methodSet(protectedMethods)
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := r.identify(stream.Context(), info.FullMethod, protected[info.FullMethod])
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// identify returns ctx with the principal of the caller's bearer token,
+// or of their API key when they send no token. A missing or invalid
+// credential leaves ctx unchanged, or fails the call to a protected method.
+func (r *Resolver) identify(ctx context.Context, method string, protected bool) (context.Context, error) {
+	claims, err := r.credentials.AuthenticateContext(ctx)
+	if errors.Is(err, auth.ErrMissingToken) {
+		if key, ok := auth.APIKeyFromContext(ctx); ok {
+			return r.identifyAPIKey(ctx, key, protected)
+		}
+	}
+	if err != nil {
+		if protected {
+			return nil, unauthenticated(err)
+		}
+		return ctx, nil
+	}
+
+	principal, err := r.PrincipalFor(ctx, claims)
+	if err != nil {
+		if protected {
+			return nil, unauthenticated(err)
+		}
+		log.Printf("Failed to load principal for %s: %v", method, err)
+		return ctx, nil
+	}
+
+	ctx = authmd.NewContext(ctx, principal)
+	return authmd.AppendToOutgoingContext(ctx, principal), nil
+}
+
+// identifyAPIKey is identify for callers that send an API key
+func (r *Resolver) identifyAPIKey(ctx context.Context, key string, protected bool) (context.Context, error) {
+	principal, err := r.APIKeyPrincipal(ctx, key)
+	if err != nil {
+		if protected {
+			return nil, unauthenticated(err)
+		}
+		return ctx, nil
+	}
+
+	ctx = authmd.NewContext(ctx, principal)
+	return authmd.AppendToOutgoingContext(ctx, principal), nil
+}
+
+// serverStream is a stream whose handler sees ctx
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// unauthenticated converts an authentication failure to a gRPC error.
+// Failures while checking the credential are not the caller's fault.
+func unauthenticated(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrMissingToken):
+		return status.Errorf(codes.Unauthenticated, "missing bearer token")
+	case errors.Is(err, auth.ErrTokenExpired):
+		return status.Errorf(codes.Unauthenticated, "token expired")
+	case errors.Is(err, auth.ErrSessionIdle):
+		return status.Errorf(codes.Unauthenticated, "session expired after inactivity")
+	case errors.Is(err, auth.ErrInvalidToken),
+		errors.Is(err, auth.ErrTokenBlacklisted),
+		errors.Is(err, auth.ErrTokenRevoked),
+		errors.Is(err, auth.ErrUnsupportedTokenVersion):
+		return status.Errorf(codes.Unauthenticated, "invalid token")
+	case errors.Is(err, auth.ErrInvalidAPIKey):
+		return status.Errorf(codes.Unauthenticated, "invalid API key")
+	case errors.Is(err, auth.ErrTokenScopeRestricted):
+		return status.Errorf(codes.PermissionDenied, "token does not allow this call")
+	}
+	log.Printf("Failed to authenticate request: %v", err)
+	return status.Errorf(codes.Internal, "failed to authenticate request")
+}
+
+func methodSet(methods []string) map[string]bool {
+	set := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		set[method] = true
+	}
+	return set
+}
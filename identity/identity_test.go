@@ -0,0 +1,353 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"user-management/auth"
+	"user-management/authmd"
+	"user-management/database"
+	"user-management/models"
+)
+
+// fakeCredentials accepts the token and API keys the test sets up
+type fakeCredentials struct {
+	claims   *auth.JWTClaims
+	tokenErr error
+	keys     map[string]*models.User
+	keyErr   error
+}
+
+func (f *fakeCredentials) AuthenticateContext(ctx context.Context) (*auth.JWTClaims, error) {
+	return f.claims, f.tokenErr
+}
+
+func (f *fakeCredentials) AuthenticateAPIKey(ctx context.Context, key string) (*models.User, error) {
+	if f.keyErr != nil {
+		return nil, f.keyErr
+	}
+	user, ok := f.keys[key]
+	if !ok {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	return user, nil
+}
+
+// failingUsers is a user repository that can't be reached
+type failingUsers struct {
+	database.UserRepository
+}
+
+func (failingUsers) FindPrincipal(ctx context.Context, id models.ID) (*models.User, error) {
+	return nil, errors.New("connection refused")
+}
+
+func newUser(t *testing.T, users database.UserRepository, user models.User) models.User {
+	t.Helper()
+	user.ID = models.ID(primitive.NewObjectID().Hex())
+	user.Email = user.ID.String() + "@example.com"
+	if err := users.Insert(context.Background(), user); err != nil {
+		t.Fatalf("Insert() = %v", err)
+	}
+	return user
+}
+
+func TestPrincipalFor(t *testing.T) {
+	users := database.NewMemoryRepositories().Users
+	orgID := primitive.NewObjectID()
+	member := newUser(t, users, models.User{IsActive: true, Roles: []string{models.RoleAdmin}, OrgID: &orgID, OrgRole: models.OrgRoleMember})
+	loner := newUser(t, users, models.User{IsActive: true})
+	authTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		claims  *auth.JWTClaims
+		want    authmd.Principal
+		wantErr error
+	}{
+		{
+			name: "organization and roles",
+			claims: &auth.JWTClaims{
+				UserID:           member.ID.String(),
+				AuthMethod:       "password",
+				AuthTime:         jwt.NewNumericDate(authTime),
+				RegisteredClaims: jwt.RegisteredClaims{ID: "session-1"},
+			},
+			want: authmd.Principal{
+				UserID:     member.ID.String(),
+				OrgID:      orgID.Hex(),
+				Roles:      []string{models.RoleAdmin, authmd.OrgRolePrefix + models.OrgRoleMember},
+				SessionID:  "session-1",
+				AuthMethod: "password",
+				AuthTime:   authTime,
+			},
+		},
+		{
+			name:   "scoped token",
+			claims: &auth.JWTClaims{UserID: loner.ID.String(), Scope: auth.ScopeTwoFactorEnrollment},
+			want: authmd.Principal{
+				UserID: loner.ID.String(),
+				Roles:  []string{},
+				Scopes: []string{auth.ScopeTwoFactorEnrollment},
+			},
+		},
+		{
+			name:    "removed user",
+			claims:  &auth.JWTClaims{UserID: primitive.NewObjectID().Hex()},
+			wantErr: auth.ErrInvalidToken,
+		},
+		{
+			name:    "malformed user ID",
+			claims:  &auth.JWTClaims{UserID: "not-an-id"},
+			wantErr: auth.ErrInvalidToken,
+		},
+	}
+
+	resolver := NewResolver(&fakeCredentials{}, users)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.PrincipalFor(context.Background(), tt.claims)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PrincipalFor() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PrincipalFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const (
+		protectedMethod = "/user.UserService/GetProfile"
+		openMethod      = "/user.AuthService/Login"
+	)
+	users := database.NewMemoryRepositories().Users
+	user := newUser(t, users, models.User{IsActive: true})
+	sessionClaims := &auth.JWTClaims{
+		UserID:           user.ID.String(),
+		AuthMethod:       "password",
+		RegisteredClaims: jwt.RegisteredClaims{ID: "session-1"},
+	}
+	keys := map[string]*models.User{"umk_valid": &user}
+
+	tests := []struct {
+		name        string
+		credentials *fakeCredentials
+		users       database.UserRepository
+		apiKey      string
+		method      string
+		wantCode    codes.Code
+		wantMessage string
+		// wantAuthMethod is the auth method of the principal the handler
+		// sees, empty when it sees none
+		wantAuthMethod string
+	}{
+		{
+			name:           "valid token",
+			credentials:    &fakeCredentials{claims: sessionClaims},
+			method:         protectedMethod,
+			wantAuthMethod: "password",
+		},
+		{
+			name:           "token wins over an API key",
+			credentials:    &fakeCredentials{claims: sessionClaims, keys: keys},
+			apiKey:         "umk_valid",
+			method:         protectedMethod,
+			wantAuthMethod: "password",
+		},
+		{
+			name:           "API key without a token",
+			credentials:    &fakeCredentials{tokenErr: auth.ErrMissingToken, keys: keys},
+			apiKey:         "umk_valid",
+			method:         protectedMethod,
+			wantAuthMethod: auth.AuthMethodAPIKey,
+		},
+		{
+			name:        "invalid API key",
+			credentials: &fakeCredentials{tokenErr: auth.ErrMissingToken, keys: keys},
+			apiKey:      "umk_unknown",
+			method:      protectedMethod,
+			wantCode:    codes.Unauthenticated,
+			wantMessage: "invalid API key",
+		},
+		{
+			name:        "invalid API key to an open method",
+			credentials: &fakeCredentials{tokenErr: auth.ErrMissingToken, keys: keys},
+			apiKey:      "umk_unknown",
+			method:      openMethod,
+		},
+		{
+			name:        "missing token",
+			credentials: &fakeCredentials{tokenErr: auth.ErrMissingToken},
+			method:      protectedMethod,
+			wantCode:    codes.Unauthenticated,
+			wantMessage: "missing bearer token",
+		},
+		{
+			name:        "missing token to an open method",
+			credentials: &fakeCredentials{tokenErr: auth.ErrMissingToken},
+			method:      openMethod,
+		},
+		{
+			name:        "expired token",
+			credentials: &fakeCredentials{tokenErr: auth.ErrTokenExpired},
+			method:      protectedMethod,
+			wantCode:    codes.Unauthenticated,
+			wantMessage: "token expired",
+		},
+		{
+			name:        "revoked token",
+			credentials: &fakeCredentials{tokenErr: auth.ErrTokenRevoked},
+			method:      protectedMethod,
+			wantCode:    codes.Unauthenticated,
+			wantMessage: "invalid token",
+		},
+		{
+			name:        "scoped token",
+			credentials: &fakeCredentials{tokenErr: auth.ErrTokenScopeRestricted},
+			method:      protectedMethod,
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "token of a removed user",
+			credentials: &fakeCredentials{claims: &auth.JWTClaims{UserID: primitive.NewObjectID().Hex()}},
+			method:      protectedMethod,
+			wantCode:    codes.Unauthenticated,
+			wantMessage: "invalid token",
+		},
+		{
+			name:        "users can't be loaded",
+			credentials: &fakeCredentials{claims: sessionClaims},
+			users:       failingUsers{},
+			method:      protectedMethod,
+			wantCode:    codes.Internal,
+		},
+		{
+			name:        "users can't be loaded for an open method",
+			credentials: &fakeCredentials{claims: sessionClaims},
+			users:       failingUsers{},
+			method:      openMethod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := tt.users
+			if repository == nil {
+				repository = users
+			}
+			interceptor := NewResolver(tt.credentials, repository).UnaryServerInterceptor(protectedMethod)
+
+			ctx := context.Background()
+			if tt.apiKey != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(auth.HeaderAPIKey, tt.apiKey))
+			}
+			var seen *authmd.Principal
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if principal, ok := authmd.FromContext(ctx); ok {
+					seen = &principal
+				}
+				return nil, nil
+			}
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("interceptor returned %v, want code %v", err, tt.wantCode)
+			}
+			if tt.wantMessage != "" && status.Convert(err).Message() != tt.wantMessage {
+				t.Errorf("interceptor returned %q, want %q", status.Convert(err).Message(), tt.wantMessage)
+			}
+			if err != nil {
+				return
+			}
+
+			switch {
+			case tt.wantAuthMethod == "" && seen != nil:
+				t.Errorf("handler saw principal %+v, want none", *seen)
+			case tt.wantAuthMethod != "" && seen == nil:
+				t.Errorf("handler saw no principal")
+			case tt.wantAuthMethod != "" && (seen.UserID != user.ID.String() || seen.AuthMethod != tt.wantAuthMethod):
+				t.Errorf("handler saw principal %+v, want user %s by %s", *seen, user.ID, tt.wantAuthMethod)
+			}
+		})
+	}
+}
+
+// contextStream is a server stream with a context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	const method = "/user.UserService/WatchSession"
+	users := database.NewMemoryRepositories().Users
+	user := newUser(t, users, models.User{IsActive: true})
+
+	t.Run("identified caller", func(t *testing.T) {
+		resolver := NewResolver(&fakeCredentials{claims: &auth.JWTClaims{UserID: user.ID.String()}}, users)
+		stream := &contextStream{ctx: context.Background()}
+		err := resolver.StreamServerInterceptor(method)(nil, stream, &grpc.StreamServerInfo{FullMethod: method}, func(srv interface{}, stream grpc.ServerStream) error {
+			principal, ok := authmd.FromContext(stream.Context())
+			if !ok || principal.UserID != user.ID.String() {
+				t.Errorf("handler saw principal %+v, want user %s", principal, user.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("interceptor returned %v", err)
+		}
+	})
+
+	t.Run("protected without a token", func(t *testing.T) {
+		resolver := NewResolver(&fakeCredentials{tokenErr: auth.ErrMissingToken}, users)
+		stream := &contextStream{ctx: context.Background()}
+		err := resolver.StreamServerInterceptor(method)(nil, stream, &grpc.StreamServerInfo{FullMethod: method}, func(srv interface{}, stream grpc.ServerStream) error {
+			t.Error("handler was called")
+			return nil
+		})
+		if code := status.Code(err); code != codes.Unauthenticated {
+			t.Errorf("interceptor returned %v, want code %v", err, codes.Unauthenticated)
+		}
+	})
+}
+
+func TestAPIKeyPrincipal(t *testing.T) {
+	users := database.NewMemoryRepositories().Users
+	orgID := primitive.NewObjectID()
+	user := newUser(t, users, models.User{IsActive: true, OrgID: &orgID, OrgRole: models.OrgRoleAdmin})
+	resolver := NewResolver(&fakeCredentials{keys: map[string]*models.User{"umk_valid": &user}}, users)
+
+	principal, err := resolver.APIKeyPrincipal(context.Background(), "umk_valid")
+	if err != nil {
+		t.Fatalf("APIKeyPrincipal() = %v", err)
+	}
+	want := authmd.Principal{
+		UserID:     user.ID.String(),
+		OrgID:      orgID.Hex(),
+		Roles:      []string{authmd.OrgRolePrefix + models.OrgRoleAdmin},
+		AuthMethod: auth.AuthMethodAPIKey,
+	}
+	if !reflect.DeepEqual(principal, want) {
+		t.Errorf("APIKeyPrincipal() = %+v, want %+v", principal, want)
+	}
+
+	if _, err := resolver.APIKeyPrincipal(context.Background(), "umk_unknown"); !errors.Is(err, auth.ErrInvalidAPIKey) {
+		t.Errorf("APIKeyPrincipal() of an unknown key = %v, want %v", err, auth.ErrInvalidAPIKey)
+	}
+}
@@ -0,0 +1,80 @@
+package importers
+
+import (
+	"io"
+
+	pb "user-management/proto"
+)
+
+// Auth0System is the external ID system of users imported from Auth0
+const Auth0System = "auth0"
+
+// auth0User is a user from an Auth0 user export job, or from the password
+// hash export that Auth0 support provides
+type auth0User struct {
+	UserID        string `json:"user_id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Blocked       bool   `json:"blocked"`
+	// PasswordHash is the bcrypt hash from the password hash export
+	PasswordHash       string `json:"passwordHash"`
+	CustomPasswordHash *struct {
+		Algorithm string `json:"algorithm"`
+		Hash      struct {
+			Value string `json:"value"`
+		} `json:"hash"`
+	} `json:"custom_password_hash"`
+	Identities []struct {
+		Provider string         `json:"provider"`
+		UserID   flexibleString `json:"user_id"`
+		IsSocial bool           `json:"isSocial"`
+	} `json:"identities"`
+}
+
+// Auth0 reads an Auth0 user export, as newline-delimited JSON or a JSON
+// array. Social identities become external IDs named after their provider,
+// such as "google-oauth2", and the Auth0 user ID is kept under "auth0".
+func Auth0(r io.Reader) (*Result, error) {
+	result := &Result{}
+	err := decodeRecords(r, func(u *auth0User) {
+		if u.Email == "" {
+			result.skip(u.UserID, "", "no email address")
+			return
+		}
+
+		passwordHash := u.PasswordHash
+		if u.CustomPasswordHash != nil {
+			switch u.CustomPasswordHash.Algorithm {
+			case "bcrypt", "argon2", "pbkdf2":
+				passwordHash = u.CustomPasswordHash.Hash.Value
+			default:
+				result.skip(u.UserID, u.Email, "unsupported password hash algorithm "+u.CustomPasswordHash.Algorithm)
+				return
+			}
+		}
+
+		externalIDs := map[string]string{}
+		if u.UserID != "" {
+			externalIDs[Auth0System] = u.UserID
+		}
+		for _, identity := range u.Identities {
+			if identity.IsSocial && identity.UserID != "" {
+				externalIDs[socialSystem(identity.Provider)] = string(identity.UserID)
+			}
+		}
+
+		result.add(u.UserID, &pb.ImportUser{
+			Email:         u.Email,
+			Name:          u.Name,
+			PasswordHash:  passwordHash,
+			ExternalIds:   externalIDs,
+			Disabled:      u.Blocked,
+			EmailVerified: u.EmailVerified,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
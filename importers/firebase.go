@@ -0,0 +1,89 @@
+package importers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	pb "user-management/proto"
+)
+
+// FirebaseSystem is the external ID system of users imported from Firebase
+const FirebaseSystem = "firebase"
+
+// ErrMissingFirebaseHashConfig is returned when an export holds password
+// hashes but the project's hash parameters were not given
+var ErrMissingFirebaseHashConfig = errors.New("firebase password hash parameters are required to import passwords")
+
+// FirebaseHashConfig holds the project's password hash parameters, shown
+// in the Firebase console under Authentication > Users > Password hash
+// parameters
+type FirebaseHashConfig struct {
+	SignerKey     string
+	SaltSeparator string
+	Rounds        int
+	MemCost       int
+}
+
+type firebaseUser struct {
+	LocalID          string `json:"localId"`
+	Email            string `json:"email"`
+	EmailVerified    bool   `json:"emailVerified"`
+	DisplayName      string `json:"displayName"`
+	PasswordHash     string `json:"passwordHash"`
+	Salt             string `json:"salt"`
+	Disabled         bool   `json:"disabled"`
+	ProviderUserInfo []struct {
+		ProviderID string `json:"providerId"`
+		RawID      string `json:"rawId"`
+	} `json:"providerUserInfo"`
+}
+
+// Firebase reads the output of `firebase auth:export --format=json`.
+// Sign-in providers such as google.com become external IDs named "google",
+// and the Firebase UID is kept under "firebase".
+func Firebase(r io.Reader, hashConfig FirebaseHashConfig) (*Result, error) {
+	var export struct {
+		Users []firebaseUser `json:"users"`
+	}
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to parse firebase export: %v", err)
+	}
+
+	result := &Result{}
+	for _, u := range export.Users {
+		if u.Email == "" {
+			result.skip(u.LocalID, "", "no email address")
+			continue
+		}
+
+		var passwordHash string
+		if u.PasswordHash != "" {
+			if hashConfig.SignerKey == "" {
+				return nil, ErrMissingFirebaseHashConfig
+			}
+			passwordHash = fmt.Sprintf("$firebase-scrypt$m=%d,r=%d$%s$%s$%s$%s",
+				hashConfig.MemCost, hashConfig.Rounds, hashConfig.SignerKey, hashConfig.SaltSeparator, u.Salt, u.PasswordHash)
+		}
+
+		externalIDs := map[string]string{FirebaseSystem: u.LocalID}
+		for _, provider := range u.ProviderUserInfo {
+			// Email and phone sign-in are not external identities
+			if provider.ProviderID == "password" || provider.ProviderID == "phone" || provider.RawID == "" {
+				continue
+			}
+			externalIDs[socialSystem(provider.ProviderID)] = provider.RawID
+		}
+
+		result.add(u.LocalID, &pb.ImportUser{
+			Email:         u.Email,
+			Name:          u.DisplayName,
+			PasswordHash:  passwordHash,
+			ExternalIds:   externalIDs,
+			Disabled:      u.Disabled,
+			EmailVerified: u.EmailVerified,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,134 @@
+// Package importers converts user exports from other auth providers into
+// the users of an ImportUsers request.
+package importers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "user-management/proto"
+)
+
+// Skipped is a source record that was not imported
+type Skipped struct {
+	// SourceID is the record's ID at the provider, empty for native files
+	SourceID string `json:"source_id,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// Result holds the converted users in source order and the records that
+// could not be converted
+type Result struct {
+	Users []*pb.ImportUser
+	// SourceIDs holds the provider's ID of each user in Users
+	SourceIDs []string
+	Skipped   []Skipped
+}
+
+func (r *Result) add(sourceID string, user *pb.ImportUser) {
+	r.Users = append(r.Users, user)
+	r.SourceIDs = append(r.SourceIDs, sourceID)
+}
+
+func (r *Result) skip(sourceID, email, reason string) {
+	r.Skipped = append(r.Skipped, Skipped{SourceID: sourceID, Email: email, Reason: reason})
+}
+
+// SourceID returns the provider's ID of Users[i], or "" if unknown
+func (r *Result) SourceID(i int) string {
+	if i < 0 || i >= len(r.SourceIDs) {
+		return ""
+	}
+	return r.SourceIDs[i]
+}
+
+// Native reads a file in the form {"users": [ImportUser, ...]}
+func Native(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req pb.ImportUsersRequest
+	if err := protojson.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("failed to parse users: %v", err)
+	}
+
+	return &Result{Users: req.Users}, nil
+}
+
+var invalidSystemChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// socialSystem turns a provider name such as "google.com" or
+// "google-oauth2" into an external ID system name
+func socialSystem(provider string) string {
+	system := strings.TrimSuffix(strings.ToLower(provider), ".com")
+	return strings.Trim(invalidSystemChars.ReplaceAllString(system, "-"), "-")
+}
+
+// decodeRecords reads a JSON array of records or newline-delimited JSON,
+// calling fn for each record in order
+func decodeRecords[T any](r io.Reader, fn func(*T)) error {
+	br := bufio.NewReader(r)
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+
+	for line := 1; dec.More(); line++ {
+		var record T
+		if err := dec.Decode(&record); err != nil {
+			return fmt.Errorf("failed to parse record %d: %v", line, err)
+		}
+		fn(&record)
+	}
+	return nil
+}
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.ContainsAny(b, " \t\r\n") {
+			return b[0], nil
+		}
+		br.ReadByte()
+	}
+}
+
+// flexibleString decodes a JSON string or number, since some providers
+// send numeric user IDs
+type flexibleString string
+
+func (s *flexibleString) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*s = flexibleString(str)
+		return nil
+	}
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err != nil {
+		return err
+	}
+	*s = flexibleString(num.String())
+	return nil
+}
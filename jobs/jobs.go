@@ -0,0 +1,90 @@
+// Package jobs runs the server's periodic cleanup tasks. Each job runs on
+// its own interval, and every run is logged and counted in metrics.
+//
+// Every replica runs the jobs. Jobs must be safe to run concurrently,
+// which deleting whatever has expired is.
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"user-management/metrics"
+)
+
+// Job is a task run every Interval
+type Job struct {
+	// Name labels the job in logs and metrics, such as "login_attempts"
+	Name     string
+	Interval time.Duration
+	// Run does the work once and returns how many records it deleted or
+	// purged
+	Run func(ctx context.Context) (int, error)
+}
+
+// Runner runs jobs until its context is cancelled
+type Runner struct {
+	jobs []Job
+}
+
+func NewRunner(jobs ...Job) *Runner {
+	return &Runner{jobs: jobs}
+}
+
+// Add schedules another job. It must be called before Run.
+func (r *Runner) Add(job Job) {
+	r.jobs = append(r.jobs, job)
+}
+
+// Run runs every job right away and then every interval, until ctx is
+// cancelled. It returns once all runs in progress have finished.
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range r.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			r.schedule(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+func (r *Runner) schedule(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce runs job and records the outcome
+func runOnce(ctx context.Context, job Job) {
+	start := time.Now()
+	processed, err := job.Run(ctx)
+	metrics.JobDuration.WithLabelValues(job.Name).Observe(time.Since(start).Seconds())
+	if processed > 0 {
+		metrics.JobRecords.WithLabelValues(job.Name).Add(float64(processed))
+		log.Printf("Job %s processed %d records", job.Name, processed)
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		metrics.JobRuns.WithLabelValues(job.Name, "failure").Inc()
+		log.Printf("Job %s failed: %v", job.Name, err)
+		return
+	}
+	metrics.JobRuns.WithLabelValues(job.Name, "success").Inc()
+	metrics.JobLastSuccess.WithLabelValues(job.Name).SetToCurrentTime()
+}
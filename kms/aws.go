@@ -0,0 +1,98 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// awsContextKey is the encryption context key associated data is passed
+// as. AWS KMS logs it in CloudTrail, so it never holds secrets.
+const awsContextKey = "purpose"
+
+// awsKey is a symmetric encryption or HMAC key in AWS KMS
+type awsKey struct {
+	uri    string
+	keyID  string
+	client *kms.Client
+}
+
+func newAWSKey(ctx context.Context, uri, keyID string) (*awsKey, error) {
+	var options []func(*config.LoadOptions) error
+	// Keys named by ARN are used in their own region
+	if arn := strings.Split(keyID, ":"); len(arn) > 3 && arn[0] == "arn" {
+		options = append(options, config.WithRegion(arn[3]))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %v", err)
+	}
+	return &awsKey{
+		uri:    uri,
+		keyID:  keyID,
+		client: kms.NewFromConfig(cfg),
+	}, nil
+}
+
+func (k *awsKey) URI() string {
+	return k.uri
+}
+
+func (k *awsKey) Encrypt(ctx context.Context, plaintext []byte, associatedData string) ([]byte, error) {
+	out, err := k.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:             aws.String(k.keyID),
+		Plaintext:         plaintext,
+		EncryptionContext: map[string]string{awsContextKey: associatedData},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AWS KMS failed to encrypt: %v", err)
+	}
+	return out.CiphertextBlob, nil
+}
+
+func (k *awsKey) Decrypt(ctx context.Context, ciphertext []byte, associatedData string) ([]byte, error) {
+	out, err := k.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:             aws.String(k.keyID),
+		CiphertextBlob:    ciphertext,
+		EncryptionContext: map[string]string{awsContextKey: associatedData},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AWS KMS failed to decrypt: %v", err)
+	}
+	return out.Plaintext, nil
+}
+
+func (k *awsKey) Sign(ctx context.Context, message []byte) ([]byte, error) {
+	out, err := k.client.GenerateMac(ctx, &kms.GenerateMacInput{
+		KeyId:        aws.String(k.keyID),
+		MacAlgorithm: types.MacAlgorithmSpecHmacSha256,
+		Message:      message,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AWS KMS failed to sign: %v", err)
+	}
+	return out.Mac, nil
+}
+
+func (k *awsKey) Verify(ctx context.Context, message, mac []byte) (bool, error) {
+	out, err := k.client.VerifyMac(ctx, &kms.VerifyMacInput{
+		KeyId:        aws.String(k.keyID),
+		MacAlgorithm: types.MacAlgorithmSpecHmacSha256,
+		Message:      message,
+		Mac:          mac,
+	})
+	var invalid *types.KMSInvalidMacException
+	if errors.As(err, &invalid) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("AWS KMS failed to verify: %v", err)
+	}
+	return out.MacValid, nil
+}
@@ -0,0 +1,79 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+
+	gcpkms "cloud.google.com/go/kms/apiv1"
+	"cloud.google.com/go/kms/apiv1/kmspb"
+)
+
+// gcpKey is a symmetric encryption key or an HMAC key version in Cloud KMS
+type gcpKey struct {
+	uri    string
+	name   string
+	client *gcpkms.KeyManagementClient
+}
+
+func newGCPKey(ctx context.Context, uri, name string) (*gcpKey, error) {
+	client, err := gcpkms.NewKeyManagementClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Cloud KMS: %v", err)
+	}
+	return &gcpKey{
+		uri:    uri,
+		name:   name,
+		client: client,
+	}, nil
+}
+
+func (k *gcpKey) URI() string {
+	return k.uri
+}
+
+func (k *gcpKey) Encrypt(ctx context.Context, plaintext []byte, associatedData string) ([]byte, error) {
+	resp, err := k.client.Encrypt(ctx, &kmspb.EncryptRequest{
+		Name:                        k.name,
+		Plaintext:                   plaintext,
+		AdditionalAuthenticatedData: []byte(associatedData),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Cloud KMS failed to encrypt: %v", err)
+	}
+	return resp.Ciphertext, nil
+}
+
+func (k *gcpKey) Decrypt(ctx context.Context, ciphertext []byte, associatedData string) ([]byte, error) {
+	resp, err := k.client.Decrypt(ctx, &kmspb.DecryptRequest{
+		Name:                        k.name,
+		Ciphertext:                  ciphertext,
+		AdditionalAuthenticatedData: []byte(associatedData),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Cloud KMS failed to decrypt: %v", err)
+	}
+	return resp.Plaintext, nil
+}
+
+func (k *gcpKey) Sign(ctx context.Context, message []byte) ([]byte, error) {
+	resp, err := k.client.MacSign(ctx, &kmspb.MacSignRequest{
+		Name: k.name,
+		Data: message,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Cloud KMS failed to sign: %v", err)
+	}
+	return resp.Mac, nil
+}
+
+func (k *gcpKey) Verify(ctx context.Context, message, mac []byte) (bool, error) {
+	resp, err := k.client.MacVerify(ctx, &kmspb.MacVerifyRequest{
+		Name: k.name,
+		Data: message,
+		Mac:  mac,
+	})
+	if err != nil {
+		return false, fmt.Errorf("Cloud KMS failed to verify: %v", err)
+	}
+	return resp.Success, nil
+}
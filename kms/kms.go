@@ -0,0 +1,115 @@
+// Package kms uses keys held by a key management service, so the keys
+// protecting tokens and personal data never exist in plaintext outside
+// it. Keys are named by URI:
+//
+//	awskms://arn:aws:kms:eu-west-1:111122223333:key/1234abcd-...
+//	awskms://alias/user-management
+//	gcpkms://projects/p/locations/l/keyRings/r/cryptoKeys/k
+//	hashivault://transit/user-management
+//
+// AWS KMS and Cloud KMS find their credentials and region the way their
+// SDKs do by default. Vault is reached at VAULT_ADDR with VAULT_TOKEN,
+// using the transit secrets engine mounted at the URI's first segment.
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Schemes of key URIs
+const (
+	SchemeAWS   = "awskms://"
+	SchemeGCP   = "gcpkms://"
+	SchemeVault = "hashivault://"
+)
+
+// Key encrypts small values, such as other keys, with a key that never
+// leaves the key management service
+type Key interface {
+	// URI names the key
+	URI() string
+	// Encrypt encrypts plaintext, binding it to associatedData, which must
+	// be passed again to decrypt it
+	Encrypt(ctx context.Context, plaintext []byte, associatedData string) ([]byte, error)
+	Decrypt(ctx context.Context, ciphertext []byte, associatedData string) ([]byte, error)
+}
+
+// MACKey computes HMAC-SHA256 codes with a key that never leaves the key
+// management service
+type MACKey interface {
+	URI() string
+	Sign(ctx context.Context, message []byte) ([]byte, error)
+	// Verify reports whether mac was computed over message with the key
+	Verify(ctx context.Context, message, mac []byte) (bool, error)
+}
+
+// ErrUnknownScheme is returned for URIs of no supported service
+var ErrUnknownScheme = errors.New("key URI must start with " + SchemeAWS + ", " + SchemeGCP + " or " + SchemeVault)
+
+// ValidateURI checks that uri names a key of a supported service, without
+// contacting it
+func ValidateURI(uri string) error {
+	_, _, err := parseURI(uri)
+	return err
+}
+
+// IsURI reports whether value has the scheme of a key URI, so it is meant
+// to name a key rather than be one
+func IsURI(value string) bool {
+	return ValidateURI(value) != ErrUnknownScheme
+}
+
+// Open returns the encryption key named by uri
+func Open(ctx context.Context, uri string) (Key, error) {
+	scheme, name, err := parseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	switch scheme {
+	case SchemeAWS:
+		return newAWSKey(ctx, uri, name)
+	case SchemeGCP:
+		return newGCPKey(ctx, uri, name)
+	default:
+		return newVaultKey(uri, name)
+	}
+}
+
+// OpenMAC returns the HMAC key named by uri. Cloud KMS MAC keys are named
+// down to their version, such as .../cryptoKeys/k/cryptoKeyVersions/1.
+func OpenMAC(ctx context.Context, uri string) (MACKey, error) {
+	scheme, name, err := parseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	switch scheme {
+	case SchemeAWS:
+		return newAWSKey(ctx, uri, name)
+	case SchemeGCP:
+		if !strings.Contains(name, "/cryptoKeyVersions/") {
+			return nil, fmt.Errorf("Cloud KMS MAC key %s must name a key version", uri)
+		}
+		return newGCPKey(ctx, uri, name)
+	default:
+		return newVaultKey(uri, name)
+	}
+}
+
+// parseURI splits uri into its scheme and the service's name of the key
+func parseURI(uri string) (scheme, name string, err error) {
+	for _, scheme := range []string{SchemeAWS, SchemeGCP, SchemeVault} {
+		if name, ok := strings.CutPrefix(uri, scheme); ok {
+			if name == "" {
+				return "", "", fmt.Errorf("key URI %s has no key name", uri)
+			}
+			if scheme == SchemeVault && strings.Count(name, "/") != 1 {
+				return "", "", fmt.Errorf("Vault key URI %s must be %s<mount>/<key>", uri, SchemeVault)
+			}
+			return scheme, name, nil
+		}
+	}
+	return "", "", ErrUnknownScheme
+}
@@ -0,0 +1,48 @@
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// EncryptedSecretPrefix marks configuration secrets encrypted with a key,
+// which the server decrypts in memory at startup
+const EncryptedSecretPrefix = "kms:"
+
+// EncryptSecret encrypts value for the configuration secret name, such as
+// JWT_SECRET. It can't be decrypted for another secret.
+func EncryptSecret(ctx context.Context, key Key, name, value string) (string, error) {
+	ciphertext, err := key.Encrypt(ctx, []byte(value), secretAssociatedData(name))
+	if err != nil {
+		return "", err
+	}
+	return EncryptedSecretPrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// IsEncryptedSecret reports whether value was written by EncryptSecret
+func IsEncryptedSecret(value string) bool {
+	return strings.HasPrefix(value, EncryptedSecretPrefix)
+}
+
+// DecryptSecret reverses EncryptSecret
+func DecryptSecret(ctx context.Context, key Key, name, value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, EncryptedSecretPrefix)
+	if !ok {
+		return "", fmt.Errorf("secret is not encrypted")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("encrypted secret is not base64")
+	}
+	plaintext, err := key.Decrypt(ctx, ciphertext, secretAssociatedData(name))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func secretAssociatedData(name string) string {
+	return "config secret " + name
+}
@@ -0,0 +1,114 @@
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// vaultPrefix starts Vault's ciphertexts and HMACs, ahead of the version
+// of the key that made them
+const vaultPrefix = "vault:"
+
+// vaultKey is a key of Vault's transit secrets engine. Its ciphertexts
+// and HMACs are kept in Vault's format, which names the key version, so
+// values made before the key is rotated can still be used.
+type vaultKey struct {
+	uri    string
+	mount  string
+	name   string
+	client *vault.Client
+}
+
+func newVaultKey(uri, path string) (*vaultKey, error) {
+	// Reads VAULT_ADDR, VAULT_TOKEN and the other VAULT_ variables
+	client, err := vault.NewClient(vault.DefaultConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vault client: %v", err)
+	}
+	mount, name, _ := strings.Cut(path, "/")
+	return &vaultKey{
+		uri:    uri,
+		mount:  mount,
+		name:   name,
+		client: client,
+	}, nil
+}
+
+func (k *vaultKey) URI() string {
+	return k.uri
+}
+
+func (k *vaultKey) Encrypt(ctx context.Context, plaintext []byte, associatedData string) ([]byte, error) {
+	data, err := k.write(ctx, "encrypt/"+k.name, map[string]interface{}{
+		"plaintext":       base64.StdEncoding.EncodeToString(plaintext),
+		"associated_data": base64.StdEncoding.EncodeToString([]byte(associatedData)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Vault failed to encrypt: %v", err)
+	}
+	ciphertext, ok := data["ciphertext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("Vault returned no ciphertext")
+	}
+	return []byte(ciphertext), nil
+}
+
+func (k *vaultKey) Decrypt(ctx context.Context, ciphertext []byte, associatedData string) ([]byte, error) {
+	data, err := k.write(ctx, "decrypt/"+k.name, map[string]interface{}{
+		"ciphertext":      string(ciphertext),
+		"associated_data": base64.StdEncoding.EncodeToString([]byte(associatedData)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Vault failed to decrypt: %v", err)
+	}
+	encoded, ok := data["plaintext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("Vault returned no plaintext")
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+func (k *vaultKey) Sign(ctx context.Context, message []byte) ([]byte, error) {
+	data, err := k.write(ctx, "hmac/"+k.name+"/sha2-256", map[string]interface{}{
+		"input": base64.StdEncoding.EncodeToString(message),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Vault failed to sign: %v", err)
+	}
+	mac, ok := data["hmac"].(string)
+	if !ok {
+		return nil, fmt.Errorf("Vault returned no HMAC")
+	}
+	return []byte(mac), nil
+}
+
+func (k *vaultKey) Verify(ctx context.Context, message, mac []byte) (bool, error) {
+	// Vault rejects malformed HMACs as bad requests
+	if !strings.HasPrefix(string(mac), vaultPrefix) {
+		return false, nil
+	}
+	data, err := k.write(ctx, "verify/"+k.name+"/sha2-256", map[string]interface{}{
+		"input": base64.StdEncoding.EncodeToString(message),
+		"hmac":  string(mac),
+	})
+	if err != nil {
+		return false, fmt.Errorf("Vault failed to verify: %v", err)
+	}
+	valid, _ := data["valid"].(bool)
+	return valid, nil
+}
+
+func (k *vaultKey) write(ctx context.Context, path string, body map[string]interface{}) (map[string]interface{}, error) {
+	secret, err := k.client.Logical().WriteWithContext(ctx, k.mount+"/"+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("empty response")
+	}
+	return secret.Data, nil
+}
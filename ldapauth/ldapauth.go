@@ -0,0 +1,121 @@
+// Package ldapauth checks passwords against an LDAP directory, such as
+// Active Directory or OpenLDAP. The user's entry is searched for by email
+// with a service account, then the password is checked by binding as the
+// entry.
+package ldapauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// DefaultUserFilter finds users by their mail attribute
+const DefaultUserFilter = "(mail=%s)"
+
+var (
+	// ErrUserNotFound is returned when no entry has the email
+	ErrUserNotFound = errors.New("user not in directory")
+	// ErrInvalidCredentials is returned when the directory rejects the
+	// password
+	ErrInvalidCredentials = errors.New("invalid directory credentials")
+)
+
+type Config struct {
+	// URL is the directory server, ldaps://host:636 or ldap://host:389
+	URL string
+	// BindDN and BindPassword are the service account users are searched
+	// for with
+	BindDN       string
+	BindPassword string
+	// BaseDN is the subtree users are searched for in
+	BaseDN string
+	// UserFilter finds a user's entry, with %s replaced by the escaped
+	// email. Empty selects DefaultUserFilter.
+	UserFilter string
+	// Timeout bounds each connection, search and bind
+	Timeout time.Duration
+}
+
+// Client checks passwords against one directory. It opens a connection per
+// check.
+type Client struct {
+	config Config
+}
+
+func New(config Config) *Client {
+	if config.UserFilter == "" {
+		config.UserFilter = DefaultUserFilter
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &Client{config: config}
+}
+
+// ValidateUserFilter checks that filter has one %s for the email
+func ValidateUserFilter(filter string) error {
+	if strings.Count(filter, "%s") != 1 || strings.Count(filter, "%") != 1 {
+		return fmt.Errorf("user filter must contain %%s once, such as %s", DefaultUserFilter)
+	}
+	if _, err := ldap.CompileFilter(fmt.Sprintf(filter, "user@example.com")); err != nil {
+		return fmt.Errorf("invalid user filter: %v", err)
+	}
+	return nil
+}
+
+// Authenticate checks the password of the directory user with email. It
+// returns ErrUserNotFound or ErrInvalidCredentials when the directory
+// answers but doesn't accept the user, and other errors when it can't be
+// reached or its answer can't be used.
+func (c *Client) Authenticate(ctx context.Context, email, password string) error {
+	// Binds without a password are anonymous and always succeed
+	if password == "" {
+		return ErrInvalidCredentials
+	}
+
+	timeout := c.config.Timeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	conn, err := ldap.DialURL(c.config.URL, ldap.DialWithDialer(&net.Dialer{Timeout: timeout}))
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %v", c.config.URL, err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(timeout)
+
+	if err := conn.Bind(c.config.BindDN, c.config.BindPassword); err != nil {
+		return fmt.Errorf("failed to bind as %s: %v", c.config.BindDN, err)
+	}
+
+	// A size limit of two is enough to tell an ambiguous filter
+	result, err := conn.Search(ldap.NewSearchRequest(
+		c.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(timeout.Seconds()), false,
+		fmt.Sprintf(c.config.UserFilter, ldap.EscapeFilter(email)),
+		[]string{"dn"}, nil,
+	))
+	switch {
+	case ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded), err == nil && len(result.Entries) > 1:
+		return fmt.Errorf("more than one directory entry matches %s", email)
+	case err != nil:
+		return fmt.Errorf("failed to search for %s: %v", email, err)
+	case len(result.Entries) == 0:
+		return ErrUserNotFound
+	}
+
+	err = conn.Bind(result.Entries[0].DN, password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return ErrInvalidCredentials
+	}
+	if err != nil {
+		return fmt.Errorf("failed to bind as %s: %v", result.Entries[0].DN, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"user-management/requestctx"
+)
+
+// ExemplarTraceIDLabel is the exemplar label holding the trace ID. Grafana
+// matches it when linking exemplars to the tracing datasource.
+const ExemplarTraceIDLabel = "trace_id"
+
+// UnaryServerInterceptor counts RPCs and records their latency. It must run
+// after the requestctx interceptor so the trace ID is known.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		elapsed := time.Since(start).Seconds()
+
+		code := status.Code(err).String()
+		RPCRequests.WithLabelValues(info.FullMethod, code).Inc()
+
+		observer := RPCDuration.WithLabelValues(info.FullMethod, code)
+		if reqInfo, ok := requestctx.FromContext(ctx); ok && reqInfo.TraceID != "" {
+			observer.(prometheus.ExemplarObserver).ObserveWithExemplar(elapsed, prometheus.Labels{
+				ExemplarTraceIDLabel: reqInfo.TraceID,
+			})
+		} else {
+			observer.Observe(elapsed)
+		}
+
+		return resp, err
+	}
+}
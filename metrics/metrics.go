@@ -0,0 +1,247 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "auth"
+
+// Event outbox metrics
+var (
+	OutboxBacklog = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "backlog_events",
+		Help:      "Number of events waiting in the outbox to be published.",
+	})
+
+	DeadLetterBacklog = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "dead_letter_events",
+		Help:      "Number of events in the dead letter queue.",
+	})
+
+	EventsPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "events_published_total",
+		Help:      "Events successfully published, by event type.",
+	}, []string{"type"})
+
+	EventPublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "publish_failures_total",
+		Help:      "Failed publish attempts, by event type.",
+	}, []string{"type"})
+
+	EventsDeadLettered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "events_dead_lettered_total",
+		Help:      "Events moved to the dead letter queue after exhausting retries, by event type.",
+	}, []string{"type"})
+)
+
+// Token metrics
+var (
+	TokensValidated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tokens",
+		Name:      "validated_total",
+		Help:      "Tokens that passed signature checks, by token format version.",
+	}, []string{"version"})
+
+	TokensIssued = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tokens",
+		Name:      "issued_total",
+		Help:      "Tokens issued, by token type (session, action, refresh).",
+	}, []string{"type"})
+
+	TokensRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tokens",
+		Name:      "rejected_total",
+		Help:      "Tokens that failed validation, by expected token type and a fixed set of reasons.",
+	}, []string{"type", "reason"})
+
+	TokenBlacklistLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tokens",
+		Name:      "blacklist_lookups_total",
+		Help:      "Token blacklist lookups, by result (hit, miss).",
+	}, []string{"result"})
+
+	TokenBlacklistCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tokens",
+		Name:      "blacklist_cache_lookups_total",
+		Help:      "Token blacklist lookups in the Redis cache, by result (hit, miss, cold, error). Cold and error lookups fall back to MongoDB.",
+	}, []string{"result"})
+
+	TokenBlacklistSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "tokens",
+		Name:      "blacklist_size",
+		Help:      "Invalidated tokens in the blacklist that have not expired yet.",
+	})
+)
+
+// Database metrics
+var (
+	DatabaseUp = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "up",
+		Help:      "Whether the last MongoDB health checks succeeded (1) or failed (0).",
+	})
+
+	DatabaseIndexesReady = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "indexes_ready",
+		Help:      "Whether every index the server needs exists and is built (1) or not (0).",
+	})
+
+	DatabaseReadRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "read_retries_total",
+		Help:      "Transient read failures, by whether they were retried or the retry budget was exhausted.",
+	}, []string{"outcome"})
+
+	DatabasePrimaryChanges = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "primary_changes_total",
+		Help:      "Replica set primary changes seen by the driver.",
+	})
+)
+
+// Token signing metrics, when tokens are signed by an HSM
+var (
+	HSMUp = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "hsm",
+		Name:      "up",
+		Help:      "Whether the last HSM health checks could sign (1) or not (0).",
+	})
+
+	TokenSigningDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "hsm",
+		Name:      "signing_duration_seconds",
+		Help:      "Latency of HSM signatures, including health check probes.",
+		Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	})
+
+	TokenSigningFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "hsm",
+		Name:      "signing_failures_total",
+		Help:      "HSM signatures that failed or timed out, including health check probes.",
+	})
+)
+
+// Login chain metrics
+var (
+	LoginMethodResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "login",
+		Name:      "method_results_total",
+		Help:      "Credential checks of the login chain, by login method and result (success, invalid, not_applicable, unavailable, error).",
+	}, []string{"method", "result"})
+
+	LoginMethodDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "login",
+		Name:      "method_duration_seconds",
+		Help:      "Latency of credential checks of the login chain, by login method.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"method"})
+)
+
+// Schema migration metrics
+var (
+	DocumentsMigrated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "schema",
+		Name:      "documents_migrated_total",
+		Help:      "Stored documents rewritten to their current schema version, by collection.",
+	}, []string{"collection"})
+)
+
+// Background job metrics
+var (
+	JobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "jobs",
+		Name:      "runs_total",
+		Help:      "Background job runs, by job and result (success, failure).",
+	}, []string{"job", "result"})
+
+	JobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "jobs",
+		Name:      "run_duration_seconds",
+		Help:      "Duration of background job runs, by job.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300},
+	}, []string{"job"})
+
+	JobRecords = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "jobs",
+		Name:      "records_processed_total",
+		Help:      "Records background jobs deleted or purged, by job.",
+	}, []string{"job"})
+
+	JobLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "jobs",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful run, by job.",
+	}, []string{"job"})
+)
+
+// Tenant isolation metrics
+var (
+	TenantIsolationViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tenancy",
+		Name:      "isolation_violations_total",
+		Help:      "Queries of organization-scoped RPCs not limited to the organization, by kind.",
+	}, []string{"kind"})
+)
+
+// RPC metrics. Latency observations carry the caller's trace ID as an
+// exemplar so dashboards can link slow requests to their traces.
+var (
+	RPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "Handled RPCs, by full method name and status code.",
+	}, []string{"method", "code"})
+
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "RPC latency, by full method name and status code.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"method", "code"})
+)
+
+// Handler serves all registered metrics. Scrapers that ask for the
+// OpenMetrics format also receive exemplars.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	})
+}
@@ -0,0 +1,159 @@
+// Package migrations rewrites stored documents written with an older schema
+// version, so the upgrades models apply on read can eventually be removed
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"user-management/database"
+	"user-management/metrics"
+	"user-management/models"
+)
+
+type MigratorConfig struct {
+	// Interval is the delay between passes over the collections
+	Interval time.Duration
+	// BatchSize is the number of documents upgraded per query
+	BatchSize int
+}
+
+// target is a collection whose documents follow a schema
+type target struct {
+	collection *database.Collection
+	name       string
+	schema     *models.Schema
+}
+
+// Migrator upgrades documents below their schema's current version. Several
+// migrators can run against the same database; a document is only
+// rewritten if its version did not change since it was read.
+type Migrator struct {
+	targets []target
+	config  MigratorConfig
+}
+
+func NewMigrator(db *database.Database, config MigratorConfig) *Migrator {
+	return &Migrator{
+		targets: []target{
+			{db.Users, "users", models.UserSchema},
+			{db.Orgs, "organizations", models.OrganizationSchema},
+			{db.Changes, "profile_change_requests", models.ProfileChangeRequestSchema},
+			{db.Devices, "devices", models.DeviceSchema},
+			{db.AuditLogs, "audit_logs", models.AuditLogSchema},
+			{db.Outbox, "outbox_events", models.OutboxEventSchema},
+			{db.DeadLetters, "dead_letter_events", models.DeadLetterEventSchema},
+		},
+		config: config,
+	}
+}
+
+// Run upgrades outdated documents every interval until ctx is cancelled
+func (m *Migrator) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		for _, t := range m.targets {
+			migrated, err := m.migrate(ctx, t)
+			if migrated > 0 {
+				log.Printf("Upgraded %d %s documents to schema version %d", migrated, t.name, t.schema.Version())
+			}
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Failed to upgrade %s documents: %v", t.name, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// migrate upgrades every outdated document of t in batches
+func (m *Migrator) migrate(ctx context.Context, t target) (int, error) {
+	outdated := bson.M{"$or": []bson.M{
+		{"schema_version": bson.M{"$lt": t.schema.Version()}},
+		{"schema_version": bson.M{"$exists": false}},
+	}}
+
+	migrated := 0
+	for {
+		cursor, err := t.collection.Find(ctx, outdated, options.Find().SetLimit(int64(m.config.BatchSize)))
+		if err != nil {
+			return migrated, err
+		}
+
+		var docs []bson.Raw
+		err = cursor.All(ctx, &docs)
+		if err != nil {
+			return migrated, err
+		}
+		if len(docs) == 0 {
+			return migrated, nil
+		}
+
+		batchMigrated := 0
+		for _, doc := range docs {
+			ok, err := m.migrateDocument(ctx, t, doc)
+			if err != nil {
+				return migrated, err
+			}
+			if ok {
+				batchMigrated++
+			}
+		}
+		migrated += batchMigrated
+		metrics.DocumentsMigrated.WithLabelValues(t.name).Add(float64(batchMigrated))
+
+		// Every document in the batch lost a race or failed to change, stop
+		// rather than reading the same batch again
+		if batchMigrated == 0 {
+			return migrated, nil
+		}
+	}
+}
+
+// migrateDocument stores the upgraded form of doc. It returns false if the
+// document changed version since it was read.
+func (m *Migrator) migrateDocument(ctx context.Context, t target, doc bson.Raw) (bool, error) {
+	var upgraded bson.M
+	if err := bson.Unmarshal(doc, &upgraded); err != nil {
+		return false, err
+	}
+	if err := t.schema.UpgradeDocument(upgraded); err != nil {
+		return false, err
+	}
+
+	set, unset, err := models.ChangedFields(doc, upgraded)
+	if err != nil {
+		return false, err
+	}
+
+	// Only the fields the upgrade changed are written, so concurrent
+	// updates to other fields are kept
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	filter := bson.M{"_id": doc.Lookup("_id")}
+	if version, err := doc.LookupErr("schema_version"); err == nil {
+		filter["schema_version"] = version
+	} else {
+		filter["schema_version"] = bson.M{"$exists": false}
+	}
+
+	result, err := t.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to update document %s: %v", doc.Lookup("_id"), err)
+	}
+	return result.ModifiedCount > 0, nil
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey is a long-lived key a machine client authenticates with as the
+// user who created it, stored by hash
+type APIKey struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	UserID ID                 `bson:"user_id"`
+	// Name is the user's name for the key, such as "Billing sync"
+	Name string `bson:"name"`
+	// Prefix is the start of the key, kept so users can tell keys apart
+	Prefix    string    `bson:"prefix"`
+	KeyHash   string    `bson:"key_hash"`
+	CreatedAt time.Time `bson:"created_at"`
+	// LastUsedAt is nil until the key is used
+	LastUsedAt *time.Time `bson:"last_used_at,omitempty"`
+	// ExpiresAt is nil for keys that last until they are revoked
+	ExpiresAt *time.Time `bson:"expires_at,omitempty"`
+}
+
+// Revoked reports whether the key was revoked along with every token of
+// user, the key's owner, such as by a password reset
+func (k APIKey) Revoked(user *User) bool {
+	return user.TokensValidAfter != nil && !k.CreatedAt.After(*user.TokensValidAfter)
+}
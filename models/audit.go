@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLog records a security-relevant change to an account
+type AuditLog struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	Action string             `bson:"action"`
+	// ActorID is the user who made the change, nil for the system
+	ActorID       *ID       `bson:"actor_id,omitempty"`
+	TargetID      ID        `bson:"target_id"`
+	IPAddress     string    `bson:"ip_address,omitempty"`
+	UserAgent     string    `bson:"user_agent,omitempty"`
+	Details       bson.M    `bson:"details,omitempty"`
+	CreatedAt     time.Time `bson:"created_at"`
+	SchemaVersion int       `bson:"schema_version"`
+	// Changes are the fields the action changed, with their values
+	// before and after it
+	Changes []AuditChange `bson:"changes,omitempty"`
+}
+
+// AuditChange is a field changed by an audited action. Values of fields
+// stored encrypted are left out, so the log doesn't keep a readable copy.
+type AuditChange struct {
+	Field  string      `bson:"field"`
+	Before interface{} `bson:"before,omitempty"`
+	After  interface{} `bson:"after,omitempty"`
+}
+
+// LoginHistory is a user's recent successful logins, projected from the
+// audit log
+type LoginHistory struct {
+	UserID      ID            `bson:"_id"`
+	Logins      []LoginRecord `bson:"logins"`
+	LoginCount  int64         `bson:"login_count"`
+	LastLoginAt time.Time     `bson:"last_login_at"`
+}
+
+// LoginRecord is one successful login
+type LoginRecord struct {
+	IPAddress string    `bson:"ip_address,omitempty"`
+	UserAgent string    `bson:"user_agent,omitempty"`
+	At        time.Time `bson:"at"`
+}
+
+// AuditStatKey identifies a daily audit count
+type AuditStatKey struct {
+	// Day is the UTC date, formatted 2006-01-02
+	Day    string `bson:"day"`
+	Action string `bson:"action"`
+}
+
+// AuditStat counts the audit entries of one action on one day, projected
+// from the audit log
+type AuditStat struct {
+	Key   AuditStatKey `bson:"_id"`
+	Count int64        `bson:"count"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrTenantKeyDestroyed is returned for data encrypted with an
+// organization's key after the key was destroyed
+var ErrTenantKeyDestroyed = errors.New("organization's encryption key was destroyed")
+
+// FieldCipher encrypts the personal data of organizations' members with
+// their organization's key. Decrypt returns values stored before
+// encryption was enabled unchanged.
+type FieldCipher interface {
+	Encrypt(orgID primitive.ObjectID, plaintext, associatedData string) (string, error)
+	Decrypt(orgID primitive.ObjectID, value, associatedData string) (string, error)
+}
+
+// fieldCipher encrypts members' personal data when users and profile
+// change requests are written, and decrypts it when they are read. It is
+// set once at startup.
+var fieldCipher FieldCipher
+
+// SetFieldCipher enables encrypting members' personal data with c
+func SetFieldCipher(c FieldCipher) {
+	fieldCipher = c
+}
+
+// StoredMemberField returns value as it is stored in a personal field of
+// userID, a member of orgID, for updates that set the field directly.
+// Fields of users outside organizations are stored as they are.
+func StoredMemberField(orgID *primitive.ObjectID, userID ID, value string) (string, error) {
+	if fieldCipher == nil || orgID == nil || value == "" {
+		return value, nil
+	}
+	return fieldCipher.Encrypt(*orgID, value, userID.String())
+}
+
+// readMemberField reverses StoredMemberField. Fields encrypted with a
+// destroyed key read as empty.
+func readMemberField(orgID *primitive.ObjectID, userID ID, value string) (string, error) {
+	if fieldCipher == nil || orgID == nil || value == "" {
+		return value, nil
+	}
+	plaintext, err := fieldCipher.Decrypt(*orgID, value, userID.String())
+	if errors.Is(err, ErrTenantKeyDestroyed) {
+		return "", nil
+	}
+	return plaintext, err
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OutboxEvent is a domain event waiting to be published. Events are written
+// alongside the change they describe and delivered by the outbox processor.
+type OutboxEvent struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	Type          string             `bson:"type"`
+	Payload       bson.M             `bson:"payload"`
+	Attempts      int                `bson:"attempts"`
+	LastError     string             `bson:"last_error,omitempty"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at"`
+	LockedUntil   time.Time          `bson:"locked_until"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	SchemaVersion int                `bson:"schema_version"`
+}
+
+// DeadLetterEvent is an outbox event that exhausted its delivery attempts
+type DeadLetterEvent struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	Type           string             `bson:"type"`
+	Payload        bson.M             `bson:"payload"`
+	Attempts       int                `bson:"attempts"`
+	LastError      string             `bson:"last_error"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	DeadLetteredAt time.Time          `bson:"dead_lettered_at"`
+	SchemaVersion  int                `bson:"schema_version"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidID is returned when a string is neither an ObjectID nor a UUID
+var ErrInvalidID = errors.New("invalid ID format")
+
+// ID identifies a user. It holds either the hex form of a Mongo ObjectID or
+// a UUID string, depending on the IDGenerator that created the account.
+// ObjectID-shaped IDs are stored as BSON ObjectIDs so documents written
+// before UUIDs were introduced keep matching.
+type ID string
+
+// ParseID validates an ID received from a client or a token
+func ParseID(s string) (ID, error) {
+	if objectID, err := primitive.ObjectIDFromHex(s); err == nil {
+		return ID(objectID.Hex()), nil
+	}
+	u, err := uuid.Parse(s)
+	if err != nil || len(s) != 36 {
+		return "", ErrInvalidID
+	}
+	return ID(u.String()), nil
+}
+
+// String returns the ID as sent to clients and placed in tokens
+func (id ID) String() string {
+	return string(id)
+}
+
+// IsZero reports whether the ID is unset
+func (id ID) IsZero() bool {
+	return id == ""
+}
+
+// MarshalBSONValue stores ObjectID-shaped IDs as ObjectIDs and everything
+// else as a string
+func (id ID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if objectID, err := primitive.ObjectIDFromHex(string(id)); err == nil {
+		return bson.MarshalValue(objectID)
+	}
+	return bson.MarshalValue(string(id))
+}
+
+// UnmarshalBSONValue reads IDs stored either as ObjectIDs or as strings
+func (id *ID) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bsontype.ObjectID:
+		*id = ID(value.ObjectID().Hex())
+	case bsontype.String:
+		*id = ID(value.StringValue())
+	case bsontype.Null, bsontype.Undefined:
+		*id = ""
+	default:
+		return fmt.Errorf("cannot decode %s into an ID", t)
+	}
+	return nil
+}
+
+// ID formats accepted by NewIDGenerator
+const (
+	IDFormatObjectID = "objectid"
+	IDFormatUUIDv7   = "uuidv7"
+)
+
+// IDGenerator creates identifiers for new accounts
+type IDGenerator interface {
+	NewID() (ID, error)
+}
+
+// ObjectIDGenerator creates Mongo ObjectIDs, the historical format
+type ObjectIDGenerator struct{}
+
+func (ObjectIDGenerator) NewID() (ID, error) {
+	return ID(primitive.NewObjectID().Hex()), nil
+}
+
+// UUIDv7Generator creates time-ordered UUIDs (RFC 9562) that systems
+// without ObjectID support can store and sort
+type UUIDv7Generator struct{}
+
+func (UUIDv7Generator) NewID() (ID, error) {
+	u, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return ID(u.String()), nil
+}
+
+// NewIDGenerator returns the generator for format. An empty format selects
+// ObjectIDs.
+func NewIDGenerator(format string) (IDGenerator, error) {
+	switch format {
+	case "", IDFormatObjectID:
+		return ObjectIDGenerator{}, nil
+	case IDFormatUUIDv7:
+		return UUIDv7Generator{}, nil
+	default:
+		return nil, fmt.Errorf("unknown ID format %q", format)
+	}
+}
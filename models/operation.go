@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PendingOperation is a destructive admin action held for the undo window
+// before it runs. An admin can cancel it until RunAt.
+type PendingOperation struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty"`
+	Kind string             `bson:"kind"`
+	// UserID is the account an OperationHardDelete removes
+	UserID ID `bson:"user_id,omitempty"`
+	// Deprovisioning is the job an OperationDeprovision queues. It gets
+	// its ID once queued.
+	Deprovisioning *DeprovisioningJob `bson:"deprovisioning,omitempty"`
+	RequestedBy    ID                 `bson:"requested_by"`
+	Status         string             `bson:"status"`
+	// RunAt is when the undo window ends
+	RunAt time.Time `bson:"run_at"`
+	// LockedUntil hides the operation from servers until RunAt, and a
+	// running operation from other servers
+	LockedUntil time.Time `bson:"locked_until"`
+	CreatedAt   time.Time `bson:"created_at"`
+	// CompletedAt is when the operation ran, failed or was canceled
+	CompletedAt *time.Time `bson:"completed_at,omitempty"`
+	CanceledBy  ID         `bson:"canceled_by,omitempty"`
+	// Error is why a failed operation couldn't run
+	Error string `bson:"error,omitempty"`
+}
+
+// Kinds of pending operations
+const (
+	OperationHardDelete  = "hard_delete"
+	OperationDeprovision = "deprovision"
+)
+
+// Pending operation statuses
+const (
+	OperationPending  = "pending"
+	OperationDone     = "done"
+	OperationFailed   = "failed"
+	OperationCanceled = "canceled"
+)
+
+// Operation is a long-running admin task, such as a bulk import, run in
+// the background by the server that started it. Clients poll or watch it
+// until it is Done. It is removed at ExpiresAt, a retention period after
+// it finished.
+type Operation struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Kind        string             `bson:"kind"`
+	RequestedBy ID                 `bson:"requested_by"`
+	Done        bool               `bson:"done"`
+	// Processed counts the records handled so far, out of Total. Total
+	// is zero when it isn't known in advance.
+	Processed int64 `bson:"processed"`
+	Total     int64 `bson:"total"`
+	// Response is the result of a successful task, a marshaled
+	// google.protobuf.Any
+	Response []byte `bson:"response,omitempty"`
+	// ErrorCode, a gRPC status code, and ErrorMessage tell why the task
+	// failed
+	ErrorCode    int32  `bson:"error_code,omitempty"`
+	ErrorMessage string `bson:"error_message,omitempty"`
+
+	CreatedAt time.Time `bson:"created_at"`
+	// UpdatedAt is refreshed while the task runs, so operations whose
+	// server stopped can be told apart
+	UpdatedAt   time.Time  `bson:"updated_at"`
+	CompletedAt *time.Time `bson:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `bson:"expires_at,omitempty"`
+}
+
+// Kinds of long-running operations
+const (
+	OperationImportUsers    = "import_users"
+	OperationRebuildIndexes = "rebuild_indexes"
+	OperationAccountPurge   = "account_purge"
+)
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Organization groups managed accounts under shared policies
+type Organization struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name   string             `bson:"name" json:"name"`
+	Policy OrganizationPolicy `bson:"policy" json:"policy"`
+	// SSO is the organization's identity provider, if it has one
+	SSO *SSOConnection `bson:"sso,omitempty" json:"sso,omitempty"`
+	// Provisioning decides the accounts of identities the provider signs
+	// in for the first time
+	Provisioning  *ProvisioningRules `bson:"provisioning,omitempty" json:"provisioning,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+	SchemaVersion int                `bson:"schema_version" json:"-"`
+}
+
+// OrganizationPolicy holds the rules an organization applies to its members
+type OrganizationPolicy struct {
+	// RequireProfileChangeApproval queues name and email changes by members
+	// until an org admin approves them
+	RequireProfileChangeApproval bool `bson:"require_profile_change_approval" json:"require_profile_change_approval"`
+
+	// RequireTwoFactor limits members without 2FA to enrollment once
+	// TwoFactorGracePeriod has passed since their first login under the
+	// policy. A zero grace period uses the deployment's.
+	RequireTwoFactor     bool          `bson:"require_two_factor" json:"require_two_factor"`
+	TwoFactorGracePeriod time.Duration `bson:"two_factor_grace_period,omitempty" json:"two_factor_grace_period,omitempty"`
+
+	// LoginMethods replace the deployment's login chain for members, in
+	// the order to try them. Empty uses the deployment's.
+	LoginMethods []string `bson:"login_methods,omitempty" json:"login_methods,omitempty"`
+}
+
+// SSOConnection signs an organization's members in through its identity
+// provider. Logins with an email address in one of Domains are sent to it.
+type SSOConnection struct {
+	Protocol     string `bson:"protocol" json:"protocol"`
+	Issuer       string `bson:"issuer" json:"issuer"`
+	ClientID     string `bson:"client_id" json:"client_id"`
+	ClientSecret string `bson:"client_secret" json:"-"`
+	// Domains are the email domains the organization owns. A domain
+	// belongs to at most one organization.
+	Domains []string `bson:"domains" json:"domains"`
+	// Enforced stops members from signing in with a password
+	Enforced bool `bson:"enforced" json:"enforced"`
+}
+
+// ProvisioningRules are evaluated when an identity from an organization's
+// provider first signs in. Blocked identities are refused. Otherwise the
+// identity is linked to the member with its email address, or, when
+// Enabled, a new member account is created for it.
+type ProvisioningRules struct {
+	// Enabled creates accounts for identities without one
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// DefaultRole is the organization role of new accounts no group
+	// mapping applies to
+	DefaultRole string `bson:"default_role" json:"default_role"`
+	// GroupsClaim is the ID token claim listing the user's groups
+	GroupsClaim string `bson:"groups_claim" json:"groups_claim"`
+	// GroupRoles map groups to organization roles. The first mapping of a
+	// group the user is in applies.
+	GroupRoles []GroupRole `bson:"group_roles,omitempty" json:"group_roles,omitempty"`
+	// RoleMappings grant roles to the members of groups. Unlike the rest
+	// of the rules they are applied at every login, so group changes at
+	// the provider reach the account.
+	RoleMappings []RoleMapping `bson:"role_mappings,omitempty" json:"role_mappings,omitempty"`
+	// Attributes copy claims to new accounts
+	Attributes []AttributeMapping `bson:"attributes,omitempty" json:"attributes,omitempty"`
+	// Blocklist refuses identities by email address, "@domain" or
+	// "group:name"
+	Blocklist []string `bson:"blocklist,omitempty" json:"blocklist,omitempty"`
+}
+
+// GroupRole gives members of an identity provider group a role
+type GroupRole struct {
+	Group string `bson:"group" json:"group"`
+	Role  string `bson:"role" json:"role"`
+}
+
+// RoleMapping grants roles to the members of an identity provider group
+type RoleMapping struct {
+	Group string   `bson:"group" json:"group"`
+	Roles []string `bson:"roles" json:"roles"`
+}
+
+// AttributeMapping copies an ID token claim to a field of new accounts
+type AttributeMapping struct {
+	Claim string `bson:"claim" json:"claim"`
+	// Field is "name", or "external_id:" followed by a system
+	Field string `bson:"field" json:"field"`
+}
+
+// Account fields provisioning attributes can be copied to
+const (
+	ProvisioningFieldName = "name"
+	// ProvisioningFieldExternalID is followed by the system of the ID, such
+	// as "external_id:hr"
+	ProvisioningFieldExternalID = "external_id:"
+)
+
+// Organization roles
+const (
+	OrgRoleMember = "member"
+	OrgRoleAdmin  = "admin"
+)
+
+// ProfileChangeRequest is a profile change waiting for org-admin approval
+type ProfileChangeRequest struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	UserID ID                 `bson:"user_id"`
+	OrgID  primitive.ObjectID `bson:"org_id"`
+	// Name and Email are empty when that field is not being changed
+	Name          string     `bson:"name,omitempty"`
+	Email         string     `bson:"email,omitempty"`
+	Status        string     `bson:"status"`
+	RejectReason  string     `bson:"reject_reason,omitempty"`
+	ReviewedBy    *ID        `bson:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time `bson:"reviewed_at,omitempty"`
+	CreatedAt     time.Time  `bson:"created_at"`
+	UpdatedAt     time.Time  `bson:"updated_at"`
+	SchemaVersion int        `bson:"schema_version"`
+}
+
+// ProfileChangeRequest statuses
+const (
+	ProfileChangeStatusPending  = "pending"
+	ProfileChangeStatusApproved = "approved"
+	ProfileChangeStatusRejected = "rejected"
+)
+
+// TenantKey is an organization's data key, wrapped by the master key
+type TenantKey struct {
+	// OrgID is the organization the key encrypts the members' data of
+	OrgID      primitive.ObjectID `bson:"_id"`
+	WrappedKey []byte             `bson:"wrapped_key,omitempty"`
+	// MasterKeyID names the master key that wrapped the key
+	MasterKeyID string    `bson:"master_key_id"`
+	CreatedAt   time.Time `bson:"created_at"`
+	// DestroyedAt is set, and WrappedKey removed, once the key is
+	// destroyed. The data it encrypted can't be read anymore.
+	DestroyedAt *time.Time `bson:"destroyed_at,omitempty"`
+}
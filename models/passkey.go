@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Passkey is a WebAuthn credential registered by a user
+type Passkey struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	UserID ID                 `bson:"user_id"`
+	// Name is the user's name for the passkey, such as "Work laptop"
+	Name         string `bson:"name,omitempty"`
+	CredentialID []byte `bson:"credential_id"`
+	PublicKey    []byte `bson:"public_key"`
+	// AttestationFormat is the attestation statement format the
+	// authenticator used, "none" when it gave no attestation
+	AttestationFormat string   `bson:"attestation_format"`
+	AAGUID            string   `bson:"aaguid"`
+	SignCount         uint32   `bson:"sign_count"`
+	Transports        []string `bson:"transports,omitempty"`
+	BackupEligible    bool     `bson:"backup_eligible"`
+	BackupState       bool     `bson:"backup_state"`
+	// Discoverable is nil when the client didn't report it
+	Discoverable *bool     `bson:"discoverable,omitempty"`
+	CreatedAt    time.Time `bson:"created_at"`
+	// LastUsedAt is nil until the passkey is used to sign in
+	LastUsedAt *time.Time `bson:"last_used_at,omitempty"`
+}
+
+// PasskeyRegistration is the WebAuthn session of a registration between
+// its two calls
+type PasskeyRegistration struct {
+	Session   []byte    `bson:"session"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// PasskeyLogin is the WebAuthn session of a passkey login between
+// BeginPasskeyLogin and Login
+type PasskeyLogin struct {
+	Session   []byte    `bson:"session"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScheduledAction is an admin action on an account that runs at a set
+// time, such as deactivating it when a contract ends. The user is emailed
+// a notice before it runs.
+type ScheduledAction struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Action      string             `bson:"action"`
+	UserID      ID                 `bson:"user_id"`
+	Reason      string             `bson:"reason,omitempty"`
+	RequestedBy ID                 `bson:"requested_by"`
+	Status      string             `bson:"status"`
+	RunAt       time.Time          `bson:"run_at"`
+	// NotifyAt is when the user is sent notice of the action, unset when
+	// no notice is sent. NotifiedAt is when it was sent.
+	NotifyAt   *time.Time `bson:"notify_at,omitempty"`
+	NotifiedAt *time.Time `bson:"notified_at,omitempty"`
+	// LockedUntil hides the action from servers until RunAt, and a running
+	// action from other servers
+	LockedUntil time.Time `bson:"locked_until"`
+	CreatedAt   time.Time `bson:"created_at"`
+	// CompletedAt is when the action ran, failed or was canceled
+	CompletedAt *time.Time `bson:"completed_at,omitempty"`
+	CanceledBy  ID         `bson:"canceled_by,omitempty"`
+	// Error is why a failed action couldn't run
+	Error string `bson:"error,omitempty"`
+}
+
+// Scheduled actions
+const (
+	ScheduledDeactivate = "deactivate"
+	ScheduledDelete     = "delete"
+)
+
+// ScheduledActions are the actions that can be scheduled
+var ScheduledActions = []string{ScheduledDeactivate, ScheduledDelete}
+
+// Scheduled action statuses
+const (
+	ActionScheduled = "scheduled"
+	ActionDone      = "done"
+	ActionFailed    = "failed"
+	ActionCanceled  = "canceled"
+)
@@ -0,0 +1,299 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Upgrade rewrites a stored document from one schema version to the next
+type Upgrade func(doc bson.M) error
+
+// Schema describes how a model's stored documents changed over time.
+// Documents record the version they were written with in schema_version,
+// and older documents are upgraded when they are read. To change a model's
+// stored shape, append an upgrade to its schema.
+type Schema struct {
+	Name string
+	// Upgrades[i] turns a version i document into version i+1. Version 0
+	// is a document written before schema versions existed.
+	Upgrades []Upgrade
+}
+
+// Version is the schema version new documents are written with
+func (s *Schema) Version() int {
+	return len(s.Upgrades)
+}
+
+// Upgrade returns data upgraded to the current version. Documents already
+// at the current version, or written by a newer version, are returned
+// unchanged.
+func (s *Schema) Upgrade(data []byte) ([]byte, error) {
+	if documentVersion(data) >= s.Version() {
+		return data, nil
+	}
+
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if err := s.UpgradeDocument(doc); err != nil {
+		return nil, err
+	}
+	return bson.Marshal(doc)
+}
+
+// UpgradeDocument upgrades doc in place to the current version
+func (s *Schema) UpgradeDocument(doc bson.M) error {
+	version := 0
+	switch v := doc["schema_version"].(type) {
+	case int32:
+		version = int(v)
+	case int64:
+		version = int(v)
+	case int:
+		version = v
+	}
+	for ; version < s.Version(); version++ {
+		if err := s.Upgrades[version](doc); err != nil {
+			return fmt.Errorf("failed to upgrade %s document to version %d: %v", s.Name, version+1, err)
+		}
+	}
+	doc["schema_version"] = version
+	return nil
+}
+
+// documentVersion reads schema_version from a raw document, 0 when unset
+func documentVersion(data []byte) int {
+	value, err := bson.Raw(data).LookupErr("schema_version")
+	if err != nil {
+		return 0
+	}
+	switch value.Type {
+	case bsontype.Int32:
+		return int(value.Int32())
+	case bsontype.Int64:
+		return int(value.Int64())
+	}
+	return 0
+}
+
+// ChangedFields compares an upgraded document with the stored one and
+// returns the fields to set and unset to store the upgrade
+func ChangedFields(stored bson.Raw, upgraded bson.M) (bson.M, bson.M, error) {
+	set := bson.M{}
+	for key, value := range upgraded {
+		t, data, err := bson.MarshalValue(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		old, err := stored.LookupErr(key)
+		if err == nil && old.Type == t && bytes.Equal(old.Value, data) {
+			continue
+		}
+		set[key] = value
+	}
+
+	unset := bson.M{}
+	elements, err := stored.Elements()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, element := range elements {
+		if _, ok := upgraded[element.Key()]; !ok {
+			unset[element.Key()] = ""
+		}
+	}
+	return set, unset, nil
+}
+
+// setDefault sets key in doc when it is missing
+func setDefault(doc bson.M, key string, value interface{}) {
+	if _, ok := doc[key]; !ok {
+		doc[key] = value
+	}
+}
+
+// stampVersion is the upgrade of models whose stored shape did not change
+// when schema versions were introduced
+func stampVersion(bson.M) error {
+	return nil
+}
+
+// Schemas of the versioned models
+var (
+	UserSchema = &Schema{Name: "user", Upgrades: []Upgrade{
+		upgradeUserV1,
+	}}
+	OrganizationSchema = &Schema{Name: "organization", Upgrades: []Upgrade{
+		stampVersion,
+	}}
+	ProfileChangeRequestSchema = &Schema{Name: "profile change request", Upgrades: []Upgrade{
+		stampVersion,
+	}}
+	DeviceSchema = &Schema{Name: "device", Upgrades: []Upgrade{
+		upgradeDeviceV1,
+	}}
+	AuditLogSchema = &Schema{Name: "audit log", Upgrades: []Upgrade{
+		stampVersion,
+	}}
+	OutboxEventSchema = &Schema{Name: "outbox event", Upgrades: []Upgrade{
+		stampVersion,
+	}}
+	DeadLetterEventSchema = &Schema{Name: "dead letter event", Upgrades: []Upgrade{
+		stampVersion,
+	}}
+)
+
+// upgradeUserV1 fills in the status fields of accounts written before they
+// were always set, which filters on is_deleted would otherwise miss
+func upgradeUserV1(doc bson.M) error {
+	setDefault(doc, "is_active", true)
+	setDefault(doc, "is_deleted", false)
+	if createdAt, ok := doc["created_at"]; ok {
+		setDefault(doc, "updated_at", createdAt)
+	}
+	return nil
+}
+
+// upgradeDeviceV1 sets last_seen_at on devices only seen once
+func upgradeDeviceV1(doc bson.M) error {
+	if firstSeenAt, ok := doc["first_seen_at"]; ok {
+		setDefault(doc, "last_seen_at", firstSeenAt)
+	}
+	return nil
+}
+
+// The versioned models stamp the current schema version when they are
+// written and upgrade older documents when they are read. Users and
+// profile change requests also encrypt their members' personal data with
+// the organization's key.
+
+func (u User) MarshalBSON() ([]byte, error) {
+	type stored User
+	u.SchemaVersion = UserSchema.Version()
+	var err error
+	if u.Name, err = StoredMemberField(u.OrgID, u.ID, u.Name); err != nil {
+		return nil, err
+	}
+	return bson.Marshal(stored(u))
+}
+
+func (u *User) UnmarshalBSON(data []byte) error {
+	data, err := UserSchema.Upgrade(data)
+	if err != nil {
+		return err
+	}
+	type stored User
+	if err := bson.Unmarshal(data, (*stored)(u)); err != nil {
+		return err
+	}
+	u.Name, err = readMemberField(u.OrgID, u.ID, u.Name)
+	return err
+}
+
+func (o Organization) MarshalBSON() ([]byte, error) {
+	type stored Organization
+	o.SchemaVersion = OrganizationSchema.Version()
+	return bson.Marshal(stored(o))
+}
+
+func (o *Organization) UnmarshalBSON(data []byte) error {
+	data, err := OrganizationSchema.Upgrade(data)
+	if err != nil {
+		return err
+	}
+	type stored Organization
+	return bson.Unmarshal(data, (*stored)(o))
+}
+
+func (r ProfileChangeRequest) MarshalBSON() ([]byte, error) {
+	type stored ProfileChangeRequest
+	r.SchemaVersion = ProfileChangeRequestSchema.Version()
+	var err error
+	if r.Name, err = StoredMemberField(&r.OrgID, r.UserID, r.Name); err != nil {
+		return nil, err
+	}
+	if r.Email, err = StoredMemberField(&r.OrgID, r.UserID, r.Email); err != nil {
+		return nil, err
+	}
+	return bson.Marshal(stored(r))
+}
+
+func (r *ProfileChangeRequest) UnmarshalBSON(data []byte) error {
+	data, err := ProfileChangeRequestSchema.Upgrade(data)
+	if err != nil {
+		return err
+	}
+	type stored ProfileChangeRequest
+	if err := bson.Unmarshal(data, (*stored)(r)); err != nil {
+		return err
+	}
+	if r.Name, err = readMemberField(&r.OrgID, r.UserID, r.Name); err != nil {
+		return err
+	}
+	r.Email, err = readMemberField(&r.OrgID, r.UserID, r.Email)
+	return err
+}
+
+func (d Device) MarshalBSON() ([]byte, error) {
+	type stored Device
+	d.SchemaVersion = DeviceSchema.Version()
+	return bson.Marshal(stored(d))
+}
+
+func (d *Device) UnmarshalBSON(data []byte) error {
+	data, err := DeviceSchema.Upgrade(data)
+	if err != nil {
+		return err
+	}
+	type stored Device
+	return bson.Unmarshal(data, (*stored)(d))
+}
+
+func (a AuditLog) MarshalBSON() ([]byte, error) {
+	type stored AuditLog
+	a.SchemaVersion = AuditLogSchema.Version()
+	return bson.Marshal(stored(a))
+}
+
+func (a *AuditLog) UnmarshalBSON(data []byte) error {
+	data, err := AuditLogSchema.Upgrade(data)
+	if err != nil {
+		return err
+	}
+	type stored AuditLog
+	return bson.Unmarshal(data, (*stored)(a))
+}
+
+func (e OutboxEvent) MarshalBSON() ([]byte, error) {
+	type stored OutboxEvent
+	e.SchemaVersion = OutboxEventSchema.Version()
+	return bson.Marshal(stored(e))
+}
+
+func (e *OutboxEvent) UnmarshalBSON(data []byte) error {
+	data, err := OutboxEventSchema.Upgrade(data)
+	if err != nil {
+		return err
+	}
+	type stored OutboxEvent
+	return bson.Unmarshal(data, (*stored)(e))
+}
+
+func (e DeadLetterEvent) MarshalBSON() ([]byte, error) {
+	type stored DeadLetterEvent
+	e.SchemaVersion = DeadLetterEventSchema.Version()
+	return bson.Marshal(stored(e))
+}
+
+func (e *DeadLetterEvent) UnmarshalBSON(data []byte) error {
+	data, err := DeadLetterEventSchema.Upgrade(data)
+	if err != nil {
+		return err
+	}
+	type stored DeadLetterEvent
+	return bson.Unmarshal(data, (*stored)(e))
+}
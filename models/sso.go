@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SSOLogin is a login sent to an organization's identity provider, kept
+// until the provider sends the user back
+type SSOLogin struct {
+	ID primitive.ObjectID `bson:"_id,omitempty"`
+	// StateHash is the hash of the state parameter that comes back with
+	// the user
+	StateHash string             `bson:"state_hash"`
+	OrgID     primitive.ObjectID `bson:"org_id"`
+	// Nonce and CodeVerifier bind the provider's response to this login
+	Nonce        string    `bson:"nonce"`
+	CodeVerifier string    `bson:"code_verifier"`
+	IPAddress    string    `bson:"ip_address,omitempty"`
+	UserAgent    string    `bson:"user_agent,omitempty"`
+	CreatedAt    time.Time `bson:"created_at"`
+	ExpiresAt    time.Time `bson:"expires_at"`
+}
+
+// DeprovisioningJob deactivates the members of an organization its identity
+// provider no longer vouches for. Jobs run in the background and keep
+// their report once done.
+type DeprovisioningJob struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	OrgID  primitive.ObjectID `bson:"org_id"`
+	Reason string             `bson:"reason"`
+	// Issuer is the provider whose identities are deprovisioned
+	Issuer string `bson:"issuer"`
+	// Subjects are the identities deleted at the provider, for
+	// DeprovisionReasonDeleted
+	Subjects []string `bson:"subjects,omitempty"`
+	// InactiveSince selects identities that haven't signed in since, for
+	// DeprovisionReasonInactive
+	InactiveSince *time.Time `bson:"inactive_since,omitempty"`
+	RequestedBy   ID         `bson:"requested_by"`
+	Status        string     `bson:"status"`
+	// LockedUntil hides a running job from other servers
+	LockedUntil time.Time            `bson:"locked_until"`
+	Report      DeprovisioningReport `bson:"report"`
+	CreatedAt   time.Time            `bson:"created_at"`
+	CompletedAt *time.Time           `bson:"completed_at,omitempty"`
+}
+
+// DeprovisioningReport counts what a job has done so far
+type DeprovisioningReport struct {
+	Deactivated     int `bson:"deactivated"`
+	SessionsRevoked int `bson:"sessions_revoked"`
+	// Emails are the first addresses deactivated, listed in the summary
+	// sent to org admins
+	Emails  []string             `bson:"emails,omitempty"`
+	Skipped []DeprovisioningSkip `bson:"skipped,omitempty"`
+}
+
+// DeprovisioningSkip is a matching account a job left active
+type DeprovisioningSkip struct {
+	UserID ID     `bson:"user_id"`
+	Reason string `bson:"reason"`
+}
+
+// Reasons for deprovisioning
+const (
+	// DeprovisionReasonSSODisconnected deprovisions every identity of a
+	// provider the organization disconnected
+	DeprovisionReasonSSODisconnected = "sso_disconnected"
+	// DeprovisionReasonDeleted deprovisions identities the provider
+	// deleted
+	DeprovisionReasonDeleted = "deleted"
+	// DeprovisionReasonInactive deprovisions identities the provider
+	// stopped signing in
+	DeprovisionReasonInactive = "inactive"
+)
+
+// Deprovisioning job statuses
+const (
+	DeprovisioningPending = "pending"
+	DeprovisioningDone    = "done"
+)
+
+// Reasons a deprovisioning job leaves an account active
+const (
+	DeprovisionSkipAdmin    = "admin"
+	DeprovisionSkipOrgAdmin = "org_admin"
+)
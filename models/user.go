@@ -8,25 +8,289 @@ import (
 
 // User represents a user in the database
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Email     string             `bson:"email" json:"email"`
-	Password  string             `bson:"password" json:"-"` // Never include in JSON responses
-	Name      string             `bson:"name" json:"name"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
-	IsActive  bool               `bson:"is_active" json:"is_active"`
-	IsDeleted bool               `bson:"is_deleted" json:"is_deleted"`
+	ID        ID        `bson:"_id,omitempty" json:"id"`
+	Email     string    `bson:"email" json:"email"`
+	Password  string    `bson:"password" json:"-"` // Never include in JSON responses
+	Name      string    `bson:"name" json:"name"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+	IsActive  bool      `bson:"is_active" json:"is_active"`
+	IsDeleted bool      `bson:"is_deleted" json:"is_deleted"`
+	// DeletedAt is when the account was deleted. It can be restored until
+	// the deletion grace period has passed, then it is purged.
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	// EmailVerified is set when ownership of the email address was proven,
+	// including by a provider the account was imported from
+	EmailVerified bool     `bson:"email_verified,omitempty" json:"email_verified"`
+	Roles         []string `bson:"roles,omitempty" json:"roles,omitempty"`
+	// MappedRoles are the roles in Roles granted by the role mappings of
+	// the user's organization, which SSO logins update
+	MappedRoles []string `bson:"mapped_roles,omitempty" json:"-"`
+
+	// PasswordChangedAt is when the password was last set. It is unset for
+	// accounts whose password hasn't changed since it was first tracked.
+	PasswordChangedAt *time.Time `bson:"password_changed_at,omitempty" json:"-"`
+	// AcceptedTermsVersion is the version of the terms of service the user
+	// last accepted
+	AcceptedTermsVersion string     `bson:"accepted_terms_version,omitempty" json:"-"`
+	TermsAcceptedAt      *time.Time `bson:"terms_accepted_at,omitempty" json:"-"`
+
+	// OrgID is set for accounts managed by an organization
+	OrgID   *primitive.ObjectID `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	OrgRole string              `bson:"org_role,omitempty" json:"org_role,omitempty"`
+
+	// TokensValidAfter revokes every token issued at or before it, and
+	// every API key created by then
+	TokensValidAfter *time.Time `bson:"tokens_valid_after,omitempty" json:"-"`
+	// RecoveryCodeHashes are the unused recovery codes of the account
+	RecoveryCodeHashes []string   `bson:"recovery_code_hashes,omitempty" json:"-"`
+	TwoFactor          *TwoFactor `bson:"two_factor,omitempty" json:"-"`
+	// TwoFactorReset is a pending self-serve reset of TwoFactor
+	TwoFactorReset *TwoFactorReset `bson:"two_factor_reset,omitempty" json:"-"`
+	// PendingTwoFactor is a factor being set up, enabled once a code from
+	// it is verified
+	PendingTwoFactor *TwoFactor `bson:"pending_two_factor,omitempty" json:"-"`
+	// TwoFactorDeadline is when a user required to use 2FA must have set
+	// it up by. It is set at their first login under the requirement.
+	TwoFactorDeadline *time.Time `bson:"two_factor_deadline,omitempty" json:"-"`
+	// PendingPasskey is a passkey registration in progress
+	PendingPasskey *PasskeyRegistration `bson:"pending_passkey,omitempty" json:"-"`
+	// PasskeyLogin is a passkey login in progress
+	PasskeyLogin *PasskeyLogin `bson:"passkey_login,omitempty" json:"-"`
+
+	// RefreshTokenReusedAt flags an account one of whose consumed refresh
+	// tokens was presented again, a sign it was stolen. Securing the
+	// account clears it.
+	RefreshTokenReusedAt *time.Time `bson:"refresh_token_reused_at,omitempty" json:"refresh_token_reused_at,omitempty"`
+
+	// FailedLoginCount counts consecutive failed logins since the last
+	// success or lock
+	FailedLoginCount int          `bson:"failed_login_count,omitempty" json:"-"`
+	Lock             *AccountLock `bson:"lock,omitempty" json:"lock,omitempty"`
+
+	// Identities are the external identities the user signs in with. Each
+	// belongs to one user.
+	Identities []LinkedIdentity `bson:"identities,omitempty" json:"-"`
+
+	// ExternalIDs link the account to its identities in other systems,
+	// at most one per system
+	ExternalIDs []ExternalID `bson:"external_ids,omitempty" json:"external_ids,omitempty"`
+
+	// SchemaVersion is the version of UserSchema the document was written with
+	SchemaVersion int `bson:"schema_version" json:"-"`
+}
+
+// ExternalID is the user's ID in another system, such as a legacy auth
+// provider. Each ID belongs to one user per system.
+type ExternalID struct {
+	System string `bson:"system" json:"system"`
+	ID     string `bson:"id" json:"id"`
+}
+
+// LinkedIdentity is a user's identity at an identity provider
+type LinkedIdentity struct {
+	// Provider is the kind of identity, such as sso or google
+	Provider string `bson:"provider"`
+	// Issuer and Subject identify the user at the provider
+	Issuer      string             `bson:"issuer"`
+	Subject     string             `bson:"subject"`
+	OrgID       primitive.ObjectID `bson:"org_id,omitempty"`
+	Email       string             `bson:"email,omitempty"`
+	LinkedAt    time.Time          `bson:"linked_at"`
+	LastLoginAt *time.Time         `bson:"last_login_at,omitempty"`
+}
+
+// Identity providers of linked identities
+const (
+	IdentityProviderSSO    = "sso"
+	IdentityProviderGoogle = "google"
+	IdentityProviderGitHub = "github"
+)
+
+// ExternalIDMap returns the user's external IDs keyed by system
+func (u *User) ExternalIDMap() map[string]string {
+	if len(u.ExternalIDs) == 0 {
+		return nil
+	}
+	ids := make(map[string]string, len(u.ExternalIDs))
+	for _, externalID := range u.ExternalIDs {
+		ids[externalID.System] = externalID.ID
+	}
+	return ids
+}
+
+// PasswordSetAt returns when the password was last set, taking accounts
+// from before PasswordChangedAt was tracked to have kept their first one
+func (u *User) PasswordSetAt() time.Time {
+	if u.PasswordChangedAt != nil {
+		return *u.PasswordChangedAt
+	}
+	return u.CreatedAt
+}
+
+// User roles
+const (
+	RoleAdmin = "admin"
+)
+
+// HasRole reports whether the user has been granted role
+func (u *User) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Two-factor methods
+const (
+	TwoFactorMethodTOTP = "totp"
+)
+
+// TwoFactor is the second factor enrolled on an account
+type TwoFactor struct {
+	Method    string    `bson:"method"`
+	Secret    string    `bson:"secret"`
+	EnabledAt time.Time `bson:"enabled_at"`
+}
+
+// TwoFactorReset removes the account's second factor once EffectiveAt
+// passes, unless the owner cancels it first
+type TwoFactorReset struct {
+	RequestedAt time.Time `bson:"requested_at"`
+	EffectiveAt time.Time `bson:"effective_at"`
+	// Factors are the proofs given for the reset, e.g. "password" and
+	// "email_code"
+	Factors         []string `bson:"factors"`
+	IPAddress       string   `bson:"ip_address"`
+	CancelTokenHash string   `bson:"cancel_token_hash"`
+}
+
+// Account lock kinds
+const (
+	// LockKindAdmin locks are placed by an administrator and can only be
+	// lifted by one
+	LockKindAdmin = "admin"
+	// LockKindProtective locks are placed automatically after repeated
+	// failed logins. They expire on their own, or the owner can lift them
+	// with an email challenge.
+	LockKindProtective = "protective"
+)
+
+// AccountLock blocks logins to an account
+type AccountLock struct {
+	Kind     string    `bson:"kind" json:"kind"`
+	Reason   string    `bson:"reason,omitempty" json:"reason,omitempty"`
+	LockedAt time.Time `bson:"locked_at" json:"locked_at"`
+	// LockedUntil is nil for locks that never expire
+	LockedUntil *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	LockedBy    *ID        `bson:"locked_by,omitempty" json:"-"`
+}
+
+// ActiveLock returns the lock on the account if it is still in force
+func (u *User) ActiveLock(now time.Time) *AccountLock {
+	if u.Lock == nil {
+		return nil
+	}
+	if u.Lock.LockedUntil != nil && !now.Before(*u.Lock.LockedUntil) {
+		return nil
+	}
+	return u.Lock
+}
+
+// Email challenge purposes
+const (
+	// ChallengePurposeUnlock lets the owner of a protectively locked
+	// account unlock it early
+	ChallengePurposeUnlock = "unlock"
+	// ChallengePurposeRecovery proves control of the email address when
+	// securing a compromised account
+	ChallengePurposeRecovery = "account_recovery"
+	// ChallengePurposeTwoFactorReset proves control of the email address
+	// when resetting a lost second factor
+	ChallengePurposeTwoFactorReset = "two_factor_reset"
+	// ChallengePurposeVerifyEmail proves the user owns the account's email
+	// address
+	ChallengePurposeVerifyEmail = "verify_email"
+	// ChallengePurposeSetPassword confirms adding a password to an account
+	// that signs in without one
+	ChallengePurposeSetPassword = "set_password"
+	// ChallengePurposeLogin signs the user in with the otp login method
+	ChallengePurposeLogin = "login"
+)
+
+// EmailChallenge is a one-time code sent to the account's email address
+type EmailChallenge struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    ID                 `bson:"user_id"`
+	Purpose   string             `bson:"purpose"`
+	CodeHash  string             `bson:"code_hash"`
+	Attempts  int                `bson:"attempts"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// PasswordReset records a password reset request. Requests for unknown
+// emails are recorded too, without a token, so they count towards the rate
+// limits.
+type PasswordReset struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Email     string             `bson:"email"`
+	IPAddress string             `bson:"ip_address"`
+	UserID    *ID                `bson:"user_id,omitempty"`
+	TokenHash string             `bson:"token_hash,omitempty"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
 }
 
 // InvalidatedToken represents a blacklisted JWT token
 type InvalidatedToken struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty"`
 	Token     string             `bson:"token"`
-	UserID    primitive.ObjectID `bson:"user_id"`
+	UserID    ID                 `bson:"user_id"`
 	ExpiresAt time.Time          `bson:"expires_at"`
 	CreatedAt time.Time          `bson:"created_at"`
 }
 
+// Session is an issued session token, by its "jti" claim. It records where
+// the token was issued to and when it was last used, for the idle timeout.
+// Tokens issued before sessions were tracked get one on their first use.
+type Session struct {
+	ID             string    `bson:"_id"`
+	UserID         ID        `bson:"user_id"`
+	UserAgent      string    `bson:"user_agent,omitempty"`
+	IPAddress      string    `bson:"ip_address,omitempty"`
+	IssuedAt       time.Time `bson:"issued_at"`
+	LastActivityAt time.Time `bson:"last_activity_at"`
+	ExpiresAt      time.Time `bson:"expires_at"`
+	// RevokedAt is set when the session is signed out or revoked, which
+	// rejects its token
+	RevokedAt *time.Time `bson:"revoked_at,omitempty"`
+	// AuthMethod is how the user signed in, such as "password" or "sso".
+	// It is empty for sessions started before it was recorded.
+	AuthMethod string `bson:"auth_method,omitempty"`
+}
+
+// RefreshToken is a refresh token of a session, stored by hash. Refreshing
+// consumes it and issues the next token of the session, so each session has
+// one usable refresh token at a time and the consumed ones are kept to
+// detect reuse.
+type RefreshToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	TokenHash string             `bson:"token_hash"`
+	// SessionID is the session the token refreshes. Its refresh tokens are
+	// a family, revoked together.
+	SessionID string    `bson:"session_id"`
+	UserID    ID        `bson:"user_id"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	// ConsumedAt is set when the token was exchanged for the next one
+	ConsumedAt *time.Time `bson:"consumed_at,omitempty"`
+	RevokedAt  *time.Time `bson:"revoked_at,omitempty"`
+}
+
 // LoginAttempt tracks login attempts for rate limiting
 type LoginAttempt struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty"`
@@ -35,3 +299,73 @@ type LoginAttempt struct {
 	Timestamp time.Time          `bson:"timestamp"`
 	Success   bool               `bson:"success"`
 }
+
+// Device is a client that has successfully logged in to an account
+type Device struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	UserID      ID                 `bson:"user_id"`
+	Fingerprint string             `bson:"fingerprint"`
+	// Name is the user's name for the device, such as "Work laptop"
+	Name          string    `bson:"name,omitempty"`
+	UserAgent     string    `bson:"user_agent"`
+	IPAddress     string    `bson:"ip_address"`
+	FirstSeenAt   time.Time `bson:"first_seen_at"`
+	LastSeenAt    time.Time `bson:"last_seen_at"`
+	SchemaVersion int       `bson:"schema_version"`
+}
+
+// Credential types, for the self-service listing of a user's passkeys and
+// trusted devices
+const (
+	CredentialTypePasskey       = "passkey"
+	CredentialTypeTrustedDevice = "trusted_device"
+)
+
+// Pending login statuses
+const (
+	PendingLoginStatusPending  = "pending"
+	PendingLoginStatusApproved = "approved"
+)
+
+// PendingLogin is a login from an unrecognised device awaiting approval
+// from one of the account's existing devices
+type PendingLogin struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty"`
+	UserID            ID                 `bson:"user_id"`
+	Fingerprint       string             `bson:"fingerprint"`
+	UserAgent         string             `bson:"user_agent"`
+	IPAddress         string             `bson:"ip_address"`
+	ApprovalTokenHash string             `bson:"approval_token_hash"`
+	Status            string             `bson:"status"`
+	ExpiresAt         time.Time          `bson:"expires_at"`
+	CreatedAt         time.Time          `bson:"created_at"`
+	ApprovedAt        *time.Time         `bson:"approved_at,omitempty"`
+}
+
+// Device login statuses
+const (
+	DeviceLoginStatusPending   = "pending"
+	DeviceLoginStatusApproved  = "approved"
+	DeviceLoginStatusCompleted = "completed"
+	DeviceLoginStatusDenied    = "denied"
+)
+
+// DeviceLogin is a cross-device login started by a device without a
+// keyboard (TV, CLI) and approved from an authenticated session, following
+// the OAuth 2.0 device authorization grant (RFC 8628)
+type DeviceLogin struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	DeviceCodeHash string             `bson:"device_code_hash"`
+	UserCode       string             `bson:"user_code"`
+	ClientID       string             `bson:"client_id"`
+	Scope          string             `bson:"scope"`
+	Status         string             `bson:"status"`
+	UserID         *ID                `bson:"user_id,omitempty"`
+	UserAgent      string             `bson:"user_agent"`
+	IPAddress      string             `bson:"ip_address"`
+	Interval       time.Duration      `bson:"interval"`
+	LastPolledAt   *time.Time         `bson:"last_polled_at,omitempty"`
+	ExpiresAt      time.Time          `bson:"expires_at"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	ApprovedAt     *time.Time         `bson:"approved_at,omitempty"`
+}
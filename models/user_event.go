@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserEvent is one change to a user document in the event-sourced user
+// history. Replaying a user's events in version order gives the document
+// at any point in time.
+type UserEvent struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty"`
+	UserID  ID                 `bson:"user_id"`
+	Version int64              `bson:"version"`
+	Type    string             `bson:"type"`
+	// Set holds the fields the change set, with their new values
+	Set bson.Raw `bson:"set,omitempty"`
+	// Unset lists the fields the change removed
+	Unset     []string  `bson:"unset,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// UserSnapshot is the user document as of an event version, so loading a
+// user does not replay its whole history
+type UserSnapshot struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    ID                 `bson:"user_id"`
+	Version   int64              `bson:"version"`
+	State     bson.Raw           `bson:"state"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Workflow is a flow of steps across collections, such as erasing an
+// account, run one step at a time. Each step is recorded as it completes,
+// so a flow interrupted by a failure or a restart resumes at the step it
+// stopped at instead of being left half done.
+type Workflow struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Kind        string             `bson:"kind"`
+	UserID      ID                 `bson:"user_id"`
+	RequestedBy ID                 `bson:"requested_by,omitempty"`
+	Status      string             `bson:"status"`
+	// Step is the index of the next step to run
+	Step int `bson:"step"`
+	// CompletedSteps names the steps run so far
+	CompletedSteps []string `bson:"completed_steps,omitempty"`
+	// Data holds the flow's inputs and what earlier steps pass on to
+	// later ones. It is cleared once the flow is done, since it may hold
+	// personal data.
+	Data map[string]string `bson:"data,omitempty"`
+	// Attempts counts the failed runs of the current step
+	Attempts int `bson:"attempts"`
+	// LockedUntil hides a running flow from other servers, and a failed
+	// step until it is retried
+	LockedUntil time.Time `bson:"locked_until"`
+	CreatedAt   time.Time `bson:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at"`
+	// CompletedAt is when the flow finished or gave up
+	CompletedAt *time.Time `bson:"completed_at,omitempty"`
+	// Error is why the last run of the current step failed
+	Error string `bson:"error,omitempty"`
+}
+
+// Kinds of workflows
+const (
+	// WorkflowErasure erases an account and everything kept about it
+	WorkflowErasure = "erasure"
+	// WorkflowAccountRecovery cleans up after SecureAccount
+	WorkflowAccountRecovery = "account_recovery"
+)
+
+// Workflow statuses
+const (
+	WorkflowRunning = "running"
+	WorkflowDone    = "done"
+	// WorkflowFailed is a flow whose step failed too many times. An admin
+	// can retry it.
+	WorkflowFailed = "failed"
+)
@@ -0,0 +1,76 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Senders the server can be configured with
+const (
+	SenderLog      = "log"
+	SenderSMTP     = "smtp"
+	SenderSendGrid = "sendgrid"
+)
+
+// ErrInvalidMessage is returned for messages whose recipient or subject
+// can't be put in an email header
+var ErrInvalidMessage = errors.New("invalid notification message")
+
+// Message is a notification addressed to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// validate rejects line breaks in the headers, which would let a recipient
+// address add headers or recipients of its own
+func (m Message) validate() error {
+	if m.To == "" || strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return ErrInvalidMessage
+	}
+	return nil
+}
+
+// Sender delivers notifications to users
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config selects and configures the sender of a server
+type Config struct {
+	// Sender is SenderLog, SenderSMTP or SenderSendGrid
+	Sender string
+	// From is the address emails are sent from
+	From           string
+	SMTP           SMTPConfig
+	SendGridAPIKey string
+}
+
+// New returns the sender config selects
+func New(config Config) (Sender, error) {
+	switch config.Sender {
+	case SenderLog, "":
+		return NewLogSender(), nil
+	case SenderSMTP:
+		return NewSMTPSender(config.SMTP, config.From)
+	case SenderSendGrid:
+		return NewSendGridSender(config.SendGridAPIKey, config.From)
+	}
+	return nil, fmt.Errorf("unknown sender %q", config.Sender)
+}
+
+// LogSender writes notifications to the server log instead of delivering them
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("Notification to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,93 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"time"
+)
+
+// sendGridURL is the endpoint of SendGrid's v3 mail send API
+const sendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridSender delivers notifications through SendGrid's HTTP API
+type SendGridSender struct {
+	apiKey     string
+	from       *mail.Address
+	httpClient *http.Client
+}
+
+// NewSendGridSender returns a sender authenticating with apiKey, which
+// needs the Mail Send permission, and sending from the address from
+func NewSendGridSender(apiKey, from string) (*SendGridSender, error) {
+	fromAddress, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %v", err)
+	}
+	return &SendGridSender{
+		apiKey:     apiKey,
+		from:       fromAddress,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+func (s *SendGridSender) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: msg.To}}}},
+		From:             sendGridAddress{Email: s.from.Address, Name: s.from.Name},
+		Subject:          msg.Subject,
+		Content:          []sendGridContent{{Type: "text/plain", Value: msg.Body}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendGridURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach SendGrid: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Accepted messages are queued with 202 and an empty body
+	if resp.StatusCode != http.StatusAccepted {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("SendGrid rejected the message with status %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// smtpTimeout bounds a delivery when the context has no earlier deadline
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig is how an SMTP server is reached
+type SMTPConfig struct {
+	// Addr is the server's host:port. Port 465 is spoken over TLS, other
+	// ports upgrade with STARTTLS.
+	Addr string
+	// Username and Password authenticate with PLAIN when Username is set
+	Username string
+	Password string
+}
+
+// SMTPSender delivers notifications through an SMTP server. It opens a
+// connection per message.
+type SMTPSender struct {
+	config SMTPConfig
+	from   *mail.Address
+}
+
+// NewSMTPSender returns a sender sending from the address from, such as
+// "Accounts <no-reply@example.com>"
+func NewSMTPSender(config SMTPConfig, from string) (*SMTPSender, error) {
+	fromAddress, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %v", err)
+	}
+	if _, _, err := net.SplitHostPort(config.Addr); err != nil {
+		return nil, fmt.Errorf("invalid SMTP address: %v", err)
+	}
+	return &SMTPSender{config: config, from: fromAddress}, nil
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	host, port, _ := net.SplitHostPort(s.config.Addr)
+
+	deadline := time.Now().Add(smtpTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	var err error
+	if port == "465" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", s.config.Addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.config.Addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %v", s.config.Addr, err)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to greet %s: %v", s.config.Addr, err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && port != "465" {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("failed to start TLS with %s: %v", s.config.Addr, err)
+		}
+	}
+	// PlainAuth refuses to send the password over a connection without TLS,
+	// except to localhost
+	if s.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, host)); err != nil {
+			return fmt.Errorf("failed to authenticate with %s: %v", s.config.Addr, err)
+		}
+	}
+
+	if err := client.Mail(s.from.Address); err != nil {
+		return fmt.Errorf("sender rejected: %v", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("recipient rejected: %v", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %v", err)
+	}
+	if _, err := w.Write(s.compose(msg)); err != nil {
+		return fmt.Errorf("failed to write message: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %v", err)
+	}
+	return client.Quit()
+}
+
+// compose writes msg as a plain text email
+func (s *SMTPSender) compose(msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	qp.Write([]byte(msg.Body))
+	qp.Close()
+	return buf.Bytes()
+}
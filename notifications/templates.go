@@ -0,0 +1,423 @@
+package notifications
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"text/template"
+)
+
+// Template names
+const (
+	TemplateLoginApproval   = "login_approval"
+	TemplateAccountUnlock   = "account_unlock"
+	TemplateAccountLocked   = "account_locked"
+	TemplateNewDeviceLogin  = "new_device_login"
+	TemplateAccountRecovery = "account_recovery"
+	TemplateAccountSecured  = "account_secured"
+	TemplatePasswordReset   = "password_reset"
+	TemplateVerifyEmail     = "verify_email"
+	TemplateSetPassword     = "set_password"
+	TemplateLoginCode       = "login_code"
+
+	// TemplateAdminPasswordReset is a reset link sent because an admin
+	// reset the password
+	TemplateAdminPasswordReset = "admin_password_reset"
+
+	TemplateTwoFactorResetCode     = "two_factor_reset_code"
+	TemplateTwoFactorResetStarted  = "two_factor_reset_started"
+	TemplateTwoFactorResetComplete = "two_factor_reset_complete"
+
+	TemplateProfileChangeApproved = "profile_change_approved"
+	TemplateProfileChangeRejected = "profile_change_rejected"
+
+	TemplateDeprovisioningReport = "deprovisioning_report"
+
+	// TemplateScheduledAction is notice of an admin action scheduled on
+	// the account
+	TemplateScheduledAction = "scheduled_action"
+	// TemplateAccountErased confirms that an account and its data were
+	// erased
+	TemplateAccountErased = "account_erased"
+)
+
+var ErrUnknownTemplate = errors.New("unknown notification template")
+
+// Template is a named email with subject and body written as text/template
+// sources. SampleData fills every field the template uses so it can be
+// previewed without a real event.
+type Template struct {
+	Name       string
+	Subject    string
+	Body       string
+	SampleData map[string]string
+}
+
+var templates = map[string]Template{
+	TemplateLoginApproval: {
+		Name:    TemplateLoginApproval,
+		Subject: "Approve new sign-in",
+		Body: `A sign-in to your account was attempted from a new device.
+
+Device: {{.UserAgent}}
+IP address: {{.IPAddress}}
+
+If this was you, approve it within {{.ExpiresIn}}:
+{{.ApprovalLink}}
+
+If this wasn't you, change your password.`,
+		SampleData: map[string]string{
+			"UserAgent":    "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5)",
+			"IPAddress":    "203.0.113.7",
+			"ExpiresIn":    "15m0s",
+			"ApprovalLink": "https://example.com/approve-login?token=sample",
+		},
+	},
+	TemplateAccountLocked: {
+		Name:    TemplateAccountLocked,
+		Subject: "Your account was locked",
+		Body: `Your account was locked after several failed sign-in attempts in a row.
+
+The lock lifts on its own at {{.LockedUntil}}. To unlock it sooner, request an unlock code from the sign-in page.
+
+If you didn't try to sign in, someone may be guessing your password. Change it once you're back in.`,
+		SampleData: map[string]string{
+			"LockedUntil": "2025-01-01T12:15:00Z",
+		},
+	},
+	TemplateNewDeviceLogin: {
+		Name:    TemplateNewDeviceLogin,
+		Subject: "New sign-in to your account",
+		Body: `Your account was signed in to from a device it hasn't been used on before.
+
+Device: {{.UserAgent}}
+IP address: {{.IPAddress}}
+Time: {{.Time}}
+
+If this was you, there's nothing to do. If it wasn't, change your password and sign out of your other sessions.`,
+		SampleData: map[string]string{
+			"UserAgent": "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5)",
+			"IPAddress": "203.0.113.7",
+			"Time":      "2025-01-01T12:00:00Z",
+		},
+	},
+	TemplateAccountUnlock: {
+		Name:    TemplateAccountUnlock,
+		Subject: "Unlock your account",
+		Body: `Your account was locked after several failed sign-in attempts.
+
+To unlock it now, enter this code together with your password:
+{{.Code}}
+
+The code expires in {{.ExpiresIn}}. The lock lifts on its own at {{.LockedUntil}}.
+
+If you didn't try to sign in, someone may be guessing your password. Change it once you're back in.`,
+		SampleData: map[string]string{
+			"Code":        "482913",
+			"ExpiresIn":   "10m0s",
+			"LockedUntil": "2025-01-01T12:15:00Z",
+		},
+	},
+	TemplateAccountRecovery: {
+		Name:    TemplateAccountRecovery,
+		Subject: "Secure your account",
+		Body: `Someone asked to secure your account because it may have been taken over.
+
+Enter this code together with one of your recovery codes to continue:
+{{.Code}}
+
+The code expires in {{.ExpiresIn}}.
+
+If you didn't ask for this, you can ignore this email.`,
+		SampleData: map[string]string{
+			"Code":      "482913",
+			"ExpiresIn": "10m0s",
+		},
+	},
+	TemplateAccountSecured: {
+		Name:    TemplateAccountSecured,
+		Subject: "Your account was secured",
+		Body: `Your account was secured from {{.IPAddress}}.
+
+- Your password was changed
+- Every device was signed out
+- {{.DevicesRemoved}} trusted device(s) were removed
+- Two-factor authentication was {{.TwoFactor}}
+- {{.RecoveryCodesLeft}} recovery code(s) are left
+
+If you didn't do this, contact support right away.`,
+		SampleData: map[string]string{
+			"IPAddress":         "203.0.113.7",
+			"DevicesRemoved":    "2",
+			"TwoFactor":         "reset, set it up again",
+			"RecoveryCodesLeft": "9",
+		},
+	},
+	TemplatePasswordReset: {
+		Name:    TemplatePasswordReset,
+		Subject: "Reset your password",
+		Body: `Someone asked to reset the password for your account from {{.IPAddress}}.
+
+To choose a new password, open this link:
+{{.ResetLink}}
+
+The link expires in {{.ExpiresIn}} and works once. Resetting your password signs you out on every device.
+
+If you didn't ask for this, you can ignore this email. Your password stays the same.`,
+		SampleData: map[string]string{
+			"IPAddress": "203.0.113.7",
+			"ResetLink": "https://example.com/reset-password?token=sample",
+			"ExpiresIn": "30m0s",
+		},
+	},
+	TemplateAdminPasswordReset: {
+		Name:    TemplateAdminPasswordReset,
+		Subject: "Your password was reset",
+		Body: `An administrator reset the password of your account, and every device was signed out.
+
+To choose a new password, open this link:
+{{.ResetLink}}
+
+The link expires in {{.ExpiresIn}} and works once. If it expires, use "Forgot password" to get a new one.`,
+		SampleData: map[string]string{
+			"ResetLink": "https://example.com/reset-password?token=sample",
+			"ExpiresIn": "30m0s",
+		},
+	},
+	TemplateVerifyEmail: {
+		Name:    TemplateVerifyEmail,
+		Subject: "Verify your email address",
+		Body: `Enter this code to confirm this is your email address:
+
+{{.Code}}
+
+The code expires in {{.ExpiresIn}}. If you didn't create an account or change your email address, you can ignore this email.`,
+		SampleData: map[string]string{
+			"Code":      "307415",
+			"ExpiresIn": "10m0s",
+		},
+	},
+	TemplateSetPassword: {
+		Name:    TemplateSetPassword,
+		Subject: "Confirm adding a password",
+		Body: `Enter this code to add a password to your account, so you can also sign in with your email address and password:
+
+{{.Code}}
+
+The code expires in {{.ExpiresIn}}. If you didn't ask to add a password, someone may be signed in to your account. Sign out your other sessions and check your account.`,
+		SampleData: map[string]string{
+			"Code":      "581046",
+			"ExpiresIn": "10m0s",
+		},
+	},
+	TemplateLoginCode: {
+		Name:    TemplateLoginCode,
+		Subject: "Your sign-in code",
+		Body: `Enter this code to sign in:
+
+{{.Code}}
+
+The code expires in {{.ExpiresIn}}. If you didn't try to sign in, you can ignore this email. Nobody can sign in without the code.`,
+		SampleData: map[string]string{
+			"Code":      "640193",
+			"ExpiresIn": "10m0s",
+		},
+	},
+	TemplateTwoFactorResetCode: {
+		Name:    TemplateTwoFactorResetCode,
+		Subject: "Your two-factor reset code",
+		Body: `Use this code to reset two-factor authentication on your account:
+
+{{.Code}}
+
+The code expires in {{.ExpiresIn}}. If you didn't ask for it, someone may know your password. Change it right away.`,
+		SampleData: map[string]string{
+			"Code":      "123456",
+			"ExpiresIn": "10m0s",
+		},
+	},
+	TemplateTwoFactorResetStarted: {
+		Name:    TemplateTwoFactorResetStarted,
+		Subject: "Two-factor authentication will be removed",
+		Body: `A reset of two-factor authentication on your account was requested from {{.IPAddress}}, proven with: {{.Factors}}.
+
+Two-factor authentication will be removed on {{.EffectiveAt}}. Until then nothing changes.
+
+If you didn't ask for this, cancel it now:
+{{.CancelLink}}
+
+Canceling signs out every device. Then change your password, because whoever asked for the reset knows it.`,
+		SampleData: map[string]string{
+			"IPAddress":   "203.0.113.7",
+			"Factors":     "password, email code",
+			"EffectiveAt": "2024-01-04T09:00:00Z",
+			"CancelLink":  "https://example.com/cancel-two-factor-reset?token=sample",
+		},
+	},
+	TemplateTwoFactorResetComplete: {
+		Name:    TemplateTwoFactorResetComplete,
+		Subject: "Two-factor authentication was removed",
+		Body: `Two-factor authentication was removed from your account, as requested on {{.RequestedAt}}.
+
+Sign in and set it up again to keep your account protected.
+
+If you didn't ask for this, contact support right away.`,
+		SampleData: map[string]string{
+			"RequestedAt": "2024-01-01T09:00:00Z",
+		},
+	},
+	TemplateProfileChangeApproved: {
+		Name:    TemplateProfileChangeApproved,
+		Subject: "Your profile change was approved",
+		Body: `Hi {{.Name}},
+
+Your organization approved the change to your profile. It is now in effect.
+
+{{.Changes}}`,
+		SampleData: map[string]string{
+			"Name":    "Jane Doe",
+			"Changes": "Name: Jane Doe",
+		},
+	},
+	TemplateProfileChangeRejected: {
+		Name:    TemplateProfileChangeRejected,
+		Subject: "Your profile change was not approved",
+		Body: `Hi {{.Name}},
+
+Your organization did not approve the change to your profile.
+
+{{.Changes}}
+
+Reason: {{.Reason}}`,
+		SampleData: map[string]string{
+			"Name":    "Jane Doe",
+			"Changes": "Email: jane@example.com",
+			"Reason":  "Use your company email address",
+		},
+	},
+	TemplateDeprovisioningReport: {
+		Name:    TemplateDeprovisioningReport,
+		Subject: "Members of {{.Organization}} were deprovisioned",
+		Body: `Accounts of {{.Organization}} were deactivated because {{.Reason}}.
+
+Deactivated: {{.Deactivated}}
+Sessions signed out: {{.SessionsRevoked}}
+Left active: {{.Skipped}}
+
+{{.Accounts}}
+
+Accounts left active belong to admins. Deactivate them yourself if they should no longer have access.`,
+		SampleData: map[string]string{
+			"Organization":    "Example Corp",
+			"Reason":          "the identity provider deleted their identities",
+			"Deactivated":     "2",
+			"SessionsRevoked": "3",
+			"Skipped":         "0",
+			"Accounts":        "jane@example.com\njohn@example.com",
+		},
+	},
+	TemplateScheduledAction: {
+		Name:    TemplateScheduledAction,
+		Subject: "Your account will be {{.Action}} on {{.RunDate}}",
+		Body: `Hi {{.Name}},
+
+Your account is scheduled to be {{.Action}} at {{.RunAt}}.{{if .Reason}}
+
+Reason: {{.Reason}}{{end}}
+
+If you think this is a mistake, contact your administrator before then.`,
+		SampleData: map[string]string{
+			"Name":    "Jane Doe",
+			"Action":  "deactivated",
+			"RunDate": "2025-01-31",
+			"RunAt":   "2025-01-31T17:00:00Z",
+			"Reason":  "Contract ended",
+		},
+	},
+	TemplateAccountErased: {
+		Name:    TemplateAccountErased,
+		Subject: "Your account was erased",
+		Body: `Hi {{.Name}},
+
+Your account and the data kept about it were erased on {{.ErasedAt}}, as requested. You can't sign in to it or restore it.
+
+The audit log of changes to the account is kept, without the addresses and browsers it was used from.`,
+		SampleData: map[string]string{
+			"Name":     "Jane Doe",
+			"ErasedAt": "2025-01-31T17:00:00Z",
+		},
+	},
+}
+
+// Templates returns all registered templates sorted by name
+func Templates() []Template {
+	list := make([]Template, 0, len(templates))
+	for _, t := range templates {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// LookupTemplate returns the template registered under name
+func LookupTemplate(name string) (Template, error) {
+	t, ok := templates[name]
+	if !ok {
+		return Template{}, ErrUnknownTemplate
+	}
+	return t, nil
+}
+
+// Render executes a template for one recipient. Missing fields are an error
+// so a broken template is caught when previewed rather than sent.
+func Render(name, to string, data map[string]string) (Message, error) {
+	t, err := LookupTemplate(name)
+	if err != nil {
+		return Message{}, err
+	}
+
+	subject, err := execute(t.Name+".subject", t.Subject, data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	body, err := execute(t.Name+".body", t.Body, data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{To: to, Subject: subject, Body: body}, nil
+}
+
+// RenderSample renders a template with its sample data, overridden by any
+// values in data
+func RenderSample(name, to string, data map[string]string) (Message, error) {
+	t, err := LookupTemplate(name)
+	if err != nil {
+		return Message{}, err
+	}
+
+	merged := make(map[string]string, len(t.SampleData)+len(data))
+	for k, v := range t.SampleData {
+		merged[k] = v
+	}
+	for k, v := range data {
+		merged[k] = v
+	}
+
+	return Render(name, to, merged)
+}
+
+func execute(name, source string, data map[string]string) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %v", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %v", name, err)
+	}
+	return buf.String(), nil
+}
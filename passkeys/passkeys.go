@@ -0,0 +1,419 @@
+// Package passkeys registers WebAuthn credentials, holding them to the
+// deployment's attestation policy, and signs users in with them.
+package passkeys
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+// Attestation policies. Each asks the client for that attestation
+// conveyance and rejects credentials without the matching attestation.
+const (
+	// AttestationNone accepts credentials without attestation
+	AttestationNone = "none"
+	// AttestationIndirect requires an attestation statement, which may be
+	// self-signed or anonymized by the client
+	AttestationIndirect = "indirect"
+	// AttestationDirect requires an attestation statement signed with the
+	// authenticator's attestation certificate
+	AttestationDirect = "direct"
+)
+
+// Resident key requirements. A resident, or discoverable, credential lets
+// users sign in without typing their email first.
+const (
+	ResidentKeyDiscouraged = "discouraged"
+	ResidentKeyPreferred   = "preferred"
+	ResidentKeyRequired    = "required"
+)
+
+// RegistrationTimeout is how long a user has to complete a registration
+const RegistrationTimeout = 5 * time.Minute
+
+// LoginTimeout is how long a user has to complete a login
+const LoginTimeout = 5 * time.Minute
+
+// ErrInvalidCredential is returned for registration and login responses
+// that are malformed or fail WebAuthn verification
+var ErrInvalidCredential = errors.New("invalid credential")
+
+// PolicyError is returned for valid credentials the policy rejects. Its
+// message can be shown to the user.
+type PolicyError struct {
+	Message string
+}
+
+func (e PolicyError) Error() string {
+	return e.Message
+}
+
+// Policy is the deployment's requirements for new credentials
+type Policy struct {
+	Attestation string
+	// AllowedAAGUIDs limits registration to these authenticator models.
+	// Empty allows any. Needs an attestation policy other than none,
+	// since AAGUIDs without attestation are not vouched for.
+	AllowedAAGUIDs []string
+	ResidentKey    string
+}
+
+// Validate rejects policies that cannot be enforced
+func (p Policy) Validate() error {
+	switch p.Attestation {
+	case AttestationNone, AttestationIndirect, AttestationDirect:
+	default:
+		return fmt.Errorf("attestation must be %s, %s or %s", AttestationNone, AttestationIndirect, AttestationDirect)
+	}
+	switch p.ResidentKey {
+	case ResidentKeyDiscouraged, ResidentKeyPreferred, ResidentKeyRequired:
+	default:
+		return fmt.Errorf("resident_key must be %s, %s or %s", ResidentKeyDiscouraged, ResidentKeyPreferred, ResidentKeyRequired)
+	}
+	for _, aaguid := range p.AllowedAAGUIDs {
+		if _, err := uuid.Parse(aaguid); err != nil {
+			return fmt.Errorf("allowed_aaguids: %q is not a UUID", aaguid)
+		}
+	}
+	if len(p.AllowedAAGUIDs) > 0 && p.Attestation == AttestationNone {
+		return fmt.Errorf("allowed_aaguids needs attestation %s or %s", AttestationIndirect, AttestationDirect)
+	}
+	return nil
+}
+
+type Config struct {
+	// RPID is the relying party ID, the domain credentials are bound to
+	RPID string
+	// RPDisplayName names the service in authenticator prompts
+	RPDisplayName string
+	// Origins are the web origins registrations may come from
+	Origins []string
+	Policy  Policy
+}
+
+// RelyingParty registers credentials and signs users in with them for one
+// relying party ID
+type RelyingParty struct {
+	webauthn *webauthn.WebAuthn
+	policy   Policy
+	allowed  map[uuid.UUID]bool
+}
+
+func New(config Config) (*RelyingParty, error) {
+	if err := config.Policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:                  config.RPID,
+		RPDisplayName:         config.RPDisplayName,
+		RPOrigins:             config.Origins,
+		AttestationPreference: protocol.ConveyancePreference(config.Policy.Attestation),
+		Timeouts: webauthn.TimeoutsConfig{
+			Registration: webauthn.TimeoutConfig{
+				Enforce:    true,
+				Timeout:    RegistrationTimeout,
+				TimeoutUVD: RegistrationTimeout,
+			},
+			Login: webauthn.TimeoutConfig{
+				Enforce:    true,
+				Timeout:    LoginTimeout,
+				TimeoutUVD: LoginTimeout,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[uuid.UUID]bool, len(config.Policy.AllowedAAGUIDs))
+	for _, aaguid := range config.Policy.AllowedAAGUIDs {
+		allowed[uuid.MustParse(aaguid)] = true
+	}
+
+	return &RelyingParty{
+		webauthn: w,
+		policy:   config.Policy,
+		allowed:  allowed,
+	}, nil
+}
+
+// User is the account a credential is registered for
+type User struct {
+	// ID is the user handle stored with the credential
+	ID          string
+	Name        string
+	DisplayName string
+	// CredentialIDs are the credentials the user already has, which
+	// authenticators are asked not to register again
+	CredentialIDs [][]byte
+}
+
+// webauthnUser adapts User to webauthn.User
+type webauthnUser User
+
+func (u webauthnUser) WebAuthnID() []byte {
+	return []byte(u.ID)
+}
+
+func (u webauthnUser) WebAuthnName() string {
+	return u.Name
+}
+
+func (u webauthnUser) WebAuthnDisplayName() string {
+	return u.DisplayName
+}
+
+func (u webauthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.CredentialIDs))
+	for i, id := range u.CredentialIDs {
+		credentials[i] = webauthn.Credential{ID: id}
+	}
+	return credentials
+}
+
+// Credential is a verified new credential, or a registered one to sign in
+// with
+type Credential struct {
+	ID        []byte
+	PublicKey []byte
+	// AttestationFormat is the attestation statement format, "none" when
+	// there was no attestation
+	AttestationFormat string
+	AAGUID            uuid.UUID
+	SignCount         uint32
+	Transports        []string
+	// BackupEligible credentials can be synced between devices
+	BackupEligible bool
+	BackupState    bool
+	// Discoverable is whether the credential is resident, nil when the
+	// client didn't say
+	Discoverable *bool
+}
+
+// BeginRegistration starts registering a credential for user. It returns
+// the options to pass to navigator.credentials.create() as JSON, and the
+// session to keep until FinishRegistration.
+func (rp *RelyingParty) BeginRegistration(user User) (options, session []byte, err error) {
+	wu := webauthnUser(user)
+	creation, sessionData, err := rp.webauthn.BeginRegistration(wu,
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirement(rp.policy.ResidentKey)),
+		webauthn.WithExclusions(webauthn.Credentials(wu.WebAuthnCredentials()).CredentialDescriptors()),
+		// Ask the client to report whether the credential is discoverable
+		webauthn.WithExtensions(protocol.AuthenticationExtensions{"credProps": true}),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin registration: %v", err)
+	}
+
+	if options, err = json.Marshal(creation); err != nil {
+		return nil, nil, err
+	}
+	if session, err = json.Marshal(sessionData); err != nil {
+		return nil, nil, err
+	}
+	return options, session, nil
+}
+
+// FinishRegistration verifies the client's response to the options from
+// BeginRegistration and applies the policy. It returns ErrInvalidCredential
+// or a PolicyError for credentials it rejects.
+func (rp *RelyingParty) FinishRegistration(user User, session, response []byte) (*Credential, error) {
+	var sessionData webauthn.SessionData
+	if err := json.Unmarshal(session, &sessionData); err != nil {
+		return nil, fmt.Errorf("failed to decode registration session: %v", err)
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCredential, describe(err))
+	}
+
+	credential, err := rp.webauthn.CreateCredential(webauthnUser(user), sessionData, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCredential, describe(err))
+	}
+
+	aaguid, err := uuid.FromBytes(credential.Authenticator.AAGUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid AAGUID", ErrInvalidCredential)
+	}
+
+	result := &Credential{
+		ID:                credential.ID,
+		PublicKey:         credential.PublicKey,
+		AttestationFormat: credential.AttestationType,
+		AAGUID:            aaguid,
+		SignCount:         credential.Authenticator.SignCount,
+		BackupEligible:    credential.Flags.BackupEligible,
+		BackupState:       credential.Flags.BackupState,
+		Discoverable:      discoverable(parsed.ClientExtensionResults),
+	}
+	for _, transport := range credential.Transport {
+		result.Transports = append(result.Transports, string(transport))
+	}
+
+	if err := rp.enforce(result, parsed.Response.AttestationObject); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// loginUser adapts User to webauthn.User with the registered credentials
+// it can sign in with
+type loginUser struct {
+	webauthnUser
+	credentials []webauthn.Credential
+}
+
+func (u loginUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func newLoginUser(user User, credentials []Credential) loginUser {
+	u := loginUser{webauthnUser: webauthnUser(user)}
+	for _, credential := range credentials {
+		c := webauthn.Credential{
+			ID:              credential.ID,
+			PublicKey:       credential.PublicKey,
+			AttestationType: credential.AttestationFormat,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: credential.BackupEligible,
+				BackupState:    credential.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    credential.AAGUID[:],
+				SignCount: credential.SignCount,
+			},
+		}
+		for _, transport := range credential.Transports {
+			c.Transport = append(c.Transport, protocol.AuthenticatorTransport(transport))
+		}
+		u.credentials = append(u.credentials, c)
+	}
+	return u
+}
+
+// Assertion is a verified login
+type Assertion struct {
+	// CredentialID is the credential the user signed in with
+	CredentialID []byte
+	// SignCount and BackupState are the credential's new values
+	SignCount   uint32
+	BackupState bool
+}
+
+// BeginLogin starts a login of user with one of their registered
+// credentials. It returns the options to pass to
+// navigator.credentials.get() as JSON, and the session to keep until
+// FinishLogin. The authenticator must verify the user, by PIN or
+// biometrics, so the passkey alone proves two factors.
+func (rp *RelyingParty) BeginLogin(user User, credentials []Credential) (options, session []byte, err error) {
+	assertion, sessionData, err := rp.webauthn.BeginLogin(newLoginUser(user, credentials),
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin login: %v", err)
+	}
+
+	if options, err = json.Marshal(assertion); err != nil {
+		return nil, nil, err
+	}
+	if session, err = json.Marshal(sessionData); err != nil {
+		return nil, nil, err
+	}
+	return options, session, nil
+}
+
+// FinishLogin verifies the client's response to the options from
+// BeginLogin. It returns ErrInvalidCredential for responses it rejects,
+// including those whose signature counter didn't increase, a sign the
+// authenticator was cloned.
+func (rp *RelyingParty) FinishLogin(user User, credentials []Credential, session, response []byte) (*Assertion, error) {
+	var sessionData webauthn.SessionData
+	if err := json.Unmarshal(session, &sessionData); err != nil {
+		return nil, fmt.Errorf("failed to decode login session: %v", err)
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCredential, describe(err))
+	}
+
+	credential, err := rp.webauthn.ValidateLogin(newLoginUser(user, credentials), sessionData, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCredential, describe(err))
+	}
+	if credential.Authenticator.CloneWarning {
+		return nil, fmt.Errorf("%w: signature counter did not increase", ErrInvalidCredential)
+	}
+
+	return &Assertion{
+		CredentialID: credential.ID,
+		SignCount:    credential.Authenticator.SignCount,
+		BackupState:  credential.Flags.BackupState,
+	}, nil
+}
+
+// enforce applies the policy to a verified credential
+func (rp *RelyingParty) enforce(credential *Credential, attestation protocol.AttestationObject) error {
+	format := protocol.AttestationFormat(attestation.Format)
+
+	switch rp.policy.Attestation {
+	case AttestationIndirect:
+		if format == protocol.AttestationFormatNone {
+			return PolicyError{Message: "this authenticator did not provide attestation, which is required"}
+		}
+	case AttestationDirect:
+		// Packed attestation without a certificate chain is self-signed by
+		// the credential itself
+		_, hasCertificate := attestation.AttStatement["x5c"]
+		if format == protocol.AttestationFormatNone || (format == protocol.AttestationFormatPacked && !hasCertificate) {
+			return PolicyError{Message: "this authenticator did not provide attestation from its manufacturer, which is required"}
+		}
+	}
+
+	if len(rp.allowed) > 0 && !rp.allowed[credential.AAGUID] {
+		return PolicyError{Message: "this authenticator model is not allowed"}
+	}
+
+	// Clients that don't support credProps can't say; the client already
+	// refuses to create a non-discoverable credential when one is required
+	if rp.policy.ResidentKey == ResidentKeyRequired && credential.Discoverable != nil && !*credential.Discoverable {
+		return PolicyError{Message: "a passkey stored on the authenticator is required"}
+	}
+
+	return nil
+}
+
+// discoverable reads the credProps extension output
+func discoverable(results protocol.AuthenticationExtensionsClientOutputs) *bool {
+	props, ok := results["credProps"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	rk, ok := props["rk"].(bool)
+	if !ok {
+		return nil
+	}
+	return &rk
+}
+
+// describe returns the most helpful message of a WebAuthn error
+func describe(err error) string {
+	var protocolErr *protocol.Error
+	if errors.As(err, &protocolErr) {
+		if protocolErr.DevInfo != "" {
+			return strings.TrimSpace(protocolErr.Details + ": " + protocolErr.DevInfo)
+		}
+		return protocolErr.Details
+	}
+	return err.Error()
+}
@@ -0,0 +1,318 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.31.1
+// source: proto/contract.proto
+
+package user
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Caller is who makes a conformance call
+type Caller int32
+
+const (
+	Caller_CALLER_ANONYMOUS Caller = 0
+	// A signed-in user without the admin role
+	Caller_CALLER_USER  Caller = 1
+	Caller_CALLER_ADMIN Caller = 2
+)
+
+// Enum value maps for Caller.
+var (
+	Caller_name = map[int32]string{
+		0: "CALLER_ANONYMOUS",
+		1: "CALLER_USER",
+		2: "CALLER_ADMIN",
+	}
+	Caller_value = map[string]int32{
+		"CALLER_ANONYMOUS": 0,
+		"CALLER_USER":      1,
+		"CALLER_ADMIN":     2,
+	}
+)
+
+func (x Caller) Enum() *Caller {
+	p := new(Caller)
+	*p = x
+	return p
+}
+
+func (x Caller) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Caller) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_contract_proto_enumTypes[0].Descriptor()
+}
+
+func (Caller) Type() protoreflect.EnumType {
+	return &file_proto_contract_proto_enumTypes[0]
+}
+
+func (x Caller) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Caller.Descriptor instead.
+func (Caller) EnumDescriptor() ([]byte, []int) {
+	return file_proto_contract_proto_rawDescGZIP(), []int{0}
+}
+
+// Contract is behaviour every implementation of a method must keep.
+// cmd/contractgen turns contracts into conformance cases, which
+// cmd/contracttest runs against a server.
+type Contract struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Calls without a valid bearer token fail with UNAUTHENTICATED
+	RequiresAuth bool `protobuf:"varint,1,opt,name=requires_auth,json=requiresAuth,proto3" json:"requires_auth,omitempty"`
+	// Calls by a user without the admin role fail with PERMISSION_DENIED.
+	// Implies requires_auth.
+	RequiresAdmin bool `protobuf:"varint,2,opt,name=requires_admin,json=requiresAdmin,proto3" json:"requires_admin,omitempty"`
+	// Request used to check the auth requirements, in protojson; defaults
+	// to {}. Set it when an empty request fails validation before auth.
+	AuthRequest   string       `protobuf:"bytes,3,opt,name=auth_request,json=authRequest,proto3" json:"auth_request,omitempty"`
+	Errors        []*ErrorCase `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Contract) Reset() {
+	*x = Contract{}
+	mi := &file_proto_contract_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Contract) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Contract) ProtoMessage() {}
+
+func (x *Contract) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_contract_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Contract.ProtoReflect.Descriptor instead.
+func (*Contract) Descriptor() ([]byte, []int) {
+	return file_proto_contract_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Contract) GetRequiresAuth() bool {
+	if x != nil {
+		return x.RequiresAuth
+	}
+	return false
+}
+
+func (x *Contract) GetRequiresAdmin() bool {
+	if x != nil {
+		return x.RequiresAdmin
+	}
+	return false
+}
+
+func (x *Contract) GetAuthRequest() string {
+	if x != nil {
+		return x.AuthRequest
+	}
+	return ""
+}
+
+func (x *Contract) GetErrors() []*ErrorCase {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+// ErrorCase is a request that must fail with a given code
+type ErrorCase struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// What the case checks, e.g. "rejects an invalid email"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Request in protojson
+	Request string `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	// Expected code by name, e.g. "INVALID_ARGUMENT"
+	Code          string `protobuf:"bytes,3,opt,name=code,proto3" json:"code,omitempty"`
+	Caller        Caller `protobuf:"varint,4,opt,name=caller,proto3,enum=user.Caller" json:"caller,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorCase) Reset() {
+	*x = ErrorCase{}
+	mi := &file_proto_contract_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorCase) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorCase) ProtoMessage() {}
+
+func (x *ErrorCase) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_contract_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorCase.ProtoReflect.Descriptor instead.
+func (*ErrorCase) Descriptor() ([]byte, []int) {
+	return file_proto_contract_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ErrorCase) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ErrorCase) GetRequest() string {
+	if x != nil {
+		return x.Request
+	}
+	return ""
+}
+
+func (x *ErrorCase) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ErrorCase) GetCaller() Caller {
+	if x != nil {
+		return x.Caller
+	}
+	return Caller_CALLER_ANONYMOUS
+}
+
+var file_proto_contract_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*Contract)(nil),
+		Field:         51000,
+		Name:          "user.contract",
+		Tag:           "bytes,51000,opt,name=contract",
+		Filename:      "proto/contract.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional user.Contract contract = 51000;
+	E_Contract = &file_proto_contract_proto_extTypes[0]
+)
+
+var File_proto_contract_proto protoreflect.FileDescriptor
+
+const file_proto_contract_proto_rawDesc = "" +
+	"\n" +
+	"\x14proto/contract.proto\x12\x04user\x1a google/protobuf/descriptor.proto\"\xa2\x01\n" +
+	"\bContract\x12#\n" +
+	"\rrequires_auth\x18\x01 \x01(\bR\frequiresAuth\x12%\n" +
+	"\x0erequires_admin\x18\x02 \x01(\bR\rrequiresAdmin\x12!\n" +
+	"\fauth_request\x18\x03 \x01(\tR\vauthRequest\x12'\n" +
+	"\x06errors\x18\x04 \x03(\v2\x0f.user.ErrorCaseR\x06errors\"s\n" +
+	"\tErrorCase\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\arequest\x18\x02 \x01(\tR\arequest\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\tR\x04code\x12$\n" +
+	"\x06caller\x18\x04 \x01(\x0e2\f.user.CallerR\x06caller*A\n" +
+	"\x06Caller\x12\x14\n" +
+	"\x10CALLER_ANONYMOUS\x10\x00\x12\x0f\n" +
+	"\vCALLER_USER\x10\x01\x12\x10\n" +
+	"\fCALLER_ADMIN\x10\x02:L\n" +
+	"\bcontract\x12\x1e.google.protobuf.MethodOptions\x18\xb8\x8e\x03 \x01(\v2\x0e.user.ContractR\bcontractB\bZ\x06./userb\x06proto3"
+
+var (
+	file_proto_contract_proto_rawDescOnce sync.Once
+	file_proto_contract_proto_rawDescData []byte
+)
+
+func file_proto_contract_proto_rawDescGZIP() []byte {
+	file_proto_contract_proto_rawDescOnce.Do(func() {
+		file_proto_contract_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_contract_proto_rawDesc), len(file_proto_contract_proto_rawDesc)))
+	})
+	return file_proto_contract_proto_rawDescData
+}
+
+var file_proto_contract_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_contract_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_contract_proto_goTypes = []any{
+	(Caller)(0),                        // 0: user.Caller
+	(*Contract)(nil),                   // 1: user.Contract
+	(*ErrorCase)(nil),                  // 2: user.ErrorCase
+	(*descriptorpb.MethodOptions)(nil), // 3: google.protobuf.MethodOptions
+}
+var file_proto_contract_proto_depIdxs = []int32{
+	2, // 0: user.Contract.errors:type_name -> user.ErrorCase
+	0, // 1: user.ErrorCase.caller:type_name -> user.Caller
+	3, // 2: user.contract:extendee -> google.protobuf.MethodOptions
+	1, // 3: user.contract:type_name -> user.Contract
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	3, // [3:4] is the sub-list for extension type_name
+	2, // [2:3] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_proto_contract_proto_init() }
+func file_proto_contract_proto_init() {
+	if File_proto_contract_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_contract_proto_rawDesc), len(file_proto_contract_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_contract_proto_goTypes,
+		DependencyIndexes: file_proto_contract_proto_depIdxs,
+		EnumInfos:         file_proto_contract_proto_enumTypes,
+		MessageInfos:      file_proto_contract_proto_msgTypes,
+		ExtensionInfos:    file_proto_contract_proto_extTypes,
+	}.Build()
+	File_proto_contract_proto = out.File
+	file_proto_contract_proto_goTypes = nil
+	file_proto_contract_proto_depIdxs = nil
+}
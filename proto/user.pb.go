@@ -9,6 +9,8 @@ package user
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -24,14 +26,17 @@ const (
 
 // User message definition
 type User struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
-	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	IsActive      bool                   `protobuf:"varint,6,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	IsDeleted     bool                   `protobuf:"varint,7,opt,name=is_deleted,json=isDeleted,proto3" json:"is_deleted,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email     string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Name      string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	IsActive  bool                   `protobuf:"varint,6,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	IsDeleted bool                   `protobuf:"varint,7,opt,name=is_deleted,json=isDeleted,proto3" json:"is_deleted,omitempty"`
+	// External system name to the user's ID in that system. Not set by
+	// ListUsers.
+	ExternalIds   map[string]string `protobuf:"bytes,8,rep,name=external_ids,json=externalIds,proto3" json:"external_ids,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -115,13 +120,31 @@ func (x *User) GetIsDeleted() bool {
 	return false
 }
 
+func (x *User) GetExternalIds() map[string]string {
+	if x != nil {
+		return x.ExternalIds
+	}
+	return nil
+}
+
 // Authentication messages
 type LoginRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
-	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Email string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	// Checked by the password, ldap and oidc login methods. Logins present
+	// a password, a one_time_code or a passkey_assertion.
+	Password string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	// Code from the authenticator app, or a recovery code, for accounts with
+	// 2FA. Without it those logins return two_factor_required.
+	TwoFactorCode string `protobuf:"bytes,3,opt,name=two_factor_code,json=twoFactorCode,proto3" json:"two_factor_code,omitempty"`
+	// Code emailed by SendLoginCode, for the otp login method
+	OneTimeCode string `protobuf:"bytes,4,opt,name=one_time_code,json=oneTimeCode,proto3" json:"one_time_code,omitempty"`
+	// PublicKeyCredential returned by navigator.credentials.get() for the
+	// options from BeginPasskeyLogin, as JSON, for the passkey login method.
+	// Passkeys verify the user, so no two_factor_code is needed.
+	PasskeyAssertion string `protobuf:"bytes,5,opt,name=passkey_assertion,json=passkeyAssertion,proto3" json:"passkey_assertion,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *LoginRequest) Reset() {
@@ -168,11 +191,49 @@ func (x *LoginRequest) GetPassword() string {
 	return ""
 }
 
+func (x *LoginRequest) GetTwoFactorCode() string {
+	if x != nil {
+		return x.TwoFactorCode
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetOneTimeCode() string {
+	if x != nil {
+		return x.OneTimeCode
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPasskeyAssertion() string {
+	if x != nil {
+		return x.PasskeyAssertion
+	}
+	return ""
+}
+
 type LoginResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
-	User          *User                  `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Token            string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	User             *User                  `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Message          string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	ApprovalRequired bool                   `protobuf:"varint,4,opt,name=approval_required,json=approvalRequired,proto3" json:"approval_required,omitempty"`
+	PendingLoginId   string                 `protobuf:"bytes,5,opt,name=pending_login_id,json=pendingLoginId,proto3" json:"pending_login_id,omitempty"`
+	// Set when the user must set up 2FA. The token then only allows
+	// EnableTwoFactor, VerifyTwoFactor and Logout.
+	TwoFactorEnrollmentRequired bool `protobuf:"varint,6,opt,name=two_factor_enrollment_required,json=twoFactorEnrollmentRequired,proto3" json:"two_factor_enrollment_required,omitempty"`
+	// When a user required to use 2FA must have set it up by
+	TwoFactorEnrollmentDeadline *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=two_factor_enrollment_deadline,json=twoFactorEnrollmentDeadline,proto3" json:"two_factor_enrollment_deadline,omitempty"`
+	SecurityContext             *LoginSecurityContext  `protobuf:"bytes,8,opt,name=security_context,json=securityContext,proto3" json:"security_context,omitempty"`
+	// Set instead of a token when the password was right and the account
+	// has 2FA. Log in again with two_factor_code.
+	TwoFactorRequired bool `protobuf:"varint,9,opt,name=two_factor_required,json=twoFactorRequired,proto3" json:"two_factor_required,omitempty"`
+	// Exchanged for the next token with RefreshToken. Empty with an
+	// enrollment-only token, or when refresh tokens are disabled.
+	RefreshToken string `protobuf:"bytes,10,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	// Whether the account had never signed in from this device before, as
+	// in security_context
+	NewDevice     bool `protobuf:"varint,11,opt,name=new_device,json=newDevice,proto3" json:"new_device,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -228,27 +289,104 @@ func (x *LoginResponse) GetMessage() string {
 	return ""
 }
 
-type LogoutRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+func (x *LoginResponse) GetApprovalRequired() bool {
+	if x != nil {
+		return x.ApprovalRequired
+	}
+	return false
+}
+
+func (x *LoginResponse) GetPendingLoginId() string {
+	if x != nil {
+		return x.PendingLoginId
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetTwoFactorEnrollmentRequired() bool {
+	if x != nil {
+		return x.TwoFactorEnrollmentRequired
+	}
+	return false
+}
+
+func (x *LoginResponse) GetTwoFactorEnrollmentDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TwoFactorEnrollmentDeadline
+	}
+	return nil
+}
+
+func (x *LoginResponse) GetSecurityContext() *LoginSecurityContext {
+	if x != nil {
+		return x.SecurityContext
+	}
+	return nil
+}
+
+func (x *LoginResponse) GetTwoFactorRequired() bool {
+	if x != nil {
+		return x.TwoFactorRequired
+	}
+	return false
+}
+
+func (x *LoginResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetNewDevice() bool {
+	if x != nil {
+		return x.NewDevice
+	}
+	return false
+}
+
+// LoginSecurityContext describes a successful login so clients can drive
+// what happens after it without further calls
+type LoginSecurityContext struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether the account had never signed in from this device before
+	NewDevice bool `protobuf:"varint,1,opt,name=new_device,json=newDevice,proto3" json:"new_device,omitempty"`
+	// Whether a second factor was checked during this login
+	TwoFactorUsed     bool                   `protobuf:"varint,2,opt,name=two_factor_used,json=twoFactorUsed,proto3" json:"two_factor_used,omitempty"`
+	TwoFactorEnabled  bool                   `protobuf:"varint,3,opt,name=two_factor_enabled,json=twoFactorEnabled,proto3" json:"two_factor_enabled,omitempty"`
+	PasswordChangedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=password_changed_at,json=passwordChangedAt,proto3" json:"password_changed_at,omitempty"`
+	// Unset unless passwords expire
+	PasswordExpiresAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=password_expires_at,json=passwordExpiresAt,proto3" json:"password_expires_at,omitempty"`
+	// Set when the password expires soon, for display
+	PasswordWarning string `protobuf:"bytes,6,opt,name=password_warning,json=passwordWarning,proto3" json:"password_warning,omitempty"`
+	// What the client should ask the user to do next, most urgent first:
+	// "set_up_two_factor", "change_password", "verify_email" or
+	// "accept_terms"
+	RequiredActions []string `protobuf:"bytes,7,rep,name=required_actions,json=requiredActions,proto3" json:"required_actions,omitempty"`
+	// Terms to accept with AcceptTerms, set with "accept_terms"
+	TermsVersion string `protobuf:"bytes,8,opt,name=terms_version,json=termsVersion,proto3" json:"terms_version,omitempty"`
+	TermsUrl     string `protobuf:"bytes,9,opt,name=terms_url,json=termsUrl,proto3" json:"terms_url,omitempty"`
+	// The login method that checked the credentials: password, ldap, oidc,
+	// passkey or otp
+	LoginMethod   string `protobuf:"bytes,10,opt,name=login_method,json=loginMethod,proto3" json:"login_method,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LogoutRequest) Reset() {
-	*x = LogoutRequest{}
+func (x *LoginSecurityContext) Reset() {
+	*x = LoginSecurityContext{}
 	mi := &file_proto_user_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LogoutRequest) String() string {
+func (x *LoginSecurityContext) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogoutRequest) ProtoMessage() {}
+func (*LoginSecurityContext) ProtoMessage() {}
 
-func (x *LogoutRequest) ProtoReflect() protoreflect.Message {
+func (x *LoginSecurityContext) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_user_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -260,39 +398,102 @@ func (x *LogoutRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogoutRequest.ProtoReflect.Descriptor instead.
-func (*LogoutRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use LoginSecurityContext.ProtoReflect.Descriptor instead.
+func (*LoginSecurityContext) Descriptor() ([]byte, []int) {
 	return file_proto_user_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *LogoutRequest) GetToken() string {
+func (x *LoginSecurityContext) GetNewDevice() bool {
 	if x != nil {
-		return x.Token
+		return x.NewDevice
+	}
+	return false
+}
+
+func (x *LoginSecurityContext) GetTwoFactorUsed() bool {
+	if x != nil {
+		return x.TwoFactorUsed
+	}
+	return false
+}
+
+func (x *LoginSecurityContext) GetTwoFactorEnabled() bool {
+	if x != nil {
+		return x.TwoFactorEnabled
+	}
+	return false
+}
+
+func (x *LoginSecurityContext) GetPasswordChangedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PasswordChangedAt
+	}
+	return nil
+}
+
+func (x *LoginSecurityContext) GetPasswordExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PasswordExpiresAt
+	}
+	return nil
+}
+
+func (x *LoginSecurityContext) GetPasswordWarning() string {
+	if x != nil {
+		return x.PasswordWarning
 	}
 	return ""
 }
 
-type LogoutResponse struct {
+func (x *LoginSecurityContext) GetRequiredActions() []string {
+	if x != nil {
+		return x.RequiredActions
+	}
+	return nil
+}
+
+func (x *LoginSecurityContext) GetTermsVersion() string {
+	if x != nil {
+		return x.TermsVersion
+	}
+	return ""
+}
+
+func (x *LoginSecurityContext) GetTermsUrl() string {
+	if x != nil {
+		return x.TermsUrl
+	}
+	return ""
+}
+
+func (x *LoginSecurityContext) GetLoginMethod() string {
+	if x != nil {
+		return x.LoginMethod
+	}
+	return ""
+}
+
+type SendLoginCodeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LogoutResponse) Reset() {
-	*x = LogoutResponse{}
+func (x *SendLoginCodeRequest) Reset() {
+	*x = SendLoginCodeRequest{}
 	mi := &file_proto_user_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LogoutResponse) String() string {
+func (x *SendLoginCodeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogoutResponse) ProtoMessage() {}
+func (*SendLoginCodeRequest) ProtoMessage() {}
 
-func (x *LogoutResponse) ProtoReflect() protoreflect.Message {
+func (x *SendLoginCodeRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_user_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -304,41 +505,39 @@ func (x *LogoutResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogoutResponse.ProtoReflect.Descriptor instead.
-func (*LogoutResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use SendLoginCodeRequest.ProtoReflect.Descriptor instead.
+func (*SendLoginCodeRequest) Descriptor() ([]byte, []int) {
 	return file_proto_user_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *LogoutResponse) GetMessage() string {
+func (x *SendLoginCodeRequest) GetEmail() string {
 	if x != nil {
-		return x.Message
+		return x.Email
 	}
 	return ""
 }
 
-type RegisterRequest struct {
+type SendLoginCodeResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
-	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
-	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RegisterRequest) Reset() {
-	*x = RegisterRequest{}
+func (x *SendLoginCodeResponse) Reset() {
+	*x = SendLoginCodeResponse{}
 	mi := &file_proto_user_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RegisterRequest) String() string {
+func (x *SendLoginCodeResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterRequest) ProtoMessage() {}
+func (*SendLoginCodeResponse) ProtoMessage() {}
 
-func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+func (x *SendLoginCodeResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_user_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -350,54 +549,39 @@ func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
-func (*RegisterRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SendLoginCodeResponse.ProtoReflect.Descriptor instead.
+func (*SendLoginCodeResponse) Descriptor() ([]byte, []int) {
 	return file_proto_user_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *RegisterRequest) GetEmail() string {
-	if x != nil {
-		return x.Email
-	}
-	return ""
-}
-
-func (x *RegisterRequest) GetPassword() string {
-	if x != nil {
-		return x.Password
-	}
-	return ""
-}
-
-func (x *RegisterRequest) GetName() string {
+func (x *SendLoginCodeResponse) GetMessage() string {
 	if x != nil {
-		return x.Name
+		return x.Message
 	}
 	return ""
 }
 
-type RegisterResponse struct {
+type BeginPasskeyLoginRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RegisterResponse) Reset() {
-	*x = RegisterResponse{}
+func (x *BeginPasskeyLoginRequest) Reset() {
+	*x = BeginPasskeyLoginRequest{}
 	mi := &file_proto_user_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RegisterResponse) String() string {
+func (x *BeginPasskeyLoginRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterResponse) ProtoMessage() {}
+func (*BeginPasskeyLoginRequest) ProtoMessage() {}
 
-func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+func (x *BeginPasskeyLoginRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_user_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -409,47 +593,41 @@ func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
-func (*RegisterResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use BeginPasskeyLoginRequest.ProtoReflect.Descriptor instead.
+func (*BeginPasskeyLoginRequest) Descriptor() ([]byte, []int) {
 	return file_proto_user_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *RegisterResponse) GetUser() *User {
-	if x != nil {
-		return x.User
-	}
-	return nil
-}
-
-func (x *RegisterResponse) GetMessage() string {
+func (x *BeginPasskeyLoginRequest) GetEmail() string {
 	if x != nil {
-		return x.Message
+		return x.Email
 	}
 	return ""
 }
 
-// User management messages
-type GetProfileRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type BeginPasskeyLoginResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Options for navigator.credentials.get(), as JSON
+	RequestOptions string                 `protobuf:"bytes,1,opt,name=request_options,json=requestOptions,proto3" json:"request_options,omitempty"`
+	ExpiresAt      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *GetProfileRequest) Reset() {
-	*x = GetProfileRequest{}
+func (x *BeginPasskeyLoginResponse) Reset() {
+	*x = BeginPasskeyLoginResponse{}
 	mi := &file_proto_user_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetProfileRequest) String() string {
+func (x *BeginPasskeyLoginResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetProfileRequest) ProtoMessage() {}
+func (*BeginPasskeyLoginResponse) ProtoMessage() {}
 
-func (x *GetProfileRequest) ProtoReflect() protoreflect.Message {
+func (x *BeginPasskeyLoginResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_user_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -461,39 +639,46 @@ func (x *GetProfileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetProfileRequest.ProtoReflect.Descriptor instead.
-func (*GetProfileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use BeginPasskeyLoginResponse.ProtoReflect.Descriptor instead.
+func (*BeginPasskeyLoginResponse) Descriptor() ([]byte, []int) {
 	return file_proto_user_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *GetProfileRequest) GetUserId() string {
+func (x *BeginPasskeyLoginResponse) GetRequestOptions() string {
 	if x != nil {
-		return x.UserId
+		return x.RequestOptions
 	}
 	return ""
 }
 
-type GetProfileResponse struct {
+func (x *BeginPasskeyLoginResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type LogoutRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetProfileResponse) Reset() {
-	*x = GetProfileResponse{}
+func (x *LogoutRequest) Reset() {
+	*x = LogoutRequest{}
 	mi := &file_proto_user_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetProfileResponse) String() string {
+func (x *LogoutRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetProfileResponse) ProtoMessage() {}
+func (*LogoutRequest) ProtoMessage() {}
 
-func (x *GetProfileResponse) ProtoReflect() protoreflect.Message {
+func (x *LogoutRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_user_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -505,41 +690,39 @@ func (x *GetProfileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetProfileResponse.ProtoReflect.Descriptor instead.
-func (*GetProfileResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use LogoutRequest.ProtoReflect.Descriptor instead.
+func (*LogoutRequest) Descriptor() ([]byte, []int) {
 	return file_proto_user_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *GetProfileResponse) GetUser() *User {
+func (x *LogoutRequest) GetToken() string {
 	if x != nil {
-		return x.User
+		return x.Token
 	}
-	return nil
+	return ""
 }
 
-type UpdateProfileRequest struct {
+type LogoutResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateProfileRequest) Reset() {
-	*x = UpdateProfileRequest{}
+func (x *LogoutResponse) Reset() {
+	*x = LogoutResponse{}
 	mi := &file_proto_user_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateProfileRequest) String() string {
+func (x *LogoutResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateProfileRequest) ProtoMessage() {}
+func (*LogoutResponse) ProtoMessage() {}
 
-func (x *UpdateProfileRequest) ProtoReflect() protoreflect.Message {
+func (x *LogoutResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_user_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -551,33 +734,286 @@ func (x *UpdateProfileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateProfileRequest.ProtoReflect.Descriptor instead.
-func (*UpdateProfileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use LogoutResponse.ProtoReflect.Descriptor instead.
+func (*LogoutResponse) Descriptor() ([]byte, []int) {
 	return file_proto_user_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *UpdateProfileRequest) GetUserId() string {
+func (x *LogoutResponse) GetMessage() string {
 	if x != nil {
-		return x.UserId
+		return x.Message
 	}
 	return ""
 }
 
-func (x *UpdateProfileRequest) GetName() string {
+// Refresh token messages
+type RefreshTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RefreshToken  string                 `protobuf:"bytes,1,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshTokenRequest) Reset() {
+	*x = RefreshTokenRequest{}
+	mi := &file_proto_user_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshTokenRequest) ProtoMessage() {}
+
+func (x *RefreshTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[10]
 	if x != nil {
-		return x.Name
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshTokenRequest.ProtoReflect.Descriptor instead.
+func (*RefreshTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RefreshTokenRequest) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
 	}
 	return ""
 }
 
-func (x *UpdateProfileRequest) GetEmail() string {
+type RefreshTokenResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// New session token of the same session
+	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// Replaces the refresh token of the request, which can't be used again
+	RefreshToken string `protobuf:"bytes,2,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	// When token expires. Refresh before then.
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// The refreshed session. It ends at its expires_at unless refreshed
+	// again, or earlier when idle past the idle timeout after its
+	// last_activity_at.
+	Session       *Session `protobuf:"bytes,4,opt,name=session,proto3" json:"session,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshTokenResponse) Reset() {
+	*x = RefreshTokenResponse{}
+	mi := &file_proto_user_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshTokenResponse) ProtoMessage() {}
+
+func (x *RefreshTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshTokenResponse.ProtoReflect.Descriptor instead.
+func (*RefreshTokenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RefreshTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RefreshTokenResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *RefreshTokenResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *RefreshTokenResponse) GetSession() *Session {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+type LogoutAllDevicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogoutAllDevicesRequest) Reset() {
+	*x = LogoutAllDevicesRequest{}
+	mi := &file_proto_user_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogoutAllDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogoutAllDevicesRequest) ProtoMessage() {}
+
+func (x *LogoutAllDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogoutAllDevicesRequest.ProtoReflect.Descriptor instead.
+func (*LogoutAllDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *LogoutAllDevicesRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type LogoutAllDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogoutAllDevicesResponse) Reset() {
+	*x = LogoutAllDevicesResponse{}
+	mi := &file_proto_user_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogoutAllDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogoutAllDevicesResponse) ProtoMessage() {}
+
+func (x *LogoutAllDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogoutAllDevicesResponse.ProtoReflect.Descriptor instead.
+func (*LogoutAllDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *LogoutAllDevicesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type RegisterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	mi := &file_proto_user_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RegisterRequest) GetEmail() string {
 	if x != nil {
 		return x.Email
 	}
 	return ""
 }
 
-type UpdateProfileResponse struct {
+func (x *RegisterRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type RegisterResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
@@ -585,21 +1021,21 @@ type UpdateProfileResponse struct {
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateProfileResponse) Reset() {
-	*x = UpdateProfileResponse{}
-	mi := &file_proto_user_proto_msgTypes[10]
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_proto_user_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateProfileResponse) String() string {
+func (x *RegisterResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateProfileResponse) ProtoMessage() {}
+func (*RegisterResponse) ProtoMessage() {}
 
-func (x *UpdateProfileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_user_proto_msgTypes[10]
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -610,91 +1046,13329 @@ func (x *UpdateProfileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateProfileResponse.ProtoReflect.Descriptor instead.
-func (*UpdateProfileResponse) Descriptor() ([]byte, []int) {
-	return file_proto_user_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *UpdateProfileResponse) GetUser() *User {
+func (x *RegisterResponse) GetUser() *User {
 	if x != nil {
 		return x.User
 	}
 	return nil
 }
 
-func (x *UpdateProfileResponse) GetMessage() string {
+func (x *RegisterResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-type DeleteProfileRequest struct {
+// Login approval messages
+type PendingLogin struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserAgent     string                 `protobuf:"bytes,2,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	IpAddress     string                 `protobuf:"bytes,3,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteProfileRequest) Reset() {
-	*x = DeleteProfileRequest{}
-	mi := &file_proto_user_proto_msgTypes[11]
+func (x *PendingLogin) Reset() {
+	*x = PendingLogin{}
+	mi := &file_proto_user_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteProfileRequest) String() string {
+func (x *PendingLogin) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteProfileRequest) ProtoMessage() {}
+func (*PendingLogin) ProtoMessage() {}
 
-func (x *DeleteProfileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_user_proto_msgTypes[11]
+func (x *PendingLogin) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingLogin.ProtoReflect.Descriptor instead.
+func (*PendingLogin) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PendingLogin) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PendingLogin) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *PendingLogin) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *PendingLogin) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *PendingLogin) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type ApproveLoginRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	PendingLoginId string                 `protobuf:"bytes,1,opt,name=pending_login_id,json=pendingLoginId,proto3" json:"pending_login_id,omitempty"`
+	ApprovalToken  string                 `protobuf:"bytes,2,opt,name=approval_token,json=approvalToken,proto3" json:"approval_token,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ApproveLoginRequest) Reset() {
+	*x = ApproveLoginRequest{}
+	mi := &file_proto_user_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveLoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveLoginRequest) ProtoMessage() {}
+
+func (x *ApproveLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveLoginRequest.ProtoReflect.Descriptor instead.
+func (*ApproveLoginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ApproveLoginRequest) GetPendingLoginId() string {
+	if x != nil {
+		return x.PendingLoginId
+	}
+	return ""
+}
+
+func (x *ApproveLoginRequest) GetApprovalToken() string {
+	if x != nil {
+		return x.ApprovalToken
+	}
+	return ""
+}
+
+type ApproveLoginResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveLoginResponse) Reset() {
+	*x = ApproveLoginResponse{}
+	mi := &file_proto_user_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveLoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveLoginResponse) ProtoMessage() {}
+
+func (x *ApproveLoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveLoginResponse.ProtoReflect.Descriptor instead.
+func (*ApproveLoginResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ApproveLoginResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ListPendingLoginsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPendingLoginsRequest) Reset() {
+	*x = ListPendingLoginsRequest{}
+	mi := &file_proto_user_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPendingLoginsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPendingLoginsRequest) ProtoMessage() {}
+
+func (x *ListPendingLoginsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPendingLoginsRequest.ProtoReflect.Descriptor instead.
+func (*ListPendingLoginsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{19}
+}
+
+type ListPendingLoginsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PendingLogins []*PendingLogin        `protobuf:"bytes,1,rep,name=pending_logins,json=pendingLogins,proto3" json:"pending_logins,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPendingLoginsResponse) Reset() {
+	*x = ListPendingLoginsResponse{}
+	mi := &file_proto_user_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPendingLoginsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPendingLoginsResponse) ProtoMessage() {}
+
+func (x *ListPendingLoginsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPendingLoginsResponse.ProtoReflect.Descriptor instead.
+func (*ListPendingLoginsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListPendingLoginsResponse) GetPendingLogins() []*PendingLogin {
+	if x != nil {
+		return x.PendingLogins
+	}
+	return nil
+}
+
+// Cross-device login messages (OAuth 2.0 device authorization grant, RFC 8628)
+type StartDeviceLoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	Scope         string                 `protobuf:"bytes,2,opt,name=scope,proto3" json:"scope,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartDeviceLoginRequest) Reset() {
+	*x = StartDeviceLoginRequest{}
+	mi := &file_proto_user_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartDeviceLoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartDeviceLoginRequest) ProtoMessage() {}
+
+func (x *StartDeviceLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartDeviceLoginRequest.ProtoReflect.Descriptor instead.
+func (*StartDeviceLoginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *StartDeviceLoginRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *StartDeviceLoginRequest) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+type StartDeviceLoginResponse struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	DeviceCode              string                 `protobuf:"bytes,1,opt,name=device_code,json=deviceCode,proto3" json:"device_code,omitempty"`
+	UserCode                string                 `protobuf:"bytes,2,opt,name=user_code,json=userCode,proto3" json:"user_code,omitempty"`
+	VerificationUri         string                 `protobuf:"bytes,3,opt,name=verification_uri,json=verificationUri,proto3" json:"verification_uri,omitempty"`
+	VerificationUriComplete string                 `protobuf:"bytes,4,opt,name=verification_uri_complete,json=verificationUriComplete,proto3" json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int32                  `protobuf:"varint,5,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"`
+	Interval                int32                  `protobuf:"varint,6,opt,name=interval,proto3" json:"interval,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *StartDeviceLoginResponse) Reset() {
+	*x = StartDeviceLoginResponse{}
+	mi := &file_proto_user_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartDeviceLoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartDeviceLoginResponse) ProtoMessage() {}
+
+func (x *StartDeviceLoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartDeviceLoginResponse.ProtoReflect.Descriptor instead.
+func (*StartDeviceLoginResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *StartDeviceLoginResponse) GetDeviceCode() string {
+	if x != nil {
+		return x.DeviceCode
+	}
+	return ""
+}
+
+func (x *StartDeviceLoginResponse) GetUserCode() string {
+	if x != nil {
+		return x.UserCode
+	}
+	return ""
+}
+
+func (x *StartDeviceLoginResponse) GetVerificationUri() string {
+	if x != nil {
+		return x.VerificationUri
+	}
+	return ""
+}
+
+func (x *StartDeviceLoginResponse) GetVerificationUriComplete() string {
+	if x != nil {
+		return x.VerificationUriComplete
+	}
+	return ""
+}
+
+func (x *StartDeviceLoginResponse) GetExpiresIn() int32 {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return 0
+}
+
+func (x *StartDeviceLoginResponse) GetInterval() int32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+type ApproveDeviceLoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserCode      string                 `protobuf:"bytes,1,opt,name=user_code,json=userCode,proto3" json:"user_code,omitempty"`
+	Deny          bool                   `protobuf:"varint,2,opt,name=deny,proto3" json:"deny,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveDeviceLoginRequest) Reset() {
+	*x = ApproveDeviceLoginRequest{}
+	mi := &file_proto_user_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveDeviceLoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveDeviceLoginRequest) ProtoMessage() {}
+
+func (x *ApproveDeviceLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveDeviceLoginRequest.ProtoReflect.Descriptor instead.
+func (*ApproveDeviceLoginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ApproveDeviceLoginRequest) GetUserCode() string {
+	if x != nil {
+		return x.UserCode
+	}
+	return ""
+}
+
+func (x *ApproveDeviceLoginRequest) GetDeny() bool {
+	if x != nil {
+		return x.Deny
+	}
+	return false
+}
+
+type ApproveDeviceLoginResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	ClientId      string                 `protobuf:"bytes,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	Scope         string                 `protobuf:"bytes,3,opt,name=scope,proto3" json:"scope,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveDeviceLoginResponse) Reset() {
+	*x = ApproveDeviceLoginResponse{}
+	mi := &file_proto_user_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveDeviceLoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveDeviceLoginResponse) ProtoMessage() {}
+
+func (x *ApproveDeviceLoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveDeviceLoginResponse.ProtoReflect.Descriptor instead.
+func (*ApproveDeviceLoginResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ApproveDeviceLoginResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ApproveDeviceLoginResponse) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *ApproveDeviceLoginResponse) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+type PollDeviceLoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceCode    string                 `protobuf:"bytes,1,opt,name=device_code,json=deviceCode,proto3" json:"device_code,omitempty"`
+	ClientId      string                 `protobuf:"bytes,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollDeviceLoginRequest) Reset() {
+	*x = PollDeviceLoginRequest{}
+	mi := &file_proto_user_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollDeviceLoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollDeviceLoginRequest) ProtoMessage() {}
+
+func (x *PollDeviceLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollDeviceLoginRequest.ProtoReflect.Descriptor instead.
+func (*PollDeviceLoginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PollDeviceLoginRequest) GetDeviceCode() string {
+	if x != nil {
+		return x.DeviceCode
+	}
+	return ""
+}
+
+func (x *PollDeviceLoginRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+type PollDeviceLoginResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Pending bool                   `protobuf:"varint,1,opt,name=pending,proto3" json:"pending,omitempty"`
+	Token   string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	User    *User                  `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	Message string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	// RFC 8628 error code: authorization_pending, slow_down, access_denied or expired_token
+	Error        string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Interval     int32  `protobuf:"varint,6,opt,name=interval,proto3" json:"interval,omitempty"`
+	Scope        string `protobuf:"bytes,7,opt,name=scope,proto3" json:"scope,omitempty"`
+	RefreshToken string `protobuf:"bytes,8,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	// Set when the user must set up 2FA. The token then only allows
+	// EnableTwoFactor, VerifyTwoFactor and Logout.
+	TwoFactorEnrollmentRequired bool                   `protobuf:"varint,9,opt,name=two_factor_enrollment_required,json=twoFactorEnrollmentRequired,proto3" json:"two_factor_enrollment_required,omitempty"`
+	TwoFactorEnrollmentDeadline *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=two_factor_enrollment_deadline,json=twoFactorEnrollmentDeadline,proto3" json:"two_factor_enrollment_deadline,omitempty"`
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
+}
+
+func (x *PollDeviceLoginResponse) Reset() {
+	*x = PollDeviceLoginResponse{}
+	mi := &file_proto_user_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollDeviceLoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollDeviceLoginResponse) ProtoMessage() {}
+
+func (x *PollDeviceLoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollDeviceLoginResponse.ProtoReflect.Descriptor instead.
+func (*PollDeviceLoginResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *PollDeviceLoginResponse) GetPending() bool {
+	if x != nil {
+		return x.Pending
+	}
+	return false
+}
+
+func (x *PollDeviceLoginResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *PollDeviceLoginResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *PollDeviceLoginResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PollDeviceLoginResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PollDeviceLoginResponse) GetInterval() int32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *PollDeviceLoginResponse) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *PollDeviceLoginResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *PollDeviceLoginResponse) GetTwoFactorEnrollmentRequired() bool {
+	if x != nil {
+		return x.TwoFactorEnrollmentRequired
+	}
+	return false
+}
+
+func (x *PollDeviceLoginResponse) GetTwoFactorEnrollmentDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TwoFactorEnrollmentDeadline
+	}
+	return nil
+}
+
+// Action token messages
+type CreateActionTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Purpose       string                 `protobuf:"bytes,1,opt,name=purpose,proto3" json:"purpose,omitempty"`
+	Resource      string                 `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
+	TtlSeconds    int32                  `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateActionTokenRequest) Reset() {
+	*x = CreateActionTokenRequest{}
+	mi := &file_proto_user_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateActionTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateActionTokenRequest) ProtoMessage() {}
+
+func (x *CreateActionTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateActionTokenRequest.ProtoReflect.Descriptor instead.
+func (*CreateActionTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CreateActionTokenRequest) GetPurpose() string {
+	if x != nil {
+		return x.Purpose
+	}
+	return ""
+}
+
+func (x *CreateActionTokenRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *CreateActionTokenRequest) GetTtlSeconds() int32 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type CreateActionTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateActionTokenResponse) Reset() {
+	*x = CreateActionTokenResponse{}
+	mi := &file_proto_user_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateActionTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateActionTokenResponse) ProtoMessage() {}
+
+func (x *CreateActionTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateActionTokenResponse.ProtoReflect.Descriptor instead.
+func (*CreateActionTokenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CreateActionTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CreateActionTokenResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type ValidateTokenRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Token string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// Expected purpose of an action token; empty validates a session token
+	Purpose       string `protobuf:"bytes,2,opt,name=purpose,proto3" json:"purpose,omitempty"`
+	Resource      string `protobuf:"bytes,3,opt,name=resource,proto3" json:"resource,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateTokenRequest) Reset() {
+	*x = ValidateTokenRequest{}
+	mi := &file_proto_user_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateTokenRequest) ProtoMessage() {}
+
+func (x *ValidateTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateTokenRequest.ProtoReflect.Descriptor instead.
+func (*ValidateTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ValidateTokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ValidateTokenRequest) GetPurpose() string {
+	if x != nil {
+		return x.Purpose
+	}
+	return ""
+}
+
+func (x *ValidateTokenRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+type ValidateTokenResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Valid     bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	UserId    string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Email     string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Purpose   string                 `protobuf:"bytes,4,opt,name=purpose,proto3" json:"purpose,omitempty"`
+	Resource  string                 `protobuf:"bytes,5,opt,name=resource,proto3" json:"resource,omitempty"`
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// The user's organization and roles, set for session tokens
+	OrgId string   `protobuf:"bytes,7,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Roles []string `protobuf:"bytes,8,rep,name=roles,proto3" json:"roles,omitempty"`
+	// Whether the account is active, false once it is deactivated or
+	// deleted
+	Active        bool `protobuf:"varint,9,opt,name=active,proto3" json:"active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateTokenResponse) Reset() {
+	*x = ValidateTokenResponse{}
+	mi := &file_proto_user_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateTokenResponse) ProtoMessage() {}
+
+func (x *ValidateTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateTokenResponse.ProtoReflect.Descriptor instead.
+func (*ValidateTokenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ValidateTokenResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateTokenResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ValidateTokenResponse) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ValidateTokenResponse) GetPurpose() string {
+	if x != nil {
+		return x.Purpose
+	}
+	return ""
+}
+
+func (x *ValidateTokenResponse) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *ValidateTokenResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *ValidateTokenResponse) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *ValidateTokenResponse) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *ValidateTokenResponse) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+// User management messages
+type GetProfileRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The user to act on, the caller when empty. Only admins may name
+	// another user.
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProfileRequest) Reset() {
+	*x = GetProfileRequest{}
+	mi := &file_proto_user_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProfileRequest) ProtoMessage() {}
+
+func (x *GetProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProfileRequest.ProtoReflect.Descriptor instead.
+func (*GetProfileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetProfileRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetProfileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProfileResponse) Reset() {
+	*x = GetProfileResponse{}
+	mi := &file_proto_user_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProfileResponse) ProtoMessage() {}
+
+func (x *GetProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProfileResponse.ProtoReflect.Descriptor instead.
+func (*GetProfileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetProfileResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type UpdateProfileRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The user to act on, the caller when empty. Only admins may name
+	// another user.
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name          string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Email         string `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProfileRequest) Reset() {
+	*x = UpdateProfileRequest{}
+	mi := &file_proto_user_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProfileRequest) ProtoMessage() {}
+
+func (x *UpdateProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProfileRequest.ProtoReflect.Descriptor instead.
+func (*UpdateProfileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *UpdateProfileRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateProfileRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateProfileRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type UpdateProfileResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	User    *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Set when the member's organization must approve the change first
+	PendingApproval bool   `protobuf:"varint,3,opt,name=pending_approval,json=pendingApproval,proto3" json:"pending_approval,omitempty"`
+	ChangeRequestId string `protobuf:"bytes,4,opt,name=change_request_id,json=changeRequestId,proto3" json:"change_request_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateProfileResponse) Reset() {
+	*x = UpdateProfileResponse{}
+	mi := &file_proto_user_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProfileResponse) ProtoMessage() {}
+
+func (x *UpdateProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProfileResponse.ProtoReflect.Descriptor instead.
+func (*UpdateProfileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *UpdateProfileResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *UpdateProfileResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UpdateProfileResponse) GetPendingApproval() bool {
+	if x != nil {
+		return x.PendingApproval
+	}
+	return false
+}
+
+func (x *UpdateProfileResponse) GetChangeRequestId() string {
+	if x != nil {
+		return x.ChangeRequestId
+	}
+	return ""
+}
+
+type DeleteProfileRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The user to act on, the caller when empty. Only admins may name
+	// another user.
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProfileRequest) Reset() {
+	*x = DeleteProfileRequest{}
+	mi := &file_proto_user_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProfileRequest) ProtoMessage() {}
+
+func (x *DeleteProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProfileRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProfileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DeleteProfileRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type DeleteProfileResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// Until when RestoreProfile can bring the account back. Unset when
+	// deleted accounts can't be restored.
+	RestorableUntil *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=restorable_until,json=restorableUntil,proto3" json:"restorable_until,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DeleteProfileResponse) Reset() {
+	*x = DeleteProfileResponse{}
+	mi := &file_proto_user_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProfileResponse) ProtoMessage() {}
+
+func (x *DeleteProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProfileResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProfileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *DeleteProfileResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *DeleteProfileResponse) GetRestorableUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RestorableUntil
+	}
+	return nil
+}
+
+type RestoreProfileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreProfileRequest) Reset() {
+	*x = RestoreProfileRequest{}
+	mi := &file_proto_user_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreProfileRequest) ProtoMessage() {}
+
+func (x *RestoreProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreProfileRequest.ProtoReflect.Descriptor instead.
+func (*RestoreProfileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RestoreProfileRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *RestoreProfileRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type RestoreProfileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreProfileResponse) Reset() {
+	*x = RestoreProfileResponse{}
+	mi := &file_proto_user_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreProfileResponse) ProtoMessage() {}
+
+func (x *RestoreProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreProfileResponse.ProtoReflect.Descriptor instead.
+func (*RestoreProfileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *RestoreProfileResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ListUsersRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Page        int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize    int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	NameFilter  string                 `protobuf:"bytes,3,opt,name=name_filter,json=nameFilter,proto3" json:"name_filter,omitempty"`
+	EmailFilter string                 `protobuf:"bytes,4,opt,name=email_filter,json=emailFilter,proto3" json:"email_filter,omitempty"`
+	// Token from an earlier page; pages requested with it read the users as
+	// they were when the first page was listed
+	ConsistencyToken string `protobuf:"bytes,5,opt,name=consistency_token,json=consistencyToken,proto3" json:"consistency_token,omitempty"`
+	// Only active, or only inactive, users when set
+	IsActive *bool `protobuf:"varint,6,opt,name=is_active,json=isActive,proto3,oneof" json:"is_active,omitempty"`
+	// Only users created after, and before, these times when set
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	// created_at (default), updated_at, name or email
+	SortBy string `protobuf:"bytes,9,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	// asc, or desc (default)
+	SortOrder     string `protobuf:"bytes,10,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersRequest) Reset() {
+	*x = ListUsersRequest{}
+	mi := &file_proto_user_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersRequest) ProtoMessage() {}
+
+func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersRequest.ProtoReflect.Descriptor instead.
+func (*ListUsersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ListUsersRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListUsersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListUsersRequest) GetNameFilter() string {
+	if x != nil {
+		return x.NameFilter
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetEmailFilter() string {
+	if x != nil {
+		return x.EmailFilter
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetConsistencyToken() string {
+	if x != nil {
+		return x.ConsistencyToken
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetIsActive() bool {
+	if x != nil && x.IsActive != nil {
+		return *x.IsActive
+	}
+	return false
+}
+
+func (x *ListUsersRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *ListUsersRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *ListUsersRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetSortOrder() string {
+	if x != nil {
+		return x.SortOrder
+	}
+	return ""
+}
+
+type ListUsersResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Users      []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	TotalCount int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Page       int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize   int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Pass to the next ListUsers call to page through the same snapshot;
+	// empty when the deployment cannot read at a fixed point in time
+	ConsistencyToken string `protobuf:"bytes,5,opt,name=consistency_token,json=consistencyToken,proto3" json:"consistency_token,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListUsersResponse) Reset() {
+	*x = ListUsersResponse{}
+	mi := &file_proto_user_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersResponse) ProtoMessage() {}
+
+func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersResponse.ProtoReflect.Descriptor instead.
+func (*ListUsersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ListUsersResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ListUsersResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *ListUsersResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListUsersResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListUsersResponse) GetConsistencyToken() string {
+	if x != nil {
+		return x.ConsistencyToken
+	}
+	return ""
+}
+
+// Auth options messages
+type GetAuthOptionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Either may be given. Options of the organization apply to its members.
+	Email          string `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	OrganizationId string `protobuf:"bytes,2,opt,name=organization_id,json=organizationId,proto3" json:"organization_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetAuthOptionsRequest) Reset() {
+	*x = GetAuthOptionsRequest{}
+	mi := &file_proto_user_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAuthOptionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuthOptionsRequest) ProtoMessage() {}
+
+func (x *GetAuthOptionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuthOptionsRequest.ProtoReflect.Descriptor instead.
+func (*GetAuthOptionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *GetAuthOptionsRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *GetAuthOptionsRequest) GetOrganizationId() string {
+	if x != nil {
+		return x.OrganizationId
+	}
+	return ""
+}
+
+type AuthMethod struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// password, passkey, otp, totp, sso, google or github
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Whether every login must use this method
+	Required bool `protobuf:"varint,2,opt,name=required,proto3" json:"required,omitempty"`
+	// For sso, the organization whose identity provider signs the user in
+	OrganizationId string `protobuf:"bytes,3,opt,name=organization_id,json=organizationId,proto3" json:"organization_id,omitempty"`
+	// For sso, the identity provider page to send the user to
+	RedirectUrl   string `protobuf:"bytes,4,opt,name=redirect_url,json=redirectUrl,proto3" json:"redirect_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthMethod) Reset() {
+	*x = AuthMethod{}
+	mi := &file_proto_user_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthMethod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthMethod) ProtoMessage() {}
+
+func (x *AuthMethod) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthMethod.ProtoReflect.Descriptor instead.
+func (*AuthMethod) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *AuthMethod) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *AuthMethod) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *AuthMethod) GetOrganizationId() string {
+	if x != nil {
+		return x.OrganizationId
+	}
+	return ""
+}
+
+func (x *AuthMethod) GetRedirectUrl() string {
+	if x != nil {
+		return x.RedirectUrl
+	}
+	return ""
+}
+
+type GetAuthOptionsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Methods a login can use, in the order to offer them
+	Methods       []*AuthMethod `protobuf:"bytes,1,rep,name=methods,proto3" json:"methods,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAuthOptionsResponse) Reset() {
+	*x = GetAuthOptionsResponse{}
+	mi := &file_proto_user_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAuthOptionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuthOptionsResponse) ProtoMessage() {}
+
+func (x *GetAuthOptionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuthOptionsResponse.ProtoReflect.Descriptor instead.
+func (*GetAuthOptionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *GetAuthOptionsResponse) GetMethods() []*AuthMethod {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+// SSO login messages
+type CompleteSSOLoginRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Query parameters the identity provider redirected back with
+	State         string `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Code          string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteSSOLoginRequest) Reset() {
+	*x = CompleteSSOLoginRequest{}
+	mi := &file_proto_user_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteSSOLoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteSSOLoginRequest) ProtoMessage() {}
+
+func (x *CompleteSSOLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteSSOLoginRequest.ProtoReflect.Descriptor instead.
+func (*CompleteSSOLoginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *CompleteSSOLoginRequest) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *CompleteSSOLoginRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type CompleteSSOLoginResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Token           string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	User            *User                  `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Message         string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	SecurityContext *LoginSecurityContext  `protobuf:"bytes,4,opt,name=security_context,json=securityContext,proto3" json:"security_context,omitempty"`
+	RefreshToken    string                 `protobuf:"bytes,5,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	// Whether the account had never signed in from this device before
+	NewDevice     bool `protobuf:"varint,6,opt,name=new_device,json=newDevice,proto3" json:"new_device,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteSSOLoginResponse) Reset() {
+	*x = CompleteSSOLoginResponse{}
+	mi := &file_proto_user_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteSSOLoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteSSOLoginResponse) ProtoMessage() {}
+
+func (x *CompleteSSOLoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteSSOLoginResponse.ProtoReflect.Descriptor instead.
+func (*CompleteSSOLoginResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *CompleteSSOLoginResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CompleteSSOLoginResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *CompleteSSOLoginResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CompleteSSOLoginResponse) GetSecurityContext() *LoginSecurityContext {
+	if x != nil {
+		return x.SecurityContext
+	}
+	return nil
+}
+
+func (x *CompleteSSOLoginResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *CompleteSSOLoginResponse) GetNewDevice() bool {
+	if x != nil {
+		return x.NewDevice
+	}
+	return false
+}
+
+// Social login messages
+type LoginWithProviderRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// google or github
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	// A Google ID token, or a GitHub access token with the user:email scope
+	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// Code from the authenticator app, or a recovery code, for accounts with
+	// 2FA. Without it those logins return two_factor_required.
+	TwoFactorCode string `protobuf:"bytes,3,opt,name=two_factor_code,json=twoFactorCode,proto3" json:"two_factor_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginWithProviderRequest) Reset() {
+	*x = LoginWithProviderRequest{}
+	mi := &file_proto_user_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginWithProviderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginWithProviderRequest) ProtoMessage() {}
+
+func (x *LoginWithProviderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginWithProviderRequest.ProtoReflect.Descriptor instead.
+func (*LoginWithProviderRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *LoginWithProviderRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *LoginWithProviderRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginWithProviderRequest) GetTwoFactorCode() string {
+	if x != nil {
+		return x.TwoFactorCode
+	}
+	return ""
+}
+
+type LoginWithProviderResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Token           string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	User            *User                  `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Message         string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	SecurityContext *LoginSecurityContext  `protobuf:"bytes,4,opt,name=security_context,json=securityContext,proto3" json:"security_context,omitempty"`
+	// Set instead of a token when the account has 2FA. Log in again with
+	// two_factor_code.
+	TwoFactorRequired bool `protobuf:"varint,5,opt,name=two_factor_required,json=twoFactorRequired,proto3" json:"two_factor_required,omitempty"`
+	// Set when the user must set up 2FA. The token then only allows
+	// EnableTwoFactor, VerifyTwoFactor and Logout.
+	TwoFactorEnrollmentRequired bool                   `protobuf:"varint,6,opt,name=two_factor_enrollment_required,json=twoFactorEnrollmentRequired,proto3" json:"two_factor_enrollment_required,omitempty"`
+	TwoFactorEnrollmentDeadline *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=two_factor_enrollment_deadline,json=twoFactorEnrollmentDeadline,proto3" json:"two_factor_enrollment_deadline,omitempty"`
+	// Whether the login created the account
+	Created      bool   `protobuf:"varint,8,opt,name=created,proto3" json:"created,omitempty"`
+	RefreshToken string `protobuf:"bytes,9,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	// Whether the account had never signed in from this device before
+	NewDevice     bool `protobuf:"varint,10,opt,name=new_device,json=newDevice,proto3" json:"new_device,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginWithProviderResponse) Reset() {
+	*x = LoginWithProviderResponse{}
+	mi := &file_proto_user_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginWithProviderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginWithProviderResponse) ProtoMessage() {}
+
+func (x *LoginWithProviderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginWithProviderResponse.ProtoReflect.Descriptor instead.
+func (*LoginWithProviderResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *LoginWithProviderResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginWithProviderResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *LoginWithProviderResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LoginWithProviderResponse) GetSecurityContext() *LoginSecurityContext {
+	if x != nil {
+		return x.SecurityContext
+	}
+	return nil
+}
+
+func (x *LoginWithProviderResponse) GetTwoFactorRequired() bool {
+	if x != nil {
+		return x.TwoFactorRequired
+	}
+	return false
+}
+
+func (x *LoginWithProviderResponse) GetTwoFactorEnrollmentRequired() bool {
+	if x != nil {
+		return x.TwoFactorEnrollmentRequired
+	}
+	return false
+}
+
+func (x *LoginWithProviderResponse) GetTwoFactorEnrollmentDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TwoFactorEnrollmentDeadline
+	}
+	return nil
+}
+
+func (x *LoginWithProviderResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+func (x *LoginWithProviderResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *LoginWithProviderResponse) GetNewDevice() bool {
+	if x != nil {
+		return x.NewDevice
+	}
+	return false
+}
+
+// Email verification messages
+type StartEmailVerificationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartEmailVerificationRequest) Reset() {
+	*x = StartEmailVerificationRequest{}
+	mi := &file_proto_user_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartEmailVerificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartEmailVerificationRequest) ProtoMessage() {}
+
+func (x *StartEmailVerificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartEmailVerificationRequest.ProtoReflect.Descriptor instead.
+func (*StartEmailVerificationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{48}
+}
+
+type StartEmailVerificationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartEmailVerificationResponse) Reset() {
+	*x = StartEmailVerificationResponse{}
+	mi := &file_proto_user_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartEmailVerificationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartEmailVerificationResponse) ProtoMessage() {}
+
+func (x *StartEmailVerificationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartEmailVerificationResponse.ProtoReflect.Descriptor instead.
+func (*StartEmailVerificationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *StartEmailVerificationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type VerifyEmailRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyEmailRequest) Reset() {
+	*x = VerifyEmailRequest{}
+	mi := &file_proto_user_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyEmailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyEmailRequest) ProtoMessage() {}
+
+func (x *VerifyEmailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyEmailRequest.ProtoReflect.Descriptor instead.
+func (*VerifyEmailRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *VerifyEmailRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type VerifyEmailResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyEmailResponse) Reset() {
+	*x = VerifyEmailResponse{}
+	mi := &file_proto_user_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyEmailResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyEmailResponse) ProtoMessage() {}
+
+func (x *VerifyEmailResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyEmailResponse.ProtoReflect.Descriptor instead.
+func (*VerifyEmailResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *VerifyEmailResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Set password messages
+type StartSetPasswordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartSetPasswordRequest) Reset() {
+	*x = StartSetPasswordRequest{}
+	mi := &file_proto_user_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartSetPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartSetPasswordRequest) ProtoMessage() {}
+
+func (x *StartSetPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartSetPasswordRequest.ProtoReflect.Descriptor instead.
+func (*StartSetPasswordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{52}
+}
+
+type StartSetPasswordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartSetPasswordResponse) Reset() {
+	*x = StartSetPasswordResponse{}
+	mi := &file_proto_user_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartSetPasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartSetPasswordResponse) ProtoMessage() {}
+
+func (x *StartSetPasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartSetPasswordResponse.ProtoReflect.Descriptor instead.
+func (*StartSetPasswordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *StartSetPasswordResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type SetPasswordRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Code from StartSetPassword
+	Code          string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	NewPassword   string `protobuf:"bytes,2,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPasswordRequest) Reset() {
+	*x = SetPasswordRequest{}
+	mi := &file_proto_user_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPasswordRequest) ProtoMessage() {}
+
+func (x *SetPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPasswordRequest.ProtoReflect.Descriptor instead.
+func (*SetPasswordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *SetPasswordRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *SetPasswordRequest) GetNewPassword() string {
+	if x != nil {
+		return x.NewPassword
+	}
+	return ""
+}
+
+type SetPasswordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPasswordResponse) Reset() {
+	*x = SetPasswordResponse{}
+	mi := &file_proto_user_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPasswordResponse) ProtoMessage() {}
+
+func (x *SetPasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPasswordResponse.ProtoReflect.Descriptor instead.
+func (*SetPasswordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *SetPasswordResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Account unlock messages
+type StartUnlockChallengeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartUnlockChallengeRequest) Reset() {
+	*x = StartUnlockChallengeRequest{}
+	mi := &file_proto_user_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartUnlockChallengeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartUnlockChallengeRequest) ProtoMessage() {}
+
+func (x *StartUnlockChallengeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartUnlockChallengeRequest.ProtoReflect.Descriptor instead.
+func (*StartUnlockChallengeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *StartUnlockChallengeRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type StartUnlockChallengeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartUnlockChallengeResponse) Reset() {
+	*x = StartUnlockChallengeResponse{}
+	mi := &file_proto_user_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartUnlockChallengeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartUnlockChallengeResponse) ProtoMessage() {}
+
+func (x *StartUnlockChallengeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartUnlockChallengeResponse.ProtoReflect.Descriptor instead.
+func (*StartUnlockChallengeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *StartUnlockChallengeResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type UnlockWithChallengeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Password      string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockWithChallengeRequest) Reset() {
+	*x = UnlockWithChallengeRequest{}
+	mi := &file_proto_user_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockWithChallengeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockWithChallengeRequest) ProtoMessage() {}
+
+func (x *UnlockWithChallengeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockWithChallengeRequest.ProtoReflect.Descriptor instead.
+func (*UnlockWithChallengeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *UnlockWithChallengeRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UnlockWithChallengeRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *UnlockWithChallengeRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type UnlockWithChallengeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockWithChallengeResponse) Reset() {
+	*x = UnlockWithChallengeResponse{}
+	mi := &file_proto_user_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockWithChallengeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockWithChallengeResponse) ProtoMessage() {}
+
+func (x *UnlockWithChallengeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockWithChallengeResponse.ProtoReflect.Descriptor instead.
+func (*UnlockWithChallengeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *UnlockWithChallengeResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Account recovery messages
+type StartAccountRecoveryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartAccountRecoveryRequest) Reset() {
+	*x = StartAccountRecoveryRequest{}
+	mi := &file_proto_user_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartAccountRecoveryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartAccountRecoveryRequest) ProtoMessage() {}
+
+func (x *StartAccountRecoveryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartAccountRecoveryRequest.ProtoReflect.Descriptor instead.
+func (*StartAccountRecoveryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *StartAccountRecoveryRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type StartAccountRecoveryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartAccountRecoveryResponse) Reset() {
+	*x = StartAccountRecoveryResponse{}
+	mi := &file_proto_user_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartAccountRecoveryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartAccountRecoveryResponse) ProtoMessage() {}
+
+func (x *StartAccountRecoveryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartAccountRecoveryResponse.ProtoReflect.Descriptor instead.
+func (*StartAccountRecoveryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *StartAccountRecoveryResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type SecureAccountRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Email string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	// Code from the account recovery email
+	EmailCode    string `protobuf:"bytes,2,opt,name=email_code,json=emailCode,proto3" json:"email_code,omitempty"`
+	RecoveryCode string `protobuf:"bytes,3,opt,name=recovery_code,json=recoveryCode,proto3" json:"recovery_code,omitempty"`
+	NewPassword  string `protobuf:"bytes,4,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
+	// Trusted devices the user recognises; every other device is removed
+	KeepDeviceIds []string `protobuf:"bytes,5,rep,name=keep_device_ids,json=keepDeviceIds,proto3" json:"keep_device_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecureAccountRequest) Reset() {
+	*x = SecureAccountRequest{}
+	mi := &file_proto_user_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecureAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecureAccountRequest) ProtoMessage() {}
+
+func (x *SecureAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecureAccountRequest.ProtoReflect.Descriptor instead.
+func (*SecureAccountRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *SecureAccountRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *SecureAccountRequest) GetEmailCode() string {
+	if x != nil {
+		return x.EmailCode
+	}
+	return ""
+}
+
+func (x *SecureAccountRequest) GetRecoveryCode() string {
+	if x != nil {
+		return x.RecoveryCode
+	}
+	return ""
+}
+
+func (x *SecureAccountRequest) GetNewPassword() string {
+	if x != nil {
+		return x.NewPassword
+	}
+	return ""
+}
+
+func (x *SecureAccountRequest) GetKeepDeviceIds() []string {
+	if x != nil {
+		return x.KeepDeviceIds
+	}
+	return nil
+}
+
+type SecureAccountResponse struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Message                string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	DevicesRemoved         int32                  `protobuf:"varint,2,opt,name=devices_removed,json=devicesRemoved,proto3" json:"devices_removed,omitempty"`
+	TwoFactorReset         bool                   `protobuf:"varint,3,opt,name=two_factor_reset,json=twoFactorReset,proto3" json:"two_factor_reset,omitempty"`
+	RecoveryCodesRemaining int32                  `protobuf:"varint,4,opt,name=recovery_codes_remaining,json=recoveryCodesRemaining,proto3" json:"recovery_codes_remaining,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *SecureAccountResponse) Reset() {
+	*x = SecureAccountResponse{}
+	mi := &file_proto_user_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecureAccountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecureAccountResponse) ProtoMessage() {}
+
+func (x *SecureAccountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecureAccountResponse.ProtoReflect.Descriptor instead.
+func (*SecureAccountResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *SecureAccountResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SecureAccountResponse) GetDevicesRemoved() int32 {
+	if x != nil {
+		return x.DevicesRemoved
+	}
+	return 0
+}
+
+func (x *SecureAccountResponse) GetTwoFactorReset() bool {
+	if x != nil {
+		return x.TwoFactorReset
+	}
+	return false
+}
+
+func (x *SecureAccountResponse) GetRecoveryCodesRemaining() int32 {
+	if x != nil {
+		return x.RecoveryCodesRemaining
+	}
+	return 0
+}
+
+// Two-factor enrollment messages
+type EnableTwoFactorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableTwoFactorRequest) Reset() {
+	*x = EnableTwoFactorRequest{}
+	mi := &file_proto_user_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableTwoFactorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableTwoFactorRequest) ProtoMessage() {}
+
+func (x *EnableTwoFactorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableTwoFactorRequest.ProtoReflect.Descriptor instead.
+func (*EnableTwoFactorRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{64}
+}
+
+type EnableTwoFactorResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Base32 TOTP secret, for manual entry
+	Secret string `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	// otpauth:// URI to show as a QR code
+	ProvisioningUri string `protobuf:"bytes,2,opt,name=provisioning_uri,json=provisioningUri,proto3" json:"provisioning_uri,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *EnableTwoFactorResponse) Reset() {
+	*x = EnableTwoFactorResponse{}
+	mi := &file_proto_user_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableTwoFactorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableTwoFactorResponse) ProtoMessage() {}
+
+func (x *EnableTwoFactorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableTwoFactorResponse.ProtoReflect.Descriptor instead.
+func (*EnableTwoFactorResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *EnableTwoFactorResponse) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *EnableTwoFactorResponse) GetProvisioningUri() string {
+	if x != nil {
+		return x.ProvisioningUri
+	}
+	return ""
+}
+
+type VerifyTwoFactorRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Current code from the authenticator app
+	Code          string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyTwoFactorRequest) Reset() {
+	*x = VerifyTwoFactorRequest{}
+	mi := &file_proto_user_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyTwoFactorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyTwoFactorRequest) ProtoMessage() {}
+
+func (x *VerifyTwoFactorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyTwoFactorRequest.ProtoReflect.Descriptor instead.
+func (*VerifyTwoFactorRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *VerifyTwoFactorRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type VerifyTwoFactorResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// Full session token, returned when the call was made with an
+	// enrollment-only token
+	Token         string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	RefreshToken  string `protobuf:"bytes,3,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyTwoFactorResponse) Reset() {
+	*x = VerifyTwoFactorResponse{}
+	mi := &file_proto_user_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyTwoFactorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyTwoFactorResponse) ProtoMessage() {}
+
+func (x *VerifyTwoFactorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyTwoFactorResponse.ProtoReflect.Descriptor instead.
+func (*VerifyTwoFactorResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *VerifyTwoFactorResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *VerifyTwoFactorResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *VerifyTwoFactorResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+type DisableTwoFactorRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Password string                 `protobuf:"bytes,1,opt,name=password,proto3" json:"password,omitempty"`
+	// Current code from the authenticator app, or a recovery code
+	Code          string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableTwoFactorRequest) Reset() {
+	*x = DisableTwoFactorRequest{}
+	mi := &file_proto_user_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableTwoFactorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableTwoFactorRequest) ProtoMessage() {}
+
+func (x *DisableTwoFactorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableTwoFactorRequest.ProtoReflect.Descriptor instead.
+func (*DisableTwoFactorRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *DisableTwoFactorRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *DisableTwoFactorRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type DisableTwoFactorResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableTwoFactorResponse) Reset() {
+	*x = DisableTwoFactorResponse{}
+	mi := &file_proto_user_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableTwoFactorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableTwoFactorResponse) ProtoMessage() {}
+
+func (x *DisableTwoFactorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableTwoFactorResponse.ProtoReflect.Descriptor instead.
+func (*DisableTwoFactorResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *DisableTwoFactorResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Passkey messages
+type Passkey struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Authenticator model, a UUID
+	Aaguid string `protobuf:"bytes,2,opt,name=aaguid,proto3" json:"aaguid,omitempty"`
+	// Attestation statement format, "none" without attestation
+	AttestationFormat string `protobuf:"bytes,3,opt,name=attestation_format,json=attestationFormat,proto3" json:"attestation_format,omitempty"`
+	// Whether the passkey can be synced between devices
+	BackupEligible bool                   `protobuf:"varint,4,opt,name=backup_eligible,json=backupEligible,proto3" json:"backup_eligible,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Name           string                 `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Passkey) Reset() {
+	*x = Passkey{}
+	mi := &file_proto_user_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Passkey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Passkey) ProtoMessage() {}
+
+func (x *Passkey) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Passkey.ProtoReflect.Descriptor instead.
+func (*Passkey) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *Passkey) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Passkey) GetAaguid() string {
+	if x != nil {
+		return x.Aaguid
+	}
+	return ""
+}
+
+func (x *Passkey) GetAttestationFormat() string {
+	if x != nil {
+		return x.AttestationFormat
+	}
+	return ""
+}
+
+func (x *Passkey) GetBackupEligible() bool {
+	if x != nil {
+		return x.BackupEligible
+	}
+	return false
+}
+
+func (x *Passkey) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Passkey) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type BeginCredentialRegistrationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BeginCredentialRegistrationRequest) Reset() {
+	*x = BeginCredentialRegistrationRequest{}
+	mi := &file_proto_user_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BeginCredentialRegistrationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BeginCredentialRegistrationRequest) ProtoMessage() {}
+
+func (x *BeginCredentialRegistrationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BeginCredentialRegistrationRequest.ProtoReflect.Descriptor instead.
+func (*BeginCredentialRegistrationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{71}
+}
+
+type BeginCredentialRegistrationResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Options for navigator.credentials.create(), as JSON
+	CreationOptions string                 `protobuf:"bytes,1,opt,name=creation_options,json=creationOptions,proto3" json:"creation_options,omitempty"`
+	ExpiresAt       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *BeginCredentialRegistrationResponse) Reset() {
+	*x = BeginCredentialRegistrationResponse{}
+	mi := &file_proto_user_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BeginCredentialRegistrationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BeginCredentialRegistrationResponse) ProtoMessage() {}
+
+func (x *BeginCredentialRegistrationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BeginCredentialRegistrationResponse.ProtoReflect.Descriptor instead.
+func (*BeginCredentialRegistrationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *BeginCredentialRegistrationResponse) GetCreationOptions() string {
+	if x != nil {
+		return x.CreationOptions
+	}
+	return ""
+}
+
+func (x *BeginCredentialRegistrationResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type RegisterCredentialRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// PublicKeyCredential returned by navigator.credentials.create(), as JSON
+	Credential string `protobuf:"bytes,1,opt,name=credential,proto3" json:"credential,omitempty"`
+	// Optional name, such as "Work laptop"
+	Name          string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterCredentialRequest) Reset() {
+	*x = RegisterCredentialRequest{}
+	mi := &file_proto_user_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterCredentialRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterCredentialRequest) ProtoMessage() {}
+
+func (x *RegisterCredentialRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterCredentialRequest.ProtoReflect.Descriptor instead.
+func (*RegisterCredentialRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *RegisterCredentialRequest) GetCredential() string {
+	if x != nil {
+		return x.Credential
+	}
+	return ""
+}
+
+func (x *RegisterCredentialRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type RegisterCredentialResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Passkey       *Passkey               `protobuf:"bytes,1,opt,name=passkey,proto3" json:"passkey,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterCredentialResponse) Reset() {
+	*x = RegisterCredentialResponse{}
+	mi := &file_proto_user_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterCredentialResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterCredentialResponse) ProtoMessage() {}
+
+func (x *RegisterCredentialResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterCredentialResponse.ProtoReflect.Descriptor instead.
+func (*RegisterCredentialResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *RegisterCredentialResponse) GetPasskey() *Passkey {
+	if x != nil {
+		return x.Passkey
+	}
+	return nil
+}
+
+// Credential messages
+type Credential struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// "passkey" or "trusted_device"
+	Type      string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Name      string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Unset for a passkey that was never used to sign in
+	LastUsedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	// Browser and address a trusted device last signed in from
+	UserAgent string `protobuf:"bytes,6,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	IpAddress string `protobuf:"bytes,7,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	// Authenticator model of a passkey
+	Aaguid        string `protobuf:"bytes,8,opt,name=aaguid,proto3" json:"aaguid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Credential) Reset() {
+	*x = Credential{}
+	mi := &file_proto_user_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Credential) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Credential) ProtoMessage() {}
+
+func (x *Credential) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Credential.ProtoReflect.Descriptor instead.
+func (*Credential) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *Credential) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Credential) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Credential) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Credential) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Credential) GetLastUsedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return nil
+}
+
+func (x *Credential) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *Credential) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *Credential) GetAaguid() string {
+	if x != nil {
+		return x.Aaguid
+	}
+	return ""
+}
+
+type ListCredentialsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCredentialsRequest) Reset() {
+	*x = ListCredentialsRequest{}
+	mi := &file_proto_user_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCredentialsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCredentialsRequest) ProtoMessage() {}
+
+func (x *ListCredentialsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCredentialsRequest.ProtoReflect.Descriptor instead.
+func (*ListCredentialsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{76}
+}
+
+type ListCredentialsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Oldest first
+	Credentials   []*Credential `protobuf:"bytes,1,rep,name=credentials,proto3" json:"credentials,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCredentialsResponse) Reset() {
+	*x = ListCredentialsResponse{}
+	mi := &file_proto_user_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCredentialsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCredentialsResponse) ProtoMessage() {}
+
+func (x *ListCredentialsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCredentialsResponse.ProtoReflect.Descriptor instead.
+func (*ListCredentialsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *ListCredentialsResponse) GetCredentials() []*Credential {
+	if x != nil {
+		return x.Credentials
+	}
+	return nil
+}
+
+type RenameCredentialRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameCredentialRequest) Reset() {
+	*x = RenameCredentialRequest{}
+	mi := &file_proto_user_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameCredentialRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameCredentialRequest) ProtoMessage() {}
+
+func (x *RenameCredentialRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameCredentialRequest.ProtoReflect.Descriptor instead.
+func (*RenameCredentialRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *RenameCredentialRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RenameCredentialRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *RenameCredentialRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type RenameCredentialResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Credential    *Credential            `protobuf:"bytes,1,opt,name=credential,proto3" json:"credential,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameCredentialResponse) Reset() {
+	*x = RenameCredentialResponse{}
+	mi := &file_proto_user_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameCredentialResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameCredentialResponse) ProtoMessage() {}
+
+func (x *RenameCredentialResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameCredentialResponse.ProtoReflect.Descriptor instead.
+func (*RenameCredentialResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *RenameCredentialResponse) GetCredential() *Credential {
+	if x != nil {
+		return x.Credential
+	}
+	return nil
+}
+
+type DeleteCredentialRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCredentialRequest) Reset() {
+	*x = DeleteCredentialRequest{}
+	mi := &file_proto_user_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCredentialRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCredentialRequest) ProtoMessage() {}
+
+func (x *DeleteCredentialRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCredentialRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCredentialRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *DeleteCredentialRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteCredentialRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type DeleteCredentialResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCredentialResponse) Reset() {
+	*x = DeleteCredentialResponse{}
+	mi := &file_proto_user_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCredentialResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCredentialResponse) ProtoMessage() {}
+
+func (x *DeleteCredentialResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCredentialResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCredentialResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{81}
+}
+
+func (x *DeleteCredentialResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// KnownDevice is a device the account has signed in from. Logins from other
+// devices are new, and the owner is alerted of them.
+type KnownDevice struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Name the user gave the device, if any
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Browser and address the device last signed in from
+	UserAgent   string                 `protobuf:"bytes,3,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	IpAddress   string                 `protobuf:"bytes,4,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	FirstSeenAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=first_seen_at,json=firstSeenAt,proto3" json:"first_seen_at,omitempty"`
+	LastSeenAt  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_seen_at,json=lastSeenAt,proto3" json:"last_seen_at,omitempty"`
+	// Whether the request was made from this device
+	Current       bool `protobuf:"varint,7,opt,name=current,proto3" json:"current,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KnownDevice) Reset() {
+	*x = KnownDevice{}
+	mi := &file_proto_user_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KnownDevice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KnownDevice) ProtoMessage() {}
+
+func (x *KnownDevice) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KnownDevice.ProtoReflect.Descriptor instead.
+func (*KnownDevice) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *KnownDevice) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *KnownDevice) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *KnownDevice) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *KnownDevice) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *KnownDevice) GetFirstSeenAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstSeenAt
+	}
+	return nil
+}
+
+func (x *KnownDevice) GetLastSeenAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeenAt
+	}
+	return nil
+}
+
+func (x *KnownDevice) GetCurrent() bool {
+	if x != nil {
+		return x.Current
+	}
+	return false
+}
+
+type ListKnownDevicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListKnownDevicesRequest) Reset() {
+	*x = ListKnownDevicesRequest{}
+	mi := &file_proto_user_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListKnownDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListKnownDevicesRequest) ProtoMessage() {}
+
+func (x *ListKnownDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListKnownDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ListKnownDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *ListKnownDevicesRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListKnownDevicesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListKnownDevicesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Most recently seen first
+	Devices       []*KnownDevice `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+	TotalCount    int32          `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Page          int32          `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32          `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListKnownDevicesResponse) Reset() {
+	*x = ListKnownDevicesResponse{}
+	mi := &file_proto_user_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListKnownDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListKnownDevicesResponse) ProtoMessage() {}
+
+func (x *ListKnownDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListKnownDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ListKnownDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{84}
+}
+
+func (x *ListKnownDevicesResponse) GetDevices() []*KnownDevice {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+func (x *ListKnownDevicesResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *ListKnownDevicesResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListKnownDevicesResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+// Linked identity messages
+type LinkedIdentity struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "sso", or a social login provider such as "google"
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	// Issuer and subject identify the user at the provider
+	Issuer  string `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	Subject string `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
+	// Email the provider gave when the identity was linked
+	Email string `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	// Organization whose identity provider it is, for sso
+	OrganizationId string                 `protobuf:"bytes,5,opt,name=organization_id,json=organizationId,proto3" json:"organization_id,omitempty"`
+	LinkedAt       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=linked_at,json=linkedAt,proto3" json:"linked_at,omitempty"`
+	LastLoginAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_login_at,json=lastLoginAt,proto3" json:"last_login_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *LinkedIdentity) Reset() {
+	*x = LinkedIdentity{}
+	mi := &file_proto_user_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LinkedIdentity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkedIdentity) ProtoMessage() {}
+
+func (x *LinkedIdentity) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkedIdentity.ProtoReflect.Descriptor instead.
+func (*LinkedIdentity) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{85}
+}
+
+func (x *LinkedIdentity) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *LinkedIdentity) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *LinkedIdentity) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *LinkedIdentity) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *LinkedIdentity) GetOrganizationId() string {
+	if x != nil {
+		return x.OrganizationId
+	}
+	return ""
+}
+
+func (x *LinkedIdentity) GetLinkedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LinkedAt
+	}
+	return nil
+}
+
+func (x *LinkedIdentity) GetLastLoginAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastLoginAt
+	}
+	return nil
+}
+
+type ListLinkedIdentitiesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLinkedIdentitiesRequest) Reset() {
+	*x = ListLinkedIdentitiesRequest{}
+	mi := &file_proto_user_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLinkedIdentitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLinkedIdentitiesRequest) ProtoMessage() {}
+
+func (x *ListLinkedIdentitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLinkedIdentitiesRequest.ProtoReflect.Descriptor instead.
+func (*ListLinkedIdentitiesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{86}
+}
+
+type ListLinkedIdentitiesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Oldest first
+	Identities []*LinkedIdentity `protobuf:"bytes,1,rep,name=identities,proto3" json:"identities,omitempty"`
+	// Whether the account can also sign in with a password
+	HasPassword   bool `protobuf:"varint,2,opt,name=has_password,json=hasPassword,proto3" json:"has_password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLinkedIdentitiesResponse) Reset() {
+	*x = ListLinkedIdentitiesResponse{}
+	mi := &file_proto_user_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLinkedIdentitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLinkedIdentitiesResponse) ProtoMessage() {}
+
+func (x *ListLinkedIdentitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLinkedIdentitiesResponse.ProtoReflect.Descriptor instead.
+func (*ListLinkedIdentitiesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{87}
+}
+
+func (x *ListLinkedIdentitiesResponse) GetIdentities() []*LinkedIdentity {
+	if x != nil {
+		return x.Identities
+	}
+	return nil
+}
+
+func (x *ListLinkedIdentitiesResponse) GetHasPassword() bool {
+	if x != nil {
+		return x.HasPassword
+	}
+	return false
+}
+
+type UnlinkIdentityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Issuer        string                 `protobuf:"bytes,1,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	Subject       string                 `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlinkIdentityRequest) Reset() {
+	*x = UnlinkIdentityRequest{}
+	mi := &file_proto_user_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlinkIdentityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlinkIdentityRequest) ProtoMessage() {}
+
+func (x *UnlinkIdentityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[88]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlinkIdentityRequest.ProtoReflect.Descriptor instead.
+func (*UnlinkIdentityRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{88}
+}
+
+func (x *UnlinkIdentityRequest) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *UnlinkIdentityRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+type UnlinkIdentityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlinkIdentityResponse) Reset() {
+	*x = UnlinkIdentityResponse{}
+	mi := &file_proto_user_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlinkIdentityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlinkIdentityResponse) ProtoMessage() {}
+
+func (x *UnlinkIdentityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlinkIdentityResponse.ProtoReflect.Descriptor instead.
+func (*UnlinkIdentityResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *UnlinkIdentityResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Session messages
+type Session struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Name the user gave the device the session was started on, if any
+	DeviceName     string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	UserAgent      string                 `protobuf:"bytes,3,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	IpAddress      string                 `protobuf:"bytes,4,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	IssuedAt       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	LastActivityAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_activity_at,json=lastActivityAt,proto3" json:"last_activity_at,omitempty"`
+	ExpiresAt      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Whether this is the session the request was made with
+	Current bool `protobuf:"varint,8,opt,name=current,proto3" json:"current,omitempty"`
+	// Whether the device the session was started on is one of the account's
+	// known devices, which logins from new devices are checked against
+	DeviceRegistered bool `protobuf:"varint,9,opt,name=device_registered,json=deviceRegistered,proto3" json:"device_registered,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_proto_user_proto_msgTypes[90]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[90]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{90}
+}
+
+func (x *Session) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Session) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *Session) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *Session) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *Session) GetIssuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return nil
+}
+
+func (x *Session) GetLastActivityAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActivityAt
+	}
+	return nil
+}
+
+func (x *Session) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Session) GetCurrent() bool {
+	if x != nil {
+		return x.Current
+	}
+	return false
+}
+
+func (x *Session) GetDeviceRegistered() bool {
+	if x != nil {
+		return x.DeviceRegistered
+	}
+	return false
+}
+
+type ListSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_proto_user_proto_msgTypes[91]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[91]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{91}
+}
+
+type ListSessionsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Most recently used first
+	Sessions      []*Session `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_proto_user_proto_msgTypes[92]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[92]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{92}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+type RevokeSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeSessionRequest) Reset() {
+	*x = RevokeSessionRequest{}
+	mi := &file_proto_user_proto_msgTypes[93]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeSessionRequest) ProtoMessage() {}
+
+func (x *RevokeSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[93]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeSessionRequest.ProtoReflect.Descriptor instead.
+func (*RevokeSessionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{93}
+}
+
+func (x *RevokeSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type RevokeSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeSessionResponse) Reset() {
+	*x = RevokeSessionResponse{}
+	mi := &file_proto_user_proto_msgTypes[94]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeSessionResponse) ProtoMessage() {}
+
+func (x *RevokeSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[94]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeSessionResponse.ProtoReflect.Descriptor instead.
+func (*RevokeSessionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{94}
+}
+
+func (x *RevokeSessionResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type WatchSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchSessionRequest) Reset() {
+	*x = WatchSessionRequest{}
+	mi := &file_proto_user_proto_msgTypes[95]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSessionRequest) ProtoMessage() {}
+
+func (x *WatchSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[95]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSessionRequest.ProtoReflect.Descriptor instead.
+func (*WatchSessionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{95}
+}
+
+// SessionEvent reports the state of the caller's session to WatchSession
+type SessionEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "active" when the watch starts and every 30 seconds while the session
+	// lasts, or "ended" as the last event
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Why the session ended: "revoked" when it was signed out, alone or with
+	// every other session, "expired" when its token expired, or "idle" when
+	// it went unused for too long
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionEvent) Reset() {
+	*x = SessionEvent{}
+	mi := &file_proto_user_proto_msgTypes[96]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionEvent) ProtoMessage() {}
+
+func (x *SessionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[96]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionEvent.ProtoReflect.Descriptor instead.
+func (*SessionEvent) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{96}
+}
+
+func (x *SessionEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SessionEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SessionEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+// ApiKey is a long-lived key a machine client sends in the "x-api-key"
+// metadata instead of a bearer token, to act as the user who created it
+type ApiKey struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Start of the key, to tell keys apart
+	Prefix    string                 `protobuf:"bytes,3,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Unset until the key is used
+	LastUsedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	// Unset for keys that last until they are revoked
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApiKey) Reset() {
+	*x = ApiKey{}
+	mi := &file_proto_user_proto_msgTypes[97]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApiKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiKey) ProtoMessage() {}
+
+func (x *ApiKey) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[97]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiKey.ProtoReflect.Descriptor instead.
+func (*ApiKey) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{97}
+}
+
+func (x *ApiKey) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ApiKey) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ApiKey) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ApiKey) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ApiKey) GetLastUsedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return nil
+}
+
+func (x *ApiKey) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type CreateApiKeyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Such as "Billing sync"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Optional, must be in the future
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateApiKeyRequest) Reset() {
+	*x = CreateApiKeyRequest{}
+	mi := &file_proto_user_proto_msgTypes[98]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateApiKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateApiKeyRequest) ProtoMessage() {}
+
+func (x *CreateApiKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[98]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateApiKeyRequest.ProtoReflect.Descriptor instead.
+func (*CreateApiKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{98}
+}
+
+func (x *CreateApiKeyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateApiKeyRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type CreateApiKeyResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	ApiKey *ApiKey                `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	// The key itself. Only its hash is stored, so it can't be shown again.
+	Key           string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateApiKeyResponse) Reset() {
+	*x = CreateApiKeyResponse{}
+	mi := &file_proto_user_proto_msgTypes[99]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateApiKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateApiKeyResponse) ProtoMessage() {}
+
+func (x *CreateApiKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[99]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateApiKeyResponse.ProtoReflect.Descriptor instead.
+func (*CreateApiKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{99}
+}
+
+func (x *CreateApiKeyResponse) GetApiKey() *ApiKey {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+func (x *CreateApiKeyResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type ListApiKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListApiKeysRequest) Reset() {
+	*x = ListApiKeysRequest{}
+	mi := &file_proto_user_proto_msgTypes[100]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListApiKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListApiKeysRequest) ProtoMessage() {}
+
+func (x *ListApiKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[100]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListApiKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListApiKeysRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{100}
+}
+
+type ListApiKeysResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Oldest first
+	ApiKeys       []*ApiKey `protobuf:"bytes,1,rep,name=api_keys,json=apiKeys,proto3" json:"api_keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListApiKeysResponse) Reset() {
+	*x = ListApiKeysResponse{}
+	mi := &file_proto_user_proto_msgTypes[101]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListApiKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListApiKeysResponse) ProtoMessage() {}
+
+func (x *ListApiKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[101]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListApiKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListApiKeysResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{101}
+}
+
+func (x *ListApiKeysResponse) GetApiKeys() []*ApiKey {
+	if x != nil {
+		return x.ApiKeys
+	}
+	return nil
+}
+
+type RevokeApiKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeApiKeyRequest) Reset() {
+	*x = RevokeApiKeyRequest{}
+	mi := &file_proto_user_proto_msgTypes[102]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeApiKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeApiKeyRequest) ProtoMessage() {}
+
+func (x *RevokeApiKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[102]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeApiKeyRequest.ProtoReflect.Descriptor instead.
+func (*RevokeApiKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{102}
+}
+
+func (x *RevokeApiKeyRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RevokeApiKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeApiKeyResponse) Reset() {
+	*x = RevokeApiKeyResponse{}
+	mi := &file_proto_user_proto_msgTypes[103]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeApiKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeApiKeyResponse) ProtoMessage() {}
+
+func (x *RevokeApiKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[103]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeApiKeyResponse.ProtoReflect.Descriptor instead.
+func (*RevokeApiKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{103}
+}
+
+func (x *RevokeApiKeyResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Terms of service messages
+type AcceptTermsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Version being accepted, which must be the current one
+	Version       string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptTermsRequest) Reset() {
+	*x = AcceptTermsRequest{}
+	mi := &file_proto_user_proto_msgTypes[104]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptTermsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptTermsRequest) ProtoMessage() {}
+
+func (x *AcceptTermsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[104]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptTermsRequest.ProtoReflect.Descriptor instead.
+func (*AcceptTermsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{104}
+}
+
+func (x *AcceptTermsRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type AcceptTermsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptTermsResponse) Reset() {
+	*x = AcceptTermsResponse{}
+	mi := &file_proto_user_proto_msgTypes[105]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptTermsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptTermsResponse) ProtoMessage() {}
+
+func (x *AcceptTermsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[105]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptTermsResponse.ProtoReflect.Descriptor instead.
+func (*AcceptTermsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{105}
+}
+
+func (x *AcceptTermsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Two-factor reset messages
+type StartTwoFactorResetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartTwoFactorResetRequest) Reset() {
+	*x = StartTwoFactorResetRequest{}
+	mi := &file_proto_user_proto_msgTypes[106]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartTwoFactorResetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTwoFactorResetRequest) ProtoMessage() {}
+
+func (x *StartTwoFactorResetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[106]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTwoFactorResetRequest.ProtoReflect.Descriptor instead.
+func (*StartTwoFactorResetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{106}
+}
+
+func (x *StartTwoFactorResetRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type StartTwoFactorResetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartTwoFactorResetResponse) Reset() {
+	*x = StartTwoFactorResetResponse{}
+	mi := &file_proto_user_proto_msgTypes[107]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartTwoFactorResetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTwoFactorResetResponse) ProtoMessage() {}
+
+func (x *StartTwoFactorResetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[107]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTwoFactorResetResponse.ProtoReflect.Descriptor instead.
+func (*StartTwoFactorResetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{107}
+}
+
+func (x *StartTwoFactorResetResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ResetTwoFactorRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Email    string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Password string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	// One of email_code, from StartTwoFactorReset, or recovery_code is
+	// required
+	EmailCode     string `protobuf:"bytes,3,opt,name=email_code,json=emailCode,proto3" json:"email_code,omitempty"`
+	RecoveryCode  string `protobuf:"bytes,4,opt,name=recovery_code,json=recoveryCode,proto3" json:"recovery_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetTwoFactorRequest) Reset() {
+	*x = ResetTwoFactorRequest{}
+	mi := &file_proto_user_proto_msgTypes[108]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetTwoFactorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetTwoFactorRequest) ProtoMessage() {}
+
+func (x *ResetTwoFactorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[108]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetTwoFactorRequest.ProtoReflect.Descriptor instead.
+func (*ResetTwoFactorRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{108}
+}
+
+func (x *ResetTwoFactorRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ResetTwoFactorRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *ResetTwoFactorRequest) GetEmailCode() string {
+	if x != nil {
+		return x.EmailCode
+	}
+	return ""
+}
+
+func (x *ResetTwoFactorRequest) GetRecoveryCode() string {
+	if x != nil {
+		return x.RecoveryCode
+	}
+	return ""
+}
+
+type ResetTwoFactorResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// When two-factor authentication will be removed
+	EffectiveAt   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=effective_at,json=effectiveAt,proto3" json:"effective_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetTwoFactorResponse) Reset() {
+	*x = ResetTwoFactorResponse{}
+	mi := &file_proto_user_proto_msgTypes[109]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetTwoFactorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetTwoFactorResponse) ProtoMessage() {}
+
+func (x *ResetTwoFactorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[109]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetTwoFactorResponse.ProtoReflect.Descriptor instead.
+func (*ResetTwoFactorResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{109}
+}
+
+func (x *ResetTwoFactorResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ResetTwoFactorResponse) GetEffectiveAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveAt
+	}
+	return nil
+}
+
+type CancelTwoFactorResetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Token from the cancel link
+	Token         string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTwoFactorResetRequest) Reset() {
+	*x = CancelTwoFactorResetRequest{}
+	mi := &file_proto_user_proto_msgTypes[110]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTwoFactorResetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTwoFactorResetRequest) ProtoMessage() {}
+
+func (x *CancelTwoFactorResetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[110]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTwoFactorResetRequest.ProtoReflect.Descriptor instead.
+func (*CancelTwoFactorResetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{110}
+}
+
+func (x *CancelTwoFactorResetRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type CancelTwoFactorResetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTwoFactorResetResponse) Reset() {
+	*x = CancelTwoFactorResetResponse{}
+	mi := &file_proto_user_proto_msgTypes[111]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTwoFactorResetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTwoFactorResetResponse) ProtoMessage() {}
+
+func (x *CancelTwoFactorResetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[111]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTwoFactorResetResponse.ProtoReflect.Descriptor instead.
+func (*CancelTwoFactorResetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{111}
+}
+
+func (x *CancelTwoFactorResetResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Password reset messages
+type RequestPasswordResetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestPasswordResetRequest) Reset() {
+	*x = RequestPasswordResetRequest{}
+	mi := &file_proto_user_proto_msgTypes[112]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestPasswordResetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestPasswordResetRequest) ProtoMessage() {}
+
+func (x *RequestPasswordResetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[112]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestPasswordResetRequest.ProtoReflect.Descriptor instead.
+func (*RequestPasswordResetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{112}
+}
+
+func (x *RequestPasswordResetRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type RequestPasswordResetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestPasswordResetResponse) Reset() {
+	*x = RequestPasswordResetResponse{}
+	mi := &file_proto_user_proto_msgTypes[113]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestPasswordResetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestPasswordResetResponse) ProtoMessage() {}
+
+func (x *RequestPasswordResetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[113]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestPasswordResetResponse.ProtoReflect.Descriptor instead.
+func (*RequestPasswordResetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{113}
+}
+
+func (x *RequestPasswordResetResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ResetPasswordRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Token from the reset link
+	Token         string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	NewPassword   string `protobuf:"bytes,2,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetPasswordRequest) Reset() {
+	*x = ResetPasswordRequest{}
+	mi := &file_proto_user_proto_msgTypes[114]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetPasswordRequest) ProtoMessage() {}
+
+func (x *ResetPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[114]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetPasswordRequest.ProtoReflect.Descriptor instead.
+func (*ResetPasswordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{114}
+}
+
+func (x *ResetPasswordRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ResetPasswordRequest) GetNewPassword() string {
+	if x != nil {
+		return x.NewPassword
+	}
+	return ""
+}
+
+type ResetPasswordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetPasswordResponse) Reset() {
+	*x = ResetPasswordResponse{}
+	mi := &file_proto_user_proto_msgTypes[115]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetPasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetPasswordResponse) ProtoMessage() {}
+
+func (x *ResetPasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[115]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetPasswordResponse.ProtoReflect.Descriptor instead.
+func (*ResetPasswordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{115}
+}
+
+func (x *ResetPasswordResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Password strength messages
+type EvaluatePasswordRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Password string                 `protobuf:"bytes,1,opt,name=password,proto3" json:"password,omitempty"`
+	// Email and name of the account, if known. Passwords containing them are
+	// scored lower.
+	Email         string `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Name          string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvaluatePasswordRequest) Reset() {
+	*x = EvaluatePasswordRequest{}
+	mi := &file_proto_user_proto_msgTypes[116]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvaluatePasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluatePasswordRequest) ProtoMessage() {}
+
+func (x *EvaluatePasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[116]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluatePasswordRequest.ProtoReflect.Descriptor instead.
+func (*EvaluatePasswordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{116}
+}
+
+func (x *EvaluatePasswordRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *EvaluatePasswordRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *EvaluatePasswordRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type EvaluatePasswordResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Score runs from 0, guessed in moments, to 4, out of reach of an offline
+	// attack
+	Score int32 `protobuf:"varint,1,opt,name=score,proto3" json:"score,omitempty"`
+	// Estimated time for an offline attack on the stored password hash
+	CrackTimeSeconds float64 `protobuf:"fixed64,2,opt,name=crack_time_seconds,json=crackTimeSeconds,proto3" json:"crack_time_seconds,omitempty"`
+	// crack_time_seconds in words, such as "3 hours" or "centuries"
+	CrackTimeDisplay string `protobuf:"bytes,3,opt,name=crack_time_display,json=crackTimeDisplay,proto3" json:"crack_time_display,omitempty"`
+	// Policy rules the password fails, in the order of requirements
+	UnmetRequirements []string `protobuf:"bytes,4,rep,name=unmet_requirements,json=unmetRequirements,proto3" json:"unmet_requirements,omitempty"`
+	// Every rule of the password policy
+	Requirements []string `protobuf:"bytes,5,rep,name=requirements,proto3" json:"requirements,omitempty"`
+	// Whether the password would be accepted
+	Acceptable bool `protobuf:"varint,6,opt,name=acceptable,proto3" json:"acceptable,omitempty"`
+	// Explains the weakest part of the password, or is empty
+	Warning       string `protobuf:"bytes,7,opt,name=warning,proto3" json:"warning,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvaluatePasswordResponse) Reset() {
+	*x = EvaluatePasswordResponse{}
+	mi := &file_proto_user_proto_msgTypes[117]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvaluatePasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluatePasswordResponse) ProtoMessage() {}
+
+func (x *EvaluatePasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[117]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluatePasswordResponse.ProtoReflect.Descriptor instead.
+func (*EvaluatePasswordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{117}
+}
+
+func (x *EvaluatePasswordResponse) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *EvaluatePasswordResponse) GetCrackTimeSeconds() float64 {
+	if x != nil {
+		return x.CrackTimeSeconds
+	}
+	return 0
+}
+
+func (x *EvaluatePasswordResponse) GetCrackTimeDisplay() string {
+	if x != nil {
+		return x.CrackTimeDisplay
+	}
+	return ""
+}
+
+func (x *EvaluatePasswordResponse) GetUnmetRequirements() []string {
+	if x != nil {
+		return x.UnmetRequirements
+	}
+	return nil
+}
+
+func (x *EvaluatePasswordResponse) GetRequirements() []string {
+	if x != nil {
+		return x.Requirements
+	}
+	return nil
+}
+
+func (x *EvaluatePasswordResponse) GetAcceptable() bool {
+	if x != nil {
+		return x.Acceptable
+	}
+	return false
+}
+
+func (x *EvaluatePasswordResponse) GetWarning() string {
+	if x != nil {
+		return x.Warning
+	}
+	return ""
+}
+
+type GenerateRecoveryCodesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Password      string                 `protobuf:"bytes,1,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateRecoveryCodesRequest) Reset() {
+	*x = GenerateRecoveryCodesRequest{}
+	mi := &file_proto_user_proto_msgTypes[118]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateRecoveryCodesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateRecoveryCodesRequest) ProtoMessage() {}
+
+func (x *GenerateRecoveryCodesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[118]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateRecoveryCodesRequest.ProtoReflect.Descriptor instead.
+func (*GenerateRecoveryCodesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{118}
+}
+
+func (x *GenerateRecoveryCodesRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type GenerateRecoveryCodesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RecoveryCodes []string               `protobuf:"bytes,1,rep,name=recovery_codes,json=recoveryCodes,proto3" json:"recovery_codes,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateRecoveryCodesResponse) Reset() {
+	*x = GenerateRecoveryCodesResponse{}
+	mi := &file_proto_user_proto_msgTypes[119]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateRecoveryCodesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateRecoveryCodesResponse) ProtoMessage() {}
+
+func (x *GenerateRecoveryCodesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[119]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateRecoveryCodesResponse.ProtoReflect.Descriptor instead.
+func (*GenerateRecoveryCodesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{119}
+}
+
+func (x *GenerateRecoveryCodesResponse) GetRecoveryCodes() []string {
+	if x != nil {
+		return x.RecoveryCodes
+	}
+	return nil
+}
+
+func (x *GenerateRecoveryCodesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Organization messages
+type OrganizationPolicy struct {
+	state                        protoimpl.MessageState `protogen:"open.v1"`
+	RequireProfileChangeApproval bool                   `protobuf:"varint,1,opt,name=require_profile_change_approval,json=requireProfileChangeApproval,proto3" json:"require_profile_change_approval,omitempty"`
+	// Members without 2FA get a token that only allows enrollment once the
+	// grace period has passed since their first login under the policy
+	RequireTwoFactor bool `protobuf:"varint,2,opt,name=require_two_factor,json=requireTwoFactor,proto3" json:"require_two_factor,omitempty"`
+	// Zero uses the deployment's grace period
+	TwoFactorGracePeriod *durationpb.Duration `protobuf:"bytes,3,opt,name=two_factor_grace_period,json=twoFactorGracePeriod,proto3" json:"two_factor_grace_period,omitempty"`
+	// Login methods members' credentials are checked with, in the order to
+	// try them: password, ldap, oidc, passkey or otp. Empty uses the
+	// deployment's.
+	LoginMethods  []string `protobuf:"bytes,4,rep,name=login_methods,json=loginMethods,proto3" json:"login_methods,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrganizationPolicy) Reset() {
+	*x = OrganizationPolicy{}
+	mi := &file_proto_user_proto_msgTypes[120]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrganizationPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrganizationPolicy) ProtoMessage() {}
+
+func (x *OrganizationPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[120]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrganizationPolicy.ProtoReflect.Descriptor instead.
+func (*OrganizationPolicy) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{120}
+}
+
+func (x *OrganizationPolicy) GetRequireProfileChangeApproval() bool {
+	if x != nil {
+		return x.RequireProfileChangeApproval
+	}
+	return false
+}
+
+func (x *OrganizationPolicy) GetRequireTwoFactor() bool {
+	if x != nil {
+		return x.RequireTwoFactor
+	}
+	return false
+}
+
+func (x *OrganizationPolicy) GetTwoFactorGracePeriod() *durationpb.Duration {
+	if x != nil {
+		return x.TwoFactorGracePeriod
+	}
+	return nil
+}
+
+func (x *OrganizationPolicy) GetLoginMethods() []string {
+	if x != nil {
+		return x.LoginMethods
+	}
+	return nil
+}
+
+// OrganizationSSO connects an organization to its identity provider
+type OrganizationSSO struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only "oidc" is supported
+	Protocol string `protobuf:"bytes,1,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// Issuer URL of the provider, where its discovery document is published
+	Issuer   string `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	ClientId string `protobuf:"bytes,3,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	// Write-only, never returned
+	ClientSecret string `protobuf:"bytes,4,opt,name=client_secret,json=clientSecret,proto3" json:"client_secret,omitempty"`
+	// Email domains the organization owns, whose logins go to the provider
+	Domains []string `protobuf:"bytes,5,rep,name=domains,proto3" json:"domains,omitempty"`
+	// Whether members may only sign in through the provider
+	Enforced      bool `protobuf:"varint,6,opt,name=enforced,proto3" json:"enforced,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrganizationSSO) Reset() {
+	*x = OrganizationSSO{}
+	mi := &file_proto_user_proto_msgTypes[121]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrganizationSSO) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrganizationSSO) ProtoMessage() {}
+
+func (x *OrganizationSSO) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[121]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrganizationSSO.ProtoReflect.Descriptor instead.
+func (*OrganizationSSO) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{121}
+}
+
+func (x *OrganizationSSO) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *OrganizationSSO) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *OrganizationSSO) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *OrganizationSSO) GetClientSecret() string {
+	if x != nil {
+		return x.ClientSecret
+	}
+	return ""
+}
+
+func (x *OrganizationSSO) GetDomains() []string {
+	if x != nil {
+		return x.Domains
+	}
+	return nil
+}
+
+func (x *OrganizationSSO) GetEnforced() bool {
+	if x != nil {
+		return x.Enforced
+	}
+	return false
+}
+
+// OrganizationProvisioning holds the rules evaluated when an identity from
+// the organization's provider first signs in
+type OrganizationProvisioning struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Create accounts for identities without one
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// Role of new accounts no group role applies to, "member" by default
+	DefaultRole string `protobuf:"bytes,2,opt,name=default_role,json=defaultRole,proto3" json:"default_role,omitempty"`
+	// ID token claim listing the user's groups, "groups" by default
+	GroupsClaim string `protobuf:"bytes,3,opt,name=groups_claim,json=groupsClaim,proto3" json:"groups_claim,omitempty"`
+	// The first mapping of a group the user is in applies
+	GroupRoles []*ProvisioningGroupRole `protobuf:"bytes,4,rep,name=group_roles,json=groupRoles,proto3" json:"group_roles,omitempty"`
+	Attributes []*ProvisioningAttribute `protobuf:"bytes,5,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	// Email addresses, "@domain" or "group:name" entries that are refused
+	Blocklist []string `protobuf:"bytes,6,rep,name=blocklist,proto3" json:"blocklist,omitempty"`
+	// Roles granted to group members, updated at every login
+	RoleMappings  []*ProvisioningRoleMapping `protobuf:"bytes,7,rep,name=role_mappings,json=roleMappings,proto3" json:"role_mappings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrganizationProvisioning) Reset() {
+	*x = OrganizationProvisioning{}
+	mi := &file_proto_user_proto_msgTypes[122]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrganizationProvisioning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrganizationProvisioning) ProtoMessage() {}
+
+func (x *OrganizationProvisioning) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[122]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrganizationProvisioning.ProtoReflect.Descriptor instead.
+func (*OrganizationProvisioning) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{122}
+}
+
+func (x *OrganizationProvisioning) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *OrganizationProvisioning) GetDefaultRole() string {
+	if x != nil {
+		return x.DefaultRole
+	}
+	return ""
+}
+
+func (x *OrganizationProvisioning) GetGroupsClaim() string {
+	if x != nil {
+		return x.GroupsClaim
+	}
+	return ""
+}
+
+func (x *OrganizationProvisioning) GetGroupRoles() []*ProvisioningGroupRole {
+	if x != nil {
+		return x.GroupRoles
+	}
+	return nil
+}
+
+func (x *OrganizationProvisioning) GetAttributes() []*ProvisioningAttribute {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *OrganizationProvisioning) GetBlocklist() []string {
+	if x != nil {
+		return x.Blocklist
+	}
+	return nil
+}
+
+func (x *OrganizationProvisioning) GetRoleMappings() []*ProvisioningRoleMapping {
+	if x != nil {
+		return x.RoleMappings
+	}
+	return nil
+}
+
+type ProvisioningGroupRole struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Group string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	// "member" or "admin"
+	Role          string `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProvisioningGroupRole) Reset() {
+	*x = ProvisioningGroupRole{}
+	mi := &file_proto_user_proto_msgTypes[123]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProvisioningGroupRole) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvisioningGroupRole) ProtoMessage() {}
+
+func (x *ProvisioningGroupRole) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[123]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvisioningGroupRole.ProtoReflect.Descriptor instead.
+func (*ProvisioningGroupRole) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{123}
+}
+
+func (x *ProvisioningGroupRole) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *ProvisioningGroupRole) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+// ProvisioningRoleMapping grants roles to the members of a group
+type ProvisioningRoleMapping struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Group string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	// Roles such as "billing:read"; not "admin" or "org:" roles
+	Roles         []string `protobuf:"bytes,2,rep,name=roles,proto3" json:"roles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProvisioningRoleMapping) Reset() {
+	*x = ProvisioningRoleMapping{}
+	mi := &file_proto_user_proto_msgTypes[124]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProvisioningRoleMapping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvisioningRoleMapping) ProtoMessage() {}
+
+func (x *ProvisioningRoleMapping) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[124]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvisioningRoleMapping.ProtoReflect.Descriptor instead.
+func (*ProvisioningRoleMapping) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{124}
+}
+
+func (x *ProvisioningRoleMapping) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *ProvisioningRoleMapping) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+// ProvisioningAttribute copies an ID token claim to new accounts
+type ProvisioningAttribute struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Claim string                 `protobuf:"bytes,1,opt,name=claim,proto3" json:"claim,omitempty"`
+	// "name", or "external_id:" followed by a system
+	Field         string `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProvisioningAttribute) Reset() {
+	*x = ProvisioningAttribute{}
+	mi := &file_proto_user_proto_msgTypes[125]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProvisioningAttribute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvisioningAttribute) ProtoMessage() {}
+
+func (x *ProvisioningAttribute) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[125]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvisioningAttribute.ProtoReflect.Descriptor instead.
+func (*ProvisioningAttribute) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{125}
+}
+
+func (x *ProvisioningAttribute) GetClaim() string {
+	if x != nil {
+		return x.Claim
+	}
+	return ""
+}
+
+func (x *ProvisioningAttribute) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+type Organization struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Id            string                    `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                    `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Policy        *OrganizationPolicy       `protobuf:"bytes,3,opt,name=policy,proto3" json:"policy,omitempty"`
+	CreatedAt     *timestamppb.Timestamp    `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp    `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Sso           *OrganizationSSO          `protobuf:"bytes,6,opt,name=sso,proto3" json:"sso,omitempty"`
+	Provisioning  *OrganizationProvisioning `protobuf:"bytes,7,opt,name=provisioning,proto3" json:"provisioning,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Organization) Reset() {
+	*x = Organization{}
+	mi := &file_proto_user_proto_msgTypes[126]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Organization) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Organization) ProtoMessage() {}
+
+func (x *Organization) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[126]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Organization.ProtoReflect.Descriptor instead.
+func (*Organization) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{126}
+}
+
+func (x *Organization) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Organization) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Organization) GetPolicy() *OrganizationPolicy {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+func (x *Organization) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Organization) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Organization) GetSso() *OrganizationSSO {
+	if x != nil {
+		return x.Sso
+	}
+	return nil
+}
+
+func (x *Organization) GetProvisioning() *OrganizationProvisioning {
+	if x != nil {
+		return x.Provisioning
+	}
+	return nil
+}
+
+type ProfileChangeRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Requested values; empty when that field is unchanged
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Email         string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	RejectReason  string                 `protobuf:"bytes,6,opt,name=reject_reason,json=rejectReason,proto3" json:"reject_reason,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ReviewedAt    *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=reviewed_at,json=reviewedAt,proto3" json:"reviewed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProfileChangeRequest) Reset() {
+	*x = ProfileChangeRequest{}
+	mi := &file_proto_user_proto_msgTypes[127]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProfileChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileChangeRequest) ProtoMessage() {}
+
+func (x *ProfileChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[127]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileChangeRequest.ProtoReflect.Descriptor instead.
+func (*ProfileChangeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{127}
+}
+
+func (x *ProfileChangeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ProfileChangeRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ProfileChangeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProfileChangeRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ProfileChangeRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ProfileChangeRequest) GetRejectReason() string {
+	if x != nil {
+		return x.RejectReason
+	}
+	return ""
+}
+
+func (x *ProfileChangeRequest) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ProfileChangeRequest) GetReviewedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReviewedAt
+	}
+	return nil
+}
+
+type ListProfileChangeRequestsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Page     int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Defaults to pending
+	Status        string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProfileChangeRequestsRequest) Reset() {
+	*x = ListProfileChangeRequestsRequest{}
+	mi := &file_proto_user_proto_msgTypes[128]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProfileChangeRequestsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProfileChangeRequestsRequest) ProtoMessage() {}
+
+func (x *ListProfileChangeRequestsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[128]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProfileChangeRequestsRequest.ProtoReflect.Descriptor instead.
+func (*ListProfileChangeRequestsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{128}
+}
+
+func (x *ListProfileChangeRequestsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListProfileChangeRequestsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListProfileChangeRequestsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListProfileChangeRequestsResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Requests      []*ProfileChangeRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	TotalCount    int32                   `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Page          int32                   `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                   `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProfileChangeRequestsResponse) Reset() {
+	*x = ListProfileChangeRequestsResponse{}
+	mi := &file_proto_user_proto_msgTypes[129]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProfileChangeRequestsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProfileChangeRequestsResponse) ProtoMessage() {}
+
+func (x *ListProfileChangeRequestsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[129]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProfileChangeRequestsResponse.ProtoReflect.Descriptor instead.
+func (*ListProfileChangeRequestsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{129}
+}
+
+func (x *ListProfileChangeRequestsResponse) GetRequests() []*ProfileChangeRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+func (x *ListProfileChangeRequestsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *ListProfileChangeRequestsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListProfileChangeRequestsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ApproveProfileChangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveProfileChangeRequest) Reset() {
+	*x = ApproveProfileChangeRequest{}
+	mi := &file_proto_user_proto_msgTypes[130]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveProfileChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveProfileChangeRequest) ProtoMessage() {}
+
+func (x *ApproveProfileChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[130]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveProfileChangeRequest.ProtoReflect.Descriptor instead.
+func (*ApproveProfileChangeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{130}
+}
+
+func (x *ApproveProfileChangeRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type ApproveProfileChangeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveProfileChangeResponse) Reset() {
+	*x = ApproveProfileChangeResponse{}
+	mi := &file_proto_user_proto_msgTypes[131]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveProfileChangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveProfileChangeResponse) ProtoMessage() {}
+
+func (x *ApproveProfileChangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[131]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveProfileChangeResponse.ProtoReflect.Descriptor instead.
+func (*ApproveProfileChangeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{131}
+}
+
+func (x *ApproveProfileChangeResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *ApproveProfileChangeResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type RejectProfileChangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectProfileChangeRequest) Reset() {
+	*x = RejectProfileChangeRequest{}
+	mi := &file_proto_user_proto_msgTypes[132]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectProfileChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectProfileChangeRequest) ProtoMessage() {}
+
+func (x *RejectProfileChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[132]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectProfileChangeRequest.ProtoReflect.Descriptor instead.
+func (*RejectProfileChangeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{132}
+}
+
+func (x *RejectProfileChangeRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *RejectProfileChangeRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type RejectProfileChangeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectProfileChangeResponse) Reset() {
+	*x = RejectProfileChangeResponse{}
+	mi := &file_proto_user_proto_msgTypes[133]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectProfileChangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectProfileChangeResponse) ProtoMessage() {}
+
+func (x *RejectProfileChangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[133]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectProfileChangeResponse.ProtoReflect.Descriptor instead.
+func (*RejectProfileChangeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{133}
+}
+
+func (x *RejectProfileChangeResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Admin messages
+type NotificationTemplate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Subject       string                 `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	Body          string                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	SampleData    map[string]string      `protobuf:"bytes,4,rep,name=sample_data,json=sampleData,proto3" json:"sample_data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotificationTemplate) Reset() {
+	*x = NotificationTemplate{}
+	mi := &file_proto_user_proto_msgTypes[134]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationTemplate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationTemplate) ProtoMessage() {}
+
+func (x *NotificationTemplate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[134]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationTemplate.ProtoReflect.Descriptor instead.
+func (*NotificationTemplate) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{134}
+}
+
+func (x *NotificationTemplate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NotificationTemplate) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *NotificationTemplate) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *NotificationTemplate) GetSampleData() map[string]string {
+	if x != nil {
+		return x.SampleData
+	}
+	return nil
+}
+
+type ListNotificationTemplatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNotificationTemplatesRequest) Reset() {
+	*x = ListNotificationTemplatesRequest{}
+	mi := &file_proto_user_proto_msgTypes[135]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNotificationTemplatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNotificationTemplatesRequest) ProtoMessage() {}
+
+func (x *ListNotificationTemplatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[135]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNotificationTemplatesRequest.ProtoReflect.Descriptor instead.
+func (*ListNotificationTemplatesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{135}
+}
+
+type ListNotificationTemplatesResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Templates     []*NotificationTemplate `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNotificationTemplatesResponse) Reset() {
+	*x = ListNotificationTemplatesResponse{}
+	mi := &file_proto_user_proto_msgTypes[136]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNotificationTemplatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNotificationTemplatesResponse) ProtoMessage() {}
+
+func (x *ListNotificationTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[136]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNotificationTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*ListNotificationTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{136}
+}
+
+func (x *ListNotificationTemplatesResponse) GetTemplates() []*NotificationTemplate {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+type PreviewNotificationTemplateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Overrides for the template's sample data
+	Data          map[string]string `protobuf:"bytes,2,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PreviewNotificationTemplateRequest) Reset() {
+	*x = PreviewNotificationTemplateRequest{}
+	mi := &file_proto_user_proto_msgTypes[137]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreviewNotificationTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreviewNotificationTemplateRequest) ProtoMessage() {}
+
+func (x *PreviewNotificationTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[137]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreviewNotificationTemplateRequest.ProtoReflect.Descriptor instead.
+func (*PreviewNotificationTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{137}
+}
+
+func (x *PreviewNotificationTemplateRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PreviewNotificationTemplateRequest) GetData() map[string]string {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type PreviewNotificationTemplateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subject       string                 `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Body          string                 `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PreviewNotificationTemplateResponse) Reset() {
+	*x = PreviewNotificationTemplateResponse{}
+	mi := &file_proto_user_proto_msgTypes[138]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreviewNotificationTemplateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreviewNotificationTemplateResponse) ProtoMessage() {}
+
+func (x *PreviewNotificationTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[138]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreviewNotificationTemplateResponse.ProtoReflect.Descriptor instead.
+func (*PreviewNotificationTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{138}
+}
+
+func (x *PreviewNotificationTemplateResponse) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *PreviewNotificationTemplateResponse) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type SendTestNotificationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Data          map[string]string      `protobuf:"bytes,3,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendTestNotificationRequest) Reset() {
+	*x = SendTestNotificationRequest{}
+	mi := &file_proto_user_proto_msgTypes[139]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendTestNotificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendTestNotificationRequest) ProtoMessage() {}
+
+func (x *SendTestNotificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[139]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendTestNotificationRequest.ProtoReflect.Descriptor instead.
+func (*SendTestNotificationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{139}
+}
+
+func (x *SendTestNotificationRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SendTestNotificationRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *SendTestNotificationRequest) GetData() map[string]string {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type SendTestNotificationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendTestNotificationResponse) Reset() {
+	*x = SendTestNotificationResponse{}
+	mi := &file_proto_user_proto_msgTypes[140]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendTestNotificationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendTestNotificationResponse) ProtoMessage() {}
+
+func (x *SendTestNotificationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[140]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendTestNotificationResponse.ProtoReflect.Descriptor instead.
+func (*SendTestNotificationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{140}
+}
+
+func (x *SendTestNotificationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type DeadLetterEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type  string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// Event payload as relaxed extended JSON
+	Payload        string                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	Attempts       int32                  `protobuf:"varint,4,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LastError      string                 `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	DeadLetteredAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=dead_lettered_at,json=deadLetteredAt,proto3" json:"dead_lettered_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeadLetterEvent) Reset() {
+	*x = DeadLetterEvent{}
+	mi := &file_proto_user_proto_msgTypes[141]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadLetterEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadLetterEvent) ProtoMessage() {}
+
+func (x *DeadLetterEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[141]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadLetterEvent.ProtoReflect.Descriptor instead.
+func (*DeadLetterEvent) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{141}
+}
+
+func (x *DeadLetterEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeadLetterEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DeadLetterEvent) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *DeadLetterEvent) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *DeadLetterEvent) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *DeadLetterEvent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *DeadLetterEvent) GetDeadLetteredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeadLetteredAt
+	}
+	return nil
+}
+
+type ListDeadLetterEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeadLetterEventsRequest) Reset() {
+	*x = ListDeadLetterEventsRequest{}
+	mi := &file_proto_user_proto_msgTypes[142]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeadLetterEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLetterEventsRequest) ProtoMessage() {}
+
+func (x *ListDeadLetterEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[142]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLetterEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListDeadLetterEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{142}
+}
+
+func (x *ListDeadLetterEventsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListDeadLetterEventsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListDeadLetterEventsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type ListDeadLetterEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*DeadLetterEvent     `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeadLetterEventsResponse) Reset() {
+	*x = ListDeadLetterEventsResponse{}
+	mi := &file_proto_user_proto_msgTypes[143]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeadLetterEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLetterEventsResponse) ProtoMessage() {}
+
+func (x *ListDeadLetterEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[143]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLetterEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListDeadLetterEventsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{143}
+}
+
+func (x *ListDeadLetterEventsResponse) GetEvents() []*DeadLetterEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *ListDeadLetterEventsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *ListDeadLetterEventsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListDeadLetterEventsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ReplayDeadLetterEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Ids   []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	// Replay every dead-lettered event instead of the listed IDs
+	All           bool `protobuf:"varint,2,opt,name=all,proto3" json:"all,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayDeadLetterEventsRequest) Reset() {
+	*x = ReplayDeadLetterEventsRequest{}
+	mi := &file_proto_user_proto_msgTypes[144]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayDeadLetterEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayDeadLetterEventsRequest) ProtoMessage() {}
+
+func (x *ReplayDeadLetterEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[144]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayDeadLetterEventsRequest.ProtoReflect.Descriptor instead.
+func (*ReplayDeadLetterEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{144}
+}
+
+func (x *ReplayDeadLetterEventsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *ReplayDeadLetterEventsRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+type ReplayDeadLetterEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReplayedCount int32                  `protobuf:"varint,1,opt,name=replayed_count,json=replayedCount,proto3" json:"replayed_count,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayDeadLetterEventsResponse) Reset() {
+	*x = ReplayDeadLetterEventsResponse{}
+	mi := &file_proto_user_proto_msgTypes[145]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayDeadLetterEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayDeadLetterEventsResponse) ProtoMessage() {}
+
+func (x *ReplayDeadLetterEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[145]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayDeadLetterEventsResponse.ProtoReflect.Descriptor instead.
+func (*ReplayDeadLetterEventsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{145}
+}
+
+func (x *ReplayDeadLetterEventsResponse) GetReplayedCount() int32 {
+	if x != nil {
+		return x.ReplayedCount
+	}
+	return 0
+}
+
+func (x *ReplayDeadLetterEventsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ExportConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportConfigRequest) Reset() {
+	*x = ExportConfigRequest{}
+	mi := &file_proto_user_proto_msgTypes[146]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportConfigRequest) ProtoMessage() {}
+
+func (x *ExportConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[146]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportConfigRequest.ProtoReflect.Descriptor instead.
+func (*ExportConfigRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{146}
+}
+
+type ExportConfigResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Signed JSON snapshot of the effective runtime settings
+	Snapshot      string `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportConfigResponse) Reset() {
+	*x = ExportConfigResponse{}
+	mi := &file_proto_user_proto_msgTypes[147]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportConfigResponse) ProtoMessage() {}
+
+func (x *ExportConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[147]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportConfigResponse.ProtoReflect.Descriptor instead.
+func (*ExportConfigResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{147}
+}
+
+func (x *ExportConfigResponse) GetSnapshot() string {
+	if x != nil {
+		return x.Snapshot
+	}
+	return ""
+}
+
+type ImportConfigRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Snapshot string                 `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	// Validate the snapshot and report changes without saving it
+	DryRun        bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportConfigRequest) Reset() {
+	*x = ImportConfigRequest{}
+	mi := &file_proto_user_proto_msgTypes[148]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportConfigRequest) ProtoMessage() {}
+
+func (x *ImportConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[148]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportConfigRequest.ProtoReflect.Descriptor instead.
+func (*ImportConfigRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{148}
+}
+
+func (x *ImportConfigRequest) GetSnapshot() string {
+	if x != nil {
+		return x.Snapshot
+	}
+	return ""
+}
+
+func (x *ImportConfigRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type ImportConfigResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Message           string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	SourceEnvironment string                 `protobuf:"bytes,2,opt,name=source_environment,json=sourceEnvironment,proto3" json:"source_environment,omitempty"`
+	// Settings, by JSON path, that differ from the running configuration
+	ChangedSettings []string `protobuf:"bytes,3,rep,name=changed_settings,json=changedSettings,proto3" json:"changed_settings,omitempty"`
+	RestartRequired bool     `protobuf:"varint,4,opt,name=restart_required,json=restartRequired,proto3" json:"restart_required,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ImportConfigResponse) Reset() {
+	*x = ImportConfigResponse{}
+	mi := &file_proto_user_proto_msgTypes[149]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportConfigResponse) ProtoMessage() {}
+
+func (x *ImportConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[149]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportConfigResponse.ProtoReflect.Descriptor instead.
+func (*ImportConfigResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{149}
+}
+
+func (x *ImportConfigResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ImportConfigResponse) GetSourceEnvironment() string {
+	if x != nil {
+		return x.SourceEnvironment
+	}
+	return ""
+}
+
+func (x *ImportConfigResponse) GetChangedSettings() []string {
+	if x != nil {
+		return x.ChangedSettings
+	}
+	return nil
+}
+
+func (x *ImportConfigResponse) GetRestartRequired() bool {
+	if x != nil {
+		return x.RestartRequired
+	}
+	return false
+}
+
+type LockUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockUserRequest) Reset() {
+	*x = LockUserRequest{}
+	mi := &file_proto_user_proto_msgTypes[150]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockUserRequest) ProtoMessage() {}
+
+func (x *LockUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[150]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockUserRequest.ProtoReflect.Descriptor instead.
+func (*LockUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{150}
+}
+
+func (x *LockUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *LockUserRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type LockUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockUserResponse) Reset() {
+	*x = LockUserResponse{}
+	mi := &file_proto_user_proto_msgTypes[151]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockUserResponse) ProtoMessage() {}
+
+func (x *LockUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[151]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockUserResponse.ProtoReflect.Descriptor instead.
+func (*LockUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{151}
+}
+
+func (x *LockUserResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type UnlockUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockUserRequest) Reset() {
+	*x = UnlockUserRequest{}
+	mi := &file_proto_user_proto_msgTypes[152]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockUserRequest) ProtoMessage() {}
+
+func (x *UnlockUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[152]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockUserRequest.ProtoReflect.Descriptor instead.
+func (*UnlockUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{152}
+}
+
+func (x *UnlockUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type UnlockUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockUserResponse) Reset() {
+	*x = UnlockUserResponse{}
+	mi := &file_proto_user_proto_msgTypes[153]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockUserResponse) ProtoMessage() {}
+
+func (x *UnlockUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[153]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockUserResponse.ProtoReflect.Descriptor instead.
+func (*UnlockUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{153}
+}
+
+func (x *UnlockUserResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type DeactivateUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeactivateUserRequest) Reset() {
+	*x = DeactivateUserRequest{}
+	mi := &file_proto_user_proto_msgTypes[154]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeactivateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeactivateUserRequest) ProtoMessage() {}
+
+func (x *DeactivateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[154]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeactivateUserRequest.ProtoReflect.Descriptor instead.
+func (*DeactivateUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{154}
+}
+
+func (x *DeactivateUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DeactivateUserRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type DeactivateUserResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Sessions of the user that were signed out
+	SessionsRevoked int32  `protobuf:"varint,1,opt,name=sessions_revoked,json=sessionsRevoked,proto3" json:"sessions_revoked,omitempty"`
+	Message         string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DeactivateUserResponse) Reset() {
+	*x = DeactivateUserResponse{}
+	mi := &file_proto_user_proto_msgTypes[155]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeactivateUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeactivateUserResponse) ProtoMessage() {}
+
+func (x *DeactivateUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[155]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeactivateUserResponse.ProtoReflect.Descriptor instead.
+func (*DeactivateUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{155}
+}
+
+func (x *DeactivateUserResponse) GetSessionsRevoked() int32 {
+	if x != nil {
+		return x.SessionsRevoked
+	}
+	return 0
+}
+
+func (x *DeactivateUserResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ReactivateUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReactivateUserRequest) Reset() {
+	*x = ReactivateUserRequest{}
+	mi := &file_proto_user_proto_msgTypes[156]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReactivateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReactivateUserRequest) ProtoMessage() {}
+
+func (x *ReactivateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[156]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReactivateUserRequest.ProtoReflect.Descriptor instead.
+func (*ReactivateUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{156}
+}
+
+func (x *ReactivateUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ReactivateUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReactivateUserResponse) Reset() {
+	*x = ReactivateUserResponse{}
+	mi := &file_proto_user_proto_msgTypes[157]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReactivateUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReactivateUserResponse) ProtoMessage() {}
+
+func (x *ReactivateUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[157]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReactivateUserResponse.ProtoReflect.Descriptor instead.
+func (*ReactivateUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{157}
+}
+
+func (x *ReactivateUserResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type HardDeleteUserRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Must be set: the account and its history can't be recovered
+	Confirm bool `protobuf:"varint,2,opt,name=confirm,proto3" json:"confirm,omitempty"`
+	// Report what would be removed without removing anything. confirm is
+	// not needed.
+	DryRun        bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HardDeleteUserRequest) Reset() {
+	*x = HardDeleteUserRequest{}
+	mi := &file_proto_user_proto_msgTypes[158]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HardDeleteUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HardDeleteUserRequest) ProtoMessage() {}
+
+func (x *HardDeleteUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[158]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HardDeleteUserRequest.ProtoReflect.Descriptor instead.
+func (*HardDeleteUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{158}
+}
+
+func (x *HardDeleteUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *HardDeleteUserRequest) GetConfirm() bool {
+	if x != nil {
+		return x.Confirm
+	}
+	return false
+}
+
+func (x *HardDeleteUserRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type HardDeleteUserResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// What the call would change, only set on dry runs
+	Affected []*AffectedRecords `protobuf:"bytes,2,rep,name=affected,proto3" json:"affected,omitempty"`
+	// Set when the delete waits out the undo window instead of running
+	Operation     *PendingOperation `protobuf:"bytes,3,opt,name=operation,proto3" json:"operation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HardDeleteUserResponse) Reset() {
+	*x = HardDeleteUserResponse{}
+	mi := &file_proto_user_proto_msgTypes[159]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HardDeleteUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HardDeleteUserResponse) ProtoMessage() {}
+
+func (x *HardDeleteUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[159]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HardDeleteUserResponse.ProtoReflect.Descriptor instead.
+func (*HardDeleteUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{159}
+}
+
+func (x *HardDeleteUserResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *HardDeleteUserResponse) GetAffected() []*AffectedRecords {
+	if x != nil {
+		return x.Affected
+	}
+	return nil
+}
+
+func (x *HardDeleteUserResponse) GetOperation() *PendingOperation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+// AffectedRecords are the documents of one collection a destructive call
+// changes
+type AffectedRecords struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Collection string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	// "delete" or "update"
+	Operation string `protobuf:"bytes,2,opt,name=operation,proto3" json:"operation,omitempty"`
+	Count     int64  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	// IDs of up to 10 of the documents
+	SampleIds     []string `protobuf:"bytes,4,rep,name=sample_ids,json=sampleIds,proto3" json:"sample_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AffectedRecords) Reset() {
+	*x = AffectedRecords{}
+	mi := &file_proto_user_proto_msgTypes[160]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AffectedRecords) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AffectedRecords) ProtoMessage() {}
+
+func (x *AffectedRecords) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[160]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AffectedRecords.ProtoReflect.Descriptor instead.
+func (*AffectedRecords) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{160}
+}
+
+func (x *AffectedRecords) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *AffectedRecords) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *AffectedRecords) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *AffectedRecords) GetSampleIds() []string {
+	if x != nil {
+		return x.SampleIds
+	}
+	return nil
+}
+
+// PendingOperation is a destructive admin action held for the undo window.
+// It can be canceled until run_at.
+type PendingOperation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// "hard_delete" or "deprovision"
+	Kind string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Account a hard delete removes
+	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Organization a deprovisioning is for
+	OrgId string `protobuf:"bytes,4,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	// Admin or service account that requested the operation
+	RequestedBy string `protobuf:"bytes,5,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	// "pending", "done", "failed" or "canceled"
+	Status    string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// When the undo window ends and the operation runs
+	RunAt       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=run_at,json=runAt,proto3" json:"run_at,omitempty"`
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	// Deprovisioning job the operation queued, once done
+	JobId string `protobuf:"bytes,10,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Why a failed operation couldn't run
+	Error         string `protobuf:"bytes,11,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PendingOperation) Reset() {
+	*x = PendingOperation{}
+	mi := &file_proto_user_proto_msgTypes[161]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PendingOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingOperation) ProtoMessage() {}
+
+func (x *PendingOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[161]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingOperation.ProtoReflect.Descriptor instead.
+func (*PendingOperation) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{161}
+}
+
+func (x *PendingOperation) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PendingOperation) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *PendingOperation) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *PendingOperation) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *PendingOperation) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+func (x *PendingOperation) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PendingOperation) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *PendingOperation) GetRunAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RunAt
+	}
+	return nil
+}
+
+func (x *PendingOperation) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *PendingOperation) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *PendingOperation) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CancelOperationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOperationRequest) Reset() {
+	*x = CancelOperationRequest{}
+	mi := &file_proto_user_proto_msgTypes[162]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOperationRequest) ProtoMessage() {}
+
+func (x *CancelOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[162]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOperationRequest.ProtoReflect.Descriptor instead.
+func (*CancelOperationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{162}
+}
+
+func (x *CancelOperationRequest) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+type CancelOperationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Operation     *PendingOperation      `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOperationResponse) Reset() {
+	*x = CancelOperationResponse{}
+	mi := &file_proto_user_proto_msgTypes[163]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOperationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOperationResponse) ProtoMessage() {}
+
+func (x *CancelOperationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[163]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOperationResponse.ProtoReflect.Descriptor instead.
+func (*CancelOperationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{163}
+}
+
+func (x *CancelOperationResponse) GetOperation() *PendingOperation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+func (x *CancelOperationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Scheduled actions on accounts
+type ScheduledAction struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// "deactivate" or "delete"
+	Action string `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Reason string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Admin or service account that scheduled the action
+	RequestedBy string `protobuf:"bytes,5,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	// "scheduled", "done", "failed" or "canceled"
+	Status string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	RunAt  *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=run_at,json=runAt,proto3" json:"run_at,omitempty"`
+	// When the user is emailed notice of the action, unset when no notice
+	// is sent
+	NotifyAt    *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=notify_at,json=notifyAt,proto3" json:"notify_at,omitempty"`
+	NotifiedAt  *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=notified_at,json=notifiedAt,proto3" json:"notified_at,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	// Why a failed action couldn't run
+	Error         string `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduledAction) Reset() {
+	*x = ScheduledAction{}
+	mi := &file_proto_user_proto_msgTypes[164]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduledAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduledAction) ProtoMessage() {}
+
+func (x *ScheduledAction) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[164]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduledAction.ProtoReflect.Descriptor instead.
+func (*ScheduledAction) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{164}
+}
+
+func (x *ScheduledAction) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ScheduledAction) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ScheduledAction) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ScheduledAction) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ScheduledAction) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+func (x *ScheduledAction) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ScheduledAction) GetRunAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RunAt
+	}
+	return nil
+}
+
+func (x *ScheduledAction) GetNotifyAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotifyAt
+	}
+	return nil
+}
+
+func (x *ScheduledAction) GetNotifiedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotifiedAt
+	}
+	return nil
+}
+
+func (x *ScheduledAction) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ScheduledAction) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *ScheduledAction) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ScheduleActionRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// "deactivate" or "delete"
+	Action string `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	// When the action runs, in the future
+	RunAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=run_at,json=runAt,proto3" json:"run_at,omitempty"`
+	// Recorded with the action and shown to the user in the notice
+	Reason        string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleActionRequest) Reset() {
+	*x = ScheduleActionRequest{}
+	mi := &file_proto_user_proto_msgTypes[165]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleActionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleActionRequest) ProtoMessage() {}
+
+func (x *ScheduleActionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[165]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleActionRequest.ProtoReflect.Descriptor instead.
+func (*ScheduleActionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{165}
+}
+
+func (x *ScheduleActionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ScheduleActionRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ScheduleActionRequest) GetRunAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RunAt
+	}
+	return nil
+}
+
+func (x *ScheduleActionRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ScheduleActionResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ScheduledAction *ScheduledAction       `protobuf:"bytes,1,opt,name=scheduled_action,json=scheduledAction,proto3" json:"scheduled_action,omitempty"`
+	Message         string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ScheduleActionResponse) Reset() {
+	*x = ScheduleActionResponse{}
+	mi := &file_proto_user_proto_msgTypes[166]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleActionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleActionResponse) ProtoMessage() {}
+
+func (x *ScheduleActionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[166]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleActionResponse.ProtoReflect.Descriptor instead.
+func (*ScheduleActionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{166}
+}
+
+func (x *ScheduleActionResponse) GetScheduledAction() *ScheduledAction {
+	if x != nil {
+		return x.ScheduledAction
+	}
+	return nil
+}
+
+func (x *ScheduleActionResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type CancelScheduledActionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActionId      string                 `protobuf:"bytes,1,opt,name=action_id,json=actionId,proto3" json:"action_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelScheduledActionRequest) Reset() {
+	*x = CancelScheduledActionRequest{}
+	mi := &file_proto_user_proto_msgTypes[167]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelScheduledActionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelScheduledActionRequest) ProtoMessage() {}
+
+func (x *CancelScheduledActionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[167]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelScheduledActionRequest.ProtoReflect.Descriptor instead.
+func (*CancelScheduledActionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{167}
+}
+
+func (x *CancelScheduledActionRequest) GetActionId() string {
+	if x != nil {
+		return x.ActionId
+	}
+	return ""
+}
+
+type CancelScheduledActionResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ScheduledAction *ScheduledAction       `protobuf:"bytes,1,opt,name=scheduled_action,json=scheduledAction,proto3" json:"scheduled_action,omitempty"`
+	Message         string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CancelScheduledActionResponse) Reset() {
+	*x = CancelScheduledActionResponse{}
+	mi := &file_proto_user_proto_msgTypes[168]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelScheduledActionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelScheduledActionResponse) ProtoMessage() {}
+
+func (x *CancelScheduledActionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[168]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelScheduledActionResponse.ProtoReflect.Descriptor instead.
+func (*CancelScheduledActionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{168}
+}
+
+func (x *CancelScheduledActionResponse) GetScheduledAction() *ScheduledAction {
+	if x != nil {
+		return x.ScheduledAction
+	}
+	return nil
+}
+
+func (x *CancelScheduledActionResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ListScheduledActionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only the actions on this user when set
+	UserId string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Only actions with this status when set
+	Status        string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Page          int32  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScheduledActionsRequest) Reset() {
+	*x = ListScheduledActionsRequest{}
+	mi := &file_proto_user_proto_msgTypes[169]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScheduledActionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScheduledActionsRequest) ProtoMessage() {}
+
+func (x *ListScheduledActionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[169]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScheduledActionsRequest.ProtoReflect.Descriptor instead.
+func (*ListScheduledActionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{169}
+}
+
+func (x *ListScheduledActionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListScheduledActionsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListScheduledActionsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListScheduledActionsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListScheduledActionsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Soonest first
+	ScheduledActions []*ScheduledAction `protobuf:"bytes,1,rep,name=scheduled_actions,json=scheduledActions,proto3" json:"scheduled_actions,omitempty"`
+	TotalCount       int32              `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Page             int32              `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize         int32              `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListScheduledActionsResponse) Reset() {
+	*x = ListScheduledActionsResponse{}
+	mi := &file_proto_user_proto_msgTypes[170]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScheduledActionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScheduledActionsResponse) ProtoMessage() {}
+
+func (x *ListScheduledActionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[170]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScheduledActionsResponse.ProtoReflect.Descriptor instead.
+func (*ListScheduledActionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{170}
+}
+
+func (x *ListScheduledActionsResponse) GetScheduledActions() []*ScheduledAction {
+	if x != nil {
+		return x.ScheduledActions
+	}
+	return nil
+}
+
+func (x *ListScheduledActionsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *ListScheduledActionsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListScheduledActionsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+// Multi-step flows, such as account erasures, that resume where they
+// stopped after a failure
+type Workflow struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// "erasure" or "account_recovery"
+	Kind   string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Admin who started the flow, unset for flows the user started
+	RequestedBy string `protobuf:"bytes,4,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	// "running", "done" or "failed"
+	Status string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	// Steps run so far, in order
+	CompletedSteps []string `protobuf:"bytes,6,rep,name=completed_steps,json=completedSteps,proto3" json:"completed_steps,omitempty"`
+	// The step running or to be retried, unset once done
+	CurrentStep string `protobuf:"bytes,7,opt,name=current_step,json=currentStep,proto3" json:"current_step,omitempty"`
+	// Failed runs of the current step
+	Attempts int32 `protobuf:"varint,8,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// Why the last run of the current step failed
+	Error         string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Workflow) Reset() {
+	*x = Workflow{}
+	mi := &file_proto_user_proto_msgTypes[171]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Workflow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Workflow) ProtoMessage() {}
+
+func (x *Workflow) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[171]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Workflow.ProtoReflect.Descriptor instead.
+func (*Workflow) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{171}
+}
+
+func (x *Workflow) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Workflow) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Workflow) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Workflow) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+func (x *Workflow) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Workflow) GetCompletedSteps() []string {
+	if x != nil {
+		return x.CompletedSteps
+	}
+	return nil
+}
+
+func (x *Workflow) GetCurrentStep() string {
+	if x != nil {
+		return x.CurrentStep
+	}
+	return ""
+}
+
+func (x *Workflow) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Workflow) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Workflow) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Workflow) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Workflow) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+type EraseUserRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Must be set: the account and its history can't be recovered
+	Confirm       bool `protobuf:"varint,2,opt,name=confirm,proto3" json:"confirm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EraseUserRequest) Reset() {
+	*x = EraseUserRequest{}
+	mi := &file_proto_user_proto_msgTypes[172]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserRequest) ProtoMessage() {}
+
+func (x *EraseUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[172]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserRequest.ProtoReflect.Descriptor instead.
+func (*EraseUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{172}
+}
+
+func (x *EraseUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *EraseUserRequest) GetConfirm() bool {
+	if x != nil {
+		return x.Confirm
+	}
+	return false
+}
+
+type EraseUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Workflow      *Workflow              `protobuf:"bytes,1,opt,name=workflow,proto3" json:"workflow,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EraseUserResponse) Reset() {
+	*x = EraseUserResponse{}
+	mi := &file_proto_user_proto_msgTypes[173]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserResponse) ProtoMessage() {}
+
+func (x *EraseUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[173]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserResponse.ProtoReflect.Descriptor instead.
+func (*EraseUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{173}
+}
+
+func (x *EraseUserResponse) GetWorkflow() *Workflow {
+	if x != nil {
+		return x.Workflow
+	}
+	return nil
+}
+
+func (x *EraseUserResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetWorkflowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkflowId    string                 `protobuf:"bytes,1,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWorkflowRequest) Reset() {
+	*x = GetWorkflowRequest{}
+	mi := &file_proto_user_proto_msgTypes[174]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWorkflowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWorkflowRequest) ProtoMessage() {}
+
+func (x *GetWorkflowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[174]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWorkflowRequest.ProtoReflect.Descriptor instead.
+func (*GetWorkflowRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{174}
+}
+
+func (x *GetWorkflowRequest) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+type GetWorkflowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Workflow      *Workflow              `protobuf:"bytes,1,opt,name=workflow,proto3" json:"workflow,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWorkflowResponse) Reset() {
+	*x = GetWorkflowResponse{}
+	mi := &file_proto_user_proto_msgTypes[175]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWorkflowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWorkflowResponse) ProtoMessage() {}
+
+func (x *GetWorkflowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[175]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWorkflowResponse.ProtoReflect.Descriptor instead.
+func (*GetWorkflowResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{175}
+}
+
+func (x *GetWorkflowResponse) GetWorkflow() *Workflow {
+	if x != nil {
+		return x.Workflow
+	}
+	return nil
+}
+
+type ListWorkflowsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only flows of this kind when set
+	Kind string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Only flows with this status when set
+	Status string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// Only flows about this user when set
+	UserId        string `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Page          int32  `protobuf:"varint,4,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32  `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWorkflowsRequest) Reset() {
+	*x = ListWorkflowsRequest{}
+	mi := &file_proto_user_proto_msgTypes[176]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWorkflowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWorkflowsRequest) ProtoMessage() {}
+
+func (x *ListWorkflowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[176]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWorkflowsRequest.ProtoReflect.Descriptor instead.
+func (*ListWorkflowsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{176}
+}
+
+func (x *ListWorkflowsRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ListWorkflowsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListWorkflowsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListWorkflowsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListWorkflowsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListWorkflowsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first
+	Workflows     []*Workflow `protobuf:"bytes,1,rep,name=workflows,proto3" json:"workflows,omitempty"`
+	TotalCount    int32       `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Page          int32       `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32       `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWorkflowsResponse) Reset() {
+	*x = ListWorkflowsResponse{}
+	mi := &file_proto_user_proto_msgTypes[177]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWorkflowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWorkflowsResponse) ProtoMessage() {}
+
+func (x *ListWorkflowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[177]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWorkflowsResponse.ProtoReflect.Descriptor instead.
+func (*ListWorkflowsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{177}
+}
+
+func (x *ListWorkflowsResponse) GetWorkflows() []*Workflow {
+	if x != nil {
+		return x.Workflows
+	}
+	return nil
+}
+
+func (x *ListWorkflowsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *ListWorkflowsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListWorkflowsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type RetryWorkflowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkflowId    string                 `protobuf:"bytes,1,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryWorkflowRequest) Reset() {
+	*x = RetryWorkflowRequest{}
+	mi := &file_proto_user_proto_msgTypes[178]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryWorkflowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryWorkflowRequest) ProtoMessage() {}
+
+func (x *RetryWorkflowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[178]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryWorkflowRequest.ProtoReflect.Descriptor instead.
+func (*RetryWorkflowRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{178}
+}
+
+func (x *RetryWorkflowRequest) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+type RetryWorkflowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Workflow      *Workflow              `protobuf:"bytes,1,opt,name=workflow,proto3" json:"workflow,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryWorkflowResponse) Reset() {
+	*x = RetryWorkflowResponse{}
+	mi := &file_proto_user_proto_msgTypes[179]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryWorkflowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryWorkflowResponse) ProtoMessage() {}
+
+func (x *RetryWorkflowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[179]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryWorkflowResponse.ProtoReflect.Descriptor instead.
+func (*RetryWorkflowResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{179}
+}
+
+func (x *RetryWorkflowResponse) GetWorkflow() *Workflow {
+	if x != nil {
+		return x.Workflow
+	}
+	return nil
+}
+
+func (x *RetryWorkflowResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ResetUserPasswordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetUserPasswordRequest) Reset() {
+	*x = ResetUserPasswordRequest{}
+	mi := &file_proto_user_proto_msgTypes[180]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetUserPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetUserPasswordRequest) ProtoMessage() {}
+
+func (x *ResetUserPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[180]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetUserPasswordRequest.ProtoReflect.Descriptor instead.
+func (*ResetUserPasswordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{180}
+}
+
+func (x *ResetUserPasswordRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ResetUserPasswordResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// When the emailed reset link stops working
+	ExpiresAt       *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	SessionsRevoked int32                  `protobuf:"varint,2,opt,name=sessions_revoked,json=sessionsRevoked,proto3" json:"sessions_revoked,omitempty"`
+	Message         string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ResetUserPasswordResponse) Reset() {
+	*x = ResetUserPasswordResponse{}
+	mi := &file_proto_user_proto_msgTypes[181]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetUserPasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetUserPasswordResponse) ProtoMessage() {}
+
+func (x *ResetUserPasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[181]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetUserPasswordResponse.ProtoReflect.Descriptor instead.
+func (*ResetUserPasswordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{181}
+}
+
+func (x *ResetUserPasswordResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *ResetUserPasswordResponse) GetSessionsRevoked() int32 {
+	if x != nil {
+		return x.SessionsRevoked
+	}
+	return 0
+}
+
+func (x *ResetUserPasswordResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetLoginHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLoginHistoryRequest) Reset() {
+	*x = GetLoginHistoryRequest{}
+	mi := &file_proto_user_proto_msgTypes[182]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLoginHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLoginHistoryRequest) ProtoMessage() {}
+
+func (x *GetLoginHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[182]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLoginHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetLoginHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{182}
+}
+
+func (x *GetLoginHistoryRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type LoginRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IpAddress     string                 `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	UserAgent     string                 `protobuf:"bytes,2,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginRecord) Reset() {
+	*x = LoginRecord{}
+	mi := &file_proto_user_proto_msgTypes[183]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRecord) ProtoMessage() {}
+
+func (x *LoginRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[183]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRecord.ProtoReflect.Descriptor instead.
+func (*LoginRecord) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{183}
+}
+
+func (x *LoginRecord) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *LoginRecord) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *LoginRecord) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+type GetLoginHistoryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Most recent first, at most 20
+	Logins        []*LoginRecord         `protobuf:"bytes,1,rep,name=logins,proto3" json:"logins,omitempty"`
+	LoginCount    int64                  `protobuf:"varint,2,opt,name=login_count,json=loginCount,proto3" json:"login_count,omitempty"`
+	LastLoginAt   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_login_at,json=lastLoginAt,proto3" json:"last_login_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLoginHistoryResponse) Reset() {
+	*x = GetLoginHistoryResponse{}
+	mi := &file_proto_user_proto_msgTypes[184]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLoginHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLoginHistoryResponse) ProtoMessage() {}
+
+func (x *GetLoginHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[184]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLoginHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetLoginHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{184}
+}
+
+func (x *GetLoginHistoryResponse) GetLogins() []*LoginRecord {
+	if x != nil {
+		return x.Logins
+	}
+	return nil
+}
+
+func (x *GetLoginHistoryResponse) GetLoginCount() int64 {
+	if x != nil {
+		return x.LoginCount
+	}
+	return 0
+}
+
+func (x *GetLoginHistoryResponse) GetLastLoginAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastLoginAt
+	}
+	return nil
+}
+
+type ForceLogoutRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Report the sessions that would be revoked without revoking them
+	DryRun        bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForceLogoutRequest) Reset() {
+	*x = ForceLogoutRequest{}
+	mi := &file_proto_user_proto_msgTypes[185]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForceLogoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForceLogoutRequest) ProtoMessage() {}
+
+func (x *ForceLogoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[185]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForceLogoutRequest.ProtoReflect.Descriptor instead.
+func (*ForceLogoutRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{185}
+}
+
+func (x *ForceLogoutRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ForceLogoutRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type ForceLogoutResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// On dry runs, the sessions that would be revoked
+	SessionsRevoked int32  `protobuf:"varint,1,opt,name=sessions_revoked,json=sessionsRevoked,proto3" json:"sessions_revoked,omitempty"`
+	Message         string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// What the call would change, only set on dry runs
+	Affected      []*AffectedRecords `protobuf:"bytes,3,rep,name=affected,proto3" json:"affected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForceLogoutResponse) Reset() {
+	*x = ForceLogoutResponse{}
+	mi := &file_proto_user_proto_msgTypes[186]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForceLogoutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForceLogoutResponse) ProtoMessage() {}
+
+func (x *ForceLogoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[186]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForceLogoutResponse.ProtoReflect.Descriptor instead.
+func (*ForceLogoutResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{186}
+}
+
+func (x *ForceLogoutResponse) GetSessionsRevoked() int32 {
+	if x != nil {
+		return x.SessionsRevoked
+	}
+	return 0
+}
+
+func (x *ForceLogoutResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ForceLogoutResponse) GetAffected() []*AffectedRecords {
+	if x != nil {
+		return x.Affected
+	}
+	return nil
+}
+
+type CreateOrganizationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Policy        *OrganizationPolicy    `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOrganizationRequest) Reset() {
+	*x = CreateOrganizationRequest{}
+	mi := &file_proto_user_proto_msgTypes[187]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrganizationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrganizationRequest) ProtoMessage() {}
+
+func (x *CreateOrganizationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[187]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrganizationRequest.ProtoReflect.Descriptor instead.
+func (*CreateOrganizationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{187}
+}
+
+func (x *CreateOrganizationRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateOrganizationRequest) GetPolicy() *OrganizationPolicy {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+type CreateOrganizationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Organization  *Organization          `protobuf:"bytes,1,opt,name=organization,proto3" json:"organization,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOrganizationResponse) Reset() {
+	*x = CreateOrganizationResponse{}
+	mi := &file_proto_user_proto_msgTypes[188]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrganizationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrganizationResponse) ProtoMessage() {}
+
+func (x *CreateOrganizationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[188]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrganizationResponse.ProtoReflect.Descriptor instead.
+func (*CreateOrganizationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{188}
+}
+
+func (x *CreateOrganizationResponse) GetOrganization() *Organization {
+	if x != nil {
+		return x.Organization
+	}
+	return nil
+}
+
+type UpdateOrganizationPolicyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrgId         string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Policy        *OrganizationPolicy    `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateOrganizationPolicyRequest) Reset() {
+	*x = UpdateOrganizationPolicyRequest{}
+	mi := &file_proto_user_proto_msgTypes[189]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateOrganizationPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOrganizationPolicyRequest) ProtoMessage() {}
+
+func (x *UpdateOrganizationPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[189]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateOrganizationPolicyRequest.ProtoReflect.Descriptor instead.
+func (*UpdateOrganizationPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{189}
+}
+
+func (x *UpdateOrganizationPolicyRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *UpdateOrganizationPolicyRequest) GetPolicy() *OrganizationPolicy {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+type UpdateOrganizationPolicyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Organization  *Organization          `protobuf:"bytes,1,opt,name=organization,proto3" json:"organization,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateOrganizationPolicyResponse) Reset() {
+	*x = UpdateOrganizationPolicyResponse{}
+	mi := &file_proto_user_proto_msgTypes[190]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateOrganizationPolicyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOrganizationPolicyResponse) ProtoMessage() {}
+
+func (x *UpdateOrganizationPolicyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[190]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateOrganizationPolicyResponse.ProtoReflect.Descriptor instead.
+func (*UpdateOrganizationPolicyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{190}
+}
+
+func (x *UpdateOrganizationPolicyResponse) GetOrganization() *Organization {
+	if x != nil {
+		return x.Organization
+	}
+	return nil
+}
+
+type SetOrganizationSSORequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	OrgId string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	// Unset removes the connection
+	Sso *OrganizationSSO `protobuf:"bytes,2,opt,name=sso,proto3" json:"sso,omitempty"`
+	// With sso unset, also deactivate the members who signed in through the
+	// removed provider
+	DeprovisionMembers bool `protobuf:"varint,3,opt,name=deprovision_members,json=deprovisionMembers,proto3" json:"deprovision_members,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *SetOrganizationSSORequest) Reset() {
+	*x = SetOrganizationSSORequest{}
+	mi := &file_proto_user_proto_msgTypes[191]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetOrganizationSSORequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOrganizationSSORequest) ProtoMessage() {}
+
+func (x *SetOrganizationSSORequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[191]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOrganizationSSORequest.ProtoReflect.Descriptor instead.
+func (*SetOrganizationSSORequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{191}
+}
+
+func (x *SetOrganizationSSORequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *SetOrganizationSSORequest) GetSso() *OrganizationSSO {
+	if x != nil {
+		return x.Sso
+	}
+	return nil
+}
+
+func (x *SetOrganizationSSORequest) GetDeprovisionMembers() bool {
+	if x != nil {
+		return x.DeprovisionMembers
+	}
+	return false
+}
+
+type SetOrganizationSSOResponse struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Organization *Organization          `protobuf:"bytes,1,opt,name=organization,proto3" json:"organization,omitempty"`
+	// Set when members are being deprovisioned
+	Job *DeprovisioningJob `protobuf:"bytes,2,opt,name=job,proto3" json:"job,omitempty"`
+	// Set instead of job while the deprovisioning waits out the undo window
+	Operation     *PendingOperation `protobuf:"bytes,3,opt,name=operation,proto3" json:"operation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetOrganizationSSOResponse) Reset() {
+	*x = SetOrganizationSSOResponse{}
+	mi := &file_proto_user_proto_msgTypes[192]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetOrganizationSSOResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOrganizationSSOResponse) ProtoMessage() {}
+
+func (x *SetOrganizationSSOResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[192]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOrganizationSSOResponse.ProtoReflect.Descriptor instead.
+func (*SetOrganizationSSOResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{192}
+}
+
+func (x *SetOrganizationSSOResponse) GetOrganization() *Organization {
+	if x != nil {
+		return x.Organization
+	}
+	return nil
+}
+
+func (x *SetOrganizationSSOResponse) GetJob() *DeprovisioningJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+func (x *SetOrganizationSSOResponse) GetOperation() *PendingOperation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+type SetOrganizationProvisioningRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	OrgId string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	// Unset removes the rules, so only existing members can sign in
+	Provisioning *OrganizationProvisioning `protobuf:"bytes,2,opt,name=provisioning,proto3" json:"provisioning,omitempty"`
+	// Validate the rules and evaluate the samples without saving
+	DryRun bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// ID token claims, each a JSON object, to evaluate the rules against
+	SampleClaims  []string `protobuf:"bytes,4,rep,name=sample_claims,json=sampleClaims,proto3" json:"sample_claims,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetOrganizationProvisioningRequest) Reset() {
+	*x = SetOrganizationProvisioningRequest{}
+	mi := &file_proto_user_proto_msgTypes[193]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetOrganizationProvisioningRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOrganizationProvisioningRequest) ProtoMessage() {}
+
+func (x *SetOrganizationProvisioningRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[193]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOrganizationProvisioningRequest.ProtoReflect.Descriptor instead.
+func (*SetOrganizationProvisioningRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{193}
+}
+
+func (x *SetOrganizationProvisioningRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *SetOrganizationProvisioningRequest) GetProvisioning() *OrganizationProvisioning {
+	if x != nil {
+		return x.Provisioning
+	}
+	return nil
+}
+
+func (x *SetOrganizationProvisioningRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *SetOrganizationProvisioningRequest) GetSampleClaims() []string {
+	if x != nil {
+		return x.SampleClaims
+	}
+	return nil
+}
+
+// ProvisioningDecision is what the rules decide for a sample
+type ProvisioningDecision struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Position of the sample in the request
+	Index   int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Allowed bool  `protobuf:"varint,2,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	// Blocklist entry that refused the sample
+	Reason  string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	OrgRole string `protobuf:"bytes,4,opt,name=org_role,json=orgRole,proto3" json:"org_role,omitempty"`
+	// Group whose mapping chose the role; empty for the default role
+	MatchedGroup string `protobuf:"bytes,5,opt,name=matched_group,json=matchedGroup,proto3" json:"matched_group,omitempty"`
+	// Name from the claims, or made from the email address
+	Name        string            `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	ExternalIds map[string]string `protobuf:"bytes,7,rep,name=external_ids,json=externalIds,proto3" json:"external_ids,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Roles the role mappings grant
+	Roles         []string `protobuf:"bytes,8,rep,name=roles,proto3" json:"roles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProvisioningDecision) Reset() {
+	*x = ProvisioningDecision{}
+	mi := &file_proto_user_proto_msgTypes[194]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProvisioningDecision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvisioningDecision) ProtoMessage() {}
+
+func (x *ProvisioningDecision) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[194]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvisioningDecision.ProtoReflect.Descriptor instead.
+func (*ProvisioningDecision) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{194}
+}
+
+func (x *ProvisioningDecision) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ProvisioningDecision) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *ProvisioningDecision) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ProvisioningDecision) GetOrgRole() string {
+	if x != nil {
+		return x.OrgRole
+	}
+	return ""
+}
+
+func (x *ProvisioningDecision) GetMatchedGroup() string {
+	if x != nil {
+		return x.MatchedGroup
+	}
+	return ""
+}
+
+func (x *ProvisioningDecision) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProvisioningDecision) GetExternalIds() map[string]string {
+	if x != nil {
+		return x.ExternalIds
+	}
+	return nil
+}
+
+func (x *ProvisioningDecision) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+type SetOrganizationProvisioningResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unset for a dry run
+	Organization  *Organization           `protobuf:"bytes,1,opt,name=organization,proto3" json:"organization,omitempty"`
+	Decisions     []*ProvisioningDecision `protobuf:"bytes,2,rep,name=decisions,proto3" json:"decisions,omitempty"`
+	Message       string                  `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetOrganizationProvisioningResponse) Reset() {
+	*x = SetOrganizationProvisioningResponse{}
+	mi := &file_proto_user_proto_msgTypes[195]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetOrganizationProvisioningResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOrganizationProvisioningResponse) ProtoMessage() {}
+
+func (x *SetOrganizationProvisioningResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[195]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOrganizationProvisioningResponse.ProtoReflect.Descriptor instead.
+func (*SetOrganizationProvisioningResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{195}
+}
+
+func (x *SetOrganizationProvisioningResponse) GetOrganization() *Organization {
+	if x != nil {
+		return x.Organization
+	}
+	return nil
+}
+
+func (x *SetOrganizationProvisioningResponse) GetDecisions() []*ProvisioningDecision {
+	if x != nil {
+		return x.Decisions
+	}
+	return nil
+}
+
+func (x *SetOrganizationProvisioningResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// DeprovisioningJob deactivates, in the background, the members an
+// organization's identity provider no longer vouches for
+type DeprovisioningJob struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	// "sso_disconnected", "deleted" or "inactive"
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// "pending" or "done"
+	Status      string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	// Accounts deactivated so far
+	Deactivated int32 `protobuf:"varint,7,opt,name=deactivated,proto3" json:"deactivated,omitempty"`
+	// Sessions of those accounts that were revoked
+	SessionsRevoked int32 `protobuf:"varint,8,opt,name=sessions_revoked,json=sessionsRevoked,proto3" json:"sessions_revoked,omitempty"`
+	// Accounts left active
+	Skipped       []*DeprovisioningSkip `protobuf:"bytes,9,rep,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeprovisioningJob) Reset() {
+	*x = DeprovisioningJob{}
+	mi := &file_proto_user_proto_msgTypes[196]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeprovisioningJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeprovisioningJob) ProtoMessage() {}
+
+func (x *DeprovisioningJob) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[196]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeprovisioningJob.ProtoReflect.Descriptor instead.
+func (*DeprovisioningJob) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{196}
+}
+
+func (x *DeprovisioningJob) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeprovisioningJob) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *DeprovisioningJob) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *DeprovisioningJob) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DeprovisioningJob) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *DeprovisioningJob) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *DeprovisioningJob) GetDeactivated() int32 {
+	if x != nil {
+		return x.Deactivated
+	}
+	return 0
+}
+
+func (x *DeprovisioningJob) GetSessionsRevoked() int32 {
+	if x != nil {
+		return x.SessionsRevoked
+	}
+	return 0
+}
+
+func (x *DeprovisioningJob) GetSkipped() []*DeprovisioningSkip {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+type DeprovisioningSkip struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// "admin" or "org_admin"
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeprovisioningSkip) Reset() {
+	*x = DeprovisioningSkip{}
+	mi := &file_proto_user_proto_msgTypes[197]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeprovisioningSkip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeprovisioningSkip) ProtoMessage() {}
+
+func (x *DeprovisioningSkip) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[197]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeprovisioningSkip.ProtoReflect.Descriptor instead.
+func (*DeprovisioningSkip) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{197}
+}
+
+func (x *DeprovisioningSkip) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DeprovisioningSkip) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type DeprovisionOrganizationMembersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	OrgId string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	// Subjects of identities deleted at the provider, such as by a SCIM
+	// delete
+	Subjects []string `protobuf:"bytes,2,rep,name=subjects,proto3" json:"subjects,omitempty"`
+	// Instead of subjects, deprovision identities that haven't signed in
+	// for this long, at least a day
+	InactiveFor   *durationpb.Duration `protobuf:"bytes,3,opt,name=inactive_for,json=inactiveFor,proto3" json:"inactive_for,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeprovisionOrganizationMembersRequest) Reset() {
+	*x = DeprovisionOrganizationMembersRequest{}
+	mi := &file_proto_user_proto_msgTypes[198]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeprovisionOrganizationMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeprovisionOrganizationMembersRequest) ProtoMessage() {}
+
+func (x *DeprovisionOrganizationMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[198]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeprovisionOrganizationMembersRequest.ProtoReflect.Descriptor instead.
+func (*DeprovisionOrganizationMembersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{198}
+}
+
+func (x *DeprovisionOrganizationMembersRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *DeprovisionOrganizationMembersRequest) GetSubjects() []string {
+	if x != nil {
+		return x.Subjects
+	}
+	return nil
+}
+
+func (x *DeprovisionOrganizationMembersRequest) GetInactiveFor() *durationpb.Duration {
+	if x != nil {
+		return x.InactiveFor
+	}
+	return nil
+}
+
+type DeprovisionOrganizationMembersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unset while the job waits out the undo window
+	Job *DeprovisioningJob `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	// Set when the job waits out the undo window before it is queued
+	Operation     *PendingOperation `protobuf:"bytes,2,opt,name=operation,proto3" json:"operation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeprovisionOrganizationMembersResponse) Reset() {
+	*x = DeprovisionOrganizationMembersResponse{}
+	mi := &file_proto_user_proto_msgTypes[199]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeprovisionOrganizationMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeprovisionOrganizationMembersResponse) ProtoMessage() {}
+
+func (x *DeprovisionOrganizationMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[199]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeprovisionOrganizationMembersResponse.ProtoReflect.Descriptor instead.
+func (*DeprovisionOrganizationMembersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{199}
+}
+
+func (x *DeprovisionOrganizationMembersResponse) GetJob() *DeprovisioningJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+func (x *DeprovisionOrganizationMembersResponse) GetOperation() *PendingOperation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+type GetDeprovisioningJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeprovisioningJobRequest) Reset() {
+	*x = GetDeprovisioningJobRequest{}
+	mi := &file_proto_user_proto_msgTypes[200]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeprovisioningJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeprovisioningJobRequest) ProtoMessage() {}
+
+func (x *GetDeprovisioningJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[200]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeprovisioningJobRequest.ProtoReflect.Descriptor instead.
+func (*GetDeprovisioningJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{200}
+}
+
+func (x *GetDeprovisioningJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type GetDeprovisioningJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *DeprovisioningJob     `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeprovisioningJobResponse) Reset() {
+	*x = GetDeprovisioningJobResponse{}
+	mi := &file_proto_user_proto_msgTypes[201]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeprovisioningJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeprovisioningJobResponse) ProtoMessage() {}
+
+func (x *GetDeprovisioningJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[201]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeprovisioningJobResponse.ProtoReflect.Descriptor instead.
+func (*GetDeprovisioningJobResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{201}
+}
+
+func (x *GetDeprovisioningJobResponse) GetJob() *DeprovisioningJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type SetOrganizationMemberRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	OrgId  string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	UserId string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// "member" or "admin"; empty removes the user from the organization
+	Role          string `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetOrganizationMemberRequest) Reset() {
+	*x = SetOrganizationMemberRequest{}
+	mi := &file_proto_user_proto_msgTypes[202]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetOrganizationMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOrganizationMemberRequest) ProtoMessage() {}
+
+func (x *SetOrganizationMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[202]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOrganizationMemberRequest.ProtoReflect.Descriptor instead.
+func (*SetOrganizationMemberRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{202}
+}
+
+func (x *SetOrganizationMemberRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *SetOrganizationMemberRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SetOrganizationMemberRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type SetOrganizationMemberResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetOrganizationMemberResponse) Reset() {
+	*x = SetOrganizationMemberResponse{}
+	mi := &file_proto_user_proto_msgTypes[203]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetOrganizationMemberResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOrganizationMemberResponse) ProtoMessage() {}
+
+func (x *SetOrganizationMemberResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[203]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOrganizationMemberResponse.ProtoReflect.Descriptor instead.
+func (*SetOrganizationMemberResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{203}
+}
+
+func (x *SetOrganizationMemberResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Destroying an organization's encryption key deletes its members'
+// encrypted personal data
+type DestroyOrganizationKeyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	OrgId string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	// Must be set: the data can't be recovered once the key is destroyed
+	Confirm       bool `protobuf:"varint,2,opt,name=confirm,proto3" json:"confirm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DestroyOrganizationKeyRequest) Reset() {
+	*x = DestroyOrganizationKeyRequest{}
+	mi := &file_proto_user_proto_msgTypes[204]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DestroyOrganizationKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DestroyOrganizationKeyRequest) ProtoMessage() {}
+
+func (x *DestroyOrganizationKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[204]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DestroyOrganizationKeyRequest.ProtoReflect.Descriptor instead.
+func (*DestroyOrganizationKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{204}
+}
+
+func (x *DestroyOrganizationKeyRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *DestroyOrganizationKeyRequest) GetConfirm() bool {
+	if x != nil {
+		return x.Confirm
+	}
+	return false
+}
+
+type DestroyOrganizationKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DestroyedAt   *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=destroyed_at,json=destroyedAt,proto3" json:"destroyed_at,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DestroyOrganizationKeyResponse) Reset() {
+	*x = DestroyOrganizationKeyResponse{}
+	mi := &file_proto_user_proto_msgTypes[205]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DestroyOrganizationKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DestroyOrganizationKeyResponse) ProtoMessage() {}
+
+func (x *DestroyOrganizationKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[205]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DestroyOrganizationKeyResponse.ProtoReflect.Descriptor instead.
+func (*DestroyOrganizationKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{205}
+}
+
+func (x *DestroyOrganizationKeyResponse) GetDestroyedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DestroyedAt
+	}
+	return nil
+}
+
+func (x *DestroyOrganizationKeyResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// External IDs
+type SetExternalIdRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	System string                 `protobuf:"bytes,2,opt,name=system,proto3" json:"system,omitempty"`
+	// Empty removes the user's ID in the system
+	ExternalId    string `protobuf:"bytes,3,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetExternalIdRequest) Reset() {
+	*x = SetExternalIdRequest{}
+	mi := &file_proto_user_proto_msgTypes[206]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetExternalIdRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetExternalIdRequest) ProtoMessage() {}
+
+func (x *SetExternalIdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[206]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetExternalIdRequest.ProtoReflect.Descriptor instead.
+func (*SetExternalIdRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{206}
+}
+
+func (x *SetExternalIdRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SetExternalIdRequest) GetSystem() string {
+	if x != nil {
+		return x.System
+	}
+	return ""
+}
+
+func (x *SetExternalIdRequest) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+type SetExternalIdResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetExternalIdResponse) Reset() {
+	*x = SetExternalIdResponse{}
+	mi := &file_proto_user_proto_msgTypes[207]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetExternalIdResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetExternalIdResponse) ProtoMessage() {}
+
+func (x *SetExternalIdResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[207]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetExternalIdResponse.ProtoReflect.Descriptor instead.
+func (*SetExternalIdResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{207}
+}
+
+func (x *SetExternalIdResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *SetExternalIdResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetUserByExternalIdRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	System        string                 `protobuf:"bytes,1,opt,name=system,proto3" json:"system,omitempty"`
+	ExternalId    string                 `protobuf:"bytes,2,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserByExternalIdRequest) Reset() {
+	*x = GetUserByExternalIdRequest{}
+	mi := &file_proto_user_proto_msgTypes[208]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserByExternalIdRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserByExternalIdRequest) ProtoMessage() {}
+
+func (x *GetUserByExternalIdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[208]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserByExternalIdRequest.ProtoReflect.Descriptor instead.
+func (*GetUserByExternalIdRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{208}
+}
+
+func (x *GetUserByExternalIdRequest) GetSystem() string {
+	if x != nil {
+		return x.System
+	}
+	return ""
+}
+
+func (x *GetUserByExternalIdRequest) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+type GetUserByExternalIdResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserByExternalIdResponse) Reset() {
+	*x = GetUserByExternalIdResponse{}
+	mi := &file_proto_user_proto_msgTypes[209]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserByExternalIdResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserByExternalIdResponse) ProtoMessage() {}
+
+func (x *GetUserByExternalIdResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[209]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserByExternalIdResponse.ProtoReflect.Descriptor instead.
+func (*GetUserByExternalIdResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{209}
+}
+
+func (x *GetUserByExternalIdResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// Batch user lookup
+type GetUsersByIdsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// At most 100 IDs. Duplicates are looked up once.
+	UserIds       []string `protobuf:"bytes,1,rep,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsersByIdsRequest) Reset() {
+	*x = GetUsersByIdsRequest{}
+	mi := &file_proto_user_proto_msgTypes[210]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsersByIdsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersByIdsRequest) ProtoMessage() {}
+
+func (x *GetUsersByIdsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[210]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersByIdsRequest.ProtoReflect.Descriptor instead.
+func (*GetUsersByIdsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{210}
+}
+
+func (x *GetUsersByIdsRequest) GetUserIds() []string {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+type GetUsersByIdsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The users found, in the order of user_ids
+	Users []*User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// IDs of users that don't exist or are deleted
+	MissingIds    []string `protobuf:"bytes,2,rep,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsersByIdsResponse) Reset() {
+	*x = GetUsersByIdsResponse{}
+	mi := &file_proto_user_proto_msgTypes[211]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsersByIdsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersByIdsResponse) ProtoMessage() {}
+
+func (x *GetUsersByIdsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[211]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersByIdsResponse.ProtoReflect.Descriptor instead.
+func (*GetUsersByIdsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{211}
+}
+
+func (x *GetUsersByIdsResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *GetUsersByIdsResponse) GetMissingIds() []string {
+	if x != nil {
+		return x.MissingIds
+	}
+	return nil
+}
+
+// Streaming user export
+type ExportUsersRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	NameFilter  string                 `protobuf:"bytes,1,opt,name=name_filter,json=nameFilter,proto3" json:"name_filter,omitempty"`
+	EmailFilter string                 `protobuf:"bytes,2,opt,name=email_filter,json=emailFilter,proto3" json:"email_filter,omitempty"`
+	// Only active, or only inactive, users when set
+	IsActive *bool `protobuf:"varint,3,opt,name=is_active,json=isActive,proto3,oneof" json:"is_active,omitempty"`
+	// Only users created after, and before, these times when set
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	// ID of the last user an interrupted export sent; the export continues
+	// with the users after it
+	ResumeAfter   string `protobuf:"bytes,6,opt,name=resume_after,json=resumeAfter,proto3" json:"resume_after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportUsersRequest) Reset() {
+	*x = ExportUsersRequest{}
+	mi := &file_proto_user_proto_msgTypes[212]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportUsersRequest) ProtoMessage() {}
+
+func (x *ExportUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[212]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportUsersRequest.ProtoReflect.Descriptor instead.
+func (*ExportUsersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{212}
+}
+
+func (x *ExportUsersRequest) GetNameFilter() string {
+	if x != nil {
+		return x.NameFilter
+	}
+	return ""
+}
+
+func (x *ExportUsersRequest) GetEmailFilter() string {
+	if x != nil {
+		return x.EmailFilter
+	}
+	return ""
+}
+
+func (x *ExportUsersRequest) GetIsActive() bool {
+	if x != nil && x.IsActive != nil {
+		return *x.IsActive
+	}
+	return false
+}
+
+func (x *ExportUsersRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *ExportUsersRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *ExportUsersRequest) GetResumeAfter() string {
+	if x != nil {
+		return x.ResumeAfter
+	}
+	return ""
+}
+
+// Bulk user import
+type ImportUser struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Email string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Hash from the previous provider: bcrypt, argon2 (PHC string) or
+	// pbkdf2-sha1/sha256/sha512. Empty imports the account without a password.
+	PasswordHash string            `protobuf:"bytes,3,opt,name=password_hash,json=passwordHash,proto3" json:"password_hash,omitempty"`
+	ExternalIds  map[string]string `protobuf:"bytes,4,rep,name=external_ids,json=externalIds,proto3" json:"external_ids,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Import the account deactivated
+	Disabled bool `protobuf:"varint,5,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	// The previous provider verified the email address
+	EmailVerified bool `protobuf:"varint,6,opt,name=email_verified,json=emailVerified,proto3" json:"email_verified,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportUser) Reset() {
+	*x = ImportUser{}
+	mi := &file_proto_user_proto_msgTypes[213]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportUser) ProtoMessage() {}
+
+func (x *ImportUser) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[213]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportUser.ProtoReflect.Descriptor instead.
+func (*ImportUser) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{213}
+}
+
+func (x *ImportUser) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ImportUser) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ImportUser) GetPasswordHash() string {
+	if x != nil {
+		return x.PasswordHash
+	}
+	return ""
+}
+
+func (x *ImportUser) GetExternalIds() map[string]string {
+	if x != nil {
+		return x.ExternalIds
+	}
+	return nil
+}
+
+func (x *ImportUser) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *ImportUser) GetEmailVerified() bool {
+	if x != nil {
+		return x.EmailVerified
+	}
+	return false
+}
+
+type ImportUsersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Users []*ImportUser          `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// Validate the users without importing them
+	DryRun bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// Import in the background and return an operation, whose response is
+	// the ImportUsersResponse
+	Async         bool `protobuf:"varint,3,opt,name=async,proto3" json:"async,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportUsersRequest) Reset() {
+	*x = ImportUsersRequest{}
+	mi := &file_proto_user_proto_msgTypes[214]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportUsersRequest) ProtoMessage() {}
+
+func (x *ImportUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[214]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportUsersRequest.ProtoReflect.Descriptor instead.
+func (*ImportUsersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{214}
+}
+
+func (x *ImportUsersRequest) GetUsers() []*ImportUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ImportUsersRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ImportUsersRequest) GetAsync() bool {
+	if x != nil {
+		return x.Async
+	}
+	return false
+}
+
+type SkippedImportUser struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Position of the user in the request
+	Index         int32  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Email         string `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkippedImportUser) Reset() {
+	*x = SkippedImportUser{}
+	mi := &file_proto_user_proto_msgTypes[215]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkippedImportUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkippedImportUser) ProtoMessage() {}
+
+func (x *SkippedImportUser) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[215]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkippedImportUser.ProtoReflect.Descriptor instead.
+func (*SkippedImportUser) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{215}
+}
+
+func (x *SkippedImportUser) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *SkippedImportUser) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *SkippedImportUser) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ImportUsersResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Imported int32                  `protobuf:"varint,1,opt,name=imported,proto3" json:"imported,omitempty"`
+	Skipped  []*SkippedImportUser   `protobuf:"bytes,2,rep,name=skipped,proto3" json:"skipped,omitempty"`
+	Message  string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// Set instead of the results for async imports
+	Operation     *Operation `protobuf:"bytes,4,opt,name=operation,proto3" json:"operation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportUsersResponse) Reset() {
+	*x = ImportUsersResponse{}
+	mi := &file_proto_user_proto_msgTypes[216]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportUsersResponse) ProtoMessage() {}
+
+func (x *ImportUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[216]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportUsersResponse.ProtoReflect.Descriptor instead.
+func (*ImportUsersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{216}
+}
+
+func (x *ImportUsersResponse) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportUsersResponse) GetSkipped() []*SkippedImportUser {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+func (x *ImportUsersResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ImportUsersResponse) GetOperation() *Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+// Operation is a long-running admin task, such as a bulk import, run in
+// the background. It is kept for admin.operation_retention once done.
+type Operation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// "import_users", "rebuild_indexes" or "account_purge"
+	Kind string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Admin or service account that started the operation
+	RequestedBy string `protobuf:"bytes,3,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	Done        bool   `protobuf:"varint,4,opt,name=done,proto3" json:"done,omitempty"`
+	// Records handled so far
+	Processed int64 `protobuf:"varint,5,opt,name=processed,proto3" json:"processed,omitempty"`
+	// Records the operation expects to handle, 0 when not known in advance
+	Total     int64                  `protobuf:"varint,6,opt,name=total,proto3" json:"total,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Last progress, refreshed at least every 30 seconds while running
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	// Set once done
+	//
+	// Types that are valid to be assigned to Result:
+	//
+	//	*Operation_Error
+	//	*Operation_Response
+	Result        isOperation_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Operation) Reset() {
+	*x = Operation{}
+	mi := &file_proto_user_proto_msgTypes[217]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Operation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operation) ProtoMessage() {}
+
+func (x *Operation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[217]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
+func (*Operation) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{217}
+}
+
+func (x *Operation) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Operation) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Operation) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+func (x *Operation) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *Operation) GetProcessed() int64 {
+	if x != nil {
+		return x.Processed
+	}
+	return 0
+}
+
+func (x *Operation) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Operation) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Operation) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Operation) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *Operation) GetResult() isOperation_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *Operation) GetError() *OperationError {
+	if x != nil {
+		if x, ok := x.Result.(*Operation_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetResponse() *anypb.Any {
+	if x != nil {
+		if x, ok := x.Result.(*Operation_Response); ok {
+			return x.Response
+		}
+	}
+	return nil
+}
+
+type isOperation_Result interface {
+	isOperation_Result()
+}
+
+type Operation_Error struct {
+	Error *OperationError `protobuf:"bytes,10,opt,name=error,proto3,oneof"`
+}
+
+type Operation_Response struct {
+	// Result of the task, such as an ImportUsersResponse. Unset for
+	// tasks without one.
+	Response *anypb.Any `protobuf:"bytes,11,opt,name=response,proto3,oneof"`
+}
+
+func (*Operation_Error) isOperation_Result() {}
+
+func (*Operation_Response) isOperation_Result() {}
+
+type OperationError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// gRPC status code name, such as "INTERNAL"
+	Code          string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OperationError) Reset() {
+	*x = OperationError{}
+	mi := &file_proto_user_proto_msgTypes[218]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OperationError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperationError) ProtoMessage() {}
+
+func (x *OperationError) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[218]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperationError.ProtoReflect.Descriptor instead.
+func (*OperationError) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{218}
+}
+
+func (x *OperationError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *OperationError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetOperationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOperationRequest) Reset() {
+	*x = GetOperationRequest{}
+	mi := &file_proto_user_proto_msgTypes[219]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOperationRequest) ProtoMessage() {}
+
+func (x *GetOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[219]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOperationRequest.ProtoReflect.Descriptor instead.
+func (*GetOperationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{219}
+}
+
+func (x *GetOperationRequest) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+type GetOperationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Operation     *Operation             `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOperationResponse) Reset() {
+	*x = GetOperationResponse{}
+	mi := &file_proto_user_proto_msgTypes[220]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOperationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOperationResponse) ProtoMessage() {}
+
+func (x *GetOperationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[220]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOperationResponse.ProtoReflect.Descriptor instead.
+func (*GetOperationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{220}
+}
+
+func (x *GetOperationResponse) GetOperation() *Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+type ListOperationsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only operations of this kind, when set
+	Kind          string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Page          int32  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOperationsRequest) Reset() {
+	*x = ListOperationsRequest{}
+	mi := &file_proto_user_proto_msgTypes[221]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOperationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOperationsRequest) ProtoMessage() {}
+
+func (x *ListOperationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[221]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOperationsRequest.ProtoReflect.Descriptor instead.
+func (*ListOperationsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{221}
+}
+
+func (x *ListOperationsRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ListOperationsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListOperationsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListOperationsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first
+	Operations    []*Operation `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
+	TotalCount    int32        `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Page          int32        `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32        `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOperationsResponse) Reset() {
+	*x = ListOperationsResponse{}
+	mi := &file_proto_user_proto_msgTypes[222]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOperationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOperationsResponse) ProtoMessage() {}
+
+func (x *ListOperationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[222]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOperationsResponse.ProtoReflect.Descriptor instead.
+func (*ListOperationsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{222}
+}
+
+func (x *ListOperationsResponse) GetOperations() []*Operation {
+	if x != nil {
+		return x.Operations
+	}
+	return nil
+}
+
+func (x *ListOperationsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *ListOperationsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListOperationsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type WatchOperationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchOperationRequest) Reset() {
+	*x = WatchOperationRequest{}
+	mi := &file_proto_user_proto_msgTypes[223]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchOperationRequest) ProtoMessage() {}
+
+func (x *WatchOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[223]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchOperationRequest.ProtoReflect.Descriptor instead.
+func (*WatchOperationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{223}
+}
+
+func (x *WatchOperationRequest) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+type RebuildIndexesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RebuildIndexesRequest) Reset() {
+	*x = RebuildIndexesRequest{}
+	mi := &file_proto_user_proto_msgTypes[224]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RebuildIndexesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RebuildIndexesRequest) ProtoMessage() {}
+
+func (x *RebuildIndexesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[224]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RebuildIndexesRequest.ProtoReflect.Descriptor instead.
+func (*RebuildIndexesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{224}
+}
+
+type RebuildIndexesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Operation     *Operation             `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RebuildIndexesResponse) Reset() {
+	*x = RebuildIndexesResponse{}
+	mi := &file_proto_user_proto_msgTypes[225]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RebuildIndexesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RebuildIndexesResponse) ProtoMessage() {}
+
+func (x *RebuildIndexesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[225]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RebuildIndexesResponse.ProtoReflect.Descriptor instead.
+func (*RebuildIndexesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{225}
+}
+
+func (x *RebuildIndexesResponse) GetOperation() *Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+type RunAccountPurgeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunAccountPurgeRequest) Reset() {
+	*x = RunAccountPurgeRequest{}
+	mi := &file_proto_user_proto_msgTypes[226]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunAccountPurgeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunAccountPurgeRequest) ProtoMessage() {}
+
+func (x *RunAccountPurgeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[226]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunAccountPurgeRequest.ProtoReflect.Descriptor instead.
+func (*RunAccountPurgeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{226}
+}
+
+type RunAccountPurgeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Operation     *Operation             `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunAccountPurgeResponse) Reset() {
+	*x = RunAccountPurgeResponse{}
+	mi := &file_proto_user_proto_msgTypes[227]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunAccountPurgeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunAccountPurgeResponse) ProtoMessage() {}
+
+func (x *RunAccountPurgeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[227]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunAccountPurgeResponse.ProtoReflect.Descriptor instead.
+func (*RunAccountPurgeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{227}
+}
+
+func (x *RunAccountPurgeResponse) GetOperation() *Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+// AccountPurgeResult is the response of an account_purge operation
+type AccountPurgeResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Purged        int32                  `protobuf:"varint,1,opt,name=purged,proto3" json:"purged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountPurgeResult) Reset() {
+	*x = AccountPurgeResult{}
+	mi := &file_proto_user_proto_msgTypes[228]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountPurgeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountPurgeResult) ProtoMessage() {}
+
+func (x *AccountPurgeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[228]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountPurgeResult.ProtoReflect.Descriptor instead.
+func (*AccountPurgeResult) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{228}
+}
+
+func (x *AccountPurgeResult) GetPurged() int32 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
+}
+
+type ReplayAuditLogRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Projections to rebuild, all of them when empty
+	Projections []string `protobuf:"bytes,1,rep,name=projections,proto3" json:"projections,omitempty"`
+	// Last entry applied by the previous batch. An empty value resets the
+	// projections and starts a new replay.
+	AfterId string `protobuf:"bytes,2,opt,name=after_id,json=afterId,proto3" json:"after_id,omitempty"`
+	// Last entry the replay covers, as returned by the first batch
+	UntilId string `protobuf:"bytes,3,opt,name=until_id,json=untilId,proto3" json:"until_id,omitempty"`
+	// Entries applied by this call, 500 by default and at most 5000
+	BatchSize     int32 `protobuf:"varint,4,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayAuditLogRequest) Reset() {
+	*x = ReplayAuditLogRequest{}
+	mi := &file_proto_user_proto_msgTypes[229]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayAuditLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayAuditLogRequest) ProtoMessage() {}
+
+func (x *ReplayAuditLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[229]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayAuditLogRequest.ProtoReflect.Descriptor instead.
+func (*ReplayAuditLogRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{229}
+}
+
+func (x *ReplayAuditLogRequest) GetProjections() []string {
+	if x != nil {
+		return x.Projections
+	}
+	return nil
+}
+
+func (x *ReplayAuditLogRequest) GetAfterId() string {
+	if x != nil {
+		return x.AfterId
+	}
+	return ""
+}
+
+func (x *ReplayAuditLogRequest) GetUntilId() string {
+	if x != nil {
+		return x.UntilId
+	}
+	return ""
+}
+
+func (x *ReplayAuditLogRequest) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+type ReplayAuditLogResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Processed int32                  `protobuf:"varint,1,opt,name=processed,proto3" json:"processed,omitempty"`
+	// Pass as after_id and until_id to continue the replay
+	AfterId       string   `protobuf:"bytes,2,opt,name=after_id,json=afterId,proto3" json:"after_id,omitempty"`
+	UntilId       string   `protobuf:"bytes,3,opt,name=until_id,json=untilId,proto3" json:"until_id,omitempty"`
+	Remaining     int64    `protobuf:"varint,4,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	Done          bool     `protobuf:"varint,5,opt,name=done,proto3" json:"done,omitempty"`
+	Projections   []string `protobuf:"bytes,6,rep,name=projections,proto3" json:"projections,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayAuditLogResponse) Reset() {
+	*x = ReplayAuditLogResponse{}
+	mi := &file_proto_user_proto_msgTypes[230]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayAuditLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayAuditLogResponse) ProtoMessage() {}
+
+func (x *ReplayAuditLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[230]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayAuditLogResponse.ProtoReflect.Descriptor instead.
+func (*ReplayAuditLogResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{230}
+}
+
+func (x *ReplayAuditLogResponse) GetProcessed() int32 {
+	if x != nil {
+		return x.Processed
+	}
+	return 0
+}
+
+func (x *ReplayAuditLogResponse) GetAfterId() string {
+	if x != nil {
+		return x.AfterId
+	}
+	return ""
+}
+
+func (x *ReplayAuditLogResponse) GetUntilId() string {
+	if x != nil {
+		return x.UntilId
+	}
+	return ""
+}
+
+func (x *ReplayAuditLogResponse) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *ReplayAuditLogResponse) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *ReplayAuditLogResponse) GetProjections() []string {
+	if x != nil {
+		return x.Projections
+	}
+	return nil
+}
+
+type GetAuditLogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filters; empty ones match every entry
+	TargetId      string                 `protobuf:"bytes,1,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	ActorId       string                 `protobuf:"bytes,2,opt,name=actor_id,json=actorId,proto3" json:"actor_id,omitempty"`
+	Action        string                 `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=since,proto3" json:"since,omitempty"`
+	Until         *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=until,proto3" json:"until,omitempty"`
+	Page          int32                  `protobuf:"varint,6,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,7,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAuditLogsRequest) Reset() {
+	*x = GetAuditLogsRequest{}
+	mi := &file_proto_user_proto_msgTypes[231]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAuditLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuditLogsRequest) ProtoMessage() {}
+
+func (x *GetAuditLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[231]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuditLogsRequest.ProtoReflect.Descriptor instead.
+func (*GetAuditLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{231}
+}
+
+func (x *GetAuditLogsRequest) GetTargetId() string {
+	if x != nil {
+		return x.TargetId
+	}
+	return ""
+}
+
+func (x *GetAuditLogsRequest) GetActorId() string {
+	if x != nil {
+		return x.ActorId
+	}
+	return ""
+}
+
+func (x *GetAuditLogsRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *GetAuditLogsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *GetAuditLogsRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *GetAuditLogsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetAuditLogsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+// AuditChange is a field changed by the audited action. Values are
+// omitted for fields stored encrypted.
+type AuditChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Before        string                 `protobuf:"bytes,2,opt,name=before,proto3" json:"before,omitempty"`
+	After         string                 `protobuf:"bytes,3,opt,name=after,proto3" json:"after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditChange) Reset() {
+	*x = AuditChange{}
+	mi := &file_proto_user_proto_msgTypes[232]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditChange) ProtoMessage() {}
+
+func (x *AuditChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[232]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditChange.ProtoReflect.Descriptor instead.
+func (*AuditChange) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{232}
+}
+
+func (x *AuditChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *AuditChange) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *AuditChange) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+type AuditLogEntry struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Action string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	// Empty for changes made by the system
+	ActorId   string `protobuf:"bytes,3,opt,name=actor_id,json=actorId,proto3" json:"actor_id,omitempty"`
+	TargetId  string `protobuf:"bytes,4,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	IpAddress string `protobuf:"bytes,5,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	UserAgent string `protobuf:"bytes,6,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	// Values that are not strings are JSON encoded
+	Details       map[string]string      `protobuf:"bytes,7,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Changes       []*AuditChange         `protobuf:"bytes,8,rep,name=changes,proto3" json:"changes,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditLogEntry) Reset() {
+	*x = AuditLogEntry{}
+	mi := &file_proto_user_proto_msgTypes[233]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditLogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditLogEntry) ProtoMessage() {}
+
+func (x *AuditLogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[233]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditLogEntry.ProtoReflect.Descriptor instead.
+func (*AuditLogEntry) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{233}
+}
+
+func (x *AuditLogEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AuditLogEntry) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditLogEntry) GetActorId() string {
+	if x != nil {
+		return x.ActorId
+	}
+	return ""
+}
+
+func (x *AuditLogEntry) GetTargetId() string {
+	if x != nil {
+		return x.TargetId
+	}
+	return ""
+}
+
+func (x *AuditLogEntry) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *AuditLogEntry) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *AuditLogEntry) GetDetails() map[string]string {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *AuditLogEntry) GetChanges() []*AuditChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *AuditLogEntry) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type GetAuditLogsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first
+	Entries       []*AuditLogEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	TotalCount    int32            `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Page          int32            `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32            `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAuditLogsResponse) Reset() {
+	*x = GetAuditLogsResponse{}
+	mi := &file_proto_user_proto_msgTypes[234]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAuditLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuditLogsResponse) ProtoMessage() {}
+
+func (x *GetAuditLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[234]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuditLogsResponse.ProtoReflect.Descriptor instead.
+func (*GetAuditLogsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{234}
+}
+
+func (x *GetAuditLogsResponse) GetEntries() []*AuditLogEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *GetAuditLogsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *GetAuditLogsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetAuditLogsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type GetUserAtRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Point in time to rebuild the user at, now when unset
+	At            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserAtRequest) Reset() {
+	*x = GetUserAtRequest{}
+	mi := &file_proto_user_proto_msgTypes[235]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserAtRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserAtRequest) ProtoMessage() {}
+
+func (x *GetUserAtRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[235]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserAtRequest.ProtoReflect.Descriptor instead.
+func (*GetUserAtRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{235}
+}
+
+func (x *GetUserAtRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetUserAtRequest) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+type GetUserAtResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	User  *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	// Version of the last event applied
+	Version       int64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserAtResponse) Reset() {
+	*x = GetUserAtResponse{}
+	mi := &file_proto_user_proto_msgTypes[236]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserAtResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserAtResponse) ProtoMessage() {}
+
+func (x *GetUserAtResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[236]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserAtResponse.ProtoReflect.Descriptor instead.
+func (*GetUserAtResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{236}
+}
+
+func (x *GetUserAtResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *GetUserAtResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type ListUserEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUserEventsRequest) Reset() {
+	*x = ListUserEventsRequest{}
+	mi := &file_proto_user_proto_msgTypes[237]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUserEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUserEventsRequest) ProtoMessage() {}
+
+func (x *ListUserEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[237]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUserEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListUserEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{237}
+}
+
+func (x *ListUserEventsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListUserEventsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListUserEventsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type UserEvent struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Version int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Type    string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// Names of the fields the event set or removed
+	ChangedFields []string               `protobuf:"bytes,3,rep,name=changed_fields,json=changedFields,proto3" json:"changed_fields,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserEvent) Reset() {
+	*x = UserEvent{}
+	mi := &file_proto_user_proto_msgTypes[238]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserEvent) ProtoMessage() {}
+
+func (x *UserEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[238]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserEvent.ProtoReflect.Descriptor instead.
+func (*UserEvent) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{238}
+}
+
+func (x *UserEvent) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UserEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UserEvent) GetChangedFields() []string {
+	if x != nil {
+		return x.ChangedFields
+	}
+	return nil
+}
+
+func (x *UserEvent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListUserEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*UserEvent           `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUserEventsResponse) Reset() {
+	*x = ListUserEventsResponse{}
+	mi := &file_proto_user_proto_msgTypes[239]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUserEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUserEventsResponse) ProtoMessage() {}
+
+func (x *ListUserEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[239]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUserEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListUserEventsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{239}
+}
+
+func (x *ListUserEventsResponse) GetEvents() []*UserEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *ListUserEventsResponse) GetTotalCount() int32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.TotalCount
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use DeleteProfileRequest.ProtoReflect.Descriptor instead.
-func (*DeleteProfileRequest) Descriptor() ([]byte, []int) {
-	return file_proto_user_proto_rawDescGZIP(), []int{11}
+func (x *ListUserEventsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
 }
 
-func (x *DeleteProfileRequest) GetUserId() string {
+func (x *ListUserEventsResponse) GetPageSize() int32 {
 	if x != nil {
-		return x.UserId
+		return x.PageSize
 	}
-	return ""
+	return 0
 }
 
-type DeleteProfileResponse struct {
+type GetReplicationStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteProfileResponse) Reset() {
-	*x = DeleteProfileResponse{}
-	mi := &file_proto_user_proto_msgTypes[12]
+func (x *GetReplicationStatusRequest) Reset() {
+	*x = GetReplicationStatusRequest{}
+	mi := &file_proto_user_proto_msgTypes[240]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteProfileResponse) String() string {
+func (x *GetReplicationStatusRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteProfileResponse) ProtoMessage() {}
+func (*GetReplicationStatusRequest) ProtoMessage() {}
 
-func (x *DeleteProfileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_user_proto_msgTypes[12]
+func (x *GetReplicationStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[240]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -705,43 +14379,39 @@ func (x *DeleteProfileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteProfileResponse.ProtoReflect.Descriptor instead.
-func (*DeleteProfileResponse) Descriptor() ([]byte, []int) {
-	return file_proto_user_proto_rawDescGZIP(), []int{12}
-}
-
-func (x *DeleteProfileResponse) GetMessage() string {
-	if x != nil {
-		return x.Message
-	}
-	return ""
+// Deprecated: Use GetReplicationStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetReplicationStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{240}
 }
 
-type ListUsersRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
-	NameFilter    string                 `protobuf:"bytes,3,opt,name=name_filter,json=nameFilter,proto3" json:"name_filter,omitempty"`
-	EmailFilter   string                 `protobuf:"bytes,4,opt,name=email_filter,json=emailFilter,proto3" json:"email_filter,omitempty"`
+type ReplicationMember struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Host   string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Region string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	// primary, secondary, arbiter or unknown
+	State string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	// Estimated replication lag behind the primary
+	LagMs         int64 `protobuf:"varint,4,opt,name=lag_ms,json=lagMs,proto3" json:"lag_ms,omitempty"`
+	RoundTripMs   int64 `protobuf:"varint,5,opt,name=round_trip_ms,json=roundTripMs,proto3" json:"round_trip_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListUsersRequest) Reset() {
-	*x = ListUsersRequest{}
-	mi := &file_proto_user_proto_msgTypes[13]
+func (x *ReplicationMember) Reset() {
+	*x = ReplicationMember{}
+	mi := &file_proto_user_proto_msgTypes[241]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListUsersRequest) String() string {
+func (x *ReplicationMember) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListUsersRequest) ProtoMessage() {}
+func (*ReplicationMember) ProtoMessage() {}
 
-func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_user_proto_msgTypes[13]
+func (x *ReplicationMember) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[241]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -752,64 +14422,83 @@ func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListUsersRequest.ProtoReflect.Descriptor instead.
-func (*ListUsersRequest) Descriptor() ([]byte, []int) {
-	return file_proto_user_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use ReplicationMember.ProtoReflect.Descriptor instead.
+func (*ReplicationMember) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{241}
 }
 
-func (x *ListUsersRequest) GetPage() int32 {
+func (x *ReplicationMember) GetHost() string {
 	if x != nil {
-		return x.Page
+		return x.Host
 	}
-	return 0
+	return ""
 }
 
-func (x *ListUsersRequest) GetPageSize() int32 {
+func (x *ReplicationMember) GetRegion() string {
 	if x != nil {
-		return x.PageSize
+		return x.Region
 	}
-	return 0
+	return ""
 }
 
-func (x *ListUsersRequest) GetNameFilter() string {
+func (x *ReplicationMember) GetState() string {
 	if x != nil {
-		return x.NameFilter
+		return x.State
 	}
 	return ""
 }
 
-func (x *ListUsersRequest) GetEmailFilter() string {
+func (x *ReplicationMember) GetLagMs() int64 {
 	if x != nil {
-		return x.EmailFilter
+		return x.LagMs
 	}
-	return ""
+	return 0
 }
 
-type ListUsersResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
-	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
-	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ReplicationMember) GetRoundTripMs() int64 {
+	if x != nil {
+		return x.RoundTripMs
+	}
+	return 0
 }
 
-func (x *ListUsersResponse) Reset() {
-	*x = ListUsersResponse{}
-	mi := &file_proto_user_proto_msgTypes[14]
+type GetReplicationStatusResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Region of this server
+	LocalRegion    string               `protobuf:"bytes,1,opt,name=local_region,json=localRegion,proto3" json:"local_region,omitempty"`
+	Primary        string               `protobuf:"bytes,2,opt,name=primary,proto3" json:"primary,omitempty"`
+	PrimaryRegion  string               `protobuf:"bytes,3,opt,name=primary_region,json=primaryRegion,proto3" json:"primary_region,omitempty"`
+	Members        []*ReplicationMember `protobuf:"bytes,4,rep,name=members,proto3" json:"members,omitempty"`
+	MajorityWrites bool                 `protobuf:"varint,5,opt,name=majority_writes,json=majorityWrites,proto3" json:"majority_writes,omitempty"`
+	// Largest lag of a secondary outside the primary's region
+	MaxRemoteLagMs int64 `protobuf:"varint,6,opt,name=max_remote_lag_ms,json=maxRemoteLagMs,proto3" json:"max_remote_lag_ms,omitempty"`
+	// Acknowledged writes that losing the primary's region could lose: zero
+	// with majority writes, otherwise max_remote_lag_ms
+	RpoMs int64 `protobuf:"varint,7,opt,name=rpo_ms,json=rpoMs,proto3" json:"rpo_ms,omitempty"`
+	// Start and length of the last period without a primary
+	LastFailoverAt         *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=last_failover_at,json=lastFailoverAt,proto3" json:"last_failover_at,omitempty"`
+	LastFailoverDurationMs int64                  `protobuf:"varint,9,opt,name=last_failover_duration_ms,json=lastFailoverDurationMs,proto3" json:"last_failover_duration_ms,omitempty"`
+	// Set while there is no primary
+	NoPrimarySince *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=no_primary_since,json=noPrimarySince,proto3" json:"no_primary_since,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetReplicationStatusResponse) Reset() {
+	*x = GetReplicationStatusResponse{}
+	mi := &file_proto_user_proto_msgTypes[242]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListUsersResponse) String() string {
+func (x *GetReplicationStatusResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListUsersResponse) ProtoMessage() {}
+func (*GetReplicationStatusResponse) ProtoMessage() {}
 
-func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_user_proto_msgTypes[14]
+func (x *GetReplicationStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[242]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -820,52 +14509,95 @@ func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListUsersResponse.ProtoReflect.Descriptor instead.
-func (*ListUsersResponse) Descriptor() ([]byte, []int) {
-	return file_proto_user_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use GetReplicationStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetReplicationStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{242}
 }
 
-func (x *ListUsersResponse) GetUsers() []*User {
+func (x *GetReplicationStatusResponse) GetLocalRegion() string {
 	if x != nil {
-		return x.Users
+		return x.LocalRegion
+	}
+	return ""
+}
+
+func (x *GetReplicationStatusResponse) GetPrimary() string {
+	if x != nil {
+		return x.Primary
+	}
+	return ""
+}
+
+func (x *GetReplicationStatusResponse) GetPrimaryRegion() string {
+	if x != nil {
+		return x.PrimaryRegion
+	}
+	return ""
+}
+
+func (x *GetReplicationStatusResponse) GetMembers() []*ReplicationMember {
+	if x != nil {
+		return x.Members
 	}
 	return nil
 }
 
-func (x *ListUsersResponse) GetTotalCount() int32 {
+func (x *GetReplicationStatusResponse) GetMajorityWrites() bool {
 	if x != nil {
-		return x.TotalCount
+		return x.MajorityWrites
+	}
+	return false
+}
+
+func (x *GetReplicationStatusResponse) GetMaxRemoteLagMs() int64 {
+	if x != nil {
+		return x.MaxRemoteLagMs
 	}
 	return 0
 }
 
-func (x *ListUsersResponse) GetPage() int32 {
+func (x *GetReplicationStatusResponse) GetRpoMs() int64 {
 	if x != nil {
-		return x.Page
+		return x.RpoMs
 	}
 	return 0
 }
 
-func (x *ListUsersResponse) GetPageSize() int32 {
+func (x *GetReplicationStatusResponse) GetLastFailoverAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.PageSize
+		return x.LastFailoverAt
+	}
+	return nil
+}
+
+func (x *GetReplicationStatusResponse) GetLastFailoverDurationMs() int64 {
+	if x != nil {
+		return x.LastFailoverDurationMs
 	}
 	return 0
 }
 
+func (x *GetReplicationStatusResponse) GetNoPrimarySince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NoPrimarySince
+	}
+	return nil
+}
+
 // Password change
 type ChangePasswordRequest struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	UserId          string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	CurrentPassword string                 `protobuf:"bytes,2,opt,name=current_password,json=currentPassword,proto3" json:"current_password,omitempty"`
-	NewPassword     string                 `protobuf:"bytes,3,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The caller when empty. Users only change their own password.
+	UserId          string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CurrentPassword string `protobuf:"bytes,2,opt,name=current_password,json=currentPassword,proto3" json:"current_password,omitempty"`
+	NewPassword     string `protobuf:"bytes,3,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
 
 func (x *ChangePasswordRequest) Reset() {
 	*x = ChangePasswordRequest{}
-	mi := &file_proto_user_proto_msgTypes[15]
+	mi := &file_proto_user_proto_msgTypes[243]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -877,7 +14609,7 @@ func (x *ChangePasswordRequest) String() string {
 func (*ChangePasswordRequest) ProtoMessage() {}
 
 func (x *ChangePasswordRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_user_proto_msgTypes[15]
+	mi := &file_proto_user_proto_msgTypes[243]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -890,7 +14622,7 @@ func (x *ChangePasswordRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChangePasswordRequest.ProtoReflect.Descriptor instead.
 func (*ChangePasswordRequest) Descriptor() ([]byte, []int) {
-	return file_proto_user_proto_rawDescGZIP(), []int{15}
+	return file_proto_user_proto_rawDescGZIP(), []int{243}
 }
 
 func (x *ChangePasswordRequest) GetUserId() string {
@@ -923,7 +14655,7 @@ type ChangePasswordResponse struct {
 
 func (x *ChangePasswordResponse) Reset() {
 	*x = ChangePasswordResponse{}
-	mi := &file_proto_user_proto_msgTypes[16]
+	mi := &file_proto_user_proto_msgTypes[244]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -935,7 +14667,7 @@ func (x *ChangePasswordResponse) String() string {
 func (*ChangePasswordResponse) ProtoMessage() {}
 
 func (x *ChangePasswordResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_user_proto_msgTypes[16]
+	mi := &file_proto_user_proto_msgTypes[244]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -948,7 +14680,7 @@ func (x *ChangePasswordResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChangePasswordResponse.ProtoReflect.Descriptor instead.
 func (*ChangePasswordResponse) Descriptor() ([]byte, []int) {
-	return file_proto_user_proto_rawDescGZIP(), []int{16}
+	return file_proto_user_proto_rawDescGZIP(), []int{244}
 }
 
 func (x *ChangePasswordResponse) GetMessage() string {
@@ -962,7 +14694,7 @@ var File_proto_user_proto protoreflect.FileDescriptor
 
 const file_proto_user_proto_rawDesc = "" +
 	"\n" +
-	"\x10proto/user.proto\x12\x04user\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf2\x01\n" +
+	"\x10proto/user.proto\x12\x04user\x1a\x19google/protobuf/any.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x14proto/contract.proto\"\xf2\x02\n" +
 	"\x04User\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
 	"\x05email\x18\x02 \x01(\tR\x05email\x12\x12\n" +
@@ -973,18 +14705,70 @@ const file_proto_user_proto_rawDesc = "" +
 	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1b\n" +
 	"\tis_active\x18\x06 \x01(\bR\bisActive\x12\x1d\n" +
 	"\n" +
-	"is_deleted\x18\a \x01(\bR\tisDeleted\"@\n" +
+	"is_deleted\x18\a \x01(\bR\tisDeleted\x12>\n" +
+	"\fexternal_ids\x18\b \x03(\v2\x1b.user.User.ExternalIdsEntryR\vexternalIds\x1a>\n" +
+	"\x10ExternalIdsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb9\x01\n" +
 	"\fLoginRequest\x12\x14\n" +
 	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"_\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12&\n" +
+	"\x0ftwo_factor_code\x18\x03 \x01(\tR\rtwoFactorCode\x12\"\n" +
+	"\rone_time_code\x18\x04 \x01(\tR\voneTimeCode\x12+\n" +
+	"\x11passkey_assertion\x18\x05 \x01(\tR\x10passkeyAssertion\"\x97\x04\n" +
 	"\rLoginResponse\x12\x14\n" +
 	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1e\n" +
 	"\x04user\x18\x02 \x01(\v2\n" +
 	".user.UserR\x04user\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"%\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12+\n" +
+	"\x11approval_required\x18\x04 \x01(\bR\x10approvalRequired\x12(\n" +
+	"\x10pending_login_id\x18\x05 \x01(\tR\x0ependingLoginId\x12C\n" +
+	"\x1etwo_factor_enrollment_required\x18\x06 \x01(\bR\x1btwoFactorEnrollmentRequired\x12_\n" +
+	"\x1etwo_factor_enrollment_deadline\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x1btwoFactorEnrollmentDeadline\x12E\n" +
+	"\x10security_context\x18\b \x01(\v2\x1a.user.LoginSecurityContextR\x0fsecurityContext\x12.\n" +
+	"\x13two_factor_required\x18\t \x01(\bR\x11twoFactorRequired\x12#\n" +
+	"\rrefresh_token\x18\n" +
+	" \x01(\tR\frefreshToken\x12\x1d\n" +
+	"\n" +
+	"new_device\x18\v \x01(\bR\tnewDevice\"\xde\x03\n" +
+	"\x14LoginSecurityContext\x12\x1d\n" +
+	"\n" +
+	"new_device\x18\x01 \x01(\bR\tnewDevice\x12&\n" +
+	"\x0ftwo_factor_used\x18\x02 \x01(\bR\rtwoFactorUsed\x12,\n" +
+	"\x12two_factor_enabled\x18\x03 \x01(\bR\x10twoFactorEnabled\x12J\n" +
+	"\x13password_changed_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x11passwordChangedAt\x12J\n" +
+	"\x13password_expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x11passwordExpiresAt\x12)\n" +
+	"\x10password_warning\x18\x06 \x01(\tR\x0fpasswordWarning\x12)\n" +
+	"\x10required_actions\x18\a \x03(\tR\x0frequiredActions\x12#\n" +
+	"\rterms_version\x18\b \x01(\tR\ftermsVersion\x12\x1b\n" +
+	"\tterms_url\x18\t \x01(\tR\btermsUrl\x12!\n" +
+	"\flogin_method\x18\n" +
+	" \x01(\tR\vloginMethod\",\n" +
+	"\x14SendLoginCodeRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"1\n" +
+	"\x15SendLoginCodeResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"0\n" +
+	"\x18BeginPasskeyLoginRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"\x7f\n" +
+	"\x19BeginPasskeyLoginResponse\x12'\n" +
+	"\x0frequest_options\x18\x01 \x01(\tR\x0erequestOptions\x129\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"%\n" +
 	"\rLogoutRequest\x12\x14\n" +
 	"\x05token\x18\x01 \x01(\tR\x05token\"*\n" +
 	"\x0eLogoutResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\":\n" +
+	"\x13RefreshTokenRequest\x12#\n" +
+	"\rrefresh_token\x18\x01 \x01(\tR\frefreshToken\"\xb5\x01\n" +
+	"\x14RefreshTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12#\n" +
+	"\rrefresh_token\x18\x02 \x01(\tR\frefreshToken\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12'\n" +
+	"\asession\x18\x04 \x01(\v2\r.user.SessionR\asession\"/\n" +
+	"\x17LogoutAllDevicesRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"4\n" +
+	"\x18LogoutAllDevicesResponse\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\"W\n" +
 	"\x0fRegisterRequest\x12\x14\n" +
 	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
@@ -993,7 +14777,85 @@ const file_proto_user_proto_rawDesc = "" +
 	"\x10RegisterResponse\x12\x1e\n" +
 	"\x04user\x18\x01 \x01(\v2\n" +
 	".user.UserR\x04user\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\",\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xd2\x01\n" +
+	"\fPendingLogin\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x02 \x01(\tR\tuserAgent\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x03 \x01(\tR\tipAddress\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"f\n" +
+	"\x13ApproveLoginRequest\x12(\n" +
+	"\x10pending_login_id\x18\x01 \x01(\tR\x0ependingLoginId\x12%\n" +
+	"\x0eapproval_token\x18\x02 \x01(\tR\rapprovalToken\"0\n" +
+	"\x14ApproveLoginResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\x1a\n" +
+	"\x18ListPendingLoginsRequest\"V\n" +
+	"\x19ListPendingLoginsResponse\x129\n" +
+	"\x0epending_logins\x18\x01 \x03(\v2\x12.user.PendingLoginR\rpendingLogins\"L\n" +
+	"\x17StartDeviceLoginRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x14\n" +
+	"\x05scope\x18\x02 \x01(\tR\x05scope\"\xfa\x01\n" +
+	"\x18StartDeviceLoginResponse\x12\x1f\n" +
+	"\vdevice_code\x18\x01 \x01(\tR\n" +
+	"deviceCode\x12\x1b\n" +
+	"\tuser_code\x18\x02 \x01(\tR\buserCode\x12)\n" +
+	"\x10verification_uri\x18\x03 \x01(\tR\x0fverificationUri\x12:\n" +
+	"\x19verification_uri_complete\x18\x04 \x01(\tR\x17verificationUriComplete\x12\x1d\n" +
+	"\n" +
+	"expires_in\x18\x05 \x01(\x05R\texpiresIn\x12\x1a\n" +
+	"\binterval\x18\x06 \x01(\x05R\binterval\"L\n" +
+	"\x19ApproveDeviceLoginRequest\x12\x1b\n" +
+	"\tuser_code\x18\x01 \x01(\tR\buserCode\x12\x12\n" +
+	"\x04deny\x18\x02 \x01(\bR\x04deny\"i\n" +
+	"\x1aApproveDeviceLoginResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x1b\n" +
+	"\tclient_id\x18\x02 \x01(\tR\bclientId\x12\x14\n" +
+	"\x05scope\x18\x03 \x01(\tR\x05scope\"V\n" +
+	"\x16PollDeviceLoginRequest\x12\x1f\n" +
+	"\vdevice_code\x18\x01 \x01(\tR\n" +
+	"deviceCode\x12\x1b\n" +
+	"\tclient_id\x18\x02 \x01(\tR\bclientId\"\x96\x03\n" +
+	"\x17PollDeviceLoginResponse\x12\x18\n" +
+	"\apending\x18\x01 \x01(\bR\apending\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12\x1e\n" +
+	"\x04user\x18\x03 \x01(\v2\n" +
+	".user.UserR\x04user\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x1a\n" +
+	"\binterval\x18\x06 \x01(\x05R\binterval\x12\x14\n" +
+	"\x05scope\x18\a \x01(\tR\x05scope\x12#\n" +
+	"\rrefresh_token\x18\b \x01(\tR\frefreshToken\x12C\n" +
+	"\x1etwo_factor_enrollment_required\x18\t \x01(\bR\x1btwoFactorEnrollmentRequired\x12_\n" +
+	"\x1etwo_factor_enrollment_deadline\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x1btwoFactorEnrollmentDeadline\"q\n" +
+	"\x18CreateActionTokenRequest\x12\x18\n" +
+	"\apurpose\x18\x01 \x01(\tR\apurpose\x12\x1a\n" +
+	"\bresource\x18\x02 \x01(\tR\bresource\x12\x1f\n" +
+	"\vttl_seconds\x18\x03 \x01(\x05R\n" +
+	"ttlSeconds\"l\n" +
+	"\x19CreateActionTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x129\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"b\n" +
+	"\x14ValidateTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x18\n" +
+	"\apurpose\x18\x02 \x01(\tR\apurpose\x12\x1a\n" +
+	"\bresource\x18\x03 \x01(\tR\bresource\"\x92\x02\n" +
+	"\x15ValidateTokenResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x18\n" +
+	"\apurpose\x18\x04 \x01(\tR\apurpose\x12\x1a\n" +
+	"\bresource\x18\x05 \x01(\tR\bresource\x129\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x15\n" +
+	"\x06org_id\x18\a \x01(\tR\x05orgId\x12\x14\n" +
+	"\x05roles\x18\b \x03(\tR\x05roles\x12\x16\n" +
+	"\x06active\x18\t \x01(\bR\x06active\",\n" +
 	"\x11GetProfileRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\"4\n" +
 	"\x12GetProfileResponse\x12\x1e\n" +
@@ -1002,45 +14864,1115 @@ const file_proto_user_proto_rawDesc = "" +
 	"\x14UpdateProfileRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05email\x18\x03 \x01(\tR\x05email\"Q\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\"\xa8\x01\n" +
 	"\x15UpdateProfileResponse\x12\x1e\n" +
 	"\x04user\x18\x01 \x01(\v2\n" +
 	".user.UserR\x04user\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"/\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12)\n" +
+	"\x10pending_approval\x18\x03 \x01(\bR\x0fpendingApproval\x12*\n" +
+	"\x11change_request_id\x18\x04 \x01(\tR\x0fchangeRequestId\"/\n" +
 	"\x14DeleteProfileRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"1\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"x\n" +
 	"\x15DeleteProfileResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"\x87\x01\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12E\n" +
+	"\x10restorable_until\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x0frestorableUntil\"I\n" +
+	"\x15RestoreProfileRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"2\n" +
+	"\x16RestoreProfileResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\xa0\x03\n" +
 	"\x10ListUsersRequest\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
 	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1f\n" +
 	"\vname_filter\x18\x03 \x01(\tR\n" +
 	"nameFilter\x12!\n" +
-	"\femail_filter\x18\x04 \x01(\tR\vemailFilter\"\x87\x01\n" +
+	"\femail_filter\x18\x04 \x01(\tR\vemailFilter\x12+\n" +
+	"\x11consistency_token\x18\x05 \x01(\tR\x10consistencyToken\x12 \n" +
+	"\tis_active\x18\x06 \x01(\bH\x00R\bisActive\x88\x01\x01\x12?\n" +
+	"\rcreated_after\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\x12\x17\n" +
+	"\asort_by\x18\t \x01(\tR\x06sortBy\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\n" +
+	" \x01(\tR\tsortOrderB\f\n" +
+	"\n" +
+	"_is_active\"\xb4\x01\n" +
 	"\x11ListUsersResponse\x12 \n" +
 	"\x05users\x18\x01 \x03(\v2\n" +
 	".user.UserR\x05users\x12\x1f\n" +
 	"\vtotal_count\x18\x02 \x01(\x05R\n" +
 	"totalCount\x12\x12\n" +
 	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"~\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12+\n" +
+	"\x11consistency_token\x18\x05 \x01(\tR\x10consistencyToken\"V\n" +
+	"\x15GetAuthOptionsRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12'\n" +
+	"\x0forganization_id\x18\x02 \x01(\tR\x0eorganizationId\"\x88\x01\n" +
+	"\n" +
+	"AuthMethod\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1a\n" +
+	"\brequired\x18\x02 \x01(\bR\brequired\x12'\n" +
+	"\x0forganization_id\x18\x03 \x01(\tR\x0eorganizationId\x12!\n" +
+	"\fredirect_url\x18\x04 \x01(\tR\vredirectUrl\"D\n" +
+	"\x16GetAuthOptionsResponse\x12*\n" +
+	"\amethods\x18\x01 \x03(\v2\x10.user.AuthMethodR\amethods\"C\n" +
+	"\x17CompleteSSOLoginRequest\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"\xf5\x01\n" +
+	"\x18CompleteSSOLoginResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1e\n" +
+	"\x04user\x18\x02 \x01(\v2\n" +
+	".user.UserR\x04user\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12E\n" +
+	"\x10security_context\x18\x04 \x01(\v2\x1a.user.LoginSecurityContextR\x0fsecurityContext\x12#\n" +
+	"\rrefresh_token\x18\x05 \x01(\tR\frefreshToken\x12\x1d\n" +
+	"\n" +
+	"new_device\x18\x06 \x01(\bR\tnewDevice\"t\n" +
+	"\x18LoginWithProviderRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12&\n" +
+	"\x0ftwo_factor_code\x18\x03 \x01(\tR\rtwoFactorCode\"\xe6\x03\n" +
+	"\x19LoginWithProviderResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1e\n" +
+	"\x04user\x18\x02 \x01(\v2\n" +
+	".user.UserR\x04user\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12E\n" +
+	"\x10security_context\x18\x04 \x01(\v2\x1a.user.LoginSecurityContextR\x0fsecurityContext\x12.\n" +
+	"\x13two_factor_required\x18\x05 \x01(\bR\x11twoFactorRequired\x12C\n" +
+	"\x1etwo_factor_enrollment_required\x18\x06 \x01(\bR\x1btwoFactorEnrollmentRequired\x12_\n" +
+	"\x1etwo_factor_enrollment_deadline\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x1btwoFactorEnrollmentDeadline\x12\x18\n" +
+	"\acreated\x18\b \x01(\bR\acreated\x12#\n" +
+	"\rrefresh_token\x18\t \x01(\tR\frefreshToken\x12\x1d\n" +
+	"\n" +
+	"new_device\x18\n" +
+	" \x01(\bR\tnewDevice\"\x1f\n" +
+	"\x1dStartEmailVerificationRequest\":\n" +
+	"\x1eStartEmailVerificationResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"(\n" +
+	"\x12VerifyEmailRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\"/\n" +
+	"\x13VerifyEmailResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\x19\n" +
+	"\x17StartSetPasswordRequest\"4\n" +
+	"\x18StartSetPasswordResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"K\n" +
+	"\x12SetPasswordRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12!\n" +
+	"\fnew_password\x18\x02 \x01(\tR\vnewPassword\"/\n" +
+	"\x13SetPasswordResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"3\n" +
+	"\x1bStartUnlockChallengeRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"8\n" +
+	"\x1cStartUnlockChallengeResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"b\n" +
+	"\x1aUnlockWithChallengeRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x1a\n" +
+	"\bpassword\x18\x03 \x01(\tR\bpassword\"7\n" +
+	"\x1bUnlockWithChallengeResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"3\n" +
+	"\x1bStartAccountRecoveryRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"8\n" +
+	"\x1cStartAccountRecoveryResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\xbb\x01\n" +
+	"\x14SecureAccountRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1d\n" +
+	"\n" +
+	"email_code\x18\x02 \x01(\tR\temailCode\x12#\n" +
+	"\rrecovery_code\x18\x03 \x01(\tR\frecoveryCode\x12!\n" +
+	"\fnew_password\x18\x04 \x01(\tR\vnewPassword\x12&\n" +
+	"\x0fkeep_device_ids\x18\x05 \x03(\tR\rkeepDeviceIds\"\xbe\x01\n" +
+	"\x15SecureAccountResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12'\n" +
+	"\x0fdevices_removed\x18\x02 \x01(\x05R\x0edevicesRemoved\x12(\n" +
+	"\x10two_factor_reset\x18\x03 \x01(\bR\x0etwoFactorReset\x128\n" +
+	"\x18recovery_codes_remaining\x18\x04 \x01(\x05R\x16recoveryCodesRemaining\"\x18\n" +
+	"\x16EnableTwoFactorRequest\"\\\n" +
+	"\x17EnableTwoFactorResponse\x12\x16\n" +
+	"\x06secret\x18\x01 \x01(\tR\x06secret\x12)\n" +
+	"\x10provisioning_uri\x18\x02 \x01(\tR\x0fprovisioningUri\",\n" +
+	"\x16VerifyTwoFactorRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\"n\n" +
+	"\x17VerifyTwoFactorResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12#\n" +
+	"\rrefresh_token\x18\x03 \x01(\tR\frefreshToken\"I\n" +
+	"\x17DisableTwoFactorRequest\x12\x1a\n" +
+	"\bpassword\x18\x01 \x01(\tR\bpassword\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"4\n" +
+	"\x18DisableTwoFactorResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\xd8\x01\n" +
+	"\aPasskey\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06aaguid\x18\x02 \x01(\tR\x06aaguid\x12-\n" +
+	"\x12attestation_format\x18\x03 \x01(\tR\x11attestationFormat\x12'\n" +
+	"\x0fbackup_eligible\x18\x04 \x01(\bR\x0ebackupEligible\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x12\n" +
+	"\x04name\x18\x06 \x01(\tR\x04name\"$\n" +
+	"\"BeginCredentialRegistrationRequest\"\x8b\x01\n" +
+	"#BeginCredentialRegistrationResponse\x12)\n" +
+	"\x10creation_options\x18\x01 \x01(\tR\x0fcreationOptions\x129\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"O\n" +
+	"\x19RegisterCredentialRequest\x12\x1e\n" +
+	"\n" +
+	"credential\x18\x01 \x01(\tR\n" +
+	"credential\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"E\n" +
+	"\x1aRegisterCredentialResponse\x12'\n" +
+	"\apasskey\x18\x01 \x01(\v2\r.user.PasskeyR\apasskey\"\x93\x02\n" +
+	"\n" +
+	"Credential\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12<\n" +
+	"\flast_used_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastUsedAt\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x06 \x01(\tR\tuserAgent\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\a \x01(\tR\tipAddress\x12\x16\n" +
+	"\x06aaguid\x18\b \x01(\tR\x06aaguid\"\x18\n" +
+	"\x16ListCredentialsRequest\"M\n" +
+	"\x17ListCredentialsResponse\x122\n" +
+	"\vcredentials\x18\x01 \x03(\v2\x10.user.CredentialR\vcredentials\"Q\n" +
+	"\x17RenameCredentialRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"L\n" +
+	"\x18RenameCredentialResponse\x120\n" +
+	"\n" +
+	"credential\x18\x01 \x01(\v2\x10.user.CredentialR\n" +
+	"credential\"=\n" +
+	"\x17DeleteCredentialRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\"4\n" +
+	"\x18DeleteCredentialResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\x87\x02\n" +
+	"\vKnownDevice\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x03 \x01(\tR\tuserAgent\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x04 \x01(\tR\tipAddress\x12>\n" +
+	"\rfirst_seen_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vfirstSeenAt\x12<\n" +
+	"\flast_seen_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastSeenAt\x12\x18\n" +
+	"\acurrent\x18\a \x01(\bR\acurrent\"J\n" +
+	"\x17ListKnownDevicesRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\"\x99\x01\n" +
+	"\x18ListKnownDevicesResponse\x12+\n" +
+	"\adevices\x18\x01 \x03(\v2\x11.user.KnownDeviceR\adevices\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"\x96\x02\n" +
+	"\x0eLinkedIdentity\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x16\n" +
+	"\x06issuer\x18\x02 \x01(\tR\x06issuer\x12\x18\n" +
+	"\asubject\x18\x03 \x01(\tR\asubject\x12\x14\n" +
+	"\x05email\x18\x04 \x01(\tR\x05email\x12'\n" +
+	"\x0forganization_id\x18\x05 \x01(\tR\x0eorganizationId\x127\n" +
+	"\tlinked_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\blinkedAt\x12>\n" +
+	"\rlast_login_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vlastLoginAt\"\x1d\n" +
+	"\x1bListLinkedIdentitiesRequest\"w\n" +
+	"\x1cListLinkedIdentitiesResponse\x124\n" +
+	"\n" +
+	"identities\x18\x01 \x03(\v2\x14.user.LinkedIdentityR\n" +
+	"identities\x12!\n" +
+	"\fhas_password\x18\x02 \x01(\bR\vhasPassword\"I\n" +
+	"\x15UnlinkIdentityRequest\x12\x16\n" +
+	"\x06issuer\x18\x01 \x01(\tR\x06issuer\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\"2\n" +
+	"\x16UnlinkIdentityResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\xf9\x02\n" +
+	"\aSession\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vdevice_name\x18\x02 \x01(\tR\n" +
+	"deviceName\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x03 \x01(\tR\tuserAgent\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x04 \x01(\tR\tipAddress\x127\n" +
+	"\tissued_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bissuedAt\x12D\n" +
+	"\x10last_activity_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x0elastActivityAt\x129\n" +
+	"\n" +
+	"expires_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x18\n" +
+	"\acurrent\x18\b \x01(\bR\acurrent\x12+\n" +
+	"\x11device_registered\x18\t \x01(\bR\x10deviceRegistered\"\x15\n" +
+	"\x13ListSessionsRequest\"A\n" +
+	"\x14ListSessionsResponse\x12)\n" +
+	"\bsessions\x18\x01 \x03(\v2\r.user.SessionR\bsessions\"5\n" +
+	"\x14RevokeSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"1\n" +
+	"\x15RevokeSessionResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\x15\n" +
+	"\x13WatchSessionRequest\"j\n" +
+	"\fSessionEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12.\n" +
+	"\x04time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"\xf8\x01\n" +
+	"\x06ApiKey\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06prefix\x18\x03 \x01(\tR\x06prefix\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12<\n" +
+	"\flast_used_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastUsedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"d\n" +
+	"\x13CreateApiKeyRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x129\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"O\n" +
+	"\x14CreateApiKeyResponse\x12%\n" +
+	"\aapi_key\x18\x01 \x01(\v2\f.user.ApiKeyR\x06apiKey\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\"\x14\n" +
+	"\x12ListApiKeysRequest\">\n" +
+	"\x13ListApiKeysResponse\x12'\n" +
+	"\bapi_keys\x18\x01 \x03(\v2\f.user.ApiKeyR\aapiKeys\"%\n" +
+	"\x13RevokeApiKeyRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"0\n" +
+	"\x14RevokeApiKeyResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\".\n" +
+	"\x12AcceptTermsRequest\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\"/\n" +
+	"\x13AcceptTermsResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"2\n" +
+	"\x1aStartTwoFactorResetRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"7\n" +
+	"\x1bStartTwoFactorResetResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\x8d\x01\n" +
+	"\x15ResetTwoFactorRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x1d\n" +
+	"\n" +
+	"email_code\x18\x03 \x01(\tR\temailCode\x12#\n" +
+	"\rrecovery_code\x18\x04 \x01(\tR\frecoveryCode\"q\n" +
+	"\x16ResetTwoFactorResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12=\n" +
+	"\feffective_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\veffectiveAt\"3\n" +
+	"\x1bCancelTwoFactorResetRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"8\n" +
+	"\x1cCancelTwoFactorResetResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"3\n" +
+	"\x1bRequestPasswordResetRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"8\n" +
+	"\x1cRequestPasswordResetResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"O\n" +
+	"\x14ResetPasswordRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12!\n" +
+	"\fnew_password\x18\x02 \x01(\tR\vnewPassword\"1\n" +
+	"\x15ResetPasswordResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"_\n" +
+	"\x17EvaluatePasswordRequest\x12\x1a\n" +
+	"\bpassword\x18\x01 \x01(\tR\bpassword\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"\x99\x02\n" +
+	"\x18EvaluatePasswordResponse\x12\x14\n" +
+	"\x05score\x18\x01 \x01(\x05R\x05score\x12,\n" +
+	"\x12crack_time_seconds\x18\x02 \x01(\x01R\x10crackTimeSeconds\x12,\n" +
+	"\x12crack_time_display\x18\x03 \x01(\tR\x10crackTimeDisplay\x12-\n" +
+	"\x12unmet_requirements\x18\x04 \x03(\tR\x11unmetRequirements\x12\"\n" +
+	"\frequirements\x18\x05 \x03(\tR\frequirements\x12\x1e\n" +
+	"\n" +
+	"acceptable\x18\x06 \x01(\bR\n" +
+	"acceptable\x12\x18\n" +
+	"\awarning\x18\a \x01(\tR\awarning\":\n" +
+	"\x1cGenerateRecoveryCodesRequest\x12\x1a\n" +
+	"\bpassword\x18\x01 \x01(\tR\bpassword\"`\n" +
+	"\x1dGenerateRecoveryCodesResponse\x12%\n" +
+	"\x0erecovery_codes\x18\x01 \x03(\tR\rrecoveryCodes\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x80\x02\n" +
+	"\x12OrganizationPolicy\x12E\n" +
+	"\x1frequire_profile_change_approval\x18\x01 \x01(\bR\x1crequireProfileChangeApproval\x12,\n" +
+	"\x12require_two_factor\x18\x02 \x01(\bR\x10requireTwoFactor\x12P\n" +
+	"\x17two_factor_grace_period\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x14twoFactorGracePeriod\x12#\n" +
+	"\rlogin_methods\x18\x04 \x03(\tR\floginMethods\"\xbd\x01\n" +
+	"\x0fOrganizationSSO\x12\x1a\n" +
+	"\bprotocol\x18\x01 \x01(\tR\bprotocol\x12\x16\n" +
+	"\x06issuer\x18\x02 \x01(\tR\x06issuer\x12\x1b\n" +
+	"\tclient_id\x18\x03 \x01(\tR\bclientId\x12#\n" +
+	"\rclient_secret\x18\x04 \x01(\tR\fclientSecret\x12\x18\n" +
+	"\adomains\x18\x05 \x03(\tR\adomains\x12\x1a\n" +
+	"\benforced\x18\x06 \x01(\bR\benforced\"\xd7\x02\n" +
+	"\x18OrganizationProvisioning\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12!\n" +
+	"\fdefault_role\x18\x02 \x01(\tR\vdefaultRole\x12!\n" +
+	"\fgroups_claim\x18\x03 \x01(\tR\vgroupsClaim\x12<\n" +
+	"\vgroup_roles\x18\x04 \x03(\v2\x1b.user.ProvisioningGroupRoleR\n" +
+	"groupRoles\x12;\n" +
+	"\n" +
+	"attributes\x18\x05 \x03(\v2\x1b.user.ProvisioningAttributeR\n" +
+	"attributes\x12\x1c\n" +
+	"\tblocklist\x18\x06 \x03(\tR\tblocklist\x12B\n" +
+	"\rrole_mappings\x18\a \x03(\v2\x1d.user.ProvisioningRoleMappingR\froleMappings\"A\n" +
+	"\x15ProvisioningGroupRole\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\"E\n" +
+	"\x17ProvisioningRoleMapping\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x14\n" +
+	"\x05roles\x18\x02 \x03(\tR\x05roles\"C\n" +
+	"\x15ProvisioningAttribute\x12\x14\n" +
+	"\x05claim\x18\x01 \x01(\tR\x05claim\x12\x14\n" +
+	"\x05field\x18\x02 \x01(\tR\x05field\"\xc7\x02\n" +
+	"\fOrganization\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x120\n" +
+	"\x06policy\x18\x03 \x01(\v2\x18.user.OrganizationPolicyR\x06policy\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12'\n" +
+	"\x03sso\x18\x06 \x01(\v2\x15.user.OrganizationSSOR\x03sso\x12B\n" +
+	"\fprovisioning\x18\a \x01(\v2\x1e.user.OrganizationProvisioningR\fprovisioning\"\x9e\x02\n" +
+	"\x14ProfileChangeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x04 \x01(\tR\x05email\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12#\n" +
+	"\rreject_reason\x18\x06 \x01(\tR\frejectReason\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12;\n" +
+	"\vreviewed_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"reviewedAt\"k\n" +
+	" ListProfileChangeRequestsRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"\xad\x01\n" +
+	"!ListProfileChangeRequestsResponse\x126\n" +
+	"\brequests\x18\x01 \x03(\v2\x1a.user.ProfileChangeRequestR\brequests\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"<\n" +
+	"\x1bApproveProfileChangeRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\"X\n" +
+	"\x1cApproveProfileChangeResponse\x12\x1e\n" +
+	"\x04user\x18\x01 \x01(\v2\n" +
+	".user.UserR\x04user\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"S\n" +
+	"\x1aRejectProfileChangeRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"7\n" +
+	"\x1bRejectProfileChangeResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\xe4\x01\n" +
+	"\x14NotificationTemplate\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\tR\x04body\x12K\n" +
+	"\vsample_data\x18\x04 \x03(\v2*.user.NotificationTemplate.SampleDataEntryR\n" +
+	"sampleData\x1a=\n" +
+	"\x0fSampleDataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\"\n" +
+	" ListNotificationTemplatesRequest\"]\n" +
+	"!ListNotificationTemplatesResponse\x128\n" +
+	"\ttemplates\x18\x01 \x03(\v2\x1a.user.NotificationTemplateR\ttemplates\"\xb9\x01\n" +
+	"\"PreviewNotificationTemplateRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12F\n" +
+	"\x04data\x18\x02 \x03(\v22.user.PreviewNotificationTemplateRequest.DataEntryR\x04data\x1a7\n" +
+	"\tDataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"S\n" +
+	"#PreviewNotificationTemplateResponse\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\x12\x12\n" +
+	"\x04body\x18\x02 \x01(\tR\x04body\"\xbb\x01\n" +
+	"\x1bSendTestNotificationRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12?\n" +
+	"\x04data\x18\x03 \x03(\v2+.user.SendTestNotificationRequest.DataEntryR\x04data\x1a7\n" +
+	"\tDataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"8\n" +
+	"\x1cSendTestNotificationResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\x8b\x02\n" +
+	"\x0fDeadLetterEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\tR\apayload\x12\x1a\n" +
+	"\battempts\x18\x04 \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12D\n" +
+	"\x10dead_lettered_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x0edeadLetteredAt\"b\n" +
+	"\x1bListDeadLetterEventsRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\"\x9f\x01\n" +
+	"\x1cListDeadLetterEventsResponse\x12-\n" +
+	"\x06events\x18\x01 \x03(\v2\x15.user.DeadLetterEventR\x06events\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"C\n" +
+	"\x1dReplayDeadLetterEventsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\x12\x10\n" +
+	"\x03all\x18\x02 \x01(\bR\x03all\"a\n" +
+	"\x1eReplayDeadLetterEventsResponse\x12%\n" +
+	"\x0ereplayed_count\x18\x01 \x01(\x05R\rreplayedCount\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x15\n" +
+	"\x13ExportConfigRequest\"2\n" +
+	"\x14ExportConfigResponse\x12\x1a\n" +
+	"\bsnapshot\x18\x01 \x01(\tR\bsnapshot\"J\n" +
+	"\x13ImportConfigRequest\x12\x1a\n" +
+	"\bsnapshot\x18\x01 \x01(\tR\bsnapshot\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"\xb5\x01\n" +
+	"\x14ImportConfigResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12-\n" +
+	"\x12source_environment\x18\x02 \x01(\tR\x11sourceEnvironment\x12)\n" +
+	"\x10changed_settings\x18\x03 \x03(\tR\x0fchangedSettings\x12)\n" +
+	"\x10restart_required\x18\x04 \x01(\bR\x0frestartRequired\"B\n" +
+	"\x0fLockUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\",\n" +
+	"\x10LockUserResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\",\n" +
+	"\x11UnlockUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\".\n" +
+	"\x12UnlockUserResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"H\n" +
+	"\x15DeactivateUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"]\n" +
+	"\x16DeactivateUserResponse\x12)\n" +
+	"\x10sessions_revoked\x18\x01 \x01(\x05R\x0fsessionsRevoked\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"0\n" +
+	"\x15ReactivateUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"2\n" +
+	"\x16ReactivateUserResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"c\n" +
+	"\x15HardDeleteUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x18\n" +
+	"\aconfirm\x18\x02 \x01(\bR\aconfirm\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\"\x9b\x01\n" +
+	"\x16HardDeleteUserResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x121\n" +
+	"\baffected\x18\x02 \x03(\v2\x15.user.AffectedRecordsR\baffected\x124\n" +
+	"\toperation\x18\x03 \x01(\v2\x16.user.PendingOperationR\toperation\"\x84\x01\n" +
+	"\x0fAffectedRecords\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12\x1c\n" +
+	"\toperation\x18\x02 \x01(\tR\toperation\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x03R\x05count\x12\x1d\n" +
+	"\n" +
+	"sample_ids\x18\x04 \x03(\tR\tsampleIds\"\xfb\x02\n" +
+	"\x10PendingOperation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x15\n" +
+	"\x06org_id\x18\x04 \x01(\tR\x05orgId\x12!\n" +
+	"\frequested_by\x18\x05 \x01(\tR\vrequestedBy\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x121\n" +
+	"\x06run_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x05runAt\x12=\n" +
+	"\fcompleted_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\x15\n" +
+	"\x06job_id\x18\n" +
+	" \x01(\tR\x05jobId\x12\x14\n" +
+	"\x05error\x18\v \x01(\tR\x05error\";\n" +
+	"\x16CancelOperationRequest\x12!\n" +
+	"\foperation_id\x18\x01 \x01(\tR\voperationId\"i\n" +
+	"\x17CancelOperationResponse\x124\n" +
+	"\toperation\x18\x01 \x01(\v2\x16.user.PendingOperationR\toperation\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xde\x03\n" +
+	"\x0fScheduledAction\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12!\n" +
+	"\frequested_by\x18\x05 \x01(\tR\vrequestedBy\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x121\n" +
+	"\x06run_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x05runAt\x127\n" +
+	"\tnotify_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\bnotifyAt\x12;\n" +
+	"\vnotified_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"notifiedAt\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12=\n" +
+	"\fcompleted_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\x14\n" +
+	"\x05error\x18\f \x01(\tR\x05error\"\x93\x01\n" +
+	"\x15ScheduleActionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x121\n" +
+	"\x06run_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05runAt\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"t\n" +
+	"\x16ScheduleActionResponse\x12@\n" +
+	"\x10scheduled_action\x18\x01 \x01(\v2\x15.user.ScheduledActionR\x0fscheduledAction\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\";\n" +
+	"\x1cCancelScheduledActionRequest\x12\x1b\n" +
+	"\taction_id\x18\x01 \x01(\tR\bactionId\"{\n" +
+	"\x1dCancelScheduledActionResponse\x12@\n" +
+	"\x10scheduled_action\x18\x01 \x01(\v2\x15.user.ScheduledActionR\x0fscheduledAction\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x7f\n" +
+	"\x1bListScheduledActionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"\xb4\x01\n" +
+	"\x1cListScheduledActionsResponse\x12B\n" +
+	"\x11scheduled_actions\x18\x01 \x03(\v2\x15.user.ScheduledActionR\x10scheduledActions\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"\xb5\x03\n" +
+	"\bWorkflow\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12!\n" +
+	"\frequested_by\x18\x04 \x01(\tR\vrequestedBy\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12'\n" +
+	"\x0fcompleted_steps\x18\x06 \x03(\tR\x0ecompletedSteps\x12!\n" +
+	"\fcurrent_step\x18\a \x01(\tR\vcurrentStep\x12\x1a\n" +
+	"\battempts\x18\b \x01(\x05R\battempts\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12=\n" +
+	"\fcompleted_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\"E\n" +
+	"\x10EraseUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x18\n" +
+	"\aconfirm\x18\x02 \x01(\bR\aconfirm\"Y\n" +
+	"\x11EraseUserResponse\x12*\n" +
+	"\bworkflow\x18\x01 \x01(\v2\x0e.user.WorkflowR\bworkflow\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"5\n" +
+	"\x12GetWorkflowRequest\x12\x1f\n" +
+	"\vworkflow_id\x18\x01 \x01(\tR\n" +
+	"workflowId\"A\n" +
+	"\x13GetWorkflowResponse\x12*\n" +
+	"\bworkflow\x18\x01 \x01(\v2\x0e.user.WorkflowR\bworkflow\"\x8c\x01\n" +
+	"\x14ListWorkflowsRequest\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04page\x18\x04 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\"\x97\x01\n" +
+	"\x15ListWorkflowsResponse\x12,\n" +
+	"\tworkflows\x18\x01 \x03(\v2\x0e.user.WorkflowR\tworkflows\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"7\n" +
+	"\x14RetryWorkflowRequest\x12\x1f\n" +
+	"\vworkflow_id\x18\x01 \x01(\tR\n" +
+	"workflowId\"]\n" +
+	"\x15RetryWorkflowResponse\x12*\n" +
+	"\bworkflow\x18\x01 \x01(\v2\x0e.user.WorkflowR\bworkflow\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"3\n" +
+	"\x18ResetUserPasswordRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x9b\x01\n" +
+	"\x19ResetUserPasswordResponse\x129\n" +
+	"\n" +
+	"expires_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12)\n" +
+	"\x10sessions_revoked\x18\x02 \x01(\x05R\x0fsessionsRevoked\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"1\n" +
+	"\x16GetLoginHistoryRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"w\n" +
+	"\vLoginRecord\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x01 \x01(\tR\tipAddress\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x02 \x01(\tR\tuserAgent\x12*\n" +
+	"\x02at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\"\xa5\x01\n" +
+	"\x17GetLoginHistoryResponse\x12)\n" +
+	"\x06logins\x18\x01 \x03(\v2\x11.user.LoginRecordR\x06logins\x12\x1f\n" +
+	"\vlogin_count\x18\x02 \x01(\x03R\n" +
+	"loginCount\x12>\n" +
+	"\rlast_login_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vlastLoginAt\"F\n" +
+	"\x12ForceLogoutRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"\x8d\x01\n" +
+	"\x13ForceLogoutResponse\x12)\n" +
+	"\x10sessions_revoked\x18\x01 \x01(\x05R\x0fsessionsRevoked\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x121\n" +
+	"\baffected\x18\x03 \x03(\v2\x15.user.AffectedRecordsR\baffected\"a\n" +
+	"\x19CreateOrganizationRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x120\n" +
+	"\x06policy\x18\x02 \x01(\v2\x18.user.OrganizationPolicyR\x06policy\"T\n" +
+	"\x1aCreateOrganizationResponse\x126\n" +
+	"\forganization\x18\x01 \x01(\v2\x12.user.OrganizationR\forganization\"j\n" +
+	"\x1fUpdateOrganizationPolicyRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x120\n" +
+	"\x06policy\x18\x02 \x01(\v2\x18.user.OrganizationPolicyR\x06policy\"Z\n" +
+	" UpdateOrganizationPolicyResponse\x126\n" +
+	"\forganization\x18\x01 \x01(\v2\x12.user.OrganizationR\forganization\"\x8c\x01\n" +
+	"\x19SetOrganizationSSORequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12'\n" +
+	"\x03sso\x18\x02 \x01(\v2\x15.user.OrganizationSSOR\x03sso\x12/\n" +
+	"\x13deprovision_members\x18\x03 \x01(\bR\x12deprovisionMembers\"\xb5\x01\n" +
+	"\x1aSetOrganizationSSOResponse\x126\n" +
+	"\forganization\x18\x01 \x01(\v2\x12.user.OrganizationR\forganization\x12)\n" +
+	"\x03job\x18\x02 \x01(\v2\x17.user.DeprovisioningJobR\x03job\x124\n" +
+	"\toperation\x18\x03 \x01(\v2\x16.user.PendingOperationR\toperation\"\xbd\x01\n" +
+	"\"SetOrganizationProvisioningRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12B\n" +
+	"\fprovisioning\x18\x02 \x01(\v2\x1e.user.OrganizationProvisioningR\fprovisioning\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\x12#\n" +
+	"\rsample_claims\x18\x04 \x03(\tR\fsampleClaims\"\xd8\x02\n" +
+	"\x14ProvisioningDecision\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x18\n" +
+	"\aallowed\x18\x02 \x01(\bR\aallowed\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x19\n" +
+	"\borg_role\x18\x04 \x01(\tR\aorgRole\x12#\n" +
+	"\rmatched_group\x18\x05 \x01(\tR\fmatchedGroup\x12\x12\n" +
+	"\x04name\x18\x06 \x01(\tR\x04name\x12N\n" +
+	"\fexternal_ids\x18\a \x03(\v2+.user.ProvisioningDecision.ExternalIdsEntryR\vexternalIds\x12\x14\n" +
+	"\x05roles\x18\b \x03(\tR\x05roles\x1a>\n" +
+	"\x10ExternalIdsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb1\x01\n" +
+	"#SetOrganizationProvisioningResponse\x126\n" +
+	"\forganization\x18\x01 \x01(\v2\x12.user.OrganizationR\forganization\x128\n" +
+	"\tdecisions\x18\x02 \x03(\v2\x1a.user.ProvisioningDecisionR\tdecisions\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\xe5\x02\n" +
+	"\x11DeprovisioningJob\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12=\n" +
+	"\fcompleted_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12 \n" +
+	"\vdeactivated\x18\a \x01(\x05R\vdeactivated\x12)\n" +
+	"\x10sessions_revoked\x18\b \x01(\x05R\x0fsessionsRevoked\x122\n" +
+	"\askipped\x18\t \x03(\v2\x18.user.DeprovisioningSkipR\askipped\"E\n" +
+	"\x12DeprovisioningSkip\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x98\x01\n" +
+	"%DeprovisionOrganizationMembersRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x1a\n" +
+	"\bsubjects\x18\x02 \x03(\tR\bsubjects\x12<\n" +
+	"\finactive_for\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\vinactiveFor\"\x89\x01\n" +
+	"&DeprovisionOrganizationMembersResponse\x12)\n" +
+	"\x03job\x18\x01 \x01(\v2\x17.user.DeprovisioningJobR\x03job\x124\n" +
+	"\toperation\x18\x02 \x01(\v2\x16.user.PendingOperationR\toperation\"4\n" +
+	"\x1bGetDeprovisioningJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"I\n" +
+	"\x1cGetDeprovisioningJobResponse\x12)\n" +
+	"\x03job\x18\x01 \x01(\v2\x17.user.DeprovisioningJobR\x03job\"b\n" +
+	"\x1cSetOrganizationMemberRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\"9\n" +
+	"\x1dSetOrganizationMemberResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"P\n" +
+	"\x1dDestroyOrganizationKeyRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x18\n" +
+	"\aconfirm\x18\x02 \x01(\bR\aconfirm\"y\n" +
+	"\x1eDestroyOrganizationKeyResponse\x12=\n" +
+	"\fdestroyed_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\vdestroyedAt\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"h\n" +
+	"\x14SetExternalIdRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06system\x18\x02 \x01(\tR\x06system\x12\x1f\n" +
+	"\vexternal_id\x18\x03 \x01(\tR\n" +
+	"externalId\"Q\n" +
+	"\x15SetExternalIdResponse\x12\x1e\n" +
+	"\x04user\x18\x01 \x01(\v2\n" +
+	".user.UserR\x04user\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"U\n" +
+	"\x1aGetUserByExternalIdRequest\x12\x16\n" +
+	"\x06system\x18\x01 \x01(\tR\x06system\x12\x1f\n" +
+	"\vexternal_id\x18\x02 \x01(\tR\n" +
+	"externalId\"=\n" +
+	"\x1bGetUserByExternalIdResponse\x12\x1e\n" +
+	"\x04user\x18\x01 \x01(\v2\n" +
+	".user.UserR\x04user\"1\n" +
+	"\x14GetUsersByIdsRequest\x12\x19\n" +
+	"\buser_ids\x18\x01 \x03(\tR\auserIds\"Z\n" +
+	"\x15GetUsersByIdsResponse\x12 \n" +
+	"\x05users\x18\x01 \x03(\v2\n" +
+	".user.UserR\x05users\x12\x1f\n" +
+	"\vmissing_ids\x18\x02 \x03(\tR\n" +
+	"missingIds\"\xaf\x02\n" +
+	"\x12ExportUsersRequest\x12\x1f\n" +
+	"\vname_filter\x18\x01 \x01(\tR\n" +
+	"nameFilter\x12!\n" +
+	"\femail_filter\x18\x02 \x01(\tR\vemailFilter\x12 \n" +
+	"\tis_active\x18\x03 \x01(\bH\x00R\bisActive\x88\x01\x01\x12?\n" +
+	"\rcreated_after\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\x12!\n" +
+	"\fresume_after\x18\x06 \x01(\tR\vresumeAfterB\f\n" +
+	"\n" +
+	"_is_active\"\xa4\x02\n" +
+	"\n" +
+	"ImportUser\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12#\n" +
+	"\rpassword_hash\x18\x03 \x01(\tR\fpasswordHash\x12D\n" +
+	"\fexternal_ids\x18\x04 \x03(\v2!.user.ImportUser.ExternalIdsEntryR\vexternalIds\x12\x1a\n" +
+	"\bdisabled\x18\x05 \x01(\bR\bdisabled\x12%\n" +
+	"\x0eemail_verified\x18\x06 \x01(\bR\remailVerified\x1a>\n" +
+	"\x10ExternalIdsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"k\n" +
+	"\x12ImportUsersRequest\x12&\n" +
+	"\x05users\x18\x01 \x03(\v2\x10.user.ImportUserR\x05users\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05async\x18\x03 \x01(\bR\x05async\"W\n" +
+	"\x11SkippedImportUser\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\xad\x01\n" +
+	"\x13ImportUsersResponse\x12\x1a\n" +
+	"\bimported\x18\x01 \x01(\x05R\bimported\x121\n" +
+	"\askipped\x18\x02 \x03(\v2\x17.user.SkippedImportUserR\askipped\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12-\n" +
+	"\toperation\x18\x04 \x01(\v2\x0f.user.OperationR\toperation\"\xbb\x03\n" +
+	"\tOperation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12!\n" +
+	"\frequested_by\x18\x03 \x01(\tR\vrequestedBy\x12\x12\n" +
+	"\x04done\x18\x04 \x01(\bR\x04done\x12\x1c\n" +
+	"\tprocessed\x18\x05 \x01(\x03R\tprocessed\x12\x14\n" +
+	"\x05total\x18\x06 \x01(\x03R\x05total\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12=\n" +
+	"\fcompleted_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12,\n" +
+	"\x05error\x18\n" +
+	" \x01(\v2\x14.user.OperationErrorH\x00R\x05error\x122\n" +
+	"\bresponse\x18\v \x01(\v2\x14.google.protobuf.AnyH\x00R\bresponseB\b\n" +
+	"\x06result\">\n" +
+	"\x0eOperationError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"8\n" +
+	"\x13GetOperationRequest\x12!\n" +
+	"\foperation_id\x18\x01 \x01(\tR\voperationId\"E\n" +
+	"\x14GetOperationResponse\x12-\n" +
+	"\toperation\x18\x01 \x01(\v2\x0f.user.OperationR\toperation\"\\\n" +
+	"\x15ListOperationsRequest\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"\x9b\x01\n" +
+	"\x16ListOperationsResponse\x12/\n" +
+	"\n" +
+	"operations\x18\x01 \x03(\v2\x0f.user.OperationR\n" +
+	"operations\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\":\n" +
+	"\x15WatchOperationRequest\x12!\n" +
+	"\foperation_id\x18\x01 \x01(\tR\voperationId\"\x17\n" +
+	"\x15RebuildIndexesRequest\"G\n" +
+	"\x16RebuildIndexesResponse\x12-\n" +
+	"\toperation\x18\x01 \x01(\v2\x0f.user.OperationR\toperation\"\x18\n" +
+	"\x16RunAccountPurgeRequest\"H\n" +
+	"\x17RunAccountPurgeResponse\x12-\n" +
+	"\toperation\x18\x01 \x01(\v2\x0f.user.OperationR\toperation\",\n" +
+	"\x12AccountPurgeResult\x12\x16\n" +
+	"\x06purged\x18\x01 \x01(\x05R\x06purged\"\x8e\x01\n" +
+	"\x15ReplayAuditLogRequest\x12 \n" +
+	"\vprojections\x18\x01 \x03(\tR\vprojections\x12\x19\n" +
+	"\bafter_id\x18\x02 \x01(\tR\aafterId\x12\x19\n" +
+	"\buntil_id\x18\x03 \x01(\tR\auntilId\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x04 \x01(\x05R\tbatchSize\"\xc0\x01\n" +
+	"\x16ReplayAuditLogResponse\x12\x1c\n" +
+	"\tprocessed\x18\x01 \x01(\x05R\tprocessed\x12\x19\n" +
+	"\bafter_id\x18\x02 \x01(\tR\aafterId\x12\x19\n" +
+	"\buntil_id\x18\x03 \x01(\tR\auntilId\x12\x1c\n" +
+	"\tremaining\x18\x04 \x01(\x03R\tremaining\x12\x12\n" +
+	"\x04done\x18\x05 \x01(\bR\x04done\x12 \n" +
+	"\vprojections\x18\x06 \x03(\tR\vprojections\"\xfa\x01\n" +
+	"\x13GetAuditLogsRequest\x12\x1b\n" +
+	"\ttarget_id\x18\x01 \x01(\tR\btargetId\x12\x19\n" +
+	"\bactor_id\x18\x02 \x01(\tR\aactorId\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x120\n" +
+	"\x05since\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\x12\x12\n" +
+	"\x04page\x18\x06 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\a \x01(\x05R\bpageSize\"Q\n" +
+	"\vAuditChange\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x16\n" +
+	"\x06before\x18\x02 \x01(\tR\x06before\x12\x14\n" +
+	"\x05after\x18\x03 \x01(\tR\x05after\"\x8d\x03\n" +
+	"\rAuditLogEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x19\n" +
+	"\bactor_id\x18\x03 \x01(\tR\aactorId\x12\x1b\n" +
+	"\ttarget_id\x18\x04 \x01(\tR\btargetId\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x05 \x01(\tR\tipAddress\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x06 \x01(\tR\tuserAgent\x12:\n" +
+	"\adetails\x18\a \x03(\v2 .user.AuditLogEntry.DetailsEntryR\adetails\x12+\n" +
+	"\achanges\x18\b \x03(\v2\x11.user.AuditChangeR\achanges\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x1a:\n" +
+	"\fDetailsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x97\x01\n" +
+	"\x14GetAuditLogsResponse\x12-\n" +
+	"\aentries\x18\x01 \x03(\v2\x13.user.AuditLogEntryR\aentries\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"W\n" +
+	"\x10GetUserAtRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12*\n" +
+	"\x02at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\"M\n" +
+	"\x11GetUserAtResponse\x12\x1e\n" +
+	"\x04user\x18\x01 \x01(\v2\n" +
+	".user.UserR\x04user\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\"a\n" +
+	"\x15ListUserEventsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"\x9b\x01\n" +
+	"\tUserEvent\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12%\n" +
+	"\x0echanged_fields\x18\x03 \x03(\tR\rchangedFields\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x93\x01\n" +
+	"\x16ListUserEventsResponse\x12'\n" +
+	"\x06events\x18\x01 \x03(\v2\x0f.user.UserEventR\x06events\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"\x1d\n" +
+	"\x1bGetReplicationStatusRequest\"\x90\x01\n" +
+	"\x11ReplicationMember\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x15\n" +
+	"\x06lag_ms\x18\x04 \x01(\x03R\x05lagMs\x12\"\n" +
+	"\rround_trip_ms\x18\x05 \x01(\x03R\vroundTripMs\"\xe7\x03\n" +
+	"\x1cGetReplicationStatusResponse\x12!\n" +
+	"\flocal_region\x18\x01 \x01(\tR\vlocalRegion\x12\x18\n" +
+	"\aprimary\x18\x02 \x01(\tR\aprimary\x12%\n" +
+	"\x0eprimary_region\x18\x03 \x01(\tR\rprimaryRegion\x121\n" +
+	"\amembers\x18\x04 \x03(\v2\x17.user.ReplicationMemberR\amembers\x12'\n" +
+	"\x0fmajority_writes\x18\x05 \x01(\bR\x0emajorityWrites\x12)\n" +
+	"\x11max_remote_lag_ms\x18\x06 \x01(\x03R\x0emaxRemoteLagMs\x12\x15\n" +
+	"\x06rpo_ms\x18\a \x01(\x03R\x05rpoMs\x12D\n" +
+	"\x10last_failover_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x0elastFailoverAt\x129\n" +
+	"\x19last_failover_duration_ms\x18\t \x01(\x03R\x16lastFailoverDurationMs\x12D\n" +
+	"\x10no_primary_since\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x0enoPrimarySince\"~\n" +
 	"\x15ChangePasswordRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12)\n" +
 	"\x10current_password\x18\x02 \x01(\tR\x0fcurrentPassword\x12!\n" +
 	"\fnew_password\x18\x03 \x01(\tR\vnewPassword\"2\n" +
 	"\x16ChangePasswordResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage2\xaf\x01\n" +
-	"\vAuthService\x120\n" +
-	"\x05Login\x12\x12.user.LoginRequest\x1a\x13.user.LoginResponse\x123\n" +
-	"\x06Logout\x12\x13.user.LogoutRequest\x1a\x14.user.LogoutResponse\x129\n" +
-	"\bRegister\x12\x15.user.RegisterRequest\x1a\x16.user.RegisterResponse2\xed\x02\n" +
-	"\vUserService\x12?\n" +
-	"\n" +
-	"GetProfile\x12\x17.user.GetProfileRequest\x1a\x18.user.GetProfileResponse\x12H\n" +
-	"\rUpdateProfile\x12\x1a.user.UpdateProfileRequest\x1a\x1b.user.UpdateProfileResponse\x12H\n" +
-	"\rDeleteProfile\x12\x1a.user.DeleteProfileRequest\x1a\x1b.user.DeleteProfileResponse\x12<\n" +
-	"\tListUsers\x12\x16.user.ListUsersRequest\x1a\x17.user.ListUsersResponse\x12K\n" +
-	"\x0eChangePassword\x12\x1b.user.ChangePasswordRequest\x1a\x1c.user.ChangePasswordResponseB\bZ\x06./userb\x06proto3"
+	"\amessage\x18\x01 \x01(\tR\amessage2\x9e*\n" +
+	"\vAuthService\x12\xe1\x01\n" +
+	"\x05Login\x12\x12.user.LoginRequest\x1a\x13.user.LoginResponse\"\xae\x01\xc2\xf3\x18\xa9\x01\"X\n" +
+	"\x18rejects an invalid email\x12*{\"email\": \"not-an-email\", \"password\": \"x\"}\x1a\x10INVALID_ARGUMENT\"M\n" +
+	"\x1arejects a missing password\x12\x1d{\"email\": \"user@example.com\"}\x1a\x10INVALID_ARGUMENT\x12\x85\x02\n" +
+	"\x0eRestoreProfile\x12\x1b.user.RestoreProfileRequest\x1a\x1c.user.RestoreProfileResponse\"\xb7\x01\xc2\xf3\x18\xb2\x01\"a\n" +
+	"\x18rejects an invalid email\x123{\"email\": \"not-an-email\", \"password\": \"Password1!\"}\x1a\x10INVALID_ARGUMENT\"M\n" +
+	"\x1arejects a missing password\x12\x1d{\"email\": \"user@example.com\"}\x1a\x10INVALID_ARGUMENT\x12\x97\x01\n" +
+	"\rSendLoginCode\x12\x1a.user.SendLoginCodeRequest\x1a\x1b.user.SendLoginCodeResponse\"M\xc2\xf3\x18I\"G\n" +
+	"\x18rejects an invalid email\x12\x19{\"email\": \"not-an-email\"}\x1a\x10INVALID_ARGUMENT\x12\xa3\x01\n" +
+	"\x11BeginPasskeyLogin\x12\x1e.user.BeginPasskeyLoginRequest\x1a\x1f.user.BeginPasskeyLoginResponse\"M\xc2\xf3\x18I\"G\n" +
+	"\x18rejects an invalid email\x12\x19{\"email\": \"not-an-email\"}\x1a\x10INVALID_ARGUMENT\x12\xb1\x01\n" +
+	"\x06Logout\x12\x13.user.LogoutRequest\x1a\x14.user.LogoutResponse\"|\xc2\xf3\x18x\"/\n" +
+	"\x17rejects a missing token\x12\x02{}\x1a\x10INVALID_ARGUMENT\"E\n" +
+	"\x18rejects an invalid token\x12\x18{\"token\": \"not-a-token\"}\x1a\x0fUNAUTHENTICATED\x12\xe5\x01\n" +
+	"\fRefreshToken\x12\x19.user.RefreshTokenRequest\x1a\x1a.user.RefreshTokenResponse\"\x9d\x01\xc2\xf3\x18\x98\x01\"7\n" +
+	"\x1frejects a missing refresh token\x12\x02{}\x1a\x10INVALID_ARGUMENT\"]\n" +
+	" rejects an unknown refresh token\x12({\"refresh_token\": \"not-a-refresh-token\"}\x1a\x0fUNAUTHENTICATED\x12\xcf\x01\n" +
+	"\x10LogoutAllDevices\x12\x1d.user.LogoutAllDevicesRequest\x1a\x1e.user.LogoutAllDevicesResponse\"|\xc2\xf3\x18x\"/\n" +
+	"\x17rejects a missing token\x12\x02{}\x1a\x10INVALID_ARGUMENT\"E\n" +
+	"\x18rejects an invalid token\x12\x18{\"token\": \"not-a-token\"}\x1a\x0fUNAUTHENTICATED\x12\xb2\x02\n" +
+	"\bRegister\x12\x15.user.RegisterRequest\x1a\x16.user.RegisterResponse\"\xf6\x01\xc2\xf3\x18\xf1\x01\"v\n" +
+	"\x18rejects an invalid email\x12H{\"email\": \"not-an-email\", \"password\": \"Password1!\", \"name\": \"Test User\"}\x1a\x10INVALID_ARGUMENT\"w\n" +
+	"\x17rejects a weak password\x12J{\"email\": \"user@example.com\", \"password\": \"password\", \"name\": \"Test User\"}\x1a\x10INVALID_ARGUMENT\x12E\n" +
+	"\fApproveLogin\x12\x19.user.ApproveLoginRequest\x1a\x1a.user.ApproveLoginResponse\x12\\\n" +
+	"\x11ListPendingLogins\x12\x1e.user.ListPendingLoginsRequest\x1a\x1f.user.ListPendingLoginsResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12Q\n" +
+	"\x10StartDeviceLogin\x12\x1d.user.StartDeviceLoginRequest\x1a\x1e.user.StartDeviceLoginResponse\x12{\n" +
+	"\x12ApproveDeviceLogin\x12\x1f.user.ApproveDeviceLoginRequest\x1a .user.ApproveDeviceLoginResponse\"\"\xc2\xf3\x18\x1e\b\x01\x1a\x1a{\"user_code\": \"ABCD-EFGH\"}\x12N\n" +
+	"\x0fPollDeviceLogin\x12\x1c.user.PollDeviceLoginRequest\x1a\x1d.user.PollDeviceLoginResponse\x12\\\n" +
+	"\x11CreateActionToken\x12\x1e.user.CreateActionTokenRequest\x1a\x1f.user.CreateActionTokenResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12\x7f\n" +
+	"\rValidateToken\x12\x1a.user.ValidateTokenRequest\x1a\x1b.user.ValidateTokenResponse\"5\xc2\xf3\x181\"/\n" +
+	"\x17rejects a missing token\x12\x02{}\x1a\x10INVALID_ARGUMENT\x12]\n" +
+	"\x14StartUnlockChallenge\x12!.user.StartUnlockChallengeRequest\x1a\".user.StartUnlockChallengeResponse\x12Z\n" +
+	"\x13UnlockWithChallenge\x12 .user.UnlockWithChallengeRequest\x1a!.user.UnlockWithChallengeResponse\x12]\n" +
+	"\x14StartAccountRecovery\x12!.user.StartAccountRecoveryRequest\x1a\".user.StartAccountRecoveryResponse\x12H\n" +
+	"\rSecureAccount\x12\x1a.user.SecureAccountRequest\x1a\x1b.user.SecureAccountResponse\x12\xa9\x01\n" +
+	"\x13StartTwoFactorReset\x12 .user.StartTwoFactorResetRequest\x1a!.user.StartTwoFactorResetResponse\"M\xc2\xf3\x18I\"G\n" +
+	"\x18rejects an invalid email\x12\x19{\"email\": \"not-an-email\"}\x1a\x10INVALID_ARGUMENT\x12\xa7\x02\n" +
+	"\x0eResetTwoFactor\x12\x1b.user.ResetTwoFactorRequest\x1a\x1c.user.ResetTwoFactorResponse\"\xd9\x01\xc2\xf3\x18\xd4\x01\"e\n" +
+	"\x1arejects a missing password\x125{\"email\": \"user@example.com\", \"email_code\": \"123456\"}\x1a\x10INVALID_ARGUMENT\"k\n" +
+	"\x1erejects a missing second proof\x127{\"email\": \"user@example.com\", \"password\": \"Password1!\"}\x1a\x10INVALID_ARGUMENT\x12\xdc\x01\n" +
+	"\x14CancelTwoFactorReset\x12!.user.CancelTwoFactorResetRequest\x1a\".user.CancelTwoFactorResetResponse\"}\xc2\xf3\x18y\"/\n" +
+	"\x17rejects a missing token\x12\x02{}\x1a\x10INVALID_ARGUMENT\"F\n" +
+	"\x18rejects an unknown token\x12\x18{\"token\": \"not-a-token\"}\x1a\x10INVALID_ARGUMENT\x12\xac\x01\n" +
+	"\x14RequestPasswordReset\x12!.user.RequestPasswordResetRequest\x1a\".user.RequestPasswordResetResponse\"M\xc2\xf3\x18I\"G\n" +
+	"\x18rejects an invalid email\x12\x19{\"email\": \"not-an-email\"}\x1a\x10INVALID_ARGUMENT\x12\xe6\x02\n" +
+	"\rResetPassword\x12\x1a.user.ResetPasswordRequest\x1a\x1b.user.ResetPasswordResponse\"\x9b\x02\xc2\xf3\x18\x96\x02\"K\n" +
+	"\x17rejects a missing token\x12\x1e{\"new_password\": \"Password1!\"}\x1a\x10INVALID_ARGUMENT\"a\n" +
+	"\x17rejects a weak password\x124{\"token\": \"not-a-token\", \"new_password\": \"password\"}\x1a\x10INVALID_ARGUMENT\"d\n" +
+	"\x18rejects an unknown token\x126{\"token\": \"not-a-token\", \"new_password\": \"Password1!\"}\x1a\x10INVALID_ARGUMENT\x12Q\n" +
+	"\x10EvaluatePassword\x12\x1d.user.EvaluatePasswordRequest\x1a\x1e.user.EvaluatePasswordResponse\x12\xf6\x01\n" +
+	"\x0eGetAuthOptions\x12\x1b.user.GetAuthOptionsRequest\x1a\x1c.user.GetAuthOptionsResponse\"\xa8\x01\xc2\xf3\x18\xa3\x01\"G\n" +
+	"\x18rejects an invalid email\x12\x19{\"email\": \"not-an-email\"}\x1a\x10INVALID_ARGUMENT\"X\n" +
+	"\"rejects an invalid organization ID\x12 {\"organization_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT\x12\xea\x01\n" +
+	"\x10CompleteSSOLogin\x12\x1d.user.CompleteSSOLoginRequest\x1a\x1e.user.CompleteSSOLoginResponse\"\x96\x01\xc2\xf3\x18\x91\x01\":\n" +
+	"\x17rejects a missing state\x12\r{\"code\": \"x\"}\x1a\x10INVALID_ARGUMENT\"S\n" +
+	"\x18rejects an unknown state\x12%{\"state\": \"not-a-state\", \"code\": \"x\"}\x1a\x10INVALID_ARGUMENT\x12\xf9\x01\n" +
+	"\x11LoginWithProvider\x12\x1e.user.LoginWithProviderRequest\x1a\x1f.user.LoginWithProviderResponse\"\xa2\x01\xc2\xf3\x18\x9d\x01\"V\n" +
+	"\x1brejects an unknown provider\x12%{\"provider\": \"myspace\", \"token\": \"x\"}\x1a\x10INVALID_ARGUMENT\"C\n" +
+	"\x17rejects a missing token\x12\x16{\"provider\": \"google\"}\x1a\x10INVALID_ARGUMENT\x12k\n" +
+	"\x16StartEmailVerification\x12#.user.StartEmailVerificationRequest\x1a$.user.StartEmailVerificationResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12\xcc\x01\n" +
+	"\vVerifyEmail\x12\x18.user.VerifyEmailRequest\x1a\x19.user.VerifyEmailResponse\"\x87\x01\xc2\xf3\x18\x82\x01\b\x01\"0\n" +
+	"\x16rejects a missing code\x12\x02{}\x1a\x10INVALID_ARGUMENT \x01\"L\n" +
+	"\"rejects a code that was never sent\x12\x12{\"code\": \"000000\"}\x1a\x10INVALID_ARGUMENT \x01\x12Y\n" +
+	"\x10StartSetPassword\x12\x1d.user.StartSetPasswordRequest\x1a\x1e.user.StartSetPasswordResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12\x9e\x01\n" +
+	"\vSetPassword\x12\x18.user.SetPasswordRequest\x1a\x19.user.SetPasswordResponse\"Z\xc2\xf3\x18V\b\x01\"R\n" +
+	"\x16rejects a missing code\x12${\"new_password\": \"Correct-Horse-42\"}\x1a\x10INVALID_ARGUMENT \x012\xe5\"\n" +
+	"\vUserService\x12\xf5\x01\n" +
+	"\n" +
+	"GetProfile\x12\x17.user.GetProfileRequest\x1a\x18.user.GetProfileResponse\"\xb3\x01\xc2\xf3\x18\xae\x01\b\x01\"J\n" +
+	"\x1arejects an invalid user ID\x12\x18{\"user_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x01\"^\n" +
+	"\x1erejects another user's profile\x12'{\"user_id\": \"ffffffffffffffffffffffff\"}\x1a\x11PERMISSION_DENIED \x01\x12\xc1\x01\n" +
+	"\rUpdateProfile\x12\x1a.user.UpdateProfileRequest\x1a\x1b.user.UpdateProfileResponse\"w\xc2\xf3\x18s\b\x01\"o\n" +
+	"\x1erejects another user's profile\x128{\"user_id\": \"ffffffffffffffffffffffff\", \"name\": \"Other\"}\x1a\x11PERMISSION_DENIED \x01\x12\xb0\x01\n" +
+	"\rDeleteProfile\x12\x1a.user.DeleteProfileRequest\x1a\x1b.user.DeleteProfileResponse\"f\xc2\xf3\x18b\b\x01\"^\n" +
+	"\x1erejects another user's profile\x12'{\"user_id\": \"ffffffffffffffffffffffff\"}\x1a\x11PERMISSION_DENIED \x01\x12\x8e\x04\n" +
+	"\tListUsers\x12\x16.user.ListUsersRequest\x1a\x17.user.ListUsersResponse\"\xcf\x03\xc2\xf3\x18\xca\x03\x10\x01\"\x8b\x01\n" +
+	"\x1frejects an overlong name filter\x12T{\"name_filter\": \"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\"}\x1a\x10INVALID_ARGUMENT \x02\"\x9a\x01\n" +
+	"/rejects created_after later than created_before\x12S{\"created_after\": \"2025-02-01T00:00:00Z\", \"created_before\": \"2025-01-01T00:00:00Z\"}\x1a\x10INVALID_ARGUMENT \x02\"L\n" +
+	"\x1drejects an unknown sort field\x12\x17{\"sort_by\": \"password\"}\x1a\x10INVALID_ARGUMENT \x02\"M\n" +
+	"\x1drejects an unknown sort order\x12\x18{\"sort_order\": \"random\"}\x1a\x10INVALID_ARGUMENT \x02\x12\xf7\x01\n" +
+	"\x0eChangePassword\x12\x1b.user.ChangePasswordRequest\x1a\x1c.user.ChangePasswordResponse\"\xa9\x01\xc2\xf3\x18\xa4\x01\b\x01\"\x9f\x01\n" +
+	"\x1frejects another user's password\x12g{\"user_id\": \"ffffffffffffffffffffffff\", \"current_password\": \"Password1!\", \"new_password\": \"Password2!\"}\x1a\x11PERMISSION_DENIED \x01\x12V\n" +
+	"\x0fEnableTwoFactor\x12\x1c.user.EnableTwoFactorRequest\x1a\x1d.user.EnableTwoFactorResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12j\n" +
+	"\x0fVerifyTwoFactor\x12\x1c.user.VerifyTwoFactorRequest\x1a\x1d.user.VerifyTwoFactorResponse\"\x1a\xc2\xf3\x18\x16\b\x01\x1a\x12{\"code\": \"123456\"}\x12\x9f\x01\n" +
+	"\x10DisableTwoFactor\x12\x1d.user.DisableTwoFactorRequest\x1a\x1e.user.DisableTwoFactorResponse\"L\xc2\xf3\x18H\b\x01\"D\n" +
+	"\x1arejects a missing password\x12\x12{\"code\": \"123456\"}\x1a\x10INVALID_ARGUMENT \x01\x12h\n" +
+	"\x15GenerateRecoveryCodes\x12\".user.GenerateRecoveryCodesRequest\x1a#.user.GenerateRecoveryCodesResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12z\n" +
+	"\x1bBeginCredentialRegistration\x12(.user.BeginCredentialRegistrationRequest\x1a).user.BeginCredentialRegistrationResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12\x97\x01\n" +
+	"\x12RegisterCredential\x12\x1f.user.RegisterCredentialRequest\x1a .user.RegisterCredentialResponse\">\xc2\xf3\x18:\b\x01\"6\n" +
+	"\x1crejects a missing credential\x12\x02{}\x1a\x10INVALID_ARGUMENT \x01\x12V\n" +
+	"\x0fListCredentials\x12\x1c.user.ListCredentialsRequest\x1a\x1d.user.ListCredentialsResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12\xd2\x03\n" +
+	"\x10RenameCredential\x12\x1d.user.RenameCredentialRequest\x1a\x1e.user.RenameCredentialResponse\"\xfe\x02\xc2\xf3\x18\xf9\x02\b\x01\"\x87\x01\n" +
+	"\"rejects an unknown credential type\x12M{\"id\": \"ffffffffffffffffffffffff\", \"type\": \"password\", \"name\": \"Work laptop\"}\x1a\x10INVALID_ARGUMENT \x01\"c\n" +
+	"\x16rejects a missing name\x125{\"id\": \"ffffffffffffffffffffffff\", \"type\": \"passkey\"}\x1a\x10INVALID_ARGUMENT \x01\"\x85\x01\n" +
+	"!rejects another user's credential\x12S{\"id\": \"ffffffffffffffffffffffff\", \"type\": \"trusted_device\", \"name\": \"Work laptop\"}\x1a\tNOT_FOUND \x01\x12\xb9\x01\n" +
+	"\x10DeleteCredential\x12\x1d.user.DeleteCredentialRequest\x1a\x1e.user.DeleteCredentialResponse\"f\xc2\xf3\x18b\b\x01\"^\n" +
+	" rejects an invalid credential ID\x12&{\"id\": \"not-an-id\", \"type\": \"passkey\"}\x1a\x10INVALID_ARGUMENT \x01\x12Y\n" +
+	"\x10ListKnownDevices\x12\x1d.user.ListKnownDevicesRequest\x1a\x1e.user.ListKnownDevicesResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12e\n" +
+	"\x14ListLinkedIdentities\x12!.user.ListLinkedIdentitiesRequest\x1a\".user.ListLinkedIdentitiesResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12\xaa\x02\n" +
+	"\x0eUnlinkIdentity\x12\x1b.user.UnlinkIdentityRequest\x1a\x1c.user.UnlinkIdentityResponse\"\xdc\x01\xc2\xf3\x18\xd7\x01\b\x01\"[\n" +
+	"\x19rejects a missing subject\x12*{\"issuer\": \"https://accounts.example.com\"}\x1a\x10INVALID_ARGUMENT \x01\"v\n" +
+	"%rejects an identity that isn't linked\x12@{\"issuer\": \"https://accounts.example.com\", \"subject\": \"unknown\"}\x1a\tNOT_FOUND \x01\x12M\n" +
+	"\fListSessions\x12\x19.user.ListSessionsRequest\x1a\x1a.user.ListSessionsResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12G\n" +
+	"\fWatchSession\x12\x19.user.WatchSessionRequest\x1a\x12.user.SessionEvent\"\x06\xc2\xf3\x18\x02\b\x010\x01\x12\xd0\x01\n" +
+	"\rRevokeSession\x12\x1a.user.RevokeSessionRequest\x1a\x1b.user.RevokeSessionResponse\"\x85\x01\xc2\xf3\x18\x80\x01\b\x01\"6\n" +
+	"\x1crejects a missing session ID\x12\x02{}\x1a\x10INVALID_ARGUMENT \x01\"D\n" +
+	"\x1arejects an unknown session\x12\x19{\"session_id\": \"unknown\"}\x1a\tNOT_FOUND \x01\x12\xf6\x01\n" +
+	"\fCreateApiKey\x12\x19.user.CreateApiKeyRequest\x1a\x1a.user.CreateApiKeyResponse\"\xae\x01\xc2\xf3\x18\xa9\x01\b\x01\"0\n" +
+	"\x16rejects a missing name\x12\x02{}\x1a\x10INVALID_ARGUMENT \x01\"s\n" +
+	"\x1drejects an expiry in the past\x12>{\"name\": \"Billing sync\", \"expires_at\": \"2020-01-01T00:00:00Z\"}\x1a\x10INVALID_ARGUMENT \x01\x12J\n" +
+	"\vListApiKeys\x12\x18.user.ListApiKeysRequest\x1a\x19.user.ListApiKeysResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12\xec\x01\n" +
+	"\fRevokeApiKey\x12\x19.user.RevokeApiKeyRequest\x1a\x1a.user.RevokeApiKeyResponse\"\xa4\x01\xc2\xf3\x18\x9f\x01\b\x01\"H\n" +
+	"\x1drejects an invalid API key ID\x12\x13{\"id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x01\"Q\n" +
+	"\x1erejects another user's API key\x12\"{\"id\": \"ffffffffffffffffffffffff\"}\x1a\tNOT_FOUND \x01\x12\x7f\n" +
+	"\vAcceptTerms\x12\x18.user.AcceptTermsRequest\x1a\x19.user.AcceptTermsResponse\";\xc2\xf3\x187\b\x01\"3\n" +
+	"\x19rejects a missing version\x12\x02{}\x1a\x10INVALID_ARGUMENT \x012\xec5\n" +
+	"\fAdminService\x12t\n" +
+	"\x19ListNotificationTemplates\x12&.user.ListNotificationTemplatesRequest\x1a'.user.ListNotificationTemplatesResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12z\n" +
+	"\x1bPreviewNotificationTemplate\x12(.user.PreviewNotificationTemplateRequest\x1a).user.PreviewNotificationTemplateResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12e\n" +
+	"\x14SendTestNotification\x12!.user.SendTestNotificationRequest\x1a\".user.SendTestNotificationResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12e\n" +
+	"\x14ListDeadLetterEvents\x12!.user.ListDeadLetterEventsRequest\x1a\".user.ListDeadLetterEventsResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12k\n" +
+	"\x16ReplayDeadLetterEvents\x12#.user.ReplayDeadLetterEventsRequest\x1a$.user.ReplayDeadLetterEventsResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12M\n" +
+	"\fExportConfig\x12\x19.user.ExportConfigRequest\x1a\x1a.user.ExportConfigResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12M\n" +
+	"\fImportConfig\x12\x19.user.ImportConfigRequest\x1a\x1a.user.ImportConfigResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12A\n" +
+	"\bLockUser\x12\x15.user.LockUserRequest\x1a\x16.user.LockUserResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12G\n" +
+	"\n" +
+	"UnlockUser\x12\x17.user.UnlockUserRequest\x1a\x18.user.UnlockUserResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12\x9f\x01\n" +
+	"\x0eDeactivateUser\x12\x1b.user.DeactivateUserRequest\x1a\x1c.user.DeactivateUserResponse\"R\xc2\xf3\x18N\x10\x01\"J\n" +
+	"\x1arejects an invalid user ID\x12\x18{\"user_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\x12\x9f\x01\n" +
+	"\x0eReactivateUser\x12\x1b.user.ReactivateUserRequest\x1a\x1c.user.ReactivateUserResponse\"R\xc2\xf3\x18N\x10\x01\"J\n" +
+	"\x1arejects an invalid user ID\x12\x18{\"user_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\x12\xec\x02\n" +
+	"\x0eHardDeleteUser\x12\x1b.user.HardDeleteUserRequest\x1a\x1c.user.HardDeleteUserResponse\"\x9e\x02\xc2\xf3\x18\x99\x02\x10\x01\"[\n" +
+	"\x1arejects an invalid user ID\x12){\"user_id\": \"not-an-id\", \"confirm\": true}\x1a\x10INVALID_ARGUMENT \x02\"T\n" +
+	"\x15requires confirmation\x12'{\"user_id\": \"000000000000000000000000\"}\x1a\x10INVALID_ARGUMENT \x02\"b\n" +
+	"\x19dry run of a missing user\x128{\"user_id\": \"ffffffffffffffffffffffff\", \"dry_run\": true}\x1a\tNOT_FOUND \x02\x12\xa8\x01\n" +
+	"\x11ResetUserPassword\x12\x1e.user.ResetUserPasswordRequest\x1a\x1f.user.ResetUserPasswordResponse\"R\xc2\xf3\x18N\x10\x01\"J\n" +
+	"\x1arejects an invalid user ID\x12\x18{\"user_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\x12\xa2\x01\n" +
+	"\x0fGetLoginHistory\x12\x1c.user.GetLoginHistoryRequest\x1a\x1d.user.GetLoginHistoryResponse\"R\xc2\xf3\x18N\x10\x01\"J\n" +
+	"\x1arejects an invalid user ID\x12\x18{\"user_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\x12\xfc\x01\n" +
+	"\vForceLogout\x12\x18.user.ForceLogoutRequest\x1a\x19.user.ForceLogoutResponse\"\xb7\x01\xc2\xf3\x18\xb2\x01\x10\x01\"J\n" +
+	"\x1arejects an invalid user ID\x12\x18{\"user_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\"b\n" +
+	"\x19dry run of a missing user\x128{\"user_id\": \"ffffffffffffffffffffffff\", \"dry_run\": true}\x1a\tNOT_FOUND \x02\x12_\n" +
+	"\x12CreateOrganization\x12\x1f.user.CreateOrganizationRequest\x1a .user.CreateOrganizationResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12q\n" +
+	"\x18UpdateOrganizationPolicy\x12%.user.UpdateOrganizationPolicyRequest\x1a&.user.UpdateOrganizationPolicyResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12_\n" +
+	"\x12SetOrganizationSSO\x12\x1f.user.SetOrganizationSSORequest\x1a .user.SetOrganizationSSOResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12\xd2\x02\n" +
+	"\x1bSetOrganizationProvisioning\x12(.user.SetOrganizationProvisioningRequest\x1a).user.SetOrganizationProvisioningResponse\"\xdd\x01\xc2\xf3\x18\xd8\x01\x10\x01\"Q\n" +
+	"\"rejects an invalid organization ID\x12\x17{\"org_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\"\x80\x01\n" +
+	"\x17rejects an unknown role\x12Q{\"org_id\": \"000000000000000000000000\", \"provisioning\": {\"default_role\": \"owner\"}}\x1a\x10INVALID_ARGUMENT \x02\x12\xd6\x01\n" +
+	"\x1eDeprovisionOrganizationMembers\x12+.user.DeprovisionOrganizationMembersRequest\x1a,.user.DeprovisionOrganizationMembersResponse\"Y\xc2\xf3\x18U\x10\x01\"Q\n" +
+	"\"rejects an invalid organization ID\x12\x17{\"org_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\x12\xaf\x01\n" +
+	"\x14GetDeprovisioningJob\x12!.user.GetDeprovisioningJobRequest\x1a\".user.GetDeprovisioningJobResponse\"P\xc2\xf3\x18L\x10\x01\"H\n" +
+	"\x19rejects an invalid job ID\x12\x17{\"job_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\x12h\n" +
+	"\x15SetOrganizationMember\x12\".user.SetOrganizationMemberRequest\x1a#.user.SetOrganizationMemberResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12\xfe\x01\n" +
+	"\x0fCancelOperation\x12\x1c.user.CancelOperationRequest\x1a\x1d.user.CancelOperationResponse\"\xad\x01\xc2\xf3\x18\xa8\x01\x10\x01\"T\n" +
+	"\x1frejects an invalid operation ID\x12\x1d{\"operation_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\"N\n" +
+	"\x11missing operation\x12,{\"operation_id\": \"000000000000000000000000\"}\x1a\tNOT_FOUND \x02\x12\xa8\x02\n" +
+	"\x0eScheduleAction\x12\x1b.user.ScheduleActionRequest\x1a\x1c.user.ScheduleActionResponse\"\xda\x01\xc2\xf3\x18\xd5\x01\x10\x01\"b\n" +
+	"\x1arejects an invalid user ID\x120{\"user_id\": \"not-an-id\", \"action\": \"deactivate\"}\x1a\x10INVALID_ARGUMENT \x02\"m\n" +
+	"\x19rejects an unknown action\x12<{\"user_id\": \"000000000000000000000000\", \"action\": \"promote\"}\x1a\x10INVALID_ARGUMENT \x02\x12\xbc\x01\n" +
+	"\x15CancelScheduledAction\x12\".user.CancelScheduledActionRequest\x1a#.user.CancelScheduledActionResponse\"Z\xc2\xf3\x18V\x10\x01\"R\n" +
+	"\x18missing scheduled action\x12){\"action_id\": \"000000000000000000000000\"}\x1a\tNOT_FOUND \x02\x12e\n" +
+	"\x14ListScheduledActions\x12!.user.ListScheduledActionsRequest\x1a\".user.ListScheduledActionsResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12\xf9\x01\n" +
+	"\tEraseUser\x12\x16.user.EraseUserRequest\x1a\x17.user.EraseUserResponse\"\xba\x01\xc2\xf3\x18\xb5\x01\x10\x01\"[\n" +
+	"\x1arejects an invalid user ID\x12){\"user_id\": \"not-an-id\", \"confirm\": true}\x1a\x10INVALID_ARGUMENT \x02\"T\n" +
+	"\x15requires confirmation\x12'{\"user_id\": \"000000000000000000000000\"}\x1a\x10INVALID_ARGUMENT \x02\x12\x98\x01\n" +
+	"\vGetWorkflow\x12\x18.user.GetWorkflowRequest\x1a\x19.user.GetWorkflowResponse\"T\xc2\xf3\x18P\x10\x01\"L\n" +
+	"\x10missing workflow\x12+{\"workflow_id\": \"000000000000000000000000\"}\x1a\tNOT_FOUND \x02\x12P\n" +
+	"\rListWorkflows\x12\x1a.user.ListWorkflowsRequest\x1a\x1b.user.ListWorkflowsResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12\x9e\x01\n" +
+	"\rRetryWorkflow\x12\x1a.user.RetryWorkflowRequest\x1a\x1b.user.RetryWorkflowResponse\"T\xc2\xf3\x18P\x10\x01\"L\n" +
+	"\x10missing workflow\x12+{\"workflow_id\": \"000000000000000000000000\"}\x1a\tNOT_FOUND \x02\x12\xa6\x02\n" +
+	"\x16DestroyOrganizationKey\x12#.user.DestroyOrganizationKeyRequest\x1a$.user.DestroyOrganizationKeyResponse\"\xc0\x01\xc2\xf3\x18\xbb\x01\x10\x01\"b\n" +
+	"\"rejects an invalid organization ID\x12({\"org_id\": \"not-an-id\", \"confirm\": true}\x1a\x10INVALID_ARGUMENT \x02\"S\n" +
+	"\x15requires confirmation\x12&{\"org_id\": \"000000000000000000000000\"}\x1a\x10INVALID_ARGUMENT \x02\x12P\n" +
+	"\rSetExternalId\x12\x1a.user.SetExternalIdRequest\x1a\x1b.user.SetExternalIdResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12b\n" +
+	"\x13GetUserByExternalId\x12 .user.GetUserByExternalIdRequest\x1a!.user.GetUserByExternalIdResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12\x94\x01\n" +
+	"\rGetUsersByIds\x12\x1a.user.GetUsersByIdsRequest\x1a\x1b.user.GetUsersByIdsResponse\"J\xc2\xf3\x18F\x10\x01\"B\n" +
+	"\x0finvalid user ID\x12\x1b{\"user_ids\": [\"not-an-id\"]}\x1a\x10INVALID_ARGUMENT \x02\x12\x89\x01\n" +
+	"\vExportUsers\x12\x18.user.ExportUsersRequest\x1a\n" +
+	".user.User\"R\xc2\xf3\x18N\x10\x01\"J\n" +
+	"\x15invalid resume cursor\x12\x1d{\"resume_after\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x020\x01\x12J\n" +
+	"\vImportUsers\x12\x18.user.ImportUsersRequest\x1a\x19.user.ImportUsersResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12\xf5\x01\n" +
+	"\fGetOperation\x12\x19.user.GetOperationRequest\x1a\x1a.user.GetOperationResponse\"\xad\x01\xc2\xf3\x18\xa8\x01\x10\x01\"T\n" +
+	"\x1frejects an invalid operation ID\x12\x1d{\"operation_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\"N\n" +
+	"\x11missing operation\x12,{\"operation_id\": \"000000000000000000000000\"}\x1a\tNOT_FOUND \x02\x12S\n" +
+	"\x0eListOperations\x12\x1b.user.ListOperationsRequest\x1a\x1c.user.ListOperationsResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12\x98\x01\n" +
+	"\x0eWatchOperation\x12\x1b.user.WatchOperationRequest\x1a\x0f.user.Operation\"V\xc2\xf3\x18R\x10\x01\"N\n" +
+	"\x11missing operation\x12,{\"operation_id\": \"000000000000000000000000\"}\x1a\tNOT_FOUND \x020\x01\x12S\n" +
+	"\x0eRebuildIndexes\x12\x1b.user.RebuildIndexesRequest\x1a\x1c.user.RebuildIndexesResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12V\n" +
+	"\x0fRunAccountPurge\x12\x1c.user.RunAccountPurgeRequest\x1a\x1d.user.RunAccountPurgeResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12S\n" +
+	"\x0eReplayAuditLog\x12\x1b.user.ReplayAuditLogRequest\x1a\x1c.user.ReplayAuditLogResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12\xed\x01\n" +
+	"\fGetAuditLogs\x12\x19.user.GetAuditLogsRequest\x1a\x1a.user.GetAuditLogsResponse\"\xa5\x01\xc2\xf3\x18\xa0\x01\x10\x01\"N\n" +
+	"\x1crejects an invalid target ID\x12\x1a{\"target_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\"L\n" +
+	"\x1brejects an invalid actor ID\x12\x19{\"actor_id\": \"not-an-id\"}\x1a\x10INVALID_ARGUMENT \x02\x12D\n" +
+	"\tGetUserAt\x12\x16.user.GetUserAtRequest\x1a\x17.user.GetUserAtResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12S\n" +
+	"\x0eListUserEvents\x12\x1b.user.ListUserEventsRequest\x1a\x1c.user.ListUserEventsResponse\"\x06\xc2\xf3\x18\x02\x10\x01\x12e\n" +
+	"\x14GetReplicationStatus\x12!.user.GetReplicationStatusRequest\x1a\".user.GetReplicationStatusResponse\"\x06\xc2\xf3\x18\x02\x10\x012\xd6\x02\n" +
+	"\x13OrganizationService\x12t\n" +
+	"\x19ListProfileChangeRequests\x12&.user.ListProfileChangeRequestsRequest\x1a'.user.ListProfileChangeRequestsResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12e\n" +
+	"\x14ApproveProfileChange\x12!.user.ApproveProfileChangeRequest\x1a\".user.ApproveProfileChangeResponse\"\x06\xc2\xf3\x18\x02\b\x01\x12b\n" +
+	"\x13RejectProfileChange\x12 .user.RejectProfileChangeRequest\x1a!.user.RejectProfileChangeResponse\"\x06\xc2\xf3\x18\x02\b\x01B\bZ\x06./userb\x06proto3"
 
 var (
 	file_proto_user_proto_rawDescOnce sync.Once
@@ -1054,56 +15986,634 @@ func file_proto_user_proto_rawDescGZIP() []byte {
 	return file_proto_user_proto_rawDescData
 }
 
-var file_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 252)
 var file_proto_user_proto_goTypes = []any{
-	(*User)(nil),                   // 0: user.User
-	(*LoginRequest)(nil),           // 1: user.LoginRequest
-	(*LoginResponse)(nil),          // 2: user.LoginResponse
-	(*LogoutRequest)(nil),          // 3: user.LogoutRequest
-	(*LogoutResponse)(nil),         // 4: user.LogoutResponse
-	(*RegisterRequest)(nil),        // 5: user.RegisterRequest
-	(*RegisterResponse)(nil),       // 6: user.RegisterResponse
-	(*GetProfileRequest)(nil),      // 7: user.GetProfileRequest
-	(*GetProfileResponse)(nil),     // 8: user.GetProfileResponse
-	(*UpdateProfileRequest)(nil),   // 9: user.UpdateProfileRequest
-	(*UpdateProfileResponse)(nil),  // 10: user.UpdateProfileResponse
-	(*DeleteProfileRequest)(nil),   // 11: user.DeleteProfileRequest
-	(*DeleteProfileResponse)(nil),  // 12: user.DeleteProfileResponse
-	(*ListUsersRequest)(nil),       // 13: user.ListUsersRequest
-	(*ListUsersResponse)(nil),      // 14: user.ListUsersResponse
-	(*ChangePasswordRequest)(nil),  // 15: user.ChangePasswordRequest
-	(*ChangePasswordResponse)(nil), // 16: user.ChangePasswordResponse
-	(*timestamppb.Timestamp)(nil),  // 17: google.protobuf.Timestamp
+	(*User)(nil),                                   // 0: user.User
+	(*LoginRequest)(nil),                           // 1: user.LoginRequest
+	(*LoginResponse)(nil),                          // 2: user.LoginResponse
+	(*LoginSecurityContext)(nil),                   // 3: user.LoginSecurityContext
+	(*SendLoginCodeRequest)(nil),                   // 4: user.SendLoginCodeRequest
+	(*SendLoginCodeResponse)(nil),                  // 5: user.SendLoginCodeResponse
+	(*BeginPasskeyLoginRequest)(nil),               // 6: user.BeginPasskeyLoginRequest
+	(*BeginPasskeyLoginResponse)(nil),              // 7: user.BeginPasskeyLoginResponse
+	(*LogoutRequest)(nil),                          // 8: user.LogoutRequest
+	(*LogoutResponse)(nil),                         // 9: user.LogoutResponse
+	(*RefreshTokenRequest)(nil),                    // 10: user.RefreshTokenRequest
+	(*RefreshTokenResponse)(nil),                   // 11: user.RefreshTokenResponse
+	(*LogoutAllDevicesRequest)(nil),                // 12: user.LogoutAllDevicesRequest
+	(*LogoutAllDevicesResponse)(nil),               // 13: user.LogoutAllDevicesResponse
+	(*RegisterRequest)(nil),                        // 14: user.RegisterRequest
+	(*RegisterResponse)(nil),                       // 15: user.RegisterResponse
+	(*PendingLogin)(nil),                           // 16: user.PendingLogin
+	(*ApproveLoginRequest)(nil),                    // 17: user.ApproveLoginRequest
+	(*ApproveLoginResponse)(nil),                   // 18: user.ApproveLoginResponse
+	(*ListPendingLoginsRequest)(nil),               // 19: user.ListPendingLoginsRequest
+	(*ListPendingLoginsResponse)(nil),              // 20: user.ListPendingLoginsResponse
+	(*StartDeviceLoginRequest)(nil),                // 21: user.StartDeviceLoginRequest
+	(*StartDeviceLoginResponse)(nil),               // 22: user.StartDeviceLoginResponse
+	(*ApproveDeviceLoginRequest)(nil),              // 23: user.ApproveDeviceLoginRequest
+	(*ApproveDeviceLoginResponse)(nil),             // 24: user.ApproveDeviceLoginResponse
+	(*PollDeviceLoginRequest)(nil),                 // 25: user.PollDeviceLoginRequest
+	(*PollDeviceLoginResponse)(nil),                // 26: user.PollDeviceLoginResponse
+	(*CreateActionTokenRequest)(nil),               // 27: user.CreateActionTokenRequest
+	(*CreateActionTokenResponse)(nil),              // 28: user.CreateActionTokenResponse
+	(*ValidateTokenRequest)(nil),                   // 29: user.ValidateTokenRequest
+	(*ValidateTokenResponse)(nil),                  // 30: user.ValidateTokenResponse
+	(*GetProfileRequest)(nil),                      // 31: user.GetProfileRequest
+	(*GetProfileResponse)(nil),                     // 32: user.GetProfileResponse
+	(*UpdateProfileRequest)(nil),                   // 33: user.UpdateProfileRequest
+	(*UpdateProfileResponse)(nil),                  // 34: user.UpdateProfileResponse
+	(*DeleteProfileRequest)(nil),                   // 35: user.DeleteProfileRequest
+	(*DeleteProfileResponse)(nil),                  // 36: user.DeleteProfileResponse
+	(*RestoreProfileRequest)(nil),                  // 37: user.RestoreProfileRequest
+	(*RestoreProfileResponse)(nil),                 // 38: user.RestoreProfileResponse
+	(*ListUsersRequest)(nil),                       // 39: user.ListUsersRequest
+	(*ListUsersResponse)(nil),                      // 40: user.ListUsersResponse
+	(*GetAuthOptionsRequest)(nil),                  // 41: user.GetAuthOptionsRequest
+	(*AuthMethod)(nil),                             // 42: user.AuthMethod
+	(*GetAuthOptionsResponse)(nil),                 // 43: user.GetAuthOptionsResponse
+	(*CompleteSSOLoginRequest)(nil),                // 44: user.CompleteSSOLoginRequest
+	(*CompleteSSOLoginResponse)(nil),               // 45: user.CompleteSSOLoginResponse
+	(*LoginWithProviderRequest)(nil),               // 46: user.LoginWithProviderRequest
+	(*LoginWithProviderResponse)(nil),              // 47: user.LoginWithProviderResponse
+	(*StartEmailVerificationRequest)(nil),          // 48: user.StartEmailVerificationRequest
+	(*StartEmailVerificationResponse)(nil),         // 49: user.StartEmailVerificationResponse
+	(*VerifyEmailRequest)(nil),                     // 50: user.VerifyEmailRequest
+	(*VerifyEmailResponse)(nil),                    // 51: user.VerifyEmailResponse
+	(*StartSetPasswordRequest)(nil),                // 52: user.StartSetPasswordRequest
+	(*StartSetPasswordResponse)(nil),               // 53: user.StartSetPasswordResponse
+	(*SetPasswordRequest)(nil),                     // 54: user.SetPasswordRequest
+	(*SetPasswordResponse)(nil),                    // 55: user.SetPasswordResponse
+	(*StartUnlockChallengeRequest)(nil),            // 56: user.StartUnlockChallengeRequest
+	(*StartUnlockChallengeResponse)(nil),           // 57: user.StartUnlockChallengeResponse
+	(*UnlockWithChallengeRequest)(nil),             // 58: user.UnlockWithChallengeRequest
+	(*UnlockWithChallengeResponse)(nil),            // 59: user.UnlockWithChallengeResponse
+	(*StartAccountRecoveryRequest)(nil),            // 60: user.StartAccountRecoveryRequest
+	(*StartAccountRecoveryResponse)(nil),           // 61: user.StartAccountRecoveryResponse
+	(*SecureAccountRequest)(nil),                   // 62: user.SecureAccountRequest
+	(*SecureAccountResponse)(nil),                  // 63: user.SecureAccountResponse
+	(*EnableTwoFactorRequest)(nil),                 // 64: user.EnableTwoFactorRequest
+	(*EnableTwoFactorResponse)(nil),                // 65: user.EnableTwoFactorResponse
+	(*VerifyTwoFactorRequest)(nil),                 // 66: user.VerifyTwoFactorRequest
+	(*VerifyTwoFactorResponse)(nil),                // 67: user.VerifyTwoFactorResponse
+	(*DisableTwoFactorRequest)(nil),                // 68: user.DisableTwoFactorRequest
+	(*DisableTwoFactorResponse)(nil),               // 69: user.DisableTwoFactorResponse
+	(*Passkey)(nil),                                // 70: user.Passkey
+	(*BeginCredentialRegistrationRequest)(nil),     // 71: user.BeginCredentialRegistrationRequest
+	(*BeginCredentialRegistrationResponse)(nil),    // 72: user.BeginCredentialRegistrationResponse
+	(*RegisterCredentialRequest)(nil),              // 73: user.RegisterCredentialRequest
+	(*RegisterCredentialResponse)(nil),             // 74: user.RegisterCredentialResponse
+	(*Credential)(nil),                             // 75: user.Credential
+	(*ListCredentialsRequest)(nil),                 // 76: user.ListCredentialsRequest
+	(*ListCredentialsResponse)(nil),                // 77: user.ListCredentialsResponse
+	(*RenameCredentialRequest)(nil),                // 78: user.RenameCredentialRequest
+	(*RenameCredentialResponse)(nil),               // 79: user.RenameCredentialResponse
+	(*DeleteCredentialRequest)(nil),                // 80: user.DeleteCredentialRequest
+	(*DeleteCredentialResponse)(nil),               // 81: user.DeleteCredentialResponse
+	(*KnownDevice)(nil),                            // 82: user.KnownDevice
+	(*ListKnownDevicesRequest)(nil),                // 83: user.ListKnownDevicesRequest
+	(*ListKnownDevicesResponse)(nil),               // 84: user.ListKnownDevicesResponse
+	(*LinkedIdentity)(nil),                         // 85: user.LinkedIdentity
+	(*ListLinkedIdentitiesRequest)(nil),            // 86: user.ListLinkedIdentitiesRequest
+	(*ListLinkedIdentitiesResponse)(nil),           // 87: user.ListLinkedIdentitiesResponse
+	(*UnlinkIdentityRequest)(nil),                  // 88: user.UnlinkIdentityRequest
+	(*UnlinkIdentityResponse)(nil),                 // 89: user.UnlinkIdentityResponse
+	(*Session)(nil),                                // 90: user.Session
+	(*ListSessionsRequest)(nil),                    // 91: user.ListSessionsRequest
+	(*ListSessionsResponse)(nil),                   // 92: user.ListSessionsResponse
+	(*RevokeSessionRequest)(nil),                   // 93: user.RevokeSessionRequest
+	(*RevokeSessionResponse)(nil),                  // 94: user.RevokeSessionResponse
+	(*WatchSessionRequest)(nil),                    // 95: user.WatchSessionRequest
+	(*SessionEvent)(nil),                           // 96: user.SessionEvent
+	(*ApiKey)(nil),                                 // 97: user.ApiKey
+	(*CreateApiKeyRequest)(nil),                    // 98: user.CreateApiKeyRequest
+	(*CreateApiKeyResponse)(nil),                   // 99: user.CreateApiKeyResponse
+	(*ListApiKeysRequest)(nil),                     // 100: user.ListApiKeysRequest
+	(*ListApiKeysResponse)(nil),                    // 101: user.ListApiKeysResponse
+	(*RevokeApiKeyRequest)(nil),                    // 102: user.RevokeApiKeyRequest
+	(*RevokeApiKeyResponse)(nil),                   // 103: user.RevokeApiKeyResponse
+	(*AcceptTermsRequest)(nil),                     // 104: user.AcceptTermsRequest
+	(*AcceptTermsResponse)(nil),                    // 105: user.AcceptTermsResponse
+	(*StartTwoFactorResetRequest)(nil),             // 106: user.StartTwoFactorResetRequest
+	(*StartTwoFactorResetResponse)(nil),            // 107: user.StartTwoFactorResetResponse
+	(*ResetTwoFactorRequest)(nil),                  // 108: user.ResetTwoFactorRequest
+	(*ResetTwoFactorResponse)(nil),                 // 109: user.ResetTwoFactorResponse
+	(*CancelTwoFactorResetRequest)(nil),            // 110: user.CancelTwoFactorResetRequest
+	(*CancelTwoFactorResetResponse)(nil),           // 111: user.CancelTwoFactorResetResponse
+	(*RequestPasswordResetRequest)(nil),            // 112: user.RequestPasswordResetRequest
+	(*RequestPasswordResetResponse)(nil),           // 113: user.RequestPasswordResetResponse
+	(*ResetPasswordRequest)(nil),                   // 114: user.ResetPasswordRequest
+	(*ResetPasswordResponse)(nil),                  // 115: user.ResetPasswordResponse
+	(*EvaluatePasswordRequest)(nil),                // 116: user.EvaluatePasswordRequest
+	(*EvaluatePasswordResponse)(nil),               // 117: user.EvaluatePasswordResponse
+	(*GenerateRecoveryCodesRequest)(nil),           // 118: user.GenerateRecoveryCodesRequest
+	(*GenerateRecoveryCodesResponse)(nil),          // 119: user.GenerateRecoveryCodesResponse
+	(*OrganizationPolicy)(nil),                     // 120: user.OrganizationPolicy
+	(*OrganizationSSO)(nil),                        // 121: user.OrganizationSSO
+	(*OrganizationProvisioning)(nil),               // 122: user.OrganizationProvisioning
+	(*ProvisioningGroupRole)(nil),                  // 123: user.ProvisioningGroupRole
+	(*ProvisioningRoleMapping)(nil),                // 124: user.ProvisioningRoleMapping
+	(*ProvisioningAttribute)(nil),                  // 125: user.ProvisioningAttribute
+	(*Organization)(nil),                           // 126: user.Organization
+	(*ProfileChangeRequest)(nil),                   // 127: user.ProfileChangeRequest
+	(*ListProfileChangeRequestsRequest)(nil),       // 128: user.ListProfileChangeRequestsRequest
+	(*ListProfileChangeRequestsResponse)(nil),      // 129: user.ListProfileChangeRequestsResponse
+	(*ApproveProfileChangeRequest)(nil),            // 130: user.ApproveProfileChangeRequest
+	(*ApproveProfileChangeResponse)(nil),           // 131: user.ApproveProfileChangeResponse
+	(*RejectProfileChangeRequest)(nil),             // 132: user.RejectProfileChangeRequest
+	(*RejectProfileChangeResponse)(nil),            // 133: user.RejectProfileChangeResponse
+	(*NotificationTemplate)(nil),                   // 134: user.NotificationTemplate
+	(*ListNotificationTemplatesRequest)(nil),       // 135: user.ListNotificationTemplatesRequest
+	(*ListNotificationTemplatesResponse)(nil),      // 136: user.ListNotificationTemplatesResponse
+	(*PreviewNotificationTemplateRequest)(nil),     // 137: user.PreviewNotificationTemplateRequest
+	(*PreviewNotificationTemplateResponse)(nil),    // 138: user.PreviewNotificationTemplateResponse
+	(*SendTestNotificationRequest)(nil),            // 139: user.SendTestNotificationRequest
+	(*SendTestNotificationResponse)(nil),           // 140: user.SendTestNotificationResponse
+	(*DeadLetterEvent)(nil),                        // 141: user.DeadLetterEvent
+	(*ListDeadLetterEventsRequest)(nil),            // 142: user.ListDeadLetterEventsRequest
+	(*ListDeadLetterEventsResponse)(nil),           // 143: user.ListDeadLetterEventsResponse
+	(*ReplayDeadLetterEventsRequest)(nil),          // 144: user.ReplayDeadLetterEventsRequest
+	(*ReplayDeadLetterEventsResponse)(nil),         // 145: user.ReplayDeadLetterEventsResponse
+	(*ExportConfigRequest)(nil),                    // 146: user.ExportConfigRequest
+	(*ExportConfigResponse)(nil),                   // 147: user.ExportConfigResponse
+	(*ImportConfigRequest)(nil),                    // 148: user.ImportConfigRequest
+	(*ImportConfigResponse)(nil),                   // 149: user.ImportConfigResponse
+	(*LockUserRequest)(nil),                        // 150: user.LockUserRequest
+	(*LockUserResponse)(nil),                       // 151: user.LockUserResponse
+	(*UnlockUserRequest)(nil),                      // 152: user.UnlockUserRequest
+	(*UnlockUserResponse)(nil),                     // 153: user.UnlockUserResponse
+	(*DeactivateUserRequest)(nil),                  // 154: user.DeactivateUserRequest
+	(*DeactivateUserResponse)(nil),                 // 155: user.DeactivateUserResponse
+	(*ReactivateUserRequest)(nil),                  // 156: user.ReactivateUserRequest
+	(*ReactivateUserResponse)(nil),                 // 157: user.ReactivateUserResponse
+	(*HardDeleteUserRequest)(nil),                  // 158: user.HardDeleteUserRequest
+	(*HardDeleteUserResponse)(nil),                 // 159: user.HardDeleteUserResponse
+	(*AffectedRecords)(nil),                        // 160: user.AffectedRecords
+	(*PendingOperation)(nil),                       // 161: user.PendingOperation
+	(*CancelOperationRequest)(nil),                 // 162: user.CancelOperationRequest
+	(*CancelOperationResponse)(nil),                // 163: user.CancelOperationResponse
+	(*ScheduledAction)(nil),                        // 164: user.ScheduledAction
+	(*ScheduleActionRequest)(nil),                  // 165: user.ScheduleActionRequest
+	(*ScheduleActionResponse)(nil),                 // 166: user.ScheduleActionResponse
+	(*CancelScheduledActionRequest)(nil),           // 167: user.CancelScheduledActionRequest
+	(*CancelScheduledActionResponse)(nil),          // 168: user.CancelScheduledActionResponse
+	(*ListScheduledActionsRequest)(nil),            // 169: user.ListScheduledActionsRequest
+	(*ListScheduledActionsResponse)(nil),           // 170: user.ListScheduledActionsResponse
+	(*Workflow)(nil),                               // 171: user.Workflow
+	(*EraseUserRequest)(nil),                       // 172: user.EraseUserRequest
+	(*EraseUserResponse)(nil),                      // 173: user.EraseUserResponse
+	(*GetWorkflowRequest)(nil),                     // 174: user.GetWorkflowRequest
+	(*GetWorkflowResponse)(nil),                    // 175: user.GetWorkflowResponse
+	(*ListWorkflowsRequest)(nil),                   // 176: user.ListWorkflowsRequest
+	(*ListWorkflowsResponse)(nil),                  // 177: user.ListWorkflowsResponse
+	(*RetryWorkflowRequest)(nil),                   // 178: user.RetryWorkflowRequest
+	(*RetryWorkflowResponse)(nil),                  // 179: user.RetryWorkflowResponse
+	(*ResetUserPasswordRequest)(nil),               // 180: user.ResetUserPasswordRequest
+	(*ResetUserPasswordResponse)(nil),              // 181: user.ResetUserPasswordResponse
+	(*GetLoginHistoryRequest)(nil),                 // 182: user.GetLoginHistoryRequest
+	(*LoginRecord)(nil),                            // 183: user.LoginRecord
+	(*GetLoginHistoryResponse)(nil),                // 184: user.GetLoginHistoryResponse
+	(*ForceLogoutRequest)(nil),                     // 185: user.ForceLogoutRequest
+	(*ForceLogoutResponse)(nil),                    // 186: user.ForceLogoutResponse
+	(*CreateOrganizationRequest)(nil),              // 187: user.CreateOrganizationRequest
+	(*CreateOrganizationResponse)(nil),             // 188: user.CreateOrganizationResponse
+	(*UpdateOrganizationPolicyRequest)(nil),        // 189: user.UpdateOrganizationPolicyRequest
+	(*UpdateOrganizationPolicyResponse)(nil),       // 190: user.UpdateOrganizationPolicyResponse
+	(*SetOrganizationSSORequest)(nil),              // 191: user.SetOrganizationSSORequest
+	(*SetOrganizationSSOResponse)(nil),             // 192: user.SetOrganizationSSOResponse
+	(*SetOrganizationProvisioningRequest)(nil),     // 193: user.SetOrganizationProvisioningRequest
+	(*ProvisioningDecision)(nil),                   // 194: user.ProvisioningDecision
+	(*SetOrganizationProvisioningResponse)(nil),    // 195: user.SetOrganizationProvisioningResponse
+	(*DeprovisioningJob)(nil),                      // 196: user.DeprovisioningJob
+	(*DeprovisioningSkip)(nil),                     // 197: user.DeprovisioningSkip
+	(*DeprovisionOrganizationMembersRequest)(nil),  // 198: user.DeprovisionOrganizationMembersRequest
+	(*DeprovisionOrganizationMembersResponse)(nil), // 199: user.DeprovisionOrganizationMembersResponse
+	(*GetDeprovisioningJobRequest)(nil),            // 200: user.GetDeprovisioningJobRequest
+	(*GetDeprovisioningJobResponse)(nil),           // 201: user.GetDeprovisioningJobResponse
+	(*SetOrganizationMemberRequest)(nil),           // 202: user.SetOrganizationMemberRequest
+	(*SetOrganizationMemberResponse)(nil),          // 203: user.SetOrganizationMemberResponse
+	(*DestroyOrganizationKeyRequest)(nil),          // 204: user.DestroyOrganizationKeyRequest
+	(*DestroyOrganizationKeyResponse)(nil),         // 205: user.DestroyOrganizationKeyResponse
+	(*SetExternalIdRequest)(nil),                   // 206: user.SetExternalIdRequest
+	(*SetExternalIdResponse)(nil),                  // 207: user.SetExternalIdResponse
+	(*GetUserByExternalIdRequest)(nil),             // 208: user.GetUserByExternalIdRequest
+	(*GetUserByExternalIdResponse)(nil),            // 209: user.GetUserByExternalIdResponse
+	(*GetUsersByIdsRequest)(nil),                   // 210: user.GetUsersByIdsRequest
+	(*GetUsersByIdsResponse)(nil),                  // 211: user.GetUsersByIdsResponse
+	(*ExportUsersRequest)(nil),                     // 212: user.ExportUsersRequest
+	(*ImportUser)(nil),                             // 213: user.ImportUser
+	(*ImportUsersRequest)(nil),                     // 214: user.ImportUsersRequest
+	(*SkippedImportUser)(nil),                      // 215: user.SkippedImportUser
+	(*ImportUsersResponse)(nil),                    // 216: user.ImportUsersResponse
+	(*Operation)(nil),                              // 217: user.Operation
+	(*OperationError)(nil),                         // 218: user.OperationError
+	(*GetOperationRequest)(nil),                    // 219: user.GetOperationRequest
+	(*GetOperationResponse)(nil),                   // 220: user.GetOperationResponse
+	(*ListOperationsRequest)(nil),                  // 221: user.ListOperationsRequest
+	(*ListOperationsResponse)(nil),                 // 222: user.ListOperationsResponse
+	(*WatchOperationRequest)(nil),                  // 223: user.WatchOperationRequest
+	(*RebuildIndexesRequest)(nil),                  // 224: user.RebuildIndexesRequest
+	(*RebuildIndexesResponse)(nil),                 // 225: user.RebuildIndexesResponse
+	(*RunAccountPurgeRequest)(nil),                 // 226: user.RunAccountPurgeRequest
+	(*RunAccountPurgeResponse)(nil),                // 227: user.RunAccountPurgeResponse
+	(*AccountPurgeResult)(nil),                     // 228: user.AccountPurgeResult
+	(*ReplayAuditLogRequest)(nil),                  // 229: user.ReplayAuditLogRequest
+	(*ReplayAuditLogResponse)(nil),                 // 230: user.ReplayAuditLogResponse
+	(*GetAuditLogsRequest)(nil),                    // 231: user.GetAuditLogsRequest
+	(*AuditChange)(nil),                            // 232: user.AuditChange
+	(*AuditLogEntry)(nil),                          // 233: user.AuditLogEntry
+	(*GetAuditLogsResponse)(nil),                   // 234: user.GetAuditLogsResponse
+	(*GetUserAtRequest)(nil),                       // 235: user.GetUserAtRequest
+	(*GetUserAtResponse)(nil),                      // 236: user.GetUserAtResponse
+	(*ListUserEventsRequest)(nil),                  // 237: user.ListUserEventsRequest
+	(*UserEvent)(nil),                              // 238: user.UserEvent
+	(*ListUserEventsResponse)(nil),                 // 239: user.ListUserEventsResponse
+	(*GetReplicationStatusRequest)(nil),            // 240: user.GetReplicationStatusRequest
+	(*ReplicationMember)(nil),                      // 241: user.ReplicationMember
+	(*GetReplicationStatusResponse)(nil),           // 242: user.GetReplicationStatusResponse
+	(*ChangePasswordRequest)(nil),                  // 243: user.ChangePasswordRequest
+	(*ChangePasswordResponse)(nil),                 // 244: user.ChangePasswordResponse
+	nil,                                            // 245: user.User.ExternalIdsEntry
+	nil,                                            // 246: user.NotificationTemplate.SampleDataEntry
+	nil,                                            // 247: user.PreviewNotificationTemplateRequest.DataEntry
+	nil,                                            // 248: user.SendTestNotificationRequest.DataEntry
+	nil,                                            // 249: user.ProvisioningDecision.ExternalIdsEntry
+	nil,                                            // 250: user.ImportUser.ExternalIdsEntry
+	nil,                                            // 251: user.AuditLogEntry.DetailsEntry
+	(*timestamppb.Timestamp)(nil),                  // 252: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),                    // 253: google.protobuf.Duration
+	(*anypb.Any)(nil),                              // 254: google.protobuf.Any
 }
 var file_proto_user_proto_depIdxs = []int32{
-	17, // 0: user.User.created_at:type_name -> google.protobuf.Timestamp
-	17, // 1: user.User.updated_at:type_name -> google.protobuf.Timestamp
-	0,  // 2: user.LoginResponse.user:type_name -> user.User
-	0,  // 3: user.RegisterResponse.user:type_name -> user.User
-	0,  // 4: user.GetProfileResponse.user:type_name -> user.User
-	0,  // 5: user.UpdateProfileResponse.user:type_name -> user.User
-	0,  // 6: user.ListUsersResponse.users:type_name -> user.User
-	1,  // 7: user.AuthService.Login:input_type -> user.LoginRequest
-	3,  // 8: user.AuthService.Logout:input_type -> user.LogoutRequest
-	5,  // 9: user.AuthService.Register:input_type -> user.RegisterRequest
-	7,  // 10: user.UserService.GetProfile:input_type -> user.GetProfileRequest
-	9,  // 11: user.UserService.UpdateProfile:input_type -> user.UpdateProfileRequest
-	11, // 12: user.UserService.DeleteProfile:input_type -> user.DeleteProfileRequest
-	13, // 13: user.UserService.ListUsers:input_type -> user.ListUsersRequest
-	15, // 14: user.UserService.ChangePassword:input_type -> user.ChangePasswordRequest
-	2,  // 15: user.AuthService.Login:output_type -> user.LoginResponse
-	4,  // 16: user.AuthService.Logout:output_type -> user.LogoutResponse
-	6,  // 17: user.AuthService.Register:output_type -> user.RegisterResponse
-	8,  // 18: user.UserService.GetProfile:output_type -> user.GetProfileResponse
-	10, // 19: user.UserService.UpdateProfile:output_type -> user.UpdateProfileResponse
-	12, // 20: user.UserService.DeleteProfile:output_type -> user.DeleteProfileResponse
-	14, // 21: user.UserService.ListUsers:output_type -> user.ListUsersResponse
-	16, // 22: user.UserService.ChangePassword:output_type -> user.ChangePasswordResponse
-	15, // [15:23] is the sub-list for method output_type
-	7,  // [7:15] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	252, // 0: user.User.created_at:type_name -> google.protobuf.Timestamp
+	252, // 1: user.User.updated_at:type_name -> google.protobuf.Timestamp
+	245, // 2: user.User.external_ids:type_name -> user.User.ExternalIdsEntry
+	0,   // 3: user.LoginResponse.user:type_name -> user.User
+	252, // 4: user.LoginResponse.two_factor_enrollment_deadline:type_name -> google.protobuf.Timestamp
+	3,   // 5: user.LoginResponse.security_context:type_name -> user.LoginSecurityContext
+	252, // 6: user.LoginSecurityContext.password_changed_at:type_name -> google.protobuf.Timestamp
+	252, // 7: user.LoginSecurityContext.password_expires_at:type_name -> google.protobuf.Timestamp
+	252, // 8: user.BeginPasskeyLoginResponse.expires_at:type_name -> google.protobuf.Timestamp
+	252, // 9: user.RefreshTokenResponse.expires_at:type_name -> google.protobuf.Timestamp
+	90,  // 10: user.RefreshTokenResponse.session:type_name -> user.Session
+	0,   // 11: user.RegisterResponse.user:type_name -> user.User
+	252, // 12: user.PendingLogin.created_at:type_name -> google.protobuf.Timestamp
+	252, // 13: user.PendingLogin.expires_at:type_name -> google.protobuf.Timestamp
+	16,  // 14: user.ListPendingLoginsResponse.pending_logins:type_name -> user.PendingLogin
+	0,   // 15: user.PollDeviceLoginResponse.user:type_name -> user.User
+	252, // 16: user.PollDeviceLoginResponse.two_factor_enrollment_deadline:type_name -> google.protobuf.Timestamp
+	252, // 17: user.CreateActionTokenResponse.expires_at:type_name -> google.protobuf.Timestamp
+	252, // 18: user.ValidateTokenResponse.expires_at:type_name -> google.protobuf.Timestamp
+	0,   // 19: user.GetProfileResponse.user:type_name -> user.User
+	0,   // 20: user.UpdateProfileResponse.user:type_name -> user.User
+	252, // 21: user.DeleteProfileResponse.restorable_until:type_name -> google.protobuf.Timestamp
+	252, // 22: user.ListUsersRequest.created_after:type_name -> google.protobuf.Timestamp
+	252, // 23: user.ListUsersRequest.created_before:type_name -> google.protobuf.Timestamp
+	0,   // 24: user.ListUsersResponse.users:type_name -> user.User
+	42,  // 25: user.GetAuthOptionsResponse.methods:type_name -> user.AuthMethod
+	0,   // 26: user.CompleteSSOLoginResponse.user:type_name -> user.User
+	3,   // 27: user.CompleteSSOLoginResponse.security_context:type_name -> user.LoginSecurityContext
+	0,   // 28: user.LoginWithProviderResponse.user:type_name -> user.User
+	3,   // 29: user.LoginWithProviderResponse.security_context:type_name -> user.LoginSecurityContext
+	252, // 30: user.LoginWithProviderResponse.two_factor_enrollment_deadline:type_name -> google.protobuf.Timestamp
+	252, // 31: user.Passkey.created_at:type_name -> google.protobuf.Timestamp
+	252, // 32: user.BeginCredentialRegistrationResponse.expires_at:type_name -> google.protobuf.Timestamp
+	70,  // 33: user.RegisterCredentialResponse.passkey:type_name -> user.Passkey
+	252, // 34: user.Credential.created_at:type_name -> google.protobuf.Timestamp
+	252, // 35: user.Credential.last_used_at:type_name -> google.protobuf.Timestamp
+	75,  // 36: user.ListCredentialsResponse.credentials:type_name -> user.Credential
+	75,  // 37: user.RenameCredentialResponse.credential:type_name -> user.Credential
+	252, // 38: user.KnownDevice.first_seen_at:type_name -> google.protobuf.Timestamp
+	252, // 39: user.KnownDevice.last_seen_at:type_name -> google.protobuf.Timestamp
+	82,  // 40: user.ListKnownDevicesResponse.devices:type_name -> user.KnownDevice
+	252, // 41: user.LinkedIdentity.linked_at:type_name -> google.protobuf.Timestamp
+	252, // 42: user.LinkedIdentity.last_login_at:type_name -> google.protobuf.Timestamp
+	85,  // 43: user.ListLinkedIdentitiesResponse.identities:type_name -> user.LinkedIdentity
+	252, // 44: user.Session.issued_at:type_name -> google.protobuf.Timestamp
+	252, // 45: user.Session.last_activity_at:type_name -> google.protobuf.Timestamp
+	252, // 46: user.Session.expires_at:type_name -> google.protobuf.Timestamp
+	90,  // 47: user.ListSessionsResponse.sessions:type_name -> user.Session
+	252, // 48: user.SessionEvent.time:type_name -> google.protobuf.Timestamp
+	252, // 49: user.ApiKey.created_at:type_name -> google.protobuf.Timestamp
+	252, // 50: user.ApiKey.last_used_at:type_name -> google.protobuf.Timestamp
+	252, // 51: user.ApiKey.expires_at:type_name -> google.protobuf.Timestamp
+	252, // 52: user.CreateApiKeyRequest.expires_at:type_name -> google.protobuf.Timestamp
+	97,  // 53: user.CreateApiKeyResponse.api_key:type_name -> user.ApiKey
+	97,  // 54: user.ListApiKeysResponse.api_keys:type_name -> user.ApiKey
+	252, // 55: user.ResetTwoFactorResponse.effective_at:type_name -> google.protobuf.Timestamp
+	253, // 56: user.OrganizationPolicy.two_factor_grace_period:type_name -> google.protobuf.Duration
+	123, // 57: user.OrganizationProvisioning.group_roles:type_name -> user.ProvisioningGroupRole
+	125, // 58: user.OrganizationProvisioning.attributes:type_name -> user.ProvisioningAttribute
+	124, // 59: user.OrganizationProvisioning.role_mappings:type_name -> user.ProvisioningRoleMapping
+	120, // 60: user.Organization.policy:type_name -> user.OrganizationPolicy
+	252, // 61: user.Organization.created_at:type_name -> google.protobuf.Timestamp
+	252, // 62: user.Organization.updated_at:type_name -> google.protobuf.Timestamp
+	121, // 63: user.Organization.sso:type_name -> user.OrganizationSSO
+	122, // 64: user.Organization.provisioning:type_name -> user.OrganizationProvisioning
+	252, // 65: user.ProfileChangeRequest.created_at:type_name -> google.protobuf.Timestamp
+	252, // 66: user.ProfileChangeRequest.reviewed_at:type_name -> google.protobuf.Timestamp
+	127, // 67: user.ListProfileChangeRequestsResponse.requests:type_name -> user.ProfileChangeRequest
+	0,   // 68: user.ApproveProfileChangeResponse.user:type_name -> user.User
+	246, // 69: user.NotificationTemplate.sample_data:type_name -> user.NotificationTemplate.SampleDataEntry
+	134, // 70: user.ListNotificationTemplatesResponse.templates:type_name -> user.NotificationTemplate
+	247, // 71: user.PreviewNotificationTemplateRequest.data:type_name -> user.PreviewNotificationTemplateRequest.DataEntry
+	248, // 72: user.SendTestNotificationRequest.data:type_name -> user.SendTestNotificationRequest.DataEntry
+	252, // 73: user.DeadLetterEvent.created_at:type_name -> google.protobuf.Timestamp
+	252, // 74: user.DeadLetterEvent.dead_lettered_at:type_name -> google.protobuf.Timestamp
+	141, // 75: user.ListDeadLetterEventsResponse.events:type_name -> user.DeadLetterEvent
+	160, // 76: user.HardDeleteUserResponse.affected:type_name -> user.AffectedRecords
+	161, // 77: user.HardDeleteUserResponse.operation:type_name -> user.PendingOperation
+	252, // 78: user.PendingOperation.created_at:type_name -> google.protobuf.Timestamp
+	252, // 79: user.PendingOperation.run_at:type_name -> google.protobuf.Timestamp
+	252, // 80: user.PendingOperation.completed_at:type_name -> google.protobuf.Timestamp
+	161, // 81: user.CancelOperationResponse.operation:type_name -> user.PendingOperation
+	252, // 82: user.ScheduledAction.run_at:type_name -> google.protobuf.Timestamp
+	252, // 83: user.ScheduledAction.notify_at:type_name -> google.protobuf.Timestamp
+	252, // 84: user.ScheduledAction.notified_at:type_name -> google.protobuf.Timestamp
+	252, // 85: user.ScheduledAction.created_at:type_name -> google.protobuf.Timestamp
+	252, // 86: user.ScheduledAction.completed_at:type_name -> google.protobuf.Timestamp
+	252, // 87: user.ScheduleActionRequest.run_at:type_name -> google.protobuf.Timestamp
+	164, // 88: user.ScheduleActionResponse.scheduled_action:type_name -> user.ScheduledAction
+	164, // 89: user.CancelScheduledActionResponse.scheduled_action:type_name -> user.ScheduledAction
+	164, // 90: user.ListScheduledActionsResponse.scheduled_actions:type_name -> user.ScheduledAction
+	252, // 91: user.Workflow.created_at:type_name -> google.protobuf.Timestamp
+	252, // 92: user.Workflow.updated_at:type_name -> google.protobuf.Timestamp
+	252, // 93: user.Workflow.completed_at:type_name -> google.protobuf.Timestamp
+	171, // 94: user.EraseUserResponse.workflow:type_name -> user.Workflow
+	171, // 95: user.GetWorkflowResponse.workflow:type_name -> user.Workflow
+	171, // 96: user.ListWorkflowsResponse.workflows:type_name -> user.Workflow
+	171, // 97: user.RetryWorkflowResponse.workflow:type_name -> user.Workflow
+	252, // 98: user.ResetUserPasswordResponse.expires_at:type_name -> google.protobuf.Timestamp
+	252, // 99: user.LoginRecord.at:type_name -> google.protobuf.Timestamp
+	183, // 100: user.GetLoginHistoryResponse.logins:type_name -> user.LoginRecord
+	252, // 101: user.GetLoginHistoryResponse.last_login_at:type_name -> google.protobuf.Timestamp
+	160, // 102: user.ForceLogoutResponse.affected:type_name -> user.AffectedRecords
+	120, // 103: user.CreateOrganizationRequest.policy:type_name -> user.OrganizationPolicy
+	126, // 104: user.CreateOrganizationResponse.organization:type_name -> user.Organization
+	120, // 105: user.UpdateOrganizationPolicyRequest.policy:type_name -> user.OrganizationPolicy
+	126, // 106: user.UpdateOrganizationPolicyResponse.organization:type_name -> user.Organization
+	121, // 107: user.SetOrganizationSSORequest.sso:type_name -> user.OrganizationSSO
+	126, // 108: user.SetOrganizationSSOResponse.organization:type_name -> user.Organization
+	196, // 109: user.SetOrganizationSSOResponse.job:type_name -> user.DeprovisioningJob
+	161, // 110: user.SetOrganizationSSOResponse.operation:type_name -> user.PendingOperation
+	122, // 111: user.SetOrganizationProvisioningRequest.provisioning:type_name -> user.OrganizationProvisioning
+	249, // 112: user.ProvisioningDecision.external_ids:type_name -> user.ProvisioningDecision.ExternalIdsEntry
+	126, // 113: user.SetOrganizationProvisioningResponse.organization:type_name -> user.Organization
+	194, // 114: user.SetOrganizationProvisioningResponse.decisions:type_name -> user.ProvisioningDecision
+	252, // 115: user.DeprovisioningJob.created_at:type_name -> google.protobuf.Timestamp
+	252, // 116: user.DeprovisioningJob.completed_at:type_name -> google.protobuf.Timestamp
+	197, // 117: user.DeprovisioningJob.skipped:type_name -> user.DeprovisioningSkip
+	253, // 118: user.DeprovisionOrganizationMembersRequest.inactive_for:type_name -> google.protobuf.Duration
+	196, // 119: user.DeprovisionOrganizationMembersResponse.job:type_name -> user.DeprovisioningJob
+	161, // 120: user.DeprovisionOrganizationMembersResponse.operation:type_name -> user.PendingOperation
+	196, // 121: user.GetDeprovisioningJobResponse.job:type_name -> user.DeprovisioningJob
+	252, // 122: user.DestroyOrganizationKeyResponse.destroyed_at:type_name -> google.protobuf.Timestamp
+	0,   // 123: user.SetExternalIdResponse.user:type_name -> user.User
+	0,   // 124: user.GetUserByExternalIdResponse.user:type_name -> user.User
+	0,   // 125: user.GetUsersByIdsResponse.users:type_name -> user.User
+	252, // 126: user.ExportUsersRequest.created_after:type_name -> google.protobuf.Timestamp
+	252, // 127: user.ExportUsersRequest.created_before:type_name -> google.protobuf.Timestamp
+	250, // 128: user.ImportUser.external_ids:type_name -> user.ImportUser.ExternalIdsEntry
+	213, // 129: user.ImportUsersRequest.users:type_name -> user.ImportUser
+	215, // 130: user.ImportUsersResponse.skipped:type_name -> user.SkippedImportUser
+	217, // 131: user.ImportUsersResponse.operation:type_name -> user.Operation
+	252, // 132: user.Operation.created_at:type_name -> google.protobuf.Timestamp
+	252, // 133: user.Operation.updated_at:type_name -> google.protobuf.Timestamp
+	252, // 134: user.Operation.completed_at:type_name -> google.protobuf.Timestamp
+	218, // 135: user.Operation.error:type_name -> user.OperationError
+	254, // 136: user.Operation.response:type_name -> google.protobuf.Any
+	217, // 137: user.GetOperationResponse.operation:type_name -> user.Operation
+	217, // 138: user.ListOperationsResponse.operations:type_name -> user.Operation
+	217, // 139: user.RebuildIndexesResponse.operation:type_name -> user.Operation
+	217, // 140: user.RunAccountPurgeResponse.operation:type_name -> user.Operation
+	252, // 141: user.GetAuditLogsRequest.since:type_name -> google.protobuf.Timestamp
+	252, // 142: user.GetAuditLogsRequest.until:type_name -> google.protobuf.Timestamp
+	251, // 143: user.AuditLogEntry.details:type_name -> user.AuditLogEntry.DetailsEntry
+	232, // 144: user.AuditLogEntry.changes:type_name -> user.AuditChange
+	252, // 145: user.AuditLogEntry.created_at:type_name -> google.protobuf.Timestamp
+	233, // 146: user.GetAuditLogsResponse.entries:type_name -> user.AuditLogEntry
+	252, // 147: user.GetUserAtRequest.at:type_name -> google.protobuf.Timestamp
+	0,   // 148: user.GetUserAtResponse.user:type_name -> user.User
+	252, // 149: user.UserEvent.created_at:type_name -> google.protobuf.Timestamp
+	238, // 150: user.ListUserEventsResponse.events:type_name -> user.UserEvent
+	241, // 151: user.GetReplicationStatusResponse.members:type_name -> user.ReplicationMember
+	252, // 152: user.GetReplicationStatusResponse.last_failover_at:type_name -> google.protobuf.Timestamp
+	252, // 153: user.GetReplicationStatusResponse.no_primary_since:type_name -> google.protobuf.Timestamp
+	1,   // 154: user.AuthService.Login:input_type -> user.LoginRequest
+	37,  // 155: user.AuthService.RestoreProfile:input_type -> user.RestoreProfileRequest
+	4,   // 156: user.AuthService.SendLoginCode:input_type -> user.SendLoginCodeRequest
+	6,   // 157: user.AuthService.BeginPasskeyLogin:input_type -> user.BeginPasskeyLoginRequest
+	8,   // 158: user.AuthService.Logout:input_type -> user.LogoutRequest
+	10,  // 159: user.AuthService.RefreshToken:input_type -> user.RefreshTokenRequest
+	12,  // 160: user.AuthService.LogoutAllDevices:input_type -> user.LogoutAllDevicesRequest
+	14,  // 161: user.AuthService.Register:input_type -> user.RegisterRequest
+	17,  // 162: user.AuthService.ApproveLogin:input_type -> user.ApproveLoginRequest
+	19,  // 163: user.AuthService.ListPendingLogins:input_type -> user.ListPendingLoginsRequest
+	21,  // 164: user.AuthService.StartDeviceLogin:input_type -> user.StartDeviceLoginRequest
+	23,  // 165: user.AuthService.ApproveDeviceLogin:input_type -> user.ApproveDeviceLoginRequest
+	25,  // 166: user.AuthService.PollDeviceLogin:input_type -> user.PollDeviceLoginRequest
+	27,  // 167: user.AuthService.CreateActionToken:input_type -> user.CreateActionTokenRequest
+	29,  // 168: user.AuthService.ValidateToken:input_type -> user.ValidateTokenRequest
+	56,  // 169: user.AuthService.StartUnlockChallenge:input_type -> user.StartUnlockChallengeRequest
+	58,  // 170: user.AuthService.UnlockWithChallenge:input_type -> user.UnlockWithChallengeRequest
+	60,  // 171: user.AuthService.StartAccountRecovery:input_type -> user.StartAccountRecoveryRequest
+	62,  // 172: user.AuthService.SecureAccount:input_type -> user.SecureAccountRequest
+	106, // 173: user.AuthService.StartTwoFactorReset:input_type -> user.StartTwoFactorResetRequest
+	108, // 174: user.AuthService.ResetTwoFactor:input_type -> user.ResetTwoFactorRequest
+	110, // 175: user.AuthService.CancelTwoFactorReset:input_type -> user.CancelTwoFactorResetRequest
+	112, // 176: user.AuthService.RequestPasswordReset:input_type -> user.RequestPasswordResetRequest
+	114, // 177: user.AuthService.ResetPassword:input_type -> user.ResetPasswordRequest
+	116, // 178: user.AuthService.EvaluatePassword:input_type -> user.EvaluatePasswordRequest
+	41,  // 179: user.AuthService.GetAuthOptions:input_type -> user.GetAuthOptionsRequest
+	44,  // 180: user.AuthService.CompleteSSOLogin:input_type -> user.CompleteSSOLoginRequest
+	46,  // 181: user.AuthService.LoginWithProvider:input_type -> user.LoginWithProviderRequest
+	48,  // 182: user.AuthService.StartEmailVerification:input_type -> user.StartEmailVerificationRequest
+	50,  // 183: user.AuthService.VerifyEmail:input_type -> user.VerifyEmailRequest
+	52,  // 184: user.AuthService.StartSetPassword:input_type -> user.StartSetPasswordRequest
+	54,  // 185: user.AuthService.SetPassword:input_type -> user.SetPasswordRequest
+	31,  // 186: user.UserService.GetProfile:input_type -> user.GetProfileRequest
+	33,  // 187: user.UserService.UpdateProfile:input_type -> user.UpdateProfileRequest
+	35,  // 188: user.UserService.DeleteProfile:input_type -> user.DeleteProfileRequest
+	39,  // 189: user.UserService.ListUsers:input_type -> user.ListUsersRequest
+	243, // 190: user.UserService.ChangePassword:input_type -> user.ChangePasswordRequest
+	64,  // 191: user.UserService.EnableTwoFactor:input_type -> user.EnableTwoFactorRequest
+	66,  // 192: user.UserService.VerifyTwoFactor:input_type -> user.VerifyTwoFactorRequest
+	68,  // 193: user.UserService.DisableTwoFactor:input_type -> user.DisableTwoFactorRequest
+	118, // 194: user.UserService.GenerateRecoveryCodes:input_type -> user.GenerateRecoveryCodesRequest
+	71,  // 195: user.UserService.BeginCredentialRegistration:input_type -> user.BeginCredentialRegistrationRequest
+	73,  // 196: user.UserService.RegisterCredential:input_type -> user.RegisterCredentialRequest
+	76,  // 197: user.UserService.ListCredentials:input_type -> user.ListCredentialsRequest
+	78,  // 198: user.UserService.RenameCredential:input_type -> user.RenameCredentialRequest
+	80,  // 199: user.UserService.DeleteCredential:input_type -> user.DeleteCredentialRequest
+	83,  // 200: user.UserService.ListKnownDevices:input_type -> user.ListKnownDevicesRequest
+	86,  // 201: user.UserService.ListLinkedIdentities:input_type -> user.ListLinkedIdentitiesRequest
+	88,  // 202: user.UserService.UnlinkIdentity:input_type -> user.UnlinkIdentityRequest
+	91,  // 203: user.UserService.ListSessions:input_type -> user.ListSessionsRequest
+	95,  // 204: user.UserService.WatchSession:input_type -> user.WatchSessionRequest
+	93,  // 205: user.UserService.RevokeSession:input_type -> user.RevokeSessionRequest
+	98,  // 206: user.UserService.CreateApiKey:input_type -> user.CreateApiKeyRequest
+	100, // 207: user.UserService.ListApiKeys:input_type -> user.ListApiKeysRequest
+	102, // 208: user.UserService.RevokeApiKey:input_type -> user.RevokeApiKeyRequest
+	104, // 209: user.UserService.AcceptTerms:input_type -> user.AcceptTermsRequest
+	135, // 210: user.AdminService.ListNotificationTemplates:input_type -> user.ListNotificationTemplatesRequest
+	137, // 211: user.AdminService.PreviewNotificationTemplate:input_type -> user.PreviewNotificationTemplateRequest
+	139, // 212: user.AdminService.SendTestNotification:input_type -> user.SendTestNotificationRequest
+	142, // 213: user.AdminService.ListDeadLetterEvents:input_type -> user.ListDeadLetterEventsRequest
+	144, // 214: user.AdminService.ReplayDeadLetterEvents:input_type -> user.ReplayDeadLetterEventsRequest
+	146, // 215: user.AdminService.ExportConfig:input_type -> user.ExportConfigRequest
+	148, // 216: user.AdminService.ImportConfig:input_type -> user.ImportConfigRequest
+	150, // 217: user.AdminService.LockUser:input_type -> user.LockUserRequest
+	152, // 218: user.AdminService.UnlockUser:input_type -> user.UnlockUserRequest
+	154, // 219: user.AdminService.DeactivateUser:input_type -> user.DeactivateUserRequest
+	156, // 220: user.AdminService.ReactivateUser:input_type -> user.ReactivateUserRequest
+	158, // 221: user.AdminService.HardDeleteUser:input_type -> user.HardDeleteUserRequest
+	180, // 222: user.AdminService.ResetUserPassword:input_type -> user.ResetUserPasswordRequest
+	182, // 223: user.AdminService.GetLoginHistory:input_type -> user.GetLoginHistoryRequest
+	185, // 224: user.AdminService.ForceLogout:input_type -> user.ForceLogoutRequest
+	187, // 225: user.AdminService.CreateOrganization:input_type -> user.CreateOrganizationRequest
+	189, // 226: user.AdminService.UpdateOrganizationPolicy:input_type -> user.UpdateOrganizationPolicyRequest
+	191, // 227: user.AdminService.SetOrganizationSSO:input_type -> user.SetOrganizationSSORequest
+	193, // 228: user.AdminService.SetOrganizationProvisioning:input_type -> user.SetOrganizationProvisioningRequest
+	198, // 229: user.AdminService.DeprovisionOrganizationMembers:input_type -> user.DeprovisionOrganizationMembersRequest
+	200, // 230: user.AdminService.GetDeprovisioningJob:input_type -> user.GetDeprovisioningJobRequest
+	202, // 231: user.AdminService.SetOrganizationMember:input_type -> user.SetOrganizationMemberRequest
+	162, // 232: user.AdminService.CancelOperation:input_type -> user.CancelOperationRequest
+	165, // 233: user.AdminService.ScheduleAction:input_type -> user.ScheduleActionRequest
+	167, // 234: user.AdminService.CancelScheduledAction:input_type -> user.CancelScheduledActionRequest
+	169, // 235: user.AdminService.ListScheduledActions:input_type -> user.ListScheduledActionsRequest
+	172, // 236: user.AdminService.EraseUser:input_type -> user.EraseUserRequest
+	174, // 237: user.AdminService.GetWorkflow:input_type -> user.GetWorkflowRequest
+	176, // 238: user.AdminService.ListWorkflows:input_type -> user.ListWorkflowsRequest
+	178, // 239: user.AdminService.RetryWorkflow:input_type -> user.RetryWorkflowRequest
+	204, // 240: user.AdminService.DestroyOrganizationKey:input_type -> user.DestroyOrganizationKeyRequest
+	206, // 241: user.AdminService.SetExternalId:input_type -> user.SetExternalIdRequest
+	208, // 242: user.AdminService.GetUserByExternalId:input_type -> user.GetUserByExternalIdRequest
+	210, // 243: user.AdminService.GetUsersByIds:input_type -> user.GetUsersByIdsRequest
+	212, // 244: user.AdminService.ExportUsers:input_type -> user.ExportUsersRequest
+	214, // 245: user.AdminService.ImportUsers:input_type -> user.ImportUsersRequest
+	219, // 246: user.AdminService.GetOperation:input_type -> user.GetOperationRequest
+	221, // 247: user.AdminService.ListOperations:input_type -> user.ListOperationsRequest
+	223, // 248: user.AdminService.WatchOperation:input_type -> user.WatchOperationRequest
+	224, // 249: user.AdminService.RebuildIndexes:input_type -> user.RebuildIndexesRequest
+	226, // 250: user.AdminService.RunAccountPurge:input_type -> user.RunAccountPurgeRequest
+	229, // 251: user.AdminService.ReplayAuditLog:input_type -> user.ReplayAuditLogRequest
+	231, // 252: user.AdminService.GetAuditLogs:input_type -> user.GetAuditLogsRequest
+	235, // 253: user.AdminService.GetUserAt:input_type -> user.GetUserAtRequest
+	237, // 254: user.AdminService.ListUserEvents:input_type -> user.ListUserEventsRequest
+	240, // 255: user.AdminService.GetReplicationStatus:input_type -> user.GetReplicationStatusRequest
+	128, // 256: user.OrganizationService.ListProfileChangeRequests:input_type -> user.ListProfileChangeRequestsRequest
+	130, // 257: user.OrganizationService.ApproveProfileChange:input_type -> user.ApproveProfileChangeRequest
+	132, // 258: user.OrganizationService.RejectProfileChange:input_type -> user.RejectProfileChangeRequest
+	2,   // 259: user.AuthService.Login:output_type -> user.LoginResponse
+	38,  // 260: user.AuthService.RestoreProfile:output_type -> user.RestoreProfileResponse
+	5,   // 261: user.AuthService.SendLoginCode:output_type -> user.SendLoginCodeResponse
+	7,   // 262: user.AuthService.BeginPasskeyLogin:output_type -> user.BeginPasskeyLoginResponse
+	9,   // 263: user.AuthService.Logout:output_type -> user.LogoutResponse
+	11,  // 264: user.AuthService.RefreshToken:output_type -> user.RefreshTokenResponse
+	13,  // 265: user.AuthService.LogoutAllDevices:output_type -> user.LogoutAllDevicesResponse
+	15,  // 266: user.AuthService.Register:output_type -> user.RegisterResponse
+	18,  // 267: user.AuthService.ApproveLogin:output_type -> user.ApproveLoginResponse
+	20,  // 268: user.AuthService.ListPendingLogins:output_type -> user.ListPendingLoginsResponse
+	22,  // 269: user.AuthService.StartDeviceLogin:output_type -> user.StartDeviceLoginResponse
+	24,  // 270: user.AuthService.ApproveDeviceLogin:output_type -> user.ApproveDeviceLoginResponse
+	26,  // 271: user.AuthService.PollDeviceLogin:output_type -> user.PollDeviceLoginResponse
+	28,  // 272: user.AuthService.CreateActionToken:output_type -> user.CreateActionTokenResponse
+	30,  // 273: user.AuthService.ValidateToken:output_type -> user.ValidateTokenResponse
+	57,  // 274: user.AuthService.StartUnlockChallenge:output_type -> user.StartUnlockChallengeResponse
+	59,  // 275: user.AuthService.UnlockWithChallenge:output_type -> user.UnlockWithChallengeResponse
+	61,  // 276: user.AuthService.StartAccountRecovery:output_type -> user.StartAccountRecoveryResponse
+	63,  // 277: user.AuthService.SecureAccount:output_type -> user.SecureAccountResponse
+	107, // 278: user.AuthService.StartTwoFactorReset:output_type -> user.StartTwoFactorResetResponse
+	109, // 279: user.AuthService.ResetTwoFactor:output_type -> user.ResetTwoFactorResponse
+	111, // 280: user.AuthService.CancelTwoFactorReset:output_type -> user.CancelTwoFactorResetResponse
+	113, // 281: user.AuthService.RequestPasswordReset:output_type -> user.RequestPasswordResetResponse
+	115, // 282: user.AuthService.ResetPassword:output_type -> user.ResetPasswordResponse
+	117, // 283: user.AuthService.EvaluatePassword:output_type -> user.EvaluatePasswordResponse
+	43,  // 284: user.AuthService.GetAuthOptions:output_type -> user.GetAuthOptionsResponse
+	45,  // 285: user.AuthService.CompleteSSOLogin:output_type -> user.CompleteSSOLoginResponse
+	47,  // 286: user.AuthService.LoginWithProvider:output_type -> user.LoginWithProviderResponse
+	49,  // 287: user.AuthService.StartEmailVerification:output_type -> user.StartEmailVerificationResponse
+	51,  // 288: user.AuthService.VerifyEmail:output_type -> user.VerifyEmailResponse
+	53,  // 289: user.AuthService.StartSetPassword:output_type -> user.StartSetPasswordResponse
+	55,  // 290: user.AuthService.SetPassword:output_type -> user.SetPasswordResponse
+	32,  // 291: user.UserService.GetProfile:output_type -> user.GetProfileResponse
+	34,  // 292: user.UserService.UpdateProfile:output_type -> user.UpdateProfileResponse
+	36,  // 293: user.UserService.DeleteProfile:output_type -> user.DeleteProfileResponse
+	40,  // 294: user.UserService.ListUsers:output_type -> user.ListUsersResponse
+	244, // 295: user.UserService.ChangePassword:output_type -> user.ChangePasswordResponse
+	65,  // 296: user.UserService.EnableTwoFactor:output_type -> user.EnableTwoFactorResponse
+	67,  // 297: user.UserService.VerifyTwoFactor:output_type -> user.VerifyTwoFactorResponse
+	69,  // 298: user.UserService.DisableTwoFactor:output_type -> user.DisableTwoFactorResponse
+	119, // 299: user.UserService.GenerateRecoveryCodes:output_type -> user.GenerateRecoveryCodesResponse
+	72,  // 300: user.UserService.BeginCredentialRegistration:output_type -> user.BeginCredentialRegistrationResponse
+	74,  // 301: user.UserService.RegisterCredential:output_type -> user.RegisterCredentialResponse
+	77,  // 302: user.UserService.ListCredentials:output_type -> user.ListCredentialsResponse
+	79,  // 303: user.UserService.RenameCredential:output_type -> user.RenameCredentialResponse
+	81,  // 304: user.UserService.DeleteCredential:output_type -> user.DeleteCredentialResponse
+	84,  // 305: user.UserService.ListKnownDevices:output_type -> user.ListKnownDevicesResponse
+	87,  // 306: user.UserService.ListLinkedIdentities:output_type -> user.ListLinkedIdentitiesResponse
+	89,  // 307: user.UserService.UnlinkIdentity:output_type -> user.UnlinkIdentityResponse
+	92,  // 308: user.UserService.ListSessions:output_type -> user.ListSessionsResponse
+	96,  // 309: user.UserService.WatchSession:output_type -> user.SessionEvent
+	94,  // 310: user.UserService.RevokeSession:output_type -> user.RevokeSessionResponse
+	99,  // 311: user.UserService.CreateApiKey:output_type -> user.CreateApiKeyResponse
+	101, // 312: user.UserService.ListApiKeys:output_type -> user.ListApiKeysResponse
+	103, // 313: user.UserService.RevokeApiKey:output_type -> user.RevokeApiKeyResponse
+	105, // 314: user.UserService.AcceptTerms:output_type -> user.AcceptTermsResponse
+	136, // 315: user.AdminService.ListNotificationTemplates:output_type -> user.ListNotificationTemplatesResponse
+	138, // 316: user.AdminService.PreviewNotificationTemplate:output_type -> user.PreviewNotificationTemplateResponse
+	140, // 317: user.AdminService.SendTestNotification:output_type -> user.SendTestNotificationResponse
+	143, // 318: user.AdminService.ListDeadLetterEvents:output_type -> user.ListDeadLetterEventsResponse
+	145, // 319: user.AdminService.ReplayDeadLetterEvents:output_type -> user.ReplayDeadLetterEventsResponse
+	147, // 320: user.AdminService.ExportConfig:output_type -> user.ExportConfigResponse
+	149, // 321: user.AdminService.ImportConfig:output_type -> user.ImportConfigResponse
+	151, // 322: user.AdminService.LockUser:output_type -> user.LockUserResponse
+	153, // 323: user.AdminService.UnlockUser:output_type -> user.UnlockUserResponse
+	155, // 324: user.AdminService.DeactivateUser:output_type -> user.DeactivateUserResponse
+	157, // 325: user.AdminService.ReactivateUser:output_type -> user.ReactivateUserResponse
+	159, // 326: user.AdminService.HardDeleteUser:output_type -> user.HardDeleteUserResponse
+	181, // 327: user.AdminService.ResetUserPassword:output_type -> user.ResetUserPasswordResponse
+	184, // 328: user.AdminService.GetLoginHistory:output_type -> user.GetLoginHistoryResponse
+	186, // 329: user.AdminService.ForceLogout:output_type -> user.ForceLogoutResponse
+	188, // 330: user.AdminService.CreateOrganization:output_type -> user.CreateOrganizationResponse
+	190, // 331: user.AdminService.UpdateOrganizationPolicy:output_type -> user.UpdateOrganizationPolicyResponse
+	192, // 332: user.AdminService.SetOrganizationSSO:output_type -> user.SetOrganizationSSOResponse
+	195, // 333: user.AdminService.SetOrganizationProvisioning:output_type -> user.SetOrganizationProvisioningResponse
+	199, // 334: user.AdminService.DeprovisionOrganizationMembers:output_type -> user.DeprovisionOrganizationMembersResponse
+	201, // 335: user.AdminService.GetDeprovisioningJob:output_type -> user.GetDeprovisioningJobResponse
+	203, // 336: user.AdminService.SetOrganizationMember:output_type -> user.SetOrganizationMemberResponse
+	163, // 337: user.AdminService.CancelOperation:output_type -> user.CancelOperationResponse
+	166, // 338: user.AdminService.ScheduleAction:output_type -> user.ScheduleActionResponse
+	168, // 339: user.AdminService.CancelScheduledAction:output_type -> user.CancelScheduledActionResponse
+	170, // 340: user.AdminService.ListScheduledActions:output_type -> user.ListScheduledActionsResponse
+	173, // 341: user.AdminService.EraseUser:output_type -> user.EraseUserResponse
+	175, // 342: user.AdminService.GetWorkflow:output_type -> user.GetWorkflowResponse
+	177, // 343: user.AdminService.ListWorkflows:output_type -> user.ListWorkflowsResponse
+	179, // 344: user.AdminService.RetryWorkflow:output_type -> user.RetryWorkflowResponse
+	205, // 345: user.AdminService.DestroyOrganizationKey:output_type -> user.DestroyOrganizationKeyResponse
+	207, // 346: user.AdminService.SetExternalId:output_type -> user.SetExternalIdResponse
+	209, // 347: user.AdminService.GetUserByExternalId:output_type -> user.GetUserByExternalIdResponse
+	211, // 348: user.AdminService.GetUsersByIds:output_type -> user.GetUsersByIdsResponse
+	0,   // 349: user.AdminService.ExportUsers:output_type -> user.User
+	216, // 350: user.AdminService.ImportUsers:output_type -> user.ImportUsersResponse
+	220, // 351: user.AdminService.GetOperation:output_type -> user.GetOperationResponse
+	222, // 352: user.AdminService.ListOperations:output_type -> user.ListOperationsResponse
+	217, // 353: user.AdminService.WatchOperation:output_type -> user.Operation
+	225, // 354: user.AdminService.RebuildIndexes:output_type -> user.RebuildIndexesResponse
+	227, // 355: user.AdminService.RunAccountPurge:output_type -> user.RunAccountPurgeResponse
+	230, // 356: user.AdminService.ReplayAuditLog:output_type -> user.ReplayAuditLogResponse
+	234, // 357: user.AdminService.GetAuditLogs:output_type -> user.GetAuditLogsResponse
+	236, // 358: user.AdminService.GetUserAt:output_type -> user.GetUserAtResponse
+	239, // 359: user.AdminService.ListUserEvents:output_type -> user.ListUserEventsResponse
+	242, // 360: user.AdminService.GetReplicationStatus:output_type -> user.GetReplicationStatusResponse
+	129, // 361: user.OrganizationService.ListProfileChangeRequests:output_type -> user.ListProfileChangeRequestsResponse
+	131, // 362: user.OrganizationService.ApproveProfileChange:output_type -> user.ApproveProfileChangeResponse
+	133, // 363: user.OrganizationService.RejectProfileChange:output_type -> user.RejectProfileChangeResponse
+	259, // [259:364] is the sub-list for method output_type
+	154, // [154:259] is the sub-list for method input_type
+	154, // [154:154] is the sub-list for extension type_name
+	154, // [154:154] is the sub-list for extension extendee
+	0,   // [0:154] is the sub-list for field type_name
 }
 
 func init() { file_proto_user_proto_init() }
@@ -1111,15 +16621,22 @@ func file_proto_user_proto_init() {
 	if File_proto_user_proto != nil {
 		return
 	}
+	file_proto_contract_proto_init()
+	file_proto_user_proto_msgTypes[39].OneofWrappers = []any{}
+	file_proto_user_proto_msgTypes[212].OneofWrappers = []any{}
+	file_proto_user_proto_msgTypes[217].OneofWrappers = []any{
+		(*Operation_Error)(nil),
+		(*Operation_Response)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_user_proto_rawDesc), len(file_proto_user_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   17,
+			NumMessages:   252,
 			NumExtensions: 0,
-			NumServices:   2,
+			NumServices:   4,
 		},
 		GoTypes:           file_proto_user_proto_goTypes,
 		DependencyIndexes: file_proto_user_proto_depIdxs,
@@ -0,0 +1,326 @@
+// Package provisioning evaluates an organization's provisioning rules
+// against the claims of an identity its provider signs in for the first
+// time.
+package provisioning
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"user-management/authmd"
+	"user-management/models"
+	"user-management/sso"
+	"user-management/utils"
+)
+
+// DefaultGroupsClaim is the claim groups are read from when the rules
+// don't name one
+const DefaultGroupsClaim = "groups"
+
+// fallbackName names new accounts when neither the claims nor the email
+// address give a name
+const fallbackName = "Member"
+
+// blockedGroupPrefix marks blocklist entries that match a group
+const blockedGroupPrefix = "group:"
+
+// Limits on the size of a rule set
+const (
+	maxGroupRoles   = 100
+	maxRoleMappings = 100
+	maxMappedRoles  = 20
+	maxAttributes   = 20
+	maxBlocklist    = 1000
+)
+
+// rolePattern is the form of roles granted by role mappings
+var rolePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,63}$`)
+
+// Decision is what the rules decide for an identity
+type Decision struct {
+	// Allowed is false when a blocklist entry matched
+	Allowed bool
+	// Reason says which entry blocked the identity
+	Reason string
+	// OrgRole is the role a new account gets, and MatchedGroup the group
+	// whose mapping chose it. MatchedGroup is empty for the default role.
+	OrgRole      string
+	MatchedGroup string
+	// Roles are the roles the role mappings grant, sorted
+	Roles []string
+	// Name and ExternalIDs are the fields of a new account. Name is made
+	// from the email address when no claim holds a valid name.
+	Name        string
+	ExternalIDs []models.ExternalID
+}
+
+// Evaluate applies rules to the claims of an ID token. Nil rules allow
+// every identity and give new accounts the member role.
+func Evaluate(rules *models.ProvisioningRules, claims map[string]any) Decision {
+	if rules == nil {
+		rules = &models.ProvisioningRules{}
+	}
+
+	email := strings.ToLower(claimString(claims, "email"))
+	groups := claimGroups(rules, claims)
+
+	if entry := blockedBy(rules.Blocklist, email, groups); entry != "" {
+		return Decision{Reason: fmt.Sprintf("blocked by %q", entry)}
+	}
+
+	decision := Decision{
+		Allowed: true,
+		OrgRole: rules.DefaultRole,
+		Roles:   mappedRoles(rules, groups),
+	}
+	if decision.OrgRole == "" {
+		decision.OrgRole = models.OrgRoleMember
+	}
+	for _, mapping := range rules.GroupRoles {
+		if containsString(groups, mapping.Group) {
+			decision.OrgRole = mapping.Role
+			decision.MatchedGroup = mapping.Group
+			break
+		}
+	}
+
+	name := claimString(claims, "name")
+	for _, attribute := range rules.Attributes {
+		value := utils.SanitizeString(claimString(claims, attribute.Claim))
+		if value == "" {
+			continue
+		}
+		if attribute.Field == models.ProvisioningFieldName {
+			name = value
+		} else if system, ok := strings.CutPrefix(attribute.Field, models.ProvisioningFieldExternalID); ok {
+			if utils.ValidateExternalID(value) == nil {
+				decision.ExternalIDs = append(decision.ExternalIDs, models.ExternalID{System: system, ID: value})
+			}
+		}
+	}
+	sort.Slice(decision.ExternalIDs, func(i, j int) bool {
+		return decision.ExternalIDs[i].System < decision.ExternalIDs[j].System
+	})
+
+	decision.Name = utils.SanitizeString(name)
+	if utils.ValidateName(decision.Name, "name") != nil {
+		decision.Name = NameFromEmail(email)
+	}
+
+	return decision
+}
+
+// MappedRoles returns the roles the role mappings of rules grant to the
+// groups in claims, sorted
+func MappedRoles(rules *models.ProvisioningRules, claims map[string]any) []string {
+	if rules == nil {
+		return nil
+	}
+	return mappedRoles(rules, claimGroups(rules, claims))
+}
+
+func mappedRoles(rules *models.ProvisioningRules, groups []string) []string {
+	var roles []string
+	for _, mapping := range rules.RoleMappings {
+		if !containsString(groups, mapping.Group) {
+			continue
+		}
+		for _, role := range mapping.Roles {
+			if !containsString(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// claimGroups returns the groups the user is in
+func claimGroups(rules *models.ProvisioningRules, claims map[string]any) []string {
+	groupsClaim := rules.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = DefaultGroupsClaim
+	}
+	return claimStrings(claims, groupsClaim)
+}
+
+// Validate checks rules set by an admin. Blocklist entries and systems are
+// expected in lower case.
+func Validate(rules *models.ProvisioningRules) error {
+	if rules.DefaultRole != "" && !validRole(rules.DefaultRole) {
+		return fmt.Errorf("default role must be %q or %q", models.OrgRoleMember, models.OrgRoleAdmin)
+	}
+
+	if len(rules.GroupRoles) > maxGroupRoles {
+		return fmt.Errorf("at most %d group roles are allowed", maxGroupRoles)
+	}
+	for _, mapping := range rules.GroupRoles {
+		if mapping.Group == "" {
+			return fmt.Errorf("group roles need a group")
+		}
+		if !validRole(mapping.Role) {
+			return fmt.Errorf("role of group %q must be %q or %q", mapping.Group, models.OrgRoleMember, models.OrgRoleAdmin)
+		}
+	}
+
+	if len(rules.RoleMappings) > maxRoleMappings {
+		return fmt.Errorf("at most %d role mappings are allowed", maxRoleMappings)
+	}
+	for _, mapping := range rules.RoleMappings {
+		if mapping.Group == "" {
+			return fmt.Errorf("role mappings need a group")
+		}
+		if len(mapping.Roles) == 0 || len(mapping.Roles) > maxMappedRoles {
+			return fmt.Errorf("role mapping of group %q needs 1 to %d roles", mapping.Group, maxMappedRoles)
+		}
+		for _, role := range mapping.Roles {
+			if err := validateMappedRole(role); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(rules.Attributes) > maxAttributes {
+		return fmt.Errorf("at most %d attributes are allowed", maxAttributes)
+	}
+	fields := make(map[string]bool, len(rules.Attributes))
+	for _, attribute := range rules.Attributes {
+		if attribute.Claim == "" {
+			return fmt.Errorf("attributes need a claim")
+		}
+		if fields[attribute.Field] {
+			return fmt.Errorf("field %q is mapped more than once", attribute.Field)
+		}
+		fields[attribute.Field] = true
+
+		if attribute.Field == models.ProvisioningFieldName {
+			continue
+		}
+		system, ok := strings.CutPrefix(attribute.Field, models.ProvisioningFieldExternalID)
+		if !ok {
+			return fmt.Errorf("field must be %q or start with %q", models.ProvisioningFieldName, models.ProvisioningFieldExternalID)
+		}
+		if err := utils.ValidateExternalSystem(system); err != nil {
+			return err
+		}
+	}
+
+	if len(rules.Blocklist) > maxBlocklist {
+		return fmt.Errorf("at most %d blocklist entries are allowed", maxBlocklist)
+	}
+	for _, entry := range rules.Blocklist {
+		if group, ok := strings.CutPrefix(entry, blockedGroupPrefix); ok {
+			if group == "" {
+				return fmt.Errorf("blocklist entry %q needs a group", entry)
+			}
+			continue
+		}
+		if domain, ok := strings.CutPrefix(entry, "@"); ok {
+			if err := sso.ValidateDomain(domain); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := utils.ValidateEmail(entry); err != nil {
+			return fmt.Errorf("blocklist entry %q must be an email address, @domain or group:name", entry)
+		}
+	}
+
+	return nil
+}
+
+// blockedBy returns the blocklist entry matching the email address or one
+// of the groups, if any
+func blockedBy(blocklist []string, email string, groups []string) string {
+	domain := ""
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		domain = email[at:]
+	}
+
+	for _, entry := range blocklist {
+		if group, ok := strings.CutPrefix(entry, blockedGroupPrefix); ok {
+			if containsString(groups, group) {
+				return entry
+			}
+		} else if entry == email || (domain != "" && entry == domain) {
+			return entry
+		}
+	}
+	return ""
+}
+
+// claimString returns a string or number claim as a string
+func claimString(claims map[string]any, name string) string {
+	switch value := claims[name].(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return ""
+}
+
+// claimStrings returns a claim holding a list of strings, or a single one
+func claimStrings(claims map[string]any, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	case []string:
+		return value
+	}
+	return nil
+}
+
+// NameFromEmail makes a valid name from the local part of an email
+// address, such as "jane doe" from "jane.doe@example.com"
+func NameFromEmail(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+	name := strings.Join(strings.FieldsFunc(local, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-' && r != '\''
+	}), " ")
+	if len(name) > utils.MaxNameLength {
+		name = strings.TrimSpace(name[:utils.MaxNameLength])
+	}
+	if utils.ValidateName(name, "name") != nil {
+		return fallbackName
+	}
+	return name
+}
+
+// validateMappedRole checks a role granted by a role mapping. An identity
+// provider can't grant the service's admin role, or an organization role,
+// which role mappings don't set.
+func validateMappedRole(role string) error {
+	if !rolePattern.MatchString(role) {
+		return fmt.Errorf("role %q may only contain lowercase letters, digits and _.:- and be at most 64 characters", role)
+	}
+	if role == models.RoleAdmin || strings.HasPrefix(role, authmd.OrgRolePrefix) {
+		return fmt.Errorf("role %q can't be granted by a role mapping", role)
+	}
+	return nil
+}
+
+func validRole(role string) bool {
+	return role == models.OrgRoleMember || role == models.OrgRoleAdmin
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+// Package requestctx carries per-request information, such as the request
+// ID, through the context so it can be attached to logs and queries
+package requestctx
+
+import (
+	"context"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// HeaderRequestID is the metadata key clients and proxies use to pass a
+// request ID, and the response header it is returned in
+const HeaderRequestID = "x-request-id"
+
+// HeaderTraceParent is the W3C Trace Context header set by tracing proxies
+// and clients
+const HeaderTraceParent = "traceparent"
+
+// traceParentPattern matches version 00 traceparent values and captures the
+// trace ID
+var traceParentPattern = regexp.MustCompile(`^00-([0-9a-f]{32})-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// maxRequestIDLength bounds client-supplied request IDs, which end up in
+// logs and query comments
+const maxRequestIDLength = 128
+
+// Info describes the RPC a context belongs to
+type Info struct {
+	RequestID string
+	// TraceID is the W3C trace ID the caller sent, if any
+	TraceID string
+	// Method is the full gRPC method name, e.g. /user.AuthService/Login
+	Method string
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying info
+func NewContext(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// FromContext returns the request info stored in ctx
+func FromContext(ctx context.Context) (Info, bool) {
+	info, ok := ctx.Value(contextKey{}).(Info)
+	return info, ok
+}
+
+// UnaryServerInterceptor assigns every request an ID, reusing the caller's
+// x-request-id when it sends one, and returns it in the response headers.
+// It also picks up the trace ID from a traceparent header.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID, traceID := "", ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(HeaderRequestID); len(values) > 0 && len(values[0]) <= maxRequestIDLength {
+				requestID = values[0]
+			}
+			if values := md.Get(HeaderTraceParent); len(values) > 0 {
+				traceID = parseTraceParent(values[0])
+			}
+		}
+		if requestID == "" {
+			requestID = primitive.NewObjectID().Hex()
+		}
+
+		grpc.SetHeader(ctx, metadata.Pairs(HeaderRequestID, requestID))
+
+		ctx = NewContext(ctx, Info{
+			RequestID: requestID,
+			TraceID:   traceID,
+			Method:    info.FullMethod,
+		})
+		return handler(ctx, req)
+	}
+}
+
+// parseTraceParent returns the trace ID of a traceparent value, or "" when
+// the value is malformed or the trace ID is all zeros
+func parseTraceParent(value string) string {
+	match := traceParentPattern.FindStringSubmatch(value)
+	if match == nil || match[1] == "00000000000000000000000000000000" {
+		return ""
+	}
+	return match[1]
+}
@@ -0,0 +1,190 @@
+// Package sanitize rejects request strings that carry MongoDB operator
+// payloads where plain values are expected. Handlers build queries from
+// typed proto fields, so a string can never become an operator on its
+// own; this is a second line of defence for values that reach queries,
+// aggregation expressions or field paths.
+package sanitize
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// exemptFields are the fields, by full name, whose legitimate values may
+// fail the check: passwords users choose, password hashes, which start
+// with "$", and configuration snapshots, which are parsed before use.
+// None of them reach a query as they are. Other secrets, such as tokens,
+// codes and client secrets, are plain values and are checked.
+var exemptFields = map[protoreflect.FullName]bool{
+	"user.LoginRequest.password":                  true,
+	"user.RegisterRequest.password":               true,
+	"user.RestoreProfileRequest.password":         true,
+	"user.UnlockWithChallengeRequest.password":    true,
+	"user.DisableTwoFactorRequest.password":       true,
+	"user.ResetTwoFactorRequest.password":         true,
+	"user.EvaluatePasswordRequest.password":       true,
+	"user.GenerateRecoveryCodesRequest.password":  true,
+	"user.SetPasswordRequest.new_password":        true,
+	"user.SecureAccountRequest.new_password":      true,
+	"user.ResetPasswordRequest.new_password":      true,
+	"user.ChangePasswordRequest.current_password": true,
+	"user.ChangePasswordRequest.new_password":     true,
+	"user.ImportUser.password_hash":               true,
+	"user.ImportConfigRequest.snapshot":           true,
+}
+
+// operatorObject matches an embedded query object such as {"$ne": ""}
+var operatorObject = regexp.MustCompile(`\{\s*["']?\$`)
+
+// Error describes the field that was rejected
+type Error struct {
+	Field  string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+// CheckValue reports why s is not a plain value, or nil if it is
+func CheckValue(s string) error {
+	switch {
+	case strings.ContainsRune(s, 0):
+		return fmt.Errorf("contains a null character")
+	case strings.HasPrefix(s, "$"):
+		return fmt.Errorf("must not start with $")
+	case operatorObject.MatchString(s):
+		return fmt.Errorf("must not contain query operators")
+	}
+	return nil
+}
+
+// checkKey reports why a map key cannot be used as a document field name
+func checkKey(s string) error {
+	if err := CheckValue(s); err != nil {
+		return err
+	}
+	if strings.Contains(s, ".") {
+		return fmt.Errorf("must not contain dots")
+	}
+	return nil
+}
+
+// CheckMessage checks every string, repeated string and map in msg,
+// including nested messages, and returns the first rejected field
+func CheckMessage(msg proto.Message) error {
+	return checkMessage(msg.ProtoReflect(), "")
+}
+
+func checkMessage(m protoreflect.Message, prefix string) error {
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if exemptFields[fd.FullName()] {
+			return true
+		}
+		err = checkField(fd, v, prefix+string(fd.Name()))
+		return err == nil
+	})
+	return err
+}
+
+func checkField(fd protoreflect.FieldDescriptor, v protoreflect.Value, path string) error {
+	switch {
+	case fd.IsMap():
+		var err error
+		v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+			key := k.String()
+			if reason := checkKey(key); reason != nil {
+				err = &Error{Field: path, Reason: "key " + reason.Error()}
+				return false
+			}
+			err = checkSingle(fd.MapValue(), mv, path+"["+key+"]")
+			return err == nil
+		})
+		return err
+	case fd.IsList():
+		list := v.List()
+		for i := 0; i < list.Len(); i++ {
+			if err := checkSingle(fd, list.Get(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return checkSingle(fd, v, path)
+	}
+}
+
+func checkSingle(fd protoreflect.FieldDescriptor, v protoreflect.Value, path string) error {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		if reason := CheckValue(v.String()); reason != nil {
+			return &Error{Field: path, Reason: reason.Error()}
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return checkMessage(v.Message(), path+".")
+	}
+	return nil
+}
+
+// UnaryServerInterceptor rejects requests with operator payloads before
+// they reach a handler. Requests to exemptMethods, by full name, are not
+// checked; their strings must never reach a query.
+func UnaryServerInterceptor(exemptMethods ...string) grpc.UnaryServerInterceptor {
+	exempt := methodSet(exemptMethods)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if msg, ok := req.(proto.Message); ok && !exempt[info.FullMethod] {
+			if err := CheckMessage(msg); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor checks every message a streaming RPC receives
+// like UnaryServerInterceptor, as the handler reads it
+func StreamServerInterceptor(exemptMethods ...string) grpc.StreamServerInterceptor {
+	exempt := methodSet(exemptMethods)
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if exempt[info.FullMethod] {
+			return handler(srv, stream)
+		}
+		return handler(srv, &serverStream{ServerStream: stream})
+	}
+}
+
+// serverStream is a stream that checks the messages it receives
+type serverStream struct {
+	grpc.ServerStream
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(proto.Message); ok {
+		if err := CheckMessage(msg); err != nil {
+			return status.Errorf(codes.InvalidArgument, "%s", err.Error())
+		}
+	}
+	return nil
+}
+
+func methodSet(methods []string) map[string]bool {
+	set := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		set[method] = true
+	}
+	return set
+}
@@ -0,0 +1,349 @@
+package sanitize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	pb "user-management/proto"
+)
+
+func TestCheckMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		msg   proto.Message
+		field string // rejected field, empty when the message passes
+	}{
+		{
+			name: "plain values",
+			msg:  &pb.LoginRequest{Email: "jane@example.com", Password: "Password1!"},
+		},
+		{
+			name:  "operator prefix",
+			msg:   &pb.LoginRequest{Email: "$ne"},
+			field: "email",
+		},
+		{
+			name:  "embedded operator object",
+			msg:   &pb.UpdateProfileRequest{Name: `{"$gt": ""}`},
+			field: "name",
+		},
+		{
+			name:  "null character",
+			msg:   &pb.UpdateProfileRequest{Name: "jane\x00"},
+			field: "name",
+		},
+		{
+			name: "password is exempt",
+			msg:  &pb.LoginRequest{Email: "jane@example.com", Password: "$ne"},
+		},
+		{
+			name: "current and new password are exempt",
+			msg:  &pb.ChangePasswordRequest{CurrentPassword: "$2a$10$abc", NewPassword: `{"$ne": ""}`},
+		},
+		{
+			name:  "token is checked",
+			msg:   &pb.ValidateTokenRequest{Token: "$token"},
+			field: "token",
+		},
+		{
+			name:  "refresh token is checked",
+			msg:   &pb.RefreshTokenRequest{RefreshToken: `{"$ne": ""}`},
+			field: "refresh_token",
+		},
+		{
+			name:  "approval token is checked",
+			msg:   &pb.ApproveLoginRequest{PendingLoginId: "64b7f0c2a1b2c3d4e5f60718", ApprovalToken: "$where"},
+			field: "approval_token",
+		},
+		{
+			name:  "device code is checked",
+			msg:   &pb.PollDeviceLoginRequest{DeviceCode: "$code"},
+			field: "device_code",
+		},
+		{
+			name:  "provider token is checked",
+			msg:   &pb.LoginWithProviderRequest{Token: `{"$gt": ""}`},
+			field: "token",
+		},
+		{
+			name:  "OAuth code is checked",
+			msg:   &pb.CompleteSSOLoginRequest{Code: "$ne"},
+			field: "code",
+		},
+		{
+			name:  "passkey assertion is checked",
+			msg:   &pb.LoginRequest{Email: "jane@example.com", PasskeyAssertion: `{"id": {"$ne": ""}}`},
+			field: "passkey_assertion",
+		},
+		{
+			name:  "passkey credential is checked",
+			msg:   &pb.RegisterCredentialRequest{Credential: `{"$where": "sleep(1000)"}`},
+			field: "credential",
+		},
+		{
+			name:  "client secret is checked",
+			msg:   &pb.SetOrganizationSSORequest{OrgId: "64b7f0c2a1b2c3d4e5f60718", Sso: &pb.OrganizationSSO{ClientSecret: "$ne"}},
+			field: "sso.client_secret",
+		},
+		{
+			name:  "other fields beside an exempt one are checked",
+			msg:   &pb.LoginRequest{Email: "$where", Password: "$ne"},
+			field: "email",
+		},
+		{
+			name: "snapshot is exempt",
+			msg:  &pb.ImportConfigRequest{Snapshot: `{"$set": {}}`},
+		},
+		{
+			name: "nested password hash is exempt",
+			msg: &pb.ImportUsersRequest{Users: []*pb.ImportUser{
+				{Email: "jane@example.com", PasswordHash: "$argon2id$v=19$m=65536,t=3,p=4$salt$hash"},
+			}},
+		},
+		{
+			name: "nested field",
+			msg: &pb.ImportUsersRequest{Users: []*pb.ImportUser{
+				{Email: "jane@example.com"},
+				{Email: "$ne"},
+			}},
+			field: "users[1].email",
+		},
+		{
+			name:  "repeated string",
+			msg:   &pb.GetUsersByIdsRequest{UserIds: []string{"64b7f0c2a1b2c3d4e5f60718", "$in"}},
+			field: "user_ids[1]",
+		},
+		{
+			name:  "map value",
+			msg:   &pb.PreviewNotificationTemplateRequest{Name: "welcome", Data: map[string]string{"name": "$ne"}},
+			field: "data[name]",
+		},
+		{
+			name:  "map key with a dot",
+			msg:   &pb.PreviewNotificationTemplateRequest{Name: "welcome", Data: map[string]string{"user.name": "Jane"}},
+			field: "data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckMessage(tt.msg)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("CheckMessage() = %v, want nil", err)
+				}
+				return
+			}
+			var checkErr *Error
+			if !errors.As(err, &checkErr) {
+				t.Fatalf("CheckMessage() = %v, want an *Error", err)
+			}
+			if checkErr.Field != tt.field {
+				t.Errorf("CheckMessage() rejected %q, want %q", checkErr.Field, tt.field)
+			}
+		})
+	}
+}
+
+// operatorPayloads are values every checked string field must reject
+var operatorPayloads = []string{"$ne", `{"$gt": ""}`, `{"$where": "sleep(1000)"}`}
+
+// TestEveryRequestField sets each string reachable from the request of
+// every RPC to each payload in turn, and expects the check to reject it
+// unless the field is exempt
+func TestEveryRequestField(t *testing.T) {
+	checked := 0
+	services := pb.File_proto_user_proto.Services()
+	for i := 0; i < services.Len(); i++ {
+		methods := services.Get(i).Methods()
+		for j := 0; j < methods.Len(); j++ {
+			method := methods.Get(j)
+			msgType, err := protoregistry.GlobalTypes.FindMessageByName(method.Input().FullName())
+			if err != nil {
+				t.Fatalf("request type of %s: %v", method.FullName(), err)
+			}
+
+			for _, path := range stringFields(method.Input(), nil) {
+				leaf := path[len(path)-1]
+				for _, payload := range operatorPayloads {
+					msg := msgType.New()
+					field := setPath(msg, path, payload)
+					name := fmt.Sprintf("%s/%s=%s", method.FullName(), field, payload)
+
+					err := CheckMessage(msg.Interface())
+					if exemptFields[leaf.FullName()] {
+						if err != nil {
+							t.Errorf("%s: CheckMessage() = %v, want nil for an exempt field", name, err)
+						}
+						continue
+					}
+					checked++
+					var checkErr *Error
+					if !errors.As(err, &checkErr) {
+						t.Errorf("%s: CheckMessage() = %v, want an *Error", name, err)
+					} else if checkErr.Field != field {
+						t.Errorf("%s: CheckMessage() rejected %q, want %q", name, checkErr.Field, field)
+					}
+				}
+			}
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no request fields were checked")
+	}
+}
+
+// TestExemptFieldsExist keeps exemptFields from naming fields that were
+// renamed or removed
+func TestExemptFieldsExist(t *testing.T) {
+	for name := range exemptFields {
+		desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+		if err != nil {
+			t.Errorf("exempt field %s: %v", name, err)
+			continue
+		}
+		if fd, ok := desc.(protoreflect.FieldDescriptor); !ok || fd.Kind() != protoreflect.StringKind {
+			t.Errorf("exempt field %s is not a string field", name)
+		}
+	}
+}
+
+// stringFields returns the paths from md to every string field it holds,
+// directly or in nested messages. Messages already on the way there are
+// not entered again, so recursive types end.
+func stringFields(md protoreflect.MessageDescriptor, visiting []protoreflect.FullName) [][]protoreflect.FieldDescriptor {
+	for _, name := range visiting {
+		if name == md.FullName() {
+			return nil
+		}
+	}
+	visiting = append(visiting, md.FullName())
+
+	var paths [][]protoreflect.FieldDescriptor
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		value := fd
+		if fd.IsMap() {
+			value = fd.MapValue()
+		}
+		switch value.Kind() {
+		case protoreflect.StringKind:
+			paths = append(paths, []protoreflect.FieldDescriptor{fd})
+		case protoreflect.MessageKind, protoreflect.GroupKind:
+			for _, path := range stringFields(value.Message(), visiting) {
+				paths = append(paths, append([]protoreflect.FieldDescriptor{fd}, path...))
+			}
+		}
+	}
+	return paths
+}
+
+// setPath sets the string at the end of path in m to value, adding one
+// element to the lists and maps on the way, and returns the field name
+// CheckMessage reports for it
+func setPath(m protoreflect.Message, path []protoreflect.FieldDescriptor, value string) string {
+	const key = "k"
+	fd := path[0]
+	name := string(fd.Name())
+
+	if len(path) == 1 {
+		switch {
+		case fd.IsMap():
+			m.Mutable(fd).Map().Set(protoreflect.ValueOfString(key).MapKey(), protoreflect.ValueOfString(value))
+			return name + "[" + key + "]"
+		case fd.IsList():
+			m.Mutable(fd).List().Append(protoreflect.ValueOfString(value))
+			return name + "[0]"
+		default:
+			m.Set(fd, protoreflect.ValueOfString(value))
+			return name
+		}
+	}
+
+	switch {
+	case fd.IsMap():
+		nested := m.Mutable(fd).Map().Mutable(protoreflect.ValueOfString(key).MapKey()).Message()
+		return name + "[" + key + "]." + setPath(nested, path[1:], value)
+	case fd.IsList():
+		nested := m.Mutable(fd).List().AppendMutable().Message()
+		return name + "[0]." + setPath(nested, path[1:], value)
+	default:
+		return name + "." + setPath(m.Mutable(fd).Message(), path[1:], value)
+	}
+}
+
+// recvStream is a server stream that receives one message
+type recvStream struct {
+	grpc.ServerStream
+	msg proto.Message
+}
+
+func (s *recvStream) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), s.msg)
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	const method = "/user.UserService/ExportUsers"
+	tests := []struct {
+		name    string
+		exempt  []string
+		msg     proto.Message
+		wantErr codes.Code
+	}{
+		{
+			name: "plain request",
+			msg:  &pb.ListUsersRequest{NameFilter: "jane"},
+		},
+		{
+			name:    "operator payload",
+			msg:     &pb.ListUsersRequest{NameFilter: "$ne"},
+			wantErr: codes.InvalidArgument,
+		},
+		{
+			name:   "exempt method",
+			exempt: []string{method},
+			msg:    &pb.ListUsersRequest{NameFilter: "$ne"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := StreamServerInterceptor(tt.exempt...)
+			stream := &recvStream{msg: tt.msg}
+			info := &grpc.StreamServerInfo{FullMethod: method}
+			err := interceptor(nil, stream, info, func(srv interface{}, stream grpc.ServerStream) error {
+				return stream.RecvMsg(&pb.ListUsersRequest{})
+			})
+			if code := status.Code(err); code != tt.wantErr {
+				t.Errorf("interceptor returned %v, want code %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const method = "/envoy.service.auth.v3.Authorization/Check"
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+	req := &pb.LoginRequest{Email: "$ne"}
+
+	interceptor := UnaryServerInterceptor(method)
+	if _, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/user.AuthService/Login"}, handler); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("checked method returned %v, want code %v", err, codes.InvalidArgument)
+	}
+	if _, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: method}, handler); err != nil {
+		t.Errorf("exempt method returned %v, want nil", err)
+	}
+}
@@ -0,0 +1,39 @@
+package scrub
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor redacts the messages of errors returned to
+// clients, and recovers panics in handlers so their dumps are logged
+// redacted instead of crashing the process with raw request data on
+// stderr
+func (s *Scrubber) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				// The log output is already scrubbed
+				log.Printf("Panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+				resp, err = nil, status.Errorf(codes.Internal, "internal error")
+			}
+		}()
+
+		resp, err = handler(ctx, req)
+		if err != nil {
+			st := status.Convert(err)
+			if scrubbed := s.String(st.Message()); scrubbed != st.Message() {
+				// Keep the code and any details
+				p := st.Proto()
+				p.Message = scrubbed
+				err = status.ErrorProto(p)
+			}
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,76 @@
+// Package scrub redacts secrets and personal data from log lines and error
+// messages before they leave the process.
+package scrub
+
+import (
+	"io"
+	"regexp"
+)
+
+// Config selects what is redacted beyond secrets, which are always
+// redacted
+type Config struct {
+	// Emails redacts email addresses
+	Emails bool
+}
+
+type rule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// secretRules always apply. Key-value rules keep the key so log lines stay
+// readable.
+var secretRules = []rule{
+	// JWTs, including in URLs and metadata dumps
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), "[REDACTED_TOKEN]"},
+	{regexp.MustCompile(`(?i)(bearer\s+)\S+`), "${1}[REDACTED]"},
+	// API keys, which start with auth.APIKeyPrefix
+	{regexp.MustCompile(`\bumk_[A-Za-z0-9_-]+`), "[REDACTED_API_KEY]"},
+	// Password hashes in any supported format
+	{regexp.MustCompile(`\$(2[aby]|argon2i|argon2id|pbkdf2-[a-z0-9]+|firebase-scrypt)\$\S+`), "[REDACTED_HASH]"},
+	// password=..., "token": "...", recovery_code: ...
+	{regexp.MustCompile(`(?i)("?\b[a-z_]*(password|secret|token|device_code|recovery_code|signing_key)"?\s*[:=]\s*)("[^"]*"|\S+)`), "${1}[REDACTED]"},
+}
+
+var emailRule = rule{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[REDACTED_EMAIL]"}
+
+// Scrubber redacts text according to its config
+type Scrubber struct {
+	rules []rule
+}
+
+func New(config Config) *Scrubber {
+	rules := append([]rule{}, secretRules...)
+	if config.Emails {
+		rules = append(rules, emailRule)
+	}
+	return &Scrubber{rules: rules}
+}
+
+// String returns s with secrets redacted
+func (s *Scrubber) String(text string) string {
+	for _, r := range s.rules {
+		text = r.pattern.ReplaceAllString(text, r.replacement)
+	}
+	return text
+}
+
+// Writer returns a writer that redacts everything written to w. The log
+// package writes one line per call, so patterns never straddle writes.
+func (s *Scrubber) Writer(w io.Writer) io.Writer {
+	return &writer{scrubber: s, w: w}
+}
+
+type writer struct {
+	scrubber *Scrubber
+	w        io.Writer
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, w.scrubber.String(string(p))); err != nil {
+		return 0, err
+	}
+	// Report the caller's length, the redacted text may be shorter
+	return len(p), nil
+}
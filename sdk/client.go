@@ -0,0 +1,53 @@
+// Package sdk is a Go client for the user management service. Errors the
+// service reports with details come back as typed errors, so callers can
+// use errors.Is and errors.As instead of matching messages:
+//
+//	_, err := client.Auth.Register(ctx, req)
+//	var weak *sdk.ErrWeakPassword
+//	switch {
+//	case errors.Is(err, sdk.ErrEmailTaken):
+//		// offer to sign in instead
+//	case errors.As(err, &weak):
+//		// show weak.Requirements
+//	}
+package sdk
+
+import (
+	"google.golang.org/grpc"
+
+	pb "user-management/proto"
+)
+
+// Client holds a client for each of the service's APIs, sharing one
+// connection
+type Client struct {
+	conn *grpc.ClientConn
+
+	Auth          pb.AuthServiceClient
+	Users         pb.UserServiceClient
+	Admin         pb.AdminServiceClient
+	Organizations pb.OrganizationServiceClient
+}
+
+// New connects to the service at target. opts are passed to
+// grpc.NewClient and must include transport credentials.
+func New(target string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append(opts, grpc.WithChainUnaryInterceptor(UnaryClientInterceptor()))
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		conn:          conn,
+		Auth:          pb.NewAuthServiceClient(conn),
+		Users:         pb.NewUserServiceClient(conn),
+		Admin:         pb.NewAdminServiceClient(conn),
+		Organizations: pb.NewOrganizationServiceClient(conn),
+	}, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
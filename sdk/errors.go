@@ -0,0 +1,160 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"user-management/apierrors"
+)
+
+// ErrEmailTaken is returned when an email address belongs to another
+// account
+var ErrEmailTaken = errors.New("email is already taken")
+
+// ErrTwoFactorEnrollmentRequired is returned for calls made with the
+// restricted token of a user who must set up two-factor authentication
+// first
+var ErrTwoFactorEnrollmentRequired = errors.New("two-factor enrollment required")
+
+// ErrReauthenticationRequired is returned for calls that need a recent
+// login, such as setting a password. The user must sign in again.
+var ErrReauthenticationRequired = errors.New("reauthentication required")
+
+// ErrRefreshTokenReused is returned by RefreshToken for a refresh token that
+// was already exchanged. The session was revoked, so the user must sign in
+// again.
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
+// ErrRateLimited is returned when the server refuses a request for being
+// too frequent. RetryAfter is zero when the server gave no delay.
+type ErrRateLimited struct {
+	RetryAfter time.Duration
+	err        error
+}
+
+func (e *ErrRateLimited) Error() string { return e.err.Error() }
+
+// Unwrap returns the underlying gRPC status error
+func (e *ErrRateLimited) Unwrap() error { return e.err }
+
+// Is makes errors.Is(err, &ErrRateLimited{}) match any rate limit error
+func (e *ErrRateLimited) Is(target error) bool {
+	_, ok := target.(*ErrRateLimited)
+	return ok
+}
+
+// ErrAccountLocked is returned when a login is refused because the account
+// is locked. LockedUntil is when a temporary lock ends, and zero for a lock
+// only an administrator can lift.
+type ErrAccountLocked struct {
+	LockedUntil time.Time
+	err         error
+}
+
+func (e *ErrAccountLocked) Error() string { return e.err.Error() }
+
+// Unwrap returns the underlying gRPC status error
+func (e *ErrAccountLocked) Unwrap() error { return e.err }
+
+// Is makes errors.Is(err, &ErrAccountLocked{}) match any account lock error
+func (e *ErrAccountLocked) Is(target error) bool {
+	_, ok := target.(*ErrAccountLocked)
+	return ok
+}
+
+// ErrWeakPassword is returned when a password fails the strength rules.
+// Requirements lists the rules it fails, such as "at least one number".
+type ErrWeakPassword struct {
+	Requirements []string
+	err          error
+}
+
+func (e *ErrWeakPassword) Error() string { return e.err.Error() }
+
+// Unwrap returns the underlying gRPC status error
+func (e *ErrWeakPassword) Unwrap() error { return e.err }
+
+// Is makes errors.Is(err, &ErrWeakPassword{}) match any weak password error
+func (e *ErrWeakPassword) Is(target error) bool {
+	_, ok := target.(*ErrWeakPassword)
+	return ok
+}
+
+// sentinelError ties a gRPC status error to a sentinel such as
+// ErrEmailTaken, keeping the status reachable through errors.Unwrap
+type sentinelError struct {
+	sentinel error
+	err      error
+}
+
+func (e *sentinelError) Error() string        { return e.err.Error() }
+func (e *sentinelError) Unwrap() error        { return e.err }
+func (e *sentinelError) Is(target error) bool { return target == e.sentinel }
+
+// FromError converts a gRPC error from the service into one of the typed
+// errors above, using the ErrorInfo reason in its details. Other errors are
+// returned unchanged, so status.FromError and status.Code still work on
+// every error.
+func FromError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || err == nil {
+		return err
+	}
+
+	var (
+		reason       string
+		metadata     map[string]string
+		retryAfter   time.Duration
+		requirements []string
+	)
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.Domain == apierrors.Domain {
+				reason = d.Reason
+				metadata = d.Metadata
+			}
+		case *errdetails.RetryInfo:
+			retryAfter = d.RetryDelay.AsDuration()
+		case *errdetails.BadRequest:
+			for _, violation := range d.FieldViolations {
+				requirements = append(requirements, violation.Description)
+			}
+		}
+	}
+
+	switch reason {
+	case apierrors.ReasonEmailTaken:
+		return &sentinelError{sentinel: ErrEmailTaken, err: err}
+	case apierrors.ReasonRateLimited:
+		return &ErrRateLimited{RetryAfter: retryAfter, err: err}
+	case apierrors.ReasonWeakPassword:
+		return &ErrWeakPassword{Requirements: requirements, err: err}
+	case apierrors.ReasonAccountLocked:
+		locked := &ErrAccountLocked{err: err}
+		if until, ok := metadata[apierrors.MetadataLockedUntil]; ok {
+			locked.LockedUntil, _ = time.Parse(time.RFC3339, until)
+		}
+		return locked
+	case apierrors.ReasonTwoFactorEnrollmentRequired:
+		return &sentinelError{sentinel: ErrTwoFactorEnrollmentRequired, err: err}
+	case apierrors.ReasonReauthenticationRequired:
+		return &sentinelError{sentinel: ErrReauthenticationRequired, err: err}
+	case apierrors.ReasonRefreshTokenReused:
+		return &sentinelError{sentinel: ErrRefreshTokenReused, err: err}
+	}
+	return err
+}
+
+// UnaryClientInterceptor converts errors from every call with FromError.
+// Clients built with New already use it.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
@@ -0,0 +1,195 @@
+// Package servertls serves the gRPC listener and REST gateway over TLS,
+// optionally requiring client certificates, with certificates that can be
+// reloaded without a restart.
+package servertls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"user-management/fips"
+)
+
+// Client certificate requirements when a client CA is configured
+const (
+	// ClientAuthRequire rejects clients without a certificate signed by
+	// the client CA
+	ClientAuthRequire = "require"
+	// ClientAuthOptional verifies certificates clients send, but accepts
+	// clients that send none
+	ClientAuthOptional = "optional"
+)
+
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is a PEM bundle of the CAs client certificates are
+	// verified against. Empty disables mutual TLS.
+	ClientCAFile string
+	ClientAuth   string
+	// FIPS allows only approved cipher suites, curves and certificate keys
+	FIPS bool
+}
+
+// Reloader holds the current certificates. Connections made after Reload
+// use the new ones; established connections keep theirs.
+type Reloader struct {
+	config  Config
+	current atomic.Pointer[tls.Config]
+}
+
+// New loads the certificates, failing if any of the files is missing or
+// invalid
+func New(config Config) (*Reloader, error) {
+	if config.ClientAuth == "" {
+		config.ClientAuth = ClientAuthRequire
+	}
+	switch config.ClientAuth {
+	case ClientAuthRequire, ClientAuthOptional:
+	default:
+		return nil, fmt.Errorf("client auth must be %s or %s", ClientAuthRequire, ClientAuthOptional)
+	}
+
+	r := &Reloader{config: config}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files again. On error the previous certificates stay in
+// use.
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %v", err)
+	}
+	if r.config.FIPS {
+		if err := fips.CheckPublicKey(cert.Leaf.PublicKey); err != nil {
+			return fmt.Errorf("TLS certificate: %v", err)
+		}
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		// gRPC clients require HTTP/2 to be negotiated
+		NextProtos: []string{"h2"},
+	}
+	if r.config.FIPS {
+		config.CipherSuites = fips.TLSCipherSuites
+		config.CurvePreferences = fips.TLSCurves
+	}
+
+	if r.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA bundle: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("client CA bundle %s has no PEM certificates", r.config.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		if r.config.ClientAuth == ClientAuthOptional {
+			config.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	r.current.Store(config)
+	return nil
+}
+
+// NotAfter is when the current certificate expires
+func (r *Reloader) NotAfter() time.Time {
+	return r.current.Load().Certificates[0].Leaf.NotAfter
+}
+
+// TLSConfig returns a config for the listener that hands each new
+// connection the current certificates
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current.Load(), nil
+		},
+	}
+}
+
+// Policy narrows the TLS versions and cipher suites of a listener
+type Policy struct {
+	// MinVersion is the oldest TLS version accepted, such as
+	// tls.VersionTLS13. Zero keeps TLS 1.2.
+	MinVersion uint16
+	// CipherSuites are the TLS 1.2 cipher suites accepted. Empty keeps
+	// Go's defaults, or the approved suites in FIPS mode. TLS 1.3 suites
+	// can't be configured.
+	CipherSuites []uint16
+}
+
+// ParseVersion returns the TLS version named "1.2" or "1.3"
+func ParseVersion(name string) (uint16, error) {
+	switch name {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("TLS version must be 1.2 or 1.3")
+	}
+}
+
+// ParseCipherSuites returns the cipher suites with the given IANA names,
+// such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. Only TLS 1.2 suites
+// without known weaknesses are accepted.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	suites := make([]uint16, 0, len(names))
+	for _, name := range names {
+		suite := cipherSuite(name)
+		if suite == nil {
+			return nil, fmt.Errorf("%s is not a secure TLS 1.2 cipher suite", name)
+		}
+		suites = append(suites, suite.ID)
+	}
+	return suites, nil
+}
+
+func cipherSuite(name string) *tls.CipherSuite {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name && slices.Contains(suite.SupportedVersions, tls.VersionTLS12) {
+			return suite
+		}
+	}
+	return nil
+}
+
+// HTTPSConfig returns a config for an HTTPS listener, such as the REST
+// gateway's, that hands each new connection the current certificates
+// with policy applied. HTTP/1.1 is offered as well as HTTP/2.
+func (r *Reloader) HTTPSConfig(policy Policy) *tls.Config {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	policy.Apply(config)
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		current := r.current.Load().Clone()
+		policy.Apply(current)
+		current.NextProtos = []string{"h2", "http/1.1"}
+		return current, nil
+	}
+	return config
+}
+
+// Apply narrows config to the policy
+func (p Policy) Apply(config *tls.Config) {
+	if p.MinVersion != 0 {
+		config.MinVersion = p.MinVersion
+	}
+	if len(p.CipherSuites) > 0 {
+		config.CipherSuites = p.CipherSuites
+	}
+}
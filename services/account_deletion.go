@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/apierrors"
+	"user-management/audit"
+	"user-management/authn"
+	"user-management/database"
+	"user-management/events"
+	"user-management/eventsource"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// accountPurgeBatchSize bounds the accounts purged per run
+const accountPurgeBatchSize = 100
+
+// RestoreProfile brings back an account deleted with DeleteProfile, within
+// the deletion grace period, for whoever can sign in to it with a
+// password. The account's sessions stay revoked, so the owner signs in
+// again afterwards.
+func (s *AuthService) RestoreProfile(ctx context.Context, req *pb.RestoreProfileRequest) (*pb.RestoreProfileResponse, error) {
+	clientIP := requestClientIP(ctx)
+
+	req.Email = utils.SanitizeString(req.Email)
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	if req.Password == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "password is required")
+	}
+
+	// Guesses count towards the same limit as logins
+	allowed, err := s.rateLimiter.CheckRateLimit(ctx, req.Email, clientIP)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check rate limit")
+	}
+	if !allowed {
+		retryAfter, err := s.rateLimiter.RetryAfter(ctx, req.Email, clientIP)
+		if err != nil {
+			log.Printf("Failed to compute login retry delay: %v", err)
+		}
+		return nil, apierrors.RateLimited("too many login attempts, please try again later", retryAfter)
+	}
+
+	notRestorable := status.Errorf(codes.NotFound, "no restorable account with this email")
+	if s.config.DeletionGracePeriod <= 0 {
+		return nil, notRestorable
+	}
+
+	now := time.Now()
+	user, err := s.users.FindDeletedByEmail(ctx, req.Email, now.Add(-s.config.DeletionGracePeriod))
+	if err == database.ErrNotFound {
+		s.rateLimiter.RecordLoginAttempt(ctx, req.Email, clientIP, false)
+		return nil, notRestorable
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	// Any method of the account's login chain that takes a password
+	methods, err := s.loginMethods(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve login methods")
+	}
+	_, err = s.loginChain.Run(ctx, methods, user, authn.Credentials{
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	switch {
+	case errors.Is(err, authn.ErrInvalidCredentials):
+		s.rateLimiter.RecordLoginAttempt(ctx, req.Email, clientIP, false)
+		return nil, status.Errorf(codes.Unauthenticated, "invalid email or password")
+	case errors.Is(err, authn.ErrNoMethod):
+		return nil, status.Errorf(codes.FailedPrecondition, "the account can't be restored with a password")
+	case errors.Is(err, authn.ErrUnavailable):
+		return nil, status.Errorf(codes.Unavailable, "login is unavailable, please try again later")
+	case err != nil:
+		log.Printf("Failed to check credentials of user %s: %v", user.ID.String(), err)
+		return nil, status.Errorf(codes.Internal, "failed to check credentials")
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		// The purge job may have taken the account since it was read
+		err := s.users.Restore(ctx, user.ID, *user.DeletedAt, now)
+		if err == database.ErrNotFound {
+			return errUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypeRestored); err != nil {
+			return err
+		}
+		err = audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionUserRestored,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: clientIP,
+			UserAgent: requestUserAgent(ctx),
+		})
+		if err != nil {
+			return err
+		}
+		return events.Enqueue(ctx, s.db, events.TypeUserRestored, bson.M{
+			"user_id": user.ID.String(),
+		})
+	})
+	if err == errUserNotFound {
+		return nil, notRestorable
+	}
+	if err != nil {
+		log.Printf("Failed to restore user %s: %v", user.ID.String(), err)
+		return nil, status.Errorf(codes.Internal, "failed to restore account")
+	}
+	s.rateLimiter.RecordLoginAttempt(ctx, req.Email, clientIP, true)
+
+	log.Printf("User %s restored their deleted account", user.ID.String())
+
+	return &pb.RestoreProfileResponse{
+		Message: "Account restored, sign in again",
+	}, nil
+}
+
+// PurgeDeletedAccounts permanently deletes up to accountPurgeBatchSize
+// accounts whose deletion grace period has ended, and returns how many
+func (s *AdminService) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	gracePeriod := time.Duration(s.config.Settings.Deletion.GracePeriod)
+	if gracePeriod <= 0 {
+		return 0, nil
+	}
+	deletedBefore := time.Now().Add(-gracePeriod)
+
+	userIDs, err := s.users.ListPurgeable(ctx, deletedBefore, accountPurgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, userID := range userIDs {
+		err := s.db.WithTransaction(ctx, func(ctx context.Context) error {
+			// Accounts restored since they were read are kept
+			err := s.users.Purge(ctx, userID, deletedBefore)
+			if err == database.ErrNotFound {
+				return errUserNotDeleted
+			}
+			if err != nil {
+				return err
+			}
+			if err := deleteUserData(ctx, s.db, userID); err != nil {
+				return err
+			}
+			return audit.Record(ctx, s.db, models.AuditLog{
+				Action:   audit.ActionUserPurged,
+				TargetID: userID,
+			})
+		})
+		if err == errUserNotDeleted {
+			continue
+		}
+		if err != nil {
+			return purged, fmt.Errorf("user %s: %v", userID.String(), err)
+		}
+		purged++
+	}
+	return purged, nil
+}
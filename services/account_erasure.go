@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/audit"
+	"user-management/database"
+	"user-management/events"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+)
+
+// erasureSteps erase an account. It is deleted first, so nobody can sign
+// in to it while the rest is removed.
+var erasureSteps = []workflowStep{
+	{name: "delete_account", run: eraseDeleteAccount},
+	{name: "delete_data", run: eraseUserData},
+	{name: "remove_account", run: eraseRemoveAccount},
+	{name: "notify", run: eraseNotify},
+}
+
+// EraseUser erases an account and everything kept about it, deleted or
+// not, such as on a data protection request. The erasure runs as a
+// workflow, so one that fails partway resumes where it stopped. The user
+// is emailed once it is done.
+func (s *AdminService) EraseUser(ctx context.Context, req *pb.EraseUserRequest) (*pb.EraseUserResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+	if !req.Confirm {
+		return nil, status.Errorf(codes.InvalidArgument, "confirm must be set, the account can't be recovered")
+	}
+	if userID == admin.ID {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot erase your own account")
+	}
+
+	user, err := s.users.FindIncludingDeleted(ctx, userID)
+	if err == database.ErrNotFound {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	wf := &models.Workflow{
+		Kind:        models.WorkflowErasure,
+		UserID:      userID,
+		RequestedBy: admin.ID,
+		// For the email confirming the erasure
+		Data: map[string]string{
+			"email": user.Email,
+			"name":  user.Name,
+		},
+	}
+	err = startWorkflow(ctx, s.db, wf)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, status.Errorf(codes.AlreadyExists, "user is already being erased")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to start erasure")
+	}
+
+	log.Printf("Admin %s started erasure %s of user %s", admin.Email, wf.ID.Hex(), userID.String())
+
+	// The erasure goes on if the client disconnects
+	env := workflowEnv{db: s.db, users: s.users, sender: s.sender}
+	if err := runWorkflow(context.WithoutCancel(ctx), env, wf); err != nil {
+		log.Printf("Failed to run erasure %s: %v", wf.ID.Hex(), err)
+	}
+
+	message := "User erased"
+	if wf.Status != models.WorkflowDone {
+		message = "Erasure started, it resumes in the background"
+	}
+	return &pb.EraseUserResponse{
+		Workflow: workflowToProto(wf),
+		Message:  message,
+	}, nil
+}
+
+// eraseDeleteAccount deletes the account and revokes its sessions, unless
+// it was already deleted
+func eraseDeleteAccount(ctx context.Context, env workflowEnv, wf *models.Workflow) error {
+	err := softDeleteUser(ctx, env.db, env.users, wf.UserID, wf.RequestedBy, time.Now())
+	if err == errUserNotFound {
+		return nil
+	}
+	return err
+}
+
+func eraseUserData(ctx context.Context, env workflowEnv, wf *models.Workflow) error {
+	return deleteUserData(ctx, env.db, wf.UserID)
+}
+
+// eraseRemoveAccount removes the account's document, and tells downstream
+// services to erase their copies
+func eraseRemoveAccount(ctx context.Context, env workflowEnv, wf *models.Workflow) error {
+	err := env.users.Delete(ctx, wf.UserID)
+	if err == database.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = audit.Record(ctx, env.db, models.AuditLog{
+		Action:   audit.ActionUserErased,
+		ActorID:  &wf.RequestedBy,
+		TargetID: wf.UserID,
+		Details:  bson.M{"workflow_id": wf.ID.Hex()},
+	})
+	if err != nil {
+		return err
+	}
+	return events.Enqueue(ctx, env.db, events.TypeUserErased, bson.M{
+		"user_id": wf.UserID.String(),
+	})
+}
+
+// eraseNotify emails the user that the erasure is done
+func eraseNotify(ctx context.Context, env workflowEnv, wf *models.Workflow) error {
+	email := wf.Data["email"]
+	if email == "" {
+		return nil
+	}
+	msg, err := notifications.Render(notifications.TemplateAccountErased, email, map[string]string{
+		"Name":     wf.Data["name"],
+		"ErasedAt": time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	return env.sender.Send(ctx, msg)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/alerts"
+	"user-management/apierrors"
+	"user-management/eventsource"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+func (s *AuthService) StartUnlockChallenge(ctx context.Context, req *pb.StartUnlockChallengeRequest) (*pb.StartUnlockChallengeResponse, error) {
+	req.Email = utils.SanitizeString(req.Email)
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	// The same response is returned whether or not the account exists or is
+	// locked, so the RPC can't be used to probe accounts
+	response := &pb.StartUnlockChallengeResponse{
+		Message: "If the account is locked, an unlock code has been sent to its email address",
+	}
+
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return response, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	lock := user.ActiveLock(time.Now())
+	if lock == nil || lock.Kind != models.LockKindProtective {
+		return response, nil
+	}
+
+	code, err := s.issueEmailChallenge(ctx, user.ID, models.ChallengePurposeUnlock)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create unlock challenge")
+	}
+	if code == "" {
+		return response, nil
+	}
+
+	msg, err := notifications.Render(notifications.TemplateAccountUnlock, user.Email, map[string]string{
+		"Code":        code,
+		"ExpiresIn":   emailChallengeTTL.String(),
+		"LockedUntil": lock.LockedUntil.UTC().Format(time.RFC3339),
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send unlock code to %s: %v", user.Email, err)
+	}
+
+	return response, nil
+}
+
+func (s *AuthService) UnlockWithChallenge(ctx context.Context, req *pb.UnlockWithChallengeRequest) (*pb.UnlockWithChallengeResponse, error) {
+	req.Email = utils.SanitizeString(req.Email)
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	if req.Code == "" || req.Password == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "code and password are required")
+	}
+
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid code or password")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	challenge, err := s.verifyEmailChallenge(ctx, user.ID, models.ChallengePurposeUnlock, req.Code)
+	if err != nil {
+		if err == errChallengeExpired {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid or expired code, request a new one")
+		}
+		if err != errChallengeMismatch {
+			return nil, status.Errorf(codes.Internal, "failed to check unlock challenge")
+		}
+	}
+	if err != nil || !utils.CheckPasswordHash(req.Password, user.Password) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid code or password")
+	}
+
+	now := time.Now()
+
+	// Only protective locks can be lifted by the account owner
+	err = s.users.UnlockProtective(ctx, user.ID, now)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, status.Errorf(codes.Internal, "failed to unlock account")
+	}
+
+	s.deleteEmailChallenge(ctx, challenge)
+
+	if err == mongo.ErrNoDocuments {
+		if user.Lock != nil && user.Lock.Kind == models.LockKindAdmin {
+			return nil, status.Errorf(codes.PermissionDenied, "account is locked by an administrator")
+		}
+		return &pb.UnlockWithChallengeResponse{
+			Message: "Account is not locked",
+		}, nil
+	}
+
+	recordUserEvent(ctx, s.db, user.ID, eventsource.TypeUnlocked)
+
+	log.Printf("User %s lifted a protective lock with an unlock challenge", user.ID.String())
+
+	return &pb.UnlockWithChallengeResponse{
+		Message: "Account unlocked, sign in again",
+	}, nil
+}
+
+// lockError describes an active lock to the client
+func lockError(lock *models.AccountLock) error {
+	if lock.Kind == models.LockKindProtective && lock.LockedUntil != nil {
+		return apierrors.AccountLocked(fmt.Sprintf("account is temporarily locked until %s, request an unlock code to unlock it now",
+			lock.LockedUntil.UTC().Format(time.RFC3339)), lock.LockedUntil)
+	}
+	return apierrors.AccountLocked("account is locked by an administrator", nil)
+}
+
+// recordFailedLogin counts a failed password for the account and places a
+// protective lock once too many fail in a row
+func (s *AuthService) recordFailedLogin(ctx context.Context, user *models.User) {
+	if s.config.LockoutThreshold <= 0 {
+		return
+	}
+
+	failures, err := s.users.RecordFailedLogin(ctx, user.ID)
+	if err != nil {
+		log.Printf("Failed to record failed login for user %s: %v", user.ID.String(), err)
+		return
+	}
+
+	if failures < s.config.LockoutThreshold {
+		return
+	}
+
+	now := time.Now()
+	lockedUntil := now.Add(s.config.LockoutDuration)
+
+	err = s.users.LockProtectively(ctx, user.ID, models.AccountLock{
+		Kind:        models.LockKindProtective,
+		Reason:      "too many failed logins",
+		LockedAt:    now,
+		LockedUntil: &lockedUntil,
+	})
+	// An administrator's lock stays in place, and nothing is recorded or
+	// told about a lock the user doesn't have
+	if err == mongo.ErrNoDocuments {
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to lock user %s: %v", user.ID.String(), err)
+		return
+	}
+	recordUserEvent(ctx, s.db, user.ID, eventsource.TypeLocked)
+	s.config.Alerts.Record(alerts.EventAccountLocked)
+
+	log.Printf("User %s locked until %s after %d failed logins", user.ID.String(), lockedUntil.Format(time.RFC3339), failures)
+
+	msg, err := notifications.Render(notifications.TemplateAccountLocked, user.Email, map[string]string{
+		"LockedUntil": lockedUntil.UTC().Format(time.RFC3339),
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send lockout notice to %s: %v", user.Email, err)
+	}
+}
+
+// clearFailedLogins resets the failure count and any expired lock after a
+// successful login
+func (s *AuthService) clearFailedLogins(ctx context.Context, user *models.User) {
+	if user.FailedLoginCount == 0 && user.Lock == nil {
+		return
+	}
+
+	// An administrator's lock stays in place
+	err := s.users.ClearFailedLogins(ctx, user.ID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Printf("Failed to reset failed logins for user %s: %v", user.ID.String(), err)
+		return
+	}
+	if user.Lock != nil {
+		recordUserEvent(ctx, s.db, user.ID, eventsource.TypeUnlocked)
+	}
+}
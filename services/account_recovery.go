@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/audit"
+	"user-management/eventsource"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// errRecoveryCodeUsed aborts the recovery transaction when the recovery
+// code was consumed by a concurrent request
+var errRecoveryCodeUsed = errors.New("recovery code already used")
+
+func (s *AuthService) StartAccountRecovery(ctx context.Context, req *pb.StartAccountRecoveryRequest) (*pb.StartAccountRecoveryResponse, error) {
+	req.Email = utils.SanitizeString(req.Email)
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	// The same response is returned whether or not the account exists, so
+	// the RPC can't be used to probe accounts
+	response := &pb.StartAccountRecoveryResponse{
+		Message: "If the account exists, a code has been sent to its email address",
+	}
+
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return response, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	code, err := s.issueEmailChallenge(ctx, user.ID, models.ChallengePurposeRecovery)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create recovery challenge")
+	}
+	if code == "" {
+		return response, nil
+	}
+
+	msg, err := notifications.Render(notifications.TemplateAccountRecovery, user.Email, map[string]string{
+		"Code":      code,
+		"ExpiresIn": emailChallengeTTL.String(),
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send recovery code to %s: %v", user.Email, err)
+	}
+
+	return response, nil
+}
+
+// SecureAccount recovers an account that may have been taken over. Once the
+// caller proves control of the email address and holds a recovery code, it
+// rotates the password, revokes every token, resets 2FA and lifts
+// protective locks in one transaction with an audit entry per step. An
+// account_recovery workflow started in the same transaction then removes
+// trusted devices the caller doesn't keep and outstanding approvals, and
+// emails the user, resuming in the background if a step fails.
+func (s *AuthService) SecureAccount(ctx context.Context, req *pb.SecureAccountRequest) (*pb.SecureAccountResponse, error) {
+	req.Email = utils.SanitizeString(req.Email)
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	if req.EmailCode == "" || req.RecoveryCode == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "email code and recovery code are required")
+	}
+
+	if err := s.config.PasswordPolicy.Validate(req.NewPassword); err != nil {
+		return nil, passwordError(err)
+	}
+
+	keepDeviceIDs := make([]primitive.ObjectID, 0, len(req.KeepDeviceIds))
+	for _, id := range req.KeepDeviceIds {
+		deviceObjectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid device ID format: %s", id)
+		}
+		keepDeviceIDs = append(keepDeviceIDs, deviceObjectID)
+	}
+
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid email code or recovery code")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	// Verify both factors
+	challenge, err := s.verifyEmailChallenge(ctx, user.ID, models.ChallengePurposeRecovery, req.EmailCode)
+	if err != nil {
+		if err == errChallengeExpired {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid or expired email code, request a new one")
+		}
+		if err != errChallengeMismatch {
+			return nil, status.Errorf(codes.Internal, "failed to check recovery challenge")
+		}
+	}
+
+	recoveryCodeHash := utils.HashToken(utils.NormalizeRecoveryCode(req.RecoveryCode))
+	if err != nil || !containsString(user.RecoveryCodeHashes, recoveryCodeHash) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid email code or recovery code")
+	}
+
+	if lock := user.ActiveLock(time.Now()); lock != nil && lock.Kind == models.LockKindAdmin {
+		return nil, status.Errorf(codes.PermissionDenied, "account is locked by an administrator")
+	}
+
+	if utils.CheckPasswordHash(req.NewPassword, user.Password) {
+		return nil, status.Errorf(codes.InvalidArgument, "new password must differ from the current password")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password")
+	}
+
+	clientIP := requestClientIP(ctx)
+	userAgent := requestUserAgent(ctx)
+	recoveryCodesRemaining := len(user.RecoveryCodeHashes) - 1
+
+	keep := make([]string, len(keepDeviceIDs))
+	for i, id := range keepDeviceIDs {
+		keep[i] = id.Hex()
+	}
+	twoFactor := "not enabled"
+	if user.TwoFactor != nil {
+		twoFactor = "reset, set it up again"
+	}
+	wf := &models.Workflow{
+		Kind:   models.WorkflowAccountRecovery,
+		UserID: user.ID,
+		Data: map[string]string{
+			"recovery_id":         challenge.ID.Hex(),
+			"keep_device_ids":     strings.Join(keep, ","),
+			"email":               user.Email,
+			"ip_address":          clientIP,
+			"user_agent":          userAgent,
+			"two_factor":          twoFactor,
+			"recovery_codes_left": strconv.Itoa(recoveryCodesRemaining),
+		},
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		record := func(action string, details bson.M) error {
+			details["recovery_id"] = challenge.ID.Hex()
+			return audit.Record(ctx, s.db, models.AuditLog{
+				Action:    action,
+				ActorID:   &user.ID,
+				TargetID:  user.ID,
+				IPAddress: clientIP,
+				UserAgent: userAgent,
+				Details:   details,
+				CreatedAt: now,
+			})
+		}
+
+		// Consuming the recovery code in the same update makes a second
+		// request with the same code fail
+		err := s.users.RecoverAccount(ctx, user.ID, recoveryCodeHash, hashedPassword, now)
+		if err == mongo.ErrNoDocuments {
+			return errRecoveryCodeUsed
+		}
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypeRecovered); err != nil {
+			return err
+		}
+
+		if err := record(audit.ActionRecoveryVerified, bson.M{
+			"factors": []string{"email_code", "recovery_code"},
+		}); err != nil {
+			return err
+		}
+		if err := record(audit.ActionRecoveryCodeUsed, bson.M{
+			"remaining": recoveryCodesRemaining,
+		}); err != nil {
+			return err
+		}
+		if err := record(audit.ActionPasswordRotated, bson.M{}); err != nil {
+			return err
+		}
+		if err := record(audit.ActionSessionsRevoked, bson.M{
+			"tokens_valid_after": now,
+		}); err != nil {
+			return err
+		}
+		if user.TwoFactor != nil {
+			if err := record(audit.ActionTwoFactorReset, bson.M{
+				"method": user.TwoFactor.Method,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return startWorkflow(ctx, s.db, wf)
+	})
+
+	if err != nil {
+		if err == errRecoveryCodeUsed {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid email code or recovery code")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to secure account")
+	}
+
+	log.Printf("User %s secured their account from %s", user.ID.String(), clientIP)
+
+	// The cleanup goes on if the client disconnects. When removing the
+	// devices fails, they are removed once it resumes and the count is 0.
+	env := workflowEnv{db: s.db, users: s.users, sender: s.sender}
+	if err := runWorkflow(context.WithoutCancel(ctx), env, wf); err != nil {
+		log.Printf("Failed to run account recovery %s: %v", wf.ID.Hex(), err)
+	}
+	devicesRemoved, _ := strconv.Atoi(wf.Data["devices_removed"])
+
+	return &pb.SecureAccountResponse{
+		Message:                "Account secured, sign in with your new password",
+		DevicesRemoved:         int32(devicesRemoved),
+		TwoFactorReset:         user.TwoFactor != nil,
+		RecoveryCodesRemaining: int32(recoveryCodesRemaining),
+	}, nil
+}
+
+// accountRecoverySteps clean up after SecureAccount
+var accountRecoverySteps = []workflowStep{
+	{name: "remove_devices", run: recoveryRemoveDevices},
+	{name: "remove_approvals", run: recoveryRemoveApprovals},
+	{name: "delete_challenge", run: recoveryDeleteChallenge},
+	{name: "notify", run: recoveryNotify},
+}
+
+// recoveryRemoveDevices removes every trusted device the caller didn't
+// keep
+func recoveryRemoveDevices(ctx context.Context, env workflowEnv, wf *models.Workflow) error {
+	keep := []primitive.ObjectID{}
+	for _, id := range strings.Split(wf.Data["keep_device_ids"], ",") {
+		if deviceID, err := primitive.ObjectIDFromHex(id); err == nil {
+			keep = append(keep, deviceID)
+		}
+	}
+
+	filter := bson.M{"user_id": wf.UserID}
+	if len(keep) > 0 {
+		filter["_id"] = bson.M{"$nin": keep}
+	}
+	deleted, err := env.db.Devices.DeleteMany(ctx, filter)
+	if err != nil {
+		return err
+	}
+	// A run after the devices were removed keeps the first count
+	if _, ok := wf.Data["devices_removed"]; ok && deleted.DeletedCount == 0 {
+		return nil
+	}
+	wf.Data["devices_removed"] = strconv.FormatInt(deleted.DeletedCount, 10)
+
+	return audit.Record(ctx, env.db, models.AuditLog{
+		Action:    audit.ActionDevicesRemoved,
+		ActorID:   &wf.UserID,
+		TargetID:  wf.UserID,
+		IPAddress: wf.Data["ip_address"],
+		UserAgent: wf.Data["user_agent"],
+		Details: bson.M{
+			"recovery_id": wf.Data["recovery_id"],
+			"removed":     deleted.DeletedCount,
+			"kept":        keep,
+		},
+	})
+}
+
+// recoveryRemoveApprovals removes outstanding approvals and API keys, which
+// could let an attacker straight back in
+func recoveryRemoveApprovals(ctx context.Context, env workflowEnv, wf *models.Workflow) error {
+	if _, err := env.db.Pending.DeleteMany(ctx, bson.M{"user_id": wf.UserID}); err != nil {
+		return err
+	}
+	if _, err := env.db.DeviceLogins.DeleteMany(ctx, bson.M{"user_id": wf.UserID}); err != nil {
+		return err
+	}
+	_, err := env.db.APIKeys.DeleteMany(ctx, bson.M{"user_id": wf.UserID})
+	return err
+}
+
+func recoveryDeleteChallenge(ctx context.Context, env workflowEnv, wf *models.Workflow) error {
+	challengeID, err := primitive.ObjectIDFromHex(wf.Data["recovery_id"])
+	if err != nil {
+		return nil
+	}
+	_, err = env.db.Challenges.DeleteOne(ctx, bson.M{"_id": challengeID})
+	return err
+}
+
+// recoveryNotify emails the user what SecureAccount changed
+func recoveryNotify(ctx context.Context, env workflowEnv, wf *models.Workflow) error {
+	devicesRemoved := wf.Data["devices_removed"]
+	if devicesRemoved == "" {
+		devicesRemoved = "0"
+	}
+	msg, err := notifications.Render(notifications.TemplateAccountSecured, wf.Data["email"], map[string]string{
+		"IPAddress":         wf.Data["ip_address"],
+		"DevicesRemoved":    devicesRemoved,
+		"TwoFactor":         wf.Data["two_factor"],
+		"RecoveryCodesLeft": wf.Data["recovery_codes_left"],
+	})
+	if err != nil {
+		return err
+	}
+	return env.sender.Send(ctx, msg)
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
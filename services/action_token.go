@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/auth"
+	"user-management/database"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+func (s *AuthService) CreateActionToken(ctx context.Context, req *pb.CreateActionTokenRequest) (*pb.CreateActionTokenResponse, error) {
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !auth.IsActionPurpose(req.Purpose) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown token purpose")
+	}
+
+	if req.TtlSeconds < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "ttl must not be negative")
+	}
+
+	token, expiresAt, err := s.authorizer.GenerateActionToken(
+		user.ID.String(),
+		user.Email,
+		req.Purpose,
+		utils.SanitizeString(req.Resource),
+		time.Duration(req.TtlSeconds)*time.Second,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate token")
+	}
+
+	return &pb.CreateActionTokenResponse{
+		Token:     token,
+		ExpiresAt: timestamppb.New(expiresAt),
+	}, nil
+}
+
+// ValidateToken lets other services and the gateway check a token without
+// holding the signing secret. Invalid tokens produce valid=false rather than
+// an error.
+func (s *AuthService) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	var claims *auth.JWTClaims
+	var err error
+	if req.Purpose == "" {
+		claims, err = s.authorizer.ValidateToken(req.Token)
+	} else {
+		claims, err = s.authorizer.ValidateActionToken(req.Token, req.Purpose)
+	}
+	if err != nil {
+		if isTokenRejection(err) {
+			return &pb.ValidateTokenResponse{Valid: false}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to validate token")
+	}
+
+	if req.Resource != "" && req.Resource != claims.Resource {
+		return &pb.ValidateTokenResponse{Valid: false}, nil
+	}
+
+	response := &pb.ValidateTokenResponse{
+		Valid:     true,
+		UserId:    claims.UserID,
+		Email:     claims.Email,
+		Purpose:   claims.Purpose,
+		Resource:  claims.Resource,
+		ExpiresAt: timestamppb.New(claims.ExpiresAt.Time),
+	}
+
+	// A token can outlive the account's access, as an action token does
+	// when the account is deactivated after it was issued
+	userID, err := models.ParseID(claims.UserID)
+	if err != nil {
+		return &pb.ValidateTokenResponse{Valid: false}, nil
+	}
+	user, err := s.users.FindByID(ctx, userID)
+	switch {
+	case err == nil:
+		response.Active = user.IsActive
+	case errors.Is(err, database.ErrNotFound):
+		// The account was deleted after the token was issued
+		return &pb.ValidateTokenResponse{Valid: false}, nil
+	default:
+		return nil, status.Errorf(codes.Internal, "failed to load user")
+	}
+
+	// Session tokens also carry the caller's organization and roles, so
+	// downstream services can authorize without another lookup
+	if req.Purpose == "" {
+		principal, err := s.authorizer.PrincipalFor(ctx, claims)
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return &pb.ValidateTokenResponse{Valid: false}, nil
+		} else if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to load user roles")
+		}
+		response.OrgId = principal.OrgID
+		response.Roles = principal.Roles
+	}
+
+	return response, nil
+}
+
+// isTokenRejection separates tokens that are simply not valid from failures
+// while checking them
+func isTokenRejection(err error) bool {
+	return errors.Is(err, auth.ErrInvalidToken) ||
+		errors.Is(err, auth.ErrTokenExpired) ||
+		errors.Is(err, auth.ErrSessionIdle) ||
+		errors.Is(err, auth.ErrTokenBlacklisted) ||
+		errors.Is(err, auth.ErrTokenRevoked) ||
+		errors.Is(err, auth.ErrUnsupportedTokenVersion) ||
+		errors.Is(err, auth.ErrPurposeMismatch) ||
+		errors.Is(err, auth.ErrTokenScopeRestricted)
+}
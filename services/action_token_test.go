@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"user-management/auth"
+	"user-management/authz"
+	"user-management/database"
+	"user-management/identity"
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+// newTestJWTService returns a JWTService that keeps its blacklist and
+// revocations in repos
+func newTestJWTService(t *testing.T, repos database.Repositories) *auth.JWTService {
+	t.Helper()
+	jwtService, err := auth.NewJWTService("test-secret", nil, time.Hour, 0, auth.TokenVersions{
+		Issue:       auth.CurrentTokenVersion,
+		MinAccepted: auth.TokenVersion1,
+	})
+	if err != nil {
+		t.Fatalf("NewJWTService() = %v", err)
+	}
+	jwtService.SetRepositories(repos)
+	return jwtService
+}
+
+func TestValidateTokenAccountState(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *models.User // stored before validating, nil for none
+		wantValid  bool
+		wantActive bool
+	}{
+		{
+			name:       "active account",
+			user:       &models.User{IsActive: true},
+			wantValid:  true,
+			wantActive: true,
+		},
+		{
+			name:      "deactivated account",
+			user:      &models.User{IsActive: false},
+			wantValid: true,
+		},
+		{
+			name: "deleted account",
+			user: &models.User{IsActive: true, IsDeleted: true},
+		},
+		{
+			name: "account that never existed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := database.NewMemoryRepositories()
+			jwtService := newTestJWTService(t, repos)
+			s := &AuthService{
+				users:      repos.Users,
+				authorizer: authz.New(jwtService, identity.NewResolver(jwtService, repos.Users)),
+			}
+
+			userID := models.ID(primitive.NewObjectID().Hex())
+			if tt.user != nil {
+				user := *tt.user
+				user.ID = userID
+				user.Email = "jane@example.com"
+				if err := repos.Users.Insert(ctx, user); err != nil {
+					t.Fatalf("Insert() = %v", err)
+				}
+			}
+			token, _, err := jwtService.GenerateActionToken(userID.String(), "jane@example.com", auth.PurposeDownloadExport, "", time.Minute)
+			if err != nil {
+				t.Fatalf("GenerateActionToken() = %v", err)
+			}
+
+			resp, err := s.ValidateToken(ctx, &pb.ValidateTokenRequest{Token: token, Purpose: auth.PurposeDownloadExport})
+			if err != nil {
+				t.Fatalf("ValidateToken() = %v", err)
+			}
+			if resp.Valid != tt.wantValid || resp.Active != tt.wantActive {
+				t.Errorf("ValidateToken() = valid %v, active %v, want valid %v, active %v", resp.Valid, resp.Active, tt.wantValid, tt.wantActive)
+			}
+		})
+	}
+}
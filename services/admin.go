@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/authz"
+	"user-management/config"
+	"user-management/database"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+	"user-management/session"
+	"user-management/spiffeauth"
+	"user-management/tenantkeys"
+	"user-management/utils"
+)
+
+// AdminConfig holds what AdminService needs to export and import settings
+type AdminConfig struct {
+	// Environment names this deployment in exported snapshots
+	Environment string
+	// Settings are the effective runtime settings of this server
+	Settings config.Settings
+	// ConfigSigningKey signs exported snapshots and verifies imported ones.
+	// It must match across environments that config is promoted between.
+	ConfigSigningKey []byte
+	// UserIDs creates the IDs of imported accounts. Nil selects ObjectIDs.
+	UserIDs models.IDGenerator
+	// TenantKeys holds organizations' encryption keys. Nil when members'
+	// data isn't encrypted.
+	TenantKeys *tenantkeys.Keyring
+}
+
+type AdminService struct {
+	pb.UnimplementedAdminServiceServer
+	db         *database.Database
+	users      database.UserRepository
+	sessions   session.Manager
+	authorizer authz.Authorizer
+	sender     notifications.Sender
+	config     AdminConfig
+}
+
+func NewAdminService(db *database.Database, sessions session.Manager, authorizer authz.Authorizer, sender notifications.Sender, config AdminConfig) *AdminService {
+	if config.UserIDs == nil {
+		config.UserIDs = models.ObjectIDGenerator{}
+	}
+	return &AdminService{
+		db:         db,
+		users:      db.Repositories().Users,
+		sessions:   sessions,
+		authorizer: authorizer,
+		sender:     sender,
+		config:     config,
+	}
+}
+
+func (s *AdminService) ListNotificationTemplates(ctx context.Context, req *pb.ListNotificationTemplatesRequest) (*pb.ListNotificationTemplatesResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var pbTemplates []*pb.NotificationTemplate
+	for _, t := range notifications.Templates() {
+		pbTemplates = append(pbTemplates, &pb.NotificationTemplate{
+			Name:       t.Name,
+			Subject:    t.Subject,
+			Body:       t.Body,
+			SampleData: t.SampleData,
+		})
+	}
+
+	return &pb.ListNotificationTemplatesResponse{
+		Templates: pbTemplates,
+	}, nil
+}
+
+func (s *AdminService) PreviewNotificationTemplate(ctx context.Context, req *pb.PreviewNotificationTemplateRequest) (*pb.PreviewNotificationTemplateResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	msg, err := notifications.RenderSample(req.Name, "", req.Data)
+	if err != nil {
+		return nil, templateError(err)
+	}
+
+	return &pb.PreviewNotificationTemplateResponse{
+		Subject: msg.Subject,
+		Body:    msg.Body,
+	}, nil
+}
+
+func (s *AdminService) SendTestNotification(ctx context.Context, req *pb.SendTestNotificationRequest) (*pb.SendTestNotificationResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req.To = utils.SanitizeString(req.To)
+	if err := utils.ValidateEmail(req.To); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	msg, err := notifications.RenderSample(req.Name, req.To, req.Data)
+	if err != nil {
+		return nil, templateError(err)
+	}
+	msg.Subject = "[TEST] " + msg.Subject
+
+	// Surface delivery errors so operators can debug the sender configuration
+	if err := s.sender.Send(ctx, msg); err != nil {
+		log.Printf("Test notification %s to %s by %s failed: %v", req.Name, req.To, admin.Email, err)
+		return nil, status.Errorf(codes.Unavailable, "failed to send notification: %v", err)
+	}
+
+	log.Printf("Test notification %s sent to %s by %s", req.Name, req.To, admin.Email)
+
+	return &pb.SendTestNotificationResponse{
+		Message: "Test notification sent",
+	}, nil
+}
+
+// requireAdmin authenticates the caller and checks that the account holds
+// the admin role. Roles are read from the database so revoking the role
+// takes effect immediately.
+//
+// Services calling the internal port are admins within their scopes,
+// which the SPIFFE interceptor already checked. They stand in as a user
+// whose ID and email are their SPIFFE ID, so audit entries and logs name
+// them.
+func (s *AdminService) requireAdmin(ctx context.Context) (*models.User, error) {
+	if account, ok := spiffeauth.FromContext(ctx); ok {
+		return &models.User{
+			ID:    models.ID(account.SPIFFEID),
+			Email: account.SPIFFEID,
+			Roles: []string{models.RoleAdmin},
+		}, nil
+	}
+
+	userID, err := authz.CallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.PermissionDenied, "admin role required")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to retrieve user")
+	}
+	if !user.IsActive {
+		return nil, status.Errorf(codes.PermissionDenied, "admin role required")
+	}
+
+	if !user.HasRole(models.RoleAdmin) {
+		return nil, status.Errorf(codes.PermissionDenied, "admin role required")
+	}
+
+	return user, nil
+}
+
+func templateError(err error) error {
+	if errors.Is(err, notifications.ErrUnknownTemplate) {
+		return status.Errorf(codes.NotFound, "%s", err.Error())
+	}
+	return status.Errorf(codes.InvalidArgument, "%s", err.Error())
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/audit"
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+const (
+	defaultReplayBatchSize = 500
+	maxReplayBatchSize     = 5000
+)
+
+// ReplayAuditLog rebuilds audit projections one batch per call. The first
+// call resets the projections; later calls continue from the IDs the
+// previous one returned.
+func (s *AdminService) ReplayAuditLog(ctx context.Context, req *pb.ReplayAuditLogRequest) (*pb.ReplayAuditLogResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	projections, err := audit.LookupProjections(req.Projections)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	names := make([]string, len(projections))
+	for i, p := range projections {
+		names[i] = p.Name()
+	}
+
+	batchSize := int(req.BatchSize)
+	if batchSize <= 0 {
+		batchSize = defaultReplayBatchSize
+	}
+	if batchSize > maxReplayBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch size must be at most %d", maxReplayBatchSize)
+	}
+
+	var afterID, untilID primitive.ObjectID
+	if req.AfterId == "" {
+		// Start a new replay
+		untilID, err = audit.StartReplay(ctx, s.db, projections)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to start replay: %v", err)
+		}
+		log.Printf("Admin %s started an audit log replay of %s", admin.Email, strings.Join(names, ", "))
+	} else {
+		afterID, err = primitive.ObjectIDFromHex(req.AfterId)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid after ID format")
+		}
+		untilID, err = primitive.ObjectIDFromHex(req.UntilId)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "until ID is required to continue a replay")
+		}
+	}
+
+	progress, err := audit.ReplayBatch(ctx, s.db, projections, afterID, untilID, batchSize)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "replayed %d entries before failing: %v", progress.Processed, err)
+	}
+
+	resp := &pb.ReplayAuditLogResponse{
+		Processed:   int32(progress.Processed),
+		Remaining:   progress.Remaining,
+		Done:        progress.Done,
+		Projections: names,
+	}
+	if !progress.LastID.IsZero() {
+		resp.AfterId = progress.LastID.Hex()
+	}
+	if !progress.UntilID.IsZero() {
+		resp.UntilId = progress.UntilID.Hex()
+	}
+	if progress.Done {
+		log.Printf("Admin %s finished an audit log replay of %s", admin.Email, strings.Join(names, ", "))
+	}
+	return resp, nil
+}
+
+// GetAuditLogs lists audit entries, newest first
+func (s *AdminService) GetAuditLogs(ctx context.Context, req *pb.GetAuditLogsRequest) (*pb.GetAuditLogsResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{}
+	if req.TargetId != "" {
+		targetID, err := models.ParseID(req.TargetId)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid target ID format")
+		}
+		filter["target_id"] = targetID
+	}
+	if req.ActorId != "" {
+		actorID, err := models.ParseID(req.ActorId)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid actor ID format")
+		}
+		filter["actor_id"] = actorID
+	}
+	if req.Action != "" {
+		filter["action"] = req.Action
+	}
+	if req.Since != nil || req.Until != nil {
+		createdAt := bson.M{}
+		if req.Since != nil {
+			createdAt["$gte"] = req.Since.AsTime()
+		}
+		if req.Until != nil {
+			createdAt["$lt"] = req.Until.AsTime()
+		}
+		filter["created_at"] = createdAt
+	}
+
+	// Set default pagination values
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	totalCount, err := s.db.AuditLogs.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count audit entries")
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSkip(int64((page - 1) * pageSize))
+	findOptions.SetLimit(int64(pageSize))
+	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := s.db.AuditLogs.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find audit entries")
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.AuditLog
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode audit entries")
+	}
+
+	pbEntries := make([]*pb.AuditLogEntry, 0, len(entries))
+	for _, entry := range entries {
+		pbEntry := &pb.AuditLogEntry{
+			Id:        entry.ID.Hex(),
+			Action:    entry.Action,
+			TargetId:  entry.TargetID.String(),
+			IpAddress: entry.IPAddress,
+			UserAgent: entry.UserAgent,
+			CreatedAt: timestamppb.New(entry.CreatedAt),
+		}
+		if entry.ActorID != nil {
+			pbEntry.ActorId = entry.ActorID.String()
+		}
+		if len(entry.Details) > 0 {
+			pbEntry.Details = make(map[string]string, len(entry.Details))
+			for key, value := range entry.Details {
+				pbEntry.Details[key] = auditValue(value)
+			}
+		}
+		for _, change := range entry.Changes {
+			pbEntry.Changes = append(pbEntry.Changes, &pb.AuditChange{
+				Field:  change.Field,
+				Before: auditValue(change.Before),
+				After:  auditValue(change.After),
+			})
+		}
+		pbEntries = append(pbEntries, pbEntry)
+	}
+
+	return &pb.GetAuditLogsResponse{
+		Entries:    pbEntries,
+		TotalCount: int32(totalCount),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+// auditValue formats a value of an audit entry, JSON encoding any that is
+// not a string
+func auditValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	}
+	encoded, err := bson.MarshalExtJSON(bson.M{"v": value}, false, false)
+	if err != nil {
+		return ""
+	}
+	var decoded struct {
+		V json.RawMessage `json:"v"`
+	}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return ""
+	}
+	return string(decoded.V)
+}
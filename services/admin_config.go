@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/config"
+	pb "user-management/proto"
+)
+
+func (s *AdminService) ExportConfig(ctx context.Context, req *pb.ExportConfigRequest) (*pb.ExportConfigResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := config.NewSnapshot(s.config.Settings, s.config.Environment, admin.Email, s.config.ConfigSigningKey)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create config snapshot")
+	}
+
+	data, err := snapshot.Marshal()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode config snapshot")
+	}
+
+	log.Printf("Config exported by %s", admin.Email)
+
+	return &pb.ExportConfigResponse{
+		Snapshot: string(data),
+	}, nil
+}
+
+func (s *AdminService) ImportConfig(ctx context.Context, req *pb.ImportConfigRequest) (*pb.ImportConfigResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Snapshot == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "snapshot is required")
+	}
+
+	snapshot, err := config.ParseSnapshot([]byte(req.Snapshot), s.config.ConfigSigningKey)
+	if err != nil {
+		if errors.Is(err, config.ErrInvalidSignature) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err.Error())
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	changed, err := config.Diff(s.config.Settings, snapshot.Settings)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to compare settings")
+	}
+
+	if req.DryRun {
+		return &pb.ImportConfigResponse{
+			Message:           "Snapshot is valid, nothing was saved",
+			SourceEnvironment: snapshot.Environment,
+			ChangedSettings:   changed,
+			RestartRequired:   len(changed) > 0,
+		}, nil
+	}
+
+	if err := config.SaveImported(ctx, s.db, snapshot, admin.Email); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save settings")
+	}
+
+	log.Printf("Config from %s imported by %s, %d settings changed", snapshot.Environment, admin.Email, len(changed))
+
+	return &pb.ImportConfigResponse{
+		Message:           "Settings saved, restart the server to apply them",
+		SourceEnvironment: snapshot.Environment,
+		ChangedSettings:   changed,
+		RestartRequired:   len(changed) > 0,
+	}, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/events"
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+func (s *AdminService) ListDeadLetterEvents(ctx context.Context, req *pb.ListDeadLetterEventsRequest) (*pb.ListDeadLetterEventsResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	// Set default pagination values
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	filter := bson.M{}
+	if req.Type != "" {
+		filter["type"] = req.Type
+	}
+
+	totalCount, err := s.db.DeadLetters.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count dead letter events")
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSkip(int64((page - 1) * pageSize))
+	findOptions.SetLimit(int64(pageSize))
+	findOptions.SetSort(bson.D{{Key: "dead_lettered_at", Value: -1}})
+
+	cursor, err := s.db.DeadLetters.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find dead letter events")
+	}
+	defer cursor.Close(ctx)
+
+	var deadLetters []models.DeadLetterEvent
+	if err = cursor.All(ctx, &deadLetters); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode dead letter events")
+	}
+
+	var pbEvents []*pb.DeadLetterEvent
+	for _, deadLetter := range deadLetters {
+		payload, err := bson.MarshalExtJSON(deadLetter.Payload, false, false)
+		if err != nil {
+			payload = []byte(fmt.Sprintf("%v", deadLetter.Payload))
+		}
+
+		pbEvents = append(pbEvents, &pb.DeadLetterEvent{
+			Id:             deadLetter.ID.Hex(),
+			Type:           deadLetter.Type,
+			Payload:        string(payload),
+			Attempts:       int32(deadLetter.Attempts),
+			LastError:      deadLetter.LastError,
+			CreatedAt:      timestamppb.New(deadLetter.CreatedAt),
+			DeadLetteredAt: timestamppb.New(deadLetter.DeadLetteredAt),
+		})
+	}
+
+	return &pb.ListDeadLetterEventsResponse{
+		Events:     pbEvents,
+		TotalCount: int32(totalCount),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *AdminService) ReplayDeadLetterEvents(ctx context.Context, req *pb.ReplayDeadLetterEventsRequest) (*pb.ReplayDeadLetterEventsResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{}
+	if !req.All {
+		if len(req.Ids) == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "event IDs are required unless replaying all")
+		}
+
+		objectIDs := make([]primitive.ObjectID, 0, len(req.Ids))
+		for _, id := range req.Ids {
+			objectID, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid event ID format: %s", id)
+			}
+			objectIDs = append(objectIDs, objectID)
+		}
+		filter["_id"] = bson.M{"$in": objectIDs}
+	}
+
+	replayed, err := events.ReplayDeadLetters(ctx, s.db, filter)
+	log.Printf("Admin %s replayed %d dead letter events", admin.Email, replayed)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "replayed %d events before failing: %v", replayed, err)
+	}
+
+	return &pb.ReplayDeadLetterEventsResponse{
+		ReplayedCount: int32(replayed),
+		Message:       "Events queued for delivery",
+	}, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/eventsource"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+func (s *AdminService) SetExternalId(ctx context.Context, req *pb.SetExternalIdRequest) (*pb.SetExternalIdResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	system := strings.ToLower(utils.SanitizeString(req.System))
+	if err := utils.ValidateExternalSystem(system); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	externalID := utils.SanitizeString(req.ExternalId)
+	message := "External ID removed"
+	if externalID != "" {
+		if err := utils.ValidateExternalID(externalID); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+		}
+		message = "External ID set"
+	}
+
+	user, err := s.users.SetExternalID(ctx, userID, system, externalID, time.Now())
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, status.Errorf(codes.AlreadyExists, "external ID is already linked to another user")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to set external ID")
+	}
+	recordUserEvent(ctx, s.db, userID, eventsource.TypeExternalIDChanged)
+
+	log.Printf("Admin %s updated the %s external ID of user %s", admin.Email, system, req.UserId)
+
+	pbUser := &pb.User{
+		Id:          user.ID.String(),
+		Email:       user.Email,
+		Name:        user.Name,
+		CreatedAt:   timestamppb.New(user.CreatedAt),
+		UpdatedAt:   timestamppb.New(user.UpdatedAt),
+		IsActive:    user.IsActive,
+		IsDeleted:   user.IsDeleted,
+		ExternalIds: user.ExternalIDMap(),
+	}
+
+	return &pb.SetExternalIdResponse{
+		User:    pbUser,
+		Message: message,
+	}, nil
+}
+
+func (s *AdminService) GetUserByExternalId(ctx context.Context, req *pb.GetUserByExternalIdRequest) (*pb.GetUserByExternalIdResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	system := strings.ToLower(utils.SanitizeString(req.System))
+	if err := utils.ValidateExternalSystem(system); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	externalID := utils.SanitizeString(req.ExternalId)
+	if err := utils.ValidateExternalID(externalID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	user, err := s.users.FindByExternalID(ctx, system, externalID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	pbUser := &pb.User{
+		Id:          user.ID.String(),
+		Email:       user.Email,
+		Name:        user.Name,
+		CreatedAt:   timestamppb.New(user.CreatedAt),
+		UpdatedAt:   timestamppb.New(user.UpdatedAt),
+		IsActive:    user.IsActive,
+		IsDeleted:   user.IsDeleted,
+		ExternalIds: user.ExternalIDMap(),
+	}
+
+	return &pb.GetUserByExternalIdResponse{
+		User: pbUser,
+	}, nil
+}
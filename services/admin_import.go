@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"user-management/events"
+	"user-management/eventsource"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// maxImportUsers bounds the users in one ImportUsers request. Larger
+// imports are split across requests.
+const maxImportUsers = 1000
+
+// importProgressEvery is how many users an async import handles between
+// progress updates
+const importProgressEvery = 50
+
+func (s *AdminService) ImportUsers(ctx context.Context, req *pb.ImportUsersRequest) (*pb.ImportUsersResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Users) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "users are required")
+	}
+	if len(req.Users) > maxImportUsers {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d users can be imported per request", maxImportUsers)
+	}
+
+	if req.Async {
+		op, err := s.startOperation(ctx, models.OperationImportUsers, admin.ID, int64(len(req.Users)),
+			func(ctx context.Context, progress *operationProgress) (proto.Message, error) {
+				return s.importUsers(ctx, admin, req, progress), nil
+			})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to start import")
+		}
+		log.Printf("Admin %s started importing %d users in operation %s", admin.Email, len(req.Users), op.ID.Hex())
+
+		return &pb.ImportUsersResponse{
+			Operation: operationToProto(op),
+			Message:   "Import started",
+		}, nil
+	}
+
+	return s.importUsers(ctx, admin, req, nil), nil
+}
+
+// importUsers imports the users of req one by one, recording progress when
+// it runs as an operation
+func (s *AdminService) importUsers(ctx context.Context, admin *models.User, req *pb.ImportUsersRequest, progress *operationProgress) *pb.ImportUsersResponse {
+	var imported int32
+	var skipped []*pb.SkippedImportUser
+	for i, importUser := range req.Users {
+		// Each user is imported on its own so one bad record does not
+		// stop the rest
+		err := s.importUser(ctx, importUser, req.DryRun)
+		if err != nil {
+			skipped = append(skipped, &pb.SkippedImportUser{
+				Index:  int32(i),
+				Email:  importUser.Email,
+				Reason: err.Error(),
+			})
+		} else {
+			imported++
+		}
+		if progress != nil && ((i+1)%importProgressEvery == 0 || i == len(req.Users)-1) {
+			progress.Set(ctx, int64(i+1))
+		}
+	}
+
+	message := fmt.Sprintf("Imported %d users, skipped %d", imported, len(skipped))
+	if req.DryRun {
+		message = fmt.Sprintf("Dry run: %d users can be imported, %d would be skipped", imported, len(skipped))
+	} else {
+		log.Printf("Admin %s imported %d users, skipped %d", admin.Email, imported, len(skipped))
+	}
+
+	return &pb.ImportUsersResponse{
+		Imported: imported,
+		Skipped:  skipped,
+		Message:  message,
+	}
+}
+
+// importUser validates one imported user and inserts it unless dryRun is
+// set. The returned error is the reason the user was skipped.
+func (s *AdminService) importUser(ctx context.Context, importUser *pb.ImportUser, dryRun bool) error {
+	user, err := s.importedUser(importUser)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		return s.checkImportConflicts(ctx, user)
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.users.Insert(ctx, *user); err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypeImported); err != nil {
+			return err
+		}
+
+		return events.Enqueue(ctx, s.db, events.TypeUserRegistered, bson.M{
+			"user_id": user.ID.String(),
+			"email":   user.Email,
+			"name":    user.Name,
+			"source":  "import",
+		})
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("email or external ID already in use")
+		}
+		log.Printf("Failed to import user %s: %v", user.Email, err)
+		return fmt.Errorf("failed to import user")
+	}
+
+	return nil
+}
+
+// importedUser builds the account for an imported user
+func (s *AdminService) importedUser(importUser *pb.ImportUser) (*models.User, error) {
+	email := utils.SanitizeString(importUser.Email)
+	if err := utils.ValidateEmail(email); err != nil {
+		return nil, err
+	}
+
+	name := utils.SanitizeString(importUser.Name)
+	if err := utils.ValidateName(name, "name"); err != nil {
+		return nil, err
+	}
+
+	// Imported hashes are kept as they are and replaced at the next login
+	if importUser.PasswordHash != "" {
+		if _, err := utils.PasswordHashFormat(importUser.PasswordHash); err != nil {
+			return nil, err
+		}
+	}
+
+	externalIDs := make([]models.ExternalID, 0, len(importUser.ExternalIds))
+	for system, id := range importUser.ExternalIds {
+		system = strings.ToLower(utils.SanitizeString(system))
+		if err := utils.ValidateExternalSystem(system); err != nil {
+			return nil, err
+		}
+		id = utils.SanitizeString(id)
+		if err := utils.ValidateExternalID(id); err != nil {
+			return nil, err
+		}
+		externalIDs = append(externalIDs, models.ExternalID{System: system, ID: id})
+	}
+	sort.Slice(externalIDs, func(i, j int) bool {
+		return externalIDs[i].System < externalIDs[j].System
+	})
+
+	userID, err := s.config.UserIDs.NewID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate user ID")
+	}
+
+	now := time.Now()
+	return &models.User{
+		ID:            userID,
+		Email:         email,
+		Password:      importUser.PasswordHash,
+		Name:          name,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		IsActive:      !importUser.Disabled,
+		IsDeleted:     false,
+		EmailVerified: importUser.EmailVerified,
+		ExternalIDs:   externalIDs,
+	}, nil
+}
+
+// checkImportConflicts reports an imported user whose email or external
+// IDs already belong to an account
+func (s *AdminService) checkImportConflicts(ctx context.Context, user *models.User) error {
+	conflict, err := s.users.Conflicts(ctx, user.Email, user.ExternalIDs)
+	if err != nil {
+		return fmt.Errorf("failed to check existing users")
+	}
+	if conflict {
+		return fmt.Errorf("email or external ID already in use")
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/eventsource"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+func (s *AdminService) LockUser(ctx context.Context, req *pb.LockUserRequest) (*pb.LockUserResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	if userID == admin.ID {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot lock your own account")
+	}
+
+	now := time.Now()
+	err = s.users.Lock(ctx, userID, models.AccountLock{
+		Kind:     models.LockKindAdmin,
+		Reason:   utils.SanitizeString(req.Reason),
+		LockedAt: now,
+		LockedBy: &admin.ID,
+	}, now)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to lock user")
+	}
+	recordUserEvent(ctx, s.db, userID, eventsource.TypeLocked)
+
+	log.Printf("Admin %s locked user %s", admin.Email, req.UserId)
+
+	return &pb.LockUserResponse{
+		Message: "User locked",
+	}, nil
+}
+
+func (s *AdminService) UnlockUser(ctx context.Context, req *pb.UnlockUserRequest) (*pb.UnlockUserResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	// Lifts both admin and protective locks
+	err = s.users.Unlock(ctx, userID, time.Now())
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to unlock user")
+	}
+	recordUserEvent(ctx, s.db, userID, eventsource.TypeUnlocked)
+
+	log.Printf("Admin %s unlocked user %s", admin.Email, req.UserId)
+
+	return &pb.UnlockUserResponse{
+		Message: "User unlocked",
+	}, nil
+}
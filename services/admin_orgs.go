@@ -0,0 +1,572 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/audit"
+	"user-management/authn"
+	"user-management/eventsource"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/provisioning"
+	"user-management/sso"
+	"user-management/utils"
+)
+
+func (s *AdminService) CreateOrganization(ctx context.Context, req *pb.CreateOrganizationRequest) (*pb.CreateOrganizationResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Name = utils.SanitizeString(req.Name)
+	if err := utils.ValidateName(req.Name, "name"); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	now := time.Now()
+	org := models.Organization{
+		Name:      req.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if req.Policy != nil {
+		org.Policy, err = organizationPolicyFromProto(req.Policy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.db.Orgs.InsertOne(ctx, org)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create organization")
+	}
+	org.ID = result.InsertedID.(primitive.ObjectID)
+
+	log.Printf("Admin %s created organization %s", admin.Email, org.ID.Hex())
+
+	return &pb.CreateOrganizationResponse{
+		Organization: organizationToProto(&org),
+	}, nil
+}
+
+func (s *AdminService) UpdateOrganizationPolicy(ctx context.Context, req *pb.UpdateOrganizationPolicyRequest) (*pb.UpdateOrganizationPolicyResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgObjectID, err := primitive.ObjectIDFromHex(req.OrgId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID format")
+	}
+
+	if req.Policy == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "policy is required")
+	}
+	policy, err := organizationPolicyFromProto(req.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var org models.Organization
+	err = s.db.Orgs.FindOneAndUpdate(ctx, bson.M{"_id": orgObjectID}, bson.M{
+		"$set": bson.M{
+			"policy":     policy,
+			"updated_at": time.Now(),
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&org)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "organization not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update organization")
+	}
+
+	log.Printf("Admin %s updated the policy of organization %s", admin.Email, req.OrgId)
+
+	return &pb.UpdateOrganizationPolicyResponse{
+		Organization: organizationToProto(&org),
+	}, nil
+}
+
+// SetOrganizationSSO connects an organization to its identity provider, or
+// disconnects it. Logins with an email address in one of the connection's
+// domains are sent to the provider from then on.
+func (s *AdminService) SetOrganizationSSO(ctx context.Context, req *pb.SetOrganizationSSORequest) (*pb.SetOrganizationSSOResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgObjectID, err := primitive.ObjectIDFromHex(req.OrgId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID format")
+	}
+
+	var existing models.Organization
+	err = s.db.Orgs.FindOne(ctx, bson.M{"_id": orgObjectID}).Decode(&existing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "organization not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find organization")
+	}
+
+	if req.DeprovisionMembers && req.Sso != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "deprovision_members requires removing the connection")
+	}
+
+	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
+	if req.Sso == nil {
+		update["$unset"] = bson.M{"sso": ""}
+	} else {
+		connection, err := ssoConnectionFromProto(req.Sso, existing.SSO)
+		if err != nil {
+			return nil, err
+		}
+		update["$set"].(bson.M)["sso"] = connection
+	}
+
+	// Members of a removed provider are deprovisioned only if the removal
+	// commits
+	var org models.Organization
+	var job *models.DeprovisioningJob
+	var op *models.PendingOperation
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		err := s.db.Orgs.FindOneAndUpdate(ctx, bson.M{"_id": orgObjectID}, update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&org)
+		if err != nil {
+			return err
+		}
+
+		if !req.DeprovisionMembers || existing.SSO == nil {
+			return nil
+		}
+		job = &models.DeprovisioningJob{
+			OrgID:       orgObjectID,
+			Reason:      models.DeprovisionReasonSSODisconnected,
+			Issuer:      existing.SSO.Issuer,
+			RequestedBy: admin.ID,
+		}
+		if s.undoWindow() > 0 {
+			op = &models.PendingOperation{
+				Kind:           models.OperationDeprovision,
+				Deprovisioning: job,
+				RequestedBy:    admin.ID,
+			}
+			job = nil
+			return s.queueOperation(ctx, op)
+		}
+		return s.queueDeprovisioning(ctx, job)
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, status.Errorf(codes.AlreadyExists, "a domain belongs to another organization")
+		}
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "organization not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update organization")
+	}
+
+	if org.SSO == nil {
+		log.Printf("Admin %s removed the SSO connection of organization %s", admin.Email, req.OrgId)
+	} else {
+		log.Printf("Admin %s connected organization %s to %s for %v", admin.Email, req.OrgId, org.SSO.Issuer, org.SSO.Domains)
+	}
+
+	response := &pb.SetOrganizationSSOResponse{
+		Organization: organizationToProto(&org),
+	}
+	if job != nil {
+		log.Printf("Admin %s queued deprovisioning job %s for organization %s (%s)", admin.Email, job.ID.Hex(), req.OrgId, job.Reason)
+		response.Job = deprovisioningJobToProto(job)
+	}
+	if op != nil {
+		log.Printf("Admin %s queued deprovisioning operation %s for organization %s (%s)", admin.Email, op.ID.Hex(), req.OrgId, op.Deprovisioning.Reason)
+		response.Operation = pendingOperationToProto(op)
+	}
+	return response, nil
+}
+
+// ssoConnectionFromProto validates a connection set by an admin. An empty
+// client secret keeps the secret of the existing connection to the same
+// client.
+func ssoConnectionFromProto(connection *pb.OrganizationSSO, existing *models.SSOConnection) (*models.SSOConnection, error) {
+	if connection.Protocol != sso.ProtocolOIDC {
+		return nil, status.Errorf(codes.InvalidArgument, "protocol must be %q", sso.ProtocolOIDC)
+	}
+	issuer, err := url.Parse(connection.Issuer)
+	if err != nil || issuer.Host == "" || (issuer.Scheme != "https" && issuer.Hostname() != "localhost") {
+		return nil, status.Errorf(codes.InvalidArgument, "issuer must be an https URL")
+	}
+	if connection.ClientId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "client_id is required")
+	}
+
+	secret := connection.ClientSecret
+	if secret == "" && existing != nil && existing.Issuer == connection.Issuer && existing.ClientID == connection.ClientId {
+		secret = existing.ClientSecret
+	}
+	if secret == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "client_secret is required")
+	}
+
+	if len(connection.Domains) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "at least one domain is required")
+	}
+	domains := make([]string, 0, len(connection.Domains))
+	for _, domain := range connection.Domains {
+		domain = strings.ToLower(utils.SanitizeString(domain))
+		if err := sso.ValidateDomain(domain); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+		}
+		if !containsString(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+
+	return &models.SSOConnection{
+		Protocol:     connection.Protocol,
+		Issuer:       connection.Issuer,
+		ClientID:     connection.ClientId,
+		ClientSecret: secret,
+		Domains:      domains,
+		Enforced:     connection.Enforced,
+	}, nil
+}
+
+// maxProvisioningSamples bounds the samples evaluated per request
+const maxProvisioningSamples = 100
+
+// SetOrganizationProvisioning sets the rules evaluated when an identity
+// from the organization's provider first signs in, or removes them. The
+// rules are evaluated against the sample claims, and only checked without
+// being saved in a dry run.
+func (s *AdminService) SetOrganizationProvisioning(ctx context.Context, req *pb.SetOrganizationProvisioningRequest) (*pb.SetOrganizationProvisioningResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgObjectID, err := primitive.ObjectIDFromHex(req.OrgId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID format")
+	}
+
+	var rules *models.ProvisioningRules
+	if req.Provisioning != nil {
+		rules, err = provisioningRulesFromProto(req.Provisioning)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(req.SampleClaims) > maxProvisioningSamples {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d samples can be evaluated per request", maxProvisioningSamples)
+	}
+	decisions := make([]*pb.ProvisioningDecision, 0, len(req.SampleClaims))
+	for i, sample := range req.SampleClaims {
+		var claims map[string]any
+		if err := json.Unmarshal([]byte(sample), &claims); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "sample %d is not a JSON object", i)
+		}
+		decision := provisioningDecisionToProto(provisioning.Evaluate(rules, claims))
+		decision.Index = int32(i)
+		decisions = append(decisions, decision)
+	}
+
+	if req.DryRun {
+		err = s.db.Orgs.FindOne(ctx, bson.M{"_id": orgObjectID}).Err()
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, status.Errorf(codes.NotFound, "organization not found")
+			}
+			return nil, status.Errorf(codes.Internal, "failed to find organization")
+		}
+
+		return &pb.SetOrganizationProvisioningResponse{
+			Decisions: decisions,
+			Message:   "Dry run: the rules are valid and were not saved",
+		}, nil
+	}
+
+	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
+	if rules == nil {
+		update["$unset"] = bson.M{"provisioning": ""}
+	} else {
+		update["$set"].(bson.M)["provisioning"] = rules
+	}
+
+	var org models.Organization
+	err = s.db.Orgs.FindOneAndUpdate(ctx, bson.M{"_id": orgObjectID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&org)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "organization not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update organization")
+	}
+
+	if org.Provisioning == nil {
+		log.Printf("Admin %s removed the provisioning rules of organization %s", admin.Email, req.OrgId)
+	} else {
+		log.Printf("Admin %s set the provisioning rules of organization %s", admin.Email, req.OrgId)
+	}
+
+	return &pb.SetOrganizationProvisioningResponse{
+		Organization: organizationToProto(&org),
+		Decisions:    decisions,
+		Message:      "Provisioning rules saved",
+	}, nil
+}
+
+// provisioningRulesFromProto validates rules set by an admin. Groups and
+// claims are kept as the provider spells them.
+func provisioningRulesFromProto(rules *pb.OrganizationProvisioning) (*models.ProvisioningRules, error) {
+	converted := &models.ProvisioningRules{
+		Enabled:     rules.Enabled,
+		DefaultRole: rules.DefaultRole,
+		GroupsClaim: rules.GroupsClaim,
+	}
+	if converted.DefaultRole == "" {
+		converted.DefaultRole = models.OrgRoleMember
+	}
+	if converted.GroupsClaim == "" {
+		converted.GroupsClaim = provisioning.DefaultGroupsClaim
+	}
+	for _, mapping := range rules.GroupRoles {
+		converted.GroupRoles = append(converted.GroupRoles, models.GroupRole{
+			Group: mapping.Group,
+			Role:  mapping.Role,
+		})
+	}
+	for _, mapping := range rules.RoleMappings {
+		roles := make([]string, 0, len(mapping.Roles))
+		for _, role := range mapping.Roles {
+			roles = append(roles, strings.TrimSpace(role))
+		}
+		converted.RoleMappings = append(converted.RoleMappings, models.RoleMapping{
+			Group: mapping.Group,
+			Roles: roles,
+		})
+	}
+	for _, attribute := range rules.Attributes {
+		converted.Attributes = append(converted.Attributes, models.AttributeMapping{
+			Claim: attribute.Claim,
+			Field: strings.ToLower(strings.TrimSpace(attribute.Field)),
+		})
+	}
+	for _, entry := range rules.Blocklist {
+		converted.Blocklist = append(converted.Blocklist, strings.ToLower(utils.SanitizeString(entry)))
+	}
+
+	if err := provisioning.Validate(converted); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	return converted, nil
+}
+
+func provisioningRulesToProto(rules *models.ProvisioningRules) *pb.OrganizationProvisioning {
+	pbRules := &pb.OrganizationProvisioning{
+		Enabled:     rules.Enabled,
+		DefaultRole: rules.DefaultRole,
+		GroupsClaim: rules.GroupsClaim,
+		Blocklist:   rules.Blocklist,
+	}
+	for _, mapping := range rules.GroupRoles {
+		pbRules.GroupRoles = append(pbRules.GroupRoles, &pb.ProvisioningGroupRole{
+			Group: mapping.Group,
+			Role:  mapping.Role,
+		})
+	}
+	for _, mapping := range rules.RoleMappings {
+		pbRules.RoleMappings = append(pbRules.RoleMappings, &pb.ProvisioningRoleMapping{
+			Group: mapping.Group,
+			Roles: mapping.Roles,
+		})
+	}
+	for _, attribute := range rules.Attributes {
+		pbRules.Attributes = append(pbRules.Attributes, &pb.ProvisioningAttribute{
+			Claim: attribute.Claim,
+			Field: attribute.Field,
+		})
+	}
+	return pbRules
+}
+
+func provisioningDecisionToProto(decision provisioning.Decision) *pb.ProvisioningDecision {
+	pbDecision := &pb.ProvisioningDecision{
+		Allowed:      decision.Allowed,
+		Reason:       decision.Reason,
+		OrgRole:      decision.OrgRole,
+		MatchedGroup: decision.MatchedGroup,
+		Name:         decision.Name,
+		Roles:        decision.Roles,
+	}
+	if len(decision.ExternalIDs) > 0 {
+		pbDecision.ExternalIds = make(map[string]string, len(decision.ExternalIDs))
+		for _, externalID := range decision.ExternalIDs {
+			pbDecision.ExternalIds[externalID.System] = externalID.ID
+		}
+	}
+	return pbDecision
+}
+
+func organizationToProto(org *models.Organization) *pb.Organization {
+	pbOrg := &pb.Organization{
+		Id:        org.ID.Hex(),
+		Name:      org.Name,
+		Policy:    organizationPolicyToProto(org.Policy),
+		CreatedAt: timestamppb.New(org.CreatedAt),
+		UpdatedAt: timestamppb.New(org.UpdatedAt),
+	}
+	// The client secret is never returned
+	if org.SSO != nil {
+		pbOrg.Sso = &pb.OrganizationSSO{
+			Protocol: org.SSO.Protocol,
+			Issuer:   org.SSO.Issuer,
+			ClientId: org.SSO.ClientID,
+			Domains:  org.SSO.Domains,
+			Enforced: org.SSO.Enforced,
+		}
+	}
+	if org.Provisioning != nil {
+		pbOrg.Provisioning = provisioningRulesToProto(org.Provisioning)
+	}
+	return pbOrg
+}
+
+// organizationPolicyFromProto validates a policy set by an admin
+func organizationPolicyFromProto(policy *pb.OrganizationPolicy) (models.OrganizationPolicy, error) {
+	gracePeriod := policy.TwoFactorGracePeriod.AsDuration()
+	if gracePeriod < 0 {
+		return models.OrganizationPolicy{}, status.Errorf(codes.InvalidArgument, "two_factor_grace_period must not be negative")
+	}
+	if err := authn.ValidateMethods(policy.LoginMethods); err != nil {
+		return models.OrganizationPolicy{}, status.Errorf(codes.InvalidArgument, "login_methods: %v", err)
+	}
+
+	return models.OrganizationPolicy{
+		RequireProfileChangeApproval: policy.RequireProfileChangeApproval,
+		RequireTwoFactor:             policy.RequireTwoFactor,
+		TwoFactorGracePeriod:         gracePeriod,
+		LoginMethods:                 policy.LoginMethods,
+	}, nil
+}
+
+func organizationPolicyToProto(policy models.OrganizationPolicy) *pb.OrganizationPolicy {
+	pbPolicy := &pb.OrganizationPolicy{
+		RequireProfileChangeApproval: policy.RequireProfileChangeApproval,
+		RequireTwoFactor:             policy.RequireTwoFactor,
+		LoginMethods:                 policy.LoginMethods,
+	}
+	if policy.TwoFactorGracePeriod > 0 {
+		pbPolicy.TwoFactorGracePeriod = durationpb.New(policy.TwoFactorGracePeriod)
+	}
+	return pbPolicy
+}
+
+func (s *AdminService) SetOrganizationMember(ctx context.Context, req *pb.SetOrganizationMemberRequest) (*pb.SetOrganizationMemberResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgObjectID, err := primitive.ObjectIDFromHex(req.OrgId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID format")
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	// The name is encrypted with the key of the user's organization, so
+	// it's stored again for the new one
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.FailedPrecondition, "user not found or belongs to another organization")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	name := user.Name
+	message := "Member updated"
+	switch req.Role {
+	case models.OrgRoleMember, models.OrgRoleAdmin:
+		err = s.db.Orgs.FindOne(ctx, bson.M{"_id": orgObjectID}).Err()
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, status.Errorf(codes.NotFound, "organization not found")
+			}
+			return nil, status.Errorf(codes.Internal, "failed to find organization")
+		}
+
+		name, err = models.StoredMemberField(&orgObjectID, user.ID, user.Name)
+		if err != nil {
+			return nil, memberDataError(err)
+		}
+	case "":
+		message = "Member removed"
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "role must be %q or %q", models.OrgRoleMember, models.OrgRoleAdmin)
+	}
+
+	changes := []models.AuditChange{{Field: "org_id"}, {Field: "org_role", Before: user.OrgRole}}
+	if user.OrgID != nil {
+		changes[0].Before = user.OrgID.Hex()
+	}
+	if req.Role != "" {
+		changes[0].After, changes[1].After = req.OrgId, req.Role
+	}
+
+	// A user can only belong to one organization at a time
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		err := s.users.SetOrgMembership(ctx, userID, orgObjectID, req.Role, name, time.Now())
+		if err == mongo.ErrNoDocuments {
+			return errUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionOrgRoleChanged,
+			ActorID:  &admin.ID,
+			TargetID: userID,
+			Changes:  changes,
+		})
+	})
+	if err != nil {
+		if err == errUserNotFound {
+			return nil, status.Errorf(codes.FailedPrecondition, "user not found or belongs to another organization")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update member")
+	}
+	recordUserEvent(ctx, s.db, userID, eventsource.TypeOrgMembershipChanged)
+
+	log.Printf("Admin %s set user %s role in organization %s to %q", admin.Email, req.UserId, req.OrgId, req.Role)
+
+	return &pb.SetOrganizationMemberResponse{
+		Message: message,
+	}, nil
+}
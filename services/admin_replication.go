@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "user-management/proto"
+)
+
+func (s *AdminService) GetReplicationStatus(ctx context.Context, req *pb.GetReplicationStatusRequest) (*pb.GetReplicationStatusResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	replication := s.db.ReplicationStatus()
+
+	var members []*pb.ReplicationMember
+	for _, member := range replication.Members {
+		members = append(members, &pb.ReplicationMember{
+			Host:        member.Host,
+			Region:      member.Region,
+			State:       member.State,
+			LagMs:       member.Lag.Milliseconds(),
+			RoundTripMs: member.RoundTrip.Milliseconds(),
+		})
+	}
+
+	resp := &pb.GetReplicationStatusResponse{
+		LocalRegion:            replication.LocalRegion,
+		Primary:                replication.Primary,
+		PrimaryRegion:          replication.PrimaryRegion,
+		Members:                members,
+		MajorityWrites:         replication.MajorityWrites,
+		MaxRemoteLagMs:         replication.MaxLag().Milliseconds(),
+		LastFailoverDurationMs: replication.LastFailoverDuration.Milliseconds(),
+	}
+	if !replication.MajorityWrites {
+		resp.RpoMs = resp.MaxRemoteLagMs
+	}
+	if !replication.LastFailoverAt.IsZero() {
+		resp.LastFailoverAt = timestamppb.New(replication.LastFailoverAt)
+	}
+	if !replication.NoPrimarySince.IsZero() {
+		resp.NoPrimarySince = timestamppb.New(replication.NoPrimarySince)
+	}
+	return resp, nil
+}
@@ -0,0 +1,708 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/audit"
+	"user-management/database"
+	"user-management/events"
+	"user-management/eventsource"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// errUserNotDeleted aborts a hard delete of an account that was restored
+// or never soft-deleted
+var errUserNotDeleted = errors.New("user is not deleted")
+
+// DeactivateUser stops the user from signing in and signs them out
+// everywhere, keeping the account and its data
+func (s *AdminService) DeactivateUser(ctx context.Context, req *pb.DeactivateUserRequest) (*pb.DeactivateUserResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+	if userID == admin.ID {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot deactivate your own account")
+	}
+	reason := utils.SanitizeString(req.Reason)
+
+	var revoked int
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		revoked, err = s.deactivateUser(ctx, userID, admin.ID, reason)
+		return err
+	})
+	if err != nil {
+		if err == errUserNotFound {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to deactivate user")
+	}
+
+	log.Printf("Admin %s deactivated user %s", admin.Email, req.UserId)
+
+	return &pb.DeactivateUserResponse{
+		SessionsRevoked: int32(revoked),
+		Message:         "User deactivated",
+	}, nil
+}
+
+// deactivateUser stops the user from signing in, signs them out
+// everywhere and returns how many sessions were revoked. Call it inside a
+// transaction.
+func (s *AdminService) deactivateUser(ctx context.Context, userID, actorID models.ID, reason string) (int, error) {
+	now := time.Now()
+	err := s.users.Deactivate(ctx, userID, now)
+	if err == database.ErrNotFound {
+		return 0, errUserNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	revoked, err := s.sessions.RevokeAllSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := eventsource.Record(ctx, s.db, userID, eventsource.TypeDeactivated); err != nil {
+		return 0, err
+	}
+	if err := events.Enqueue(ctx, s.db, events.TypeUserUpdated, bson.M{
+		"user_id": userID.String(),
+		"changes": bson.M{"is_active": false},
+	}); err != nil {
+		return 0, err
+	}
+	return revoked, audit.Record(ctx, s.db, models.AuditLog{
+		Action:   audit.ActionUserDeactivated,
+		ActorID:  &actorID,
+		TargetID: userID,
+		Details: bson.M{
+			"reason":           reason,
+			"sessions_revoked": revoked,
+		},
+		CreatedAt: now,
+	})
+}
+
+// ReactivateUser lets a deactivated user sign in again
+func (s *AdminService) ReactivateUser(ctx context.Context, req *pb.ReactivateUserRequest) (*pb.ReactivateUserResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		err := s.users.Reactivate(ctx, userID, now)
+		if err == database.ErrNotFound {
+			return errUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := eventsource.Record(ctx, s.db, userID, eventsource.TypeReactivated); err != nil {
+			return err
+		}
+		if err := events.Enqueue(ctx, s.db, events.TypeUserUpdated, bson.M{
+			"user_id": userID.String(),
+			"changes": bson.M{"is_active": true},
+		}); err != nil {
+			return err
+		}
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionUserReactivated,
+			ActorID:   &admin.ID,
+			TargetID:  userID,
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		if err == errUserNotFound {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reactivate user")
+	}
+
+	log.Printf("Admin %s reactivated user %s", admin.Email, req.UserId)
+
+	return &pb.ReactivateUserResponse{
+		Message: "User reactivated",
+	}, nil
+}
+
+// HardDeleteUser permanently removes a soft-deleted account with its
+// sessions, devices, credentials and history. Audit entries about the
+// account are kept. Its email address can then be registered again. With
+// an undo window the delete is queued and runs once it has passed.
+func (s *AdminService) HardDeleteUser(ctx context.Context, req *pb.HardDeleteUserRequest) (*pb.HardDeleteUserResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+	if req.DryRun {
+		return s.hardDeleteDryRun(ctx, userID)
+	}
+	if !req.Confirm {
+		return nil, status.Errorf(codes.InvalidArgument, "confirm must be set, the account can't be recovered")
+	}
+
+	if s.undoWindow() > 0 {
+		return s.queueHardDelete(ctx, admin, userID)
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		return s.hardDeleteUser(ctx, userID, admin.ID)
+	})
+	if err != nil {
+		switch err {
+		case errUserNotFound:
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		case errUserNotDeleted:
+			return nil, status.Errorf(codes.FailedPrecondition, "only deleted users can be hard-deleted, delete the user first")
+		}
+		log.Printf("Failed to hard-delete user %s: %v", req.UserId, err)
+		return nil, status.Errorf(codes.Internal, "failed to delete user")
+	}
+
+	log.Printf("Admin %s hard-deleted user %s", admin.Email, req.UserId)
+
+	return &pb.HardDeleteUserResponse{
+		Message: "User permanently deleted",
+	}, nil
+}
+
+// hardDeleteUser removes a soft-deleted account and its data on behalf of
+// actorID. Call it in a transaction.
+func (s *AdminService) hardDeleteUser(ctx context.Context, userID, actorID models.ID) error {
+	if err := requireDeletedUser(ctx, s.users, userID); err != nil {
+		return err
+	}
+
+	if err := s.users.Delete(ctx, userID); err != nil {
+		return err
+	}
+	if err := deleteUserData(ctx, s.db, userID); err != nil {
+		return err
+	}
+
+	return audit.Record(ctx, s.db, models.AuditLog{
+		Action:   audit.ActionUserHardDeleted,
+		ActorID:  &actorID,
+		TargetID: userID,
+	})
+}
+
+// queueHardDelete holds a hard delete for the undo window
+func (s *AdminService) queueHardDelete(ctx context.Context, admin *models.User, userID models.ID) (*pb.HardDeleteUserResponse, error) {
+	switch err := requireDeletedUser(ctx, s.users, userID); err {
+	case nil:
+	case errUserNotFound:
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	case errUserNotDeleted:
+		return nil, status.Errorf(codes.FailedPrecondition, "only deleted users can be hard-deleted, delete the user first")
+	default:
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	op := models.PendingOperation{
+		Kind:        models.OperationHardDelete,
+		UserID:      userID,
+		RequestedBy: admin.ID,
+	}
+	if err := s.queueOperation(ctx, &op); err != nil {
+		log.Printf("Failed to queue hard delete of user %s: %v", userID.String(), err)
+		return nil, status.Errorf(codes.Internal, "failed to queue delete")
+	}
+
+	log.Printf("Admin %s queued hard delete %s of user %s", admin.Email, op.ID.Hex(), userID.String())
+
+	return &pb.HardDeleteUserResponse{
+		Message:   fmt.Sprintf("User will be permanently deleted at %s unless the operation is canceled", op.RunAt.UTC().Format(time.RFC3339)),
+		Operation: pendingOperationToProto(&op),
+	}, nil
+}
+
+// hardDeleteDryRun reports what HardDeleteUser would remove
+func (s *AdminService) hardDeleteDryRun(ctx context.Context, userID models.ID) (*pb.HardDeleteUserResponse, error) {
+	switch err := requireDeletedUser(ctx, s.users, userID); err {
+	case nil:
+	case errUserNotFound:
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	case errUserNotDeleted:
+		return nil, status.Errorf(codes.FailedPrecondition, "only deleted users can be hard-deleted, delete the user first")
+	default:
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	filters := append([]collectionFilter{
+		{collection: s.db.Users, filter: bson.M{"_id": userID}, operation: operationDelete},
+	}, userData(s.db, userID)...)
+	affected, err := affectedRecords(ctx, filters)
+	if err != nil {
+		log.Printf("Failed to dry-run hard delete of user %s: %v", userID.String(), err)
+		return nil, status.Errorf(codes.Internal, "failed to check what would be deleted")
+	}
+
+	return &pb.HardDeleteUserResponse{
+		Message:  "Dry run, nothing was deleted",
+		Affected: affected,
+	}, nil
+}
+
+// requireDeletedUser returns errUserNotFound or errUserNotDeleted unless
+// the user exists and was deleted
+func requireDeletedUser(ctx context.Context, users database.UserRepository, userID models.ID) error {
+	user, err := users.FindIncludingDeleted(ctx, userID)
+	if err == database.ErrNotFound {
+		return errUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !user.IsDeleted {
+		return errUserNotDeleted
+	}
+	return nil
+}
+
+// deleteUserData removes what is kept about a user besides their document:
+// sessions, devices, passkeys, pending requests and history. Audit entries
+// about the user are kept, without the addresses and user agents they were
+// made from. Call it in the transaction deleting the user.
+func deleteUserData(ctx context.Context, db *database.Database, userID models.ID) error {
+	for _, data := range userData(db, userID) {
+		var err error
+		if data.operation == operationUpdate {
+			_, err = data.collection.UpdateMany(ctx, data.filter, bson.M{
+				"$unset": bson.M{
+					"ip_address": "",
+					"user_agent": "",
+				},
+			})
+		} else {
+			_, err = data.collection.DeleteMany(ctx, data.filter)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userData is where deleteUserData removes a user's data from
+func userData(db *database.Database, userID models.ID) []collectionFilter {
+	data := []collectionFilter{
+		{collection: db.LoginHistory, filter: bson.M{"_id": userID}, operation: operationDelete},
+	}
+	for _, collection := range []*database.Collection{
+		db.UserEvents,
+		db.UserSnapshots,
+		db.Sessions,
+		db.RefreshTokens,
+		db.APIKeys,
+		db.Devices,
+		db.Pending,
+		db.DeviceLogins,
+		db.Passkeys,
+		db.Challenges,
+		db.PasswordResets,
+		db.Changes,
+	} {
+		data = append(data, collectionFilter{collection: collection, filter: bson.M{"user_id": userID}, operation: operationDelete})
+	}
+	return append(data, collectionFilter{
+		collection: db.AuditLogs,
+		filter: bson.M{"$or": []bson.M{
+			{"target_id": userID},
+			{"actor_id": userID},
+		}},
+		operation: operationUpdate,
+	})
+}
+
+// ResetUserPassword removes the user's password, signs them out everywhere
+// and emails them a link to choose a new one. The admin never learns the
+// new password.
+func (s *AdminService) ResetUserPassword(ctx context.Context, req *pb.ResetUserPasswordRequest) (*pb.ResetUserPasswordResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate reset token")
+	}
+	ttl := time.Duration(s.config.Settings.PasswordReset.TTL)
+	expiresAt := time.Now().Add(ttl)
+
+	var user *models.User
+	var revoked int
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		var err error
+		user, err = s.users.ClearPassword(ctx, userID, now)
+		if err == database.ErrNotFound {
+			return errUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		revoked, err = s.sessions.RevokeAllSessions(ctx, userID)
+		if err != nil {
+			return err
+		}
+		// Not counted towards the user's or the admin's reset rate limits
+		if _, err := s.db.PasswordResets.InsertOne(ctx, models.PasswordReset{
+			UserID:    &userID,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: expiresAt,
+			CreatedAt: now,
+		}); err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, userID, eventsource.TypePasswordResetByAdmin); err != nil {
+			return err
+		}
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionPasswordResetByAdmin,
+			ActorID:  &admin.ID,
+			TargetID: userID,
+			Details: bson.M{
+				"sessions_revoked": revoked,
+			},
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		if err == errUserNotFound {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reset password")
+	}
+
+	log.Printf("Admin %s reset the password of user %s", admin.Email, req.UserId)
+
+	msg, err := notifications.Render(notifications.TemplateAdminPasswordReset, user.Email, map[string]string{
+		"ResetLink": fmt.Sprintf("%s?token=%s", s.config.Settings.PasswordReset.URL, url.QueryEscape(token)),
+		"ExpiresIn": ttl.String(),
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		// The password is already gone. The user can still get a link with
+		// RequestPasswordReset, or the admin can reset it again.
+		log.Printf("Failed to send password reset link to %s: %v", user.Email, err)
+		return nil, status.Errorf(codes.Internal, "password reset, but failed to email the reset link")
+	}
+
+	return &pb.ResetUserPasswordResponse{
+		ExpiresAt:       timestamppb.New(expiresAt),
+		SessionsRevoked: int32(revoked),
+		Message:         "Password reset, a link to choose a new one was emailed to the user",
+	}, nil
+}
+
+// maxUsersByIDs bounds the IDs in one GetUsersByIds request
+const maxUsersByIDs = 100
+
+// GetUsersByIds returns the users with the given IDs in one query, for
+// services that show the owners or authors of many records
+func (s *AdminService) GetUsersByIds(ctx context.Context, req *pb.GetUsersByIdsRequest) (*pb.GetUsersByIdsResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if len(req.UserIds) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "user_ids are required")
+	}
+	if len(req.UserIds) > maxUsersByIDs {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d users can be looked up per request", maxUsersByIDs)
+	}
+
+	userIDs := make([]models.ID, 0, len(req.UserIds))
+	for _, rawID := range req.UserIds {
+		userID, err := models.ParseID(rawID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format: %q", rawID)
+		}
+		if !slices.Contains(userIDs, userID) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	users, err := s.users.FindByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find users")
+	}
+	found := make(map[models.ID]models.User, len(users))
+	for _, user := range users {
+		found[user.ID] = user
+	}
+
+	response := &pb.GetUsersByIdsResponse{}
+	for _, userID := range userIDs {
+		user, ok := found[userID]
+		if !ok {
+			response.MissingIds = append(response.MissingIds, userID.String())
+			continue
+		}
+		response.Users = append(response.Users, &pb.User{
+			Id:          user.ID.String(),
+			Email:       user.Email,
+			Name:        user.Name,
+			CreatedAt:   timestamppb.New(user.CreatedAt),
+			UpdatedAt:   timestamppb.New(user.UpdatedAt),
+			IsActive:    user.IsActive,
+			IsDeleted:   user.IsDeleted,
+			ExternalIds: user.ExternalIDMap(),
+		})
+	}
+	return response, nil
+}
+
+// ExportUsers streams the users matching the request's filters in ID
+// order, reading them with one cursor rather than a page at a time
+func (s *AdminService) ExportUsers(req *pb.ExportUsersRequest, stream pb.AdminService_ExportUsersServer) error {
+	ctx := stream.Context()
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	search := database.UserSearch{
+		NameFilter:  utils.SanitizeString(req.NameFilter),
+		EmailFilter: utils.SanitizeString(req.EmailFilter),
+		IsActive:    req.IsActive,
+	}
+	if req.CreatedAfter != nil {
+		search.CreatedAfter = req.CreatedAfter.AsTime()
+	}
+	if req.CreatedBefore != nil {
+		search.CreatedBefore = req.CreatedBefore.AsTime()
+	}
+	if err := utils.ValidateUserSearch(search); err != nil {
+		return status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	var resumeAfter models.ID
+	if req.ResumeAfter != "" {
+		resumeAfter, err = models.ParseID(req.ResumeAfter)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid resume_after user ID format")
+		}
+	}
+
+	log.Printf("Admin %s started exporting users", admin.Email)
+
+	// Errors sending to the client are returned as they are
+	exported := 0
+	var sendErr error
+	err = s.users.EachMatching(ctx, search, resumeAfter, func(user models.User) error {
+		sendErr = stream.Send(&pb.User{
+			Id:          user.ID.String(),
+			Email:       user.Email,
+			Name:        user.Name,
+			CreatedAt:   timestamppb.New(user.CreatedAt),
+			UpdatedAt:   timestamppb.New(user.UpdatedAt),
+			IsActive:    user.IsActive,
+			IsDeleted:   user.IsDeleted,
+			ExternalIds: user.ExternalIDMap(),
+		})
+		if sendErr != nil {
+			return sendErr
+		}
+		exported++
+		return nil
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		log.Printf("Export of users failed after %d users: %v", exported, err)
+		return status.Errorf(codes.Internal, "failed to read users, resume after the last user received")
+	}
+
+	log.Printf("Admin %s exported %d users", admin.Email, exported)
+	return nil
+}
+
+// GetLoginHistory returns the user's recent successful logins
+func (s *AdminService) GetLoginHistory(ctx context.Context, req *pb.GetLoginHistoryRequest) (*pb.GetLoginHistoryResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	var history models.LoginHistory
+	err = s.db.LoginHistory.FindOne(ctx, bson.M{"_id": userID}).Decode(&history)
+	if err == database.ErrNotFound {
+		// Users who never logged in have no history
+		if _, err := s.users.FindIncludingDeleted(ctx, userID); err != nil {
+			if err == database.ErrNotFound {
+				return nil, status.Errorf(codes.NotFound, "user not found")
+			}
+			return nil, status.Errorf(codes.Internal, "failed to find user")
+		}
+		return &pb.GetLoginHistoryResponse{}, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find login history")
+	}
+
+	response := &pb.GetLoginHistoryResponse{
+		LoginCount:  history.LoginCount,
+		LastLoginAt: timestamppb.New(history.LastLoginAt),
+	}
+	// Stored oldest first
+	for _, login := range slices.Backward(history.Logins) {
+		response.Logins = append(response.Logins, &pb.LoginRecord{
+			IpAddress: login.IPAddress,
+			UserAgent: login.UserAgent,
+			At:        timestamppb.New(login.At),
+		})
+	}
+	return response, nil
+}
+
+// ForceLogout signs the user out on every device. Unlike DeactivateUser,
+// they can sign in again straight away.
+func (s *AdminService) ForceLogout(ctx context.Context, req *pb.ForceLogoutRequest) (*pb.ForceLogoutResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+	if req.DryRun {
+		return s.forceLogoutDryRun(ctx, userID)
+	}
+
+	var revoked int
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		err := s.users.RevokeTokens(ctx, userID, now)
+		if err == database.ErrNotFound {
+			return errUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		revoked, err = s.sessions.RevokeAllSessions(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionSessionsRevoked,
+			ActorID:  &admin.ID,
+			TargetID: userID,
+			Details: bson.M{
+				"sessions_revoked": revoked,
+			},
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		if err == errUserNotFound {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to sign out user")
+	}
+
+	log.Printf("Admin %s signed out user %s on every device", admin.Email, req.UserId)
+
+	return &pb.ForceLogoutResponse{
+		SessionsRevoked: int32(revoked),
+		Message:         "User signed out on every device",
+	}, nil
+}
+
+// forceLogoutDryRun reports the sessions ForceLogout would revoke
+func (s *AdminService) forceLogoutDryRun(ctx context.Context, userID models.ID) (*pb.ForceLogoutResponse, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err == database.ErrNotFound {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	sessions, err := s.sessions.ActiveSessions(ctx, userID, user.TokensValidAfter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list sessions")
+	}
+	affected := []*pb.AffectedRecords{{
+		Collection: s.db.Users.Name(),
+		Operation:  operationUpdate,
+		Count:      1,
+		SampleIds:  []string{userID.String()},
+	}}
+	if len(sessions) > 0 {
+		records := &pb.AffectedRecords{
+			Collection: s.db.Sessions.Name(),
+			Operation:  operationUpdate,
+			Count:      int64(len(sessions)),
+		}
+		for _, session := range sessions {
+			if len(records.SampleIds) == dryRunSampleSize {
+				break
+			}
+			records.SampleIds = append(records.SampleIds, session.ID)
+		}
+		affected = append(affected, records)
+	}
+
+	return &pb.ForceLogoutResponse{
+		SessionsRevoked: int32(len(sessions)),
+		Message:         "Dry run, nobody was signed out",
+		Affected:        affected,
+	}, nil
+}
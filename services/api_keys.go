@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/audit"
+	"user-management/auth"
+	"user-management/authz"
+	"user-management/database"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// maxAPIKeys is how many API keys a user may have at a time
+const maxAPIKeys = 20
+
+// apiKeyPrefixLength is how much of a key, after auth.APIKeyPrefix, is
+// kept to tell it apart
+const apiKeyPrefixLength = 8
+
+// CreateApiKey issues an API key that acts as the caller. The key is only
+// returned here, and only its hash is stored. Keys can't be created with
+// another API key, so a leaked key can't be used to make more.
+func (s *UserService) CreateApiKey(ctx context.Context, req *pb.CreateApiKeyRequest) (*pb.CreateApiKeyResponse, error) {
+	if _, err := authz.RequireSession(ctx); err != nil {
+		return nil, err
+	}
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := utils.SanitizeString(req.Name)
+	if err := utils.ValidateCredentialName(name); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	now := time.Now()
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		expiry := req.ExpiresAt.AsTime()
+		if !expiry.After(now) {
+			return nil, status.Errorf(codes.InvalidArgument, "expires_at must be in the future")
+		}
+		expiresAt = &expiry
+	}
+
+	// Expired and revoked keys don't count
+	apiKeys, err := s.usableAPIKeys(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count API keys")
+	}
+	if len(apiKeys) >= maxAPIKeys {
+		return nil, status.Errorf(codes.FailedPrecondition, "at most %d API keys are allowed, revoke one first", maxAPIKeys)
+	}
+
+	key, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate API key")
+	}
+	apiKey := models.APIKey{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    key[:len(auth.APIKeyPrefix)+apiKeyPrefixLength],
+		KeyHash:   hash,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.apiKeys.Insert(ctx, apiKey); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionAPIKeyCreated,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: requestClientIP(ctx),
+			UserAgent: requestUserAgent(ctx),
+			Details: bson.M{
+				"api_key_id": apiKey.ID.Hex(),
+				"name":       name,
+			},
+		})
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create API key")
+	}
+
+	return &pb.CreateApiKeyResponse{
+		ApiKey: apiKeyToProto(apiKey),
+		Key:    key,
+	}, nil
+}
+
+// ListApiKeys returns the caller's API keys, oldest first
+func (s *UserService) ListApiKeys(ctx context.Context, req *pb.ListApiKeysRequest) (*pb.ListApiKeysResponse, error) {
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := s.usableAPIKeys(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve API keys")
+	}
+
+	response := &pb.ListApiKeysResponse{
+		ApiKeys: make([]*pb.ApiKey, 0, len(apiKeys)),
+	}
+	for _, apiKey := range apiKeys {
+		response.ApiKeys = append(response.ApiKeys, apiKeyToProto(apiKey))
+	}
+	return response, nil
+}
+
+// RevokeApiKey deletes one of the caller's API keys. Requests with it fail
+// from then on.
+func (s *UserService) RevokeApiKey(ctx context.Context, req *pb.RevokeApiKeyRequest) (*pb.RevokeApiKeyResponse, error) {
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := primitive.ObjectIDFromHex(req.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid API key ID format")
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.apiKeys.Delete(ctx, user.ID, id); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionAPIKeyRevoked,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: requestClientIP(ctx),
+			UserAgent: requestUserAgent(ctx),
+			Details:   bson.M{"api_key_id": id.Hex()},
+		})
+	})
+	if err != nil {
+		if err == database.ErrNotFound {
+			return nil, status.Errorf(codes.NotFound, "API key not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to revoke API key")
+	}
+
+	return &pb.RevokeApiKeyResponse{
+		Message: "API key revoked",
+	}, nil
+}
+
+// usableAPIKeys returns the user's keys that still authenticate, oldest
+// first. The TTL index removes expired keys about once a minute, and keys
+// revoked along with the user's tokens are kept but never accepted again.
+func (s *UserService) usableAPIKeys(ctx context.Context, user *models.User) ([]models.APIKey, error) {
+	apiKeys, err := s.apiKeys.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	usable := apiKeys[:0]
+	now := time.Now()
+	for _, apiKey := range apiKeys {
+		if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+			continue
+		}
+		if apiKey.Revoked(user) {
+			continue
+		}
+		usable = append(usable, apiKey)
+	}
+	return usable, nil
+}
+
+func apiKeyToProto(apiKey models.APIKey) *pb.ApiKey {
+	pbKey := &pb.ApiKey{
+		Id:        apiKey.ID.Hex(),
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		CreatedAt: timestamppb.New(apiKey.CreatedAt),
+	}
+	if apiKey.LastUsedAt != nil {
+		pbKey.LastUsedAt = timestamppb.New(*apiKey.LastUsedAt)
+	}
+	if apiKey.ExpiresAt != nil {
+		pbKey.ExpiresAt = timestamppb.New(*apiKey.ExpiresAt)
+	}
+	return pbKey
+}
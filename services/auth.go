@@ -2,48 +2,181 @@ package services
 
 import (
 	"context"
+	"errors"
+	"log"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"user-management/alerts"
+	"user-management/apierrors"
+	"user-management/audit"
 	"user-management/auth"
+	"user-management/authn"
+	"user-management/authz"
 	"user-management/database"
+	"user-management/events"
+	"user-management/eventsource"
+	"user-management/ldapauth"
 	"user-management/models"
+	"user-management/notifications"
+	"user-management/passkeys"
 	pb "user-management/proto"
+	"user-management/session"
+	"user-management/social"
+	"user-management/sso"
 	"user-management/utils"
 )
 
+// AuthConfig holds the optional behaviour of AuthService
+type AuthConfig struct {
+	// RequireLoginApproval holds logins from unrecognised devices until
+	// they are approved from one of the account's existing devices
+	RequireLoginApproval bool
+	LoginApprovalTTL     time.Duration
+	// LoginApprovalURL is the page that approval links in emails point to
+	LoginApprovalURL string
+
+	DeviceLoginTTL      time.Duration
+	DeviceLoginInterval time.Duration
+	// DeviceLoginURL is the verification page where users enter or scan a
+	// device's code
+	DeviceLoginURL string
+	// DeviceClientIDs lists the clients allowed to use the device
+	// authorization grant. An empty list allows any client.
+	DeviceClientIDs []string
+
+	// MaxFailedLogins failed logins are allowed per email and IP address
+	// within LoginRateLimitWindow
+	MaxFailedLogins      int
+	LoginRateLimitWindow time.Duration
+	// LockoutThreshold failed passwords in a row lock the account for
+	// LockoutDuration. Zero disables the lockout.
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+
+	PasswordResetTTL time.Duration
+	// PasswordResetURL is the page that reset links in emails point to
+	PasswordResetURL string
+	// MaxPasswordResetsPerEmail and MaxPasswordResetsPerIP cap reset
+	// requests within passwordResetWindow
+	MaxPasswordResetsPerEmail int
+	MaxPasswordResetsPerIP    int
+
+	// PasswordPolicy is the set of rules new passwords must meet. The zero
+	// value selects utils.DefaultPasswordPolicy.
+	PasswordPolicy utils.PasswordPolicy
+	// PasswordMaxAge is how long a password lasts before login asks for a
+	// new one, and PasswordExpiryWarning how long before that it warns.
+	// Zero disables expiry.
+	PasswordMaxAge        time.Duration
+	PasswordExpiryWarning time.Duration
+
+	// TermsVersion is the current terms of service, which login asks users
+	// who haven't accepted it to accept. Empty when there are no terms.
+	TermsVersion string
+	TermsURL     string
+
+	// RequireTwoFactor limits users without 2FA to enrollment once
+	// TwoFactorGracePeriod has passed since their first login under the
+	// requirement. Organizations can require it of their members alone.
+	RequireTwoFactor     bool
+	TwoFactorGracePeriod time.Duration
+	// TwoFactorCipher decrypts the TOTP secrets Login checks codes
+	// against
+	TwoFactorCipher *utils.SecretCipher
+
+	// TwoFactorResetDelay is how long a self-serve 2FA reset waits before
+	// it takes effect
+	TwoFactorResetDelay time.Duration
+	// TwoFactorResetCancelURL is the page that cancel links in emails
+	// point to
+	TwoFactorResetCancelURL string
+
+	// SSO signs members of organizations with an identity provider in
+	// through it. SSOLoginTTL is how long they have to do so.
+	SSO         *sso.Client
+	SSOLoginTTL time.Duration
+
+	// Social signs users in with their Google or GitHub account
+	Social *social.Verifier
+
+	// LoginMethods is the deployment's login chain, the methods Login
+	// checks credentials with in order. Empty tries the password alone.
+	LoginMethods []string
+	// LDAP checks passwords for the ldap login method, and OIDCLogin is
+	// the provider SSO sends them to for the oidc method. Nil disables
+	// the method.
+	LDAP      *ldapauth.Client
+	OIDCLogin *sso.Connection
+	// Passkeys verifies passkey logins. Nil disables the passkey method.
+	Passkeys *passkeys.RelyingParty
+
+	// DeletionGracePeriod is how long RestoreProfile can bring a deleted
+	// account back. Zero when it can't.
+	DeletionGracePeriod time.Duration
+
+	// UserIDs creates the IDs of new accounts. Nil selects ObjectIDs.
+	UserIDs models.IDGenerator
+
+	// Alerts counts failed logins, lockouts and registrations against the
+	// alert rules. Nil disables alerting.
+	Alerts *alerts.Manager
+}
+
 type AuthService struct {
 	pb.UnimplementedAuthServiceServer
 	db          *database.Database
-	jwtService  *auth.JWTService
+	users       database.UserRepository
+	sessions    session.Manager
+	authorizer  authz.Authorizer
 	rateLimiter *utils.RateLimiter
+	sender      notifications.Sender
+	config      AuthConfig
+	loginChain  *authn.Chain
 }
 
-func NewAuthService(db *database.Database, jwtService *auth.JWTService) *AuthService {
-	return &AuthService{
+func NewAuthService(db *database.Database, sessions session.Manager, authorizer authz.Authorizer, sender notifications.Sender, config AuthConfig) *AuthService {
+	if config.UserIDs == nil {
+		config.UserIDs = models.ObjectIDGenerator{}
+	}
+	if config.PasswordPolicy == (utils.PasswordPolicy{}) {
+		config.PasswordPolicy = utils.DefaultPasswordPolicy
+	}
+	if len(config.LoginMethods) == 0 {
+		config.LoginMethods = []string{authn.MethodPassword}
+	}
+	repos := db.Repositories()
+	s := &AuthService{
 		db:          db,
-		jwtService:  jwtService,
-		rateLimiter: utils.NewRateLimiter(db),
+		users:       repos.Users,
+		sessions:    sessions,
+		authorizer:  authorizer,
+		rateLimiter: utils.NewRateLimiter(repos.Attempts, config.MaxFailedLogins, config.LoginRateLimitWindow),
+		sender:      sender,
+		config:      config,
 	}
+	s.loginChain = newLoginChain(s)
+	return s
 }
 
 func (s *AuthService) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
 	// Get client IP for rate limiting
-	clientIP := s.getClientIP(ctx)
+	clientIP := requestClientIP(ctx)
 
 	// Validate input
 	if err := utils.ValidateEmail(req.Email); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
 	}
 
-	if req.Password == "" {
+	// A password, emailed code or passkey assertion, depending on the
+	// account's login chain
+	if req.Password == "" && req.OneTimeCode == "" && req.PasskeyAssertion == "" {
 		return nil, status.Errorf(codes.InvalidArgument, "password is required")
 	}
 
@@ -53,31 +186,58 @@ func (s *AuthService) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Logi
 		return nil, status.Errorf(codes.Internal, "failed to check rate limit")
 	}
 	if !allowed {
-		return nil, status.Errorf(codes.ResourceExhausted, "too many login attempts, please try again later")
+		retryAfter, err := s.rateLimiter.RetryAfter(ctx, req.Email, clientIP)
+		if err != nil {
+			log.Printf("Failed to compute login retry delay: %v", err)
+		}
+		return nil, apierrors.RateLimited("too many login attempts, please try again later", retryAfter)
 	}
 
 	// Find user by email
-	var user models.User
-	err = s.db.Users.FindOne(ctx, bson.M{
-		"email":      req.Email,
-		"is_deleted": false,
-	}).Decode(&user)
-
+	user, err := s.users.FindByEmail(ctx, req.Email)
 	if err != nil {
 		// Record failed attempt
 		s.rateLimiter.RecordLoginAttempt(ctx, req.Email, clientIP, false)
 
-		if err == mongo.ErrNoDocuments {
+		if err == database.ErrNotFound {
+			s.config.Alerts.Record(alerts.EventLoginFailed)
 			return nil, status.Errorf(codes.NotFound, "invalid email or password")
 		}
 		return nil, status.Errorf(codes.Internal, "failed to find user")
 	}
 
-	// Verify password
-	if !utils.CheckPasswordHash(req.Password, user.Password) {
+	// Refuse locked accounts before checking the credentials
+	if lock := user.ActiveLock(time.Now()); lock != nil {
+		return nil, lockError(lock)
+	}
+
+	methods, err := s.loginMethods(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve login methods")
+	}
+	result, err := s.loginChain.Run(ctx, methods, user, authn.Credentials{
+		Email:            req.Email,
+		Password:         req.Password,
+		OneTimeCode:      req.OneTimeCode,
+		PasskeyAssertion: req.PasskeyAssertion,
+	})
+	switch {
+	case errors.Is(err, authn.ErrInvalidCredentials):
 		// Record failed attempt
 		s.rateLimiter.RecordLoginAttempt(ctx, req.Email, clientIP, false)
-		return nil, status.Errorf(codes.Unauthenticated, "invalid email or password")
+		s.recordFailedLogin(ctx, user)
+		s.config.Alerts.Record(alerts.EventLoginFailed)
+		if req.Password != "" {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid email or password")
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "invalid email or credentials")
+	case errors.Is(err, authn.ErrNoMethod):
+		return nil, status.Errorf(codes.FailedPrecondition, "this login method is not enabled for the account")
+	case errors.Is(err, authn.ErrUnavailable):
+		return nil, status.Errorf(codes.Unavailable, "login is unavailable, please try again later")
+	case err != nil:
+		log.Printf("Failed to check credentials of user %s: %v", user.ID.String(), err)
+		return nil, status.Errorf(codes.Internal, "failed to check credentials")
 	}
 
 	// Check if user is active
@@ -85,31 +245,123 @@ func (s *AuthService) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Logi
 		return nil, status.Errorf(codes.PermissionDenied, "account is deactivated/deleted")
 	}
 
+	if err := s.requireNoEnforcedSSO(ctx, user); err != nil {
+		return nil, err
+	}
+
+	userAgent := requestUserAgent(ctx)
+
+	// Accounts with 2FA also need a code, unless the login method proved
+	// a second factor itself. Wrong codes count towards the protective
+	// lock like wrong passwords, so failures are only cleared once both
+	// factors pass.
+	twoFactorUsed := result.MultiFactor
+	if user.TwoFactor != nil && !result.MultiFactor {
+		if req.TwoFactorCode == "" {
+			return &pb.LoginResponse{
+				TwoFactorRequired: true,
+				Message:           "Enter the code from your authenticator app",
+			}, nil
+		}
+		_, err := verifySecondFactor(ctx, s.db, s.users, s.config.TwoFactorCipher, user, req.TwoFactorCode, clientIP, userAgent)
+		if err == errInvalidTwoFactorCode {
+			s.rateLimiter.RecordLoginAttempt(ctx, req.Email, clientIP, false)
+			s.recordFailedLogin(ctx, user)
+			s.config.Alerts.Record(alerts.EventLoginFailed)
+			return nil, status.Errorf(codes.Unauthenticated, "invalid two-factor code")
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to check two-factor code")
+		}
+		twoFactorUsed = true
+	}
+	s.clearFailedLogins(ctx, user)
+
+	// Hold logins from new devices until an existing device approves them
+	if s.config.RequireLoginApproval {
+		pending, err := s.requireLoginApproval(ctx, user, userAgent, clientIP)
+		if err != nil {
+			return nil, err
+		}
+		if pending != nil {
+			return &pb.LoginResponse{
+				ApprovalRequired: true,
+				PendingLoginId:   pending.ID.Hex(),
+				Message:          "Login from a new device requires approval",
+			}, nil
+		}
+	}
+
+	// Users who must set up 2FA only get a token for enrollment once their
+	// grace period is over
+	deadline, err := s.twoFactorEnrollmentDeadline(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check two-factor policy")
+	}
+	enrollmentRequired := deadline != nil && !time.Now().Before(*deadline)
+
 	// Generate JWT token
-	token, err := s.jwtService.GenerateToken(user.ID.Hex(), user.Email)
+	var token, refreshToken string
+	if enrollmentRequired {
+		token, _, err = s.sessions.GenerateScopedToken(user.ID.String(), user.Email, auth.ScopeTwoFactorEnrollment, result.Method)
+	} else {
+		token, refreshToken, err = s.sessions.GenerateToken(ctx, user.ID.String(), user.Email, session.Info{
+			UserAgent: userAgent,
+			IPAddress: clientIP,
+			Method:    result.Method,
+		})
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to generate token")
 	}
 
 	// Record successful attempt
 	s.rateLimiter.RecordLoginAttempt(ctx, req.Email, clientIP, true)
+	newDevice := s.recordDevice(ctx, user.ID, userAgent, clientIP)
+	if newDevice {
+		s.notifyNewDevice(ctx, user, userAgent, clientIP)
+	}
+	err = audit.Record(ctx, s.db, models.AuditLog{
+		Action:    audit.ActionLoginSucceeded,
+		ActorID:   &user.ID,
+		TargetID:  user.ID,
+		IPAddress: clientIP,
+		UserAgent: userAgent,
+		Details:   bson.M{"method": result.Method},
+	})
+	if err != nil {
+		log.Printf("Failed to audit login for user %s: %v", user.ID.String(), err)
+	}
 
 	// Convert user to protobuf
 	pbUser := &pb.User{
-		Id:        user.ID.Hex(),
-		Email:     user.Email,
-		Name:      user.Name,
-		CreatedAt: timestamppb.New(user.CreatedAt),
-		UpdatedAt: timestamppb.New(user.UpdatedAt),
-		IsActive:  user.IsActive,
-		IsDeleted: user.IsDeleted,
+		Id:          user.ID.String(),
+		Email:       user.Email,
+		Name:        user.Name,
+		CreatedAt:   timestamppb.New(user.CreatedAt),
+		UpdatedAt:   timestamppb.New(user.UpdatedAt),
+		IsActive:    user.IsActive,
+		IsDeleted:   user.IsDeleted,
+		ExternalIds: user.ExternalIDMap(),
 	}
 
-	return &pb.LoginResponse{
-		Token:   token,
-		User:    pbUser,
-		Message: "Login success",
-	}, nil
+	response := &pb.LoginResponse{
+		Token:           token,
+		RefreshToken:    refreshToken,
+		User:            pbUser,
+		Message:         "Login success",
+		SecurityContext: s.loginSecurityContext(user, newDevice, twoFactorUsed, enrollmentRequired, time.Now()),
+		NewDevice:       newDevice,
+	}
+	response.SecurityContext.LoginMethod = result.Method
+	if deadline != nil {
+		response.TwoFactorEnrollmentDeadline = timestamppb.New(*deadline)
+	}
+	if enrollmentRequired {
+		response.TwoFactorEnrollmentRequired = true
+		response.Message = "Set up two-factor authentication to continue"
+	}
+	return response, nil
 }
 
 func (s *AuthService) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
@@ -117,23 +369,77 @@ func (s *AuthService) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.Lo
 		return nil, status.Errorf(codes.InvalidArgument, "token is required")
 	}
 
-	// Extract user ID from token
-	userID, err := s.jwtService.ExtractUserIDFromToken(req.Token)
+	// Extract user ID from token. Users who were only let in to set up 2FA
+	// can sign out too.
+	claims, err := s.authorizer.ValidateScopedToken(req.Token, auth.ScopeTwoFactorEnrollment)
 	if err != nil {
 		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
 	}
+	userID := claims.UserID
 
 	// Invalidate the token
-	err = s.jwtService.InvalidateToken(req.Token, userID)
+	err = s.sessions.InvalidateToken(req.Token, userID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to invalidate token")
 	}
 
+	if parsedUserID, err := models.ParseID(userID); err == nil {
+		err = audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionLoggedOut,
+			ActorID:   &parsedUserID,
+			TargetID:  parsedUserID,
+			IPAddress: requestClientIP(ctx),
+			UserAgent: requestUserAgent(ctx),
+		})
+		if err != nil {
+			log.Printf("Failed to audit logout for user %s: %v", userID, err)
+		}
+	}
+
 	return &pb.LogoutResponse{
 		Message: "Logout successful",
 	}, nil
 }
 
+// LogoutAllDevices signs the user out everywhere. Every token issued to
+// them so far stops working, including the one passed in.
+func (s *AuthService) LogoutAllDevices(ctx context.Context, req *pb.LogoutAllDevicesRequest) (*pb.LogoutAllDevicesResponse, error) {
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	userID, err := s.authorizer.ExtractUserIDFromToken(req.Token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+	parsedUserID, err := models.ParseID(userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.sessions.InvalidateAllTokens(ctx, userID); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionSessionsRevoked,
+			ActorID:   &parsedUserID,
+			TargetID:  parsedUserID,
+			IPAddress: requestClientIP(ctx),
+			UserAgent: requestUserAgent(ctx),
+			Details:   bson.M{"reason": "logout_all_devices"},
+		})
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to log out of all devices")
+	}
+
+	return &pb.LogoutAllDevicesResponse{
+		Message: "Logged out of all devices",
+	}, nil
+}
+
 func (s *AuthService) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
 	// Validate input
 	req.Email = utils.SanitizeString(req.Email)
@@ -143,8 +449,8 @@ func (s *AuthService) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
 	}
 
-	if err := utils.ValidatePassword(req.Password); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	if err := s.config.PasswordPolicy.Validate(req.Password); err != nil {
+		return nil, passwordError(err)
 	}
 
 	if err := utils.ValidateName(req.Name, "name"); err != nil {
@@ -152,13 +458,13 @@ func (s *AuthService) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 	}
 
 	// Check if user already exists
-	var existingUser models.User
-	err := s.db.Users.FindOne(ctx, bson.M{"email": req.Email}).Decode(&existingUser)
-	if err == nil {
-		return nil, status.Errorf(codes.AlreadyExists, "email already exists")
-	} else if err != mongo.ErrNoDocuments {
+	taken, err := s.users.EmailTaken(ctx, req.Email, "")
+	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to check existing user")
 	}
+	if taken {
+		return nil, apierrors.EmailTaken("email already exists")
+	}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
@@ -166,9 +472,15 @@ func (s *AuthService) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 		return nil, status.Errorf(codes.Internal, "failed to hash password")
 	}
 
+	userID, err := s.config.UserIDs.NewID()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate user ID")
+	}
+
 	// Create new user
 	now := time.Now()
 	user := models.User{
+		ID:        userID,
 		Email:     req.Email,
 		Password:  hashedPassword,
 		Name:      req.Name,
@@ -178,16 +490,38 @@ func (s *AuthService) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 		IsDeleted: false,
 	}
 
-	// Insert user
-	result, err := s.db.Users.InsertOne(ctx, user)
+	// Insert user together with its registration event
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.users.Insert(ctx, user); err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypeRegistered); err != nil {
+			return err
+		}
+		err := audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionRegistered,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: requestClientIP(ctx),
+			UserAgent: requestUserAgent(ctx),
+		})
+		if err != nil {
+			return err
+		}
+
+		return events.Enqueue(ctx, s.db, events.TypeUserRegistered, bson.M{
+			"user_id": user.ID.String(),
+			"email":   user.Email,
+			"name":    user.Name,
+		})
+	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create user")
 	}
+	s.config.Alerts.Record(alerts.EventUserRegistered)
 
-	// Set the user ID from the insert result
-	user.ID = result.InsertedID.(primitive.ObjectID)
 	pbUser := &pb.User{
-		Id:        user.ID.Hex(),
+		Id:        user.ID.String(),
 		Email:     user.Email,
 		Name:      user.Name,
 		CreatedAt: timestamppb.New(user.CreatedAt),
@@ -201,7 +535,41 @@ func (s *AuthService) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 	}, nil
 }
 
-func (s *AuthService) getClientIP(ctx context.Context) string {
+// passwordError converts a password validation error to a gRPC error. A
+// weak password lists the requirements it fails in the error details.
+func passwordError(err error) error {
+	var validationErr utils.ValidationError
+	if errors.As(err, &validationErr) && len(validationErr.Requirements) > 0 {
+		return apierrors.WeakPassword(validationErr.Field, validationErr.Error(), validationErr.Requirements)
+	}
+	return status.Errorf(codes.InvalidArgument, "%s", err.Error())
+}
+
+// rehashPassword replaces the user's password hash with a bcrypt hash. The
+// old hash keeps working if this fails.
+func (s *AuthService) rehashPassword(ctx context.Context, user *models.User, password string) {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		log.Printf("Failed to rehash password for user %s: %v", user.ID.String(), err)
+		return
+	}
+
+	// Skip the update if the password changed since it was checked
+	err = s.users.RehashPassword(ctx, user.ID, user.Password, hashedPassword)
+	if err == mongo.ErrNoDocuments {
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to rehash password for user %s: %v", user.ID.String(), err)
+		return
+	}
+	user.Password = hashedPassword
+	recordUserEvent(ctx, s.db, user.ID, eventsource.TypePasswordRehashed)
+}
+
+// requestClientIP returns the address of the client that made the request,
+// as reported by the proxy in front of the server
+func requestClientIP(ctx context.Context) string {
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
 		if xRealIP := md.Get("x-real-ip"); len(xRealIP) > 0 {
 			return xRealIP[0]
@@ -212,3 +580,24 @@ func (s *AuthService) getClientIP(ctx context.Context) string {
 	}
 	return "unknown"
 }
+
+// requestUserAgent returns the user agent of the client that made the
+// request
+func requestUserAgent(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		// The REST gateway's own user agent replaces its client's
+		if userAgent := md.Get("grpcgateway-user-agent"); len(userAgent) > 0 {
+			return userAgent[0]
+		}
+		if userAgent := md.Get("user-agent"); len(userAgent) > 0 {
+			return userAgent[0]
+		}
+	}
+	return "unknown"
+}
+
+// CompactLoginAttempts deletes the login attempts that no longer count
+// against the rate limit, and returns how many
+func (s *AuthService) CompactLoginAttempts(ctx context.Context) (int, error) {
+	return s.rateLimiter.Compact(ctx)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/authn"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/social"
+	"user-management/utils"
+)
+
+// Auth methods GetAuthOptions reports
+const (
+	authMethodPassword = "password"
+	authMethodPasskey  = "passkey"
+	authMethodOTP      = "otp"
+	authMethodTOTP     = "totp"
+	authMethodSSO      = "sso"
+)
+
+// Auth methods sessions record besides those of the login chain
+const (
+	authMethodSocial = "social"
+	// authMethodDevice sessions were approved from another session with
+	// the device flow
+	authMethodDevice = "device"
+)
+
+// GetAuthOptions tells login UIs which methods to offer before the user
+// signs in. The answer depends only on the deployment and organization, never
+// on whether an account exists for the email, so it can't be used to probe
+// accounts. An email whose domain an organization owns is sent to the
+// organization's identity provider.
+func (s *AuthService) GetAuthOptions(ctx context.Context, req *pb.GetAuthOptionsRequest) (*pb.GetAuthOptionsResponse, error) {
+	req.Email = utils.SanitizeString(req.Email)
+	if req.Email != "" {
+		if err := utils.ValidateEmail(req.Email); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+		}
+	}
+
+	var org *models.Organization
+	if req.OrganizationId != "" {
+		orgID, err := primitive.ObjectIDFromHex(req.OrganizationId)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID format")
+		}
+
+		org = &models.Organization{}
+		err = s.db.Orgs.FindOne(ctx, bson.M{"_id": orgID}).Decode(org)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, status.Errorf(codes.NotFound, "organization not found")
+			}
+			return nil, status.Errorf(codes.Internal, "failed to retrieve organization")
+		}
+	} else if req.Email != "" {
+		var err error
+		org, err = s.ssoOrganization(ctx, req.Email)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to retrieve organization")
+		}
+	}
+
+	// Federated users go straight to their identity provider, which is
+	// responsible for second factors
+	if org != nil && org.SSO != nil {
+		redirectURL, err := s.startSSOLogin(ctx, org)
+		if err != nil {
+			log.Printf("Failed to start SSO login for organization %s: %v", org.ID.Hex(), err)
+			return nil, status.Errorf(codes.Unavailable, "identity provider is unavailable")
+		}
+
+		methods := []*pb.AuthMethod{{
+			Type:           authMethodSSO,
+			Required:       org.SSO.Enforced,
+			OrganizationId: org.ID.Hex(),
+			RedirectUrl:    redirectURL,
+		}}
+		if !org.SSO.Enforced {
+			methods = append(methods, &pb.AuthMethod{Type: authMethodPassword})
+		}
+		return &pb.GetAuthOptionsResponse{
+			Methods: methods,
+		}, nil
+	}
+
+	methods := s.loginChainOptions(org)
+	// Social providers are alternatives to the login chain
+	for _, provider := range []string{social.ProviderGoogle, social.ProviderGitHub} {
+		if s.config.Social.Enabled(provider) {
+			methods = append(methods, &pb.AuthMethod{Type: provider})
+		}
+	}
+	if len(methods) == 1 {
+		methods[0].Required = true
+	}
+	if s.config.RequireTwoFactor || (org != nil && org.Policy.RequireTwoFactor) {
+		methods = append(methods, &pb.AuthMethod{Type: authMethodTOTP, Required: true})
+	}
+
+	return &pb.GetAuthOptionsResponse{
+		Methods: methods,
+	}, nil
+}
+
+// loginChainOptions returns the methods of the login chain of org, or of the
+// deployment when org is nil or has none. The ldap and oidc methods take
+// the password too, so they are offered as password.
+func (s *AuthService) loginChainOptions(org *models.Organization) []*pb.AuthMethod {
+	chain := s.config.LoginMethods
+	if org != nil && len(org.Policy.LoginMethods) > 0 {
+		chain = org.Policy.LoginMethods
+	}
+
+	var methods []*pb.AuthMethod
+	offered := make(map[string]bool)
+	for _, method := range chain {
+		if !s.loginChain.Configured(method) {
+			continue
+		}
+		optionType := authMethodPassword
+		switch method {
+		case authn.MethodPasskey:
+			optionType = authMethodPasskey
+		case authn.MethodOTP:
+			optionType = authMethodOTP
+		}
+		if !offered[optionType] {
+			offered[optionType] = true
+			methods = append(methods, &pb.AuthMethod{Type: optionType})
+		}
+	}
+	return methods
+}
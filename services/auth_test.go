@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/authn"
+	"user-management/database"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+const testPassword = "correct-horse-battery-1"
+
+// newTestAuthService returns an AuthService that keeps its users and
+// login attempts in repos
+func newTestAuthService(repos database.Repositories, config AuthConfig) *AuthService {
+	config.LoginMethods = []string{authn.MethodPassword}
+	s := &AuthService{
+		users:       repos.Users,
+		rateLimiter: utils.NewRateLimiter(repos.Attempts, config.MaxFailedLogins, config.LoginRateLimitWindow),
+		config:      config,
+	}
+	s.loginChain = newLoginChain(s)
+	return s
+}
+
+// insertTestUser stores user with the password testPassword
+func insertTestUser(t *testing.T, repos database.Repositories, user models.User) models.User {
+	t.Helper()
+	hash, err := utils.HashPassword(testPassword)
+	if err != nil {
+		t.Fatalf("HashPassword() = %v", err)
+	}
+	if user.ID.IsZero() {
+		user.ID = models.ID(primitive.NewObjectID().Hex())
+	}
+	user.Password = hash
+	if err := repos.Users.Insert(context.Background(), user); err != nil {
+		t.Fatalf("Insert() = %v", err)
+	}
+	return user
+}
+
+func TestLoginFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     models.User
+		email    string
+		password string
+		wantCode codes.Code
+		// wantFailures is the account's failed login count afterwards
+		wantFailures int
+	}{
+		{
+			name:     "unknown email",
+			user:     models.User{IsActive: true},
+			email:    "john@example.com",
+			password: testPassword,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:         "wrong password",
+			user:         models.User{IsActive: true},
+			email:        "jane@example.com",
+			password:     "wrong-password-1",
+			wantCode:     codes.Unauthenticated,
+			wantFailures: 1,
+		},
+		{
+			name: "locked by an administrator",
+			user: models.User{IsActive: true, Lock: &models.AccountLock{
+				Kind:     models.LockKindAdmin,
+				LockedAt: time.Now(),
+			}},
+			email:    "jane@example.com",
+			password: testPassword,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "deactivated account",
+			user:     models.User{IsActive: false},
+			email:    "jane@example.com",
+			password: testPassword,
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := database.NewMemoryRepositories()
+			s := newTestAuthService(repos, AuthConfig{
+				MaxFailedLogins:      5,
+				LoginRateLimitWindow: time.Minute,
+				LockoutThreshold:     5,
+			})
+			tt.user.Email = "jane@example.com"
+			user := insertTestUser(t, repos, tt.user)
+
+			_, err := s.Login(ctx, &pb.LoginRequest{Email: tt.email, Password: tt.password})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Login() = %v, want %v", err, tt.wantCode)
+			}
+
+			stored, err := repos.Users.FindByID(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("FindByID() = %v", err)
+			}
+			if stored.FailedLoginCount != tt.wantFailures {
+				t.Errorf("failed logins = %d, want %d", stored.FailedLoginCount, tt.wantFailures)
+			}
+		})
+	}
+}
+
+// Logins for an email are refused once it had too many failed attempts
+func TestLoginRateLimited(t *testing.T) {
+	ctx := context.Background()
+	repos := database.NewMemoryRepositories()
+	s := newTestAuthService(repos, AuthConfig{
+		MaxFailedLogins:      2,
+		LoginRateLimitWindow: time.Minute,
+	})
+	insertTestUser(t, repos, models.User{Email: "jane@example.com", IsActive: true})
+
+	req := &pb.LoginRequest{Email: "jane@example.com", Password: "wrong-password-1"}
+	for i := 0; i < 2; i++ {
+		if _, err := s.Login(ctx, req); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("Login() #%d = %v, want %v", i+1, err, codes.Unauthenticated)
+		}
+	}
+
+	// Even the right password is refused until the window passes
+	req.Password = testPassword
+	if _, err := s.Login(ctx, req); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Login() after too many failures = %v, want %v", err, codes.ResourceExhausted)
+	}
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/audit"
+	"user-management/database"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// ListCredentials returns the caller's passkeys and trusted devices, oldest
+// first
+func (s *UserService) ListCredentials(ctx context.Context, req *pb.ListCredentialsRequest) (*pb.ListCredentialsResponse, error) {
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var passkeys []models.Passkey
+	cursor, err := s.db.Passkeys.Find(ctx, bson.M{"user_id": user.ID})
+	if err == nil {
+		err = cursor.All(ctx, &passkeys)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve passkeys")
+	}
+
+	var devices []models.Device
+	cursor, err = s.db.Devices.Find(ctx, bson.M{"user_id": user.ID})
+	if err == nil {
+		err = cursor.All(ctx, &devices)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve devices")
+	}
+
+	credentials := make([]*pb.Credential, 0, len(passkeys)+len(devices))
+	for _, passkey := range passkeys {
+		credentials = append(credentials, passkeyCredentialToProto(passkey))
+	}
+	for _, device := range devices {
+		credentials = append(credentials, deviceCredentialToProto(device))
+	}
+	sort.SliceStable(credentials, func(i, j int) bool {
+		return credentials[i].CreatedAt.AsTime().Before(credentials[j].CreatedAt.AsTime())
+	})
+
+	return &pb.ListCredentialsResponse{
+		Credentials: credentials,
+	}, nil
+}
+
+// RenameCredential changes the name of one of the caller's passkeys or
+// trusted devices
+func (s *UserService) RenameCredential(ctx context.Context, req *pb.RenameCredentialRequest) (*pb.RenameCredentialResponse, error) {
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := s.credentialCollection(req.Type)
+	if err != nil {
+		return nil, err
+	}
+	id, err := primitive.ObjectIDFromHex(req.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid credential ID format")
+	}
+
+	name := utils.SanitizeString(req.Name)
+	if err := utils.ValidateCredentialName(name); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	var credential *pb.Credential
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		result := collection.FindOneAndUpdate(ctx, bson.M{
+			"_id":     id,
+			"user_id": user.ID,
+		}, bson.M{
+			"$set": bson.M{"name": name},
+		}, options.FindOneAndUpdate().SetReturnDocument(options.After))
+
+		switch req.Type {
+		case models.CredentialTypePasskey:
+			var passkey models.Passkey
+			if err := result.Decode(&passkey); err != nil {
+				return err
+			}
+			credential = passkeyCredentialToProto(passkey)
+		default:
+			var device models.Device
+			if err := result.Decode(&device); err != nil {
+				return err
+			}
+			credential = deviceCredentialToProto(device)
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionCredentialRenamed,
+			ActorID:  &user.ID,
+			TargetID: user.ID,
+			Details: bson.M{
+				"credential_id":   id.Hex(),
+				"credential_type": req.Type,
+				"name":            name,
+			},
+		})
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "credential not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to rename credential")
+	}
+
+	return &pb.RenameCredentialResponse{
+		Credential: credential,
+	}, nil
+}
+
+// DeleteCredential removes one of the caller's passkeys or trusted devices.
+// A deleted passkey can no longer be used, and the next login from a
+// deleted device needs approval again.
+func (s *UserService) DeleteCredential(ctx context.Context, req *pb.DeleteCredentialRequest) (*pb.DeleteCredentialResponse, error) {
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := s.credentialCollection(req.Type)
+	if err != nil {
+		return nil, err
+	}
+	id, err := primitive.ObjectIDFromHex(req.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid credential ID format")
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		result, err := collection.DeleteOne(ctx, bson.M{
+			"_id":     id,
+			"user_id": user.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if result.DeletedCount == 0 {
+			return mongo.ErrNoDocuments
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionCredentialDeleted,
+			ActorID:  &user.ID,
+			TargetID: user.ID,
+			Details: bson.M{
+				"credential_id":   id.Hex(),
+				"credential_type": req.Type,
+			},
+		})
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "credential not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete credential")
+	}
+
+	return &pb.DeleteCredentialResponse{
+		Message: "Credential deleted successfully",
+	}, nil
+}
+
+// ListKnownDevices returns the devices the caller has signed in from, most
+// recently seen first, with the one the request came from marked current
+func (s *UserService) ListKnownDevices(ctx context.Context, req *pb.ListKnownDevicesRequest) (*pb.ListKnownDevicesResponse, error) {
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set default pagination values
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	filter := bson.M{"user_id": user.ID}
+	totalCount, err := s.db.Devices.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count devices")
+	}
+
+	var devices []models.Device
+	cursor, err := s.db.Devices.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "last_seen_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page-1)*pageSize)).
+		SetLimit(int64(pageSize)))
+	if err == nil {
+		err = cursor.All(ctx, &devices)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve devices")
+	}
+
+	current := utils.DeviceFingerprint(requestUserAgent(ctx), requestClientIP(ctx))
+	response := &pb.ListKnownDevicesResponse{
+		Devices:    make([]*pb.KnownDevice, 0, len(devices)),
+		TotalCount: int32(totalCount),
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for _, device := range devices {
+		response.Devices = append(response.Devices, &pb.KnownDevice{
+			Id:          device.ID.Hex(),
+			Name:        device.Name,
+			UserAgent:   device.UserAgent,
+			IpAddress:   device.IPAddress,
+			FirstSeenAt: timestamppb.New(device.FirstSeenAt),
+			LastSeenAt:  timestamppb.New(device.LastSeenAt),
+			Current:     device.Fingerprint == current,
+		})
+	}
+	return response, nil
+}
+
+// credentialCollection returns the collection credentials of a type are
+// stored in
+func (s *UserService) credentialCollection(credentialType string) (*database.Collection, error) {
+	switch credentialType {
+	case models.CredentialTypePasskey:
+		return s.db.Passkeys, nil
+	case models.CredentialTypeTrustedDevice:
+		return s.db.Devices, nil
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "type must be %s or %s", models.CredentialTypePasskey, models.CredentialTypeTrustedDevice)
+	}
+}
+
+func passkeyCredentialToProto(passkey models.Passkey) *pb.Credential {
+	credential := &pb.Credential{
+		Id:        passkey.ID.Hex(),
+		Type:      models.CredentialTypePasskey,
+		Name:      passkey.Name,
+		CreatedAt: timestamppb.New(passkey.CreatedAt),
+		Aaguid:    passkey.AAGUID,
+	}
+	if passkey.LastUsedAt != nil {
+		credential.LastUsedAt = timestamppb.New(*passkey.LastUsedAt)
+	}
+	return credential
+}
+
+// deviceCredentialToProto describes a trusted device. Devices are trusted
+// from their first login and used on every login after.
+func deviceCredentialToProto(device models.Device) *pb.Credential {
+	return &pb.Credential{
+		Id:         device.ID.Hex(),
+		Type:       models.CredentialTypeTrustedDevice,
+		Name:       device.Name,
+		CreatedAt:  timestamppb.New(device.FirstSeenAt),
+		LastUsedAt: timestamppb.New(device.LastSeenAt),
+		UserAgent:  device.UserAgent,
+		IpAddress:  device.IPAddress,
+	}
+}
@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/audit"
+	"user-management/database"
+	"user-management/events"
+	"user-management/eventsource"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+)
+
+const (
+	// deprovisioningLease is how long a server works on a job without
+	// progress before another may take it over
+	deprovisioningLease = 5 * time.Minute
+	// maxDeprovisionSubjects bounds the subjects of one request
+	maxDeprovisionSubjects = 1000
+	// minDeprovisionInactivity keeps a short inactive_for from
+	// deactivating members who merely haven't signed in today
+	minDeprovisionInactivity = 24 * time.Hour
+	// maxReportEmails bounds the addresses listed in the summary
+	maxReportEmails = 100
+)
+
+// deprovisioningReasons describe each reason in the summary to org admins
+var deprovisioningReasons = map[string]string{
+	models.DeprovisionReasonSSODisconnected: "the organization disconnected its identity provider",
+	models.DeprovisionReasonDeleted:         "the identity provider deleted their identities",
+	models.DeprovisionReasonInactive:        "they stopped signing in through the identity provider",
+}
+
+// DeprovisionOrganizationMembers queues a job that deactivates the members
+// whose identities the organization's provider deleted, or that stopped
+// signing in, and revokes their sessions. With an undo window the job is
+// queued once it has passed.
+func (s *AdminService) DeprovisionOrganizationMembers(ctx context.Context, req *pb.DeprovisionOrganizationMembersRequest) (*pb.DeprovisionOrganizationMembersResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgObjectID, err := primitive.ObjectIDFromHex(req.OrgId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID format")
+	}
+
+	job := models.DeprovisioningJob{
+		OrgID:       orgObjectID,
+		RequestedBy: admin.ID,
+	}
+	switch {
+	case len(req.Subjects) > 0 && req.InactiveFor != nil:
+		return nil, status.Errorf(codes.InvalidArgument, "set subjects or inactive_for, not both")
+	case len(req.Subjects) > 0:
+		if len(req.Subjects) > maxDeprovisionSubjects {
+			return nil, status.Errorf(codes.InvalidArgument, "at most %d subjects are allowed", maxDeprovisionSubjects)
+		}
+		for _, subject := range req.Subjects {
+			if subject != "" && !containsString(job.Subjects, subject) {
+				job.Subjects = append(job.Subjects, subject)
+			}
+		}
+		if len(job.Subjects) == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "subjects must not be empty")
+		}
+		job.Reason = models.DeprovisionReasonDeleted
+	case req.InactiveFor != nil:
+		inactiveFor := req.InactiveFor.AsDuration()
+		if inactiveFor < minDeprovisionInactivity {
+			return nil, status.Errorf(codes.InvalidArgument, "inactive_for must be at least %s", minDeprovisionInactivity)
+		}
+		since := time.Now().Add(-inactiveFor)
+		job.InactiveSince = &since
+		job.Reason = models.DeprovisionReasonInactive
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "subjects or inactive_for is required")
+	}
+
+	var org models.Organization
+	err = s.db.Orgs.FindOne(ctx, bson.M{"_id": orgObjectID}).Decode(&org)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "organization not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find organization")
+	}
+	if org.SSO == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "organization has no SSO connection")
+	}
+	job.Issuer = org.SSO.Issuer
+
+	if s.undoWindow() > 0 {
+		op := models.PendingOperation{
+			Kind:           models.OperationDeprovision,
+			Deprovisioning: &job,
+			RequestedBy:    admin.ID,
+		}
+		if err := s.queueOperation(ctx, &op); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to queue deprovisioning")
+		}
+		log.Printf("Admin %s queued deprovisioning operation %s for organization %s (%s)", admin.Email, op.ID.Hex(), req.OrgId, job.Reason)
+		return &pb.DeprovisionOrganizationMembersResponse{
+			Operation: pendingOperationToProto(&op),
+		}, nil
+	}
+
+	if err := s.queueDeprovisioning(ctx, &job); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to queue deprovisioning")
+	}
+
+	log.Printf("Admin %s queued deprovisioning job %s for organization %s (%s)", admin.Email, job.ID.Hex(), req.OrgId, job.Reason)
+
+	return &pb.DeprovisionOrganizationMembersResponse{
+		Job: deprovisioningJobToProto(&job),
+	}, nil
+}
+
+// GetDeprovisioningJob returns a job with its report so far
+func (s *AdminService) GetDeprovisioningJob(ctx context.Context, req *pb.GetDeprovisioningJobRequest) (*pb.GetDeprovisioningJobResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	jobID, err := primitive.ObjectIDFromHex(req.JobId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid job ID format")
+	}
+
+	var job models.DeprovisioningJob
+	err = s.db.Deprovisioning.FindOne(ctx, bson.M{"_id": jobID}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "deprovisioning job not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find deprovisioning job")
+	}
+
+	return &pb.GetDeprovisioningJobResponse{
+		Job: deprovisioningJobToProto(&job),
+	}, nil
+}
+
+// queueDeprovisioning stores a new pending job for RunDeprovisioning
+func (s *AdminService) queueDeprovisioning(ctx context.Context, job *models.DeprovisioningJob) error {
+	now := time.Now()
+	job.Status = models.DeprovisioningPending
+	job.LockedUntil = now
+	job.CreatedAt = now
+
+	result, err := s.db.Deprovisioning.InsertOne(ctx, job)
+	if err != nil {
+		return err
+	}
+	job.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// RunDeprovisioning works through queued deprovisioning jobs every interval
+// until ctx is canceled. A job interrupted by a failure or a restart
+// continues where it stopped once its lease expires.
+func (s *AdminService) RunDeprovisioning(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			job, err := s.claimDeprovisioningJob(ctx)
+			if err == mongo.ErrNoDocuments {
+				break
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Failed to claim a deprovisioning job: %v", err)
+				}
+				break
+			}
+
+			if err := s.runDeprovisioningJob(ctx, job); err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Deprovisioning job %s failed, retrying once its lease expires: %v", job.ID.Hex(), err)
+				}
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimDeprovisioningJob leases the oldest pending job no server is
+// working on
+func (s *AdminService) claimDeprovisioningJob(ctx context.Context) (*models.DeprovisioningJob, error) {
+	now := time.Now()
+
+	var job models.DeprovisioningJob
+	err := s.db.Deprovisioning.FindOneAndUpdate(ctx, bson.M{
+		"status":       models.DeprovisioningPending,
+		"locked_until": bson.M{"$lte": now},
+	}, bson.M{
+		"$set": bson.M{"locked_until": now.Add(deprovisioningLease)},
+	}, options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After),
+	).Decode(&job)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// runDeprovisioningJob deactivates every account the job matches, records
+// the report as it goes, and sends the summary to the org admins
+func (s *AdminService) runDeprovisioningJob(ctx context.Context, job *models.DeprovisioningJob) error {
+	err := s.users.EachToDeprovision(ctx, job, func(user models.User) error {
+		if reason := deprovisioningSkipReason(job, &user); reason != "" {
+			return s.recordDeprovisioningSkip(ctx, job, user.ID, reason)
+		}
+
+		err := s.deprovisionUser(ctx, job, &user)
+		if err == errUserNotFound {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("user %s: %v", user.ID.String(), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var done models.DeprovisioningJob
+	err = s.db.Deprovisioning.FindOneAndUpdate(ctx, bson.M{
+		"_id":    job.ID,
+		"status": models.DeprovisioningPending,
+	}, bson.M{
+		"$set": bson.M{
+			"status":       models.DeprovisioningDone,
+			"completed_at": now,
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&done)
+	if err == mongo.ErrNoDocuments {
+		// Another server finished the job after taking over the lease
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Deprovisioning job %s deactivated %d accounts of organization %s and left %d active",
+		done.ID.Hex(), done.Report.Deactivated, done.OrgID.Hex(), len(done.Report.Skipped))
+	s.notifyDeprovisioningReport(ctx, &done)
+	return nil
+}
+
+// deprovisioningSkipReason returns why the job leaves user active, or "".
+// Admins are never deactivated by a job, and org admins keep their access
+// when the provider is disconnected so they can act on the report.
+func deprovisioningSkipReason(job *models.DeprovisioningJob, user *models.User) string {
+	if user.HasRole(models.RoleAdmin) {
+		return models.DeprovisionSkipAdmin
+	}
+	if job.Reason == models.DeprovisionReasonSSODisconnected && user.OrgRole == models.OrgRoleAdmin {
+		return models.DeprovisionSkipOrgAdmin
+	}
+	return ""
+}
+
+// recordDeprovisioningSkip adds an account left active to the report
+func (s *AdminService) recordDeprovisioningSkip(ctx context.Context, job *models.DeprovisioningJob, userID models.ID, reason string) error {
+	_, err := s.db.Deprovisioning.UpdateOne(ctx, bson.M{"_id": job.ID}, bson.M{
+		"$addToSet": bson.M{"report.skipped": models.DeprovisioningSkip{UserID: userID, Reason: reason}},
+		"$set":      bson.M{"locked_until": time.Now().Add(deprovisioningLease)},
+	})
+	return err
+}
+
+// deprovisionUser deactivates the account, signs it out everywhere and adds
+// it to the report. It returns errUserNotFound when the account was
+// deactivated since it was read.
+func (s *AdminService) deprovisionUser(ctx context.Context, job *models.DeprovisioningJob, user *models.User) error {
+	return s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		err := s.users.DeactivateActive(ctx, user.ID, now)
+		if err == database.ErrNotFound {
+			return errUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		revoked, err := s.sessions.RevokeAllSessions(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypeDeprovisioned); err != nil {
+			return err
+		}
+		if err := events.Enqueue(ctx, s.db, events.TypeUserUpdated, bson.M{
+			"user_id": user.ID.String(),
+			"changes": bson.M{"is_active": false},
+		}); err != nil {
+			return err
+		}
+		if err := audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionUserDeprovisioned,
+			ActorID:  &job.RequestedBy,
+			TargetID: user.ID,
+			Details: bson.M{
+				"org_id":           job.OrgID.Hex(),
+				"job_id":           job.ID.Hex(),
+				"reason":           job.Reason,
+				"sessions_revoked": revoked,
+			},
+			CreatedAt: now,
+		}); err != nil {
+			return err
+		}
+
+		_, err = s.db.Deprovisioning.UpdateOne(ctx, bson.M{"_id": job.ID}, bson.M{
+			"$inc": bson.M{
+				"report.deactivated":      1,
+				"report.sessions_revoked": revoked,
+			},
+			"$push": bson.M{
+				"report.emails": bson.M{"$each": bson.A{user.Email}, "$slice": maxReportEmails},
+			},
+			"$set": bson.M{"locked_until": now.Add(deprovisioningLease)},
+		})
+		return err
+	})
+}
+
+// notifyDeprovisioningReport emails the summary of a finished job to the
+// active admins of its organization. Failures are logged, not returned.
+func (s *AdminService) notifyDeprovisioningReport(ctx context.Context, job *models.DeprovisioningJob) {
+	var org models.Organization
+	if err := s.db.Orgs.FindOne(ctx, bson.M{"_id": job.OrgID}).Decode(&org); err != nil {
+		log.Printf("Failed to find organization %s for the deprovisioning report: %v", job.OrgID.Hex(), err)
+		return
+	}
+
+	admins, err := s.users.ListOrgAdmins(ctx, job.OrgID)
+	if err != nil {
+		log.Printf("Failed to find admins of organization %s for the deprovisioning report: %v", job.OrgID.Hex(), err)
+		return
+	}
+
+	accounts := strings.Join(job.Report.Emails, "\n")
+	if more := job.Report.Deactivated - len(job.Report.Emails); more > 0 {
+		accounts += fmt.Sprintf("\nand %d more", more)
+	}
+	data := map[string]string{
+		"Organization":    org.Name,
+		"Reason":          deprovisioningReasons[job.Reason],
+		"Deactivated":     strconv.Itoa(job.Report.Deactivated),
+		"SessionsRevoked": strconv.Itoa(job.Report.SessionsRevoked),
+		"Skipped":         strconv.Itoa(len(job.Report.Skipped)),
+		"Accounts":        accounts,
+	}
+
+	for _, admin := range admins {
+		msg, err := notifications.Render(notifications.TemplateDeprovisioningReport, admin.Email, data)
+		if err == nil {
+			err = s.sender.Send(ctx, msg)
+		}
+		if err != nil {
+			log.Printf("Failed to send %s to %s: %v", notifications.TemplateDeprovisioningReport, admin.Email, err)
+		}
+	}
+}
+
+func deprovisioningJobToProto(job *models.DeprovisioningJob) *pb.DeprovisioningJob {
+	result := &pb.DeprovisioningJob{
+		Id:              job.ID.Hex(),
+		OrgId:           job.OrgID.Hex(),
+		Reason:          job.Reason,
+		Status:          job.Status,
+		CreatedAt:       timestamppb.New(job.CreatedAt),
+		Deactivated:     int32(job.Report.Deactivated),
+		SessionsRevoked: int32(job.Report.SessionsRevoked),
+	}
+	if job.CompletedAt != nil {
+		result.CompletedAt = timestamppb.New(*job.CompletedAt)
+	}
+	for _, skip := range job.Report.Skipped {
+		result.Skipped = append(result.Skipped, &pb.DeprovisioningSkip{
+			UserId: skip.UserID.String(),
+			Reason: skip.Reason,
+		})
+	}
+	return result
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/auth"
+	"user-management/authz"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/session"
+	"user-management/utils"
+)
+
+// maxUserCodeAttempts bounds retries when a generated user code collides
+// with one that is still outstanding
+const maxUserCodeAttempts = 5
+
+// Device access token error codes (RFC 8628 section 3.5)
+const (
+	deviceErrAuthorizationPending = "authorization_pending"
+	deviceErrSlowDown             = "slow_down"
+	deviceErrAccessDenied         = "access_denied"
+	deviceErrExpiredToken         = "expired_token"
+)
+
+// slowDownIncrement is added to the polling interval each time a client
+// polls too quickly
+const slowDownIncrement = 5 * time.Second
+
+func (s *AuthService) StartDeviceLogin(ctx context.Context, req *pb.StartDeviceLoginRequest) (*pb.StartDeviceLoginResponse, error) {
+	if !s.isDeviceClientAllowed(req.ClientId) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid client ID")
+	}
+
+	deviceCode, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate device code")
+	}
+
+	now := time.Now()
+	deviceLogin := models.DeviceLogin{
+		DeviceCodeHash: utils.HashToken(deviceCode),
+		ClientID:       req.ClientId,
+		Scope:          utils.SanitizeString(req.Scope),
+		Status:         models.DeviceLoginStatusPending,
+		UserAgent:      requestUserAgent(ctx),
+		IPAddress:      requestClientIP(ctx),
+		Interval:       s.config.DeviceLoginInterval,
+		ExpiresAt:      now.Add(s.config.DeviceLoginTTL),
+		CreatedAt:      now,
+	}
+
+	for attempt := 0; ; attempt++ {
+		deviceLogin.UserCode, err = utils.GenerateUserCode()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate user code")
+		}
+
+		_, err = s.db.DeviceLogins.InsertOne(ctx, deviceLogin)
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) || attempt+1 >= maxUserCodeAttempts {
+			return nil, status.Errorf(codes.Internal, "failed to start device login")
+		}
+	}
+
+	return &pb.StartDeviceLoginResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                deviceLogin.UserCode,
+		VerificationUri:         s.config.DeviceLoginURL,
+		VerificationUriComplete: fmt.Sprintf("%s?user_code=%s", s.config.DeviceLoginURL, url.QueryEscape(deviceLogin.UserCode)),
+		ExpiresIn:               int32(s.config.DeviceLoginTTL.Seconds()),
+		Interval:                int32(deviceLogin.Interval.Seconds()),
+	}, nil
+}
+
+func (s *AuthService) ApproveDeviceLogin(ctx context.Context, req *pb.ApproveDeviceLoginRequest) (*pb.ApproveDeviceLoginResponse, error) {
+	if req.UserCode == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user code is required")
+	}
+
+	if _, err := authz.RequireSession(ctx); err != nil {
+		return nil, err
+	}
+	userID, err := authz.CallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"status":      models.DeviceLoginStatusApproved,
+		"user_id":     userID,
+		"approved_at": now,
+	}
+	message := "Device login approved"
+	if req.Deny {
+		update = bson.M{"status": models.DeviceLoginStatusDenied}
+		message = "Device login denied"
+	}
+
+	var deviceLogin models.DeviceLogin
+	err = s.db.DeviceLogins.FindOneAndUpdate(ctx, bson.M{
+		"user_code":  utils.NormalizeUserCode(req.UserCode),
+		"status":     models.DeviceLoginStatusPending,
+		"expires_at": bson.M{"$gt": now},
+	}, bson.M{
+		"$set": update,
+	}).Decode(&deviceLogin)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "device login not found or expired")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to approve device login")
+	}
+
+	return &pb.ApproveDeviceLoginResponse{
+		Message:  message,
+		ClientId: deviceLogin.ClientID,
+		Scope:    deviceLogin.Scope,
+	}, nil
+}
+
+// PollDeviceLogin is the device access token request of RFC 8628. Pending,
+// throttled, denied and expired logins are reported in the error field so
+// clients can keep polling on a normal response.
+func (s *AuthService) PollDeviceLogin(ctx context.Context, req *pb.PollDeviceLoginRequest) (*pb.PollDeviceLoginResponse, error) {
+	if req.DeviceCode == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "device code is required")
+	}
+
+	var deviceLogin models.DeviceLogin
+	err := s.db.DeviceLogins.FindOne(ctx, bson.M{
+		"device_code_hash": utils.HashToken(req.DeviceCode),
+	}).Decode(&deviceLogin)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "invalid device code")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to check device login")
+	}
+
+	if deviceLogin.ClientID != req.ClientId {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid client ID")
+	}
+
+	now := time.Now()
+	if !now.Before(deviceLogin.ExpiresAt) {
+		return &pb.PollDeviceLoginResponse{
+			Error:   deviceErrExpiredToken,
+			Message: "Device login expired, start again",
+		}, nil
+	}
+
+	switch deviceLogin.Status {
+	case models.DeviceLoginStatusDenied:
+		return &pb.PollDeviceLoginResponse{
+			Error:   deviceErrAccessDenied,
+			Message: "Device login was denied",
+		}, nil
+
+	case models.DeviceLoginStatusPending:
+		return s.recordDevicePoll(ctx, &deviceLogin, now)
+
+	case models.DeviceLoginStatusApproved:
+		// Claim the approved login exactly once so the token is only issued
+		// to the first poll after approval
+		result, err := s.db.DeviceLogins.UpdateOne(ctx, bson.M{
+			"_id":    deviceLogin.ID,
+			"status": models.DeviceLoginStatusApproved,
+		}, bson.M{
+			"$set": bson.M{"status": models.DeviceLoginStatusCompleted},
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to complete device login")
+		}
+		if result.ModifiedCount == 0 {
+			return nil, status.Errorf(codes.NotFound, "invalid device code")
+		}
+
+	default:
+		// Already exchanged for a token
+		return nil, status.Errorf(codes.NotFound, "invalid device code")
+	}
+
+	// Load the approving user
+	user, err := s.users.FindByID(ctx, *deviceLogin.UserID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	if !user.IsActive {
+		return nil, status.Errorf(codes.PermissionDenied, "account is deactivated/deleted")
+	}
+
+	if lock := user.ActiveLock(time.Now()); lock != nil {
+		return nil, lockError(lock)
+	}
+
+	// Users who must set up 2FA only get a token for enrollment once their
+	// grace period is over, as with the other logins
+	deadline, err := s.twoFactorEnrollmentDeadline(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check two-factor policy")
+	}
+	enrollmentRequired := deadline != nil && !time.Now().Before(*deadline)
+
+	// Generate JWT token
+	var token, refreshToken string
+	if enrollmentRequired {
+		token, _, err = s.sessions.GenerateScopedToken(user.ID.String(), user.Email, auth.ScopeTwoFactorEnrollment, authMethodDevice)
+	} else {
+		token, refreshToken, err = s.sessions.GenerateToken(ctx, user.ID.String(), user.Email, session.Info{
+			UserAgent: deviceLogin.UserAgent,
+			IPAddress: deviceLogin.IPAddress,
+			Method:    authMethodDevice,
+		})
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate token")
+	}
+
+	// The user approved this device explicitly, so trust it from now on
+	s.recordDevice(ctx, user.ID, deviceLogin.UserAgent, deviceLogin.IPAddress)
+
+	pbUser := &pb.User{
+		Id:          user.ID.String(),
+		Email:       user.Email,
+		Name:        user.Name,
+		CreatedAt:   timestamppb.New(user.CreatedAt),
+		UpdatedAt:   timestamppb.New(user.UpdatedAt),
+		IsActive:    user.IsActive,
+		IsDeleted:   user.IsDeleted,
+		ExternalIds: user.ExternalIDMap(),
+	}
+
+	response := &pb.PollDeviceLoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		User:         pbUser,
+		Scope:        deviceLogin.Scope,
+		Message:      "Login success",
+	}
+	if deadline != nil {
+		response.TwoFactorEnrollmentDeadline = timestamppb.New(*deadline)
+	}
+	if enrollmentRequired {
+		response.TwoFactorEnrollmentRequired = true
+		response.Message = "Set up two-factor authentication to continue"
+	}
+	return response, nil
+}
+
+// recordDevicePoll answers a poll for a login that is still awaiting the
+// user, telling clients that poll faster than the interval to slow down
+func (s *AuthService) recordDevicePoll(ctx context.Context, deviceLogin *models.DeviceLogin, now time.Time) (*pb.PollDeviceLoginResponse, error) {
+	interval := deviceLogin.Interval
+	tooFast := deviceLogin.LastPolledAt != nil && now.Sub(*deviceLogin.LastPolledAt) < interval
+	if tooFast {
+		interval += slowDownIncrement
+	}
+
+	_, err := s.db.DeviceLogins.UpdateOne(ctx, bson.M{"_id": deviceLogin.ID}, bson.M{
+		"$set": bson.M{
+			"last_polled_at": now,
+			"interval":       interval,
+		},
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update device login")
+	}
+
+	if tooFast {
+		return &pb.PollDeviceLoginResponse{
+			Pending:  true,
+			Error:    deviceErrSlowDown,
+			Interval: int32(interval.Seconds()),
+			Message:  "Polling too frequently, slow down",
+		}, nil
+	}
+
+	return &pb.PollDeviceLoginResponse{
+		Pending:  true,
+		Error:    deviceErrAuthorizationPending,
+		Interval: int32(interval.Seconds()),
+		Message:  "Waiting for approval",
+	}, nil
+}
+
+// isDeviceClientAllowed checks the client ID against the configured list of
+// device clients. An empty list allows any client.
+func (s *AuthService) isDeviceClientAllowed(clientID string) bool {
+	if len(s.config.DeviceClientIDs) == 0 {
+		return true
+	}
+	for _, allowed := range s.config.DeviceClientIDs {
+		if clientID == allowed {
+			return true
+		}
+	}
+	return false
+}
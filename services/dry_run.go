@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"user-management/database"
+	pb "user-management/proto"
+)
+
+// dryRunSampleSize bounds the IDs a dry run lists per collection
+const dryRunSampleSize = 10
+
+// Operations of AffectedRecords
+const (
+	operationDelete = "delete"
+	operationUpdate = "update"
+)
+
+// collectionFilter is the documents of a collection a destructive call
+// changes
+type collectionFilter struct {
+	collection *database.Collection
+	filter     bson.M
+	operation  string
+}
+
+// affectedRecords reports what changing the documents of each filter would
+// affect, leaving out collections with none
+func affectedRecords(ctx context.Context, filters []collectionFilter) ([]*pb.AffectedRecords, error) {
+	var affected []*pb.AffectedRecords
+	for _, f := range filters {
+		count, err := f.collection.CountDocuments(ctx, f.filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %v", f.collection.Name(), err)
+		}
+		if count == 0 {
+			continue
+		}
+
+		cursor, err := f.collection.Find(ctx, f.filter, options.Find().
+			SetProjection(bson.M{"_id": 1}).
+			SetSort(bson.M{"_id": 1}).
+			SetLimit(dryRunSampleSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %v", f.collection.Name(), err)
+		}
+		var docs []bson.Raw
+		if err := cursor.All(ctx, &docs); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %v", f.collection.Name(), err)
+		}
+
+		records := &pb.AffectedRecords{
+			Collection: f.collection.Name(),
+			Operation:  f.operation,
+			Count:      count,
+		}
+		for _, doc := range docs {
+			records.SampleIds = append(records.SampleIds, documentID(doc))
+		}
+		affected = append(affected, records)
+	}
+	return affected, nil
+}
+
+// documentID formats the _id of doc, which is an ObjectID, a string or a
+// user ID
+func documentID(doc bson.Raw) string {
+	id := doc.Lookup("_id")
+	if oid, ok := id.ObjectIDOK(); ok {
+		return oid.Hex()
+	}
+	if s, ok := id.StringValueOK(); ok {
+		return s
+	}
+	return id.String()
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"user-management/models"
+	"user-management/utils"
+)
+
+const (
+	emailCodeDigits           = 6
+	emailChallengeTTL         = 10 * time.Minute
+	maxEmailChallengeAttempts = 5
+	// emailChallengeResendDelay stops an inbox from being flooded with codes
+	emailChallengeResendDelay = time.Minute
+)
+
+var (
+	errChallengeExpired  = errors.New("no pending code, or it expired or ran out of attempts")
+	errChallengeMismatch = errors.New("code does not match")
+)
+
+// issueEmailChallenge creates a code for purpose, replacing any earlier one
+// so only the latest code works. It returns an empty code when one was sent
+// too recently to send another.
+func (s *AuthService) issueEmailChallenge(ctx context.Context, userID models.ID, purpose string) (string, error) {
+	now := time.Now()
+
+	err := s.db.Challenges.FindOne(ctx, bson.M{
+		"user_id":    userID,
+		"purpose":    purpose,
+		"created_at": bson.M{"$gt": now.Add(-emailChallengeResendDelay)},
+	}).Err()
+	if err == nil {
+		return "", nil
+	} else if err != mongo.ErrNoDocuments {
+		return "", fmt.Errorf("failed to check email challenge: %v", err)
+	}
+
+	code, err := utils.GenerateNumericCode(emailCodeDigits)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate code: %v", err)
+	}
+
+	_, err = s.db.Challenges.ReplaceOne(ctx, bson.M{
+		"user_id": userID,
+		"purpose": purpose,
+	}, models.EmailChallenge{
+		UserID:    userID,
+		Purpose:   purpose,
+		CodeHash:  utils.HashToken(code),
+		ExpiresAt: now.Add(emailChallengeTTL),
+		CreatedAt: now,
+	}, options.Replace().SetUpsert(true))
+	if err != nil {
+		return "", fmt.Errorf("failed to create email challenge: %v", err)
+	}
+
+	return code, nil
+}
+
+// verifyEmailChallenge checks a code against the pending challenge. Every
+// call uses up one attempt, whether or not the code matches, so codes can't
+// be brute forced.
+func (s *AuthService) verifyEmailChallenge(ctx context.Context, userID models.ID, purpose, code string) (*models.EmailChallenge, error) {
+	var challenge models.EmailChallenge
+	err := s.db.Challenges.FindOneAndUpdate(ctx, bson.M{
+		"user_id":    userID,
+		"purpose":    purpose,
+		"expires_at": bson.M{"$gt": time.Now()},
+		"attempts":   bson.M{"$lt": maxEmailChallengeAttempts},
+	}, bson.M{
+		"$inc": bson.M{"attempts": 1},
+	}).Decode(&challenge)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errChallengeExpired
+		}
+		return nil, fmt.Errorf("failed to check email challenge: %v", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(code)), []byte(challenge.CodeHash)) != 1 {
+		return nil, errChallengeMismatch
+	}
+
+	return &challenge, nil
+}
+
+// deleteEmailChallenge removes a challenge once it has been used
+func (s *AuthService) deleteEmailChallenge(ctx context.Context, challenge *models.EmailChallenge) {
+	if _, err := s.db.Challenges.DeleteOne(ctx, bson.M{"_id": challenge.ID}); err != nil {
+		log.Printf("Failed to delete %s challenge for user %s: %v", challenge.Purpose, challenge.UserID.String(), err)
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/audit"
+	"user-management/authmd"
+	"user-management/authz"
+	"user-management/database"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+)
+
+// StartEmailVerification sends a code to the caller's email address, which
+// VerifyEmail accepts to mark the address verified
+func (s *AuthService) StartEmailVerification(ctx context.Context, req *pb.StartEmailVerificationRequest) (*pb.StartEmailVerificationResponse, error) {
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.EmailVerified {
+		return &pb.StartEmailVerificationResponse{
+			Message: "Email address is already verified",
+		}, nil
+	}
+
+	response := &pb.StartEmailVerificationResponse{
+		Message: "A verification code has been sent to " + user.Email,
+	}
+
+	code, err := s.issueEmailChallenge(ctx, user.ID, models.ChallengePurposeVerifyEmail)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create verification code")
+	}
+	if code == "" {
+		return response, nil
+	}
+
+	msg, err := notifications.Render(notifications.TemplateVerifyEmail, user.Email, map[string]string{
+		"Code":      code,
+		"ExpiresIn": emailChallengeTTL.String(),
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send verification code to %s: %v", user.Email, err)
+	}
+
+	return response, nil
+}
+
+// VerifyEmail marks the caller's email address verified with a code from
+// StartEmailVerification
+func (s *AuthService) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Code == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "code is required")
+	}
+
+	challenge, err := s.verifyEmailChallenge(ctx, user.ID, models.ChallengePurposeVerifyEmail, req.Code)
+	if err != nil {
+		if err == errChallengeExpired || err == errChallengeMismatch {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid or expired code, request a new one")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to check verification code")
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		// Only verify the address the code was sent to
+		if err := s.users.SetEmailVerified(ctx, user.ID, user.Email, time.Now()); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionEmailVerified,
+			ActorID:  &user.ID,
+			TargetID: user.ID,
+			Details:  bson.M{"email": user.Email},
+		})
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.FailedPrecondition, "email address changed, request a new code")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to verify email address")
+	}
+
+	s.deleteEmailChallenge(ctx, challenge)
+
+	return &pb.VerifyEmailResponse{
+		Message: "Email address verified",
+	}, nil
+}
+
+// authenticatedUser returns the user the request's session token belongs to
+func (s *AuthService) authenticatedUser(ctx context.Context) (*models.User, error) {
+	user, _, err := s.authenticatedSession(ctx)
+	return user, err
+}
+
+// authenticatedSession is authenticatedUser, also returning the caller's
+// principal
+func (s *AuthService) authenticatedSession(ctx context.Context) (*models.User, authmd.Principal, error) {
+	principal, err := authz.Caller(ctx)
+	if err != nil {
+		return nil, authmd.Principal{}, err
+	}
+
+	userID, err := models.ParseID(principal.UserID)
+	if err != nil {
+		return nil, authmd.Principal{}, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if err == database.ErrNotFound {
+			return nil, authmd.Principal{}, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, authmd.Principal{}, status.Errorf(codes.Internal, "failed to retrieve user")
+	}
+
+	return user, principal, nil
+}
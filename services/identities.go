@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/audit"
+	"user-management/database"
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+// ListLinkedIdentities returns the external identities the caller signs in
+// with, oldest first
+func (s *UserService) ListLinkedIdentities(ctx context.Context, req *pb.ListLinkedIdentitiesRequest) (*pb.ListLinkedIdentitiesResponse, error) {
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Identities are appended as they are linked, so they are oldest first
+	identities := make([]*pb.LinkedIdentity, 0, len(user.Identities))
+	for _, identity := range user.Identities {
+		identities = append(identities, linkedIdentityToProto(identity))
+	}
+
+	return &pb.ListLinkedIdentitiesResponse{
+		Identities:  identities,
+		HasPassword: user.Password != "",
+	}, nil
+}
+
+// UnlinkIdentity removes one of the caller's external identities. The last
+// way to sign in can't be removed, so accounts without a password keep at
+// least one identity.
+func (s *UserService) UnlinkIdentity(ctx context.Context, req *pb.UnlinkIdentityRequest) (*pb.UnlinkIdentityResponse, error) {
+	if req.Issuer == "" || req.Subject == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "issuer and subject are required")
+	}
+
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var unlinked *models.LinkedIdentity
+	for i, identity := range user.Identities {
+		if identity.Issuer == req.Issuer && identity.Subject == req.Subject {
+			unlinked = &user.Identities[i]
+		}
+	}
+	if unlinked == nil {
+		return nil, status.Errorf(codes.NotFound, "identity not found")
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		err := s.users.UnlinkIdentity(ctx, user.ID, req.Issuer, req.Subject, time.Now())
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionIdentityUnlinked,
+			ActorID:  &user.ID,
+			TargetID: user.ID,
+			Details: bson.M{
+				"provider": unlinked.Provider,
+				"issuer":   unlinked.Issuer,
+			},
+		})
+	})
+	if err != nil {
+		if err == database.ErrLastLoginMethod {
+			return nil, status.Errorf(codes.FailedPrecondition, "set a password before unlinking your last way to sign in")
+		}
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "identity not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to unlink identity")
+	}
+
+	return &pb.UnlinkIdentityResponse{
+		Message: "Identity unlinked",
+	}, nil
+}
+
+func linkedIdentityToProto(identity models.LinkedIdentity) *pb.LinkedIdentity {
+	pbIdentity := &pb.LinkedIdentity{
+		Provider: identity.Provider,
+		Issuer:   identity.Issuer,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		LinkedAt: timestamppb.New(identity.LinkedAt),
+	}
+	if !identity.OrgID.IsZero() {
+		pbIdentity.OrganizationId = identity.OrgID.Hex()
+	}
+	if identity.LastLoginAt != nil {
+		pbIdentity.LastLoginAt = timestamppb.New(*identity.LastLoginAt)
+	}
+	return pbIdentity
+}
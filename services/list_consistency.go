@@ -0,0 +1,34 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errInvalidConsistencyToken = errors.New("invalid consistency token")
+
+// encodeConsistencyToken turns the cluster time a listing reads at into an
+// opaque token for the client to send back with later pages
+func encodeConsistencyToken(at primitive.Timestamp) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d.%d", at.T, at.I)))
+}
+
+// decodeConsistencyToken recovers the cluster time from a token
+func decodeConsistencyToken(token string) (primitive.Timestamp, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return primitive.Timestamp{}, errInvalidConsistencyToken
+	}
+
+	var at primitive.Timestamp
+	if n, err := fmt.Sscanf(string(raw), "%d.%d", &at.T, &at.I); err != nil || n != 2 || at.T == 0 {
+		return primitive.Timestamp{}, errInvalidConsistencyToken
+	}
+	if encodeConsistencyToken(at) != token {
+		return primitive.Timestamp{}, errInvalidConsistencyToken
+	}
+	return at, nil
+}
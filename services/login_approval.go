@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/authz"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+func (s *AuthService) ApproveLogin(ctx context.Context, req *pb.ApproveLoginRequest) (*pb.ApproveLoginResponse, error) {
+	var filter bson.M
+
+	if req.ApprovalToken != "" {
+		// Approval link from the notification email
+		filter = bson.M{"approval_token_hash": utils.HashToken(req.ApprovalToken)}
+	} else {
+		// Approval from an existing, authenticated session
+		if req.PendingLoginId == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "pending login ID or approval token is required")
+		}
+
+		if _, err := authz.RequireSession(ctx); err != nil {
+			return nil, err
+		}
+		userID, err := authz.CallerID(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		pendingObjectID, err := primitive.ObjectIDFromHex(req.PendingLoginId)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid pending login ID format")
+		}
+
+		filter = bson.M{"_id": pendingObjectID, "user_id": userID}
+	}
+
+	now := time.Now()
+	filter["status"] = models.PendingLoginStatusPending
+	filter["expires_at"] = bson.M{"$gt": now}
+
+	var pending models.PendingLogin
+	err := s.db.Pending.FindOneAndUpdate(ctx, filter, bson.M{
+		"$set": bson.M{
+			"status":      models.PendingLoginStatusApproved,
+			"approved_at": now,
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&pending)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "pending login not found or expired")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to approve login")
+	}
+
+	// Trust the new device so its next login goes straight through
+	s.recordDevice(ctx, pending.UserID, pending.UserAgent, pending.IPAddress)
+
+	return &pb.ApproveLoginResponse{
+		Message: "Login approved, sign in again on the new device",
+	}, nil
+}
+
+func (s *AuthService) ListPendingLogins(ctx context.Context, req *pb.ListPendingLoginsRequest) (*pb.ListPendingLoginsResponse, error) {
+	userID, err := authz.CallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := s.db.Pending.Find(ctx, bson.M{
+		"user_id":    userID,
+		"status":     models.PendingLoginStatusPending,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find pending logins")
+	}
+	defer cursor.Close(ctx)
+
+	var pendingLogins []models.PendingLogin
+	if err = cursor.All(ctx, &pendingLogins); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode pending logins")
+	}
+
+	var pbPendingLogins []*pb.PendingLogin
+	for _, pending := range pendingLogins {
+		pbPendingLogins = append(pbPendingLogins, &pb.PendingLogin{
+			Id:        pending.ID.Hex(),
+			UserAgent: pending.UserAgent,
+			IpAddress: pending.IPAddress,
+			CreatedAt: timestamppb.New(pending.CreatedAt),
+			ExpiresAt: timestamppb.New(pending.ExpiresAt),
+		})
+	}
+
+	return &pb.ListPendingLoginsResponse{
+		PendingLogins: pbPendingLogins,
+	}, nil
+}
+
+// requireLoginApproval returns a pending login when the device is not yet
+// known for the user, or nil when the login may proceed. The first device
+// an account logs in from is trusted without approval.
+func (s *AuthService) requireLoginApproval(ctx context.Context, user *models.User, userAgent, ipAddress string) (*models.PendingLogin, error) {
+	fingerprint := utils.DeviceFingerprint(userAgent, ipAddress)
+
+	deviceCount, err := s.db.Devices.CountDocuments(ctx, bson.M{"user_id": user.ID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check known devices")
+	}
+	if deviceCount == 0 {
+		return nil, nil
+	}
+
+	err = s.db.Devices.FindOne(ctx, bson.M{
+		"user_id":     user.ID,
+		"fingerprint": fingerprint,
+	}).Err()
+	if err == nil {
+		return nil, nil
+	} else if err != mongo.ErrNoDocuments {
+		return nil, status.Errorf(codes.Internal, "failed to check known devices")
+	}
+
+	now := time.Now()
+
+	// Reuse an outstanding request rather than sending another email
+	var pending models.PendingLogin
+	err = s.db.Pending.FindOne(ctx, bson.M{
+		"user_id":     user.ID,
+		"fingerprint": fingerprint,
+		"status":      models.PendingLoginStatusPending,
+		"expires_at":  bson.M{"$gt": now},
+	}).Decode(&pending)
+	if err == nil {
+		return &pending, nil
+	} else if err != mongo.ErrNoDocuments {
+		return nil, status.Errorf(codes.Internal, "failed to check pending logins")
+	}
+
+	approvalToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate approval token")
+	}
+
+	pending = models.PendingLogin{
+		UserID:            user.ID,
+		Fingerprint:       fingerprint,
+		UserAgent:         userAgent,
+		IPAddress:         ipAddress,
+		ApprovalTokenHash: utils.HashToken(approvalToken),
+		Status:            models.PendingLoginStatusPending,
+		ExpiresAt:         now.Add(s.config.LoginApprovalTTL),
+		CreatedAt:         now,
+	}
+
+	result, err := s.db.Pending.InsertOne(ctx, pending)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create pending login")
+	}
+	pending.ID = result.InsertedID.(primitive.ObjectID)
+
+	msg, err := notifications.Render(notifications.TemplateLoginApproval, user.Email, map[string]string{
+		"UserAgent":    userAgent,
+		"IPAddress":    ipAddress,
+		"ExpiresIn":    s.config.LoginApprovalTTL.String(),
+		"ApprovalLink": fmt.Sprintf("%s?token=%s", s.config.LoginApprovalURL, url.QueryEscape(approvalToken)),
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send login approval email to %s: %v", user.Email, err)
+	}
+
+	return &pending, nil
+}
+
+// recordDevice marks a device as known for the user, reporting whether it
+// was new
+func (s *AuthService) recordDevice(ctx context.Context, userID models.ID, userAgent, ipAddress string) bool {
+	now := time.Now()
+	result, err := s.db.Devices.UpdateOne(ctx, bson.M{
+		"user_id":     userID,
+		"fingerprint": utils.DeviceFingerprint(userAgent, ipAddress),
+	}, bson.M{
+		"$set": bson.M{
+			"user_agent":   userAgent,
+			"ip_address":   ipAddress,
+			"last_seen_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"first_seen_at":  now,
+			"schema_version": models.DeviceSchema.Version(),
+		},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("Failed to record device for user %s: %v", userID.String(), err)
+		return false
+	}
+	return result.UpsertedCount > 0
+}
+
+// notifyNewDevice emails the user about a sign-in from a device recorded
+// for the first time. The first device of an account is the one it was set
+// up on, so it isn't reported.
+func (s *AuthService) notifyNewDevice(ctx context.Context, user *models.User, userAgent, ipAddress string) {
+	devices, err := s.db.Devices.CountDocuments(ctx, bson.M{"user_id": user.ID})
+	if err != nil {
+		log.Printf("Failed to count devices of user %s: %v", user.ID.String(), err)
+		return
+	}
+	if devices <= 1 {
+		return
+	}
+
+	msg, err := notifications.Render(notifications.TemplateNewDeviceLogin, user.Email, map[string]string{
+		"UserAgent": userAgent,
+		"IPAddress": ipAddress,
+		"Time":      time.Now().UTC().Format(time.RFC3339),
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send new device alert to %s: %v", user.Email, err)
+	}
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/authn"
+	"user-management/database"
+	"user-management/models"
+	"user-management/notifications"
+	"user-management/passkeys"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// newLoginChain returns the authenticators of the login methods config
+// enables
+func newLoginChain(s *AuthService) *authn.Chain {
+	authenticators := map[string]authn.Authenticator{
+		authn.MethodPassword: authn.NewPassword(s.rehashPassword),
+		authn.MethodOTP:      otpAuthenticator{s},
+	}
+	if s.config.LDAP != nil {
+		authenticators[authn.MethodLDAP] = authn.NewLDAP(s.config.LDAP)
+	}
+	if s.config.SSO != nil && s.config.OIDCLogin != nil {
+		authenticators[authn.MethodOIDC] = authn.NewOIDC(s.config.SSO, *s.config.OIDCLogin)
+	}
+	if s.config.Passkeys != nil {
+		authenticators[authn.MethodPasskey] = passkeyAuthenticator{s}
+	}
+	return authn.NewChain(authenticators)
+}
+
+// loginMethods returns the login chain of user: their organization's when
+// it has one, otherwise the deployment's
+func (s *AuthService) loginMethods(ctx context.Context, user *models.User) ([]string, error) {
+	if user.OrgID != nil {
+		var org models.Organization
+		err := s.db.Orgs.FindOne(ctx, bson.M{"_id": *user.OrgID}).Decode(&org)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		if len(org.Policy.LoginMethods) > 0 {
+			return org.Policy.LoginMethods, nil
+		}
+	}
+	return s.config.LoginMethods, nil
+}
+
+// loginMethodEnabled reports whether user's login chain includes method
+// and the deployment has configured it
+func (s *AuthService) loginMethodEnabled(ctx context.Context, user *models.User, method string) (bool, error) {
+	methods, err := s.loginMethods(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(methods, method) && s.loginChain.Configured(method), nil
+}
+
+// otpAuthenticator checks a code sent by SendLoginCode
+type otpAuthenticator struct {
+	s *AuthService
+}
+
+func (a otpAuthenticator) Authenticate(ctx context.Context, user *models.User, credentials authn.Credentials) (bool, error) {
+	challenge, err := a.s.verifyEmailChallenge(ctx, user.ID, models.ChallengePurposeLogin, credentials.OneTimeCode)
+	if err == errChallengeExpired || err == errChallengeMismatch {
+		return false, authn.ErrInvalidCredentials
+	}
+	if err != nil {
+		return false, err
+	}
+	a.s.deleteEmailChallenge(ctx, challenge)
+	return false, nil
+}
+
+// passkeyAuthenticator verifies an assertion for the options from
+// BeginPasskeyLogin. The authenticator verified the user, which counts as
+// the second factor.
+type passkeyAuthenticator struct {
+	s *AuthService
+}
+
+func (a passkeyAuthenticator) Authenticate(ctx context.Context, user *models.User, credentials authn.Credentials) (bool, error) {
+	if user.PasskeyLogin == nil || time.Now().After(user.PasskeyLogin.ExpiresAt) {
+		return false, authn.ErrInvalidCredentials
+	}
+
+	// The session is single use, whether or not the assertion is valid
+	err := a.s.users.ConsumePasskeyLogin(ctx, user.ID, user.PasskeyLogin.Session)
+	if err == mongo.ErrNoDocuments {
+		return false, authn.ErrInvalidCredentials
+	}
+	if err != nil {
+		return false, err
+	}
+
+	registered, err := a.s.passkeysOf(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	assertion, err := a.s.config.Passkeys.FinishLogin(newPasskeyUser(user), registered, user.PasskeyLogin.Session, []byte(credentials.PasskeyAssertion))
+	if errors.Is(err, passkeys.ErrInvalidCredential) {
+		log.Printf("Rejected passkey login for user %s: %v", user.ID.String(), err)
+		return false, authn.ErrInvalidCredentials
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, err = a.s.db.Passkeys.UpdateOne(ctx, bson.M{
+		"user_id":       user.ID,
+		"credential_id": assertion.CredentialID,
+	}, bson.M{
+		"$set": bson.M{
+			"sign_count":   assertion.SignCount,
+			"backup_state": assertion.BackupState,
+			"last_used_at": time.Now(),
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to record passkey use for user %s: %v", user.ID.String(), err)
+	}
+	return true, nil
+}
+
+// passkeysOf returns the registered passkeys of user, as the relying
+// party verifies logins with them
+func (s *AuthService) passkeysOf(ctx context.Context, user *models.User) ([]passkeys.Credential, error) {
+	cursor, err := s.db.Passkeys.Find(ctx, bson.M{"user_id": user.ID})
+	if err != nil {
+		return nil, err
+	}
+	var stored []models.Passkey
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+
+	registered := make([]passkeys.Credential, 0, len(stored))
+	for _, passkey := range stored {
+		credential := passkeys.Credential{
+			ID:                passkey.CredentialID,
+			PublicKey:         passkey.PublicKey,
+			AttestationFormat: passkey.AttestationFormat,
+			SignCount:         passkey.SignCount,
+			Transports:        passkey.Transports,
+			BackupEligible:    passkey.BackupEligible,
+			BackupState:       passkey.BackupState,
+		}
+		// Passkeys without attestation have the zero AAGUID
+		credential.AAGUID, _ = uuid.Parse(passkey.AAGUID)
+		registered = append(registered, credential)
+	}
+	return registered, nil
+}
+
+// SendLoginCode emails a code to sign in with, for accounts whose login
+// chain includes otp. The code goes to Login as one_time_code.
+func (s *AuthService) SendLoginCode(ctx context.Context, req *pb.SendLoginCodeRequest) (*pb.SendLoginCodeResponse, error) {
+	req.Email = utils.SanitizeString(req.Email)
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	// The same response is returned whether or not the account exists or
+	// can sign in with a code, so the RPC can't be used to probe accounts
+	response := &pb.SendLoginCodeResponse{
+		Message: "If the account can sign in with an emailed code, one has been sent to its email address",
+	}
+
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err == database.ErrNotFound {
+			return response, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+	if !user.IsActive {
+		return response, nil
+	}
+	enabled, err := s.loginMethodEnabled(ctx, user, authn.MethodOTP)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve login methods")
+	}
+	if !enabled {
+		return response, nil
+	}
+
+	code, err := s.issueEmailChallenge(ctx, user.ID, models.ChallengePurposeLogin)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create login code")
+	}
+	if code == "" {
+		return response, nil
+	}
+
+	msg, err := notifications.Render(notifications.TemplateLoginCode, user.Email, map[string]string{
+		"Code":      code,
+		"ExpiresIn": emailChallengeTTL.String(),
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send login code to %s: %v", user.Email, err)
+	}
+
+	return response, nil
+}
+
+// BeginPasskeyLogin starts signing in with a passkey. The returned options
+// go to navigator.credentials.get(), and its result to Login as
+// passkey_assertion. Starting again replaces a login that wasn't finished.
+func (s *AuthService) BeginPasskeyLogin(ctx context.Context, req *pb.BeginPasskeyLoginRequest) (*pb.BeginPasskeyLoginResponse, error) {
+	req.Email = utils.SanitizeString(req.Email)
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	// Accounts that can't sign in with a passkey all get the same error
+	unavailable := status.Errorf(codes.FailedPrecondition, "passkey login is not available for this account")
+
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err == database.ErrNotFound {
+			return nil, unavailable
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+	enabled, err := s.loginMethodEnabled(ctx, user, authn.MethodPasskey)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve login methods")
+	}
+	if !enabled || !user.IsActive {
+		return nil, unavailable
+	}
+
+	registered, err := s.passkeysOf(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve passkeys")
+	}
+	if len(registered) == 0 {
+		return nil, unavailable
+	}
+
+	requestOptions, session, err := s.config.Passkeys.BeginLogin(newPasskeyUser(user), registered)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to start passkey login")
+	}
+
+	expiresAt := time.Now().Add(passkeys.LoginTimeout)
+	err = s.users.StartPasskeyLogin(ctx, user.ID, models.PasskeyLogin{
+		Session:   session,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to start passkey login")
+	}
+
+	return &pb.BeginPasskeyLoginResponse{
+		RequestOptions: string(requestOptions),
+		ExpiresAt:      timestamppb.New(expiresAt),
+	}, nil
+}
+
+// newPasskeyUser describes user to the relying party
+func newPasskeyUser(user *models.User) passkeys.User {
+	passkeyUser := passkeys.User{
+		ID:          user.ID.String(),
+		Name:        user.Email,
+		DisplayName: user.Name,
+	}
+	if passkeyUser.DisplayName == "" {
+		passkeyUser.DisplayName = user.Email
+	}
+	return passkeyUser
+}
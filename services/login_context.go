@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+// Follow-up actions a login can ask of the user, most urgent first
+const (
+	requiredActionSetUpTwoFactor = "set_up_two_factor"
+	requiredActionChangePassword = "change_password"
+	requiredActionVerifyEmail    = "verify_email"
+	requiredActionAcceptTerms    = "accept_terms"
+)
+
+// loginSecurityContext describes a successful login of user. newDevice,
+// twoFactorUsed and enrollmentRequired are what the login found.
+func (s *AuthService) loginSecurityContext(user *models.User, newDevice, twoFactorUsed, enrollmentRequired bool, now time.Time) *pb.LoginSecurityContext {
+	passwordSetAt := user.PasswordSetAt()
+	securityContext := &pb.LoginSecurityContext{
+		NewDevice:         newDevice,
+		TwoFactorUsed:     twoFactorUsed,
+		TwoFactorEnabled:  user.TwoFactor != nil,
+		PasswordChangedAt: timestamppb.New(passwordSetAt),
+	}
+
+	if enrollmentRequired {
+		securityContext.RequiredActions = append(securityContext.RequiredActions, requiredActionSetUpTwoFactor)
+	}
+
+	if s.config.PasswordMaxAge > 0 {
+		expiresAt := passwordSetAt.Add(s.config.PasswordMaxAge)
+		securityContext.PasswordExpiresAt = timestamppb.New(expiresAt)
+
+		switch remaining := expiresAt.Sub(now); {
+		case remaining <= 0:
+			securityContext.RequiredActions = append(securityContext.RequiredActions, requiredActionChangePassword)
+			securityContext.PasswordWarning = "Your password has expired, choose a new one"
+		case remaining <= s.config.PasswordExpiryWarning:
+			days := int(math.Ceil(remaining.Hours() / 24))
+			if days == 1 {
+				securityContext.PasswordWarning = "Your password expires within a day"
+			} else {
+				securityContext.PasswordWarning = fmt.Sprintf("Your password expires in %d days", days)
+			}
+		}
+	}
+
+	if !user.EmailVerified {
+		securityContext.RequiredActions = append(securityContext.RequiredActions, requiredActionVerifyEmail)
+	}
+
+	if s.config.TermsVersion != "" && user.AcceptedTermsVersion != s.config.TermsVersion {
+		securityContext.RequiredActions = append(securityContext.RequiredActions, requiredActionAcceptTerms)
+		securityContext.TermsVersion = s.config.TermsVersion
+		securityContext.TermsUrl = s.config.TermsURL
+	}
+
+	return securityContext
+}
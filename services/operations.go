@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+const (
+	// operationHeartbeat is how often a running operation's updated_at is
+	// refreshed when it makes no progress
+	operationHeartbeat = 30 * time.Second
+	// operationStaleAfter is how long an operation goes without a
+	// heartbeat before its server is taken to have stopped
+	operationStaleAfter = 5 * time.Minute
+	// operationWatchInterval is how often WatchOperation checks for
+	// progress
+	operationWatchInterval = time.Second
+)
+
+// operationTask is the work of an operation. It reports progress through
+// progress, and returns the operation's response, or nil when it has none.
+type operationTask func(ctx context.Context, progress *operationProgress) (proto.Message, error)
+
+// startOperation records an operation of kind and runs task for it in the
+// background. The task outlives the request, but not the server.
+func (s *AdminService) startOperation(ctx context.Context, kind string, requestedBy models.ID, total int64, task operationTask) (*models.Operation, error) {
+	now := time.Now()
+	op := &models.Operation{
+		Kind:        kind,
+		RequestedBy: requestedBy,
+		Total:       total,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	result, err := s.db.Operations.InsertOne(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	op.ID = result.InsertedID.(primitive.ObjectID)
+
+	go s.runOperation(context.WithoutCancel(ctx), op, task)
+	return op, nil
+}
+
+// runOperation runs task and records its outcome in op, refreshing op's
+// heartbeat until it is done
+func (s *AdminService) runOperation(ctx context.Context, op *models.Operation, task operationTask) {
+	heartbeatDone := make(chan struct{})
+	defer close(heartbeatDone)
+	go func() {
+		ticker := time.NewTicker(operationHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeatDone:
+				return
+			case <-ticker.C:
+				_, err := s.db.Operations.UpdateOne(ctx, bson.M{"_id": op.ID, "done": false}, bson.M{
+					"$set": bson.M{"updated_at": time.Now()},
+				})
+				if err != nil {
+					log.Printf("Failed to record heartbeat of operation %s: %v", op.ID.Hex(), err)
+				}
+			}
+		}
+	}()
+
+	response, err := task(ctx, &operationProgress{s: s, id: op.ID})
+
+	now := time.Now()
+	update := bson.M{
+		"done":         true,
+		"updated_at":   now,
+		"completed_at": now,
+		"expires_at":   now.Add(time.Duration(s.config.Settings.Admin.OperationRetention)),
+	}
+	if err == nil && response != nil {
+		var packed *anypb.Any
+		packed, err = anypb.New(response)
+		if err == nil {
+			update["response"], err = proto.Marshal(packed)
+		}
+	}
+	if err != nil {
+		st, ok := status.FromError(err)
+		if !ok {
+			log.Printf("Operation %s (%s) failed: %v", op.ID.Hex(), op.Kind, err)
+			st = status.New(codes.Internal, "operation failed")
+		}
+		update["error_code"] = int32(st.Code())
+		update["error_message"] = st.Message()
+	}
+
+	_, err = s.db.Operations.UpdateOne(ctx, bson.M{"_id": op.ID}, bson.M{"$set": update})
+	if err != nil {
+		log.Printf("Failed to record the outcome of operation %s: %v", op.ID.Hex(), err)
+	}
+}
+
+// operationProgress records how far a running operation has come
+type operationProgress struct {
+	s  *AdminService
+	id primitive.ObjectID
+}
+
+// Set records that processed records have been handled
+func (p *operationProgress) Set(ctx context.Context, processed int64) {
+	_, err := p.s.db.Operations.UpdateOne(ctx, bson.M{"_id": p.id, "done": false}, bson.M{
+		"$set": bson.M{
+			"processed":  processed,
+			"updated_at": time.Now(),
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to record progress of operation %s: %v", p.id.Hex(), err)
+	}
+}
+
+// FailInterruptedOperations marks operations whose server stopped before
+// they were done as failed, and returns how many
+func (s *AdminService) FailInterruptedOperations(ctx context.Context) (int, error) {
+	now := time.Now()
+	result, err := s.db.Operations.UpdateMany(ctx, bson.M{
+		"done":       false,
+		"updated_at": bson.M{"$lt": now.Add(-operationStaleAfter)},
+	}, bson.M{
+		"$set": bson.M{
+			"done":          true,
+			"error_code":    int32(codes.Aborted),
+			"error_message": "the server running the operation stopped, start it again",
+			"completed_at":  now,
+			"expires_at":    now.Add(time.Duration(s.config.Settings.Admin.OperationRetention)),
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(result.ModifiedCount), nil
+}
+
+// GetOperation returns a long-running operation with its progress, and its
+// result once done
+func (s *AdminService) GetOperation(ctx context.Context, req *pb.GetOperationRequest) (*pb.GetOperationResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	op, err := s.findOperation(ctx, req.OperationId)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetOperationResponse{
+		Operation: operationToProto(op),
+	}, nil
+}
+
+// ListOperations returns the operations kept, newest first
+func (s *AdminService) ListOperations(ctx context.Context, req *pb.ListOperationsRequest) (*pb.ListOperationsResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	filter := bson.M{}
+	if req.Kind != "" {
+		filter["kind"] = req.Kind
+	}
+
+	totalCount, err := s.db.Operations.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count operations")
+	}
+
+	cursor, err := s.db.Operations.Find(ctx, filter, options.Find().
+		SetSkip(int64((page-1)*pageSize)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find operations")
+	}
+	var ops []models.Operation
+	if err := cursor.All(ctx, &ops); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode operations")
+	}
+
+	response := &pb.ListOperationsResponse{
+		TotalCount: int32(totalCount),
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for i := range ops {
+		response.Operations = append(response.Operations, operationToProto(&ops[i]))
+	}
+	return response, nil
+}
+
+// WatchOperation sends the operation, then again whenever it progresses,
+// and returns once it is done
+func (s *AdminService) WatchOperation(req *pb.WatchOperationRequest, stream pb.AdminService_WatchOperationServer) error {
+	ctx := stream.Context()
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(operationWatchInterval)
+	defer ticker.Stop()
+
+	var sent *models.Operation
+	for {
+		op, err := s.findOperation(ctx, req.OperationId)
+		if err != nil {
+			return err
+		}
+		if sent == nil || op.UpdatedAt != sent.UpdatedAt || op.Processed != sent.Processed || op.Done != sent.Done {
+			if err := stream.Send(operationToProto(op)); err != nil {
+				return err
+			}
+			sent = op
+		}
+		if op.Done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RebuildIndexes creates any missing database indexes again in an
+// operation
+func (s *AdminService) RebuildIndexes(ctx context.Context, req *pb.RebuildIndexesRequest) (*pb.RebuildIndexesResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := s.startOperation(ctx, models.OperationRebuildIndexes, admin.ID, 0,
+		func(ctx context.Context, progress *operationProgress) (proto.Message, error) {
+			if err := s.db.RebuildIndexes(ctx); err != nil {
+				log.Printf("Failed to rebuild indexes: %v", err)
+				return nil, status.Errorf(codes.Internal, "failed to rebuild indexes")
+			}
+			return nil, nil
+		})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to start index rebuild")
+	}
+	log.Printf("Admin %s started rebuilding indexes in operation %s", admin.Email, op.ID.Hex())
+
+	return &pb.RebuildIndexesResponse{
+		Operation: operationToProto(op),
+	}, nil
+}
+
+// RunAccountPurge purges every account whose deletion grace period has
+// ended in an operation, instead of waiting for the account_purge job
+func (s *AdminService) RunAccountPurge(ctx context.Context, req *pb.RunAccountPurgeRequest) (*pb.RunAccountPurgeResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := s.startOperation(ctx, models.OperationAccountPurge, admin.ID, 0,
+		func(ctx context.Context, progress *operationProgress) (proto.Message, error) {
+			var purged int32
+			for {
+				n, err := s.PurgeDeletedAccounts(ctx)
+				purged += int32(n)
+				if err != nil {
+					log.Printf("Failed to purge deleted accounts: %v", err)
+					return nil, status.Errorf(codes.Internal, "failed to purge accounts after purging %d", purged)
+				}
+				if n == 0 {
+					return &pb.AccountPurgeResult{Purged: purged}, nil
+				}
+				progress.Set(ctx, int64(purged))
+			}
+		})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to start account purge")
+	}
+	log.Printf("Admin %s started purging deleted accounts in operation %s", admin.Email, op.ID.Hex())
+
+	return &pb.RunAccountPurgeResponse{
+		Operation: operationToProto(op),
+	}, nil
+}
+
+// findOperation returns the operation with the ID a client sent
+func (s *AdminService) findOperation(ctx context.Context, operationID string) (*models.Operation, error) {
+	id, err := primitive.ObjectIDFromHex(operationID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid operation ID format")
+	}
+
+	var op models.Operation
+	err = s.db.Operations.FindOne(ctx, bson.M{"_id": id}).Decode(&op)
+	if err == mongo.ErrNoDocuments {
+		return nil, status.Errorf(codes.NotFound, "operation not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find operation")
+	}
+	return &op, nil
+}
+
+func operationToProto(op *models.Operation) *pb.Operation {
+	result := &pb.Operation{
+		Id:          op.ID.Hex(),
+		Kind:        op.Kind,
+		RequestedBy: op.RequestedBy.String(),
+		Done:        op.Done,
+		Processed:   op.Processed,
+		Total:       op.Total,
+		CreatedAt:   timestamppb.New(op.CreatedAt),
+		UpdatedAt:   timestamppb.New(op.UpdatedAt),
+	}
+	if op.CompletedAt != nil {
+		result.CompletedAt = timestamppb.New(*op.CompletedAt)
+	}
+
+	switch {
+	case op.ErrorCode != 0:
+		result.Result = &pb.Operation_Error{Error: &pb.OperationError{
+			Code:    statusCodeName(codes.Code(op.ErrorCode)),
+			Message: op.ErrorMessage,
+		}}
+	case len(op.Response) > 0:
+		var response anypb.Any
+		if err := proto.Unmarshal(op.Response, &response); err != nil {
+			log.Printf("Failed to decode the response of operation %s: %v", op.ID.Hex(), err)
+			break
+		}
+		result.Result = &pb.Operation_Response{Response: &response}
+	}
+	return result
+}
+
+// statusCodeName returns the name of code as gRPC spells it on the wire,
+// such as "DEADLINE_EXCEEDED"
+func statusCodeName(code codes.Code) string {
+	var name strings.Builder
+	prev := ' '
+	for _, r := range code.String() {
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			name.WriteByte('_')
+		}
+		name.WriteRune(unicode.ToUpper(r))
+		prev = r
+	}
+	return name.String()
+}
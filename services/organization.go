@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/apierrors"
+	"user-management/audit"
+	"user-management/authz"
+	"user-management/database"
+	"user-management/events"
+	"user-management/eventsource"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+	"user-management/tenancy"
+	"user-management/utils"
+)
+
+var (
+	// errChangeRequestNotFound aborts a review of a request that is not
+	// pending in the reviewer's organization
+	errChangeRequestNotFound = errors.New("profile change request not found")
+	errEmailTaken            = errors.New("email is already taken")
+)
+
+// OrganizationService lets org admins manage their organization's members
+type OrganizationService struct {
+	pb.UnimplementedOrganizationServiceServer
+	db         *database.Database
+	users      database.UserRepository
+	authorizer authz.Authorizer
+	sender     notifications.Sender
+}
+
+func NewOrganizationService(db *database.Database, authorizer authz.Authorizer, sender notifications.Sender) *OrganizationService {
+	return &OrganizationService{
+		db:         db,
+		users:      db.Repositories().Users,
+		authorizer: authorizer,
+		sender:     sender,
+	}
+}
+
+func (s *OrganizationService) ListProfileChangeRequests(ctx context.Context, req *pb.ListProfileChangeRequestsRequest) (*pb.ListProfileChangeRequestsResponse, error) {
+	orgAdmin, err := s.requireOrgAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set default pagination values
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	requestStatus := req.Status
+	if requestStatus == "" {
+		requestStatus = models.ProfileChangeStatusPending
+	}
+
+	filter := bson.M{
+		"org_id": orgAdmin.OrgID,
+		"status": requestStatus,
+	}
+
+	totalCount, err := s.db.Changes.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count profile change requests")
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSkip(int64((page - 1) * pageSize))
+	findOptions.SetLimit(int64(pageSize))
+	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := s.db.Changes.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find profile change requests")
+	}
+	defer cursor.Close(ctx)
+
+	var changeRequests []models.ProfileChangeRequest
+	if err = cursor.All(ctx, &changeRequests); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode profile change requests")
+	}
+
+	var pbRequests []*pb.ProfileChangeRequest
+	for _, changeRequest := range changeRequests {
+		pbRequest := &pb.ProfileChangeRequest{
+			Id:           changeRequest.ID.Hex(),
+			UserId:       changeRequest.UserID.String(),
+			Name:         changeRequest.Name,
+			Email:        changeRequest.Email,
+			Status:       changeRequest.Status,
+			RejectReason: changeRequest.RejectReason,
+			CreatedAt:    timestamppb.New(changeRequest.CreatedAt),
+		}
+		if changeRequest.ReviewedAt != nil {
+			pbRequest.ReviewedAt = timestamppb.New(*changeRequest.ReviewedAt)
+		}
+		pbRequests = append(pbRequests, pbRequest)
+	}
+
+	return &pb.ListProfileChangeRequestsResponse{
+		Requests:   pbRequests,
+		TotalCount: int32(totalCount),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *OrganizationService) ApproveProfileChange(ctx context.Context, req *pb.ApproveProfileChangeRequest) (*pb.ApproveProfileChangeResponse, error) {
+	orgAdmin, err := s.requireOrgAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	requestObjectID, err := primitive.ObjectIDFromHex(req.RequestId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request ID format")
+	}
+
+	var changeRequest models.ProfileChangeRequest
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		err := s.db.Changes.FindOneAndUpdate(ctx, bson.M{
+			"_id":    requestObjectID,
+			"org_id": orgAdmin.OrgID,
+			"status": models.ProfileChangeStatusPending,
+		}, bson.M{
+			"$set": bson.M{
+				"status":      models.ProfileChangeStatusApproved,
+				"reviewed_by": orgAdmin.ID,
+				"reviewed_at": now,
+				"updated_at":  now,
+			},
+		}).Decode(&changeRequest)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errChangeRequestNotFound
+			}
+			return err
+		}
+
+		changes := bson.M{"updated_at": now}
+		change := database.ProfileChange{UpdatedAt: now}
+		if changeRequest.Name != "" {
+			changes["name"] = changeRequest.Name
+			// The name is stored encrypted with the organization's key
+			change.Name, err = models.StoredMemberField(orgAdmin.OrgID, changeRequest.UserID, changeRequest.Name)
+			if err != nil {
+				return err
+			}
+		}
+		if changeRequest.Email != "" {
+			// The email may have been taken since the change was requested,
+			// by an account in any organization
+			taken, err := s.users.EmailTaken(tenancy.Unscoped(ctx, "email addresses are unique across organizations"), changeRequest.Email, changeRequest.UserID)
+			if err != nil {
+				return err
+			}
+			if taken {
+				return errEmailTaken
+			}
+			changes["email"] = changeRequest.Email
+			changes["email_verified"] = false
+			change.Email = changeRequest.Email
+		}
+
+		err = s.users.UpdateMemberProfile(ctx, changeRequest.UserID, *orgAdmin.OrgID, change)
+		if err == database.ErrNotFound {
+			return errUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		// The update above matched the member in the organization
+		if err := eventsource.Record(tenancy.Unscoped(ctx, "member checked by the update"), s.db, changeRequest.UserID, eventsource.TypeProfileChangeApproved); err != nil {
+			return err
+		}
+
+		if err := events.Enqueue(ctx, s.db, events.TypeUserUpdated, bson.M{
+			"user_id": changeRequest.UserID.String(),
+			"changes": changes,
+		}); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionProfileChangeApproved,
+			ActorID:  &orgAdmin.ID,
+			TargetID: changeRequest.UserID,
+			Details: bson.M{
+				"request_id": changeRequest.ID.Hex(),
+				"changes":    changes,
+			},
+		})
+	})
+
+	if err != nil {
+		switch err {
+		case errChangeRequestNotFound:
+			return nil, status.Errorf(codes.NotFound, "profile change request not found")
+		case errEmailTaken:
+			return nil, apierrors.EmailTaken("email is already taken")
+		case errUserNotFound:
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		if errors.Is(err, models.ErrTenantKeyDestroyed) {
+			return nil, memberDataError(err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to approve profile change")
+	}
+
+	// Retrieve updated user
+	updatedUser, err := s.users.FindMember(ctx, changeRequest.UserID, *orgAdmin.OrgID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve updated user")
+	}
+
+	s.notifyRequester(ctx, updatedUser, notifications.TemplateProfileChangeApproved, &changeRequest)
+
+	pbUser := &pb.User{
+		Id:          updatedUser.ID.String(),
+		Email:       updatedUser.Email,
+		Name:        updatedUser.Name,
+		CreatedAt:   timestamppb.New(updatedUser.CreatedAt),
+		UpdatedAt:   timestamppb.New(updatedUser.UpdatedAt),
+		IsActive:    updatedUser.IsActive,
+		IsDeleted:   updatedUser.IsDeleted,
+		ExternalIds: updatedUser.ExternalIDMap(),
+	}
+
+	return &pb.ApproveProfileChangeResponse{
+		User:    pbUser,
+		Message: "Profile change approved",
+	}, nil
+}
+
+func (s *OrganizationService) RejectProfileChange(ctx context.Context, req *pb.RejectProfileChangeRequest) (*pb.RejectProfileChangeResponse, error) {
+	orgAdmin, err := s.requireOrgAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	requestObjectID, err := primitive.ObjectIDFromHex(req.RequestId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request ID format")
+	}
+
+	req.Reason = utils.SanitizeString(req.Reason)
+	if req.Reason == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+
+	var changeRequest models.ProfileChangeRequest
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		err := s.db.Changes.FindOneAndUpdate(ctx, bson.M{
+			"_id":    requestObjectID,
+			"org_id": orgAdmin.OrgID,
+			"status": models.ProfileChangeStatusPending,
+		}, bson.M{
+			"$set": bson.M{
+				"status":        models.ProfileChangeStatusRejected,
+				"reject_reason": req.Reason,
+				"reviewed_by":   orgAdmin.ID,
+				"reviewed_at":   now,
+				"updated_at":    now,
+			},
+		}).Decode(&changeRequest)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errChangeRequestNotFound
+			}
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionProfileChangeRejected,
+			ActorID:  &orgAdmin.ID,
+			TargetID: changeRequest.UserID,
+			Details: bson.M{
+				"request_id": changeRequest.ID.Hex(),
+				"reason":     req.Reason,
+			},
+		})
+	})
+
+	if err != nil {
+		if err == errChangeRequestNotFound {
+			return nil, status.Errorf(codes.NotFound, "profile change request not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reject profile change")
+	}
+
+	user, err := s.users.FindMember(ctx, changeRequest.UserID, *orgAdmin.OrgID)
+	if err == nil {
+		changeRequest.RejectReason = req.Reason
+		s.notifyRequester(ctx, user, notifications.TemplateProfileChangeRejected, &changeRequest)
+	}
+
+	return &pb.RejectProfileChangeResponse{
+		Message: "Profile change rejected",
+	}, nil
+}
+
+// requireOrgAdmin authenticates the caller and checks that they administer
+// an organization. Org admins only ever act on their own organization.
+func (s *OrganizationService) requireOrgAdmin(ctx context.Context) (*models.User, error) {
+	userID, err := authz.CallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.PermissionDenied, "org admin role required")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to retrieve user")
+	}
+	if !user.IsActive {
+		return nil, status.Errorf(codes.PermissionDenied, "org admin role required")
+	}
+
+	if user.OrgID == nil || user.OrgRole != models.OrgRoleAdmin {
+		return nil, status.Errorf(codes.PermissionDenied, "org admin role required")
+	}
+	tenancy.SetTenant(ctx, *user.OrgID, user.ID)
+
+	return user, nil
+}
+
+// notifyRequester emails the member the outcome of their profile change
+func (s *OrganizationService) notifyRequester(ctx context.Context, user *models.User, templateName string, changeRequest *models.ProfileChangeRequest) {
+	var changes []string
+	if changeRequest.Name != "" {
+		changes = append(changes, "Name: "+changeRequest.Name)
+	}
+	if changeRequest.Email != "" {
+		changes = append(changes, "Email: "+changeRequest.Email)
+	}
+
+	data := map[string]string{
+		"Name":    user.Name,
+		"Changes": strings.Join(changes, "\n"),
+	}
+	if templateName == notifications.TemplateProfileChangeRejected {
+		data["Reason"] = changeRequest.RejectReason
+	}
+
+	msg, err := notifications.Render(templateName, user.Email, data)
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send %s to %s: %v", templateName, user.Email, err)
+	}
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/audit"
+	"user-management/authmd"
+	"user-management/authz"
+	"user-management/models"
+	"user-management/passkeys"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// errPasskeyRegistrationChanged aborts a registration that was restarted
+// while it was being verified
+var errPasskeyRegistrationChanged = errors.New("passkey registration changed")
+
+// BeginCredentialRegistration starts registering a passkey for the caller.
+// The returned options go to navigator.credentials.create(), and its result
+// to RegisterCredential.
+func (s *UserService) BeginCredentialRegistration(ctx context.Context, req *pb.BeginCredentialRegistrationRequest) (*pb.BeginCredentialRegistrationResponse, error) {
+	if _, err := authz.RequireSession(ctx); err != nil {
+		return nil, err
+	}
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	passkeyUser, err := s.passkeyUser(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve passkeys")
+	}
+
+	creationOptions, session, err := s.config.Passkeys.BeginRegistration(passkeyUser)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to start passkey registration")
+	}
+
+	// Starting again replaces a registration that wasn't finished
+	expiresAt := time.Now().Add(passkeys.RegistrationTimeout)
+	err = s.users.StartPasskeyRegistration(ctx, user.ID, models.PasskeyRegistration{
+		Session:   session,
+		ExpiresAt: expiresAt,
+	}, time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to start passkey registration")
+	}
+
+	return &pb.BeginCredentialRegistrationResponse{
+		CreationOptions: string(creationOptions),
+		ExpiresAt:       timestamppb.New(expiresAt),
+	}, nil
+}
+
+// RegisterCredential verifies the authenticator's response to the options
+// from BeginCredentialRegistration against the WebAuthn policy and saves
+// the passkey
+func (s *UserService) RegisterCredential(ctx context.Context, req *pb.RegisterCredentialRequest) (*pb.RegisterCredentialResponse, error) {
+	if _, err := authz.RequireSession(ctx); err != nil {
+		return nil, err
+	}
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Credential == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "credential is required")
+	}
+	req.Name = utils.SanitizeString(req.Name)
+	if req.Name != "" {
+		if err := utils.ValidateCredentialName(req.Name); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+		}
+	}
+	if user.PendingPasskey == nil || time.Now().After(user.PendingPasskey.ExpiresAt) {
+		return nil, status.Errorf(codes.FailedPrecondition, "no passkey registration in progress, call BeginCredentialRegistration first")
+	}
+
+	passkeyUser, err := s.passkeyUser(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve passkeys")
+	}
+
+	credential, err := s.config.Passkeys.FinishRegistration(passkeyUser, user.PendingPasskey.Session, []byte(req.Credential))
+	if err != nil {
+		var policyErr passkeys.PolicyError
+		switch {
+		case errors.As(err, &policyErr):
+			return nil, status.Errorf(codes.InvalidArgument, "%s", policyErr.Message)
+		case errors.Is(err, passkeys.ErrInvalidCredential):
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+		default:
+			return nil, status.Errorf(codes.Internal, "failed to verify passkey")
+		}
+	}
+
+	now := time.Now()
+	passkey := models.Passkey{
+		UserID:            user.ID,
+		Name:              req.Name,
+		CredentialID:      credential.ID,
+		PublicKey:         credential.PublicKey,
+		AttestationFormat: credential.AttestationFormat,
+		AAGUID:            credential.AAGUID.String(),
+		SignCount:         credential.SignCount,
+		Transports:        credential.Transports,
+		BackupEligible:    credential.BackupEligible,
+		BackupState:       credential.BackupState,
+		Discoverable:      credential.Discoverable,
+		CreatedAt:         now,
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		// The session is single use
+		err := s.users.ConsumePasskeyRegistration(ctx, user.ID, user.PendingPasskey.Session)
+		if err == mongo.ErrNoDocuments {
+			return errPasskeyRegistrationChanged
+		}
+		if err != nil {
+			return err
+		}
+
+		inserted, err := s.db.Passkeys.InsertOne(ctx, passkey)
+		if err != nil {
+			return err
+		}
+		passkey.ID = inserted.InsertedID.(primitive.ObjectID)
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionPasskeyRegistered,
+			ActorID:  &user.ID,
+			TargetID: user.ID,
+			Details: bson.M{
+				"passkey_id":         passkey.ID.Hex(),
+				"aaguid":             passkey.AAGUID,
+				"attestation_format": passkey.AttestationFormat,
+			},
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		if err == errPasskeyRegistrationChanged {
+			return nil, status.Errorf(codes.Aborted, "passkey registration was restarted, try again")
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, status.Errorf(codes.AlreadyExists, "passkey is already registered")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to save passkey")
+	}
+
+	return &pb.RegisterCredentialResponse{
+		Passkey: passkeyToProto(passkey),
+	}, nil
+}
+
+// authenticatedUser returns the caller's account
+func (s *UserService) authenticatedUser(ctx context.Context) (*models.User, error) {
+	user, _, err := s.authenticatedSession(ctx)
+	return user, err
+}
+
+// authenticatedSession is authenticatedUser, also returning the caller's
+// principal
+func (s *UserService) authenticatedSession(ctx context.Context) (*models.User, authmd.Principal, error) {
+	principal, err := authz.Caller(ctx)
+	if err != nil {
+		return nil, authmd.Principal{}, err
+	}
+
+	userID, err := models.ParseID(principal.UserID)
+	if err != nil {
+		return nil, authmd.Principal{}, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, authmd.Principal{}, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, authmd.Principal{}, status.Errorf(codes.Internal, "failed to retrieve user")
+	}
+
+	return user, principal, nil
+}
+
+// passkeyUser describes user to the relying party, with the passkeys they
+// already have
+func (s *UserService) passkeyUser(ctx context.Context, user *models.User) (passkeys.User, error) {
+	cursor, err := s.db.Passkeys.Find(ctx, bson.M{"user_id": user.ID},
+		options.Find().SetProjection(bson.M{"credential_id": 1}))
+	if err != nil {
+		return passkeys.User{}, err
+	}
+	var existing []models.Passkey
+	if err := cursor.All(ctx, &existing); err != nil {
+		return passkeys.User{}, err
+	}
+
+	passkeyUser := newPasskeyUser(user)
+	for _, passkey := range existing {
+		passkeyUser.CredentialIDs = append(passkeyUser.CredentialIDs, passkey.CredentialID)
+	}
+	return passkeyUser, nil
+}
+
+func passkeyToProto(passkey models.Passkey) *pb.Passkey {
+	return &pb.Passkey{
+		Id:                passkey.ID.Hex(),
+		Name:              passkey.Name,
+		Aaguid:            passkey.AAGUID,
+		AttestationFormat: passkey.AttestationFormat,
+		BackupEligible:    passkey.BackupEligible,
+		CreatedAt:         timestamppb.New(passkey.CreatedAt),
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/audit"
+	"user-management/authz"
+	"user-management/database"
+	"user-management/eventsource"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// errPasswordChanged aborts a password change when the password changed
+// after it was checked
+var errPasswordChanged = errors.New("password changed concurrently")
+
+// ChangePassword replaces the caller's password after checking the current
+// one. Every token issued so far stops working, including the caller's, so
+// a stolen session ends with the password change.
+func (s *UserService) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	if _, err := authz.RequireSession(ctx); err != nil {
+		return nil, err
+	}
+	// Callers may only change their own password
+	userID, err := authz.RequireSelf(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CurrentPassword == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "current password is required")
+	}
+	if err := s.config.PasswordPolicy.Validate(req.NewPassword); err != nil {
+		return nil, passwordError(err)
+	}
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if err == database.ErrNotFound {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to retrieve user")
+	}
+
+	// A stolen session alone must not be enough to take over the account
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		return nil, status.Errorf(codes.PermissionDenied, "invalid password")
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return nil, status.Errorf(codes.InvalidArgument, "new password must differ from the current password")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password")
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		// Only replace the password checked above
+		err := s.users.ChangePassword(ctx, userID, user.Password, hashedPassword, now)
+		if err == database.ErrNotFound {
+			return errPasswordChanged
+		}
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, userID, eventsource.TypePasswordChanged); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionPasswordChanged,
+			ActorID:   &userID,
+			TargetID:  userID,
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		if err == errPasswordChanged {
+			return nil, status.Errorf(codes.Aborted, "password changed concurrently, try again")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to change password")
+	}
+
+	return &pb.ChangePasswordResponse{
+		Message: "Password changed, sign in again with your new password",
+	}, nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/apierrors"
+	"user-management/audit"
+	"user-management/eventsource"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// passwordResetWindow is the period the reset rate limits count requests in
+const passwordResetWindow = time.Hour
+
+// errResetTokenUsed aborts the reset transaction when the token was used by
+// a concurrent request
+var errResetTokenUsed = errors.New("password reset token already used")
+
+// RequestPasswordReset emails a single-use reset link to the account
+func (s *AuthService) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	req.Email = utils.SanitizeString(req.Email)
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	clientIP := requestClientIP(ctx)
+
+	// Limits apply whether or not the account exists, so they reveal nothing
+	for _, limit := range []struct {
+		field string
+		value string
+		max   int
+	}{
+		{"email", req.Email, s.config.MaxPasswordResetsPerEmail},
+		{"ip_address", clientIP, s.config.MaxPasswordResetsPerIP},
+	} {
+		retryAfter, limited, err := s.passwordResetLimited(ctx, limit.field, limit.value, limit.max)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to check rate limit")
+		}
+		if limited {
+			return nil, apierrors.RateLimited("too many password reset requests, please try again later", retryAfter)
+		}
+	}
+
+	// The same response is returned whether or not the account exists, so
+	// the RPC can't be used to probe accounts
+	response := &pb.RequestPasswordResetResponse{
+		Message: "If the account exists, a reset link has been sent to its email address",
+	}
+
+	now := time.Now()
+	reset := models.PasswordReset{
+		Email:     req.Email,
+		IPAddress: clientIP,
+		ExpiresAt: now.Add(s.config.PasswordResetTTL),
+		CreatedAt: now,
+	}
+
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	var token string
+	if err == nil {
+		token, err = utils.GenerateSecureToken(32)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate reset token")
+		}
+		reset.UserID = &user.ID
+		reset.TokenHash = utils.HashToken(token)
+	}
+
+	if _, err := s.db.PasswordResets.InsertOne(ctx, reset); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create password reset")
+	}
+	if token == "" {
+		return response, nil
+	}
+
+	msg, err := notifications.Render(notifications.TemplatePasswordReset, user.Email, map[string]string{
+		"IPAddress": clientIP,
+		"ResetLink": fmt.Sprintf("%s?token=%s", s.config.PasswordResetURL, url.QueryEscape(token)),
+		"ExpiresIn": s.config.PasswordResetTTL.String(),
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send password reset link to %s: %v", user.Email, err)
+	}
+
+	return response, nil
+}
+
+// passwordResetLimited reports whether max reset requests with field set to
+// value were made within passwordResetWindow, and if so how long until the
+// oldest of them leaves the window
+func (s *AuthService) passwordResetLimited(ctx context.Context, field, value string, max int) (time.Duration, bool, error) {
+	var reset models.PasswordReset
+	err := s.db.PasswordResets.FindOne(ctx, bson.M{
+		field:        value,
+		"created_at": bson.M{"$gt": time.Now().Add(-passwordResetWindow)},
+	}, options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetSkip(int64(max-1))).Decode(&reset)
+	if err == mongo.ErrNoDocuments {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to check password reset limit: %v", err)
+	}
+
+	retryAfter := time.Until(reset.CreatedAt.Add(passwordResetWindow))
+	if retryAfter < 0 {
+		retryAfter = 0
+	}
+	return retryAfter, true, nil
+}
+
+// ResetPassword sets a new password with a token from a reset link. The
+// token works once, and every other session and reset link of the account
+// stops working.
+func (s *AuthService) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+	if err := s.config.PasswordPolicy.Validate(req.NewPassword); err != nil {
+		return nil, passwordError(err)
+	}
+
+	tokenHash := utils.HashToken(req.Token)
+
+	var reset models.PasswordReset
+	err := s.db.PasswordResets.FindOne(ctx, bson.M{
+		"token_hash": tokenHash,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&reset)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid or expired reset token, request a new one")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find password reset")
+	}
+
+	user, err := s.users.FindByID(ctx, *reset.UserID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid or expired reset token, request a new one")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	if utils.CheckPasswordHash(req.NewPassword, user.Password) {
+		return nil, status.Errorf(codes.InvalidArgument, "new password must differ from the current password")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password")
+	}
+
+	clientIP := requestClientIP(ctx)
+	userAgent := requestUserAgent(ctx)
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		// Using up the token in the same transaction makes a second request
+		// with it fail
+		result, err := s.db.PasswordResets.UpdateOne(ctx, bson.M{
+			"_id":     reset.ID,
+			"used_at": bson.M{"$exists": false},
+		}, bson.M{
+			"$set": bson.M{"used_at": now},
+		})
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return errResetTokenUsed
+		}
+
+		// Other links sent to the account stop working too
+		_, err = s.db.PasswordResets.UpdateMany(ctx, bson.M{
+			"user_id": user.ID,
+			"used_at": bson.M{"$exists": false},
+		}, bson.M{
+			"$set": bson.M{"used_at": now},
+		})
+		if err != nil {
+			return err
+		}
+
+		// Sessions issued before the reset are revoked
+		if err := s.users.ResetPassword(ctx, user.ID, hashedPassword, now); err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypePasswordReset); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionPasswordReset,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: clientIP,
+			UserAgent: userAgent,
+			Details:   bson.M{"reset_id": reset.ID.Hex()},
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, errResetTokenUsed) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid or expired reset token, request a new one")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reset password")
+	}
+
+	return &pb.ResetPasswordResponse{
+		Message: "Password has been reset, sign in with your new password",
+	}, nil
+}
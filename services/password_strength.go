@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// EvaluatePassword scores a candidate password against the password policy
+// so clients can show a strength meter while it is typed. Nothing is stored.
+func (s *AuthService) EvaluatePassword(ctx context.Context, req *pb.EvaluatePasswordRequest) (*pb.EvaluatePasswordResponse, error) {
+	if len(req.Password) > utils.MaxPasswordLength {
+		return nil, status.Errorf(codes.InvalidArgument, "password must be less than %d characters", utils.MaxPasswordLength)
+	}
+
+	policy := s.config.PasswordPolicy
+	strength := utils.EstimatePasswordStrength(req.Password, req.Email, req.Name)
+	unmet := policy.UnmetWithStrength(req.Password, strength)
+
+	return &pb.EvaluatePasswordResponse{
+		Score:             int32(strength.Score),
+		CrackTimeSeconds:  strength.CrackTimeSeconds,
+		CrackTimeDisplay:  utils.FormatCrackTime(strength.CrackTimeSeconds),
+		UnmetRequirements: unmet,
+		Requirements:      policy.Requirements(),
+		Acceptable:        req.Password != "" && len(unmet) == 0,
+		Warning:           strength.Warning,
+	}, nil
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+// pendingOperationLease is how long a server works on an operation before
+// another may take it over
+const pendingOperationLease = 5 * time.Minute
+
+// undoWindow is how long destructive admin actions wait before they run
+func (s *AdminService) undoWindow() time.Duration {
+	return time.Duration(s.config.Settings.Admin.UndoWindow)
+}
+
+// queueOperation stores op to run once the undo window has passed
+func (s *AdminService) queueOperation(ctx context.Context, op *models.PendingOperation) error {
+	now := time.Now()
+	op.Status = models.OperationPending
+	op.RunAt = now.Add(s.undoWindow())
+	op.LockedUntil = op.RunAt
+	op.CreatedAt = now
+
+	result, err := s.db.PendingOperations.InsertOne(ctx, op)
+	if err != nil {
+		return err
+	}
+	op.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// CancelOperation stops a pending operation from running, as long as its
+// undo window hasn't passed
+func (s *AdminService) CancelOperation(ctx context.Context, req *pb.CancelOperationRequest) (*pb.CancelOperationResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	operationID, err := primitive.ObjectIDFromHex(req.OperationId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid operation ID format")
+	}
+
+	now := time.Now()
+	var op models.PendingOperation
+	err = s.db.PendingOperations.FindOneAndUpdate(ctx, bson.M{
+		"_id":    operationID,
+		"status": models.OperationPending,
+		"run_at": bson.M{"$gt": now},
+	}, bson.M{
+		"$set": bson.M{
+			"status":       models.OperationCanceled,
+			"canceled_by":  admin.ID,
+			"completed_at": now,
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&op)
+	if err == mongo.ErrNoDocuments {
+		err = s.db.PendingOperations.FindOne(ctx, bson.M{"_id": operationID}).Decode(&op)
+		switch {
+		case err == mongo.ErrNoDocuments:
+			return nil, status.Errorf(codes.NotFound, "operation not found")
+		case err != nil:
+			return nil, status.Errorf(codes.Internal, "failed to find operation")
+		case op.Status == models.OperationPending:
+			return nil, status.Errorf(codes.FailedPrecondition, "the undo window has passed, the operation is running")
+		}
+		return nil, status.Errorf(codes.FailedPrecondition, "operation is already %s", op.Status)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to cancel operation")
+	}
+
+	log.Printf("Admin %s canceled %s operation %s", admin.Email, op.Kind, op.ID.Hex())
+
+	return &pb.CancelOperationResponse{
+		Operation: pendingOperationToProto(&op),
+		Message:   "Operation canceled",
+	}, nil
+}
+
+// RunPendingOperations runs the operations whose undo window has passed
+// and returns how many ran. An operation interrupted by a failure or a
+// restart runs again once its lease expires.
+func (s *AdminService) RunPendingOperations(ctx context.Context) (int, error) {
+	ran := 0
+	for {
+		op, err := s.claimPendingOperation(ctx)
+		if err == mongo.ErrNoDocuments {
+			return ran, nil
+		}
+		if err != nil {
+			return ran, err
+		}
+
+		if err := s.runPendingOperation(ctx, op); err != nil {
+			return ran, fmt.Errorf("operation %s: %v", op.ID.Hex(), err)
+		}
+		ran++
+	}
+}
+
+// claimPendingOperation leases an operation whose undo window has passed
+// and that no server is working on
+func (s *AdminService) claimPendingOperation(ctx context.Context) (*models.PendingOperation, error) {
+	now := time.Now()
+
+	var op models.PendingOperation
+	err := s.db.PendingOperations.FindOneAndUpdate(ctx, bson.M{
+		"status":       models.OperationPending,
+		"locked_until": bson.M{"$lte": now},
+	}, bson.M{
+		"$set": bson.M{"locked_until": now.Add(pendingOperationLease)},
+	}, options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "run_at", Value: 1}}).
+		SetReturnDocument(options.After),
+	).Decode(&op)
+	if err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+// runPendingOperation carries out op and marks it done in the same
+// transaction. An operation that no longer applies, such as the hard
+// delete of an account restored in the meantime, is marked failed.
+func (s *AdminService) runPendingOperation(ctx context.Context, op *models.PendingOperation) error {
+	err := s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		done := bson.M{
+			"status":       models.OperationDone,
+			"completed_at": time.Now(),
+		}
+		switch op.Kind {
+		case models.OperationHardDelete:
+			if err := s.hardDeleteUser(ctx, op.UserID, op.RequestedBy); err != nil {
+				return err
+			}
+		case models.OperationDeprovision:
+			if err := s.queueDeprovisioning(ctx, op.Deprovisioning); err != nil {
+				return err
+			}
+			done["deprovisioning._id"] = op.Deprovisioning.ID
+		default:
+			return fmt.Errorf("unknown operation kind %q", op.Kind)
+		}
+		_, err := s.db.PendingOperations.UpdateOne(ctx, bson.M{"_id": op.ID}, bson.M{"$set": done})
+		return err
+	})
+
+	var reason string
+	switch err {
+	case nil:
+		log.Printf("Ran %s operation %s requested by %s", op.Kind, op.ID.Hex(), op.RequestedBy.String())
+		return nil
+	case errUserNotFound:
+		reason = "user not found"
+	case errUserNotDeleted:
+		reason = "user is no longer deleted"
+	default:
+		return err
+	}
+
+	log.Printf("Skipped %s operation %s requested by %s: %s", op.Kind, op.ID.Hex(), op.RequestedBy.String(), reason)
+	_, err = s.db.PendingOperations.UpdateOne(ctx, bson.M{"_id": op.ID}, bson.M{
+		"$set": bson.M{
+			"status":       models.OperationFailed,
+			"error":        reason,
+			"completed_at": time.Now(),
+		},
+	})
+	return err
+}
+
+func pendingOperationToProto(op *models.PendingOperation) *pb.PendingOperation {
+	result := &pb.PendingOperation{
+		Id:          op.ID.Hex(),
+		Kind:        op.Kind,
+		UserId:      op.UserID.String(),
+		RequestedBy: op.RequestedBy.String(),
+		Status:      op.Status,
+		CreatedAt:   timestamppb.New(op.CreatedAt),
+		RunAt:       timestamppb.New(op.RunAt),
+		Error:       op.Error,
+	}
+	if op.Deprovisioning != nil {
+		result.OrgId = op.Deprovisioning.OrgID.Hex()
+		if !op.Deprovisioning.ID.IsZero() {
+			result.JobId = op.Deprovisioning.ID.Hex()
+		}
+	}
+	if op.CompletedAt != nil {
+		result.CompletedAt = timestamppb.New(*op.CompletedAt)
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/database"
+	"user-management/models"
+)
+
+// queueProfileChange stores a name or email change as a pending request when
+// the user's organization requires approval for it. It returns a nil request
+// when the change can be applied straight away. A new request replaces the
+// user's previous pending one.
+func (s *UserService) queueProfileChange(ctx context.Context, userID models.ID, name, email string) (*models.ProfileChangeRequest, *models.User, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if err == database.ErrNotFound {
+			return nil, nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, nil, status.Errorf(codes.Internal, "failed to retrieve user")
+	}
+
+	// Org admins manage their own profiles
+	if user.OrgID == nil || user.OrgRole == models.OrgRoleAdmin {
+		return nil, user, nil
+	}
+
+	var org models.Organization
+	err = s.db.Orgs.FindOne(ctx, bson.M{"_id": user.OrgID}).Decode(&org)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, user, nil
+		}
+		return nil, nil, status.Errorf(codes.Internal, "failed to retrieve organization")
+	}
+
+	if !org.Policy.RequireProfileChangeApproval {
+		return nil, user, nil
+	}
+
+	// Stored like the member's own fields
+	storedName, err := models.StoredMemberField(&org.ID, user.ID, name)
+	if err != nil {
+		return nil, nil, memberDataError(err)
+	}
+	storedEmail, err := models.StoredMemberField(&org.ID, user.ID, email)
+	if err != nil {
+		return nil, nil, memberDataError(err)
+	}
+
+	now := time.Now()
+	var changeRequest models.ProfileChangeRequest
+	err = s.db.Changes.FindOneAndUpdate(ctx, bson.M{
+		"user_id": user.ID,
+		"status":  models.ProfileChangeStatusPending,
+	}, bson.M{
+		"$set": bson.M{
+			"org_id":     org.ID,
+			"name":       storedName,
+			"email":      storedEmail,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"created_at":     now,
+			"schema_version": models.ProfileChangeRequestSchema.Version(),
+		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&changeRequest)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "failed to queue profile change")
+	}
+
+	return &changeRequest, user, nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/audit"
+	"user-management/authz"
+	"user-management/eventsource"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// recoveryCodeCount is the number of recovery codes in a set
+const recoveryCodeCount = 10
+
+// GenerateRecoveryCodes replaces the caller's recovery codes with a new set.
+// The codes are only ever returned here; the server keeps their hashes.
+func (s *UserService) GenerateRecoveryCodes(ctx context.Context, req *pb.GenerateRecoveryCodesRequest) (*pb.GenerateRecoveryCodesResponse, error) {
+	if _, err := authz.RequireSession(ctx); err != nil {
+		return nil, err
+	}
+	userID, err := authz.CallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Password == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "password is required")
+	}
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to retrieve user")
+	}
+
+	// A stolen session alone must not be enough to replace the codes
+	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		return nil, status.Errorf(codes.PermissionDenied, "invalid password")
+	}
+
+	recoveryCodes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range recoveryCodes {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate recovery codes")
+		}
+		recoveryCodes[i] = code
+		hashes[i] = utils.HashToken(utils.NormalizeRecoveryCode(code))
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		err := s.users.SetRecoveryCodes(ctx, userID, hashes, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, userID, eventsource.TypeRecoveryCodesGenerated); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionRecoveryCodesGenerated,
+			ActorID:  &userID,
+			TargetID: userID,
+			Details: bson.M{
+				"count":           recoveryCodeCount,
+				"replaced_unused": len(user.RecoveryCodeHashes),
+			},
+		})
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save recovery codes")
+	}
+
+	return &pb.GenerateRecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+		Message:       "Store these codes somewhere safe, each can be used once",
+	}, nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/apierrors"
+	"user-management/audit"
+	"user-management/auth"
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+// RefreshToken exchanges a refresh token for a new session token and the
+// next refresh token of the same session. A refresh token that was already
+// exchanged means a copy of it is in someone else's hands, so presenting it
+// again revokes the whole session and flags the account.
+func (s *AuthService) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "refresh_token is required")
+	}
+
+	refreshed, err := s.sessions.Refresh(ctx, req.RefreshToken)
+	switch {
+	case errors.Is(err, auth.ErrRefreshTokenReused):
+		log.Printf("Refresh token of session %s of user %s was reused, revoked the session", refreshed.SessionID, refreshed.UserID.String())
+		err = audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionRefreshTokenReused,
+			TargetID:  refreshed.UserID,
+			IPAddress: requestClientIP(ctx),
+			UserAgent: requestUserAgent(ctx),
+			Details:   bson.M{"session_id": refreshed.SessionID},
+		})
+		if err != nil {
+			log.Printf("Failed to audit refresh token reuse for user %s: %v", refreshed.UserID.String(), err)
+		}
+		return nil, apierrors.RefreshTokenReused("refresh token was already used, the session has been revoked")
+	case errors.Is(err, auth.ErrTokenExpired), errors.Is(err, auth.ErrSessionIdle):
+		return nil, status.Errorf(codes.Unauthenticated, "session expired, please sign in again")
+	case errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrTokenBlacklisted), errors.Is(err, auth.ErrTokenRevoked):
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
+	case err != nil:
+		log.Printf("Failed to refresh token: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to refresh token")
+	}
+
+	// The token was already exchanged, so the response goes out without the
+	// device rather than failing
+	devices, err := knownDevices(ctx, s.db, refreshed.UserID)
+	if err != nil {
+		log.Printf("Failed to find known devices of user %s: %v", refreshed.UserID.String(), err)
+	}
+	pbSession := sessionToProto(refreshed.Session, devices)
+	pbSession.Current = true
+
+	return &pb.RefreshTokenResponse{
+		Token:        refreshed.Token,
+		RefreshToken: refreshed.RefreshToken,
+		ExpiresAt:    timestamppb.New(refreshed.ExpiresAt),
+		Session:      pbSession,
+	}, nil
+}
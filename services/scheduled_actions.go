@@ -0,0 +1,369 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/database"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// scheduledActionLease is how long a server works on a scheduled action
+// before another may take it over
+const scheduledActionLease = 5 * time.Minute
+
+// ScheduleAction sets an action on an account to run at a later time. The
+// user is emailed a notice admin.scheduled_action_notice before it runs.
+func (s *AdminService) ScheduleAction(ctx context.Context, req *pb.ScheduleActionRequest) (*pb.ScheduleActionResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+	if !slices.Contains(models.ScheduledActions, req.Action) {
+		return nil, status.Errorf(codes.InvalidArgument, "action must be one of %s", strings.Join(models.ScheduledActions, ", "))
+	}
+	if userID == admin.ID {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot schedule actions on your own account")
+	}
+	now := time.Now()
+	if req.RunAt == nil || !req.RunAt.AsTime().After(now) {
+		return nil, status.Errorf(codes.InvalidArgument, "run_at must be in the future")
+	}
+
+	if _, err := s.users.FindByID(ctx, userID); err != nil {
+		if err == database.ErrNotFound {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	action := &models.ScheduledAction{
+		Action:      req.Action,
+		UserID:      userID,
+		Reason:      utils.SanitizeString(req.Reason),
+		RequestedBy: admin.ID,
+		Status:      models.ActionScheduled,
+		RunAt:       req.RunAt.AsTime(),
+		LockedUntil: req.RunAt.AsTime(),
+		CreatedAt:   now,
+	}
+	if notice := time.Duration(s.config.Settings.Admin.ScheduledActionNotice); notice > 0 {
+		// Actions due sooner than the notice period are notified at once
+		notifyAt := action.RunAt.Add(-notice)
+		if notifyAt.Before(now) {
+			notifyAt = now
+		}
+		action.NotifyAt = &notifyAt
+	}
+
+	result, err := s.db.ScheduledActions.InsertOne(ctx, action)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, status.Errorf(codes.AlreadyExists, "user already has a scheduled %s action, cancel it first", req.Action)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to schedule action")
+	}
+	action.ID = result.InsertedID.(primitive.ObjectID)
+
+	log.Printf("Admin %s scheduled %s of user %s at %s", admin.Email, action.Action, userID.String(), action.RunAt.UTC().Format(time.RFC3339))
+
+	return &pb.ScheduleActionResponse{
+		ScheduledAction: scheduledActionToProto(action),
+		Message:         "Action scheduled",
+	}, nil
+}
+
+// CancelScheduledAction stops a scheduled action from running
+func (s *AdminService) CancelScheduledAction(ctx context.Context, req *pb.CancelScheduledActionRequest) (*pb.CancelScheduledActionResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	actionID, err := primitive.ObjectIDFromHex(req.ActionId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid action ID format")
+	}
+
+	now := time.Now()
+	var action models.ScheduledAction
+	err = s.db.ScheduledActions.FindOneAndUpdate(ctx, bson.M{
+		"_id":          actionID,
+		"status":       models.ActionScheduled,
+		"locked_until": bson.M{"$gt": now},
+	}, bson.M{
+		"$set": bson.M{
+			"status":       models.ActionCanceled,
+			"canceled_by":  admin.ID,
+			"completed_at": now,
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&action)
+	if err == mongo.ErrNoDocuments {
+		err = s.db.ScheduledActions.FindOne(ctx, bson.M{"_id": actionID}).Decode(&action)
+		switch {
+		case err == mongo.ErrNoDocuments:
+			return nil, status.Errorf(codes.NotFound, "scheduled action not found")
+		case err != nil:
+			return nil, status.Errorf(codes.Internal, "failed to find scheduled action")
+		case action.Status == models.ActionScheduled:
+			return nil, status.Errorf(codes.FailedPrecondition, "the action is running")
+		}
+		return nil, status.Errorf(codes.FailedPrecondition, "action is already %s", action.Status)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to cancel scheduled action")
+	}
+
+	log.Printf("Admin %s canceled scheduled %s of user %s", admin.Email, action.Action, action.UserID.String())
+
+	return &pb.CancelScheduledActionResponse{
+		ScheduledAction: scheduledActionToProto(&action),
+		Message:         "Scheduled action canceled",
+	}, nil
+}
+
+// ListScheduledActions returns scheduled actions, soonest first
+func (s *AdminService) ListScheduledActions(ctx context.Context, req *pb.ListScheduledActionsRequest) (*pb.ListScheduledActionsResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	filter := bson.M{}
+	if req.UserId != "" {
+		userID, err := models.ParseID(req.UserId)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+		}
+		filter["user_id"] = userID
+	}
+	if req.Status != "" {
+		filter["status"] = req.Status
+	}
+
+	totalCount, err := s.db.ScheduledActions.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count scheduled actions")
+	}
+
+	cursor, err := s.db.ScheduledActions.Find(ctx, filter, options.Find().
+		SetSkip(int64((page-1)*pageSize)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "run_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find scheduled actions")
+	}
+	var actions []models.ScheduledAction
+	if err := cursor.All(ctx, &actions); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode scheduled actions")
+	}
+
+	response := &pb.ListScheduledActionsResponse{
+		TotalCount: int32(totalCount),
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for i := range actions {
+		response.ScheduledActions = append(response.ScheduledActions, scheduledActionToProto(&actions[i]))
+	}
+	return response, nil
+}
+
+// RunScheduledActions emails the notices that are due, then runs the
+// actions whose time has come, and returns how many notices and actions it
+// handled. An action interrupted by a failure or a restart runs again once
+// its lease expires.
+func (s *AdminService) RunScheduledActions(ctx context.Context) (int, error) {
+	handled := 0
+	for {
+		action, err := s.claimScheduledActionNotice(ctx)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			return handled, err
+		}
+		s.sendScheduledActionNotice(ctx, action)
+		handled++
+	}
+
+	for {
+		action, err := s.claimScheduledAction(ctx)
+		if err == mongo.ErrNoDocuments {
+			return handled, nil
+		}
+		if err != nil {
+			return handled, err
+		}
+
+		if err := s.runScheduledAction(ctx, action); err != nil {
+			return handled, fmt.Errorf("scheduled action %s: %v", action.ID.Hex(), err)
+		}
+		handled++
+	}
+}
+
+// claimScheduledActionNotice marks the notice of a scheduled action as
+// sent, so only one server sends it
+func (s *AdminService) claimScheduledActionNotice(ctx context.Context) (*models.ScheduledAction, error) {
+	now := time.Now()
+
+	var action models.ScheduledAction
+	err := s.db.ScheduledActions.FindOneAndUpdate(ctx, bson.M{
+		"status":      models.ActionScheduled,
+		"notify_at":   bson.M{"$lte": now},
+		"notified_at": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{"notified_at": now},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&action)
+	if err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
+
+// sendScheduledActionNotice emails the user about an upcoming action. A
+// notice that can't be sent is logged and not retried.
+func (s *AdminService) sendScheduledActionNotice(ctx context.Context, action *models.ScheduledAction) {
+	user, err := s.users.FindByID(ctx, action.UserID)
+	if err != nil {
+		log.Printf("Skipped notice of scheduled action %s: %v", action.ID.Hex(), err)
+		return
+	}
+
+	actionName := "deactivated"
+	if action.Action == models.ScheduledDelete {
+		actionName = "deleted"
+	}
+	msg, err := notifications.Render(notifications.TemplateScheduledAction, user.Email, map[string]string{
+		"Name":    user.Name,
+		"Action":  actionName,
+		"RunDate": action.RunAt.UTC().Format(time.DateOnly),
+		"RunAt":   action.RunAt.UTC().Format(time.RFC3339),
+		"Reason":  action.Reason,
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send %s to %s: %v", notifications.TemplateScheduledAction, user.Email, err)
+	}
+}
+
+// claimScheduledAction leases an action whose time has come and that no
+// server is working on
+func (s *AdminService) claimScheduledAction(ctx context.Context) (*models.ScheduledAction, error) {
+	now := time.Now()
+
+	var action models.ScheduledAction
+	err := s.db.ScheduledActions.FindOneAndUpdate(ctx, bson.M{
+		"status":       models.ActionScheduled,
+		"locked_until": bson.M{"$lte": now},
+	}, bson.M{
+		"$set": bson.M{"locked_until": now.Add(scheduledActionLease)},
+	}, options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "run_at", Value: 1}}).
+		SetReturnDocument(options.After),
+	).Decode(&action)
+	if err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
+
+// runScheduledAction carries out action and marks it done in the same
+// transaction, on behalf of the admin who scheduled it. An action on an
+// account deleted in the meantime is marked failed.
+func (s *AdminService) runScheduledAction(ctx context.Context, action *models.ScheduledAction) error {
+	err := s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		switch action.Action {
+		case models.ScheduledDeactivate:
+			if _, err := s.deactivateUser(ctx, action.UserID, action.RequestedBy, action.Reason); err != nil {
+				return err
+			}
+		case models.ScheduledDelete:
+			if err := softDeleteUser(ctx, s.db, s.users, action.UserID, action.RequestedBy, now); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown scheduled action %q", action.Action)
+		}
+		_, err := s.db.ScheduledActions.UpdateOne(ctx, bson.M{"_id": action.ID}, bson.M{
+			"$set": bson.M{
+				"status":       models.ActionDone,
+				"completed_at": now,
+			},
+		})
+		return err
+	})
+	if err == nil {
+		log.Printf("Ran scheduled %s of user %s requested by %s", action.Action, action.UserID.String(), action.RequestedBy.String())
+		return nil
+	}
+	if err != errUserNotFound {
+		return err
+	}
+
+	log.Printf("Skipped scheduled %s of user %s: user not found", action.Action, action.UserID.String())
+	_, err = s.db.ScheduledActions.UpdateOne(ctx, bson.M{"_id": action.ID}, bson.M{
+		"$set": bson.M{
+			"status":       models.ActionFailed,
+			"error":        "user not found",
+			"completed_at": time.Now(),
+		},
+	})
+	return err
+}
+
+func scheduledActionToProto(action *models.ScheduledAction) *pb.ScheduledAction {
+	result := &pb.ScheduledAction{
+		Id:          action.ID.Hex(),
+		Action:      action.Action,
+		UserId:      action.UserID.String(),
+		Reason:      action.Reason,
+		RequestedBy: action.RequestedBy.String(),
+		Status:      action.Status,
+		RunAt:       timestamppb.New(action.RunAt),
+		CreatedAt:   timestamppb.New(action.CreatedAt),
+		Error:       action.Error,
+	}
+	if action.NotifyAt != nil {
+		result.NotifyAt = timestamppb.New(*action.NotifyAt)
+	}
+	if action.NotifiedAt != nil {
+		result.NotifiedAt = timestamppb.New(*action.NotifiedAt)
+	}
+	if action.CompletedAt != nil {
+		result.CompletedAt = timestamppb.New(*action.CompletedAt)
+	}
+	return result
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/auth"
+	"user-management/authz"
+	"user-management/database"
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+const (
+	// sessionWatchInterval is how often session watches are polled for on
+	// a standalone server, and how long a failed change stream waits
+	// before it is opened again
+	sessionWatchInterval = 2 * time.Second
+	// sessionHeartbeatInterval is how often WatchSession reports a session
+	// that is still active, so proxies don't close an idle stream. The
+	// session is checked again first, which notices expiry and idleness.
+	sessionHeartbeatInterval = 30 * time.Second
+)
+
+// Session event types and the reasons a session ends
+const (
+	sessionEventActive = "active"
+	sessionEventEnded  = "ended"
+
+	sessionEndRevoked = "revoked"
+	sessionEndExpired = "expired"
+	sessionEndIdle    = "idle"
+)
+
+// WatchSession streams the state of the caller's session until it ends, so
+// clients can sign the user out as soon as the session is revoked instead
+// of on their next call. Watching doesn't count as session activity.
+func (s *UserService) WatchSession(req *pb.WatchSessionRequest, stream pb.UserService_WatchSessionServer) error {
+	ctx := stream.Context()
+	principal, err := authz.Caller(ctx)
+	if err != nil {
+		return err
+	}
+	userID, err := models.ParseID(principal.UserID)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+	token, err := auth.BearerTokenFromContext(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+
+	watch := s.sessionWatcher.add(principal.SessionID, userID)
+	defer s.sessionWatcher.remove(watch)
+
+	send := func(eventType, reason string) error {
+		return stream.Send(&pb.SessionEvent{
+			Type:   eventType,
+			Reason: reason,
+			Time:   timestamppb.Now(),
+		})
+	}
+	if err := send(sessionEventActive, ""); err != nil {
+		return err
+	}
+
+	heartbeat := time.NewTicker(sessionHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		beat := false
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.watchesStopped:
+			return status.Errorf(codes.Unavailable, "server is shutting down, watch the session again")
+		case <-watch.changed:
+		case <-heartbeat.C:
+			beat = true
+		}
+
+		err := s.sessions.SessionEnded(ctx, token)
+		if reason, ended := sessionEndReason(err); ended {
+			return send(sessionEventEnded, reason)
+		}
+		if err != nil {
+			// Most likely the database, check again on the next heartbeat
+			log.Printf("Failed to check session: %v", err)
+			continue
+		}
+
+		if beat {
+			if err := send(sessionEventActive, ""); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StopWatches ends every session watch, so they don't hold up a graceful
+// stop. Clients are told to watch again, on another server.
+func (s *UserService) StopWatches() {
+	s.stopWatches.Do(func() { close(s.watchesStopped) })
+}
+
+// sessionEndReason tells why a session ended from the error checking it,
+// and whether it did
+func sessionEndReason(err error) (string, bool) {
+	switch {
+	case err == nil:
+		return "", false
+	case errors.Is(err, auth.ErrTokenExpired):
+		return sessionEndExpired, true
+	case errors.Is(err, auth.ErrSessionIdle):
+		return sessionEndIdle, true
+	case errors.Is(err, auth.ErrTokenBlacklisted),
+		errors.Is(err, auth.ErrTokenRevoked),
+		errors.Is(err, auth.ErrInvalidToken),
+		errors.Is(err, auth.ErrUnsupportedTokenVersion):
+		return sessionEndRevoked, true
+	}
+	return "", false
+}
+
+// sessionWatcher tells session watches when their session or user may
+// have been revoked, so each watch doesn't query MongoDB on its own. On a
+// replica set it follows one change stream on the sessions and users; on
+// a standalone server it polls for every watch at once.
+type sessionWatcher struct {
+	db       *database.Database
+	sessions database.SessionRepository
+	users    database.UserRepository
+	stopped  <-chan struct{}
+	start    sync.Once
+
+	mu      sync.Mutex
+	watches map[*sessionWatch]struct{}
+}
+
+// sessionWatch is the session of one WatchSession stream
+type sessionWatch struct {
+	// sessionID is empty for tokens issued before sessions were tracked
+	sessionID string
+	userID    models.ID
+	startedAt time.Time
+	// changed is signalled when the session should be checked again
+	changed chan struct{}
+}
+
+func newSessionWatcher(db *database.Database, repos database.Repositories, stopped <-chan struct{}) *sessionWatcher {
+	return &sessionWatcher{
+		db:       db,
+		sessions: repos.Sessions,
+		users:    repos.Users,
+		stopped:  stopped,
+		watches:  map[*sessionWatch]struct{}{},
+	}
+}
+
+// add starts watching a session, and the watcher with the first one
+func (sw *sessionWatcher) add(sessionID string, userID models.ID) *sessionWatch {
+	sw.start.Do(func() { go sw.run() })
+
+	watch := &sessionWatch{
+		sessionID: sessionID,
+		userID:    userID,
+		startedAt: time.Now(),
+		changed:   make(chan struct{}, 1),
+	}
+	sw.mu.Lock()
+	sw.watches[watch] = struct{}{}
+	sw.mu.Unlock()
+	return watch
+}
+
+func (sw *sessionWatcher) remove(watch *sessionWatch) {
+	sw.mu.Lock()
+	delete(sw.watches, watch)
+	sw.mu.Unlock()
+}
+
+// notify signals the watches matching match. A watch that hasn't checked
+// its last signal yet isn't signalled twice.
+func (sw *sessionWatcher) notify(match func(watch *sessionWatch) bool) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	for watch := range sw.watches {
+		if !match(watch) {
+			continue
+		}
+		select {
+		case watch.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run follows changes until the server stops
+func (sw *sessionWatcher) run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-sw.stopped
+		cancel()
+	}()
+
+	if sw.db.SupportsChangeStreams() {
+		sw.follow(ctx)
+	} else {
+		sw.poll(ctx)
+	}
+}
+
+// follow signals watches from a change stream, opening it again when it
+// fails. Every watch is signalled after a failure, since changes may have
+// been missed.
+func (sw *sessionWatcher) follow(ctx context.Context) {
+	for {
+		err := sw.followStream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Session change stream failed, opening it again: %v", err)
+		sw.notify(func(*sessionWatch) bool { return true })
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(sessionWatchInterval):
+		}
+	}
+}
+
+// followStream signals the watches of the sessions and users that change
+// in ways that may end a session, until the stream fails
+func (sw *sessionWatcher) followStream(ctx context.Context) error {
+	sessions, users := sw.db.Sessions.Name(), sw.db.Users.Name()
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"ns.coll": bson.M{"$in": bson.A{sessions, users}},
+		"$or": bson.A{
+			bson.M{"operationType": bson.M{"$in": bson.A{"replace", "delete"}}},
+			bson.M{"updateDescription.updatedFields.revoked_at": bson.M{"$exists": true}},
+			bson.M{"updateDescription.updatedFields.tokens_valid_after": bson.M{"$exists": true}},
+		},
+	}}}}
+	stream, err := sw.db.DB.Watch(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var event struct {
+			NS struct {
+				Coll string `bson:"coll"`
+			} `bson:"ns"`
+			DocumentKey struct {
+				ID bson.RawValue `bson:"_id"`
+			} `bson:"documentKey"`
+		}
+		if err := stream.Decode(&event); err != nil {
+			return err
+		}
+
+		switch event.NS.Coll {
+		case sessions:
+			sessionID, ok := event.DocumentKey.ID.StringValueOK()
+			if !ok {
+				continue
+			}
+			sw.notify(func(watch *sessionWatch) bool { return watch.sessionID == sessionID })
+		case users:
+			var userID models.ID
+			if err := event.DocumentKey.ID.Unmarshal(&userID); err != nil {
+				continue
+			}
+			sw.notify(func(watch *sessionWatch) bool { return watch.userID == userID })
+		}
+	}
+	return stream.Err()
+}
+
+// poll signals watches from one query for the revoked sessions and one
+// for the revoked users of all watches, every sessionWatchInterval
+func (sw *sessionWatcher) poll(ctx context.Context) {
+	ticker := time.NewTicker(sessionWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := sw.pollOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to poll watched sessions: %v", err)
+		}
+	}
+}
+
+func (sw *sessionWatcher) pollOnce(ctx context.Context) error {
+	sw.mu.Lock()
+	var sessionIDs []string
+	var userIDs []models.ID
+	var since time.Time
+	for watch := range sw.watches {
+		if watch.sessionID != "" {
+			sessionIDs = append(sessionIDs, watch.sessionID)
+		}
+		userIDs = append(userIDs, watch.userID)
+		if since.IsZero() || watch.startedAt.Before(since) {
+			since = watch.startedAt
+		}
+	}
+	sw.mu.Unlock()
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	revokedSessions, err := sw.sessions.Revoked(ctx, sessionIDs)
+	if err != nil {
+		return err
+	}
+
+	// Revocations before a watch started failed its authentication
+	usersRevokedAt, err := sw.users.RevokedSince(ctx, userIDs, since)
+	if err != nil {
+		return err
+	}
+
+	if len(revokedSessions) == 0 && len(usersRevokedAt) == 0 {
+		return nil
+	}
+	revoked := make(map[string]bool, len(revokedSessions))
+	for _, sessionID := range revokedSessions {
+		revoked[sessionID] = true
+	}
+	sw.notify(func(watch *sessionWatch) bool {
+		if revoked[watch.sessionID] {
+			return true
+		}
+		revokedAt, ok := usersRevokedAt[watch.userID]
+		return ok && !revokedAt.Before(watch.startedAt)
+	})
+	return nil
+}
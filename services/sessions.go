@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/audit"
+	"user-management/database"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// ListSessions returns the caller's signed-in sessions, most recently used
+// first
+func (s *UserService) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	user, principal, err := s.authenticatedSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessions.ActiveSessions(ctx, user.ID, user.TokensValidAfter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list sessions")
+	}
+
+	devices, err := knownDevices(ctx, s.db, user.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list sessions")
+	}
+
+	pbSessions := make([]*pb.Session, 0, len(sessions))
+	for _, session := range sessions {
+		pbSession := sessionToProto(session, devices)
+		pbSession.Current = session.ID == principal.SessionID
+		pbSessions = append(pbSessions, pbSession)
+	}
+
+	return &pb.ListSessionsResponse{
+		Sessions: pbSessions,
+	}, nil
+}
+
+// RevokeSession signs one of the caller's sessions out. Revoking the
+// current session works like Logout.
+func (s *UserService) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "session ID is required")
+	}
+
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.sessions.RevokeSession(ctx, user.ID, req.SessionId); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionSessionRevoked,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: requestClientIP(ctx),
+			UserAgent: requestUserAgent(ctx),
+			Details:   bson.M{"session_id": req.SessionId},
+		})
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to revoke session")
+	}
+
+	return &pb.RevokeSessionResponse{
+		Message: "Session revoked",
+	}, nil
+}
+
+// knownDevices maps the fingerprints of the user's known devices to them
+func knownDevices(ctx context.Context, db *database.Database, userID models.ID) (map[string]models.Device, error) {
+	cursor, err := db.Devices.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var devices []models.Device
+	if err := cursor.All(ctx, &devices); err != nil {
+		return nil, err
+	}
+
+	byFingerprint := make(map[string]models.Device, len(devices))
+	for _, device := range devices {
+		byFingerprint[device.Fingerprint] = device
+	}
+	return byFingerprint, nil
+}
+
+// sessionToProto converts a session, with the device it was started on
+// looked up in devices
+func sessionToProto(session models.Session, devices map[string]models.Device) *pb.Session {
+	device, registered := devices[utils.DeviceFingerprint(session.UserAgent, session.IPAddress)]
+	return &pb.Session{
+		Id:               session.ID,
+		DeviceName:       device.Name,
+		UserAgent:        session.UserAgent,
+		IpAddress:        session.IPAddress,
+		IssuedAt:         timestamppb.New(session.IssuedAt),
+		LastActivityAt:   timestamppb.New(session.LastActivityAt),
+		ExpiresAt:        timestamppb.New(session.ExpiresAt),
+		DeviceRegistered: registered,
+	}
+}
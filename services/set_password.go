@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/apierrors"
+	"user-management/audit"
+	"user-management/authz"
+	"user-management/database"
+	"user-management/eventsource"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// setPasswordLoginAge is how recently the caller must have signed in to
+// add a password, so an old stolen session can't add one
+const setPasswordLoginAge = 10 * time.Minute
+
+// errPasswordAlreadySet aborts setting a password on an account that got
+// one concurrently
+var errPasswordAlreadySet = errors.New("password already set")
+
+// StartSetPassword sends a code to the caller's email address, which
+// SetPassword accepts to add a password to an account that signs in
+// without one, such as through single sign-on
+func (s *AuthService) StartSetPassword(ctx context.Context, req *pb.StartSetPasswordRequest) (*pb.StartSetPasswordResponse, error) {
+	user, err := s.passwordlessCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.StartSetPasswordResponse{
+		Message: "A confirmation code has been sent to " + user.Email,
+	}
+
+	code, err := s.issueEmailChallenge(ctx, user.ID, models.ChallengePurposeSetPassword)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create confirmation code")
+	}
+	if code == "" {
+		return response, nil
+	}
+
+	msg, err := notifications.Render(notifications.TemplateSetPassword, user.Email, map[string]string{
+		"Code":      code,
+		"ExpiresIn": emailChallengeTTL.String(),
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send set password code to %s: %v", user.Email, err)
+	}
+
+	return response, nil
+}
+
+// SetPassword adds a password to the caller's account with a code from
+// StartSetPassword. The account's other login methods and sessions are
+// kept.
+func (s *AuthService) SetPassword(ctx context.Context, req *pb.SetPasswordRequest) (*pb.SetPasswordResponse, error) {
+	if req.Code == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "code is required")
+	}
+	if err := s.config.PasswordPolicy.Validate(req.NewPassword); err != nil {
+		return nil, passwordError(err)
+	}
+
+	user, err := s.passwordlessCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.verifyEmailChallenge(ctx, user.ID, models.ChallengePurposeSetPassword, req.Code)
+	if err != nil {
+		if err == errChallengeExpired || err == errChallengeMismatch {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid or expired code, request a new one")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to check confirmation code")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password")
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		// Checked in the filter so a password set concurrently is never
+		// replaced without the current one
+		err := s.users.SetFirstPassword(ctx, user.ID, hashedPassword, now)
+		if err == database.ErrNotFound {
+			return errPasswordAlreadySet
+		}
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypePasswordSet); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionPasswordSet,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: requestClientIP(ctx),
+			UserAgent: requestUserAgent(ctx),
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		if err == errPasswordAlreadySet {
+			return nil, status.Errorf(codes.FailedPrecondition, "account already has a password, use ChangePassword")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to set password")
+	}
+
+	s.deleteEmailChallenge(ctx, challenge)
+
+	return &pb.SetPasswordResponse{
+		Message: "Password set, you can now also sign in with your email address and password",
+	}, nil
+}
+
+// passwordlessCaller returns the caller's account, which must have no
+// password yet, when they signed in recently enough to add one
+func (s *AuthService) passwordlessCaller(ctx context.Context) (*models.User, error) {
+	user, principal, err := s.authenticatedSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Password != "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "account already has a password, use ChangePassword")
+	}
+	if !authz.RecentLogin(principal, setPasswordLoginAge) {
+		return nil, apierrors.ReauthenticationRequired("sign in again to set a password")
+	}
+
+	return user, nil
+}
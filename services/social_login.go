@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/alerts"
+	"user-management/apierrors"
+	"user-management/audit"
+	"user-management/auth"
+	"user-management/events"
+	"user-management/eventsource"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/provisioning"
+	"user-management/session"
+	"user-management/social"
+	"user-management/utils"
+)
+
+var (
+	// errSocialEmailUnverified rejects identities whose provider hasn't
+	// verified their email address, which could belong to someone else
+	errSocialEmailUnverified = status.Errorf(codes.PermissionDenied, "verify your email address with the provider before signing in with it")
+	// errSocialSSODomain rejects identities in the domain of an
+	// organization with an identity provider, which signs its members in
+	errSocialSSODomain = status.Errorf(codes.FailedPrecondition, "your organization requires signing in with single sign-on")
+	// errSocialAccountUnverified refuses to link an identity to an account
+	// whose email address was never verified, since whoever registered it
+	// may not own the address
+	errSocialAccountUnverified = status.Errorf(codes.FailedPrecondition, "sign in with your password and verify your email address first")
+)
+
+// LoginWithProvider signs a user in with a token from Google or GitHub.
+// The identity's account is found by the identity, or by the verified
+// email address the provider gives, which links the identity to it. A new
+// account without a password is created otherwise.
+func (s *AuthService) LoginWithProvider(ctx context.Context, req *pb.LoginWithProviderRequest) (*pb.LoginWithProviderResponse, error) {
+	if !social.Known(req.Provider) {
+		return nil, status.Errorf(codes.InvalidArgument, "provider must be %s or %s", social.ProviderGoogle, social.ProviderGitHub)
+	}
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+	if !s.config.Social.Enabled(req.Provider) {
+		return nil, status.Errorf(codes.FailedPrecondition, "signing in with %s is not enabled", req.Provider)
+	}
+
+	identity, err := s.config.Social.Verify(ctx, req.Provider, req.Token)
+	if err != nil {
+		log.Printf("Login with %s failed: %v", req.Provider, err)
+		if errors.Is(err, social.ErrInvalidToken) {
+			return nil, status.Errorf(codes.Unauthenticated, "the provider did not accept the token")
+		}
+		return nil, status.Errorf(codes.Unavailable, "provider is unavailable")
+	}
+
+	user, linked, err := s.socialUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	created := user == nil
+	if created {
+		if user, err = s.createSocialUser(ctx, identity); err != nil {
+			return nil, err
+		}
+	}
+
+	if !user.IsActive {
+		return nil, status.Errorf(codes.PermissionDenied, "account is deactivated/deleted")
+	}
+	if lock := user.ActiveLock(time.Now()); lock != nil {
+		return nil, lockError(lock)
+	}
+	if err := s.requireNoEnforcedSSO(ctx, user); err != nil {
+		return nil, err
+	}
+
+	clientIP := requestClientIP(ctx)
+	userAgent := requestUserAgent(ctx)
+
+	// The provider proves the first factor. Accounts with 2FA still need
+	// a code, so a provider account alone can't take them over.
+	twoFactorUsed := false
+	if user.TwoFactor != nil {
+		if req.TwoFactorCode == "" {
+			return &pb.LoginWithProviderResponse{
+				TwoFactorRequired: true,
+				Message:           "Enter the code from your authenticator app",
+			}, nil
+		}
+		_, err := verifySecondFactor(ctx, s.db, s.users, s.config.TwoFactorCipher, user, req.TwoFactorCode, clientIP, userAgent)
+		if err == errInvalidTwoFactorCode {
+			s.rateLimiter.RecordLoginAttempt(ctx, user.Email, clientIP, false)
+			s.recordFailedLogin(ctx, user)
+			s.config.Alerts.Record(alerts.EventLoginFailed)
+			return nil, status.Errorf(codes.Unauthenticated, "invalid two-factor code")
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to check two-factor code")
+		}
+		twoFactorUsed = true
+		s.clearFailedLogins(ctx, user)
+	}
+
+	// Linked once the account's own checks have passed
+	if !linked && !created {
+		if err := s.linkSocialIdentity(ctx, user, identity); err != nil {
+			return nil, err
+		}
+	}
+
+	deadline, err := s.twoFactorEnrollmentDeadline(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check two-factor policy")
+	}
+	enrollmentRequired := deadline != nil && !time.Now().Before(*deadline)
+
+	var token, refreshToken string
+	if enrollmentRequired {
+		token, _, err = s.sessions.GenerateScopedToken(user.ID.String(), user.Email, auth.ScopeTwoFactorEnrollment, authMethodSocial)
+	} else {
+		token, refreshToken, err = s.sessions.GenerateToken(ctx, user.ID.String(), user.Email, session.Info{
+			UserAgent: userAgent,
+			IPAddress: clientIP,
+			Method:    authMethodSocial,
+		})
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate token")
+	}
+
+	newDevice := s.recordDevice(ctx, user.ID, userAgent, clientIP)
+	if newDevice {
+		s.notifyNewDevice(ctx, user, userAgent, clientIP)
+	}
+	err = audit.Record(ctx, s.db, models.AuditLog{
+		Action:    audit.ActionLoginSucceeded,
+		ActorID:   &user.ID,
+		TargetID:  user.ID,
+		IPAddress: clientIP,
+		UserAgent: userAgent,
+		Details: bson.M{
+			"method": identity.Provider,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to audit login for user %s: %v", user.ID.String(), err)
+	}
+
+	response := &pb.LoginWithProviderResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		User: &pb.User{
+			Id:          user.ID.String(),
+			Email:       user.Email,
+			Name:        user.Name,
+			CreatedAt:   timestamppb.New(user.CreatedAt),
+			UpdatedAt:   timestamppb.New(user.UpdatedAt),
+			IsActive:    user.IsActive,
+			IsDeleted:   user.IsDeleted,
+			ExternalIds: user.ExternalIDMap(),
+		},
+		Message:         "Login success",
+		SecurityContext: s.loginSecurityContext(user, newDevice, twoFactorUsed, enrollmentRequired, time.Now()),
+		NewDevice:       newDevice,
+		Created:         created,
+	}
+	if deadline != nil {
+		response.TwoFactorEnrollmentDeadline = timestamppb.New(*deadline)
+	}
+	if enrollmentRequired {
+		response.TwoFactorEnrollmentRequired = true
+		response.Message = "Set up two-factor authentication to continue"
+	}
+	return response, nil
+}
+
+// socialUser returns the account of identity, and whether the identity is
+// already linked to it. Identities without an account return nil once
+// checked for a new one.
+func (s *AuthService) socialUser(ctx context.Context, identity *social.Identity) (*models.User, bool, error) {
+	user, err := s.users.RecordIdentityLogin(ctx, identity.Issuer, identity.Subject, time.Now())
+	if err == nil {
+		return user, true, nil
+	} else if err != mongo.ErrNoDocuments {
+		return nil, false, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, false, errSocialEmailUnverified
+	}
+
+	// Organizations with an identity provider manage their domain's
+	// accounts through it
+	org, err := s.ssoOrganization(ctx, identity.Email)
+	if err != nil {
+		return nil, false, status.Errorf(codes.Internal, "failed to retrieve organization")
+	}
+	if org != nil {
+		return nil, false, errSocialSSODomain
+	}
+
+	user, err = s.users.FindByEmail(ctx, identity.Email)
+	if err == mongo.ErrNoDocuments {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, status.Errorf(codes.Internal, "failed to find user")
+	}
+	if !user.EmailVerified {
+		return nil, false, errSocialAccountUnverified
+	}
+	return user, false, nil
+}
+
+// linkSocialIdentity adds identity to user's account so later logins find
+// it even if either email address changes
+func (s *AuthService) linkSocialIdentity(ctx context.Context, user *models.User, identity *social.Identity) error {
+	now := time.Now()
+	linked := models.LinkedIdentity{
+		Provider:    identity.Provider,
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LinkedAt:    now,
+		LastLoginAt: &now,
+	}
+
+	err := s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.users.LinkIdentity(ctx, user.ID, linked, now); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionIdentityLinked,
+			ActorID:  &user.ID,
+			TargetID: user.ID,
+			Details: bson.M{
+				"provider": identity.Provider,
+				"issuer":   identity.Issuer,
+			},
+		})
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return status.Errorf(codes.Aborted, "identity was linked concurrently, try again")
+		}
+		return status.Errorf(codes.Internal, "failed to link identity")
+	}
+
+	user.Identities = append(user.Identities, linked)
+	return nil
+}
+
+// createSocialUser creates an account without a password for identity,
+// whose email address the provider verified
+func (s *AuthService) createSocialUser(ctx context.Context, identity *social.Identity) (*models.User, error) {
+	userID, err := s.config.UserIDs.NewID()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate user ID")
+	}
+
+	name := utils.SanitizeString(identity.Name)
+	if utils.ValidateName(name, "name") != nil {
+		name = provisioning.NameFromEmail(identity.Email)
+	}
+
+	now := time.Now()
+	user := models.User{
+		ID:            userID,
+		Email:         identity.Email,
+		Name:          name,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		IsActive:      true,
+		EmailVerified: true,
+		Identities: []models.LinkedIdentity{{
+			Provider:    identity.Provider,
+			Issuer:      identity.Issuer,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			LinkedAt:    now,
+			LastLoginAt: &now,
+		}},
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.users.Insert(ctx, user); err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypeRegistered); err != nil {
+			return err
+		}
+		err := audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionIdentityLinked,
+			ActorID:  &user.ID,
+			TargetID: user.ID,
+			Details: bson.M{
+				"provider": identity.Provider,
+				"issuer":   identity.Issuer,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		return events.Enqueue(ctx, s.db, events.TypeUserRegistered, bson.M{
+			"user_id": user.ID.String(),
+			"email":   user.Email,
+			"name":    user.Name,
+			"source":  identity.Provider,
+		})
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// A deleted account keeps its email address
+			return nil, apierrors.EmailTaken("email already exists")
+		}
+		log.Printf("Failed to create account for %s identity: %v", identity.Provider, err)
+		return nil, status.Errorf(codes.Internal, "failed to create account")
+	}
+	s.config.Alerts.Record(alerts.EventUserRegistered)
+
+	return &user, nil
+}
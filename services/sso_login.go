@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/audit"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/provisioning"
+	"user-management/session"
+	"user-management/sso"
+	"user-management/utils"
+)
+
+// ssoStateBytes is the entropy of the state, nonce and code verifier of an
+// SSO login
+const ssoStateBytes = 32
+
+var (
+	// errSSORequired rejects password logins by members of organizations
+	// that enforce SSO
+	errSSORequired = status.Errorf(codes.FailedPrecondition, "your organization requires signing in with single sign-on")
+	// errNoSSOAccount rejects identities without an account
+	errNoSSOAccount = status.Errorf(codes.PermissionDenied, "no account for this identity, ask your organization's admin for access")
+	// errSSOBlocked rejects identities the organization's provisioning
+	// rules block
+	errSSOBlocked = status.Errorf(codes.PermissionDenied, "your organization doesn't allow this account to sign in")
+)
+
+// ssoOrganization returns the organization with an identity provider that
+// owns the domain of email, or nil when there is none
+func (s *AuthService) ssoOrganization(ctx context.Context, email string) (*models.Organization, error) {
+	domain := sso.EmailDomain(email)
+	if domain == "" {
+		return nil, nil
+	}
+
+	var org models.Organization
+	err := s.db.Orgs.FindOne(ctx, bson.M{"sso.domains": domain}).Decode(&org)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// startSSOLogin records a login for org's identity provider and returns the
+// provider page to send the user to
+func (s *AuthService) startSSOLogin(ctx context.Context, org *models.Organization) (string, error) {
+	state, err := utils.GenerateSecureToken(ssoStateBytes)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.GenerateSecureToken(ssoStateBytes)
+	if err != nil {
+		return "", err
+	}
+	// A PKCE code verifier, 43 characters as RFC 7636 requires at least
+	verifier, err := utils.GenerateSecureToken(ssoStateBytes)
+	if err != nil {
+		return "", err
+	}
+
+	redirectURL, err := s.config.SSO.AuthCodeURL(ctx, ssoConnection(org.SSO), state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	_, err = s.db.SSOLogins.InsertOne(ctx, models.SSOLogin{
+		StateHash:    utils.HashToken(state),
+		OrgID:        org.ID,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		IPAddress:    requestClientIP(ctx),
+		UserAgent:    requestUserAgent(ctx),
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(s.config.SSOLoginTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return redirectURL, nil
+}
+
+// CompleteSSOLogin signs in the user an organization's identity provider
+// sent back. The identity must already be linked to an account, belong to
+// a member of the organization with the same verified email address, or be
+// provisioned by the organization's rules.
+func (s *AuthService) CompleteSSOLogin(ctx context.Context, req *pb.CompleteSSOLoginRequest) (*pb.CompleteSSOLoginResponse, error) {
+	if req.State == "" || req.Code == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "state and code are required")
+	}
+
+	// A state works once
+	var login models.SSOLogin
+	err := s.db.SSOLogins.FindOneAndDelete(ctx, bson.M{
+		"state_hash": utils.HashToken(req.State),
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&login)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid or expired login, start again")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find login")
+	}
+
+	var org models.Organization
+	err = s.db.Orgs.FindOne(ctx, bson.M{"_id": login.OrgID}).Decode(&org)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, status.Errorf(codes.Internal, "failed to retrieve organization")
+	}
+	if err != nil || org.SSO == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "the organization no longer uses single sign-on")
+	}
+
+	identity, err := s.config.SSO.Exchange(ctx, ssoConnection(org.SSO), req.Code, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		if errors.Is(err, sso.ErrLoginFailed) {
+			log.Printf("SSO login for organization %s failed: %v", org.ID.Hex(), err)
+			return nil, status.Errorf(codes.Unauthenticated, "the identity provider did not confirm the login")
+		}
+		log.Printf("SSO login for organization %s failed: %v", org.ID.Hex(), err)
+		return nil, status.Errorf(codes.Unavailable, "identity provider is unavailable")
+	}
+
+	user, err := s.ssoUser(ctx, &org, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, status.Errorf(codes.PermissionDenied, "account is deactivated/deleted")
+	}
+	if lock := user.ActiveLock(time.Now()); lock != nil {
+		return nil, lockError(lock)
+	}
+
+	// Group changes at the provider reach the account at every login.
+	// Stale roles could grant too much, so the login fails without them.
+	if err := s.refreshMappedRoles(ctx, &org, user, identity); err != nil {
+		log.Printf("Failed to update mapped roles of user %s: %v", user.ID.String(), err)
+		return nil, status.Errorf(codes.Internal, "failed to update roles")
+	}
+
+	token, refreshToken, err := s.sessions.GenerateToken(ctx, user.ID.String(), user.Email, session.Info{
+		UserAgent: login.UserAgent,
+		IPAddress: login.IPAddress,
+		Method:    authMethodSSO,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate token")
+	}
+
+	newDevice := s.recordDevice(ctx, user.ID, login.UserAgent, login.IPAddress)
+	if newDevice {
+		s.notifyNewDevice(ctx, user, login.UserAgent, login.IPAddress)
+	}
+	err = audit.Record(ctx, s.db, models.AuditLog{
+		Action:    audit.ActionLoginSucceeded,
+		ActorID:   &user.ID,
+		TargetID:  user.ID,
+		IPAddress: login.IPAddress,
+		UserAgent: login.UserAgent,
+		Details: bson.M{
+			"method": authMethodSSO,
+			"org_id": org.ID.Hex(),
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to audit login for user %s: %v", user.ID.String(), err)
+	}
+
+	return &pb.CompleteSSOLoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		User: &pb.User{
+			Id:          user.ID.String(),
+			Email:       user.Email,
+			Name:        user.Name,
+			CreatedAt:   timestamppb.New(user.CreatedAt),
+			UpdatedAt:   timestamppb.New(user.UpdatedAt),
+			IsActive:    user.IsActive,
+			IsDeleted:   user.IsDeleted,
+			ExternalIds: user.ExternalIDMap(),
+		},
+		Message: "Login success",
+		// The identity provider is responsible for second factors
+		SecurityContext: s.loginSecurityContext(user, newDevice, false, false, time.Now()),
+		NewDevice:       newDevice,
+	}, nil
+}
+
+// ssoUser returns the account of identity. On their first SSO login the
+// identity is checked against org's provisioning rules, then linked to the
+// member of org with the same email address or provisioned an account.
+func (s *AuthService) ssoUser(ctx context.Context, org *models.Organization, identity *sso.Identity) (*models.User, error) {
+	now := time.Now()
+
+	user, err := s.users.RecordIdentityLogin(ctx, identity.Issuer, identity.Subject, now)
+	if err == nil {
+		return user, nil
+	} else if err != mongo.ErrNoDocuments {
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	// Only trust the email of a provider for the domains its organization
+	// owns, so one organization's provider can't claim another's accounts
+	if !identity.EmailVerified || !containsString(org.SSO.Domains, sso.EmailDomain(identity.Email)) {
+		return nil, errNoSSOAccount
+	}
+
+	decision := provisioning.Evaluate(org.Provisioning, identity.Claims)
+	if !decision.Allowed {
+		log.Printf("SSO login of %s to organization %s refused: %s", identity.Email, org.ID.Hex(), decision.Reason)
+		return nil, errSSOBlocked
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.users.LinkMemberIdentity(ctx, identity.Email, org.ID, models.LinkedIdentity{
+			Provider:    models.IdentityProviderSSO,
+			Issuer:      identity.Issuer,
+			Subject:     identity.Subject,
+			OrgID:       org.ID,
+			Email:       identity.Email,
+			LinkedAt:    now,
+			LastLoginAt: &now,
+		}, now)
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionIdentityLinked,
+			ActorID:  &user.ID,
+			TargetID: user.ID,
+			Details: bson.M{
+				"provider": models.IdentityProviderSSO,
+				"issuer":   identity.Issuer,
+				"org_id":   org.ID.Hex(),
+			},
+		})
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			if org.Provisioning != nil && org.Provisioning.Enabled {
+				return s.provisionSSOUser(ctx, org, identity, decision)
+			}
+			return nil, errNoSSOAccount
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, status.Errorf(codes.Aborted, "identity was linked concurrently, try again")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to link identity")
+	}
+
+	return user, nil
+}
+
+// requireNoEnforcedSSO rejects password logins by members of organizations
+// that only allow SSO
+func (s *AuthService) requireNoEnforcedSSO(ctx context.Context, user *models.User) error {
+	if user.OrgID == nil {
+		return nil
+	}
+
+	var org models.Organization
+	err := s.db.Orgs.FindOne(ctx, bson.M{"_id": *user.OrgID}).Decode(&org)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return status.Errorf(codes.Internal, "failed to retrieve organization")
+	}
+	if org.SSO != nil && org.SSO.Enforced {
+		return errSSORequired
+	}
+	return nil
+}
+
+func ssoConnection(connection *models.SSOConnection) sso.Connection {
+	return sso.Connection{
+		Issuer:       connection.Issuer,
+		ClientID:     connection.ClientID,
+		ClientSecret: connection.ClientSecret,
+	}
+}
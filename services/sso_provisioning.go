@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/alerts"
+	"user-management/audit"
+	"user-management/events"
+	"user-management/eventsource"
+	"user-management/models"
+	"user-management/provisioning"
+	"user-management/sso"
+)
+
+// provisionSSOUser creates a member account of org for an identity without
+// one, as the provisioning rules decided
+func (s *AuthService) provisionSSOUser(ctx context.Context, org *models.Organization, identity *sso.Identity, decision provisioning.Decision) (*models.User, error) {
+	userID, err := s.config.UserIDs.NewID()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate user ID")
+	}
+
+	now := time.Now()
+	user := models.User{
+		ID:            userID,
+		Email:         identity.Email,
+		Name:          decision.Name,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		IsActive:      true,
+		EmailVerified: true,
+		Roles:         decision.Roles,
+		MappedRoles:   decision.Roles,
+		OrgID:         &org.ID,
+		OrgRole:       decision.OrgRole,
+		Identities: []models.LinkedIdentity{{
+			Provider:    models.IdentityProviderSSO,
+			Issuer:      identity.Issuer,
+			Subject:     identity.Subject,
+			OrgID:       org.ID,
+			Email:       identity.Email,
+			LinkedAt:    now,
+			LastLoginAt: &now,
+		}},
+		ExternalIDs: decision.ExternalIDs,
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.users.Insert(ctx, user); err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypeProvisioned); err != nil {
+			return err
+		}
+		err := audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionUserProvisioned,
+			ActorID:  &user.ID,
+			TargetID: user.ID,
+			Details: bson.M{
+				"provider":      models.IdentityProviderSSO,
+				"issuer":        identity.Issuer,
+				"org_id":        org.ID.Hex(),
+				"org_role":      decision.OrgRole,
+				"matched_group": decision.MatchedGroup,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		return events.Enqueue(ctx, s.db, events.TypeUserRegistered, bson.M{
+			"user_id": user.ID.String(),
+			"email":   user.Email,
+			"name":    user.Name,
+			"source":  "sso",
+		})
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// The email address or an external ID belongs to an account
+			// outside the organization
+			return nil, status.Errorf(codes.FailedPrecondition, "an account outside your organization uses this email address or ID, ask your organization's admin for access")
+		}
+		log.Printf("Failed to provision SSO user %s in organization %s: %v", identity.Email, org.ID.Hex(), err)
+		return nil, status.Errorf(codes.Internal, "failed to create account")
+	}
+	s.config.Alerts.Record(alerts.EventUserRegistered)
+
+	return &user, nil
+}
+
+// refreshMappedRoles brings the roles org's role mappings grant user up to
+// date with the groups in the identity's claims. Roles granted otherwise
+// are kept, and never become mapped.
+func (s *AuthService) refreshMappedRoles(ctx context.Context, org *models.Organization, user *models.User, identity *sso.Identity) error {
+	var manual []string
+	for _, role := range user.Roles {
+		if !containsString(user.MappedRoles, role) {
+			manual = append(manual, role)
+		}
+	}
+	var mapped []string
+	for _, role := range provisioning.MappedRoles(org.Provisioning, identity.Claims) {
+		if !containsString(manual, role) {
+			mapped = append(mapped, role)
+		}
+	}
+
+	var added, removed []string
+	for _, role := range mapped {
+		if !containsString(user.MappedRoles, role) {
+			added = append(added, role)
+		}
+	}
+	for _, role := range user.MappedRoles {
+		if !containsString(mapped, role) {
+			removed = append(removed, role)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	roles := append(manual, mapped...)
+	err := s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.users.SetRoles(ctx, user.ID, roles, mapped, time.Now()); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionMappedRolesChanged,
+			ActorID:  &user.ID,
+			TargetID: user.ID,
+			Details: bson.M{
+				"org_id":  org.ID.Hex(),
+				"added":   added,
+				"removed": removed,
+			},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	user.Roles = roles
+	user.MappedRoles = mapped
+	return nil
+}
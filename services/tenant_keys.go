@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/audit"
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+// memberDataError is the status of a failure to encrypt a member's
+// personal data with the organization's key
+func memberDataError(err error) error {
+	if errors.Is(err, models.ErrTenantKeyDestroyed) {
+		return status.Errorf(codes.FailedPrecondition, "the organization's data was deleted")
+	}
+	log.Printf("Failed to encrypt member data: %v", err)
+	return status.Errorf(codes.Internal, "failed to encrypt member data")
+}
+
+// DestroyOrganizationKey destroys an organization's encryption key, so its
+// members' encrypted personal data can't be read anymore. Their names read
+// as empty, and no new data can be stored for the organization.
+func (s *AdminService) DestroyOrganizationKey(ctx context.Context, req *pb.DestroyOrganizationKeyRequest) (*pb.DestroyOrganizationKeyResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgObjectID, err := primitive.ObjectIDFromHex(req.OrgId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID format")
+	}
+	if !req.Confirm {
+		return nil, status.Errorf(codes.InvalidArgument, "confirm must be set, the organization's data can't be recovered")
+	}
+	if s.config.TenantKeys == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "organization data encryption is not enabled")
+	}
+
+	err = s.db.Orgs.FindOne(ctx, bson.M{"_id": orgObjectID}).Err()
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "organization not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find organization")
+	}
+
+	destroyedAt, err := s.config.TenantKeys.Destroy(ctx, orgObjectID)
+	if errors.Is(err, models.ErrTenantKeyDestroyed) {
+		return nil, status.Errorf(codes.FailedPrecondition, "the organization's key was already destroyed")
+	}
+	if err != nil {
+		log.Printf("Failed to destroy key of organization %s: %v", req.OrgId, err)
+		return nil, status.Errorf(codes.Internal, "failed to destroy key")
+	}
+
+	// The key is gone whether or not the entry is written
+	err = audit.Record(ctx, s.db, models.AuditLog{
+		Action:   audit.ActionTenantKeyDestroyed,
+		ActorID:  &admin.ID,
+		TargetID: admin.ID,
+		Details: bson.M{
+			"org_id": req.OrgId,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to audit key destruction of organization %s: %v", req.OrgId, err)
+	}
+
+	log.Printf("Admin %s destroyed the key of organization %s", admin.Email, req.OrgId)
+
+	return &pb.DestroyOrganizationKeyResponse{
+		DestroyedAt: timestamppb.New(destroyedAt),
+		Message:     "Organization key destroyed",
+	}, nil
+}
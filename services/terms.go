@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/audit"
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+// AcceptTerms records that the caller accepted the current terms of service
+func (s *UserService) AcceptTerms(ctx context.Context, req *pb.AcceptTermsRequest) (*pb.AcceptTermsResponse, error) {
+	if req.Version == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "version is required")
+	}
+
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.config.TermsVersion == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "there are no terms to accept")
+	}
+	// Accepting an older version doesn't count, the user hasn't seen the
+	// current one
+	if req.Version != s.config.TermsVersion {
+		return nil, status.Errorf(codes.FailedPrecondition, "terms have changed, accept version %s", s.config.TermsVersion)
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		err := s.users.AcceptTerms(ctx, user.ID, req.Version, now)
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:   audit.ActionTermsAccepted,
+			ActorID:  &user.ID,
+			TargetID: user.ID,
+			Details:  bson.M{"version": req.Version},
+		})
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to accept terms")
+	}
+
+	return &pb.AcceptTermsResponse{
+		Message: "Terms accepted",
+	}, nil
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-management/audit"
+	"user-management/auth"
+	"user-management/database"
+	"user-management/eventsource"
+	"user-management/models"
+	pb "user-management/proto"
+	"user-management/session"
+	"user-management/utils"
+)
+
+// errTwoFactorChanged aborts enrollment when the pending factor was
+// replaced after the code was checked
+var errTwoFactorChanged = errors.New("pending two-factor method changed")
+
+// errInvalidTwoFactorCode is returned for a code that is neither a current
+// TOTP code nor an unused recovery code
+var errInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+// Second factors a code can prove
+const (
+	factorTOTP         = "totp"
+	factorRecoveryCode = "recovery_code"
+)
+
+// EnableTwoFactor starts TOTP enrollment for the caller. The factor only
+// takes effect once VerifyTwoFactor confirms a code from it. Users who must
+// set up 2FA can call it with their enrollment-only token.
+func (s *UserService) EnableTwoFactor(ctx context.Context, req *pb.EnableTwoFactorRequest) (*pb.EnableTwoFactorResponse, error) {
+	claims, err := s.authorizer.AuthenticateScopedContext(ctx, auth.ScopeTwoFactorEnrollment)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+
+	userID, err := models.ParseID(claims.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to retrieve user")
+	}
+
+	if user.TwoFactor != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate secret")
+	}
+	encryptedSecret, err := s.config.TwoFactorCipher.Encrypt(secret, userID.String())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate secret")
+	}
+
+	// Starting again replaces a factor that was never verified
+	err = s.users.StartTwoFactor(ctx, userID, models.TwoFactor{
+		Method: models.TwoFactorMethodTOTP,
+		Secret: encryptedSecret,
+	}, time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to start two-factor enrollment")
+	}
+
+	return &pb.EnableTwoFactorResponse{
+		Secret:          secret,
+		ProvisioningUri: utils.TOTPProvisioningURI(s.config.TwoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// VerifyTwoFactor enables the factor started by EnableTwoFactor once the
+// caller proves their authenticator app produces its codes. Callers with an
+// enrollment-only token get a full session token back.
+func (s *UserService) VerifyTwoFactor(ctx context.Context, req *pb.VerifyTwoFactorRequest) (*pb.VerifyTwoFactorResponse, error) {
+	claims, err := s.authorizer.AuthenticateScopedContext(ctx, auth.ScopeTwoFactorEnrollment)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+
+	userID, err := models.ParseID(claims.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+
+	if req.Code == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "code is required")
+	}
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to retrieve user")
+	}
+
+	if user.TwoFactor != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+	if user.PendingTwoFactor == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "no two-factor enrollment in progress, call EnableTwoFactor first")
+	}
+	secret, err := s.config.TwoFactorCipher.Decrypt(user.PendingTwoFactor.Secret, userID.String())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read two-factor secret")
+	}
+	if !utils.ValidateTOTP(secret, req.Code, time.Now()) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid code")
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		err := s.users.EnableTwoFactor(ctx, userID, models.TwoFactor{
+			Method:    user.PendingTwoFactor.Method,
+			Secret:    user.PendingTwoFactor.Secret,
+			EnabledAt: now,
+		}, now)
+		if err == mongo.ErrNoDocuments {
+			return errTwoFactorChanged
+		}
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, userID, eventsource.TypeTwoFactorEnabled); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionTwoFactorEnabled,
+			ActorID:   &userID,
+			TargetID:  userID,
+			Details:   bson.M{"method": user.PendingTwoFactor.Method},
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		if err == errTwoFactorChanged {
+			return nil, status.Errorf(codes.Aborted, "two-factor enrollment was restarted, try again")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to enable two-factor authentication")
+	}
+
+	response := &pb.VerifyTwoFactorResponse{
+		Message: "Two-factor authentication enabled",
+	}
+
+	// Swap the enrollment-only token for a full session
+	if claims.Scope != "" {
+		token, refreshToken, err := s.sessions.GenerateToken(ctx, claims.UserID, user.Email, session.Info{
+			UserAgent: requestUserAgent(ctx),
+			IPAddress: requestClientIP(ctx),
+			Method:    claims.AuthMethod,
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate token")
+		}
+		response.Token = token
+		response.RefreshToken = refreshToken
+	}
+
+	return response, nil
+}
+
+// twoFactorEnrollmentDeadline returns when the user must have set up 2FA
+// by, or nil when no policy requires it of them. An organization policy
+// requires it of its members even when the deployment doesn't, and its
+// grace period takes precedence. The grace period starts at the user's
+// first login under the requirement.
+func (s *AuthService) twoFactorEnrollmentDeadline(ctx context.Context, user *models.User) (*time.Time, error) {
+	if user.TwoFactor != nil {
+		return nil, nil
+	}
+
+	required := s.config.RequireTwoFactor
+	gracePeriod := s.config.TwoFactorGracePeriod
+	if user.OrgID != nil {
+		var org models.Organization
+		err := s.db.Orgs.FindOne(ctx, bson.M{"_id": *user.OrgID}).Decode(&org)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		if err == nil && org.Policy.RequireTwoFactor {
+			required = true
+			if org.Policy.TwoFactorGracePeriod > 0 {
+				gracePeriod = org.Policy.TwoFactorGracePeriod
+			}
+		}
+	}
+	if !required {
+		return nil, nil
+	}
+	if user.TwoFactorDeadline != nil {
+		return user.TwoFactorDeadline, nil
+	}
+
+	// A concurrent login may set the deadline first, in which case its
+	// value wins
+	return s.users.SetTwoFactorDeadline(ctx, user.ID, time.Now().Add(gracePeriod))
+}
+
+// DisableTwoFactor turns 2FA off for the caller. It takes the password and
+// a code, so a stolen session alone can't remove the second factor.
+// Users required to use 2FA by the deployment or their organization can't
+// turn it off.
+func (s *UserService) DisableTwoFactor(ctx context.Context, req *pb.DisableTwoFactorRequest) (*pb.DisableTwoFactorResponse, error) {
+	user, err := s.authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Password == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "password is required")
+	}
+	if req.Code == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "code is required")
+	}
+
+	if user.TwoFactor == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+	required := s.config.RequireTwoFactor
+	if !required {
+		required, err = orgRequiresTwoFactor(ctx, s.db, user)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to check two-factor policy")
+		}
+	}
+	if required {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is required and can't be turned off")
+	}
+
+	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		return nil, status.Errorf(codes.PermissionDenied, "invalid password")
+	}
+
+	clientIP := requestClientIP(ctx)
+	userAgent := requestUserAgent(ctx)
+	factor, err := verifySecondFactor(ctx, s.db, s.users, s.config.TwoFactorCipher, user, req.Code, clientIP, userAgent)
+	if err != nil {
+		if err == errInvalidTwoFactorCode {
+			return nil, status.Errorf(codes.PermissionDenied, "invalid code")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to check code")
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		err := s.users.DisableTwoFactor(ctx, user.ID, user.TwoFactor.Secret, now)
+		if err == mongo.ErrNoDocuments {
+			return errTwoFactorChanged
+		}
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypeTwoFactorDisabled); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionTwoFactorDisabled,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: clientIP,
+			UserAgent: userAgent,
+			Details: bson.M{
+				"method": user.TwoFactor.Method,
+				"factor": factor,
+			},
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		if err == errTwoFactorChanged {
+			return nil, status.Errorf(codes.Aborted, "two-factor authentication changed, try again")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to disable two-factor authentication")
+	}
+
+	return &pb.DisableTwoFactorResponse{
+		Message: "Two-factor authentication disabled",
+	}, nil
+}
+
+// verifySecondFactor checks a code from the user's authenticator app or
+// one of their recovery codes, which is used up. It returns the factor the
+// code proved, or errInvalidTwoFactorCode.
+func verifySecondFactor(ctx context.Context, db *database.Database, users database.UserRepository, cipher *utils.SecretCipher, user *models.User, code, clientIP, userAgent string) (string, error) {
+	secret, err := cipher.Decrypt(user.TwoFactor.Secret, user.ID.String())
+	if err != nil {
+		return "", err
+	}
+	if utils.ValidateTOTP(secret, code, time.Now()) {
+		if !utils.IsEncryptedSecret(user.TwoFactor.Secret) {
+			encryptTwoFactorSecret(ctx, users, cipher, user, secret)
+		}
+		return factorTOTP, nil
+	}
+
+	hash := utils.HashToken(utils.NormalizeRecoveryCode(code))
+	err = db.WithTransaction(ctx, func(ctx context.Context) error {
+		err := users.UseRecoveryCode(ctx, user.ID, hash, time.Now())
+		if err == mongo.ErrNoDocuments {
+			return errInvalidTwoFactorCode
+		}
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, db, models.AuditLog{
+			Action:    audit.ActionRecoveryCodeUsed,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: clientIP,
+			UserAgent: userAgent,
+			Details:   bson.M{"remaining": len(user.RecoveryCodeHashes) - 1},
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return factorRecoveryCode, nil
+}
+
+// encryptTwoFactorSecret replaces a secret stored before secrets were
+// encrypted. Failures are logged, and the secret is encrypted at a later
+// login instead.
+func encryptTwoFactorSecret(ctx context.Context, users database.UserRepository, cipher *utils.SecretCipher, user *models.User, secret string) {
+	encrypted, err := cipher.Encrypt(secret, user.ID.String())
+	if err == nil {
+		err = users.ReplaceTwoFactorSecret(ctx, user.ID, user.TwoFactor.Secret, encrypted)
+	}
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Printf("Failed to encrypt the two-factor secret of user %s: %v", user.ID.String(), err)
+	}
+}
+
+// orgRequiresTwoFactor reports whether the user's organization requires
+// its members to use 2FA
+func orgRequiresTwoFactor(ctx context.Context, db *database.Database, user *models.User) (bool, error) {
+	if user.OrgID == nil {
+		return false, nil
+	}
+	var org models.Organization
+	err := db.Orgs.FindOne(ctx, bson.M{"_id": *user.OrgID}).Decode(&org)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return org.Policy.RequireTwoFactor, nil
+}
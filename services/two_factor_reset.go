@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/apierrors"
+	"user-management/audit"
+	"user-management/eventsource"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+	"user-management/utils"
+)
+
+// twoFactorResetBatchSize is the number of due resets completed per pass
+const twoFactorResetBatchSize = 100
+
+var (
+	// errTwoFactorResetConflict aborts a reset when another one started,
+	// 2FA was removed or the recovery code was used concurrently
+	errTwoFactorResetConflict = errors.New("two-factor reset conflicts with a concurrent change")
+	// errTwoFactorResetGone aborts a cancel or completion when the reset
+	// already finished or was canceled
+	errTwoFactorResetGone = errors.New("two-factor reset no longer pending")
+)
+
+// StartTwoFactorReset emails the code used as the second proof for
+// ResetTwoFactor
+func (s *AuthService) StartTwoFactorReset(ctx context.Context, req *pb.StartTwoFactorResetRequest) (*pb.StartTwoFactorResetResponse, error) {
+	req.Email = utils.SanitizeString(req.Email)
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	// The same response is returned whether or not the account exists, so
+	// the RPC can't be used to probe accounts
+	response := &pb.StartTwoFactorResetResponse{
+		Message: "If the account has two-factor authentication, a code has been sent to its email address",
+	}
+
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return response, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+	if user.TwoFactor == nil {
+		return response, nil
+	}
+
+	code, err := s.issueEmailChallenge(ctx, user.ID, models.ChallengePurposeTwoFactorReset)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create two-factor reset challenge")
+	}
+	if code == "" {
+		return response, nil
+	}
+
+	msg, err := notifications.Render(notifications.TemplateTwoFactorResetCode, user.Email, map[string]string{
+		"Code":      code,
+		"ExpiresIn": emailChallengeTTL.String(),
+	})
+	if err == nil {
+		err = s.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to send two-factor reset code to %s: %v", user.Email, err)
+	}
+
+	return response, nil
+}
+
+// ResetTwoFactor schedules the removal of a lost second factor. It takes
+// two independent proofs, the password and either an email code or a
+// recovery code. The factor is only removed after TwoFactorResetDelay, and
+// every contact point of the account is told straight away with a link to
+// cancel, so a stolen password and inbox alone can't take over the account.
+func (s *AuthService) ResetTwoFactor(ctx context.Context, req *pb.ResetTwoFactorRequest) (*pb.ResetTwoFactorResponse, error) {
+	req.Email = utils.SanitizeString(req.Email)
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	if req.Password == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "password is required")
+	}
+	if req.EmailCode == "" && req.RecoveryCode == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "an email code or a recovery code is required")
+	}
+
+	clientIP := requestClientIP(ctx)
+	userAgent := requestUserAgent(ctx)
+
+	// Password guesses here count towards the login rate limit
+	allowed, err := s.rateLimiter.CheckRateLimit(ctx, req.Email, clientIP)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check rate limit")
+	}
+	if !allowed {
+		retryAfter, err := s.rateLimiter.RetryAfter(ctx, req.Email, clientIP)
+		if err != nil {
+			log.Printf("Failed to compute login retry delay: %v", err)
+		}
+		return nil, apierrors.RateLimited("too many attempts, please try again later", retryAfter)
+	}
+
+	invalidProof := status.Errorf(codes.InvalidArgument, "invalid email, password or code")
+
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			s.rateLimiter.RecordLoginAttempt(ctx, req.Email, clientIP, false)
+			return nil, invalidProof
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	if lock := user.ActiveLock(time.Now()); lock != nil {
+		return nil, lockError(lock)
+	}
+
+	// Verify both proofs
+	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		s.rateLimiter.RecordLoginAttempt(ctx, req.Email, clientIP, false)
+		s.recordFailedLogin(ctx, user)
+		return nil, invalidProof
+	}
+	factors := []string{"password"}
+
+	var challenge *models.EmailChallenge
+	if req.EmailCode != "" {
+		challenge, err = s.verifyEmailChallenge(ctx, user.ID, models.ChallengePurposeTwoFactorReset, req.EmailCode)
+		switch err {
+		case nil:
+		case errChallengeExpired:
+			return nil, status.Errorf(codes.InvalidArgument, "invalid or expired email code, request a new one")
+		case errChallengeMismatch:
+			return nil, invalidProof
+		default:
+			return nil, status.Errorf(codes.Internal, "failed to check two-factor reset challenge")
+		}
+		factors = append(factors, "email_code")
+	}
+
+	var recoveryCodeHash string
+	if req.RecoveryCode != "" {
+		recoveryCodeHash = utils.HashToken(utils.NormalizeRecoveryCode(req.RecoveryCode))
+		if !containsString(user.RecoveryCodeHashes, recoveryCodeHash) {
+			return nil, invalidProof
+		}
+		factors = append(factors, "recovery_code")
+	}
+
+	// Only reported once the caller has proven they own the account
+	if user.TwoFactor == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+	if user.TwoFactorReset != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "a two-factor reset is already pending until %s",
+			user.TwoFactorReset.EffectiveAt.UTC().Format(time.RFC3339))
+	}
+
+	cancelToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate cancel token")
+	}
+
+	now := time.Now()
+	reset := models.TwoFactorReset{
+		RequestedAt:     now,
+		EffectiveAt:     now.Add(s.config.TwoFactorResetDelay),
+		Factors:         factors,
+		IPAddress:       clientIP,
+		CancelTokenHash: utils.HashToken(cancelToken),
+	}
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		err := s.users.StartTwoFactorReset(ctx, user.ID, reset, recoveryCodeHash, now)
+		if err == mongo.ErrNoDocuments {
+			return errTwoFactorResetConflict
+		}
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypeTwoFactorResetStarted); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionTwoFactorResetStarted,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: clientIP,
+			UserAgent: userAgent,
+			Details: bson.M{
+				"factors":      factors,
+				"effective_at": reset.EffectiveAt,
+			},
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		if err == errTwoFactorResetConflict {
+			return nil, status.Errorf(codes.Aborted, "account changed during the reset, try again")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to start two-factor reset")
+	}
+
+	if challenge != nil {
+		s.deleteEmailChallenge(ctx, challenge)
+	}
+
+	log.Printf("User %s started a two-factor reset from %s", user.ID.String(), clientIP)
+
+	s.notifyContactPoints(ctx, user, notifications.TemplateTwoFactorResetStarted, map[string]string{
+		"IPAddress":   clientIP,
+		"Factors":     strings.ReplaceAll(strings.Join(factors, ", "), "_", " "),
+		"EffectiveAt": reset.EffectiveAt.UTC().Format(time.RFC3339),
+		"CancelLink":  fmt.Sprintf("%s?token=%s", s.config.TwoFactorResetCancelURL, url.QueryEscape(cancelToken)),
+	})
+
+	return &pb.ResetTwoFactorResponse{
+		Message:     "Two-factor authentication will be removed once the waiting period ends",
+		EffectiveAt: timestamppb.New(reset.EffectiveAt),
+	}, nil
+}
+
+// CancelTwoFactorReset stops a pending reset with the token from the link
+// sent when it started. Whoever started the reset knew the password, so
+// every token issued so far is revoked as well.
+func (s *AuthService) CancelTwoFactorReset(ctx context.Context, req *pb.CancelTwoFactorResetRequest) (*pb.CancelTwoFactorResetResponse, error) {
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	tokenHash := utils.HashToken(req.Token)
+	invalidToken := status.Errorf(codes.InvalidArgument, "invalid cancel link, or the reset already finished")
+
+	user, err := s.users.FindByTwoFactorReset(ctx, tokenHash)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, invalidToken
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find two-factor reset")
+	}
+
+	clientIP := requestClientIP(ctx)
+	userAgent := requestUserAgent(ctx)
+
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		err := s.users.CancelTwoFactorReset(ctx, user.ID, tokenHash, now)
+		if err == mongo.ErrNoDocuments {
+			return errTwoFactorResetGone
+		}
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypeTwoFactorResetCanceled); err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionTwoFactorResetCanceled,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: clientIP,
+			UserAgent: userAgent,
+			Details: bson.M{
+				"requested_at":       user.TwoFactorReset.RequestedAt,
+				"tokens_valid_after": now,
+			},
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		if err == errTwoFactorResetGone {
+			return nil, invalidToken
+		}
+		return nil, status.Errorf(codes.Internal, "failed to cancel two-factor reset")
+	}
+
+	log.Printf("User %s canceled a two-factor reset from %s", user.ID.String(), clientIP)
+
+	return &pb.CancelTwoFactorResetResponse{
+		Message: "Two-factor reset canceled and every device signed out. Change your password now",
+	}, nil
+}
+
+// RunTwoFactorResets removes the second factor of accounts whose reset
+// waiting period has ended, every interval until ctx is canceled
+func (s *AuthService) RunTwoFactorResets(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		completed, err := s.completeTwoFactorResets(ctx)
+		if completed > 0 {
+			log.Printf("Completed %d two-factor resets", completed)
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to complete two-factor resets: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// completeTwoFactorResets completes up to twoFactorResetBatchSize due resets
+func (s *AuthService) completeTwoFactorResets(ctx context.Context) (int, error) {
+	users, err := s.users.ListDueTwoFactorResets(ctx, time.Now(), twoFactorResetBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for i := range users {
+		user := &users[i]
+		err := s.completeTwoFactorReset(ctx, user)
+		if err == errTwoFactorResetGone {
+			continue
+		}
+		if err != nil {
+			return completed, fmt.Errorf("user %s: %v", user.ID.String(), err)
+		}
+		completed++
+
+		s.notifyContactPoints(ctx, user, notifications.TemplateTwoFactorResetComplete, map[string]string{
+			"RequestedAt": user.TwoFactorReset.RequestedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return completed, nil
+}
+
+// completeTwoFactorReset removes the user's second factor, unless the reset
+// was canceled since the user was read
+func (s *AuthService) completeTwoFactorReset(ctx context.Context, user *models.User) error {
+	return s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		err := s.users.CompleteTwoFactorReset(ctx, user.ID, user.TwoFactorReset.CancelTokenHash, now)
+		if err == mongo.ErrNoDocuments {
+			return errTwoFactorResetGone
+		}
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, user.ID, eventsource.TypeTwoFactorReset); err != nil {
+			return err
+		}
+
+		details := bson.M{
+			"requested_at": user.TwoFactorReset.RequestedAt,
+			"factors":      user.TwoFactorReset.Factors,
+		}
+		if user.TwoFactor != nil {
+			details["method"] = user.TwoFactor.Method
+		}
+		return audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionTwoFactorReset,
+			ActorID:   &user.ID,
+			TargetID:  user.ID,
+			IPAddress: user.TwoFactorReset.IPAddress,
+			Details:   details,
+			CreatedAt: now,
+		})
+	})
+}
+
+// contactPoints are the addresses told about changes to how the account is
+// secured. The account email is currently the only one.
+func contactPoints(user *models.User) []string {
+	return []string{user.Email}
+}
+
+// notifyContactPoints sends a notification to every contact point of the
+// account. Failures are logged, not returned.
+func (s *AuthService) notifyContactPoints(ctx context.Context, user *models.User, template string, data map[string]string) {
+	for _, to := range contactPoints(user) {
+		msg, err := notifications.Render(template, to, data)
+		if err == nil {
+			err = s.sender.Send(ctx, msg)
+		}
+		if err != nil {
+			log.Printf("Failed to send %s notification to %s: %v", template, to, err)
+		}
+	}
+}
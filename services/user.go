@@ -2,56 +2,106 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
-	"user-management/auth"
+	"user-management/apierrors"
+	"user-management/audit"
+	"user-management/authz"
 	"user-management/database"
+	"user-management/events"
+	"user-management/eventsource"
 	"user-management/models"
+	"user-management/passkeys"
 	pb "user-management/proto"
+	"user-management/session"
 	"user-management/utils"
 )
 
+// errUserNotFound aborts a transaction whose target user does not exist
+var errUserNotFound = errors.New("user not found")
+
+type UserConfig struct {
+	// PasswordPolicy is the set of rules new passwords must meet. The zero
+	// value selects utils.DefaultPasswordPolicy.
+	PasswordPolicy utils.PasswordPolicy
+
+	// TwoFactorIssuer names the service in authenticator apps. Empty
+	// selects "user-management".
+	TwoFactorIssuer string
+	// TwoFactorCipher encrypts TOTP secrets before they are stored
+	TwoFactorCipher *utils.SecretCipher
+	// RequireTwoFactor stops users from turning 2FA off, as does an
+	// organization policy requiring it
+	RequireTwoFactor bool
+
+	// Passkeys registers passkeys under the WebAuthn policy
+	Passkeys *passkeys.RelyingParty
+
+	// TermsVersion is the current terms of service, the only version
+	// AcceptTerms accepts
+	TermsVersion string
+
+	// DeletionGracePeriod is how long a deleted account can be restored.
+	// Zero when it can't.
+	DeletionGracePeriod time.Duration
+}
+
 type UserService struct {
 	pb.UnimplementedUserServiceServer
 	db         *database.Database
-	jwtService *auth.JWTService
+	users      database.UserRepository
+	apiKeys    database.APIKeyRepository
+	sessions   session.Manager
+	authorizer authz.Authorizer
+	config     UserConfig
+
+	// watchesStopped is closed by StopWatches to end session watches
+	watchesStopped chan struct{}
+	stopWatches    sync.Once
+	sessionWatcher *sessionWatcher
 }
 
-func NewUserService(db *database.Database, jwtService *auth.JWTService) *UserService {
+func NewUserService(db *database.Database, sessions session.Manager, authorizer authz.Authorizer, config UserConfig) *UserService {
+	if config.PasswordPolicy == (utils.PasswordPolicy{}) {
+		config.PasswordPolicy = utils.DefaultPasswordPolicy
+	}
+	if config.TwoFactorIssuer == "" {
+		config.TwoFactorIssuer = "user-management"
+	}
+	watchesStopped := make(chan struct{})
+	repos := db.Repositories()
 	return &UserService{
-		db:         db,
-		jwtService: jwtService,
+		db:             db,
+		users:          repos.Users,
+		apiKeys:        repos.APIKeys,
+		sessions:       sessions,
+		authorizer:     authorizer,
+		config:         config,
+		watchesStopped: watchesStopped,
+		sessionWatcher: newSessionWatcher(db, repos, watchesStopped),
 	}
 }
 
 func (s *UserService) GetProfile(ctx context.Context, req *pb.GetProfileRequest) (*pb.GetProfileResponse, error) {
-	// Validate user ID
-	if req.UserId == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "user ID is required")
-	}
-
-	userObjectID, err := primitive.ObjectIDFromHex(req.UserId)
+	// Callers act on their own profile, admins on anyone's
+	userID, _, err := authz.ProfileOwner(ctx, req.UserId)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+		return nil, err
 	}
 
 	// Find user
-	var user models.User
-	err = s.db.Users.FindOne(ctx, bson.M{
-		"_id":        userObjectID,
-		"is_deleted": false,
-	}).Decode(&user)
-
+	user, err := s.users.FindByID(ctx, userID)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
+		if err == database.ErrNotFound {
 			return nil, status.Errorf(codes.NotFound, "user not found")
 		}
 		return nil, status.Errorf(codes.Internal, "failed to retrieve user")
@@ -59,13 +109,14 @@ func (s *UserService) GetProfile(ctx context.Context, req *pb.GetProfileRequest)
 
 	// Convert to protobuf
 	pbUser := &pb.User{
-		Id:        user.ID.Hex(),
-		Email:     user.Email,
-		Name:      user.Name,
-		CreatedAt: timestamppb.New(user.CreatedAt),
-		UpdatedAt: timestamppb.New(user.UpdatedAt),
-		IsActive:  user.IsActive,
-		IsDeleted: user.IsDeleted,
+		Id:          user.ID.String(),
+		Email:       user.Email,
+		Name:        user.Name,
+		CreatedAt:   timestamppb.New(user.CreatedAt),
+		UpdatedAt:   timestamppb.New(user.UpdatedAt),
+		IsActive:    user.IsActive,
+		IsDeleted:   user.IsDeleted,
+		ExternalIds: user.ExternalIDMap(),
 	}
 
 	return &pb.GetProfileResponse{
@@ -74,86 +125,162 @@ func (s *UserService) GetProfile(ctx context.Context, req *pb.GetProfileRequest)
 }
 
 func (s *UserService) UpdateProfile(ctx context.Context, req *pb.UpdateProfileRequest) (*pb.UpdateProfileResponse, error) {
-	// Validate user ID
-	if req.UserId == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "user ID is required")
+	// The email is where password resets go, so changing it needs a session
+	if req.Email != "" {
+		if _, err := authz.RequireSession(ctx); err != nil {
+			return nil, err
+		}
 	}
 
-	userObjectID, err := primitive.ObjectIDFromHex(req.UserId)
+	// Callers act on their own profile, admins on anyone's
+	userID, actorID, err := authz.ProfileOwner(ctx, req.UserId)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+		return nil, err
 	}
 
 	// Sanitize inputs
 	req.Name = utils.SanitizeString(req.Name)
 	req.Email = utils.SanitizeString(req.Email)
 
-	// update
-	update := bson.M{
-		"$set": bson.M{
-			"updated_at": time.Now(),
-		},
-	}
+	now := time.Now()
+	change := database.ProfileChange{UpdatedAt: now}
+	// eventChanges are published in the change event
+	eventChanges := bson.M{"updated_at": now}
 
 	// Validate and add fields to update
 	if req.Name != "" {
 		if err := utils.ValidateName(req.Name, "name"); err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
 		}
-		update["$set"].(bson.M)["name"] = req.Name
+		change.Name = req.Name
+		eventChanges["name"] = req.Name
 	}
 
 	if req.Email != "" {
 		if err := utils.ValidateEmail(req.Email); err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
 		}
 
 		// Check if email is already taken by another user
-		var existingUser models.User
-		err := s.db.Users.FindOne(ctx, bson.M{
-			"email": req.Email,
-			"_id":   bson.M{"$ne": userObjectID},
-		}).Decode(&existingUser)
-
-		if err == nil {
-			return nil, status.Errorf(codes.AlreadyExists, "email is already taken")
-		} else if err != mongo.ErrNoDocuments {
+		taken, err := s.users.EmailTaken(ctx, req.Email, userID)
+		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to check email uniqueness")
 		}
+		if taken {
+			return nil, apierrors.EmailTaken("email is already taken")
+		}
 
-		update["$set"].(bson.M)["email"] = req.Email
+		// The new address has to be verified again
+		change.Email = req.Email
+		eventChanges["email"] = req.Email
+		eventChanges["email_verified"] = false
 	}
 
-	// Update user
-	result, err := s.db.Users.UpdateOne(ctx, bson.M{
-		"_id":        userObjectID,
-		"is_deleted": false,
-	}, update)
+	// Members of some organizations need an org admin to approve changes
+	var auditChanges []models.AuditChange
+	if req.Name != "" || req.Email != "" {
+		changeRequest, user, err := s.queueProfileChange(ctx, userID, req.Name, req.Email)
+		if err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to update user")
+		if changeRequest != nil {
+			pbUser := &pb.User{
+				Id:          user.ID.String(),
+				Email:       user.Email,
+				Name:        user.Name,
+				CreatedAt:   timestamppb.New(user.CreatedAt),
+				UpdatedAt:   timestamppb.New(user.UpdatedAt),
+				IsActive:    user.IsActive,
+				IsDeleted:   user.IsDeleted,
+				ExternalIds: user.ExternalIDMap(),
+			}
+
+			return &pb.UpdateProfileResponse{
+				User:            pbUser,
+				Message:         "Profile change is waiting for approval from your organization",
+				PendingApproval: true,
+				ChangeRequestId: changeRequest.ID.Hex(),
+			}, nil
+		}
+
+		// Members' names stay out of the audit log, which would keep
+		// them readable after their organization's key is destroyed
+		if req.Name != "" {
+			change := models.AuditChange{Field: "name"}
+			if user.OrgID == nil {
+				change.Before, change.After = user.Name, req.Name
+			}
+			auditChanges = append(auditChanges, change)
+		}
+		if req.Email != "" {
+			auditChanges = append(auditChanges, models.AuditChange{Field: "email", Before: user.Email, After: req.Email})
+		}
+
+		// Members' names are stored encrypted with their organization's
+		// key, and published in the event as they are
+		if req.Name != "" {
+			name, err := models.StoredMemberField(user.OrgID, user.ID, req.Name)
+			if err != nil {
+				return nil, memberDataError(err)
+			}
+			change.Name = name
+		}
 	}
 
-	if result.MatchedCount == 0 {
-		return nil, status.Errorf(codes.NotFound, "user not found")
+	// Update user together with its change event
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		err := s.users.UpdateProfile(ctx, userID, change)
+		if err == database.ErrNotFound {
+			return errUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := eventsource.Record(ctx, s.db, userID, eventsource.TypeProfileUpdated); err != nil {
+			return err
+		}
+		err = audit.Record(ctx, s.db, models.AuditLog{
+			Action:    audit.ActionProfileUpdated,
+			ActorID:   &actorID,
+			TargetID:  userID,
+			IPAddress: requestClientIP(ctx),
+			UserAgent: requestUserAgent(ctx),
+			Changes:   auditChanges,
+		})
+		if err != nil {
+			return err
+		}
+
+		return events.Enqueue(ctx, s.db, events.TypeUserUpdated, bson.M{
+			"user_id": userID.String(),
+			"changes": eventChanges,
+		})
+	})
+
+	if err != nil {
+		if err == errUserNotFound {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update user")
 	}
 
 	// Retrieve updated user
-	var updatedUser models.User
-	err = s.db.Users.FindOne(ctx, bson.M{"_id": userObjectID}).Decode(&updatedUser)
+	updatedUser, err := s.users.FindByID(ctx, userID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to retrieve updated user")
 	}
 
 	// Convert to protobuf
 	pbUser := &pb.User{
-		Id:        updatedUser.ID.Hex(),
-		Email:     updatedUser.Email,
-		Name:      updatedUser.Name,
-		CreatedAt: timestamppb.New(updatedUser.CreatedAt),
-		UpdatedAt: timestamppb.New(updatedUser.UpdatedAt),
-		IsActive:  updatedUser.IsActive,
-		IsDeleted: updatedUser.IsDeleted,
+		Id:          updatedUser.ID.String(),
+		Email:       updatedUser.Email,
+		Name:        updatedUser.Name,
+		CreatedAt:   timestamppb.New(updatedUser.CreatedAt),
+		UpdatedAt:   timestamppb.New(updatedUser.UpdatedAt),
+		IsActive:    updatedUser.IsActive,
+		IsDeleted:   updatedUser.IsDeleted,
+		ExternalIds: updatedUser.ExternalIDMap(),
 	}
 
 	return &pb.UpdateProfileResponse{
@@ -163,42 +290,96 @@ func (s *UserService) UpdateProfile(ctx context.Context, req *pb.UpdateProfileRe
 }
 
 func (s *UserService) DeleteProfile(ctx context.Context, req *pb.DeleteProfileRequest) (*pb.DeleteProfileResponse, error) {
-	// Validate user ID
-	if req.UserId == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "user ID is required")
+	if _, err := authz.RequireSession(ctx); err != nil {
+		return nil, err
 	}
-
-	userObjectID, err := primitive.ObjectIDFromHex(req.UserId)
+	// Callers act on their own profile, admins on anyone's
+	userID, actorID, err := authz.ProfileOwner(ctx, req.UserId)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
-	}
-
-	// Soft delete the user
-	result, err := s.db.Users.UpdateOne(ctx, bson.M{
-		"_id":        userObjectID,
-		"is_deleted": false,
-	}, bson.M{
-		"$set": bson.M{
-			"is_deleted": true,
-			"is_active":  false,
-			"updated_at": time.Now(),
-		},
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		return softDeleteUser(ctx, s.db, s.users, userID, actorID, now)
 	})
 
 	if err != nil {
+		if err == errUserNotFound {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
 		return nil, status.Errorf(codes.Internal, "failed to delete user")
 	}
 
-	if result.MatchedCount == 0 {
-		return nil, status.Errorf(codes.NotFound, "user not found")
+	response := &pb.DeleteProfileResponse{
+		Message: "Profile deleted successfully",
 	}
+	if s.config.DeletionGracePeriod > 0 {
+		restorableUntil := now.Add(s.config.DeletionGracePeriod)
+		response.RestorableUntil = timestamppb.New(restorableUntil)
+		response.Message = fmt.Sprintf("Profile deleted, it can be restored until %s", restorableUntil.UTC().Format(time.RFC3339))
+	}
+	return response, nil
+}
 
-	return &pb.DeleteProfileResponse{
-		Message: "Profile deleted successfully",
-	}, nil
+// softDeleteUser deletes the user together with its deletion event,
+// signing them out everywhere. The account can be restored until the
+// deletion grace period ends. Call it inside a transaction.
+func softDeleteUser(ctx context.Context, db *database.Database, users database.UserRepository, userID, actorID models.ID, now time.Time) error {
+	err := users.SoftDelete(ctx, userID, now)
+	if err == database.ErrNotFound {
+		return errUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := eventsource.Record(ctx, db, userID, eventsource.TypeDeleted); err != nil {
+		return err
+	}
+	err = audit.Record(ctx, db, models.AuditLog{
+		Action:    audit.ActionUserDeleted,
+		ActorID:   &actorID,
+		TargetID:  userID,
+		IPAddress: requestClientIP(ctx),
+		UserAgent: requestUserAgent(ctx),
+	})
+	if err != nil {
+		return err
+	}
+
+	return events.Enqueue(ctx, db, events.TypeUserDeleted, bson.M{
+		"user_id": userID.String(),
+	})
+}
+
+// userSearch returns the filters of a ListUsers request
+func userSearch(req *pb.ListUsersRequest) database.UserSearch {
+	search := database.UserSearch{
+		NameFilter:  utils.SanitizeString(req.NameFilter),
+		EmailFilter: utils.SanitizeString(req.EmailFilter),
+		IsActive:    req.IsActive,
+		SortBy:      req.SortBy,
+		SortOrder:   req.SortOrder,
+	}
+	if req.CreatedAfter != nil {
+		search.CreatedAfter = req.CreatedAfter.AsTime()
+	}
+	if req.CreatedBefore != nil {
+		search.CreatedBefore = req.CreatedBefore.AsTime()
+	}
+	return search
 }
 
+// ListUsers pages through every account, which only admins may do
 func (s *UserService) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	principal, err := authz.Caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !principal.HasRole(models.RoleAdmin) {
+		return nil, status.Errorf(codes.PermissionDenied, "admin role required")
+	}
+
 	// Set default pagination values
 	page := req.Page
 	if page <= 0 {
@@ -209,36 +390,46 @@ func (s *UserService) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (
 		pageSize = 10
 	}
 
-	totalCount, err := s.db.Users.CountDocuments(ctx, bson.M{})
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to count users")
+	// Filters are escaped, so they match literally. Newest first by
+	// default.
+	search := userSearch(req)
+	if err := utils.ValidateUserSearch(search); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
 	}
 
 	skip := (page - 1) * pageSize
 
-	// Find users with pagination
-	findOptions := options.Find()
-	findOptions.SetSkip(int64(skip))
-	findOptions.SetLimit(int64(pageSize))
-	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}}) // Sort by newest
+	// Every page reads at the cluster time of the first one, so users
+	// created or deleted meanwhile do not shift the pages. A standalone
+	// server cannot read at a fixed point in time.
+	var at primitive.Timestamp
+	var consistencyToken string
+	if s.db.SupportsSnapshotReads() {
+		if req.ConsistencyToken != "" {
+			at, err = decodeConsistencyToken(req.ConsistencyToken)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+			}
+		} else if at, err = s.db.ClusterTime(ctx); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to read cluster time")
+		}
+		consistencyToken = encodeConsistencyToken(at)
+	}
 
-	// Using empty filter bson.M{} to get all users
-	cursor, err := s.db.Users.Find(ctx, bson.M{}, findOptions)
+	users, totalCount, err := s.users.Search(ctx, search, at, int64(skip), int64(pageSize))
+	if errors.Is(err, database.ErrSnapshotTooOld) {
+		return nil, status.Errorf(codes.FailedPrecondition, "consistency token expired, start again from the first page")
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to find users")
 	}
-	defer cursor.Close(ctx)
-
-	var users []models.User
-	if err = cursor.All(ctx, &users); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to decode users")
-	}
 
 	// Convert to protobuf
 	var pbUsers []*pb.User
 	for _, user := range users {
+		// External IDs are left out of listings, GetProfile returns them
 		pbUser := &pb.User{
-			Id:        user.ID.Hex(),
+			Id:        user.ID.String(),
 			Email:     user.Email,
 			Name:      user.Name,
 			CreatedAt: timestamppb.New(user.CreatedAt),
@@ -250,9 +441,10 @@ func (s *UserService) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (
 	}
 
 	return &pb.ListUsersResponse{
-		Users:      pbUsers,
-		TotalCount: int32(totalCount),
-		Page:       page,
-		PageSize:   pageSize,
+		Users:            pbUsers,
+		TotalCount:       int32(totalCount),
+		Page:             page,
+		PageSize:         pageSize,
+		ConsistencyToken: consistencyToken,
 	}, nil
 }
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/database"
+	"user-management/eventsource"
+	"user-management/models"
+	pb "user-management/proto"
+)
+
+// recordUserEvent appends a history event for a user change made outside a
+// transaction. The change is already saved, so a failure is only logged.
+func recordUserEvent(ctx context.Context, db *database.Database, userID models.ID, eventType string) {
+	if err := eventsource.Record(ctx, db, userID, eventType); err != nil {
+		log.Printf("Failed to record user history: %v", err)
+	}
+}
+
+func (s *AdminService) GetUserAt(ctx context.Context, req *pb.GetUserAtRequest) (*pb.GetUserAtResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if !s.db.EventSourcedUsers() {
+		return nil, status.Errorf(codes.FailedPrecondition, "user history is not enabled")
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	at := time.Now()
+	if req.At != nil {
+		at = req.At.AsTime()
+	}
+
+	user, version, err := eventsource.StateAt(ctx, s.db, userID, at)
+	if err != nil {
+		if err == eventsource.ErrNoHistory {
+			return nil, status.Errorf(codes.NotFound, "no history for user at that time")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to rebuild user")
+	}
+
+	pbUser := &pb.User{
+		Id:          user.ID.String(),
+		Email:       user.Email,
+		Name:        user.Name,
+		CreatedAt:   timestamppb.New(user.CreatedAt),
+		UpdatedAt:   timestamppb.New(user.UpdatedAt),
+		IsActive:    user.IsActive,
+		IsDeleted:   user.IsDeleted,
+		ExternalIds: user.ExternalIDMap(),
+	}
+
+	return &pb.GetUserAtResponse{
+		User:    pbUser,
+		Version: version,
+	}, nil
+}
+
+func (s *AdminService) ListUserEvents(ctx context.Context, req *pb.ListUserEventsRequest) (*pb.ListUserEventsResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if !s.db.EventSourcedUsers() {
+		return nil, status.Errorf(codes.FailedPrecondition, "user history is not enabled")
+	}
+
+	userID, err := models.ParseID(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	// Set default pagination values
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	filter := bson.M{"user_id": userID}
+	totalCount, err := s.db.UserEvents.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count user events")
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSkip(int64((page - 1) * pageSize))
+	findOptions.SetLimit(int64(pageSize))
+	findOptions.SetSort(bson.D{{Key: "version", Value: -1}})
+
+	cursor, err := s.db.UserEvents.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user events")
+	}
+	defer cursor.Close(ctx)
+
+	var userEvents []models.UserEvent
+	if err = cursor.All(ctx, &userEvents); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode user events")
+	}
+
+	// Only field names are returned, values can include password hashes
+	var pbEvents []*pb.UserEvent
+	for _, event := range userEvents {
+		var changed []string
+		if elements, err := event.Set.Elements(); err == nil {
+			for _, element := range elements {
+				changed = append(changed, element.Key())
+			}
+		}
+		changed = append(changed, event.Unset...)
+
+		pbEvents = append(pbEvents, &pb.UserEvent{
+			Version:       event.Version,
+			Type:          event.Type,
+			ChangedFields: changed,
+			CreatedAt:     timestamppb.New(event.CreatedAt),
+		})
+	}
+
+	return &pb.ListUserEventsResponse{
+		Events:     pbEvents,
+		TotalCount: int32(totalCount),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"user-management/database"
+	"user-management/models"
+	"user-management/notifications"
+	pb "user-management/proto"
+)
+
+const (
+	// workflowLease is how long a server works on a workflow before
+	// another may take it over
+	workflowLease = 5 * time.Minute
+	// workflowRetryDelay is how long a failed step waits before it runs
+	// again, doubled after each failure
+	workflowRetryDelay = time.Minute
+	// maxWorkflowAttempts is how many times a step fails before the
+	// workflow is given up
+	maxWorkflowAttempts = 8
+)
+
+// errWorkflowTakenOver aborts a step when another server has recorded it
+// since the workflow was claimed
+var errWorkflowTakenOver = errors.New("workflow taken over by another server")
+
+// workflowEnv is what workflow steps run with
+type workflowEnv struct {
+	db     *database.Database
+	users  database.UserRepository
+	sender notifications.Sender
+}
+
+// workflowStep is one step of a workflow. A step runs in a transaction
+// that also records it. Without transactions, or when it sends an email,
+// it may still run again after taking effect, so running it twice must
+// leave the same result.
+type workflowStep struct {
+	name string
+	run  func(ctx context.Context, env workflowEnv, wf *models.Workflow) error
+}
+
+// workflowSteps are the steps of each kind of workflow, in order
+var workflowSteps = map[string][]workflowStep{
+	models.WorkflowErasure:         erasureSteps,
+	models.WorkflowAccountRecovery: accountRecoverySteps,
+}
+
+// startWorkflow stores wf, leased to the caller, which runs it next with
+// runWorkflow. When the caller stops before finishing, another server
+// resumes it once the lease expires. Call it in the transaction making
+// the change the workflow follows up on, if any.
+func startWorkflow(ctx context.Context, db *database.Database, wf *models.Workflow) error {
+	now := time.Now()
+	wf.Status = models.WorkflowRunning
+	wf.LockedUntil = now.Add(workflowLease)
+	wf.CreatedAt = now
+	wf.UpdatedAt = now
+
+	result, err := db.Workflows.InsertOne(ctx, wf)
+	if err != nil {
+		return err
+	}
+	wf.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// runWorkflow runs the remaining steps of wf, which the caller has
+// leased. A step that fails is retried later by RunWorkflows, and the
+// error is recorded on wf rather than returned. It returns an error only
+// when the failure couldn't be recorded.
+func runWorkflow(ctx context.Context, env workflowEnv, wf *models.Workflow) error {
+	steps, ok := workflowSteps[wf.Kind]
+	if !ok {
+		return fmt.Errorf("unknown workflow kind %q", wf.Kind)
+	}
+
+	for wf.Step < len(steps) {
+		step := steps[wf.Step]
+		err := env.db.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := step.run(ctx, env, wf); err != nil {
+				return err
+			}
+			result, err := env.db.Workflows.UpdateOne(ctx, bson.M{
+				"_id":  wf.ID,
+				"step": wf.Step,
+			}, workflowStepDone(wf, step, len(steps)))
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errWorkflowTakenOver
+			}
+			return nil
+		})
+		if err == errWorkflowTakenOver {
+			log.Printf("Stopped %s workflow %s: %v", wf.Kind, wf.ID.Hex(), err)
+			return nil
+		}
+		if err != nil {
+			return failWorkflowStep(ctx, env.db, wf, step, err)
+		}
+
+		wf.Step++
+		wf.CompletedSteps = append(wf.CompletedSteps, step.name)
+		wf.Attempts = 0
+		wf.Error = ""
+		wf.UpdatedAt = time.Now()
+	}
+
+	now := time.Now()
+	wf.Status = models.WorkflowDone
+	wf.CompletedAt = &now
+	log.Printf("Completed %s workflow %s of user %s", wf.Kind, wf.ID.Hex(), wf.UserID.String())
+	return nil
+}
+
+// workflowStepDone returns the update recording step as done, and the
+// workflow as done after its last step
+func workflowStepDone(wf *models.Workflow, step workflowStep, stepCount int) bson.M {
+	now := time.Now()
+	set := bson.M{
+		"step":         wf.Step + 1,
+		"attempts":     0,
+		"locked_until": now.Add(workflowLease),
+		"updated_at":   now,
+	}
+	unset := bson.M{"error": ""}
+	if wf.Step+1 == stepCount {
+		set["status"] = models.WorkflowDone
+		set["completed_at"] = now
+		unset["data"] = ""
+	} else if len(wf.Data) > 0 {
+		set["data"] = wf.Data
+	}
+	return bson.M{
+		"$set":   set,
+		"$unset": unset,
+		"$push":  bson.M{"completed_steps": step.name},
+	}
+}
+
+// failWorkflowStep records a failed run of step, and gives the workflow up
+// after maxWorkflowAttempts failures
+func failWorkflowStep(ctx context.Context, db *database.Database, wf *models.Workflow, step workflowStep, stepErr error) error {
+	now := time.Now()
+	wf.Attempts++
+	wf.Error = stepErr.Error()
+	wf.UpdatedAt = now
+
+	set := bson.M{
+		"attempts":   wf.Attempts,
+		"error":      wf.Error,
+		"updated_at": now,
+	}
+	if wf.Attempts >= maxWorkflowAttempts {
+		wf.Status = models.WorkflowFailed
+		wf.CompletedAt = &now
+		set["status"] = wf.Status
+		set["completed_at"] = now
+		log.Printf("Gave up %s workflow %s of user %s at step %s: %v", wf.Kind, wf.ID.Hex(), wf.UserID.String(), step.name, stepErr)
+	} else {
+		wf.LockedUntil = now.Add(workflowRetryDelay << (wf.Attempts - 1))
+		set["locked_until"] = wf.LockedUntil
+		log.Printf("Step %s of %s workflow %s failed, retrying at %s: %v", step.name, wf.Kind, wf.ID.Hex(), wf.LockedUntil.UTC().Format(time.RFC3339), stepErr)
+	}
+
+	_, err := db.Workflows.UpdateOne(ctx, bson.M{"_id": wf.ID, "step": wf.Step}, bson.M{"$set": set})
+	return err
+}
+
+// RunWorkflows resumes the workflows whose server stopped or whose failed
+// step is due to be retried, and returns how many it ran
+func (s *AdminService) RunWorkflows(ctx context.Context) (int, error) {
+	env := workflowEnv{db: s.db, users: s.users, sender: s.sender}
+	ran := 0
+	for {
+		wf, err := claimWorkflow(ctx, s.db)
+		if err == mongo.ErrNoDocuments {
+			return ran, nil
+		}
+		if err != nil {
+			return ran, err
+		}
+
+		if err := runWorkflow(ctx, env, wf); err != nil {
+			return ran, fmt.Errorf("workflow %s: %v", wf.ID.Hex(), err)
+		}
+		ran++
+	}
+}
+
+// claimWorkflow leases a running workflow that no server is working on
+func claimWorkflow(ctx context.Context, db *database.Database) (*models.Workflow, error) {
+	now := time.Now()
+
+	var wf models.Workflow
+	err := db.Workflows.FindOneAndUpdate(ctx, bson.M{
+		"status":       models.WorkflowRunning,
+		"locked_until": bson.M{"$lte": now},
+	}, bson.M{
+		"$set": bson.M{"locked_until": now.Add(workflowLease)},
+	}, options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "locked_until", Value: 1}}).
+		SetReturnDocument(options.After),
+	).Decode(&wf)
+	if err != nil {
+		return nil, err
+	}
+	return &wf, nil
+}
+
+// GetWorkflow returns a workflow and the step it has reached
+func (s *AdminService) GetWorkflow(ctx context.Context, req *pb.GetWorkflowRequest) (*pb.GetWorkflowResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	wf, err := s.findWorkflow(ctx, req.WorkflowId)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetWorkflowResponse{Workflow: workflowToProto(wf)}, nil
+}
+
+// ListWorkflows returns workflows, newest first
+func (s *AdminService) ListWorkflows(ctx context.Context, req *pb.ListWorkflowsRequest) (*pb.ListWorkflowsResponse, error) {
+	if _, err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	filter := bson.M{}
+	if req.Kind != "" {
+		filter["kind"] = req.Kind
+	}
+	if req.Status != "" {
+		filter["status"] = req.Status
+	}
+	if req.UserId != "" {
+		userID, err := models.ParseID(req.UserId)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format")
+		}
+		filter["user_id"] = userID
+	}
+
+	totalCount, err := s.db.Workflows.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count workflows")
+	}
+
+	cursor, err := s.db.Workflows.Find(ctx, filter, options.Find().
+		SetSkip(int64((page-1)*pageSize)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find workflows")
+	}
+	var workflows []models.Workflow
+	if err := cursor.All(ctx, &workflows); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode workflows")
+	}
+
+	response := &pb.ListWorkflowsResponse{
+		TotalCount: int32(totalCount),
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for i := range workflows {
+		response.Workflows = append(response.Workflows, workflowToProto(&workflows[i]))
+	}
+	return response, nil
+}
+
+// RetryWorkflow resumes a failed workflow at the step it gave up on
+func (s *AdminService) RetryWorkflow(ctx context.Context, req *pb.RetryWorkflowRequest) (*pb.RetryWorkflowResponse, error) {
+	admin, err := s.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	workflowID, err := primitive.ObjectIDFromHex(req.WorkflowId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid workflow ID format")
+	}
+
+	now := time.Now()
+	var wf models.Workflow
+	err = s.db.Workflows.FindOneAndUpdate(ctx, bson.M{
+		"_id":    workflowID,
+		"status": models.WorkflowFailed,
+	}, bson.M{
+		"$set": bson.M{
+			"status":       models.WorkflowRunning,
+			"attempts":     0,
+			"locked_until": now,
+			"updated_at":   now,
+		},
+		"$unset": bson.M{"completed_at": ""},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&wf)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, status.Errorf(codes.AlreadyExists, "another workflow of this kind is running for the user")
+	}
+	if err == mongo.ErrNoDocuments {
+		found, err := s.findWorkflow(ctx, req.WorkflowId)
+		if err != nil {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.FailedPrecondition, "workflow is %s, only failed workflows can be retried", found.Status)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to retry workflow")
+	}
+
+	log.Printf("Admin %s retried %s workflow %s", admin.Email, wf.Kind, wf.ID.Hex())
+
+	return &pb.RetryWorkflowResponse{
+		Workflow: workflowToProto(&wf),
+		Message:  "Workflow will resume shortly",
+	}, nil
+}
+
+func (s *AdminService) findWorkflow(ctx context.Context, id string) (*models.Workflow, error) {
+	workflowID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid workflow ID format")
+	}
+
+	var wf models.Workflow
+	err = s.db.Workflows.FindOne(ctx, bson.M{"_id": workflowID}).Decode(&wf)
+	if err == mongo.ErrNoDocuments {
+		return nil, status.Errorf(codes.NotFound, "workflow not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find workflow")
+	}
+	return &wf, nil
+}
+
+func workflowToProto(wf *models.Workflow) *pb.Workflow {
+	result := &pb.Workflow{
+		Id:             wf.ID.Hex(),
+		Kind:           wf.Kind,
+		UserId:         wf.UserID.String(),
+		Status:         wf.Status,
+		CompletedSteps: wf.CompletedSteps,
+		Attempts:       int32(wf.Attempts),
+		Error:          wf.Error,
+		CreatedAt:      timestamppb.New(wf.CreatedAt),
+		UpdatedAt:      timestamppb.New(wf.UpdatedAt),
+	}
+	if !wf.RequestedBy.IsZero() {
+		result.RequestedBy = wf.RequestedBy.String()
+	}
+	if steps := workflowSteps[wf.Kind]; wf.Status != models.WorkflowDone && wf.Step < len(steps) {
+		result.CurrentStep = steps[wf.Step].name
+	}
+	if wf.CompletedAt != nil {
+		result.CompletedAt = timestamppb.New(*wf.CompletedAt)
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+// Package session is the session domain: issuing session tokens, exchanging
+// refresh tokens and ending sessions. Manager is what services depend on;
+// auth.JWTService implements it, and main wires it in. Store keeps the
+// sessions and refresh tokens, and decides when they end, for the
+// JWTService to sign tokens of.
+package session
+
+import (
+	"context"
+	"time"
+
+	"user-management/models"
+)
+
+// Info describes the client a session token is issued to
+type Info struct {
+	UserAgent string
+	IPAddress string
+	// Method is how the user signed in, such as "password" or "sso"
+	Method string
+}
+
+// Refreshed is the outcome of exchanging a refresh token
+type Refreshed struct {
+	// Token is a new session token of the session, and RefreshToken the
+	// session's next refresh token
+	Token        string
+	RefreshToken string
+	// ExpiresAt is when Token expires
+	ExpiresAt time.Time
+	UserID    models.ID
+	SessionID string
+	// Session is the refreshed session, as recorded after the refresh
+	Session models.Session
+}
+
+// Manager issues, refreshes and revokes sessions
+type Manager interface {
+	// GenerateToken starts a session and returns its token, and its first
+	// refresh token when refresh tokens are enabled
+	GenerateToken(ctx context.Context, userID, email string, info Info) (token, refreshToken string, err error)
+	// GenerateScopedToken issues a short-lived token that only grants scope,
+	// such as finishing two-factor enrollment, to a user who signed in with
+	// method
+	GenerateScopedToken(userID, email, scope, method string) (string, time.Time, error)
+	// Refresh exchanges a refresh token for a new session token
+	Refresh(ctx context.Context, refreshToken string) (Refreshed, error)
+
+	// ActiveSessions lists the sessions of a user that are still usable,
+	// started after validAfter when it is set
+	ActiveSessions(ctx context.Context, userID models.ID, validAfter *time.Time) ([]models.Session, error)
+	RevokeSession(ctx context.Context, userID models.ID, sessionID string) error
+	// RevokeAllSessions revokes every session of a user and returns how
+	// many were active
+	RevokeAllSessions(ctx context.Context, userID models.ID) (int, error)
+	// InvalidateToken ends the session of one token, and
+	// InvalidateAllTokens every token issued to a user so far
+	InvalidateToken(tokenString, userID string) error
+	InvalidateAllTokens(ctx context.Context, userID string) error
+	// SessionEnded returns why the session of a token has ended, or nil
+	// while it lasts, without counting the check as activity
+	SessionEnded(ctx context.Context, tokenString string) error
+}
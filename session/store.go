@@ -0,0 +1,284 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"user-management/database"
+	"user-management/models"
+	"user-management/utils"
+)
+
+// activityResolution is how stale a session's recorded activity may get
+// before a request updates it, so busy sessions don't write on every
+// request
+const activityResolution = time.Minute
+
+// refreshTokenBytes is the entropy of a refresh token
+const refreshTokenBytes = 32
+
+// Errors of the store. auth.JWTService turns them into its token errors.
+var (
+	// ErrUnknown is returned for refresh tokens and sessions that aren't
+	// stored
+	ErrUnknown = errors.New("unknown session")
+	// ErrRevoked is returned once a session or refresh token is revoked
+	ErrRevoked = errors.New("session was revoked")
+	// ErrIdle is returned for sessions not used within the idle timeout
+	ErrIdle = errors.New("session expired after inactivity")
+	// ErrExpired is returned for expired refresh tokens
+	ErrExpired = errors.New("refresh token expired")
+	// ErrTokensRevoked is returned for refresh tokens issued before every
+	// token of the user was revoked
+	ErrTokensRevoked = errors.New("tokens of the user were revoked")
+	// ErrRefreshTokenReused is returned when a refresh token that was
+	// already exchanged is presented again, which means it was copied.
+	// The session it belongs to is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token was already used")
+)
+
+// Accounts are the accounts sessions belong to. database.UserRepository
+// implements it.
+type Accounts interface {
+	// TokensRevoked reports whether the user's tokens issued at or before
+	// issuedAt were revoked
+	TokensRevoked(ctx context.Context, id models.ID, issuedAt time.Time) (bool, error)
+	// FlagRefreshTokenReuse records that a consumed refresh token of the
+	// user was presented again
+	FlagRefreshTokenReuse(ctx context.Context, id models.ID, at time.Time) error
+}
+
+// Store keeps the sessions of session tokens and their refresh tokens.
+// auth.JWTService signs the tokens and records their sessions in a Store.
+type Store struct {
+	Sessions      database.SessionRepository
+	RefreshTokens database.RefreshTokenRepository
+	Accounts      Accounts
+	// IdleTimeout ends sessions that go unused for this long before they
+	// expire. Zero never ends them early.
+	IdleTimeout time.Duration
+	// RefreshTTL is how long refresh tokens last
+	RefreshTTL time.Duration
+}
+
+// Start records a newly issued session
+func (s Store) Start(ctx context.Context, session models.Session) error {
+	if err := s.Sessions.Insert(ctx, session); err != nil {
+		return fmt.Errorf("failed to record session: %v", err)
+	}
+	return nil
+}
+
+// Check returns ErrRevoked once the session of a token is revoked and,
+// with an idle timeout, ErrIdle when it has not been used within it, and
+// records the activity of the others when recordActivity is set. token
+// describes the session as its token does: a token counts as used when it
+// is issued, and the session of a token issued before sessions were
+// tracked is recorded on its first use.
+func (s Store) Check(ctx context.Context, token models.Session, recordActivity bool) error {
+	lastActivity := token.IssuedAt
+	record, err := s.Sessions.FindByID(ctx, token.ID)
+	if err == nil {
+		if record.RevokedAt != nil {
+			return ErrRevoked
+		}
+		lastActivity = record.LastActivityAt
+	} else if err != database.ErrNotFound {
+		return fmt.Errorf("error checking session: %v", err)
+	}
+
+	now := time.Now()
+	if s.IdleTimeout > 0 && now.Sub(lastActivity) > s.IdleTimeout {
+		return ErrIdle
+	}
+	if !recordActivity || (err == nil && now.Sub(lastActivity) < activityResolution) {
+		return nil
+	}
+
+	err = s.Sessions.RecordActivity(ctx, models.Session{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		IssuedAt:  token.IssuedAt,
+		ExpiresAt: token.ExpiresAt,
+	}, now)
+	if err != nil && !database.IsDuplicate(err) {
+		return fmt.Errorf("error recording session activity: %v", err)
+	}
+	return nil
+}
+
+// Active returns the sessions of userID that are still usable, most
+// recently used first. Sessions issued at or before validAfter were
+// revoked along with every other token of the user.
+func (s Store) Active(ctx context.Context, userID models.ID, validAfter *time.Time) ([]models.Session, error) {
+	now := time.Now()
+	var issuedAfter, activeSince time.Time
+	if validAfter != nil {
+		issuedAfter = *validAfter
+	}
+	if s.IdleTimeout > 0 {
+		activeSince = now.Add(-s.IdleTimeout)
+	}
+	return s.Sessions.ListActive(ctx, userID, now, issuedAfter, activeSince)
+}
+
+// Revoke revokes one of userID's sessions and its refresh tokens. It
+// returns database.ErrNotFound when the user has no such session or it is
+// already revoked.
+func (s Store) Revoke(ctx context.Context, userID models.ID, sessionID string) error {
+	now := time.Now()
+	revokeErr := s.Sessions.Revoke(ctx, userID, sessionID, now)
+	if revokeErr != nil && revokeErr != database.ErrNotFound {
+		return revokeErr
+	}
+	// The refresh tokens are revoked even when the session was, in case
+	// revoking them failed then
+	if err := s.RefreshTokens.RevokeSession(ctx, userID, sessionID, now); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %v", err)
+	}
+	return revokeErr
+}
+
+// RevokeAll revokes every unexpired session of userID, and their refresh
+// tokens, and returns how many there were
+func (s Store) RevokeAll(ctx context.Context, userID models.ID) (int, error) {
+	now := time.Now()
+	revoked, err := s.Sessions.RevokeAll(ctx, userID, now)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.RefreshTokens.RevokeUser(ctx, userID, now); err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %v", err)
+	}
+	return revoked, nil
+}
+
+// IssueRefreshToken stores a new refresh token of a session
+func (s Store) IssueRefreshToken(ctx context.Context, userID models.ID, sessionID string, now time.Time) (string, error) {
+	refreshToken, err := utils.GenerateSecureToken(refreshTokenBytes)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.RefreshTokens.Insert(ctx, models.RefreshToken{
+		TokenHash: utils.HashToken(refreshToken),
+		SessionID: sessionID,
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.RefreshTTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store refresh token: %v", err)
+	}
+	return refreshToken, nil
+}
+
+// Exchange consumes a refresh token, extends its session and issues the
+// session's next refresh token. It returns the user, session and next
+// refresh token, for the caller to issue a session token of the session.
+// Each refresh token is exchanged once. Presenting one again returns
+// ErrRefreshTokenReused, with the user and session it belonged to set:
+// the session and all its refresh tokens are revoked, and the account is
+// flagged.
+func (s Store) Exchange(ctx context.Context, refreshToken string) (Refreshed, error) {
+	now := time.Now()
+	tokenHash := utils.HashToken(refreshToken)
+	stored, err := s.usableRefreshToken(ctx, tokenHash, now)
+	if err != nil {
+		return Refreshed{UserID: stored.UserID, SessionID: stored.SessionID}, err
+	}
+
+	revoked, err := s.Accounts.TokensRevoked(ctx, stored.UserID, stored.CreatedAt)
+	if err != nil {
+		return Refreshed{}, fmt.Errorf("error checking token revocation: %v", err)
+	}
+	if revoked {
+		return Refreshed{}, ErrTokensRevoked
+	}
+
+	record, err := s.Sessions.FindByID(ctx, stored.SessionID)
+	if err == database.ErrNotFound {
+		return Refreshed{}, ErrUnknown
+	}
+	if err != nil {
+		return Refreshed{}, fmt.Errorf("error checking session: %v", err)
+	}
+	if record.RevokedAt != nil {
+		return Refreshed{}, ErrRevoked
+	}
+	if s.IdleTimeout > 0 && now.Sub(record.LastActivityAt) > s.IdleTimeout {
+		return Refreshed{}, ErrIdle
+	}
+
+	// Consuming the token only if it is still unused lets one of
+	// concurrent exchanges succeed. The others count as reuse.
+	err = s.RefreshTokens.Consume(ctx, stored.ID, now)
+	if err != nil && err != database.ErrNotFound {
+		return Refreshed{}, fmt.Errorf("error consuming refresh token: %v", err)
+	}
+	if err == database.ErrNotFound {
+		stored, err = s.usableRefreshToken(ctx, tokenHash, now)
+		if err == nil {
+			err = ErrUnknown
+		}
+		return Refreshed{UserID: stored.UserID, SessionID: stored.SessionID}, err
+	}
+
+	nextRefreshToken, err := s.IssueRefreshToken(ctx, stored.UserID, stored.SessionID, now)
+	if err != nil {
+		return Refreshed{}, err
+	}
+	err = s.Sessions.Extend(ctx, stored.SessionID, now, now.Add(s.RefreshTTL))
+	if err != nil {
+		return Refreshed{}, fmt.Errorf("error recording session activity: %v", err)
+	}
+	if now.After(record.LastActivityAt) {
+		record.LastActivityAt = now
+	}
+	if expiresAt := now.Add(s.RefreshTTL); expiresAt.After(record.ExpiresAt) {
+		record.ExpiresAt = expiresAt
+	}
+
+	return Refreshed{
+		RefreshToken: nextRefreshToken,
+		UserID:       stored.UserID,
+		SessionID:    stored.SessionID,
+		Session:      *record,
+	}, nil
+}
+
+// usableRefreshToken returns the stored refresh token with tokenHash if it
+// can be exchanged. A token that was exchanged before revokes its session
+// and flags the account, and ErrRefreshTokenReused is returned with the
+// token.
+func (s Store) usableRefreshToken(ctx context.Context, tokenHash string, now time.Time) (models.RefreshToken, error) {
+	found, err := s.RefreshTokens.FindByHash(ctx, tokenHash)
+	var stored models.RefreshToken
+	if found != nil {
+		stored = *found
+	}
+	switch {
+	case err == database.ErrNotFound:
+		return models.RefreshToken{}, ErrUnknown
+	case err != nil:
+		return models.RefreshToken{}, fmt.Errorf("error checking refresh token: %v", err)
+	case !now.Before(stored.ExpiresAt):
+		return models.RefreshToken{}, ErrExpired
+	case stored.ConsumedAt == nil && stored.RevokedAt != nil:
+		return models.RefreshToken{}, ErrRevoked
+	case stored.ConsumedAt == nil:
+		return stored, nil
+	}
+
+	err = s.Revoke(ctx, stored.UserID, stored.SessionID)
+	if err != nil && err != database.ErrNotFound {
+		return stored, fmt.Errorf("failed to revoke session: %v", err)
+	}
+	err = s.Accounts.FlagRefreshTokenReuse(ctx, stored.UserID, now)
+	if err != nil && err != database.ErrNotFound {
+		return stored, fmt.Errorf("failed to flag account: %v", err)
+	}
+	return stored, ErrRefreshTokenReused
+}
@@ -0,0 +1,387 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"user-management/database"
+	"user-management/models"
+)
+
+const testUserID models.ID = "507f1f77bcf86cd799439011"
+
+// fakeSessions keeps sessions by ID
+type fakeSessions map[string]models.Session
+
+func (f fakeSessions) Insert(ctx context.Context, session models.Session) error {
+	f[session.ID] = session
+	return nil
+}
+
+func (f fakeSessions) FindByID(ctx context.Context, id string) (*models.Session, error) {
+	session, ok := f[id]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return &session, nil
+}
+
+func (f fakeSessions) RecordActivity(ctx context.Context, session models.Session, at time.Time) error {
+	if stored, ok := f[session.ID]; ok {
+		session = stored
+	}
+	session.LastActivityAt = at
+	f[session.ID] = session
+	return nil
+}
+
+func (f fakeSessions) Extend(ctx context.Context, id string, at, expiresAt time.Time) error {
+	session := f[id]
+	session.LastActivityAt = at
+	session.ExpiresAt = expiresAt
+	f[id] = session
+	return nil
+}
+
+func (f fakeSessions) ListActive(ctx context.Context, userID models.ID, now, issuedAfter, activeSince time.Time) ([]models.Session, error) {
+	var active []models.Session
+	for _, session := range f {
+		if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(now) &&
+			session.IssuedAt.After(issuedAfter) && !session.LastActivityAt.Before(activeSince) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+func (f fakeSessions) Revoke(ctx context.Context, userID models.ID, id string, at time.Time) error {
+	session, ok := f[id]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return database.ErrNotFound
+	}
+	session.RevokedAt = &at
+	f[id] = session
+	return nil
+}
+
+func (f fakeSessions) RevokeAll(ctx context.Context, userID models.ID, at time.Time) (int, error) {
+	revoked := 0
+	for id, session := range f {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+			f[id] = session
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (f fakeSessions) Revoked(ctx context.Context, ids []string) ([]string, error) {
+	var revoked []string
+	for _, id := range ids {
+		if f[id].RevokedAt != nil {
+			revoked = append(revoked, id)
+		}
+	}
+	return revoked, nil
+}
+
+// fakeRefreshTokens keeps refresh tokens by hash
+type fakeRefreshTokens map[string]models.RefreshToken
+
+func (f fakeRefreshTokens) Insert(ctx context.Context, token models.RefreshToken) error {
+	token.ID = primitive.NewObjectID()
+	f[token.TokenHash] = token
+	return nil
+}
+
+func (f fakeRefreshTokens) FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	token, ok := f[hash]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return &token, nil
+}
+
+func (f fakeRefreshTokens) Consume(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	for hash, token := range f {
+		if token.ID == id && token.ConsumedAt == nil && token.RevokedAt == nil {
+			token.ConsumedAt = &at
+			f[hash] = token
+			return nil
+		}
+	}
+	return database.ErrNotFound
+}
+
+func (f fakeRefreshTokens) RevokeSession(ctx context.Context, userID models.ID, sessionID string, at time.Time) error {
+	for hash, token := range f {
+		if token.UserID == userID && token.SessionID == sessionID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			f[hash] = token
+		}
+	}
+	return nil
+}
+
+func (f fakeRefreshTokens) RevokeUser(ctx context.Context, userID models.ID, at time.Time) error {
+	for hash, token := range f {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			f[hash] = token
+		}
+	}
+	return nil
+}
+
+// fakeAccounts revokes the tokens issued before tokensValidAfter, and
+// records the accounts flagged for refresh token reuse
+type fakeAccounts struct {
+	tokensValidAfter time.Time
+	flagged          []models.ID
+}
+
+func (f *fakeAccounts) TokensRevoked(ctx context.Context, id models.ID, issuedAt time.Time) (bool, error) {
+	return !issuedAt.After(f.tokensValidAfter), nil
+}
+
+func (f *fakeAccounts) FlagRefreshTokenReuse(ctx context.Context, id models.ID, at time.Time) error {
+	f.flagged = append(f.flagged, id)
+	return nil
+}
+
+// newTestStore returns a Store of fakes holding a session of testUserID
+// issued at issuedAt
+func newTestStore(t *testing.T, issuedAt time.Time) (Store, *fakeAccounts) {
+	t.Helper()
+	accounts := &fakeAccounts{}
+	s := Store{
+		Sessions:      fakeSessions{},
+		RefreshTokens: fakeRefreshTokens{},
+		Accounts:      accounts,
+		IdleTimeout:   30 * time.Minute,
+		RefreshTTL:    24 * time.Hour,
+	}
+	err := s.Start(context.Background(), models.Session{
+		ID:             "session",
+		UserID:         testUserID,
+		IssuedAt:       issuedAt,
+		LastActivityAt: issuedAt,
+		ExpiresAt:      issuedAt.Add(s.RefreshTTL),
+	})
+	if err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	return s, accounts
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		revoke   bool
+		wantErr  error
+	}{
+		{
+			name:     "active session",
+			issuedAt: now.Add(-time.Minute),
+		},
+		{
+			name:     "idle session",
+			issuedAt: now.Add(-time.Hour),
+			wantErr:  ErrIdle,
+		},
+		{
+			name:     "revoked session",
+			issuedAt: now.Add(-time.Minute),
+			revoke:   true,
+			wantErr:  ErrRevoked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, _ := newTestStore(t, tt.issuedAt)
+			if tt.revoke {
+				if err := s.Revoke(ctx, testUserID, "session"); err != nil {
+					t.Fatalf("Revoke() = %v", err)
+				}
+			}
+
+			token := models.Session{ID: "session", UserID: testUserID, IssuedAt: tt.issuedAt, ExpiresAt: now.Add(time.Hour)}
+			if err := s.Check(ctx, token, true); err != tt.wantErr {
+				t.Fatalf("Check() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				record, _ := s.Sessions.FindByID(ctx, "session")
+				if !record.LastActivityAt.After(tt.issuedAt) {
+					t.Errorf("last activity = %v, want it recorded", record.LastActivityAt)
+				}
+			}
+		})
+	}
+}
+
+// The session of a token issued before sessions were tracked is recorded
+// when it is first used
+func TestCheckUntrackedSession(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestStore(t, time.Now())
+	issuedAt := time.Now().Add(-time.Minute)
+	token := models.Session{ID: "untracked", UserID: testUserID, IssuedAt: issuedAt, ExpiresAt: issuedAt.Add(time.Hour)}
+
+	if err := s.Check(ctx, token, true); err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+	if _, err := s.Sessions.FindByID(ctx, "untracked"); err != nil {
+		t.Errorf("FindByID() = %v, want the session recorded", err)
+	}
+}
+
+func TestExchange(t *testing.T) {
+	ctx := context.Background()
+	issuedAt := time.Now().Add(-time.Minute)
+	s, _ := newTestStore(t, issuedAt)
+	refreshToken, err := s.IssueRefreshToken(ctx, testUserID, "session", issuedAt)
+	if err != nil {
+		t.Fatalf("IssueRefreshToken() = %v", err)
+	}
+
+	refreshed, err := s.Exchange(ctx, refreshToken)
+	if err != nil {
+		t.Fatalf("Exchange() = %v", err)
+	}
+	if refreshed.UserID != testUserID || refreshed.SessionID != "session" {
+		t.Errorf("Exchange() = user %q, session %q, want %q, %q", refreshed.UserID, refreshed.SessionID, testUserID, "session")
+	}
+	if refreshed.RefreshToken == "" || refreshed.RefreshToken == refreshToken {
+		t.Errorf("Exchange() = refresh token %q, want a new one", refreshed.RefreshToken)
+	}
+	if !refreshed.Session.ExpiresAt.After(issuedAt.Add(s.RefreshTTL)) {
+		t.Errorf("session expires at %v, want it extended", refreshed.Session.ExpiresAt)
+	}
+
+	if _, err := s.Exchange(ctx, refreshed.RefreshToken); err != nil {
+		t.Errorf("Exchange() of the next refresh token = %v", err)
+	}
+}
+
+// Presenting an exchanged refresh token again revokes its session and
+// flags the account
+func TestExchangeReuse(t *testing.T) {
+	ctx := context.Background()
+	s, accounts := newTestStore(t, time.Now())
+	refreshToken, err := s.IssueRefreshToken(ctx, testUserID, "session", time.Now())
+	if err != nil {
+		t.Fatalf("IssueRefreshToken() = %v", err)
+	}
+	refreshed, err := s.Exchange(ctx, refreshToken)
+	if err != nil {
+		t.Fatalf("Exchange() = %v", err)
+	}
+
+	reused, err := s.Exchange(ctx, refreshToken)
+	if err != ErrRefreshTokenReused {
+		t.Fatalf("Exchange() of a used token = %v, want %v", err, ErrRefreshTokenReused)
+	}
+	if reused.UserID != testUserID || reused.SessionID != "session" {
+		t.Errorf("Exchange() of a used token = user %q, session %q, want %q, %q", reused.UserID, reused.SessionID, testUserID, "session")
+	}
+	if len(accounts.flagged) != 1 || accounts.flagged[0] != testUserID {
+		t.Errorf("flagged accounts = %v, want %v", accounts.flagged, []models.ID{testUserID})
+	}
+	if _, err := s.Exchange(ctx, refreshed.RefreshToken); err != ErrRevoked {
+		t.Errorf("Exchange() of the next token after reuse = %v, want %v", err, ErrRevoked)
+	}
+}
+
+func TestExchangeRejected(t *testing.T) {
+	issuedAt := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name string
+		// prepare changes the store after the refresh token is issued
+		prepare func(s Store, accounts *fakeAccounts)
+		wantErr error
+	}{
+		{
+			name: "revoked session",
+			prepare: func(s Store, accounts *fakeAccounts) {
+				s.Sessions.Revoke(context.Background(), testUserID, "session", time.Now())
+			},
+			wantErr: ErrRevoked,
+		},
+		{
+			name: "tokens of the user revoked",
+			prepare: func(s Store, accounts *fakeAccounts) {
+				accounts.tokensValidAfter = time.Now()
+			},
+			wantErr: ErrTokensRevoked,
+		},
+		{
+			name: "idle session",
+			prepare: func(s Store, accounts *fakeAccounts) {
+				session := s.Sessions.(fakeSessions)["session"]
+				session.LastActivityAt = time.Now().Add(-time.Hour)
+				s.Sessions.(fakeSessions)["session"] = session
+			},
+			wantErr: ErrIdle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, accounts := newTestStore(t, issuedAt)
+			accounts.tokensValidAfter = issuedAt.Add(-time.Hour)
+			refreshToken, err := s.IssueRefreshToken(ctx, testUserID, "session", issuedAt)
+			if err != nil {
+				t.Fatalf("IssueRefreshToken() = %v", err)
+			}
+			tt.prepare(s, accounts)
+
+			if _, err := s.Exchange(ctx, refreshToken); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Exchange() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExchangeUnknown(t *testing.T) {
+	s, _ := newTestStore(t, time.Now())
+	if _, err := s.Exchange(context.Background(), "unknown"); err != ErrUnknown {
+		t.Errorf("Exchange() = %v, want %v", err, ErrUnknown)
+	}
+}
+
+func TestRevokeAll(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestStore(t, time.Now())
+	refreshToken, err := s.IssueRefreshToken(ctx, testUserID, "session", time.Now())
+	if err != nil {
+		t.Fatalf("IssueRefreshToken() = %v", err)
+	}
+
+	revoked, err := s.RevokeAll(ctx, testUserID)
+	if err != nil {
+		t.Fatalf("RevokeAll() = %v", err)
+	}
+	if revoked != 1 {
+		t.Errorf("RevokeAll() = %d, want 1", revoked)
+	}
+	active, err := s.Active(ctx, testUserID, nil)
+	if err != nil {
+		t.Fatalf("Active() = %v", err)
+	}
+	if len(active) != 0 {
+		t.Errorf("Active() = %v, want none", active)
+	}
+	if _, err := s.Exchange(ctx, refreshToken); err != ErrRevoked {
+		t.Errorf("Exchange() after revoking = %v, want %v", err, ErrRevoked)
+	}
+}
@@ -0,0 +1,254 @@
+// Package social signs users in with an account at a social provider,
+// verifying the token the client got from the provider.
+package social
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// Providers users can sign in with
+const (
+	ProviderGoogle = "google"
+	ProviderGitHub = "github"
+)
+
+// Issuers of each provider's identities
+const (
+	GoogleIssuer = "https://accounts.google.com"
+	GitHubIssuer = "https://github.com"
+)
+
+// githubAPI is the base URL of the GitHub REST API
+const githubAPI = "https://api.github.com"
+
+var (
+	// ErrUnknownProvider is returned for a provider this package doesn't
+	// support
+	ErrUnknownProvider = errors.New("unknown provider")
+	// ErrInvalidToken is returned when the provider rejects the token, or
+	// it was issued to another client
+	ErrInvalidToken = errors.New("provider rejected the token")
+)
+
+// Identity is a user as the provider describes them
+type Identity struct {
+	Provider string
+	Issuer   string
+	// Subject identifies the user at the provider and never changes
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Config holds the clients registered with each provider. A provider
+// without a client is disabled.
+type Config struct {
+	// GoogleClientID is the client Google ID tokens must be issued to
+	GoogleClientID string
+	// GitHubClientID and GitHubClientSecret are the OAuth app GitHub
+	// access tokens must belong to
+	GitHubClientID     string
+	GitHubClientSecret string
+}
+
+// Verifier checks tokens with the provider that issued them
+type Verifier struct {
+	config     Config
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	google *oidc.Provider
+}
+
+func NewVerifier(config Config) *Verifier {
+	return &Verifier{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Known reports whether provider is supported by this package
+func Known(provider string) bool {
+	return provider == ProviderGoogle || provider == ProviderGitHub
+}
+
+// Enabled reports whether a client is configured for provider. A nil
+// Verifier enables none.
+func (v *Verifier) Enabled(provider string) bool {
+	if v == nil {
+		return false
+	}
+	switch provider {
+	case ProviderGoogle:
+		return v.config.GoogleClientID != ""
+	case ProviderGitHub:
+		return v.config.GitHubClientID != "" && v.config.GitHubClientSecret != ""
+	}
+	return false
+}
+
+// Verify returns the identity a token proves: a Google ID token or a
+// GitHub access token. It returns ErrInvalidToken when the provider
+// rejects the token.
+func (v *Verifier) Verify(ctx context.Context, provider, token string) (*Identity, error) {
+	switch provider {
+	case ProviderGoogle:
+		return v.verifyGoogle(ctx, token)
+	case ProviderGitHub:
+		return v.verifyGitHub(ctx, token)
+	}
+	return nil, ErrUnknownProvider
+}
+
+// verifyGoogle checks the signature, issuer, audience and expiry of a
+// Google ID token
+func (v *Verifier) verifyGoogle(ctx context.Context, idToken string) (*Identity, error) {
+	provider, err := v.googleProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := provider.Verifier(&oidc.Config{ClientID: v.config.GoogleClientID}).Verify(oidc.ClientContext(ctx, v.httpClient), idToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := token.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return &Identity{
+		Provider:      ProviderGoogle,
+		Issuer:        GoogleIssuer,
+		Subject:       token.Subject,
+		Email:         strings.ToLower(claims.Email),
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// googleProvider fetches Google's discovery document once. The provider
+// refreshes its signing keys itself.
+func (v *Verifier) googleProvider(ctx context.Context) (*oidc.Provider, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.google == nil {
+		provider, err := oidc.NewProvider(oidc.ClientContext(ctx, v.httpClient), GoogleIssuer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover Google: %v", err)
+		}
+		v.google = provider
+	}
+	return v.google, nil
+}
+
+// verifyGitHub checks that an access token belongs to the configured OAuth
+// app, then reads the user and their primary email address
+func (v *Verifier) verifyGitHub(ctx context.Context, accessToken string) (*Identity, error) {
+	// A token of another app would let that app sign its users in here
+	body, err := json.Marshal(map[string]string{"access_token": accessToken})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		githubAPI+"/applications/"+url.PathEscape(v.config.GitHubClientID)+"/token", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(v.config.GitHubClientID, v.config.GitHubClientSecret)
+	if err := v.githubCall(req, nil); err != nil {
+		return nil, err
+	}
+
+	var user struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := v.githubGet(ctx, accessToken, "/user", &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("%w: no user ID", ErrInvalidToken)
+	}
+
+	// Needs the user:email scope
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := v.githubGet(ctx, accessToken, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: ProviderGitHub,
+		Issuer:   GitHubIssuer,
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     user.Name,
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = strings.ToLower(email.Email)
+			identity.EmailVerified = email.Verified
+		}
+	}
+	return identity, nil
+}
+
+func (v *Verifier) githubGet(ctx context.Context, accessToken, path string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, githubAPI+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	return v.githubCall(req, result)
+}
+
+// githubCall sends a request to the GitHub API and decodes the response
+// into result, if not nil. Rejected credentials return ErrInvalidToken.
+func (v *Verifier) githubCall(req *http.Request, result any) error {
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach GitHub: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden ||
+		resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity:
+		return fmt.Errorf("%w: GitHub returned %s for %s", ErrInvalidToken, resp.Status, req.URL.Path)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("GitHub returned %s for %s", resp.Status, req.URL.Path)
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %v", err)
+	}
+	return nil
+}
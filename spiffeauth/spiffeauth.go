@@ -0,0 +1,256 @@
+// Package spiffeauth authenticates services in a service mesh by their
+// SPIFFE ID, so they can call the internal listener without a bearer
+// token. Each connection must present an X.509 SVID, a client certificate
+// carrying the service's SPIFFE ID, verified against the trust domain's
+// bundle. The ID is mapped to a service account, whose scopes name the
+// RPCs it may call.
+package spiffeauth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Account is a service allowed to call the internal listener
+type Account struct {
+	// SPIFFEID identifies the service, such as
+	// "spiffe://example.org/ns/billing/sa/api"
+	SPIFFEID string `json:"spiffe_id"`
+	// Scopes are the RPCs the service may call, each a whole service such
+	// as "user.AdminService" or one method such as
+	// "/user.AdminService/ListUsers"
+	Scopes []string `json:"scopes"`
+}
+
+// Allows reports whether the account may call method, by full name
+func (a Account) Allows(method string) bool {
+	for _, scope := range a.Scopes {
+		if scope == method || strings.HasPrefix(method, "/"+scope+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// accountsFile is the service accounts file
+type accountsFile struct {
+	Accounts []Account `json:"accounts"`
+}
+
+// LoadAccounts reads the service accounts of trustDomain from a JSON file
+func LoadAccounts(path, trustDomain string) ([]Account, error) {
+	td, err := spiffeid.TrustDomainFromString(trustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust domain %q: %v", trustDomain, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service accounts: %v", err)
+	}
+	var file accountsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse service accounts %s: %v", path, err)
+	}
+
+	seen := make(map[string]bool, len(file.Accounts))
+	for _, account := range file.Accounts {
+		id, err := spiffeid.FromString(account.SPIFFEID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPIFFE ID %q: %v", account.SPIFFEID, err)
+		}
+		if !id.MemberOf(td) {
+			return nil, fmt.Errorf("SPIFFE ID %s is not in trust domain %s", id, td)
+		}
+		if seen[id.String()] {
+			return nil, fmt.Errorf("SPIFFE ID %s is listed twice", id)
+		}
+		seen[id.String()] = true
+		if len(account.Scopes) == 0 {
+			return nil, fmt.Errorf("SPIFFE ID %s has no scopes", id)
+		}
+		for _, scope := range account.Scopes {
+			if !validScope(scope) {
+				return nil, fmt.Errorf("SPIFFE ID %s has invalid scope %q, expected a service or /service/method", id, scope)
+			}
+		}
+	}
+	return file.Accounts, nil
+}
+
+func validScope(scope string) bool {
+	if method, ok := strings.CutPrefix(scope, "/"); ok {
+		service, name, ok := strings.Cut(method, "/")
+		return ok && service != "" && name != "" && !strings.Contains(name, "/")
+	}
+	return scope != "" && !strings.Contains(scope, "/")
+}
+
+// Authenticator verifies SVIDs against the trust domain's bundle and maps
+// their IDs to service accounts
+type Authenticator struct {
+	trustDomain spiffeid.TrustDomain
+	bundleFile  string
+	bundle      atomic.Pointer[x509bundle.Bundle]
+	accounts    map[spiffeid.ID]Account
+}
+
+// New loads the bundle of trustDomain's CA certificates from bundleFile
+func New(trustDomain, bundleFile string, accounts []Account) (*Authenticator, error) {
+	td, err := spiffeid.TrustDomainFromString(trustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust domain %q: %v", trustDomain, err)
+	}
+
+	a := &Authenticator{
+		trustDomain: td,
+		bundleFile:  bundleFile,
+		accounts:    make(map[spiffeid.ID]Account, len(accounts)),
+	}
+	for _, account := range accounts {
+		id, err := spiffeid.FromString(account.SPIFFEID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPIFFE ID %q: %v", account.SPIFFEID, err)
+		}
+		a.accounts[id] = account
+	}
+	if err := a.Reload(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Reload reads the bundle again, for CA rotations. On error the previous
+// bundle stays in use.
+func (a *Authenticator) Reload() error {
+	bundle, err := x509bundle.Load(a.trustDomain, a.bundleFile)
+	if err != nil {
+		return fmt.Errorf("failed to load SPIFFE bundle: %v", err)
+	}
+	if bundle.Empty() {
+		return fmt.Errorf("SPIFFE bundle %s has no certificates", a.bundleFile)
+	}
+	a.bundle.Store(bundle)
+	return nil
+}
+
+// TLSConfig returns base, the config of a listener's own certificates,
+// requiring clients to present an SVID of the trust domain
+func (a *Authenticator) TLSConfig(base *tls.Config) *tls.Config {
+	config := base.Clone()
+	a.requireSVID(config)
+	if getConfig := base.GetConfigForClient; getConfig != nil {
+		config.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			current, err := getConfig(hello)
+			if err != nil || current == nil {
+				return current, err
+			}
+			current = current.Clone()
+			a.requireSVID(current)
+			return current, nil
+		}
+	}
+	return config
+}
+
+// requireSVID verifies client certificates as SVIDs instead of against
+// the config's client CAs
+func (a *Authenticator) requireSVID(config *tls.Config) {
+	config.ClientAuth = tls.RequireAnyClientCert
+	config.ClientCAs = nil
+	config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		_, _, err := x509svid.ParseAndVerify(rawCerts, a.bundle.Load())
+		return err
+	}
+}
+
+// UnaryServerInterceptor rejects calls on the internal listener from
+// services without an account, or to RPCs outside the account's scopes.
+// The account is stored in the context for FromContext.
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		account, err := a.accountOf(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !account.Allows(info.FullMethod) {
+			return nil, status.Errorf(codes.PermissionDenied, "service %s may not call %s", account.SPIFFEID, info.FullMethod)
+		}
+		return handler(NewContext(ctx, account), req)
+	}
+}
+
+// StreamServerInterceptor checks the callers of streaming RPCs like
+// UnaryServerInterceptor
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		account, err := a.accountOf(stream.Context())
+		if err != nil {
+			return err
+		}
+		if !account.Allows(info.FullMethod) {
+			return status.Errorf(codes.PermissionDenied, "service %s may not call %s", account.SPIFFEID, info.FullMethod)
+		}
+		return handler(srv, &serverStream{ServerStream: stream, ctx: NewContext(stream.Context(), account)})
+	}
+}
+
+// serverStream is a stream whose handler sees ctx
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// accountOf returns the account of the SVID the caller presented. The
+// certificate was verified during the handshake.
+func (a *Authenticator) accountOf(ctx context.Context) (Account, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return Account{}, status.Errorf(codes.Unauthenticated, "missing SPIFFE ID")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return Account{}, status.Errorf(codes.Unauthenticated, "missing SPIFFE ID")
+	}
+	id, err := x509svid.IDFromCert(tlsInfo.State.PeerCertificates[0])
+	if err != nil {
+		return Account{}, status.Errorf(codes.Unauthenticated, "missing SPIFFE ID")
+	}
+	account, ok := a.accounts[id]
+	if !ok {
+		return Account{}, status.Errorf(codes.PermissionDenied, "service %s has no account", id)
+	}
+	return account, nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the calling service's account
+func NewContext(ctx context.Context, account Account) context.Context {
+	return context.WithValue(ctx, contextKey{}, account)
+}
+
+// FromContext returns the account of a call authenticated by SPIFFE ID
+func FromContext(ctx context.Context) (Account, bool) {
+	account, ok := ctx.Value(contextKey{}).(Account)
+	return account, ok
+}
@@ -0,0 +1,224 @@
+// Package sso signs users in through their organization's OpenID Connect
+// identity provider.
+package sso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Protocols an organization can connect its identity provider with
+const (
+	ProtocolOIDC = "oidc"
+)
+
+// discoveryTTL is how long a provider's discovery document is reused
+// before it is fetched again
+const discoveryTTL = time.Hour
+
+// ErrLoginFailed is returned when the identity provider's response can't
+// be used to sign the user in
+var ErrLoginFailed = errors.New("sso login failed")
+
+// Connection is how an organization's identity provider is reached
+type Connection struct {
+	// Issuer is the provider's issuer URL, where its discovery document
+	// is published
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+}
+
+// Identity is a user as the identity provider describes them
+type Identity struct {
+	Issuer string
+	// Subject identifies the user at the issuer and never changes
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	// Claims are all the claims of the ID token
+	Claims map[string]any
+}
+
+// Client runs logins against any number of providers, which redirect back
+// to one RedirectURL
+type Client struct {
+	redirectURL string
+	httpClient  *http.Client
+
+	mu        sync.Mutex
+	providers map[string]cachedProvider
+}
+
+type cachedProvider struct {
+	provider  *oidc.Provider
+	fetchedAt time.Time
+}
+
+func NewClient(redirectURL string) *Client {
+	return &Client{
+		redirectURL: redirectURL,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		providers:   make(map[string]cachedProvider),
+	}
+}
+
+// AuthCodeURL returns the page of the provider to send the user to. The
+// provider sends them back to the redirect URL with the state and a code
+// for Exchange. The nonce and verifier must be kept until then.
+func (c *Client) AuthCodeURL(ctx context.Context, conn Connection, state, nonce, verifier string) (string, error) {
+	config, _, err := c.oauthConfig(ctx, conn)
+	if err != nil {
+		return "", err
+	}
+	return config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange trades the code the provider sent back for the user's identity.
+// It returns ErrLoginFailed when the code or the ID token is rejected.
+func (c *Client) Exchange(ctx context.Context, conn Connection, code, nonce, verifier string) (*Identity, error) {
+	config, provider, err := c.oauthConfig(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = oidc.ClientContext(ctx, c.httpClient)
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoginFailed, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: no ID token in the token response", ErrLoginFailed)
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: conn.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoginFailed, err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrLoginFailed)
+	}
+	return identityOf(idToken)
+}
+
+// PasswordLogin signs a user in at the provider with their username and
+// password, using the resource owner password grant. It returns
+// ErrLoginFailed when the provider rejects the password or the ID token
+// is invalid.
+func (c *Client) PasswordLogin(ctx context.Context, conn Connection, username, password string) (*Identity, error) {
+	config, provider, err := c.oauthConfig(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = oidc.ClientContext(ctx, c.httpClient)
+	token, err := config.PasswordCredentialsToken(ctx, username, password)
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			return nil, fmt.Errorf("%w: %v", ErrLoginFailed, err)
+		}
+		return nil, fmt.Errorf("failed to get token from %s: %v", conn.Issuer, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: no ID token in the token response", ErrLoginFailed)
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: conn.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoginFailed, err)
+	}
+	return identityOf(idToken)
+}
+
+// identityOf reads the user's identity from a verified ID token
+func identityOf(idToken *oidc.IDToken) (*Identity, error) {
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoginFailed, err)
+	}
+
+	identity := &Identity{
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+		Claims:  claims,
+	}
+	identity.Email, _ = claims["email"].(string)
+	identity.Email = strings.ToLower(identity.Email)
+	identity.EmailVerified, _ = claims["email_verified"].(bool)
+	identity.Name, _ = claims["name"].(string)
+	return identity, nil
+}
+
+func (c *Client) oauthConfig(ctx context.Context, conn Connection) (*oauth2.Config, *oidc.Provider, error) {
+	provider, err := c.provider(ctx, conn.Issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     conn.ClientID,
+		ClientSecret: conn.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  c.redirectURL,
+		Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+	}, provider, nil
+}
+
+// provider returns the provider of issuer, fetching its discovery document
+// when it isn't cached
+func (c *Client) provider(ctx context.Context, issuer string) (*oidc.Provider, error) {
+	c.mu.Lock()
+	cached, ok := c.providers[issuer]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < discoveryTTL {
+		return cached.provider, nil
+	}
+
+	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, c.httpClient), issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover provider %s: %v", issuer, err)
+	}
+
+	c.mu.Lock()
+	c.providers[issuer] = cachedProvider{provider: provider, fetchedAt: time.Now()}
+	c.mu.Unlock()
+	return provider, nil
+}
+
+// ValidateDomain checks an email domain an organization claims
+func ValidateDomain(domain string) error {
+	if domain == "" || len(domain) > 253 || !strings.Contains(domain, ".") {
+		return fmt.Errorf("%q is not a domain", domain)
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("%q is not a domain", domain)
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return fmt.Errorf("%q is not a lower-case domain", domain)
+			}
+		}
+	}
+	return nil
+}
+
+// EmailDomain returns the lower-case domain of an email address
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
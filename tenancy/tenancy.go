@@ -0,0 +1,346 @@
+// Package tenancy verifies that RPCs made for an organization only touch
+// that organization's data. Every query such an RPC makes on a collection
+// holding organizations' data must pin the organization, and a query
+// naming another organization is a cross-tenant access attempt. Both are
+// recorded as critical audit events.
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+
+	"user-management/audit"
+	"user-management/database"
+	"user-management/metrics"
+	"user-management/models"
+)
+
+// Modes of the verifier
+const (
+	// ModeOff skips the checks
+	ModeOff = "off"
+	// ModeLog records violations and lets the queries run
+	ModeLog = "log"
+	// ModeEnforce also fails the queries, for test and staging deployments
+	ModeEnforce = "enforce"
+)
+
+// Kinds of violations
+const (
+	// ViolationMissingPredicate is a query that doesn't pin the
+	// organization
+	ViolationMissingPredicate = "missing_predicate"
+	// ViolationCrossTenant is a query naming another organization
+	ViolationCrossTenant = "cross_tenant"
+)
+
+// ErrViolation fails queries that break tenant isolation in ModeEnforce
+var ErrViolation = errors.New("tenant isolation violation")
+
+// ScopedCollection is a collection holding organizations' data
+type ScopedCollection struct {
+	// Name is the collection's name, and Field its database.Database field
+	Name  string
+	Field string
+	// Key is the field of its documents naming their organization
+	Key string
+}
+
+// ScopedCollections are the collections whose queries must pin the
+// organization
+var ScopedCollections = []ScopedCollection{
+	{Name: "users", Field: "Users", Key: "org_id"},
+	{Name: "organizations", Field: "Orgs", Key: "_id"},
+	{Name: "profile_change_requests", Field: "Changes", Key: "org_id"},
+	{Name: "deprovisioning_jobs", Field: "Deprovisioning", Key: "org_id"},
+}
+
+// Violation is one query that broke tenant isolation
+type Violation struct {
+	Kind       string
+	Collection string
+	Operation  string
+	// OtherOrgID is the organization a cross-tenant query named
+	OtherOrgID string
+}
+
+// scope tracks the tenant of one RPC. The tenant is unknown until the
+// handler has authenticated the caller and calls SetTenant.
+type scope struct {
+	method string
+
+	mu         sync.Mutex
+	orgID      primitive.ObjectID
+	actorID    models.ID
+	violations []Violation
+}
+
+type scopeKey struct{}
+
+type unscopedKey struct{}
+
+// SetTenant names the organization the RPC of ctx acts for, and the user
+// acting. Queries after it are checked.
+func SetTenant(ctx context.Context, orgID primitive.ObjectID, actorID models.ID) {
+	s, ok := ctx.Value(scopeKey{}).(*scope)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orgID = orgID
+	s.actorID = actorID
+}
+
+// Unscoped exempts queries made with the returned context from the checks,
+// for queries that must see every organization, such as checking that an
+// email address is unique. The reason documents the exemption.
+func Unscoped(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, reason)
+}
+
+// Verifier checks the queries of tenant-scoped RPCs
+type Verifier struct {
+	db   *database.Database
+	mode string
+	keys map[string]string
+}
+
+// NewVerifier returns a verifier recording violations in db's audit log
+func NewVerifier(db *database.Database, mode string) *Verifier {
+	keys := make(map[string]string, len(ScopedCollections))
+	for _, collection := range ScopedCollections {
+		keys[collection.Name] = collection.Key
+	}
+	return &Verifier{db: db, mode: mode, keys: keys}
+}
+
+// UnaryServerInterceptor checks the queries of the methods of services,
+// named like "user.OrganizationService"
+func (v *Verifier) UnaryServerInterceptor(services ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if v.mode == ModeOff || !scopedMethod(info.FullMethod, services) {
+			return handler(ctx, req)
+		}
+
+		s := &scope{method: info.FullMethod}
+		scopedCtx := context.WithValue(ctx, scopeKey{}, s)
+		resp, err := handler(database.WithQueryCheck(scopedCtx, v.check), req)
+
+		// Recorded outside the handler's transactions, which a failed
+		// query rolls back
+		v.record(ctx, s)
+		return resp, err
+	}
+}
+
+// check is the database.QueryCheck of tenant-scoped RPCs
+func (v *Verifier) check(ctx context.Context, collection, operation string, filter interface{}) error {
+	s, ok := ctx.Value(scopeKey{}).(*scope)
+	if !ok {
+		return nil
+	}
+	if _, unscoped := ctx.Value(unscopedKey{}).(string); unscoped {
+		return nil
+	}
+	key, scoped := v.keys[collection]
+	if !scoped {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.orgID.IsZero() {
+		return nil
+	}
+
+	if operation == "aggregate" {
+		filter = firstMatch(filter)
+	}
+	violation := Violation{Collection: collection, Operation: operation}
+	values, found := predicate(filter, key)
+	if !found {
+		violation.Kind = ViolationMissingPredicate
+	} else {
+		for _, value := range values {
+			if value != s.orgID.Hex() {
+				violation.Kind = ViolationCrossTenant
+				violation.OtherOrgID = value
+				break
+			}
+		}
+	}
+	if violation.Kind == "" {
+		return nil
+	}
+
+	s.violations = append(s.violations, violation)
+	metrics.TenantIsolationViolations.WithLabelValues(violation.Kind).Inc()
+	if v.mode == ModeEnforce {
+		return ErrViolation
+	}
+	return nil
+}
+
+// record writes a critical audit event for each violation of the RPC
+func (v *Verifier) record(ctx context.Context, s *scope) {
+	s.mu.Lock()
+	violations := s.violations
+	orgID, actorID := s.orgID, s.actorID
+	s.mu.Unlock()
+
+	for _, violation := range violations {
+		log.Printf("CRITICAL: tenant isolation violation in %s: %s %s on %s for organization %s",
+			s.method, violation.Kind, violation.Operation, violation.Collection, orgID.Hex())
+
+		details := bson.M{
+			"severity":   "critical",
+			"kind":       violation.Kind,
+			"method":     s.method,
+			"collection": violation.Collection,
+			"operation":  violation.Operation,
+			"org_id":     orgID.Hex(),
+			"enforced":   v.mode == ModeEnforce,
+		}
+		if violation.OtherOrgID != "" {
+			details["other_org_id"] = violation.OtherOrgID
+		}
+		err := audit.Record(ctx, v.db, models.AuditLog{
+			Action:   audit.ActionTenantIsolationViolated,
+			ActorID:  &actorID,
+			TargetID: actorID,
+			Details:  details,
+		})
+		if err != nil {
+			log.Printf("Failed to audit tenant isolation violation: %v", err)
+		}
+	}
+}
+
+func scopedMethod(fullMethod string, services []string) bool {
+	for _, service := range services {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// predicate returns the values a filter requires key to have. found is
+// false when the filter doesn't require one, directly or in an $and.
+func predicate(filter interface{}, key string) (values []string, found bool) {
+	for _, element := range elements(filter) {
+		switch element.Key {
+		case key:
+			return pinnedValues(element.Value)
+		case "$and":
+			for _, clause := range list(element.Value) {
+				if values, found := predicate(clause, key); found {
+					return values, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// pinnedValues returns the values a predicate allows, when it allows a
+// known set: an equality or an $in
+func pinnedValues(value interface{}) ([]string, bool) {
+	if id, ok := idString(value); ok {
+		return []string{id}, true
+	}
+
+	operators := elements(value)
+	if len(operators) != 1 {
+		return nil, false
+	}
+	switch operators[0].Key {
+	case "$eq":
+		return pinnedValues(operators[0].Value)
+	case "$in":
+		var values []string
+		for _, item := range list(operators[0].Value) {
+			id, ok := idString(item)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, id)
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+func idString(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case primitive.ObjectID:
+		return value.Hex(), true
+	case *primitive.ObjectID:
+		if value == nil {
+			return "", true
+		}
+		return value.Hex(), true
+	case models.ID:
+		return value.String(), true
+	case string:
+		return value, true
+	}
+	return "", false
+}
+
+// firstMatch returns the filter of a pipeline's leading $match stage
+func firstMatch(pipeline interface{}) interface{} {
+	stages := list(pipeline)
+	if len(stages) == 0 {
+		return nil
+	}
+	for _, element := range elements(stages[0]) {
+		if element.Key == "$match" {
+			return element.Value
+		}
+	}
+	return nil
+}
+
+// elements returns the fields of a document in a filter
+func elements(document interface{}) []bson.E {
+	switch document := document.(type) {
+	case bson.D:
+		return document
+	case bson.M:
+		return mapElements(document)
+	case map[string]interface{}:
+		return mapElements(document)
+	}
+	return nil
+}
+
+func mapElements(document map[string]interface{}) []bson.E {
+	fields := make([]bson.E, 0, len(document))
+	for key, value := range document {
+		fields = append(fields, bson.E{Key: key, Value: value})
+	}
+	return fields
+}
+
+// list returns the items of an array in a filter or pipeline
+func list(value interface{}) []interface{} {
+	array := reflect.ValueOf(value)
+	if array.Kind() != reflect.Slice {
+		return nil
+	}
+	items := make([]interface{}, array.Len())
+	for i := range items {
+		items[i] = array.Index(i).Interface()
+	}
+	return items
+}
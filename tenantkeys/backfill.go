@@ -0,0 +1,168 @@
+package tenantkeys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"user-management/database"
+	"user-management/models"
+)
+
+// memberFields are the personal fields of members in a collection
+type memberFields struct {
+	collection *database.Collection
+	name       string
+	// userKey is the field holding the member's user ID
+	userKey string
+	fields  []string
+}
+
+// EncryptExisting encrypts the members' fields stored before encryption
+// was enabled. Fields of organizations whose key was destroyed are
+// cleared, since their data was deleted. It returns the number of
+// documents changed.
+func (k *Keyring) EncryptExisting(ctx context.Context) (int, error) {
+	targets := []memberFields{
+		{k.db.Users, "users", "_id", []string{"name"}},
+		{k.db.Changes, "profile_change_requests", "user_id", []string{"name", "email"}},
+	}
+
+	total := 0
+	for _, t := range targets {
+		changed, err := k.encryptCollection(ctx, t)
+		total += changed
+		if err != nil {
+			return total, fmt.Errorf("failed to encrypt %s: %v", t.name, err)
+		}
+	}
+	return total, nil
+}
+
+func (k *Keyring) encryptCollection(ctx context.Context, t memberFields) (int, error) {
+	plaintext := bson.M{
+		"$type": "string",
+		"$ne":   "",
+		"$not":  primitive.Regex{Pattern: "^" + regexp.QuoteMeta(encryptedPrefix)},
+	}
+	unencrypted := make([]bson.M, len(t.fields))
+	for i, field := range t.fields {
+		unencrypted[i] = bson.M{field: plaintext}
+	}
+
+	cursor, err := t.collection.Find(ctx, bson.M{
+		"org_id": bson.M{"$exists": true},
+		"$or":    unencrypted,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	changed := 0
+	for cursor.Next(ctx) {
+		doc := cursor.Current
+		orgID, ok := doc.Lookup("org_id").ObjectIDOK()
+		if !ok {
+			continue
+		}
+		var userID models.ID
+		if err := doc.Lookup(t.userKey).Unmarshal(&userID); err != nil {
+			return changed, err
+		}
+
+		// Only the values read are replaced, so concurrent changes are
+		// kept
+		filter := bson.M{"_id": doc.Lookup("_id")}
+		set := bson.M{}
+		for _, field := range t.fields {
+			value, ok := doc.Lookup(field).StringValueOK()
+			if !ok || value == "" || IsEncrypted(value) {
+				continue
+			}
+			encrypted, err := k.Encrypt(orgID, value, userID.String())
+			if errors.Is(err, models.ErrTenantKeyDestroyed) {
+				encrypted = ""
+			} else if err != nil {
+				return changed, err
+			}
+			filter[field] = value
+			set[field] = encrypted
+		}
+		if len(set) == 0 {
+			continue
+		}
+
+		result, err := t.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+		if err != nil {
+			return changed, err
+		}
+		changed += int(result.ModifiedCount)
+	}
+	return changed, cursor.Err()
+}
+
+// RewrapKeys wraps the data keys still wrapped by the previous master key
+// with the current one, and returns the number of keys wrapped again.
+// Once it has run, the previous master key is no longer needed.
+func (k *Keyring) RewrapKeys(ctx context.Context) (int, error) {
+	if k.previous == nil {
+		return 0, nil
+	}
+	cursor, err := k.db.TenantKeys.Find(ctx, bson.M{
+		"master_key_id": k.previous.ID(),
+		"destroyed_at":  bson.M{"$exists": false},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	rewrapped := 0
+	for cursor.Next(ctx) {
+		var key models.TenantKey
+		if err := cursor.Decode(&key); err != nil {
+			return rewrapped, err
+		}
+		dataKey, err := k.previous.Unwrap(ctx, key.WrappedKey)
+		if err != nil {
+			return rewrapped, fmt.Errorf("failed to unwrap data key of organization %s: %v", key.OrgID.Hex(), err)
+		}
+		if err := k.rewrap(ctx, key, dataKey); err != nil {
+			return rewrapped, err
+		}
+		rewrapped++
+	}
+	return rewrapped, cursor.Err()
+}
+
+// RunRewrapKeys runs RewrapKeys, logging the outcome
+func (k *Keyring) RunRewrapKeys(ctx context.Context) {
+	if k.previous == nil {
+		return
+	}
+	rewrapped, err := k.RewrapKeys(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to wrap organizations' data keys with master key %s: %v", k.master.ID(), err)
+		}
+		return
+	}
+	log.Printf("Wrapped the data keys of %d organizations with master key %s; the previous master key is no longer needed", rewrapped, k.master.ID())
+}
+
+// RunEncryptExisting runs EncryptExisting, logging the outcome
+func (k *Keyring) RunEncryptExisting(ctx context.Context) {
+	changed, err := k.EncryptExisting(ctx)
+	if changed > 0 {
+		log.Printf("Encrypted the personal data of %d organization member documents", changed)
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Failed to encrypt organization members' data: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package tenantkeys
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"user-management/kms"
+)
+
+// MasterKey wraps organizations' data keys. Implementations keep the key
+// in a key management service, or in memory for LocalMasterKey.
+type MasterKey interface {
+	// ID names the key, and is stored with the data keys it wraps
+	ID() string
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// wrapAssociatedData binds wrapped keys to their purpose
+const wrapAssociatedData = "tenant data key"
+
+// LocalMasterKey is a master key derived from a secret in the server's
+// configuration
+type LocalMasterKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewLocalMasterKey derives a master key from secret
+func NewLocalMasterKey(secret string) (*LocalMasterKey, error) {
+	if secret == "" {
+		return nil, errors.New("master key secret is empty")
+	}
+	sum := sha256.Sum256([]byte(secret))
+	aead, err := newAEAD(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	// Names the key without revealing it
+	fingerprint := sha256.Sum256(sum[:])
+	return &LocalMasterKey{
+		id:   "local:" + hex.EncodeToString(fingerprint[:8]),
+		aead: aead,
+	}, nil
+}
+
+func (m *LocalMasterKey) ID() string {
+	return m.id
+}
+
+func (m *LocalMasterKey) Wrap(_ context.Context, dataKey []byte) ([]byte, error) {
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	return m.aead.Seal(nonce, nonce, dataKey, []byte(wrapAssociatedData)), nil
+}
+
+func (m *LocalMasterKey) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < m.aead.NonceSize() {
+		return nil, errors.New("wrapped key is too short")
+	}
+	nonce, ciphertext := wrapped[:m.aead.NonceSize()], wrapped[m.aead.NonceSize():]
+	return m.aead.Open(nil, nonce, ciphertext, []byte(wrapAssociatedData))
+}
+
+// KMSMasterKey is a master key held by a key management service, which
+// wraps and unwraps every data key
+type KMSMasterKey struct {
+	key kms.Key
+}
+
+func NewKMSMasterKey(key kms.Key) *KMSMasterKey {
+	return &KMSMasterKey{key: key}
+}
+
+// ID is the key's URI
+func (m *KMSMasterKey) ID() string {
+	return m.key.URI()
+}
+
+func (m *KMSMasterKey) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	return m.key.Encrypt(ctx, dataKey, wrapAssociatedData)
+}
+
+func (m *KMSMasterKey) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	return m.key.Decrypt(ctx, wrapped, wrapAssociatedData)
+}
+
+// OpenMasterKey returns the master key configured as value: the URI of a
+// KMS key, or a secret for a LocalMasterKey
+func OpenMasterKey(ctx context.Context, value string) (MasterKey, error) {
+	if kms.IsURI(value) {
+		key, err := kms.Open(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		return NewKMSMasterKey(key), nil
+	}
+	key, err := NewLocalMasterKey(value)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
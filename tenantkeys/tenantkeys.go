@@ -0,0 +1,272 @@
+// Package tenantkeys encrypts the personal data of each organization's
+// members with a data key of the organization's own. Data keys are stored
+// in tenant_keys wrapped by a master key, and unwrapped only in memory.
+// Destroying an organization's data key makes everything encrypted with
+// it unreadable, including copies in the event history, which deletes the
+// organization's members' data cryptographically.
+package tenantkeys
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"user-management/database"
+	"user-management/models"
+)
+
+// encryptedPrefix marks values encrypted with a data key and versions
+// their format
+const encryptedPrefix = "tenant:v1:"
+
+const (
+	// dataKeySize is the size of data keys, for AES-256-GCM
+	dataKeySize = 32
+	// cacheTTL bounds how long other servers keep using a destroyed key
+	cacheTTL = time.Minute
+	// loadTimeout bounds loading a key for a document being decoded,
+	// which has no context of its own
+	loadTimeout = 5 * time.Second
+)
+
+// Keyring encrypts members' personal data with their organization's data
+// key, creating the key the first time the organization needs one. It is
+// the models.FieldCipher of deployments with a master key.
+type Keyring struct {
+	db     *database.Database
+	master MasterKey
+	// previous is the master key being replaced, if any. Data keys it
+	// wrapped are wrapped again with master.
+	previous MasterKey
+
+	mu    sync.Mutex
+	cache map[primitive.ObjectID]cachedKey
+}
+
+// cachedKey is an unwrapped data key. aead is nil for a destroyed key.
+type cachedKey struct {
+	aead     cipher.AEAD
+	loadedAt time.Time
+}
+
+// NewKeyring returns a keyring storing data keys in db, wrapped by master.
+// previous, which may be nil, unwraps the keys wrapped before master
+// replaced it.
+func NewKeyring(db *database.Database, master, previous MasterKey) *Keyring {
+	return &Keyring{
+		db:       db,
+		master:   master,
+		previous: previous,
+		cache:    map[primitive.ObjectID]cachedKey{},
+	}
+}
+
+// Encrypt encrypts plaintext with orgID's data key for the record named by
+// associatedData
+func (k *Keyring) Encrypt(orgID primitive.ObjectID, plaintext, associatedData string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), loadTimeout)
+	defer cancel()
+	aead, err := k.dataKey(ctx, orgID, true)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(associatedData))
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt. Values stored before encryption was enabled
+// are returned unchanged.
+func (k *Keyring) Decrypt(orgID primitive.ObjectID, value, associatedData string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return value, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), loadTimeout)
+	defer cancel()
+	aead, err := k.dataKey(ctx, orgID, false)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed value encrypted for organization %s", orgID.Hex())
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(associatedData))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value for organization %s", orgID.Hex())
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether value was written by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// Destroy destroys orgID's data key, so the data it encrypted can't be
+// read and no more can be encrypted for the organization. Organizations
+// without a key get a destroyed one. Other servers stop using the key
+// within a minute. It returns models.ErrTenantKeyDestroyed when the key
+// was already destroyed.
+func (k *Keyring) Destroy(ctx context.Context, orgID primitive.ObjectID) (time.Time, error) {
+	now := time.Now()
+	_, err := k.db.TenantKeys.UpdateOne(ctx, bson.M{
+		"_id":          orgID,
+		"destroyed_at": bson.M{"$exists": false},
+	}, bson.M{
+		"$set":   bson.M{"destroyed_at": now},
+		"$unset": bson.M{"wrapped_key": ""},
+		"$setOnInsert": bson.M{
+			"master_key_id": k.master.ID(),
+			"created_at":    now,
+		},
+	}, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// The filter missed an existing key: it was destroyed already
+		return time.Time{}, models.ErrTenantKeyDestroyed
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	k.mu.Lock()
+	k.cache[orgID] = cachedKey{loadedAt: now}
+	k.mu.Unlock()
+	return now, nil
+}
+
+// dataKey returns orgID's unwrapped data key, creating it when create is
+// set and the organization has none
+func (k *Keyring) dataKey(ctx context.Context, orgID primitive.ObjectID, create bool) (cipher.AEAD, error) {
+	k.mu.Lock()
+	cached, ok := k.cache[orgID]
+	k.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < cacheTTL {
+		if cached.aead == nil {
+			return nil, models.ErrTenantKeyDestroyed
+		}
+		return cached.aead, nil
+	}
+
+	var key models.TenantKey
+	err := k.db.TenantKeys.FindOne(ctx, bson.M{"_id": orgID}).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		if !create {
+			return nil, fmt.Errorf("organization %s has no data key", orgID.Hex())
+		}
+		key, err = k.createKey(ctx, orgID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data key of organization %s: %v", orgID.Hex(), err)
+	}
+
+	var aead cipher.AEAD
+	if key.DestroyedAt == nil {
+		dataKey, err := k.unwrap(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unwrap data key of organization %s: %v", orgID.Hex(), err)
+		}
+		if aead, err = newAEAD(dataKey); err != nil {
+			return nil, err
+		}
+	}
+
+	k.mu.Lock()
+	k.cache[orgID] = cachedKey{aead: aead, loadedAt: time.Now()}
+	k.mu.Unlock()
+	if aead == nil {
+		return nil, models.ErrTenantKeyDestroyed
+	}
+	return aead, nil
+}
+
+// unwrap unwraps key with the master key that wrapped it. Keys wrapped by
+// the previous master key are wrapped again with the current one.
+func (k *Keyring) unwrap(ctx context.Context, key models.TenantKey) ([]byte, error) {
+	switch {
+	case key.MasterKeyID == k.master.ID():
+		return k.master.Unwrap(ctx, key.WrappedKey)
+	case k.previous != nil && key.MasterKeyID == k.previous.ID():
+		dataKey, err := k.previous.Unwrap(ctx, key.WrappedKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := k.rewrap(ctx, key, dataKey); err != nil {
+			log.Printf("Failed to wrap the data key of organization %s with master key %s: %v", key.OrgID.Hex(), k.master.ID(), err)
+		}
+		return dataKey, nil
+	default:
+		return nil, fmt.Errorf("wrapped by unknown master key %s", key.MasterKeyID)
+	}
+}
+
+// rewrap stores key's unwrapped dataKey wrapped by the current master key,
+// unless another server did first
+func (k *Keyring) rewrap(ctx context.Context, key models.TenantKey, dataKey []byte) error {
+	wrapped, err := k.master.Wrap(ctx, dataKey)
+	if err != nil {
+		return err
+	}
+	_, err = k.db.TenantKeys.UpdateOne(ctx, bson.M{
+		"_id":           key.OrgID,
+		"master_key_id": key.MasterKeyID,
+		"destroyed_at":  bson.M{"$exists": false},
+	}, bson.M{"$set": bson.M{
+		"wrapped_key":   wrapped,
+		"master_key_id": k.master.ID(),
+	}})
+	return err
+}
+
+// createKey stores a new data key for orgID. When another server created
+// one first, that key is returned instead.
+func (k *Keyring) createKey(ctx context.Context, orgID primitive.ObjectID) (models.TenantKey, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return models.TenantKey{}, fmt.Errorf("failed to generate data key: %v", err)
+	}
+	wrapped, err := k.master.Wrap(ctx, dataKey)
+	if err != nil {
+		return models.TenantKey{}, fmt.Errorf("failed to wrap data key: %v", err)
+	}
+
+	key := models.TenantKey{
+		OrgID:       orgID,
+		WrappedKey:  wrapped,
+		MasterKeyID: k.master.ID(),
+		CreatedAt:   time.Now(),
+	}
+	_, err = k.db.TenantKeys.InsertOne(ctx, key)
+	if mongo.IsDuplicateKeyError(err) {
+		err = k.db.TenantKeys.FindOne(ctx, bson.M{"_id": orgID}).Decode(&key)
+	}
+	return key, err
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
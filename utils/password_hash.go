@@ -0,0 +1,342 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Password hash formats. New hashes are bcrypt unless another format is
+// set with SetPasswordHashFormat. Other formats are accepted from accounts
+// imported from other providers, and replaced at their next login.
+const (
+	HashFormatBcrypt = "bcrypt"
+	HashFormatArgon2 = "argon2"
+	HashFormatPBKDF2 = "pbkdf2"
+	// HashFormatFirebaseScrypt is the modified scrypt used by Firebase Auth
+	HashFormatFirebaseScrypt = "firebase-scrypt"
+)
+
+// Upper bounds on imported hash parameters, so a crafted hash cannot make
+// a single login exhaust the server
+const (
+	maxArgon2Memory     = 1 << 20 // KiB
+	maxArgon2Time       = 16
+	maxPBKDF2Iterations = 10_000_000
+	maxScryptMemCost    = 17 // log2 of N
+	maxScryptRounds     = 16
+)
+
+// Parameters of new argon2id and PBKDF2 hashes, following OWASP's
+// recommendations. Weaker hashes are replaced at their next login.
+const (
+	argon2Memory     = 64 * 1024 // KiB
+	argon2Time       = 3
+	argon2Threads    = 4
+	PBKDF2Iterations = 600_000
+	saltLength       = 16
+	hashKeyLength    = 32
+)
+
+// ErrUnsupportedHash is returned for hashes in a format that cannot be
+// verified
+var ErrUnsupportedHash = errors.New("unsupported password hash format")
+
+// CheckPasswordHash compares a password with its hash in any supported
+// format
+func CheckPasswordHash(password, hash string) bool {
+	switch {
+	case isBcrypt(hash):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	case strings.HasPrefix(hash, "$argon2"):
+		params, err := parseArgon2(hash)
+		return err == nil && params.matches(password)
+	case strings.HasPrefix(hash, "$pbkdf2-"):
+		params, err := parsePBKDF2(hash)
+		return err == nil && params.matches(password)
+	case strings.HasPrefix(hash, "$firebase-scrypt$"):
+		params, err := parseFirebaseScrypt(hash)
+		return err == nil && params.matches(password)
+	default:
+		return false
+	}
+}
+
+// PasswordHashFormat validates an imported hash and returns its format.
+// Accepted forms are:
+//
+//	$2a$10$...                                    bcrypt ($2a$, $2b$, $2y$)
+//	$argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>   argon2id or argon2i, PHC string
+//	$pbkdf2-sha256$i=310000$<salt>$<key>          pbkdf2-sha1, -sha256 or -sha512
+//	$firebase-scrypt$m=14,r=8$<signer key>$<salt separator>$<salt>$<key>
+//
+// Salts and keys are base64, with or without padding. Passlib's adapted
+// base64, bare iteration counts and Auth0's "i=N,l=N" are also accepted
+// for PBKDF2.
+func PasswordHashFormat(hash string) (string, error) {
+	switch {
+	case isBcrypt(hash):
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return "", fmt.Errorf("invalid bcrypt hash: %v", err)
+		}
+		return HashFormatBcrypt, nil
+	case strings.HasPrefix(hash, "$argon2"):
+		if _, err := parseArgon2(hash); err != nil {
+			return "", err
+		}
+		return HashFormatArgon2, nil
+	case strings.HasPrefix(hash, "$pbkdf2-"):
+		if _, err := parsePBKDF2(hash); err != nil {
+			return "", err
+		}
+		return HashFormatPBKDF2, nil
+	case strings.HasPrefix(hash, "$firebase-scrypt$"):
+		if _, err := parseFirebaseScrypt(hash); err != nil {
+			return "", err
+		}
+		return HashFormatFirebaseScrypt, nil
+	default:
+		return "", ErrUnsupportedHash
+	}
+}
+
+// NeedsRehash reports whether a hash should be replaced with one from
+// HashPassword the next time the password is known
+func NeedsRehash(hash string) bool {
+	switch format, _ := PasswordHashFormat(hash); {
+	case format != hashFormat:
+		return true
+	case format == HashFormatArgon2:
+		params, err := parseArgon2(hash)
+		return err != nil || params.variant != "argon2id" || params.memory < argon2Memory || params.time < argon2Time
+	case format == HashFormatPBKDF2:
+		params, err := parsePBKDF2(hash)
+		return err != nil || params.digestName != "sha256" || params.iterations < PBKDF2Iterations
+	default:
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost < bcryptCost
+	}
+}
+
+// hashArgon2 returns an argon2id hash in PHC string format
+func hashArgon2(password string) (string, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %v", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, hashKeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// hashPBKDF2 returns a PBKDF2-HMAC-SHA256 hash, the format approved in
+// FIPS mode
+func hashPBKDF2(password string) (string, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %v", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, PBKDF2Iterations, hashKeyLength)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$pbkdf2-sha256$i=%d$%s$%s", PBKDF2Iterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+type argon2Params struct {
+	variant string
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func parseArgon2(hash string) (*argon2Params, error) {
+	// "", variant, version, parameters, salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return nil, errors.New("invalid argon2 hash: expected $variant$v=N$m=N,t=N,p=N$salt$key")
+	}
+
+	p := &argon2Params{variant: parts[1]}
+	if p.variant != "argon2id" && p.variant != "argon2i" {
+		return nil, fmt.Errorf("invalid argon2 hash: unsupported variant %s", p.variant)
+	}
+
+	if parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return nil, fmt.Errorf("invalid argon2 hash: unsupported version %s", parts[2])
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return nil, fmt.Errorf("invalid argon2 hash parameters: %v", err)
+	}
+	if p.memory == 0 || p.memory > maxArgon2Memory || p.time == 0 || p.time > maxArgon2Time || p.threads == 0 {
+		return nil, errors.New("invalid argon2 hash: parameters out of range")
+	}
+
+	var err error
+	if p.salt, err = decodeHashBase64(parts[4]); err != nil {
+		return nil, fmt.Errorf("invalid argon2 salt: %v", err)
+	}
+	if p.key, err = decodeHashBase64(parts[5]); err != nil || len(p.key) == 0 {
+		return nil, errors.New("invalid argon2 key")
+	}
+
+	return p, nil
+}
+
+func (p *argon2Params) matches(password string) bool {
+	keyLen := uint32(len(p.key))
+	var key []byte
+	if p.variant == "argon2id" {
+		key = argon2.IDKey([]byte(password), p.salt, p.time, p.memory, p.threads, keyLen)
+	} else {
+		key = argon2.Key([]byte(password), p.salt, p.time, p.memory, p.threads, keyLen)
+	}
+	return subtle.ConstantTimeCompare(key, p.key) == 1
+}
+
+type pbkdf2Params struct {
+	digestName string
+	digest     func() hash.Hash
+	iterations int
+	salt       []byte
+	key        []byte
+}
+
+func parsePBKDF2(hash string) (*pbkdf2Params, error) {
+	// "", algorithm, iterations, salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 {
+		return nil, errors.New("invalid pbkdf2 hash: expected $pbkdf2-digest$i=N$salt$key")
+	}
+
+	p := &pbkdf2Params{digestName: strings.TrimPrefix(parts[1], "pbkdf2-")}
+	switch parts[1] {
+	case "pbkdf2-sha1":
+		p.digest = sha1.New
+	case "pbkdf2-sha256":
+		p.digest = sha256.New
+	case "pbkdf2-sha512":
+		p.digest = sha512.New
+	default:
+		return nil, fmt.Errorf("invalid pbkdf2 hash: unsupported digest %s", p.digestName)
+	}
+
+	// The key length is optional and must match the key when present
+	iterationParam, lengthParam, hasLength := strings.Cut(parts[2], ",")
+	iterations, err := strconv.Atoi(strings.TrimPrefix(iterationParam, "i="))
+	if err != nil || iterations <= 0 || iterations > maxPBKDF2Iterations {
+		return nil, errors.New("invalid pbkdf2 hash: iterations out of range")
+	}
+	p.iterations = iterations
+
+	if p.salt, err = decodeHashBase64(parts[3]); err != nil {
+		return nil, fmt.Errorf("invalid pbkdf2 salt: %v", err)
+	}
+	if p.key, err = decodeHashBase64(parts[4]); err != nil || len(p.key) == 0 {
+		return nil, errors.New("invalid pbkdf2 key")
+	}
+
+	if hasLength {
+		keyLen, err := strconv.Atoi(strings.TrimPrefix(lengthParam, "l="))
+		if err != nil || keyLen != len(p.key) {
+			return nil, errors.New("invalid pbkdf2 hash: key length does not match the key")
+		}
+	}
+
+	return p, nil
+}
+
+func (p *pbkdf2Params) matches(password string) bool {
+	key, err := pbkdf2.Key(p.digest, password, p.salt, p.iterations, len(p.key))
+	return err == nil && subtle.ConstantTimeCompare(key, p.key) == 1
+}
+
+type firebaseScryptParams struct {
+	memCost       int
+	rounds        int
+	signerKey     []byte
+	saltSeparator []byte
+	salt          []byte
+	key           []byte
+}
+
+func parseFirebaseScrypt(hash string) (*firebaseScryptParams, error) {
+	// "", "firebase-scrypt", parameters, signer key, salt separator, salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 7 {
+		return nil, errors.New("invalid firebase-scrypt hash: expected $firebase-scrypt$m=N,r=N$signer key$salt separator$salt$key")
+	}
+
+	p := &firebaseScryptParams{}
+	if _, err := fmt.Sscanf(parts[2], "m=%d,r=%d", &p.memCost, &p.rounds); err != nil {
+		return nil, fmt.Errorf("invalid firebase-scrypt hash parameters: %v", err)
+	}
+	if p.memCost < 1 || p.memCost > maxScryptMemCost || p.rounds < 1 || p.rounds > maxScryptRounds {
+		return nil, errors.New("invalid firebase-scrypt hash: parameters out of range")
+	}
+
+	var err error
+	if p.signerKey, err = decodeHashBase64(parts[3]); err != nil || len(p.signerKey) == 0 {
+		return nil, errors.New("invalid firebase-scrypt signer key")
+	}
+	if p.saltSeparator, err = decodeHashBase64(parts[4]); err != nil {
+		return nil, fmt.Errorf("invalid firebase-scrypt salt separator: %v", err)
+	}
+	if p.salt, err = decodeHashBase64(parts[5]); err != nil {
+		return nil, fmt.Errorf("invalid firebase-scrypt salt: %v", err)
+	}
+	if p.key, err = decodeHashBase64(parts[6]); err != nil || len(p.key) == 0 {
+		return nil, errors.New("invalid firebase-scrypt key")
+	}
+
+	return p, nil
+}
+
+// matches derives an AES key from the password with scrypt and checks that
+// it encrypts the project's signer key to the stored key
+func (p *firebaseScryptParams) matches(password string) bool {
+	salt := append(append([]byte{}, p.salt...), p.saltSeparator...)
+	derived, err := scrypt.Key([]byte(password), salt, 1<<p.memCost, p.rounds, 1, 32)
+	if err != nil {
+		return false
+	}
+
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return false
+	}
+	key := make([]byte, len(p.signerKey))
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(key, p.signerKey)
+
+	return subtle.ConstantTimeCompare(key, p.key) == 1
+}
+
+// decodeHashBase64 decodes standard or URL-safe base64 with optional
+// padding, and passlib's variant that uses "." in place of "+"
+func decodeHashBase64(s string) ([]byte, error) {
+	s = strings.NewReplacer(".", "+", "-", "+", "_", "/").Replace(strings.TrimRight(s, "="))
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// requirementHarderToGuess is the rule a password below the policy's
+// MinScore fails
+const requirementHarderToGuess = "harder to guess"
+
+// PasswordPolicy is the set of rules new passwords must meet
+type PasswordPolicy struct {
+	MinLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireNumber  bool
+	RequireSpecial bool
+	// MinScore rejects passwords whose estimated strength is below it, on
+	// the 0-4 scale of EstimatePasswordStrength. Zero disables the check.
+	MinScore int
+}
+
+// DefaultPasswordPolicy is the policy used when none is configured
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:      MinPasswordLength,
+	RequireUpper:   true,
+	RequireLower:   true,
+	RequireNumber:  true,
+	RequireSpecial: true,
+}
+
+// Requirements lists every rule of the policy, in the order Unmet reports
+// them
+func (p PasswordPolicy) Requirements() []string {
+	return p.requirements("", true, 0)
+}
+
+// Unmet lists the rules the password fails
+func (p PasswordPolicy) Unmet(password string) []string {
+	score := 0
+	if p.MinScore > 0 {
+		score = EstimatePasswordStrength(password).Score
+	}
+	return p.requirements(password, false, score)
+}
+
+// UnmetWithStrength is Unmet for a password whose strength has already
+// been estimated
+func (p PasswordPolicy) UnmetWithStrength(password string, strength PasswordStrength) []string {
+	return p.requirements(password, false, strength.Score)
+}
+
+// requirements lists the rules the password fails, or every rule when all
+// is set
+func (p PasswordPolicy) requirements(password string, all bool, score int) []string {
+	var requirements []string
+
+	if all || len(password) < p.MinLength {
+		requirements = append(requirements, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+	if p.RequireUpper && (all || !hasUpper.MatchString(password)) {
+		requirements = append(requirements, "at least one uppercase letter")
+	}
+	if p.RequireLower && (all || !hasLower.MatchString(password)) {
+		requirements = append(requirements, "at least one lowercase letter")
+	}
+	if p.RequireNumber && (all || !hasNumber.MatchString(password)) {
+		requirements = append(requirements, "at least one number")
+	}
+	if p.RequireSpecial && (all || !hasSpecial.MatchString(password)) {
+		requirements = append(requirements, "at least one special character")
+	}
+	if p.MinScore > 0 && (all || score < p.MinScore) {
+		requirements = append(requirements, requirementHarderToGuess)
+	}
+
+	return requirements
+}
+
+// Validate checks a new password against the policy
+func (p PasswordPolicy) Validate(password string) error {
+	if len(password) == 0 {
+		return ValidationError{Field: "password", Message: "password is required"}
+	}
+
+	if len(password) > MaxPasswordLength {
+		return ValidationError{
+			Field:        "password",
+			Message:      fmt.Sprintf("password must be less than %d characters", MaxPasswordLength),
+			Requirements: []string{fmt.Sprintf("less than %d characters", MaxPasswordLength)},
+		}
+	}
+
+	requirements := p.Unmet(password)
+	if len(requirements) == 0 {
+		return nil
+	}
+
+	var message string
+	switch {
+	case len(password) < p.MinLength:
+		message = fmt.Sprintf("password must be at least %d characters", p.MinLength)
+	case requirements[0] == requirementHarderToGuess:
+		message = "password is too easy to guess"
+	default:
+		missing := requirements
+		if missing[len(missing)-1] == requirementHarderToGuess {
+			missing = missing[:len(missing)-1]
+		}
+		message = fmt.Sprintf("password must contain %s", strings.Join(missing, ", "))
+	}
+	return ValidationError{
+		Field:        "password",
+		Message:      message,
+		Requirements: requirements,
+	}
+}
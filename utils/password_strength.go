@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// passwordGuessesPerSecond is the rate of an offline attack on a stolen
+// bcrypt hash, which is what CrackTimeSeconds assumes
+const passwordGuessesPerSecond = 1e4
+
+// commonPasswords are among the most used passwords, most common first. A
+// password that is one of these, or one with digits and symbols added at
+// the end, is guessed almost at once.
+var commonPasswords = []string{
+	"123456", "password", "12345678", "qwerty", "123456789", "12345",
+	"1234", "111111", "1234567", "dragon", "123123", "baseball", "abc123",
+	"football", "monkey", "letmein", "696969", "shadow", "master", "666666",
+	"qwertyuiop", "123321", "mustang", "1234567890", "michael", "654321",
+	"superman", "1qaz2wsx", "7777777", "121212", "000000", "qazwsx",
+	"123qwe", "killer", "trustno1", "jordan", "jennifer", "zxcvbnm",
+	"asdfgh", "hunter", "buster", "soccer", "harley", "batman", "andrew",
+	"tigger", "sunshine", "iloveyou", "2000", "charlie", "robert",
+	"thomas", "hockey", "ranger", "daniel", "starwars", "klaster",
+	"112233", "george", "computer", "michelle", "jessica", "pepper",
+	"1111", "zxcvbn", "555555", "11111111", "131313", "freedom", "777777",
+	"pass", "maggie", "159753", "aaaaaa", "ginger", "princess", "joshua",
+	"cheese", "amanda", "summer", "love", "ashley", "nicole", "chelsea",
+	"biteme", "matthew", "access", "yankees", "987654321", "dallas",
+	"austin", "thunder", "taylor", "matrix", "welcome", "admin", "login",
+	"passw0rd", "p@ssw0rd", "qwerty123", "changeme", "secret",
+}
+
+var commonPasswordRanks = func() map[string]int {
+	ranks := make(map[string]int, len(commonPasswords))
+	for i, password := range commonPasswords {
+		ranks[password] = i + 1
+	}
+	return ranks
+}()
+
+// PasswordStrength estimates how hard a password is to guess
+type PasswordStrength struct {
+	// Score runs from 0, guessed in moments, to 4, out of reach of an
+	// offline attack
+	Score int
+	// Guesses is the estimated number of attempts an attacker needs
+	Guesses float64
+	// CrackTimeSeconds is how long an offline attack on the stored hash
+	// takes to make Guesses attempts
+	CrackTimeSeconds float64
+	// Warning explains the weakest part of the password, or is empty
+	Warning string
+}
+
+// EstimatePasswordStrength estimates the guesses needed for a password.
+// userInputs are values an attacker would try first, such as the account's
+// email and name.
+func EstimatePasswordStrength(password string, userInputs ...string) PasswordStrength {
+	lower := strings.ToLower(password)
+
+	var bits float64
+	var warning string
+
+	// Common passwords, also with digits or symbols added at the end
+	base := strings.TrimRightFunc(lower, func(r rune) bool {
+		return unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+	if rank, ok := commonPasswordRanks[lower]; ok {
+		return newPasswordStrength(float64(rank), "this is one of the most common passwords")
+	}
+	if rank, ok := commonPasswordRanks[base]; ok {
+		suffix := len(lower) - len(base)
+		return newPasswordStrength(float64(rank)*math.Pow(10, float64(suffix)), "adding numbers or symbols to a common password doesn't make it strong")
+	}
+
+	// Names and email addresses are tried as whole words
+	for _, input := range passwordUserWords(userInputs) {
+		if strings.Contains(lower, input) {
+			lower = strings.ReplaceAll(lower, input, "")
+			bits += math.Log2(1000)
+			warning = "avoid your name or email address"
+		}
+	}
+
+	// Each character adds the entropy of the character classes used, but
+	// runs of three or more repeats or sequences such as "aaa" or "123"
+	// add almost nothing
+	perChar := math.Log2(float64(passwordPoolSize(password)))
+	var prev, prevStep rune
+	runs := 0
+	for i, r := range []rune(lower) {
+		step := r - prev
+		if i >= 2 && step >= -1 && step <= 1 && step == prevStep {
+			bits++
+			runs++
+		} else {
+			bits += perChar
+		}
+		prev, prevStep = r, step
+	}
+	if runs > 0 && warning == "" {
+		warning = "repeated characters and sequences such as \"aaa\" or \"123\" are easy to guess"
+	}
+	if len(password) < MinPasswordLength && warning == "" {
+		warning = "short passwords are easy to guess"
+	}
+
+	// On average the password is found halfway through the search
+	return newPasswordStrength(math.Max(1, math.Pow(2, bits)/2), warning)
+}
+
+func newPasswordStrength(guesses float64, warning string) PasswordStrength {
+	score := 4
+	switch {
+	case guesses < 1e3:
+		score = 0
+	case guesses < 1e6:
+		score = 1
+	case guesses < 1e8:
+		score = 2
+	case guesses < 1e10:
+		score = 3
+	}
+	return PasswordStrength{
+		Score:            score,
+		Guesses:          guesses,
+		CrackTimeSeconds: guesses / passwordGuessesPerSecond,
+		Warning:          warning,
+	}
+}
+
+// passwordUserWords splits user inputs into the lowercase words an attacker
+// would try, such as "jane" and "doe" for "jane.doe@example.com"
+func passwordUserWords(userInputs []string) []string {
+	var words []string
+	for _, input := range userInputs {
+		input = strings.ToLower(input)
+		if at := strings.LastIndex(input, "@"); at >= 0 {
+			input = input[:at]
+		}
+		words = append(words, input)
+		words = append(words, strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+
+	var kept []string
+	for _, word := range words {
+		if len([]rune(word)) >= 3 {
+			kept = append(kept, word)
+		}
+	}
+
+	// Longest first so a full name is removed before its parts
+	sort.SliceStable(kept, func(i, j int) bool {
+		return len(kept[i]) > len(kept[j])
+	})
+	return kept
+}
+
+// passwordPoolSize is the number of characters in the classes the password
+// draws from
+func passwordPoolSize(password string) int {
+	size := 0
+	if hasLower.MatchString(password) {
+		size += 26
+	}
+	if hasUpper.MatchString(password) {
+		size += 26
+	}
+	if hasNumber.MatchString(password) {
+		size += 10
+	}
+	if hasSpecial.MatchString(password) {
+		size += 33
+	}
+	for _, r := range password {
+		if r > unicode.MaxASCII {
+			size += 100
+			break
+		}
+	}
+	if size == 0 {
+		// Whitespace and other ASCII punctuation only
+		size = 33
+	}
+	return size
+}
+
+// FormatCrackTime describes a crack time in words, such as "3 hours" or
+// "centuries"
+func FormatCrackTime(seconds float64) string {
+	const (
+		minute = 60
+		hour   = 60 * minute
+		day    = 24 * hour
+		month  = 31 * day
+		year   = 12 * month
+	)
+
+	units := []struct {
+		name    string
+		seconds float64
+	}{
+		{"year", year},
+		{"month", month},
+		{"day", day},
+		{"hour", hour},
+		{"minute", minute},
+		{"second", 1},
+	}
+
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds >= 100*year:
+		return "centuries"
+	}
+	for _, unit := range units {
+		if seconds >= unit.seconds {
+			n := int(math.Round(seconds / unit.seconds))
+			if n == 1 {
+				return fmt.Sprintf("1 %s", unit.name)
+			}
+			return fmt.Sprintf("%d %ss", n, unit.name)
+		}
+	}
+	return "less than a second"
+}
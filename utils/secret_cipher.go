@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedSecretPrefix marks values written by SecretCipher.Encrypt and
+// versions their format
+const encryptedSecretPrefix = "enc:v1:"
+
+// ErrSecretDecryption is returned for an encrypted value that was altered,
+// encrypted with another key, or belongs to another record
+var ErrSecretDecryption = errors.New("failed to decrypt secret")
+
+// SecretCipher encrypts secrets stored in the database, such as TOTP
+// secrets, with AES-256-GCM
+type SecretCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSecretCipher derives the encryption key from key
+func NewSecretCipher(key string) (*SecretCipher, error) {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretCipher{aead: aead}, nil
+}
+
+// Encrypt encrypts plaintext for the record named by associatedData, such
+// as a user ID, so it can't be copied to another record
+func (c *SecretCipher) Encrypt(plaintext, associatedData string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), []byte(associatedData))
+	return encryptedSecretPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt. Values stored before encryption was added are
+// returned unchanged.
+func (c *SecretCipher) Decrypt(value, associatedData string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedSecretPrefix)
+	if !ok {
+		return value, nil
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", ErrSecretDecryption
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, []byte(associatedData))
+	if err != nil {
+		return "", ErrSecretDecryption
+	}
+	return string(plaintext), nil
+}
+
+// IsEncryptedSecret reports whether value was written by Encrypt
+func IsEncryptedSecret(value string) bool {
+	return strings.HasPrefix(value, encryptedSecretPrefix)
+}
@@ -2,17 +2,25 @@ package utils
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"math/big"
 	"net/mail"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"golang.org/x/crypto/bcrypt"
 
 	"user-management/database"
+	"user-management/models"
 )
 
 const (
@@ -20,20 +28,32 @@ const (
 	MaxPasswordLength = 128
 	MaxNameLength     = 50
 	MaxEmailLength    = 128
+
+	MaxSearchFilterLength = 64
+
+	MaxExternalSystemLength = 32
+	MaxExternalIDLength     = 256
+
+	MaxCredentialNameLength = 64
 )
 
-// Password validation requirements
+// Password character classes
 var (
-	hasMinLen  = regexp.MustCompile(`.{8,}`)
 	hasUpper   = regexp.MustCompile(`[A-Z]`)
 	hasLower   = regexp.MustCompile(`[a-z]`)
 	hasNumber  = regexp.MustCompile(`[0-9]`)
 	hasSpecial = regexp.MustCompile(`[!@#$%^&*()_+\-=\[\]{};':"\\|,.<>\/?]`)
 )
 
+// externalSystemPattern matches external system names such as "auth0" or
+// "legacy_crm"
+var externalSystemPattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
 type ValidationError struct {
 	Field   string
 	Message string
+	// Requirements lists the unmet password rules for a weak password
+	Requirements []string
 }
 
 func (e ValidationError) Error() string {
@@ -58,120 +78,276 @@ func ValidateEmail(email string) error {
 	return nil
 }
 
-// ValidatePassword validates password strength
+// ValidatePassword validates password strength against the default policy
 func ValidatePassword(password string) error {
-	if len(password) == 0 {
-		return ValidationError{Field: "password", Message: "password is required"}
+	return DefaultPasswordPolicy.Validate(password)
+}
+
+// ValidateName validates first name and last name
+func ValidateName(name, fieldName string) error {
+	if len(name) == 0 {
+		return ValidationError{Field: fieldName, Message: fmt.Sprintf("%s is required", fieldName)}
 	}
 
-	if len(password) < MinPasswordLength {
-		return ValidationError{Field: "password", Message: fmt.Sprintf("password must be at least %d characters", MinPasswordLength)}
+	if len(name) > MaxNameLength {
+		return ValidationError{Field: fieldName, Message: fmt.Sprintf("%s is too long", fieldName)}
 	}
 
-	if len(password) > MaxPasswordLength {
-		return ValidationError{Field: "password", Message: fmt.Sprintf("password must be less than %d characters", MaxPasswordLength)}
+	// Check for valid characters (letters, spaces, hyphens, apostrophes)
+	for _, char := range name {
+		if !unicode.IsLetter(char) && char != ' ' && char != '-' && char != '\'' {
+			return ValidationError{Field: fieldName, Message: fmt.Sprintf("%s contains invalid characters", fieldName)}
+		}
 	}
 
-	var requirements []string
+	return nil
+}
 
-	if !hasMinLen.MatchString(password) {
-		requirements = append(requirements, "at least 8 characters")
+// ValidateExternalSystem validates the name of a system that users have
+// external IDs in
+func ValidateExternalSystem(system string) error {
+	if len(system) == 0 {
+		return ValidationError{Field: "system", Message: "system is required"}
 	}
-	if !hasUpper.MatchString(password) {
-		requirements = append(requirements, "at least one uppercase letter")
+
+	if len(system) > MaxExternalSystemLength {
+		return ValidationError{Field: "system", Message: "system is too long"}
 	}
-	if !hasLower.MatchString(password) {
-		requirements = append(requirements, "at least one lowercase letter")
+
+	if !externalSystemPattern.MatchString(system) {
+		return ValidationError{Field: "system", Message: "system may only contain lowercase letters, digits, hyphens and underscores"}
 	}
-	if !hasNumber.MatchString(password) {
-		requirements = append(requirements, "at least one number")
+
+	return nil
+}
+
+// ValidateCredentialName validates the name a user gives a passkey, device
+// or API key, such as "Work laptop"
+func ValidateCredentialName(name string) error {
+	if len(name) == 0 {
+		return ValidationError{Field: "name", Message: "name is required"}
 	}
-	if !hasSpecial.MatchString(password) {
-		requirements = append(requirements, "at least one special character")
+
+	if utf8.RuneCountInString(name) > MaxCredentialNameLength {
+		return ValidationError{Field: "name", Message: fmt.Sprintf("name must be at most %d characters", MaxCredentialNameLength)}
 	}
 
-	if len(requirements) > 0 {
-		return ValidationError{
-			Field:   "password",
-			Message: fmt.Sprintf("password must contain %s", strings.Join(requirements, ", ")),
+	for _, char := range name {
+		if !unicode.IsPrint(char) {
+			return ValidationError{Field: "name", Message: "name contains invalid characters"}
 		}
 	}
 
 	return nil
 }
 
-// ValidateName validates first name and last name
-func ValidateName(name, fieldName string) error {
-	if len(name) == 0 {
-		return ValidationError{Field: fieldName, Message: fmt.Sprintf("%s is required", fieldName)}
+// ValidateExternalID validates a user's ID in an external system
+func ValidateExternalID(id string) error {
+	if len(id) == 0 {
+		return ValidationError{Field: "external_id", Message: "external ID is required"}
 	}
 
-	if len(name) > MaxNameLength {
-		return ValidationError{Field: fieldName, Message: fmt.Sprintf("%s is too long", fieldName)}
+	if len(id) > MaxExternalIDLength {
+		return ValidationError{Field: "external_id", Message: "external ID is too long"}
 	}
 
-	// Check for valid characters (letters, spaces, hyphens, apostrophes)
-	for _, char := range name {
-		if !unicode.IsLetter(char) && char != ' ' && char != '-' && char != '\'' {
-			return ValidationError{Field: fieldName, Message: fmt.Sprintf("%s contains invalid characters", fieldName)}
-		}
+	return nil
+}
+
+// Bounds on the bcrypt cost. Each step doubles the time a login takes.
+const (
+	MinBcryptCost = bcrypt.DefaultCost
+	MaxBcryptCost = 14
+)
+
+// bcryptCost is the cost of new bcrypt hashes, set once at startup
+var bcryptCost = bcrypt.DefaultCost
+
+// SetBcryptCost sets the cost of new bcrypt hashes. Existing hashes with a
+// lower cost are replaced at their next login.
+func SetBcryptCost(cost int) error {
+	if cost < MinBcryptCost || cost > MaxBcryptCost {
+		return fmt.Errorf("bcrypt cost must be between %d and %d", MinBcryptCost, MaxBcryptCost)
 	}
+	bcryptCost = cost
+	return nil
+}
 
+// hashFormat is the format of new password hashes, set once at startup
+var hashFormat = HashFormatBcrypt
+
+// SetPasswordHashFormat sets the format of new password hashes: bcrypt,
+// argon2 or pbkdf2. Existing hashes in another format are replaced at
+// their next login.
+func SetPasswordHashFormat(format string) error {
+	switch format {
+	case HashFormatBcrypt, HashFormatArgon2, HashFormatPBKDF2:
+	default:
+		return fmt.Errorf("password hash format must be %s, %s or %s", HashFormatBcrypt, HashFormatArgon2, HashFormatPBKDF2)
+	}
+	hashFormat = format
 	return nil
 }
 
-// HashPassword hashes a password using bcrypt
+// HashPassword hashes a password in the format set at startup
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	switch hashFormat {
+	case HashFormatArgon2:
+		return hashArgon2(password)
+	case HashFormatPBKDF2:
+		return hashPBKDF2(password)
+	default:
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+		return string(bytes), err
+	}
+}
+
+// GenerateSecureToken returns a URL-safe random token of n bytes of entropy
+func GenerateSecureToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the SHA-256 hex digest of a token for storage
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// userCodeAlphabet omits vowels and look-alike characters so codes are easy
+// to read off a screen and never spell words
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+// GenerateUserCode returns a short code in the form XXXX-XXXX for a user to
+// type or scan on a second device
+func GenerateUserCode() (string, error) {
+	// Reject bytes past the largest multiple of the alphabet size to avoid
+	// modulo bias
+	limit := byte(256 - 256%len(userCodeAlphabet))
+	code := make([]byte, 0, 9)
+	b := make([]byte, 1)
+	for len(code) < 9 {
+		if len(code) == 4 {
+			code = append(code, '-')
+			continue
+		}
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("failed to generate user code: %v", err)
+		}
+		if b[0] >= limit {
+			continue
+		}
+		code = append(code, userCodeAlphabet[int(b[0])%len(userCodeAlphabet)])
+	}
+	return string(code), nil
+}
+
+// GenerateNumericCode returns a random code of the given number of decimal
+// digits, for codes users type in from an email
+func GenerateNumericCode(digits int) (string, error) {
+	code := make([]byte, digits)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
+
+// GenerateRecoveryCode returns a one-time account recovery code formatted
+// as four groups of four characters
+func GenerateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	encoded := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return encoded[0:4] + "-" + encoded[4:8] + "-" + encoded[8:12] + "-" + encoded[12:16], nil
+}
+
+// NormalizeRecoveryCode lowercases a recovery code and strips separators so
+// it can be hashed and compared however the user typed it
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// NormalizeUserCode converts user input to the stored XXXX-XXXX form
+func NormalizeUserCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	if len(code) != 8 {
+		return code
+	}
+	return code[:4] + "-" + code[4:]
 }
 
-// CheckPasswordHash compares a password with its hash
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+// DeviceFingerprint identifies a client device by its user agent and IP address
+func DeviceFingerprint(userAgent, ipAddress string) string {
+	return HashToken(userAgent + "|" + ipAddress)
 }
 
 // RateLimiter handles login attempt rate limiting
 type RateLimiter struct {
-	db *database.Database
+	attempts    database.AttemptRepository
+	maxAttempts int
+	window      time.Duration
 }
 
-func NewRateLimiter(db *database.Database) *RateLimiter {
-	return &RateLimiter{db: db}
+// NewRateLimiter allows up to maxAttempts failed logins per email and IP
+// address within window
+func NewRateLimiter(attempts database.AttemptRepository, maxAttempts int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		attempts:    attempts,
+		maxAttempts: maxAttempts,
+		window:      window,
+	}
 }
 
 // CheckRateLimit checks if the user has exceeded login attempts
 func (r *RateLimiter) CheckRateLimit(ctx context.Context, email, ipAddress string) (bool, error) {
-	// Count failed attempts in the last minute
-	oneMinuteAgo := time.Now().Add(-1 * time.Minute)
-
-	filter := bson.M{
-		"email":      email,
-		"ip_address": ipAddress,
-		"success":    false,
-		"timestamp":  bson.M{"$gte": oneMinuteAgo},
+	// Count failed attempts within the window
+	count, err := r.attempts.CountFailures(ctx, email, ipAddress, time.Now().Add(-r.window))
+	if err != nil {
+		return false, fmt.Errorf("failed to check rate limit: %v", err)
 	}
 
-	count, err := r.db.Attempts.CountDocuments(ctx, filter)
+	return count < int64(r.maxAttempts), nil
+}
+
+// RetryAfter returns how long until a rate-limited email and IP address may
+// try again, which is when the oldest attempt counting against the limit
+// leaves the window
+func (r *RateLimiter) RetryAfter(ctx context.Context, email, ipAddress string) (time.Duration, error) {
+	oldest, err := r.attempts.NthLatestFailure(ctx, email, ipAddress, time.Now().Add(-r.window), r.maxAttempts)
 	if err != nil {
-		return false, fmt.Errorf("failed to check rate limit: %v", err)
+		return 0, fmt.Errorf("failed to check rate limit: %v", err)
+	}
+	if oldest.IsZero() {
+		return 0, nil
 	}
 
-	// Allow up to 5 failed attempts per minute
-	return count < 5, nil
+	retryAfter := time.Until(oldest.Add(r.window))
+	if retryAfter < 0 {
+		retryAfter = 0
+	}
+	return retryAfter, nil
 }
 
 // RecordLoginAttempt records a login attempt
 func (r *RateLimiter) RecordLoginAttempt(ctx context.Context, email, ipAddress string, success bool) error {
-	attempt := bson.M{
-		"email":      email,
-		"ip_address": ipAddress,
-		"timestamp":  time.Now(),
-		"success":    success,
-	}
-
-	_, err := r.db.Attempts.InsertOne(ctx, attempt)
+	err := r.attempts.Insert(ctx, models.LoginAttempt{
+		Email:     email,
+		IPAddress: ipAddress,
+		Timestamp: time.Now(),
+		Success:   success,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to record login attempt: %v", err)
 	}
@@ -179,26 +355,41 @@ func (r *RateLimiter) RecordLoginAttempt(ctx context.Context, email, ipAddress s
 	return nil
 }
 
+// Compact deletes the login attempts that no longer count against the
+// limit and returns how many. The attempts' TTL index removes them after an
+// hour anyway; compacting keeps the collection to the failures within the
+// window.
+func (r *RateLimiter) Compact(ctx context.Context) (int, error) {
+	deleted, err := r.attempts.DeleteUnused(ctx, time.Now().Add(-r.window))
+	if err != nil {
+		return 0, fmt.Errorf("failed to compact login attempts: %v", err)
+	}
+	return int(deleted), nil
+}
+
 // SanitizeString removes leading/trailing whitespace and normalizes
 func SanitizeString(s string) string {
 	return strings.TrimSpace(s)
 }
 
-// BuildSearchFilter creates a MongoDB filter for name and email search
-func BuildSearchFilter(nameFilter, emailFilter string) bson.M {
-	filter := bson.M{"is_deleted": false}
-
-	if nameFilter != "" {
-		// Search in both first_name and last_name
-		nameRegex := bson.M{"$regex": nameFilter, "$options": "i"}
-		filter["$or"] = []bson.M{
-			{"name": nameRegex},
-		}
+// ValidateUserSearch checks the filters and sort order of a user search
+func ValidateUserSearch(search database.UserSearch) error {
+	if len(search.NameFilter) > MaxSearchFilterLength {
+		return ValidationError{Field: "name_filter", Message: "name filter is too long"}
 	}
-
-	if emailFilter != "" {
-		filter["email"] = bson.M{"$regex": emailFilter, "$options": "i"}
+	if len(search.EmailFilter) > MaxSearchFilterLength {
+		return ValidationError{Field: "email_filter", Message: "email filter is too long"}
 	}
-
-	return filter
+	if !search.CreatedAfter.IsZero() && !search.CreatedBefore.IsZero() && !search.CreatedAfter.Before(search.CreatedBefore) {
+		return ValidationError{Field: "created_after", Message: "created_after must be before created_before"}
+	}
+	if search.SortBy != "" && !slices.Contains(database.UserSortFields, search.SortBy) {
+		return ValidationError{Field: "sort_by", Message: "sort_by must be one of " + strings.Join(database.UserSortFields, ", ")}
+	}
+	switch search.SortOrder {
+	case "", database.SortOrderAsc, database.SortOrderDesc:
+	default:
+		return ValidationError{Field: "sort_order", Message: "sort_order must be asc or desc"}
+	}
+	return nil
 }